@@ -0,0 +1,126 @@
+package app
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+)
+
+// ChatOptions holds options for the chat command
+type ChatOptions struct {
+	*GlobalOptions
+
+	// Model is the alias or model ID of the instance to chat with
+	Model string
+
+	// System overrides the Modelfile's system prompt
+	System string
+}
+
+// NewChatCommand creates the chat command.
+//
+// The chat command opens an interactive chat session with a running model
+// instance through the server's OpenAI-compatible endpoint. Unlike 'xw run',
+// it never pulls or starts anything.
+//
+// Usage:
+//
+//	xw chat MODEL [--system PROMPT]
+//
+// Examples:
+//
+//	# Chat with a running instance
+//	xw chat qwen3-32b
+//
+//	# Chat with a different system prompt
+//	xw chat qwen3-32b --system "Answer in one sentence."
+//
+// Parameters:
+//   - globalOpts: Global options shared across commands
+//
+// Returns:
+//   - A configured cobra.Command for chatting with instances
+func NewChatCommand(globalOpts *GlobalOptions) *cobra.Command {
+	opts := &ChatOptions{
+		GlobalOptions: globalOpts,
+	}
+
+	cmd := &cobra.Command{
+		Use:   "chat MODEL",
+		Short: "Chat with a running model instance",
+		Long: `Open an interactive chat session with a running model instance.
+
+MODEL is the alias of the instance (see 'xw ps') or the ID of the model it
+serves. Responses are streamed from the local OpenAI-compatible endpoint,
+and the conversation history is sent with every message.
+
+The model's system prompt from its Modelfile (see 'xw show --system') is
+used by default; use --system or the /system command to change it.
+
+In the session:
+  /clear             Clear the conversation history
+  /system <prompt>   Set the system prompt
+  /quit              Exit (or press Ctrl+D)
+  """                Begin and end a multiline message
+  Ctrl+C             Interrupt the response being generated
+
+Use 'xw run' to pull and start a model before chatting with it.`,
+		Example: `  # Chat with a running instance
+  xw chat qwen3-32b
+
+  # Chat with a different system prompt
+  xw chat qwen3-32b --system "Answer in one sentence."`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			opts.Model = args[0]
+			return runChat(cmd, opts)
+		},
+	}
+
+	cmd.Flags().StringVar(&opts.System, "system", "",
+		"system prompt (default: the model's Modelfile SYSTEM)")
+
+	return cmd
+}
+
+// runChat executes the chat command logic
+func runChat(cmd *cobra.Command, opts *ChatOptions) error {
+	client := getClient(opts.GlobalOptions)
+
+	// Find the instance by alias, or by the model it serves
+	instMap := findInstance(client, opts.Model)
+	if instMap == nil {
+		instances, err := client.ListInstances(false)
+		if err != nil {
+			return fmt.Errorf("failed to list instances: %w", err)
+		}
+		for _, inst := range instances {
+			m, ok := inst.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			if modelID, _ := m["model_id"].(string); modelID == opts.Model {
+				instMap = m
+				break
+			}
+		}
+	}
+	if instMap == nil {
+		return fmt.Errorf("no instance of %s is running\n\n"+
+			"Start one with 'xw run %s'", opts.Model, opts.Model)
+	}
+
+	alias, _ := instMap["alias"].(string)
+	modelID, _ := instMap["model_id"].(string)
+	if state, _ := instMap["state"].(string); state != "ready" {
+		fmt.Printf("Warning: %s is not ready (state: %s); messages may fail until it is\n\n", alias, state)
+	}
+
+	systemPrompt := opts.System
+	if !cmd.Flags().Changed("system") {
+		systemPrompt = modelSystemPrompt(client, modelID)
+	}
+
+	printChatBanner(alias)
+	return startInteractiveChat(alias, client.GetBaseURL(), client.HTTPClient(), systemPrompt)
+}
@@ -2,8 +2,10 @@ package app
 
 import (
 	"fmt"
+	"strconv"
 
 	"github.com/spf13/cobra"
+	"github.com/tsingmaoai/xw-cli/internal/config"
 )
 
 // ConfigOptions holds options for the config command
@@ -21,6 +23,8 @@ type ConfigOptions struct {
 //   - info: Display all configuration settings
 //   - get:  Get a specific configuration value
 //   - set:  Set a configuration value
+//   - set-concurrency: Change the concurrency limit of a running instance
+//   - validate: Check devices.yaml and models.yaml files before deploying them
 //
 // Usage:
 //
@@ -54,7 +58,8 @@ func NewConfigCommand(globalOpts *GlobalOptions) *cobra.Command {
 
 The config command group allows you to view and modify server configuration
 values such as the server name and registry URL. All configuration changes
-are immediately persisted and take effect without requiring a server restart.`,
+are immediately persisted and, except for the listen host and port, take
+effect without requiring a server restart.`,
 		Example: `  # View all configuration
   xw config info
 
@@ -74,6 +79,8 @@ are immediately persisted and take effect without requiring a server restart.`,
 	cmd.AddCommand(NewConfigInfoCommand(opts))
 	cmd.AddCommand(NewConfigGetCommand(opts))
 	cmd.AddCommand(NewConfigSetCommand(opts))
+	cmd.AddCommand(NewConfigSetConcurrencyCommand(opts))
+	cmd.AddCommand(NewConfigValidateCommand(opts))
 
 	return cmd
 }
@@ -121,6 +128,8 @@ including:
 // Supported keys:
 //   - name: Server instance identifier
 //   - registry: Configuration package registry URL
+//   - registry_mirror: Docker registry mirror for image pulls
+//   - api_key: API key required by the server (masked)
 //   - host: Server host address
 //   - port: Server port number
 //   - config_dir: Configuration directory path
@@ -141,6 +150,8 @@ func NewConfigGetCommand(opts *ConfigOptions) *cobra.Command {
 Supported configuration keys:
   - name:       Server instance identifier
   - registry:   Configuration package registry URL
+  - registry_mirror: Docker registry mirror for image pulls
+  - api_key:    API key required by the server (shown masked)
   - host:       Server host address
   - port:       Server port number
   - config_dir: Configuration directory path
@@ -154,7 +165,7 @@ Supported configuration keys:
   # Get server port
   xw config get port`,
 		Args: cobra.ExactArgs(1),
-		ValidArgs: []string{"name", "registry", "host", "port", "config_dir", "data_dir"},
+		ValidArgs: []string{"name", "registry", "registry_mirror", "api_key", "host", "port", "config_dir", "data_dir"},
 		RunE: func(cmd *cobra.Command, args []string) error {
 			key := args[0]
 			return runConfigGet(opts, key)
@@ -171,9 +182,11 @@ Supported configuration keys:
 //
 // Supported keys:
 //   - registry: Configuration package registry URL
+//   - registry_mirror: Docker registry mirror for image pulls
+//   - api_key: API key clients must present
+//   - host, port: Listen address and port, applied on the next server start
 //
-// Note: Server name, host, and port cannot be modified via this command.
-// Edit server.conf manually or use command-line flags for host/port.
+// Note: The server name cannot be modified via this command.
 //
 // Usage:
 //
@@ -189,16 +202,41 @@ func NewConfigSetCommand(opts *ConfigOptions) *cobra.Command {
 
 Supported configuration keys:
   - registry: Configuration package registry URL (must be valid HTTP/HTTPS URL)
+  - registry_mirror: Docker registry mirror for runtime image pulls. Either a
+    single prefix used for every registry, or registry=prefix pairs separated
+    by commas. Use "none" to clear. The XW_REGISTRY_MIRROR environment variable
+    on the server overrides this setting.
+  - api_key: Require this key as "Authorization: Bearer <key>" (or x-api-key)
+    on /api/* and /v1/* requests. Use "none" to disable. The XW_API_KEY
+    environment variable on the server overrides this setting; the xw CLI
+    sends the key from its own XW_API_KEY.
+  - host: Address the server listens on (IP address or host name)
+  - port: Port the server listens on (1-65535)
+
+Changes are immediately persisted to disk. All keys except host and port take
+effect without a server restart. host and port are applied when the server
+is next started, unless 'xw serve' is given --host or --port; the running
+server keeps listening on its current address.
+
+Note: The server name cannot be modified via this command, as it is tied to
+running container instances (modification would break instance management).`,
+		Example: `  # Set registry URL
+  xw config set registry https://custom.registry.com/packages.json
 
-Note: Server name, host, and port cannot be modified via this command.
-  - name: Tied to running container instances (modification would break instance management)
-  - host/port: Use command-line flags (--host, --port) or edit server.conf manually
+  # Pull quay.io images through a mirror
+  xw config set registry_mirror quay.io=quay.m.daocloud.io
 
-Changes are immediately persisted to disk and take effect without server restart.`,
-		Example: `  # Set registry URL
-  xw config set registry https://custom.registry.com/packages.json`,
+  # Disable the registry mirror
+  xw config set registry_mirror none
+
+  # Require an API key (then export XW_API_KEY for the CLI)
+  xw config set api_key "$(openssl rand -hex 24)"
+
+  # Listen on all interfaces, port 9090, from the next server start
+  xw config set host 0.0.0.0
+  xw config set port 9090`,
 		Args: cobra.ExactArgs(2),
-		ValidArgs: []string{"registry"},
+		ValidArgs: []string{"registry", "registry_mirror", "api_key", "host", "port"},
 		RunE: func(cmd *cobra.Command, args []string) error {
 			key := args[0]
 			value := args[1]
@@ -209,6 +247,98 @@ Changes are immediately persisted to disk and take effect without server restart
 	return cmd
 }
 
+// NewConfigSetConcurrencyCommand creates the config set-concurrency subcommand.
+//
+// This command changes the maximum number of concurrent requests a running
+// instance serves, without restarting it.
+//
+// Usage:
+//
+//	xw config set-concurrency <model> <n>
+//
+// Returns:
+//   - A configured cobra.Command for changing instance concurrency limits
+func NewConfigSetConcurrencyCommand(opts *ConfigOptions) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "set-concurrency <model> <n>",
+		Short: "Change the concurrency limit of a running instance",
+		Long: `Change the maximum number of concurrent requests a running instance serves.
+
+The model argument is the instance alias shown by 'xw ps'. The new limit takes
+effect immediately without restarting the instance; use 0 to remove the limit.
+
+Raising the limit admits queued requests right away. Lowering it below the
+number of requests in flight does not interrupt them: they complete normally,
+and new requests wait until the in-flight count falls below the new limit.
+
+The limit is kept until the instance is stopped and replaces the value given
+with --max-concurrent at start.`,
+		Example: `  # Allow 8 concurrent requests
+  xw config set-concurrency qwen3-32b 8
+
+  # Remove the limit
+  xw config set-concurrency qwen3-32b 0`,
+		Args: cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			n, err := strconv.Atoi(args[1])
+			if err != nil || n < 0 {
+				return fmt.Errorf("invalid concurrency limit %q: must be a non-negative integer", args[1])
+			}
+			return runConfigSetConcurrency(opts, args[0], n)
+		},
+	}
+
+	return cmd
+}
+
+// NewConfigValidateCommand creates the config validate subcommand.
+//
+// This command checks device and model configuration files locally, the
+// way the server checks them when loading, without contacting the server.
+//
+// Usage:
+//
+//	xw config validate [--devices PATH] [--models PATH]
+//
+// Returns:
+//   - A configured cobra.Command for validating configuration files
+func NewConfigValidateCommand(opts *ConfigOptions) *cobra.Command {
+	var devicesPath, modelsPath string
+
+	cmd := &cobra.Command{
+		Use:   "validate",
+		Short: "Validate device and model configuration files",
+		Long: `Validate devices.yaml and models.yaml files before deploying them.
+
+The files are checked the way the server checks them when loading, without
+contacting or restarting the server. Errors are problems that would make the
+server refuse the file; the command exits with an error if there are any.
+
+Warnings point at settings that load but are likely mistakes, such as model
+groups without members. When both files are given, models are also checked
+against the devices: a warning is shown for each model device that is not
+defined in the device configuration and for each docker engine without a
+runtime image for its device.`,
+		Example: `  # Validate both files of a new configuration version
+  xw config validate --devices ./devices.yaml --models ./models.yaml
+
+  # Validate only the model configuration
+  xw config validate --models ./models.yaml`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if devicesPath == "" && modelsPath == "" {
+				return fmt.Errorf("at least one of --devices or --models is required")
+			}
+			return runConfigValidate(devicesPath, modelsPath)
+		},
+	}
+
+	cmd.Flags().StringVar(&devicesPath, "devices", "", "path to the device configuration (devices.yaml)")
+	cmd.Flags().StringVar(&modelsPath, "models", "", "path to the model configuration (models.yaml)")
+
+	return cmd
+}
+
 // runConfigInfo executes the config info command logic.
 //
 // This function calls the server API to retrieve all configuration settings
@@ -232,6 +362,9 @@ func runConfigInfo(opts *ConfigOptions) error {
 	fmt.Println("Server Configuration:")
 	fmt.Printf("Name:           %s\n", config.Name)
 	fmt.Printf("Registry:       %s\n", config.Registry)
+	if config.RegistryMirror != "" {
+		fmt.Printf("Mirror:         %s\n", config.RegistryMirror)
+	}
 	fmt.Printf("Config Version: %s\n", config.ConfigVersion)
 	fmt.Printf("Host:           %s\n", config.Host)
 	fmt.Printf("Port:           %d\n", config.Port)
@@ -287,7 +420,63 @@ func runConfigSet(opts *ConfigOptions, key, value string) error {
 	}
 
 	fmt.Printf("✓ Configuration updated: %s = %s\n", key, value)
+	if key == "host" || key == "port" {
+		fmt.Println("Restart the server ('xw serve') to apply it; it keeps its current address until then.")
+	}
 
 	return nil
 }
 
+// runConfigSetConcurrency executes the config set-concurrency command logic.
+//
+// Parameters:
+//   - opts: Config command options
+//   - alias: Alias of the instance
+//   - maxConcurrent: New concurrency limit (0 for unlimited)
+//
+// Returns:
+//   - nil on success
+//   - error if API call fails or the instance is not found
+func runConfigSetConcurrency(opts *ConfigOptions, alias string, maxConcurrent int) error {
+	c := getClient(opts.GlobalOptions)
+
+	if err := c.SetInstanceConcurrency(alias, maxConcurrent); err != nil {
+		return fmt.Errorf("failed to set concurrency: %w", err)
+	}
+
+	if maxConcurrent == 0 {
+		fmt.Printf("✓ Concurrency limit removed: %s\n", alias)
+	} else {
+		fmt.Printf("✓ Concurrency limit updated: %s = %d\n", alias, maxConcurrent)
+	}
+
+	return nil
+}
+
+// runConfigValidate executes the config validate command logic.
+//
+// Parameters:
+//   - devicesPath: Path to the device configuration (empty to skip)
+//   - modelsPath: Path to the model configuration (empty to skip)
+//
+// Returns:
+//   - nil if the files have no errors (warnings are allowed)
+//   - error if any file has errors
+func runConfigValidate(devicesPath, modelsPath string) error {
+	report := config.ValidateConfigFiles(devicesPath, modelsPath)
+
+	for _, msg := range report.Errors {
+		fmt.Printf("✗ %s\n", msg)
+	}
+	for _, msg := range report.Warnings {
+		fmt.Printf("⚠ %s\n", msg)
+	}
+
+	if !report.OK() {
+		return fmt.Errorf("configuration has %d error(s) and %d warning(s)",
+			len(report.Errors), len(report.Warnings))
+	}
+
+	fmt.Printf("✓ Configuration is valid (%d warning(s))\n", len(report.Warnings))
+	return nil
+}
@@ -0,0 +1,125 @@
+package app
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+)
+
+// CpOptions holds options for the cp command
+type CpOptions struct {
+	*GlobalOptions
+
+	// Source is the model to copy
+	Source string
+
+	// Destination is the name of the new model
+	Destination string
+
+	// From is a local checkpoint directory the new model serves instead of
+	// MODEL's weights
+	From string
+}
+
+// NewCpCommand creates the cp command.
+//
+// The cp command creates a variant of a downloaded model: a new model name
+// serving the same weights with its own Modelfile.
+//
+// Usage:
+//
+//	xw cp MODEL NEWNAME
+//
+// Examples:
+//
+//	# Create a variant and give it its own system prompt
+//	xw cp qwen3-8b qwen3-8b-translator
+//	xw edit qwen3-8b-translator --set system="Translate the input to English."
+//
+//	# Serve a local fine-tuned checkpoint with the setup of qwen3-8b
+//	xw cp qwen3-8b my-finetune --from /data/models/my-finetune
+//
+// Parameters:
+//   - globalOpts: Global options shared across commands
+//
+// Returns:
+//   - A configured cobra.Command for copying models
+func NewCpCommand(globalOpts *GlobalOptions) *cobra.Command {
+	opts := &CpOptions{
+		GlobalOptions: globalOpts,
+	}
+
+	cmd := &cobra.Command{
+		Use:   "cp MODEL NEWNAME",
+		Short: "Copy a model's configuration under a new name",
+		Long: `Create a variant of a downloaded model under a new name.
+
+The new model serves the same weights as MODEL, which are not copied or
+downloaded again, with its own copy of MODEL's Modelfile. Change its system
+prompt or parameters with 'xw edit NEWNAME' and serve it with
+'xw start NEWNAME'; MODEL is not affected.
+
+The new model supports the same devices and engines as MODEL and is listed
+by 'xw ls'. Remove it with 'xw rm --model NEWNAME', which keeps the
+weights. NEWNAME may contain lower-case letters, digits, '.', '-' and
+'_', and must not be the name of another model.
+
+With --from PATH, the new model serves the local checkpoint in PATH (e.g., a
+fine-tune of MODEL) instead, without registering it in models.yaml. MODEL
+then only provides the device, engine and capability settings and does not
+have to be downloaded. PATH must contain a config.json and lie within the
+server's models directory or a directory listed in XW_MODEL_IMPORT_DIRS
+(separated by ':') in the server's environment, since it is mounted into
+the engine container.`,
+		Example: `  # Create a variant and give it its own system prompt
+  xw cp qwen3-8b qwen3-8b-translator
+  xw edit qwen3-8b-translator --set system="Translate the input to English."
+  xw start qwen3-8b-translator
+
+  # Run a local fine-tuned checkpoint with the setup of qwen3-8b
+  xw cp qwen3-8b my-finetune --from /data/models/my-finetune
+  xw start my-finetune`,
+		Args: cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			opts.Source = args[0]
+			opts.Destination = args[1]
+			return runCp(opts)
+		},
+	}
+
+	cmd.Flags().StringVar(&opts.From, "from", "",
+		"serve this local checkpoint directory instead of MODEL's weights")
+
+	return cmd
+}
+
+// runCp executes the cp command logic
+func runCp(opts *CpOptions) error {
+	client := getClient(opts.GlobalOptions)
+
+	from := opts.From
+	if from != "" {
+		abs, err := filepath.Abs(from)
+		if err != nil {
+			return fmt.Errorf("invalid path %s: %w", from, err)
+		}
+		from = abs
+	}
+
+	base, err := client.CopyModel(opts.Source, opts.Destination, from)
+	if err != nil {
+		return fmt.Errorf("failed to copy model: %w", err)
+	}
+
+	if from != "" {
+		fmt.Printf("Copied %s to %s (weights in %s)\n", opts.Source, opts.Destination, from)
+	} else if base != "" && base != opts.Source {
+		fmt.Printf("Copied %s to %s (weights of %s)\n", opts.Source, opts.Destination, base)
+	} else {
+		fmt.Printf("Copied %s to %s\n", opts.Source, opts.Destination)
+	}
+	fmt.Printf("Use 'xw edit %s' to change its configuration\n", opts.Destination)
+
+	return nil
+}
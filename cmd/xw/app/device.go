@@ -1,11 +1,15 @@
 package app
 
 import (
+	"encoding/json"
 	"fmt"
 	"os"
+	"strconv"
+	"strings"
 	"text/tabwriter"
 	
 	"github.com/spf13/cobra"
+	"github.com/tsingmaoai/xw-cli/cmd/xw/client"
 	"github.com/tsingmaoai/xw-cli/internal/logger"
 )
 
@@ -17,7 +21,11 @@ import (
 // Usage:
 //
 //	xw device list        # List detected AI chips on server
+//	xw device list --live # Include live utilization and memory
+//	xw device list --wide # Include the NUMA node of each chip
+//	xw device list -o json --type ascend-910b # Chips of one type as JSON
 //	xw device supported   # Show supported chip types
+//	xw device allocations # Show which instance uses each chip
 //
 // Parameters:
 //   - globalOpts: Global options shared across commands
@@ -35,13 +43,20 @@ on the server machine, checking their capabilities, and viewing supported models
 		Example: `  # List detected AI chips on server
   xw device list
 
+  # Include live utilization and memory usage per chip
+  xw device list --live
+
   # Show all supported chip models
-  xw device supported`,
+  xw device supported
+
+  # Show which instance each chip is allocated to
+  xw device allocations`,
 	}
 	
 	cmd.AddCommand(
 		newDeviceListCommand(globalOpts),
 		newDeviceSupportedCommand(globalOpts),
+		newDeviceAllocationsCommand(globalOpts),
 	)
 	
 	return cmd
@@ -49,28 +64,88 @@ on the server machine, checking their capabilities, and viewing supported models
 
 // newDeviceListCommand creates the 'device list' subcommand
 func newDeviceListCommand(globalOpts *GlobalOptions) *cobra.Command {
-	return &cobra.Command{
+	var live bool
+	var wide bool
+	var output string
+	var chipType string
+	
+	cmd := &cobra.Command{
 		Use:   "list",
 		Short: "List detected AI chips on server",
-		Long:  `Query the server and list all detected AI accelerator chips.`,
+		Long: `Query the server and list all detected AI accelerator chips.
+
+With --live, the server also reads current utilization and memory usage of
+each chip from the vendor monitoring tool (npu-smi for Ascend), which helps
+decide where to place a new instance. Chips whose tool is not available are
+shown as n/a.
+
+With --wide, the NUMA node each chip is attached to is also shown (- if
+the host does not report one). Instances using several chips are placed on
+a single NUMA node when the topology allows it.
+
+Use --type to list only the chips of one type, given as its chip key (the
+CHIP KEY column, e.g. ascend-910b) or variant key (e.g. ascend-910b1).
+
+Use -o json to print the chip list for scripts: one object per logical chip
+with its chip key, PCI address, vendor and device IDs, logical index
+(as used by 'xw start --device'), and, with --live, its metrics.`,
+		Example: `  # List detected chips
+  xw device list
+
+  # Show the NUMA node of each chip
+  xw device list --wide
+
+  # List the PCI addresses of the Ascend 910B chips as JSON
+  xw device list --type ascend-910b -o json`,
 		Args:  cobra.NoArgs,
 		RunE: func(cmd *cobra.Command, args []string) error {
+			if output != "table" && output != "json" {
+				return fmt.Errorf("invalid output format %q: must be table or json", output)
+			}
+			if wide && output == "json" {
+				return fmt.Errorf("--wide cannot be used with -o json")
+			}
+			
 			client := getClient(globalOpts)
 			
-			devices, err := client.ListDevices()
+			devices, err := client.ListDevices(live)
 			if err != nil {
 				return fmt.Errorf("failed to list devices: %w", err)
 			}
+			if chipType != "" {
+				devices = filterDevicesByType(devices, chipType)
+			}
+			
+			// JSON output is the server's detection data, filtered
+			if output == "json" {
+				return printDevicesJSON(devices)
+			}
 			
 			if len(devices) == 0 {
+				if chipType != "" {
+					fmt.Printf("No %s chips detected on the server.\n", chipType)
+					fmt.Println("\nTo see all detected chips, run: xw device list")
+					return nil
+				}
 				fmt.Println("No AI chips detected on the server.")
 				fmt.Println("\nTo see supported chips, run: xw device supported")
 				return nil
 			}
 			
 			w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
-			fmt.Fprintln(w, "CHIP KEY\tCHIP\t#\tPCI ADDRESS\tVENDOR:DEVICE")
-			fmt.Fprintln(w, "--------\t----\t-\t-----------\t-------------")
+			header := []string{"CHIP KEY", "CHIP", "#", "PCI ADDRESS", "VENDOR:DEVICE"}
+			if wide {
+				header = append(header, "NUMA")
+			}
+			if live {
+				header = append(header, "HEALTH", "UTIL", "MEMORY")
+			}
+			underline := make([]string, len(header))
+			for i, title := range header {
+				underline[i] = strings.Repeat("-", len([]rune(title)))
+			}
+			fmt.Fprintln(w, strings.Join(header, "\t"))
+			fmt.Fprintln(w, strings.Join(underline, "\t"))
 			
 			for _, device := range devices {
 				pciID := fmt.Sprintf("%s:%s", device.VendorID, device.DeviceID)
@@ -82,8 +157,19 @@ func newDeviceListCommand(globalOpts *GlobalOptions) *cobra.Command {
 					chipInfo = fmt.Sprintf("%d:%d", device.PhysicalDeviceIndex, device.ChipIndex)
 				}
 				
-				fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n",
-					device.DeviceType, device.ModelName, chipInfo, device.BusAddress, pciID)
+				row := []string{device.DeviceType, device.ModelName, chipInfo, device.BusAddress, pciID}
+				if wide {
+					numaNode := "-"
+					if node := device.Properties["numa_node"]; node != "" {
+						numaNode = node
+					}
+					row = append(row, numaNode)
+				}
+				if live {
+					health, util, memory := formatDeviceMetrics(device.Properties)
+					row = append(row, health, util, memory)
+				}
+				fmt.Fprintln(w, strings.Join(row, "\t"))
 			}
 			
 			w.Flush()
@@ -112,6 +198,64 @@ func newDeviceListCommand(globalOpts *GlobalOptions) *cobra.Command {
 			return nil
 		},
 	}
+	
+	cmd.Flags().BoolVar(&live, "live", false,
+		"include live utilization and memory usage from the vendor monitoring tool")
+	cmd.Flags().BoolVar(&wide, "wide", false, "also show the NUMA node of each chip")
+	cmd.Flags().StringVarP(&output, "output", "o", "table", "output format: table or json")
+	cmd.Flags().StringVar(&chipType, "type", "",
+		"only list chips of this type (chip key or variant key, e.g. ascend-910b)")
+	
+	return cmd
+}
+
+// printDevicesJSON prints chips as an indented JSON array ([] if none).
+func printDevicesJSON(devices []client.DeviceInfo) error {
+	if devices == nil {
+		devices = []client.DeviceInfo{}
+	}
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(devices)
+}
+
+// filterDevicesByType returns the chips whose chip key, device type, or
+// variant key is chipType (case-insensitive).
+func filterDevicesByType(devices []client.DeviceInfo, chipType string) []client.DeviceInfo {
+	var filtered []client.DeviceInfo
+	for _, device := range devices {
+		if strings.EqualFold(device.DeviceType, chipType) ||
+			strings.EqualFold(device.ConfigKey, chipType) ||
+			strings.EqualFold(device.VariantKey, chipType) {
+			filtered = append(filtered, device)
+		}
+	}
+	return filtered
+}
+
+// formatDeviceMetrics formats the live metric properties of a chip for
+// display, using "n/a" for metrics the server could not collect.
+//
+// Returns:
+//   - Health status (e.g., "OK")
+//   - Utilization (e.g., "37%")
+//   - Memory usage (e.g., "3.1/64.0 GB")
+func formatDeviceMetrics(props map[string]string) (string, string, string) {
+	health, util, memory := "n/a", "n/a", "n/a"
+	
+	if v := props["health"]; v != "" {
+		health = v
+	}
+	if v := props["utilization"]; v != "" {
+		util = v + "%"
+	}
+	used, errUsed := strconv.ParseFloat(props["memory_used_mb"], 64)
+	total, errTotal := strconv.ParseFloat(props["memory_total_mb"], 64)
+	if errUsed == nil && errTotal == nil && total > 0 {
+		memory = fmt.Sprintf("%.1f/%.1f GB", used/1024, total/1024)
+	}
+	
+	return health, util, memory
 }
 
 // newDeviceSupportedCommand creates the 'device supported' subcommand
@@ -157,3 +301,56 @@ running inference workloads.`,
 	return cmd
 }
 
+
+// newDeviceAllocationsCommand creates the 'device allocations' subcommand
+func newDeviceAllocationsCommand(globalOpts *GlobalOptions) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "allocations",
+		Short: "Show which instance each chip is allocated to",
+		Long: `Show the server's device allocation state: for every chip, the instance
+it is allocated to, or "-" if it is free.
+
+This is the view the server uses when placing a new instance, so it
+explains why a start fails with too few free chips. Chips reserved for an
+instance that is still starting are marked (pending).`,
+		Example: `  # Show chip allocations
+  xw device allocations`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client := getClient(globalOpts)
+			
+			resp, err := client.ListDeviceAllocations()
+			if err != nil {
+				return fmt.Errorf("failed to list device allocations: %w", err)
+			}
+			
+			if len(resp.Devices) == 0 {
+				fmt.Println("No AI chips detected on the server.")
+				return nil
+			}
+			
+			w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+			fmt.Fprintln(w, "INDEX\tCHIP KEY\tCHIP\tPCI ADDRESS\tINSTANCE")
+			fmt.Fprintln(w, "-----\t--------\t----\t-----------\t--------")
+			for _, dev := range resp.Devices {
+				instance := "-"
+				if dev.InstanceID != "" {
+					instance = dev.InstanceID
+					if dev.Pending {
+						instance += " (pending)"
+					}
+				}
+				fmt.Fprintf(w, "%d\t%s\t%s\t%s\t%s\n",
+					dev.Index, dev.Type, dev.ModelName, dev.BusAddress, instance)
+			}
+			w.Flush()
+			
+			fmt.Printf("\nTotal: %d chip(s), %d allocated, %d free\n",
+				len(resp.Devices), len(resp.Allocations), len(resp.Free))
+			
+			return nil
+		},
+	}
+	
+	return cmd
+}
@@ -0,0 +1,134 @@
+package app
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+	"github.com/tsingmaoai/xw-cli/internal/api"
+)
+
+// DoctorOptions holds options for the doctor command
+type DoctorOptions struct {
+	*GlobalOptions
+}
+
+// NewDoctorCommand creates the doctor command.
+//
+// The doctor command diagnoses common problems with the xw server and its
+// instances and prints a pass/fail report with hints on how to fix them.
+//
+// Usage:
+//
+//	xw doctor
+//
+// Parameters:
+//   - globalOpts: Global options shared across commands
+//
+// Returns:
+//   - A configured cobra.Command for running diagnostics
+func NewDoctorCommand(globalOpts *GlobalOptions) *cobra.Command {
+	opts := &DoctorOptions{
+		GlobalOptions: globalOpts,
+	}
+
+	cmd := &cobra.Command{
+		Use:   "doctor",
+		Short: "Diagnose problems with the server and instances",
+		Long: `Check the xw server and its environment for common problems.
+
+The following are checked, each reported as passed, warning or failed:
+  - The server is reachable
+  - Docker is installed and running
+  - AI accelerators are detected
+  - There is enough free disk space for models
+  - The configuration files in use are valid
+  - Each instance is ready, and why not if it is not
+
+Warnings and failures come with a hint on how to fix them. Run this first
+when a model does not start, stays starting, or does not answer chats.
+
+The command exits with an error if any check failed.`,
+		Example: `  # Diagnose the local server
+  xw doctor
+
+  # Diagnose a remote server
+  xw doctor --server http://gpu-host:11581`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runDoctor(opts)
+		},
+	}
+
+	return cmd
+}
+
+// runDoctor executes the doctor command logic.
+//
+// Parameters:
+//   - opts: Doctor command options
+//
+// Returns:
+//   - nil if no check failed
+//   - error if the server is unreachable or any check failed
+func runDoctor(opts *DoctorOptions) error {
+	client := getClient(opts.GlobalOptions)
+
+	checks := []api.DoctorCheck{{
+		Name:    "server",
+		Status:  api.DoctorPass,
+		Message: "Reachable at " + client.GetBaseURL(),
+	}}
+	if _, err := client.Health(); err != nil {
+		checks[0].Status = api.DoctorFail
+		// Keep the first line; the hint replaces the error's own advice
+		reason, _, _ := strings.Cut(err.Error(), "\n")
+		checks[0].Message = fmt.Sprintf("Not reachable: %s", reason)
+		checks[0].Hint = "Start it with 'xw serve', or point --server / XW_SERVER at a running server"
+	} else {
+		resp, err := client.Doctor()
+		if err != nil {
+			return fmt.Errorf("failed to run diagnostics: %w", err)
+		}
+		checks = append(checks, resp.Checks...)
+	}
+
+	return printDoctorReport(checks)
+}
+
+// printDoctorReport prints the checks with their hints and a summary.
+//
+// Returns:
+//   - error if any check failed
+func printDoctorReport(checks []api.DoctorCheck) error {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	var passed, warned, failed int
+	for _, check := range checks {
+		mark := "✓"
+		switch check.Status {
+		case api.DoctorPass:
+			passed++
+		case api.DoctorWarn:
+			mark = "⚠"
+			warned++
+		default:
+			mark = "✗"
+			failed++
+		}
+		fmt.Fprintf(w, "%s %s\t%s\n", mark, check.Name, check.Message)
+		if check.Hint != "" {
+			fmt.Fprintf(w, "\t→ %s\n", check.Hint)
+		}
+	}
+	w.Flush()
+
+	fmt.Println()
+	fmt.Printf("%d passed, %d warning(s), %d failed\n", passed, warned, failed)
+
+	if failed > 0 {
+		return fmt.Errorf("%d check(s) failed", failed)
+	}
+	return nil
+}
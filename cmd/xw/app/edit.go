@@ -0,0 +1,592 @@
+package app
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/tsingmaoai/xw-cli/internal/models"
+)
+
+// EditOptions holds options for the edit command
+type EditOptions struct {
+	*GlobalOptions
+
+	// Model is the model whose Modelfile is edited
+	Model string
+
+	// Set holds directive assignments in KEY=VALUE form (system, template)
+	Set []string
+
+	// SetParams holds PARAMETER assignments in KEY=VALUE form
+	SetParams []string
+
+	// Revert restores the most recent Modelfile backup instead of editing
+	Revert bool
+
+	// Yes applies changes without showing the confirmation prompt
+	Yes bool
+}
+
+// NewEditCommand creates the edit command.
+//
+// The edit command changes the editable configuration of a downloaded
+// model's Modelfile (system prompt, template, and inference parameters),
+// either in the user's editor or, for scripts and CI, with --set and
+// --set-param without opening one.
+//
+// Usage:
+//
+//	xw edit MODEL
+//	xw edit MODEL [--set KEY=VALUE]... [--set-param KEY=VALUE]...
+//	xw edit MODEL --revert
+//
+// Examples:
+//
+//	# Edit the configuration in $EDITOR
+//	xw edit qwen2.5-7b-instruct
+//
+//	# Change the system prompt
+//	xw edit qwen2.5-7b-instruct --set system="You are a concise assistant."
+//
+//	# Adjust sampling parameters
+//	xw edit qwen2.5-7b-instruct --set-param temperature=0.7 --set-param top_p=0.9
+//
+// Parameters:
+//   - globalOpts: Global options shared across commands
+//
+// Returns:
+//   - A configured cobra.Command for editing Modelfiles
+func NewEditCommand(globalOpts *GlobalOptions) *cobra.Command {
+	opts := &EditOptions{
+		GlobalOptions: globalOpts,
+	}
+
+	cmd := &cobra.Command{
+		Use:   "edit MODEL",
+		Short: "Edit the Modelfile of a model",
+		Long: `Edit the user-editable configuration in a model's Modelfile.
+
+The Modelfile is generated when a model is pulled. This command patches its
+FROM, SYSTEM prompt, TEMPLATE, and PARAMETER directives in place; LICENSE
+and comments are left untouched.
+
+Without --set or --set-param, these directives are opened in $VISUAL or
+$EDITOR (vi if neither is set). Saving and closing the editor applies the
+changes; invalid values can be corrected in the editor again.
+
+Directives (--set):
+  from=PATH        Serve the model from a local directory (absolute path,
+                   e.g., a fine-tuned checkpoint) instead of the download;
+                   applies the next time the model is started
+  from=MODEL       Serve the downloaded weights of another model
+  system=TEXT      System prompt (empty value removes it)
+  template=TEXT    Prompt template (empty value removes it)
+
+Parameters (--set-param):
+  KEY=VALUE        Set a PARAMETER, replacing existing values for KEY.
+                   Repeat the flag to set several values for one key
+                   (e.g., stop sequences). An empty VALUE removes KEY.
+
+Known parameters are range checked: temperature (0-2), top_p (0-1),
+top_k (>= 0), repeat_penalty (> 0), num_ctx (> 0).
+
+The SYSTEM prompt is used by 'xw chat'. Requests to the OpenAI-compatible
+endpoint that send the header "X-XW-Modelfile-Defaults: true" get the
+SYSTEM prompt and PARAMETER values wherever they do not set their own.
+
+A FROM path must contain a config.json and lie within the server's models
+directory or a directory listed in XW_MODEL_IMPORT_DIRS (separated by ':')
+in the server's environment, since it is mounted into the engine container.
+A FROM that cannot be served is rejected when saving and fails the start.
+
+Only models with a Modelfile can be edited. To run a local checkpoint
+without pulling a model first, create a model for it with
+'xw cp MODEL NEWNAME --from PATH', where MODEL provides the device and
+engine settings.
+
+Every save keeps a backup of the previous Modelfile (the last 5 per model).
+--revert restores the most recent backup; run it again to step further back.
+
+Before saving, the changes are shown as a diff and must be confirmed.
+Pass --yes to apply them without prompting (required in scripts).`,
+		Example: `  # Edit the configuration in $EDITOR
+  xw edit qwen2.5-7b-instruct
+
+  # Change the system prompt
+  xw edit qwen2.5-7b-instruct --set system="You are a concise assistant."
+
+  # Adjust sampling parameters without confirmation
+  xw edit qwen2.5-7b-instruct --set-param temperature=0.7 --set-param top_p=0.9 --yes
+
+  # Replace stop sequences
+  xw edit qwen2.5-7b-instruct --set-param stop="<|im_end|>" --set-param stop="<|endoftext|>"
+
+  # Serve a local fine-tuned checkpoint under this model's name
+  xw edit qwen2.5-7b-instruct --set from=/data/models/my-finetune
+
+  # Undo the last edit
+  xw edit qwen2.5-7b-instruct --revert`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			opts.Model = args[0]
+			return runEdit(opts)
+		},
+	}
+
+	cmd.Flags().StringArrayVar(&opts.Set, "set", nil,
+		"set a directive as KEY=VALUE (from, system, template; can be repeated)")
+	cmd.Flags().StringArrayVar(&opts.SetParams, "set-param", nil,
+		"set a PARAMETER as KEY=VALUE (can be repeated)")
+	cmd.Flags().BoolVar(&opts.Revert, "revert", false,
+		"restore the Modelfile saved before the last edit")
+	cmd.Flags().BoolVarP(&opts.Yes, "yes", "y", false,
+		"apply changes without asking for confirmation")
+
+	return cmd
+}
+
+// runEdit executes the edit command logic.
+//
+// The current Modelfile is fetched from the server, the requested changes
+// (or, without --set and --set-param, the changes made in the editor) are
+// applied to its editable config, and the merged result is sent back.
+//
+// Parameters:
+//   - opts: Edit command options
+//
+// Returns:
+//   - nil on success (including when nothing changed)
+//   - error if the Modelfile cannot be fetched, an assignment is invalid,
+//     the editor fails, or the update fails
+func runEdit(opts *EditOptions) error {
+	hasEdits := len(opts.Set) > 0 || len(opts.SetParams) > 0
+	if opts.Revert && hasEdits {
+		return fmt.Errorf("--revert cannot be combined with --set or --set-param")
+	}
+
+	client := getClient(opts.GlobalOptions)
+
+	if opts.Revert {
+		backup, remaining, err := client.RevertModelfile(opts.Model)
+		if err != nil {
+			return fmt.Errorf("failed to revert Modelfile: %w", err)
+		}
+		fmt.Printf("Restored the Modelfile of %s from %s (%d older backup(s) left)\n", opts.Model, backup, remaining)
+		return nil
+	}
+
+	modelInfo, err := client.GetModel(opts.Model)
+	if err != nil {
+		return fmt.Errorf("failed to get model info: %w", err)
+	}
+
+	modelfile, ok := modelInfo["modelfile"].(string)
+	if !ok || modelfile == "" {
+		return fmt.Errorf("Modelfile not found for %s, pull the model first", opts.Model)
+	}
+
+	original := models.ParseEditableConfig(modelfile)
+	var edited *models.EditableConfig
+	if hasEdits {
+		edited, err = applyEditAssignments(original, opts.Set, opts.SetParams)
+		if err != nil {
+			return err
+		}
+		if err := validateEditableConfig(edited); err != nil {
+			return err
+		}
+	} else {
+		edited, err = editInEditor(opts.Model, original)
+		if err != nil {
+			return err
+		}
+	}
+
+	if original.Equal(edited) {
+		fmt.Printf("No changes to the Modelfile of %s\n", opts.Model)
+		return nil
+	}
+
+	merged := models.MergeEditableConfig(modelfile, edited)
+
+	fmt.Print(unifiedDiff(modelfile, merged, "Modelfile (current)", "Modelfile (edited)"))
+	if !opts.Yes {
+		fmt.Print("Apply these changes? [y/N]: ")
+		reader := bufio.NewReader(os.Stdin)
+		response, err := reader.ReadString('\n')
+		if err != nil {
+			return fmt.Errorf("failed to read user input (use --yes to skip confirmation): %w", err)
+		}
+		response = strings.TrimSpace(strings.ToLower(response))
+		if response != "y" && response != "yes" {
+			fmt.Println("Edit cancelled.")
+			return nil
+		}
+	}
+
+	if err := client.UpdateModelfile(opts.Model, merged); err != nil {
+		return fmt.Errorf("failed to update Modelfile: %w", err)
+	}
+
+	fmt.Printf("Updated the Modelfile of %s\n", opts.Model)
+	return nil
+}
+
+// editInEditor lets the user edit cfg in their editor.
+//
+// The editable directives are written to a temporary file and opened in
+// $VISUAL or $EDITOR. When the edited file does not validate, the error is
+// shown and the user may open it again to correct it.
+//
+// Parameters:
+//   - model: Model being edited, named in the file header
+//   - cfg: Current editable config (not modified)
+//
+// Returns:
+//   - The edited config
+//   - Error if the editor fails or the user gives up on an invalid edit
+func editInEditor(model string, cfg *models.EditableConfig) (*models.EditableConfig, error) {
+	f, err := os.CreateTemp("", "xw-edit-*.Modelfile")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create temporary file: %w", err)
+	}
+	path := f.Name()
+	defer os.Remove(path)
+
+	_, err = f.WriteString(formatEditableConfig(model, cfg))
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to write temporary file: %w", err)
+	}
+
+	reader := bufio.NewReader(os.Stdin)
+	for {
+		if err := runEditor(path); err != nil {
+			return nil, err
+		}
+
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read edited file: %w", err)
+		}
+		edited := models.ParseEditableConfig(string(data))
+		err = validateEditableConfig(edited)
+		if err == nil && edited.From == "" && cfg.From != "" {
+			err = fmt.Errorf("FROM cannot be empty")
+		}
+		if err == nil {
+			return edited, nil
+		}
+
+		fmt.Printf("Invalid configuration: %v\nEdit again? [Y/n]: ", err)
+		response, readErr := reader.ReadString('\n')
+		response = strings.TrimSpace(strings.ToLower(response))
+		if readErr != nil || response == "n" || response == "no" {
+			return nil, err
+		}
+	}
+}
+
+// formatEditableConfig renders the editable directives of a Modelfile for
+// editing, after a comment header explaining the file.
+func formatEditableConfig(model string, cfg *models.EditableConfig) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# Editable configuration of %s. Lines starting with '#' are ignored.\n", model)
+	b.WriteString("# FROM, TEMPLATE, SYSTEM, and PARAMETER are saved; other directives in\n")
+	b.WriteString("# the Modelfile are kept unchanged. Remove a directive to delete it.\n")
+
+	if cfg.From != "" {
+		fmt.Fprintf(&b, "\nFROM %s\n", cfg.From)
+	}
+	if cfg.Template != "" {
+		fmt.Fprintf(&b, "\n%s\n", models.FormatModelfileDirective("TEMPLATE", cfg.Template))
+	}
+	if cfg.System != "" {
+		fmt.Fprintf(&b, "\n%s\n", models.FormatModelfileDirective("SYSTEM", cfg.System))
+	}
+	if len(cfg.Parameters) > 0 {
+		b.WriteString("\n")
+		for _, p := range cfg.Parameters {
+			fmt.Fprintf(&b, "%s\n", models.FormatModelfileParameter(p))
+		}
+	}
+	return b.String()
+}
+
+// runEditor opens path in $VISUAL or $EDITOR (vi if neither is set) and
+// waits for the editor to exit. The variable may include arguments, e.g.
+// "code --wait".
+func runEditor(path string) error {
+	editor := strings.TrimSpace(os.Getenv("VISUAL"))
+	if editor == "" {
+		editor = strings.TrimSpace(os.Getenv("EDITOR"))
+	}
+	if editor == "" {
+		editor = "vi"
+	}
+
+	args := strings.Fields(editor)
+	cmd := exec.Command(args[0], append(args[1:], path)...)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("editor %s failed (set $EDITOR or use --set): %w", args[0], err)
+	}
+	return nil
+}
+
+// applyEditAssignments returns a copy of cfg with --set and --set-param
+// assignments applied.
+//
+// Parameters:
+//   - cfg: Current editable config (not modified)
+//   - sets: Directive assignments in KEY=VALUE form
+//   - setParams: PARAMETER assignments in KEY=VALUE form
+//
+// Returns:
+//   - The edited config
+//   - Error if an assignment is malformed or names an unknown directive
+func applyEditAssignments(cfg *models.EditableConfig, sets, setParams []string) (*models.EditableConfig, error) {
+	edited := &models.EditableConfig{
+		From:       cfg.From,
+		System:     cfg.System,
+		Template:   cfg.Template,
+		Parameters: append([]models.ModelfileParameter(nil), cfg.Parameters...),
+	}
+
+	for _, assignment := range sets {
+		key, value, ok := strings.Cut(assignment, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid --set %q: expected KEY=VALUE", assignment)
+		}
+		switch strings.ToLower(strings.TrimSpace(key)) {
+		case "from":
+			if value == "" {
+				return nil, fmt.Errorf("invalid --set %q: FROM cannot be empty", assignment)
+			}
+			edited.From = value
+		case "system":
+			edited.System = value
+		case "template":
+			edited.Template = value
+		default:
+			return nil, fmt.Errorf("invalid --set %q: unknown directive %q (supported: from, system, template)", assignment, key)
+		}
+	}
+
+	// Collect values per key first so repeated flags for one key replace
+	// the existing values together instead of overwriting each other.
+	var keys []string
+	values := make(map[string][]string)
+	for _, assignment := range setParams {
+		key, value, ok := strings.Cut(assignment, "=")
+		key = strings.TrimSpace(key)
+		if !ok || key == "" {
+			return nil, fmt.Errorf("invalid --set-param %q: expected KEY=VALUE", assignment)
+		}
+		if _, seen := values[key]; !seen {
+			keys = append(keys, key)
+			values[key] = nil
+		}
+		if value != "" {
+			values[key] = append(values[key], value)
+		}
+	}
+	for _, key := range keys {
+		edited.Parameters = setParameter(edited.Parameters, key, values[key])
+	}
+
+	return edited, nil
+}
+
+// setParameter replaces all values of key with values, keeping the position
+// of the first existing entry. New keys are appended; an empty values list
+// removes the key.
+func setParameter(params []models.ModelfileParameter, key string, values []string) []models.ModelfileParameter {
+	result := make([]models.ModelfileParameter, 0, len(params)+len(values))
+	inserted := false
+	for _, p := range params {
+		if p.Key != key {
+			result = append(result, p)
+			continue
+		}
+		if !inserted {
+			for _, v := range values {
+				result = append(result, models.ModelfileParameter{Key: key, Value: v})
+			}
+			inserted = true
+		}
+	}
+	if !inserted {
+		for _, v := range values {
+			result = append(result, models.ModelfileParameter{Key: key, Value: v})
+		}
+	}
+	return result
+}
+
+// validateEditableConfig checks an edited config before it is saved.
+//
+// Parameters:
+//   - cfg: Config to validate
+//
+// Returns:
+//   - Error describing the first invalid value
+func validateEditableConfig(cfg *models.EditableConfig) error {
+	for _, p := range cfg.Parameters {
+		if strings.ContainsAny(p.Key, " \t\n") {
+			return fmt.Errorf("invalid parameter name %q", p.Key)
+		}
+		if strings.Contains(p.Value, "\n") {
+			return fmt.Errorf("parameter %s: value must be a single line", p.Key)
+		}
+
+		switch p.Key {
+		case "temperature":
+			if err := checkNumberRange(p, 0, 2, true); err != nil {
+				return err
+			}
+		case "top_p":
+			if err := checkNumberRange(p, 0, 1, true); err != nil {
+				return err
+			}
+		case "repeat_penalty":
+			if err := checkNumberRange(p, 0, 0, false); err != nil {
+				return err
+			}
+		case "top_k":
+			if n, ok := p.TypedValue().(int); !ok || n < 0 {
+				return fmt.Errorf("parameter top_k must be a non-negative integer, got %q", p.Value)
+			}
+		case "num_ctx":
+			if n, ok := p.TypedValue().(int); !ok || n <= 0 {
+				return fmt.Errorf("parameter num_ctx must be a positive integer, got %q", p.Value)
+			}
+		}
+	}
+
+	return nil
+}
+
+// checkNumberRange checks that a parameter is a number in [min, max], or
+// greater than min when bounded is false.
+func checkNumberRange(p models.ModelfileParameter, min, max float64, bounded bool) error {
+	var v float64
+	switch typed := p.TypedValue().(type) {
+	case int:
+		v = float64(typed)
+	case float64:
+		v = typed
+	default:
+		return fmt.Errorf("parameter %s must be a number, got %q", p.Key, p.Value)
+	}
+	if bounded && (v < min || v > max) {
+		return fmt.Errorf("parameter %s must be between %g and %g, got %g", p.Key, min, max, v)
+	}
+	if !bounded && v <= min {
+		return fmt.Errorf("parameter %s must be greater than %g, got %g", p.Key, min, v)
+	}
+	return nil
+}
+
+// diffContextLines is the number of unchanged lines shown around changes.
+const diffContextLines = 3
+
+// diffOp is a single line of a line-based diff.
+type diffOp struct {
+	kind    byte // ' ' (unchanged), '-' (removed), or '+' (added)
+	line    string
+	oldLine int // 0-based line in the old text before this op
+	newLine int // 0-based line in the new text before this op
+}
+
+// unifiedDiff renders the line differences between two texts in unified
+// diff format. It returns an empty string when the texts are equal.
+//
+// Parameters:
+//   - oldText: Original text
+//   - newText: Changed text
+//   - oldName: Label for the original text in the header
+//   - newName: Label for the changed text in the header
+//
+// Returns:
+//   - The diff, ending with a newline, or "" if there are no differences
+func unifiedDiff(oldText, newText, oldName, newName string) string {
+	a := strings.Split(oldText, "\n")
+	b := strings.Split(newText, "\n")
+
+	// Longest common subsequence lengths of the suffixes a[i:], b[j:]
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	var ops []diffOp
+	var changed []int
+	for i, j := 0, 0; i < len(a) || j < len(b); {
+		switch {
+		case i < len(a) && j < len(b) && a[i] == b[j]:
+			ops = append(ops, diffOp{' ', a[i], i, j})
+			i++
+			j++
+		case i < len(a) && (j == len(b) || lcs[i+1][j] >= lcs[i][j+1]):
+			changed = append(changed, len(ops))
+			ops = append(ops, diffOp{'-', a[i], i, j})
+			i++
+		default:
+			changed = append(changed, len(ops))
+			ops = append(ops, diffOp{'+', b[j], i, j})
+			j++
+		}
+	}
+
+	if len(changed) == 0 {
+		return ""
+	}
+
+	var out strings.Builder
+	fmt.Fprintf(&out, "--- %s\n+++ %s\n", oldName, newName)
+
+	for k := 0; k < len(changed); {
+		// Extend the hunk while the next change is within the context window
+		first, last := changed[k], changed[k]
+		for k++; k < len(changed) && changed[k]-last <= 2*diffContextLines; k++ {
+			last = changed[k]
+		}
+		start := max(0, first-diffContextLines)
+		end := min(len(ops), last+diffContextLines+1)
+
+		oldCount, newCount := 0, 0
+		for _, op := range ops[start:end] {
+			if op.kind != '+' {
+				oldCount++
+			}
+			if op.kind != '-' {
+				newCount++
+			}
+		}
+		fmt.Fprintf(&out, "@@ -%d,%d +%d,%d @@\n", ops[start].oldLine+1, oldCount, ops[start].newLine+1, newCount)
+		for _, op := range ops[start:end] {
+			fmt.Fprintf(&out, "%c%s\n", op.kind, op.line)
+		}
+	}
+
+	return out.String()
+}
@@ -18,6 +18,9 @@ type LogsOptions struct {
 
 	// Follow continues streaming logs in real-time
 	Follow bool
+
+	// Tail limits output to the last N lines (0 for all)
+	Tail int
 }
 
 // NewLogsCommand creates the logs command.
@@ -36,6 +39,9 @@ type LogsOptions struct {
 //	# Follow logs in real-time (like tail -f)
 //	xw logs my-model -f
 //
+//	# Show only the last 100 lines
+//	xw logs my-model --tail 100
+//
 // Parameters:
 //   - globalOpts: Global options shared across commands
 //
@@ -60,8 +66,8 @@ Examples:
   # Follow logs in real-time (press Ctrl+C to stop)
   xw logs my-model -f
   
-  # Shorter version with -f
-  xw logs my-model -f`,
+  # Show the last 100 lines, then keep following
+  xw logs my-model --tail 100 -f`,
 		Args: func(cmd *cobra.Command, args []string) error {
 			if len(args) == 0 {
 				// Show help when no arguments provided
@@ -81,6 +87,8 @@ Examples:
 
 	cmd.Flags().BoolVarP(&opts.Follow, "follow", "f", false,
 		"follow log output (stream logs in real-time)")
+	cmd.Flags().IntVarP(&opts.Tail, "tail", "n", 0,
+		"number of lines to show from the end of the logs (0 for all)")
 
 	return cmd
 }
@@ -89,6 +97,10 @@ Examples:
 func runLogs(opts *LogsOptions) error {
 	client := getClient(opts.GlobalOptions)
 
+	if opts.Tail < 0 {
+		return fmt.Errorf("invalid --tail value: %d (must be >= 0)", opts.Tail)
+	}
+
 	// Setup signal handler for Ctrl+C when following
 	if opts.Follow {
 		sigChan := make(chan os.Signal, 1)
@@ -97,7 +109,7 @@ func runLogs(opts *LogsOptions) error {
 		// Start log streaming in a goroutine
 		logDone := make(chan error, 1)
 		go func() {
-			err := client.StreamInstanceLogs(opts.Alias, true, func(logLine string) {
+			err := client.StreamInstanceLogs(opts.Alias, true, opts.Tail, func(logLine string) {
 				fmt.Print(logLine)
 				// Force flush stdout for real-time output
 				os.Stdout.Sync()
@@ -120,7 +132,7 @@ func runLogs(opts *LogsOptions) error {
 	}
 
 	// Non-follow mode: just get existing logs
-	err := client.StreamInstanceLogs(opts.Alias, false, func(logLine string) {
+	err := client.StreamInstanceLogs(opts.Alias, false, opts.Tail, func(logLine string) {
 		fmt.Print(logLine)
 		os.Stdout.Sync()
 	})
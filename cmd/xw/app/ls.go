@@ -1,6 +1,7 @@
 package app
 
 import (
+	"encoding/json"
 	"fmt"
 	"os"
 	"sort"
@@ -15,7 +16,10 @@ import (
 // ListOptions holds options for the list command
 type ListOptions struct {
 	*GlobalOptions
-	All bool // Show all models supported by current device
+	All          bool     // Show all models supported by current device
+	Output       string   // Output format: table or json
+	Capabilities []string // Only show models with all of these capabilities
+	Group        string   // Only show members of this model group
 }
 
 // NewListCommand creates the list (ls) command.
@@ -38,6 +42,15 @@ type ListOptions struct {
 //	# List models compatible with Ascend devices
 //	xw ls -d ascend
 //
+//	# Emit models and device statistics as JSON
+//	xw ls -a -o json
+//
+//	# List multimodal models
+//	xw ls -a --capability vision
+//
+//	# List all members of the qwen2 model group
+//	xw ls -a --group qwen2
+//
 // Parameters:
 //   - globalOpts: Global options shared across commands
 //
@@ -55,7 +68,16 @@ func NewListCommand(globalOpts *GlobalOptions) *cobra.Command {
 		Long: `List models that have been downloaded.
 
 By default, only shows models that are currently downloaded and available locally.
-Use -a/--all to show all models supported by the current chip.`,
+Use -a/--all to show all models supported by the current chip.
+
+Use --capability to show only models with a given capability (e.g., vision,
+tool_use). Repeat the flag to require several capabilities at once.
+
+Use --group to show only the members of a model group, as defined under
+model_groups in models.yaml.
+
+Use -o json to print the full model list, including download status, disk
+size, model totals, and detected devices, as JSON for scripting.`,
 		Example: `  # List downloaded models
   xw ls
   
@@ -63,7 +85,16 @@ Use -a/--all to show all models supported by the current chip.`,
   xw ls -a
   
   # Same as ls
-  xw list`,
+  xw list
+
+  # All multimodal models
+  xw ls -a --capability vision
+
+  # All members of the qwen2 model group
+  xw ls -a --group qwen2
+
+  # All models as JSON
+  xw ls -a -o json`,
 		Args: cobra.NoArgs,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			return runList(opts)
@@ -71,6 +102,11 @@ Use -a/--all to show all models supported by the current chip.`,
 	}
 
 	cmd.Flags().BoolVarP(&opts.All, "all", "a", false, "show all models supported by current chip")
+	cmd.Flags().StringVarP(&opts.Output, "output", "o", "table", "output format: table or json")
+	cmd.Flags().StringArrayVar(&opts.Capabilities, "capability", nil,
+		"only show models with this capability (repeatable, all must match)")
+	cmd.Flags().StringVar(&opts.Group, "group", "",
+		"only show members of this model group (see model_groups in models.yaml)")
 
 	return cmd
 }
@@ -89,9 +125,17 @@ Use -a/--all to show all models supported by the current chip.`,
 func runList(opts *ListOptions) error {
 	client := getClient(opts.GlobalOptions)
 
+	switch opts.Output {
+	case "table":
+	case "json":
+		return listModelsJSON(client, opts.All, opts.Capabilities, opts.Group)
+	default:
+		return fmt.Errorf("invalid output format %q: must be table or json", opts.Output)
+	}
+
 	if opts.All {
 		// List all models supported by current chip
-		return listAllModels(client)
+		return listAllModels(client, opts.Capabilities, opts.Group)
 	}
 
 	// Query downloaded models from server
@@ -99,7 +143,42 @@ func runList(opts *ListOptions) error {
 	if err != nil {
 		return fmt.Errorf("failed to list models: %w", err)
 	}
+	
+	// Downloaded models carry no capabilities or groups; keep those the
+	// registry matches
+	if len(opts.Capabilities) > 0 || opts.Group != "" {
+		resp, err := client.QueryModels(api.ListModelsRequest{
+			DeviceType:   api.DeviceTypeAll,
+			ShowAll:      true,
+			Capabilities: opts.Capabilities,
+			Group:        opts.Group,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to list models: %w", err)
+		}
+		matching := make(map[string]bool, len(resp.Models))
+		for _, model := range resp.Models {
+			matching[model.Name] = true
+		}
+		filtered := models[:0]
+		for _, model := range models {
+			if matching[model.ID] {
+				filtered = append(filtered, model)
+			}
+		}
+		models = filtered
+	}
 
+	if len(models) == 0 && opts.Group != "" {
+		fmt.Printf("No models of group %s downloaded.\n", opts.Group)
+		fmt.Println()
+		fmt.Printf("Download them with: xw pull --group %s\n", opts.Group)
+		return nil
+	}
+	if len(models) == 0 && len(opts.Capabilities) > 0 {
+		fmt.Println("No downloaded models match the requested capabilities.")
+		return nil
+	}
 	if len(models) == 0 {
 		fmt.Println("No models downloaded.")
 		fmt.Println()
@@ -153,10 +232,51 @@ func runList(opts *ListOptions) error {
 	return nil
 }
 
-// listAllModels lists all models supported by the current chip.
-func listAllModels(c *client.Client) error {
+// listModelsJSON writes the model list response to stdout as JSON.
+//
+// Without all, only downloaded models are included in the models list,
+// matching the default table; totals and detected devices are unchanged.
+func listModelsJSON(c *client.Client, all bool, capabilities []string, group string) error {
+	resp, err := c.QueryModels(api.ListModelsRequest{
+		DeviceType:   api.DeviceTypeAll,
+		ShowAll:      true,
+		Capabilities: capabilities,
+		Group:        group,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to list models: %w", err)
+	}
+
+	if !all {
+		downloaded := make([]api.Model, 0, len(resp.Models))
+		for _, model := range resp.Models {
+			if model.Status == "downloaded" {
+				downloaded = append(downloaded, model)
+			}
+		}
+		resp.Models = downloaded
+	}
+
+	sort.Slice(resp.Models, func(i, j int) bool {
+		return resp.Models[i].Name < resp.Models[j].Name
+	})
+
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(resp)
+}
+
+// listAllModels lists all models supported by the current chip, restricted
+// to those with all of the given capabilities and in the given model group
+// (if any).
+func listAllModels(c *client.Client, capabilities []string, group string) error {
 	// Get all models from registry with showAll=true to include unsupported models
-	resp, err := c.ListModelsWithStats(api.DeviceTypeAll, true)
+	resp, err := c.QueryModels(api.ListModelsRequest{
+		DeviceType:   api.DeviceTypeAll,
+		ShowAll:      true,
+		Capabilities: capabilities,
+		Group:        group,
+	})
 	if err != nil {
 		return fmt.Errorf("failed to list models: %w", err)
 	}
@@ -252,7 +372,11 @@ func printModelRow(w *tabwriter.Writer, model api.Model) {
 	// Size: only show for downloaded models
 	sizeStr := "-"
 	if model.Status == "downloaded" && model.ModifiedAt != "" {
-		sizeStr = formatSize(model.Size)
+		size := model.DiskSize
+		if size == 0 {
+			size = model.Size
+		}
+		sizeStr = formatSize(size)
 	}
 	
 	engine := model.DefaultEngine
@@ -1,8 +1,14 @@
 package app
 
 import (
+	"bytes"
+	"encoding/json"
 	"fmt"
+	"io"
 	"os"
+	"os/signal"
+	"strings"
+	"syscall"
 	"text/tabwriter"
 	"time"
 
@@ -15,6 +21,18 @@ type PsOptions struct {
 
 	// All shows all instances (including stopped)
 	All bool
+
+	// Output is the output format: table, wide, or json
+	Output string
+
+	// Wide is shorthand for -o wide
+	Wide bool
+
+	// Watch refreshes the table until interrupted
+	Watch bool
+
+	// Interval is the refresh interval in watch mode
+	Interval time.Duration
 }
 
 // NewPsCommand creates the ps command.
@@ -33,6 +51,15 @@ type PsOptions struct {
 //	# List all instances (including stopped)
 //	xw ps --all
 //
+//	# Show devices and endpoint URLs
+//	xw ps -o wide
+//
+//	# Emit the full instance list for scripting
+//	xw ps -o json
+//
+//	# Watch instances warm up
+//	xw ps -w
+//
 // Parameters:
 //   - globalOpts: Global options shared across commands
 //
@@ -49,9 +76,30 @@ func NewPsCommand(globalOpts *GlobalOptions) *cobra.Command {
 		Aliases: []string{"list"},
 		Long: `List all model instances with their status and configuration.
 
-Shows all instances including both running and stopped ones.`,
+Shows all instances including both running and stopped ones.
+
+Output formats (--output, -o):
+  table  Default table
+  wide   Table with additional DEVICES and ENDPOINT columns
+  json   Full instance list as JSON, including port, device indices, and state
+
+--wide is the same as -o wide. The DEVICES column lists the chips allocated
+to each instance; a chip held by more than one active instance is marked
+with '!', as such overlaps slow both instances down or make them fail.
+
+Use -w/--watch to refresh the table in place every --interval until Ctrl+C,
+e.g. to watch an instance go from starting to running.`,
 		Example: `  # List all instances
-  xw ps`,
+  xw ps
+
+  # Show devices and endpoint URLs
+  xw ps --wide
+
+  # Emit the full instance list for scripting
+  xw ps -o json
+
+  # Watch instances warm up
+  xw ps -w`,
 		Args: cobra.NoArgs,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			return runPs(opts)
@@ -60,12 +108,43 @@ Shows all instances including both running and stopped ones.`,
 
 	cmd.Flags().BoolVarP(&opts.All, "all", "a", true,
 		"show all instances (default: true)")
+	cmd.Flags().StringVarP(&opts.Output, "output", "o", "table",
+		"output format: table, wide, or json")
+	cmd.Flags().BoolVar(&opts.Wide, "wide", false,
+		"show DEVICES and ENDPOINT columns (same as -o wide)")
+	cmd.Flags().BoolVarP(&opts.Watch, "watch", "w", false,
+		"refresh the table until interrupted")
+	cmd.Flags().DurationVar(&opts.Interval, "interval", 2*time.Second,
+		"refresh interval for --watch")
 
 	return cmd
 }
 
 // runPs executes the ps command logic
 func runPs(opts *PsOptions) error {
+	if opts.Wide {
+		if opts.Output == "json" {
+			return fmt.Errorf("--wide cannot be used with -o json")
+		}
+		opts.Output = "wide"
+	}
+
+	switch opts.Output {
+	case "table", "wide", "json":
+	default:
+		return fmt.Errorf("invalid output format %q: must be table, wide, or json", opts.Output)
+	}
+
+	if opts.Watch {
+		if opts.Output == "json" {
+			return fmt.Errorf("--watch cannot be used with -o json")
+		}
+		if opts.Interval <= 0 {
+			return fmt.Errorf("--interval must be positive")
+		}
+		return runPsWatch(opts)
+	}
+
 	client := getClient(opts.GlobalOptions)
 
 	// Get instances from server
@@ -74,6 +153,13 @@ func runPs(opts *PsOptions) error {
 		return fmt.Errorf("failed to list instances: %w", err)
 	}
 
+	// JSON output passes the server's instance list through unchanged
+	if opts.Output == "json" {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(instances)
+	}
+
 	if len(instances) == 0 {
 		fmt.Println("No instances found")
 		fmt.Println()
@@ -81,9 +167,82 @@ func runPs(opts *PsOptions) error {
 		return nil
 	}
 
-	// Display instances in a table
-	w := tabwriter.NewWriter(os.Stdout, 0, 0, 3, ' ', 0)
-	fmt.Fprintln(w, "ALIAS\tMODEL\tENGINE\tLOCAL PORT\tCONTAINER ID\tSTATE\tUPTIME")
+	printInstanceTable(os.Stdout, instances, opts.Output == "wide")
+
+	return nil
+}
+
+// runPsWatch refreshes the instance table in place until interrupted.
+//
+// Each refresh moves the cursor back to the top of the previous table and
+// redraws it, like the image pull progress display, so state transitions
+// and uptime update live without scrolling.
+func runPsWatch(opts *PsOptions) error {
+	client := getClient(opts.GlobalOptions)
+
+	sigChan := make(chan os.Signal, 1)
+	signal.Notify(sigChan, os.Interrupt, syscall.SIGTERM)
+	defer signal.Stop(sigChan)
+
+	ticker := time.NewTicker(opts.Interval)
+	defer ticker.Stop()
+
+	lastLineCount := 0
+	for {
+		var buf bytes.Buffer
+		fmt.Fprintf(&buf, "Every %v: xw ps    %s\n\n", opts.Interval, time.Now().Format("15:04:05"))
+
+		instances, err := client.ListInstances(opts.All)
+		switch {
+		case err != nil:
+			fmt.Fprintf(&buf, "Failed to list instances: %v\n", err)
+		case len(instances) == 0:
+			fmt.Fprintln(&buf, "No instances found")
+		default:
+			printInstanceTable(&buf, instances, opts.Output == "wide")
+		}
+
+		// Move cursor up to the start of the previous table
+		if lastLineCount > 0 {
+			fmt.Printf("\033[%dA", lastLineCount)
+		}
+
+		lines := strings.Split(strings.TrimRight(buf.String(), "\n"), "\n")
+		for _, line := range lines {
+			// Clear line and print
+			fmt.Print("\r\033[K")
+			fmt.Println(line)
+		}
+		// Clear leftover lines when the table shrinks
+		fmt.Print("\033[J")
+
+		lastLineCount = len(lines)
+		os.Stdout.Sync()
+
+		select {
+		case <-sigChan:
+			// User pressed Ctrl+C
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// printInstanceTable writes instances as a table. In wide mode, DEVICES
+// and ENDPOINT columns are added, and devices shared by several active
+// instances are marked.
+func printInstanceTable(out io.Writer, instances []interface{}, wide bool) {
+	var shared map[int]bool
+	if wide {
+		shared = sharedDevices(instances)
+	}
+
+	w := tabwriter.NewWriter(out, 0, 0, 3, ' ', 0)
+	if wide {
+		fmt.Fprintln(w, "ALIAS\tMODEL\tENGINE\tLOCAL PORT\tCONTAINER ID\tSTATE\tUPTIME\tDEVICES\tENDPOINT")
+	} else {
+		fmt.Fprintln(w, "ALIAS\tMODEL\tENGINE\tLOCAL PORT\tCONTAINER ID\tSTATE\tUPTIME")
+	}
 
 	for _, instance := range instances {
 		instanceMap, ok := instance.(map[string]interface{})
@@ -100,6 +259,10 @@ func runPs(opts *PsOptions) error {
 		backendType, _ := instanceMap["backend_type"].(string)
 		deploymentMode, _ := instanceMap["deployment_mode"].(string)
 		state, _ := instanceMap["state"].(string)
+		// Explain why a starting instance is not serving yet
+		if healthErr, _ := instanceMap["health_error"].(string); state == "starting" && healthErr != "" {
+			state = fmt.Sprintf("warming up (health check failing: %s)", healthErr)
+		}
 
 		// Combine backend and mode into engine format
 		engine := fmt.Sprintf("%s:%s", backendType, deploymentMode)
@@ -136,7 +299,7 @@ func runPs(opts *PsOptions) error {
 			containerID = "-"
 		}
 
-		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%s\n",
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%s",
 			alias,
 			modelID,
 			engine,
@@ -144,11 +307,79 @@ func runPs(opts *PsOptions) error {
 			containerID,
 			state,
 			uptime)
+
+		if wide {
+			// Get allocated device indices
+			devices := "-"
+			if indices := instanceDeviceIndices(instanceMap); len(indices) > 0 {
+				parts := make([]string, 0, len(indices))
+				for _, idx := range indices {
+					part := fmt.Sprintf("%d", idx)
+					if shared[idx] {
+						part += "!"
+					}
+					parts = append(parts, part)
+				}
+				devices = strings.Join(parts, ",")
+			}
+
+			endpoint, _ := instanceMap["endpoint"].(string)
+			if endpoint == "" {
+				endpoint = "-"
+			}
+
+			fmt.Fprintf(w, "\t%s\t%s", devices, endpoint)
+		}
+		fmt.Fprintln(w)
 	}
 
 	w.Flush()
 
-	return nil
+	if len(shared) > 0 {
+		fmt.Fprintln(out)
+		fmt.Fprintln(out, "! device allocated to more than one active instance")
+	}
+}
+
+// instanceDeviceIndices returns the device indices allocated to an instance
+// from its device_indices field.
+func instanceDeviceIndices(instanceMap map[string]interface{}) []int {
+	raw, _ := instanceMap["device_indices"].([]interface{})
+	indices := make([]int, 0, len(raw))
+	for _, idx := range raw {
+		if n, ok := idx.(float64); ok {
+			indices = append(indices, int(n))
+		}
+	}
+	return indices
+}
+
+// sharedDevices returns the devices allocated to more than one active
+// instance. Stopped and failed instances no longer hold their devices and
+// are not counted.
+func sharedDevices(instances []interface{}) map[int]bool {
+	users := make(map[int]int)
+	for _, instance := range instances {
+		instanceMap, ok := instance.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		switch state, _ := instanceMap["state"].(string); state {
+		case "stopped", "error", "failed":
+			continue
+		}
+		for _, idx := range instanceDeviceIndices(instanceMap) {
+			users[idx]++
+		}
+	}
+
+	shared := make(map[int]bool)
+	for idx, n := range users {
+		if n > 1 {
+			shared[idx] = true
+		}
+	}
+	return shared
 }
 
 // formatDuration formats a duration in human-readable format
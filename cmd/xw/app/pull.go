@@ -7,7 +7,9 @@ import (
 	"strings"
 
 	"github.com/spf13/cobra"
+	"github.com/tsingmaoai/xw-cli/cmd/xw/client"
 	"github.com/tsingmaoai/xw-cli/internal/api"
+	"github.com/tsingmaoai/xw-cli/internal/models"
 )
 
 // PullOptions holds options for the pull command
@@ -16,6 +18,15 @@ type PullOptions struct {
 
 	// Model is the model name to pull
 	Model string
+
+	// Revision is the source branch, tag, or commit to pull (empty for default)
+	Revision string
+
+	// Jobs is the number of files downloaded concurrently
+	Jobs int
+
+	// Group is a model group whose members are all pulled
+	Group string
 }
 
 // NewPullCommand creates the pull command.
@@ -25,12 +36,15 @@ type PullOptions struct {
 //
 // Usage:
 //
-//	xw pull MODEL
+//	xw pull MODEL [--revision REV] [--jobs N]
+//	xw pull --group GROUP [--jobs N]
 //
 // Examples:
 //
 //	xw pull qwen2-0.5b
 //	xw pull qwen2-7b
+//	xw pull qwen2-7b --revision v1.0
+//	xw pull --group qwen2
 //
 // Parameters:
 //   - globalOpts: Global options shared across commands
@@ -43,21 +57,56 @@ func NewPullCommand(globalOpts *GlobalOptions) *cobra.Command {
 	}
 
 	cmd := &cobra.Command{
-		Use:   "pull MODEL",
+		Use:   "pull [MODEL]",
 		Short: "Download a model",
 		Long: `Download and install an AI model.
 
 The model files are downloaded to the xw server and prepared for execution.
-This command must be run before a model can be used with 'xw run'.`,
+This command must be run before a model can be used with 'xw start';
+'xw run' pulls a model that is not downloaded yet automatically.
+
+Use --revision to pin a branch, tag, or commit of the model repository on
+its hub. The revision and the commit it resolved to are recorded in the
+model's Modelfile. Pulling another revision of a downloaded model downloads
+it next to the current files and replaces them once complete; the
+Modelfile's directives are kept. Stop the model's instances first, since
+their engines read the files being replaced.
+
+Model files are downloaded several at a time; use --jobs to change how many.
+
+Use --group instead of MODEL to pull every member of a model group, as
+defined under model_groups in models.yaml (see 'xw ls -a --group'). Members
+that are already downloaded are skipped.`,
 		Example: `  xw pull qwen2-0.5b
-  xw pull qwen2-7b`,
-		Args: cobra.ExactArgs(1),
+  xw pull qwen2-7b
+  xw pull qwen2-7b --revision v1.0
+  xw pull qwen3-32b --jobs 8
+  xw pull --group qwen2`,
+		Args: func(cmd *cobra.Command, args []string) error {
+			if opts.Group != "" {
+				return cobra.NoArgs(cmd, args)
+			}
+			return cobra.ExactArgs(1)(cmd, args)
+		},
 		RunE: func(cmd *cobra.Command, args []string) error {
+			if opts.Group != "" {
+				if opts.Revision != "" {
+					return fmt.Errorf("--revision cannot be used with --group")
+				}
+				return runPullGroup(opts)
+			}
 			opts.Model = args[0]
 			return runPull(opts)
 		},
 	}
 
+	cmd.Flags().StringVar(&opts.Revision, "revision", "",
+		"source branch, tag, or commit to download (default: the hub's default branch)")
+	cmd.Flags().IntVarP(&opts.Jobs, "jobs", "j", models.DefaultDownloadJobs,
+		fmt.Sprintf("number of files to download concurrently (1-%d)", models.MaxDownloadJobs))
+	cmd.Flags().StringVar(&opts.Group, "group", "",
+		"pull all members of this model group instead of MODEL")
+
 	return cmd
 }
 
@@ -73,6 +122,10 @@ This command must be run before a model can be used with 'xw run'.`,
 //   - nil on success
 //   - error if the request fails or the model doesn't exist
 func runPull(opts *PullOptions) error {
+	if opts.Jobs < 1 || opts.Jobs > models.MaxDownloadJobs {
+		return fmt.Errorf("--jobs must be between 1 and %d", models.MaxDownloadJobs)
+	}
+
 	client := getClient(opts.GlobalOptions)
 
 	// Check if model is supported by current device before pulling
@@ -132,20 +185,19 @@ func runPull(opts *PullOptions) error {
 		}
 	}
 
-	fmt.Printf("Pulling %s...\n", opts.Model)
+	if opts.Revision != "" {
+		fmt.Printf("Pulling %s (revision %s)...\n", opts.Model, opts.Revision)
+	} else {
+		fmt.Printf("Pulling %s...\n", opts.Model)
+	}
 
-	// Pull model with single-line progress display
-	resp, err := client.Pull(opts.Model, "", func(message string) {
-		// Only show progress bar (contains % and |)
-		if strings.Contains(message, "%") && strings.Contains(message, "|") {
-			// Use \r to overwrite, \033[K to clear to end of line
-			fmt.Printf("\r\033[K%s", message)
-		}
-		// Silently ignore all other messages
-	})
+	// Pull model with a progress bar and, once the server reports it, an
+	// overall summary line (rate, ETA, files) beneath it
+	progress := &pullProgress{}
+	resp, err := client.Pull(opts.Model, opts.Revision, opts.Jobs, progress.update)
 	
-	// Move to newline when done
-	fmt.Println()
+	// Move below the progress lines when done
+	progress.finish()
 	
 	if err != nil {
 		return fmt.Errorf("failed to pull model: %w", err)
@@ -163,3 +215,100 @@ func runPull(opts *PullOptions) error {
 
 	return nil
 }
+
+// runPullGroup pulls every member of a model group that is not downloaded
+// yet, one after another.
+//
+// A member that fails to download does not stop the others; the failures
+// are reported together at the end.
+//
+// Parameters:
+//   - opts: Pull command options, with Group set
+//
+// Returns:
+//   - nil if every member is downloaded
+//   - error if the group is unknown or any member fails to download
+func runPullGroup(opts *PullOptions) error {
+	if opts.Jobs < 1 || opts.Jobs > models.MaxDownloadJobs {
+		return fmt.Errorf("--jobs must be between 1 and %d", models.MaxDownloadJobs)
+	}
+
+	client := getClient(opts.GlobalOptions)
+
+	resp, err := client.QueryModels(api.ListModelsRequest{
+		DeviceType: api.DeviceTypeAll,
+		ShowAll:    true,
+		Group:      opts.Group,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to list model group: %w", err)
+	}
+
+	var pending []string
+	for _, model := range resp.Models {
+		if model.Status == "downloaded" {
+			fmt.Printf("%s is already downloaded, skipping\n", model.Name)
+			continue
+		}
+		pending = append(pending, model.Name)
+	}
+	if len(pending) == 0 {
+		fmt.Printf("All %d model(s) of group %s are downloaded\n", len(resp.Models), opts.Group)
+		return nil
+	}
+
+	var failed []string
+	for i, modelID := range pending {
+		fmt.Printf("\n[%d/%d] ", i+1, len(pending))
+		memberOpts := *opts
+		memberOpts.Model = modelID
+		if err := runPull(&memberOpts); err != nil {
+			fmt.Printf("✗ %s: %v\n", modelID, err)
+			failed = append(failed, modelID)
+		}
+	}
+
+	if len(failed) > 0 {
+		return fmt.Errorf("failed to pull %d of %d model(s) of group %s: %s",
+			len(failed), len(pending), opts.Group, strings.Join(failed, ", "))
+	}
+	return nil
+}
+
+// pullProgress draws the progress of xw pull: the overall progress bar and,
+// beneath it, a summary line with transfer rate, ETA and file count.
+type pullProgress struct {
+	bar     string
+	summary string
+}
+
+// update handles a progress message from the server and redraws both lines
+// in place. The cursor is left at the start of the bar line, so each call
+// overwrites the previous output.
+func (p *pullProgress) update(msg client.SSEMessage) {
+	switch {
+	case msg.Type == "summary":
+		p.summary = msg.Message
+	case strings.Contains(msg.Message, "%") && strings.Contains(msg.Message, "|"):
+		// Only show progress bar (contains % and |)
+		p.bar = msg.Message
+	default:
+		// Silently ignore all other messages
+		return
+	}
+
+	// Use \r to overwrite, \033[K to clear to end of line
+	fmt.Printf("\r\033[K%s", p.bar)
+	if p.summary != "" {
+		// Write the summary on the next line, then move back up
+		fmt.Printf("\n\033[K  %s\033[1A\r", p.summary)
+	}
+}
+
+// finish moves the cursor below the progress lines.
+func (p *pullProgress) finish() {
+	fmt.Println()
+	if p.summary != "" {
+		fmt.Println()
+	}
+}
@@ -0,0 +1,79 @@
+package app
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+)
+
+// RenameOptions holds options for the rename command
+type RenameOptions struct {
+	*GlobalOptions
+
+	// Alias is the current instance alias
+	Alias string
+
+	// NewAlias is the alias to assign
+	NewAlias string
+}
+
+// NewRenameCommand creates the rename command.
+//
+// The rename command changes the alias of a model instance without
+// restarting it.
+//
+// Usage:
+//
+//	xw rename OLD NEW
+//
+// Examples:
+//
+//	# Serve an instance under a new model name
+//	xw rename qwen3-32b claude-sonnet
+//
+// Parameters:
+//   - globalOpts: Global options shared across commands
+//
+// Returns:
+//   - A configured cobra.Command for renaming instances
+func NewRenameCommand(globalOpts *GlobalOptions) *cobra.Command {
+	opts := &RenameOptions{
+		GlobalOptions: globalOpts,
+	}
+
+	cmd := &cobra.Command{
+		Use:   "rename OLD NEW",
+		Short: "Change the alias of a model instance",
+		Long: `Change the alias of a model instance without restarting it.
+
+The alias is the model name clients use to reach the instance, so renaming
+repoints traffic without downtime: requests for NEW are routed to the
+instance as soon as the command returns, and requests already in flight are
+not interrupted. The container and its ID do not change.
+
+The new alias must not be used by another instance.`,
+		Example: `  # Serve an instance under a new model name
+  xw rename qwen3-32b claude-sonnet`,
+		Args: cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			opts.Alias = args[0]
+			opts.NewAlias = args[1]
+			return runRename(opts)
+		},
+	}
+
+	return cmd
+}
+
+// runRename executes the rename command logic
+func runRename(opts *RenameOptions) error {
+	client := getClient(opts.GlobalOptions)
+
+	if err := client.RenameInstance(opts.Alias, opts.NewAlias); err != nil {
+		return fmt.Errorf("failed to rename instance: %w", err)
+	}
+
+	fmt.Printf("Renamed instance %s to %s\n", opts.Alias, opts.NewAlias)
+
+	return nil
+}
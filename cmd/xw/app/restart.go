@@ -0,0 +1,174 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// RestartOptions holds options for the restart command
+type RestartOptions struct {
+	*GlobalOptions
+
+	// Alias is the instance alias to restart
+	Alias string
+
+	// Force skips draining in-flight requests
+	Force bool
+
+	// DrainTimeout is the maximum time to wait for in-flight requests
+	DrainTimeout time.Duration
+
+	// Wait blocks until the recreated instance is ready
+	Wait bool
+
+	// WaitTimeout is the maximum time to wait for readiness with --wait
+	WaitTimeout time.Duration
+}
+
+// NewRestartCommand creates the restart command.
+//
+// The restart command stops and removes a model instance and recreates it
+// with the options it was originally started with.
+//
+// Usage:
+//
+//	xw restart ALIAS [OPTIONS]
+//
+// Examples:
+//
+//	# Recreate a stuck instance
+//	xw restart qwen3-32b
+//
+//	# Restart immediately and wait until it serves again
+//	xw restart qwen3-32b --force --wait
+//
+// Parameters:
+//   - globalOpts: Global options shared across commands
+//
+// Returns:
+//   - A configured cobra.Command for restarting instances
+func NewRestartCommand(globalOpts *GlobalOptions) *cobra.Command {
+	opts := &RestartOptions{
+		GlobalOptions: globalOpts,
+	}
+
+	cmd := &cobra.Command{
+		Use:   "restart ALIAS",
+		Short: "Recreate a model instance with its original options",
+		Long: `Stop and remove a model instance, then start it again with the same
+options it was started with: model, engine, device selection, concurrency
+and retry limits, engine tuning options and environment variables.
+
+This is the usual fix for an instance that is stuck starting or no longer
+responds, without having to repeat the original 'xw start' flags. The
+instance keeps its alias. Devices are selected the same way as originally:
+explicit --device selections are reused, automatic selection allocates
+devices again.
+
+The instance is drained before it is stopped, like 'xw stop'; use --force to
+stop it immediately. Instances created by an older server did not record
+their options and must be restarted with 'xw stop' and 'xw start'.`,
+		Example: `  # Recreate a stuck instance
+  xw restart qwen3-32b
+
+  # Restart immediately and wait until it serves again
+  xw restart qwen3-32b --force --wait`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			opts.Alias = args[0]
+			return runRestart(opts)
+		},
+	}
+
+	cmd.Flags().BoolVarP(&opts.Force, "force", "f", false,
+		"stop the instance immediately without draining")
+	cmd.Flags().DurationVar(&opts.DrainTimeout, "drain-timeout", 30*time.Second,
+		"maximum time to wait for in-flight requests before stopping (0 to skip draining)")
+	cmd.Flags().BoolVar(&opts.Wait, "wait", false,
+		"wait until the recreated instance is ready to serve requests")
+	cmd.Flags().DurationVar(&opts.WaitTimeout, "wait-timeout", 30*time.Minute,
+		"maximum time to wait for readiness with --wait")
+
+	return cmd
+}
+
+// runRestart executes the restart command logic
+func runRestart(opts *RestartOptions) error {
+	client := getClient(opts.GlobalOptions)
+
+	if opts.DrainTimeout < 0 {
+		return fmt.Errorf("--drain-timeout must not be negative")
+	}
+	if opts.Wait && opts.WaitTimeout <= 0 {
+		return fmt.Errorf("--wait-timeout must be positive (got %s)", opts.WaitTimeout)
+	}
+
+	instMap := findInstance(client, opts.Alias)
+	if instMap == nil {
+		return fmt.Errorf("instance not found: %s", opts.Alias)
+	}
+
+	drainTimeout := opts.DrainTimeout
+	if opts.Force {
+		drainTimeout = 0
+	}
+
+	modelID, _ := instMap["model_id"].(string)
+	backendType, _ := instMap["backend_type"].(string)
+	deploymentMode, _ := instMap["deployment_mode"].(string)
+	fmt.Printf("Restarting %s with %s engine (%s mode)...\n", modelID, backendType, deploymentMode)
+	fmt.Println()
+
+	// Setup context and signal handler for Ctrl+C during startup
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	sigChan := make(chan os.Signal, 1)
+	signal.Notify(sigChan, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		<-sigChan
+		cancel()
+	}()
+
+	// The server looks up the recorded options, removes the instance and
+	// starts it again; the options never reach the client since their
+	// environment variables may hold secrets
+	progressDisplay := newProgressDisplay()
+	_, err := client.RestartInstanceWithSSEEvents(ctx, opts.Alias, drainTimeout, progressDisplay.update, progressDisplay.phase, progressDisplay.confirm)
+	progressDisplay.finish()
+
+	signal.Stop(sigChan)
+	close(sigChan)
+
+	if err != nil {
+		current := findInstance(client, opts.Alias)
+		switch {
+		case current != nil && current["id"] == instMap["id"]:
+			// Rejected before the instance was removed
+			return fmt.Errorf("failed to restart %s: %w", opts.Alias, err)
+		case current != nil:
+			return fmt.Errorf("failed to start %s again: %w", opts.Alias, err)
+		default:
+			return fmt.Errorf("failed to start %s again: %w\n\n"+
+				"The instance was removed; start it with 'xw start'", opts.Alias, err)
+		}
+	}
+
+	fmt.Println()
+	fmt.Printf("✓ Restarted instance: %s\n", opts.Alias)
+
+	if opts.Wait {
+		if err := waitForInstanceReady(client, opts.Alias, opts.WaitTimeout); err != nil {
+			return err
+		}
+	}
+
+	fmt.Println("Use 'xw ps' to view running instances")
+	return nil
+}
@@ -0,0 +1,252 @@
+package app
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/tsingmaoai/xw-cli/cmd/xw/client"
+)
+
+// RmOptions holds options for the rm command
+type RmOptions struct {
+	*GlobalOptions
+
+	// Aliases are the instances to remove
+	Aliases []string
+
+	// Failed removes all instances that failed or exited
+	Failed bool
+
+	// All removes all instances that are not serving
+	All bool
+
+	// Models removes the models created with 'xw cp' named by Aliases
+	// instead of instances
+	Models bool
+}
+
+// NewRmCommand creates the rm command.
+//
+// The rm command removes instances that are no longer serving, such as
+// containers kept after a failed start with `xw start --keep-failed`.
+//
+// Usage:
+//
+//	xw rm ALIAS... [OPTIONS]
+//	xw rm --failed
+//	xw rm --all
+//
+// Examples:
+//
+//	# Remove a kept instance
+//	xw rm my-model
+//
+//	# Remove all failed instances
+//	xw rm --failed
+//
+//	# Remove every instance that is not serving
+//	xw rm --all
+//
+//	# Remove a model created with 'xw cp'
+//	xw rm --model qwen3-8b-translator
+//
+// Parameters:
+//   - globalOpts: Global options shared across commands
+//
+// Returns:
+//   - A configured cobra.Command for removing instances
+func NewRmCommand(globalOpts *GlobalOptions) *cobra.Command {
+	opts := &RmOptions{
+		GlobalOptions: globalOpts,
+	}
+
+	cmd := &cobra.Command{
+		Use:   "rm [ALIAS...]",
+		Short: "Remove model instances that are not serving",
+		Long: `Remove model instances and their containers.
+
+Instances started with --keep-failed are left in place when they fail to
+start or crash, so their output can be read with 'xw logs'. Use 'xw rm' to
+remove them once inspected, either by alias or all at once with --failed,
+which removes every instance in the failed or error state. --all removes
+every instance that is not serving (stopped, created, failed or error), for
+example after 'xw stop --all' or a host recovery.
+
+Running instances are removed immediately without draining; use 'xw stop'
+to stop a serving instance gracefully.
+
+With --model, the arguments name models created with 'xw cp' instead. The
+model and its Modelfile are removed; the weights it serves are kept. A model
+with instances must have them removed first.`,
+		Example: `  # Remove a kept instance
+  xw rm my-model
+
+  # Remove all failed instances
+  xw rm --failed
+
+  # Remove every instance that is not serving
+  xw rm --all
+
+  # Remove a model created with 'xw cp'
+  xw rm --model qwen3-8b-translator`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			opts.Aliases = args
+			return runRm(opts)
+		},
+	}
+
+	cmd.Flags().BoolVar(&opts.Failed, "failed", false,
+		"remove all instances that failed to start or crashed")
+	cmd.Flags().BoolVar(&opts.All, "all", false,
+		"remove all instances that are not serving")
+	cmd.Flags().BoolVar(&opts.Models, "model", false,
+		"remove the models created with 'xw cp' named by the arguments")
+
+	return cmd
+}
+
+// servingStates are the instance states of instances that serve, or are
+// about to serve, requests. 'xw rm --all' leaves these alone.
+var servingStates = map[string]bool{
+	"running":   true,
+	"ready":     true,
+	"starting":  true,
+	"draining":  true,
+	"unhealthy": true,
+}
+
+// runRm executes the rm command logic
+func runRm(opts *RmOptions) error {
+	client := getClient(opts.GlobalOptions)
+
+	if opts.Failed && opts.All {
+		return fmt.Errorf("--failed and --all cannot be combined")
+	}
+	if (opts.Failed || opts.All) && len(opts.Aliases) > 0 {
+		return fmt.Errorf("--failed and --all cannot be combined with instance aliases")
+	}
+	if opts.Models {
+		if opts.Failed || opts.All {
+			return fmt.Errorf("--model cannot be combined with --failed or --all")
+		}
+		if len(opts.Aliases) == 0 {
+			return fmt.Errorf("specify the models to remove")
+		}
+		return removeModels(client, opts.Aliases)
+	}
+
+	aliases := opts.Aliases
+	if opts.Failed || opts.All {
+		instances, err := listInstanceSummaries(client)
+		if err != nil {
+			return err
+		}
+		for _, inst := range instances {
+			failed := inst.state == "failed" || inst.state == "error"
+			if (opts.Failed && failed) || (opts.All && !servingStates[inst.state]) {
+				aliases = append(aliases, inst.alias)
+			}
+		}
+
+		if len(aliases) == 0 {
+			if opts.Failed {
+				fmt.Println("No failed instances")
+			} else {
+				fmt.Println("No instances to remove")
+			}
+			return nil
+		}
+	} else if len(aliases) == 0 {
+		return fmt.Errorf("specify instance aliases to remove, --failed or --all")
+	}
+
+	return removeInstances(client, aliases, 0, "Removed instance")
+}
+
+// instanceSummary is the alias and state of a listed instance.
+type instanceSummary struct {
+	alias string
+	state string
+}
+
+// listInstanceSummaries lists the alias and state of every instance.
+func listInstanceSummaries(c *client.Client) ([]instanceSummary, error) {
+	instances, err := c.ListInstances(true)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list instances: %w", err)
+	}
+
+	result := make([]instanceSummary, 0, len(instances))
+	for _, inst := range instances {
+		instMap, ok := inst.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		alias, _ := instMap["alias"].(string)
+		state, _ := instMap["state"].(string)
+		if alias != "" {
+			result = append(result, instanceSummary{alias: alias, state: state})
+		}
+	}
+	return result, nil
+}
+
+// removeInstances stops and removes instances one by one, continuing past
+// failures, and prints a summary if more than one instance was given.
+//
+// Parameters:
+//   - c: API client
+//   - aliases: Instances to remove
+//   - drainTimeout: Maximum time to wait for in-flight requests (0 to skip draining)
+//   - done: Message printed before the alias of each removed instance
+//
+// Returns:
+//   - Error if any instance could not be removed
+func removeInstances(c *client.Client, aliases []string, drainTimeout time.Duration, done string) error {
+	var failed int
+	for _, alias := range aliases {
+		if err := c.RemoveInstanceByAlias(alias, true, drainTimeout); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: failed to remove %s: %v\n", alias, err)
+			failed++
+			continue
+		}
+		fmt.Printf("%s: %s\n", done, alias)
+	}
+
+	if len(aliases) > 1 {
+		fmt.Printf("\n%d of %d instances removed\n", len(aliases)-failed, len(aliases))
+	}
+	if failed > 0 {
+		return fmt.Errorf("failed to remove %d of %d instances", failed, len(aliases))
+	}
+	return nil
+}
+
+// removeModels removes models created with 'xw cp' one by one, continuing
+// past failures.
+//
+// Parameters:
+//   - c: API client
+//   - names: Models to remove
+//
+// Returns:
+//   - Error if any model could not be removed
+func removeModels(c *client.Client, names []string) error {
+	var failed int
+	for _, name := range names {
+		if err := c.RemoveModel(name); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: failed to remove %s: %v\n", name, err)
+			failed++
+			continue
+		}
+		fmt.Printf("Removed model: %s\n", name)
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("failed to remove %d of %d models", failed, len(names))
+	}
+	return nil
+}
@@ -7,10 +7,14 @@ package app
 
 import (
 	"fmt"
+	"net"
+	"net/url"
 	"os"
+	"time"
 
 	"github.com/spf13/cobra"
 	"github.com/tsingmaoai/xw-cli/cmd/xw/client"
+	"github.com/tsingmaoai/xw-cli/internal/config"
 )
 
 const (
@@ -23,6 +27,9 @@ const (
 	// envServerURL is the environment variable name for server URL
 	envServerURL = "XW_SERVER"
 
+	// envCACert is the environment variable name for the CA certificate file
+	envCACert = "XW_CA_CERT"
+
 	// defaultServerURL is the default server address
 	defaultServerURL = "http://localhost:11581"
 )
@@ -32,6 +39,9 @@ type GlobalOptions struct {
 	// ServerURL is the xw server address
 	ServerURL string
 
+	// CACert is a PEM file of CA certificates trusted for HTTPS servers
+	CACert string
+
 	// Verbose enables verbose output
 	Verbose bool
 }
@@ -73,6 +83,8 @@ xw server is running before executing commands.`,
 	// Add global flags
 	cmd.PersistentFlags().StringVar(&opts.ServerURL, "server", "",
 		fmt.Sprintf("xw server address (env: %s, default: %s)", envServerURL, defaultServerURL))
+	cmd.PersistentFlags().StringVar(&opts.CACert, "ca-cert", "",
+		fmt.Sprintf("CA certificate file (PEM) trusted for HTTPS servers (env: %s)", envCACert))
 	cmd.PersistentFlags().BoolVarP(&opts.Verbose, "verbose", "v", false,
 		"verbose output")
 
@@ -80,10 +92,16 @@ xw server is running before executing commands.`,
 	cmd.AddCommand(
 		NewListCommand(opts),
 		NewShowCommand(opts),
+		NewEditCommand(opts),
+		NewCpCommand(opts),
 		NewRunCommand(opts),
+		NewChatCommand(opts),
 		NewStartCommand(opts),
 		NewPsCommand(opts),
 		NewStopCommand(opts),
+		NewRmCommand(opts),
+		NewRestartCommand(opts),
+		NewRenameCommand(opts),
 		NewLogsCommand(opts),
 		NewPullCommand(opts),
 		NewVersionCommand(opts),
@@ -92,6 +110,7 @@ xw server is running before executing commands.`,
 		NewConfigCommand(opts),
 		NewUpdateCommand(opts),
 		NewReloadCommand(opts),
+		NewDoctorCommand(opts),
 	)
 
 	return cmd
@@ -103,7 +122,9 @@ xw server is running before executing commands.`,
 // the xw server. It determines the server address using the following priority:
 //   1. --server flag (if specified)
 //   2. XW_SERVER environment variable (if set)
-//   3. Default: http://localhost:11581
+//   3. Address recorded by a running server in ~/.xw/server.json (see
+//      discoverServerURL)
+//   4. Default: http://localhost:11581
 //
 // Parameters:
 //   - opts: Global options containing server URL
@@ -113,15 +134,76 @@ xw server is running before executing commands.`,
 func getClient(opts *GlobalOptions) *client.Client {
 	serverURL := opts.ServerURL
 	
-	// Priority: flag > environment variable > default
+	// Priority: flag > environment variable > server.json > default
 	if serverURL == "" {
 		serverURL = os.Getenv(envServerURL)
 	}
+	if serverURL == "" {
+		serverURL = discoverServerURL()
+	}
 	if serverURL == "" {
 		serverURL = defaultServerURL
 	}
 	
-	return client.NewClient(serverURL)
+	caCert := opts.CACert
+	if caCert == "" {
+		caCert = os.Getenv(envCACert)
+	}
+	if caCert == "" {
+		return client.NewClient(serverURL)
+	}
+
+	c, err := client.NewClientWithCA(serverURL, caCert)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	return c
+}
+
+// discoveryDialTimeout bounds the reachability check of a discovered server.
+const discoveryDialTimeout = 500 * time.Millisecond
+
+// discoverServerURL returns the address recorded in ~/.xw/server.json by a
+// running server, or "" if there is none.
+//
+// The file outlives a server that crashed, so the address is only used if
+// the recorded process is still alive and its port accepts connections.
+// A file left by a dead process is removed; otherwise connecting to the
+// old address would fail with a confusing "connection refused".
+//
+// Returns:
+//   - The discovered server URL, or "" to use the default
+func discoverServerURL() string {
+	configDir := config.NewDefaultConfig().Storage.ConfigDir
+	info, err := config.ReadServerInfo(configDir)
+	if err != nil {
+		return ""
+	}
+
+	if !info.ProcessAlive() {
+		config.RemoveStaleServerInfo(configDir)
+		return ""
+	}
+
+	u, err := url.Parse(info.Address)
+	if err != nil {
+		return ""
+	}
+	network, address := "tcp", u.Host
+	if u.Scheme == "unix" {
+		network, address = "unix", u.Path
+	}
+	if address == "" {
+		return ""
+	}
+	conn, err := net.DialTimeout(network, address, discoveryDialTimeout)
+	if err != nil {
+		return ""
+	}
+	conn.Close()
+
+	return info.Address
 }
 
 // checkError prints an error and exits if err is not nil.
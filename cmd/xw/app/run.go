@@ -16,6 +16,9 @@ import (
 
 	"github.com/chzyer/readline"
 	"github.com/spf13/cobra"
+	"github.com/tsingmaoai/xw-cli/cmd/xw/client"
+	"github.com/tsingmaoai/xw-cli/internal/api"
+	"github.com/tsingmaoai/xw-cli/internal/models"
 )
 
 // RunOptions holds options for the run command
@@ -28,7 +31,7 @@ type RunOptions struct {
 	// Alias is the optional instance alias
 	Alias string
 
-	// Engine is the inference engine in format "backend:mode" (e.g., "vllm:docker", "mindie:native")
+	// Engine is the inference engine in format "backend:mode" (e.g., "vllm:docker", "vllm:native")
 	Engine string
 
 	// Device is the device list (e.g., "0", "0,1,2,3")
@@ -36,19 +39,22 @@ type RunOptions struct {
 	
 	// TensorParallel is the tensor parallelism degree (must be 1/2/4/8)
 	TensorParallel int
+
+	// Detach returns once the instance is ready instead of opening a chat session
+	Detach bool
 }
 
 // NewRunCommand creates the run command.
 //
-// The run command is a convenience command that combines start and chat:
+// The run command is a convenience command that combines pull, start and chat:
 //  1. Check if instance exists in ps list
 //  2. If exists, wait for it to be ready
-//  3. If not exists, start the instance
-//  4. Once ready, launch interactive chat
+//  3. If not exists, pull the model if it is not downloaded and start the instance
+//  4. Once ready, launch interactive chat (unless --detach is set)
 //
 // Usage:
 //
-//	xw run MODEL [--alias ALIAS] [--backend TYPE] [--mode MODE]
+//	xw run MODEL [--alias ALIAS] [--engine ENGINE] [--device DEVICES] [--detach]
 //
 // Examples:
 //
@@ -64,16 +70,17 @@ func NewRunCommand(globalOpts *GlobalOptions) *cobra.Command {
 		Short: "Run a model with interactive chat",
 		Long: `Run a model and start an interactive chat session.
 
-This command combines instance management and chat interaction:
+This command combines pulling, instance management and chat interaction:
 - Checks if the model instance is already running
+- Downloads the model if it has not been pulled yet
 - Starts the instance if not running
 - Waits for the instance to be ready
-- Launches an interactive chat session
+- Launches an interactive chat session (skipped with --detach)
 
 If --alias is not specified, the model ID is used as the alias.
 
 Engine Selection:
-  Engine is specified as "backend:mode" (e.g., "vllm:docker", "mindie:native").
+  Engine is specified as "backend:mode" (e.g., "vllm:docker", "vllm:native").
   If not specified, xw will automatically select the best available engine.
 
 Device Selection:
@@ -89,7 +96,10 @@ Device Selection:
   xw run qwen2-7b --engine vllm:docker
 
   # Run on specific devices
-  xw run qwen2.5-7b-instruct --device 0,1`,
+  xw run qwen2.5-7b-instruct --device 0,1
+
+  # Pull and start in the background, returning once the model serves
+  xw run qwen2.5-7b-instruct --detach`,
 		Args: cobra.ExactArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			opts.Model = args[0]
@@ -101,6 +111,7 @@ Device Selection:
 	cmd.Flags().StringVar(&opts.Engine, "engine", "", "inference engine in format backend:mode (e.g., vllm:docker)")
 	cmd.Flags().StringVar(&opts.Device, "device", "", "device list (e.g., 0 or 0,1,2,3)")
 	cmd.Flags().IntVar(&opts.TensorParallel, "tp", 0, "tensor parallelism degree (must be 1, 2, 4, or 8)")
+	cmd.Flags().BoolVarP(&opts.Detach, "detach", "d", false, "return once the instance is ready instead of starting a chat session")
 
 	return cmd
 }
@@ -149,8 +160,12 @@ func runRun(opts *RunOptions) error {
 		}
 	}
 
-	// Step 2: Start instance if not exists
+	// Step 2: Start instance if not exists, pulling the model first if needed
 	if !instanceExists {
+		if err := ensureModelPulled(opts); err != nil {
+			return err
+		}
+
 		fmt.Printf("Starting new instance: %s\n", alias)
 		
 		startOpts := &StartOptions{
@@ -307,23 +322,92 @@ func runRun(opts *RunOptions) error {
 	}
 
 readyComplete:
+	if opts.Detach {
+		fmt.Println("Use 'xw ps' to view running instances")
+		return nil
+	}
+
 	// Use server's base URL - server has API proxy to forward requests to instances
 	instanceEndpoint := client.GetBaseURL()
+	httpClient := client.HTTPClient()
 
 	// Step 4: Start interactive chat
-	fmt.Println("=" + strings.Repeat("=", 60))
-	fmt.Printf("Chat session started with: %s\n", alias)
-	fmt.Println("Type your message and press Enter. Use '/h' or '/?' for help, '/quit' to exit.")
-	fmt.Println("=" + strings.Repeat("=", 60))
-	fmt.Println()
+	printChatBanner(alias)
+
+	return startInteractiveChat(alias, instanceEndpoint, httpClient, modelSystemPrompt(client, opts.Model))
+}
+
+// ensureModelPulled downloads the model of a run if it is not downloaded yet.
+//
+// The model may be given by ID or by source ID (e.g., "Qwen/Qwen3-8B"); it
+// is resolved to its ID by the server, as a start would resolve it, before
+// the downloaded models are searched.
+//
+// If the downloaded models cannot be listed (e.g., an older server), the
+// check is skipped and the start reports a missing model itself.
+//
+// Parameters:
+//   - opts: Run options
+//
+// Returns:
+//   - Error if the pull fails or was declined
+func ensureModelPulled(opts *RunOptions) error {
+	client := getClient(opts.GlobalOptions)
 
-	return startInteractiveChat(alias, instanceEndpoint)
+	modelID := opts.Model
+	if info, err := client.GetModel(opts.Model); err == nil {
+		if id, ok := info["model_id"].(string); ok && id != "" {
+			modelID = id
+		}
+	}
+
+	downloaded, err := client.ListDownloadedModels()
+	if err != nil {
+		fmt.Printf("Warning: Unable to check whether %s is downloaded: %v\n", opts.Model, err)
+		return nil
+	}
+	if isDownloaded(downloaded, modelID) {
+		return nil
+	}
+
+	fmt.Printf("Model %s is not downloaded yet\n", opts.Model)
+	pullOpts := &PullOptions{
+		GlobalOptions: opts.GlobalOptions,
+		Model:         opts.Model,
+		Jobs:          models.DefaultDownloadJobs,
+	}
+	if err := runPull(pullOpts); err != nil {
+		return err
+	}
+
+	// The pull returns without error if its compatibility prompt is declined
+	downloaded, err = client.ListDownloadedModels()
+	if err != nil {
+		return nil
+	}
+	if isDownloaded(downloaded, modelID) {
+		fmt.Println()
+		return nil
+	}
+	return fmt.Errorf("model %s was not downloaded", opts.Model)
+}
+
+// isDownloaded reports whether the model with the given ID is among the
+// downloaded models.
+func isDownloaded(downloaded []api.DownloadedModel, modelID string) bool {
+	for _, model := range downloaded {
+		if model.ID == modelID {
+			return true
+		}
+	}
+	return false
 }
 
 // chatSession holds the state of a chat session
 type chatSession struct {
 	alias         string
 	endpoint      string
+	httpClient    *http.Client // Client for the server (API key, Unix socket)
 	messages      []map[string]string
 	systemPrompt  string
 	temperature   float64
@@ -334,9 +418,33 @@ type chatSession struct {
 	cancelFunc    context.CancelFunc // To cancel ongoing requests
 }
 
+// printChatBanner prints the header of a chat session.
+func printChatBanner(alias string) {
+	fmt.Println("=" + strings.Repeat("=", 60))
+	fmt.Printf("Chat session started with: %s\n", alias)
+	fmt.Println("Type your message and press Enter. Use '/h' or '/?' for help, '/quit' to exit.")
+	fmt.Println(`Wrap text in """ to enter multiple lines.`)
+	fmt.Println("=" + strings.Repeat("=", 60))
+	fmt.Println()
+}
+
+// modelSystemPrompt returns the system prompt configured in a model's
+// Modelfile, or empty string if it has none or the model cannot be looked
+// up. It is read from the Modelfile itself rather than the "system" field of
+// the model info, which falls back to a generic default prompt.
+func modelSystemPrompt(c *client.Client, modelID string) string {
+	info, err := c.GetModel(modelID)
+	if err != nil {
+		return ""
+	}
+	modelfile, _ := info["modelfile"].(string)
+	return models.ModelfileDirectiveValue(modelfile, "SYSTEM")
+}
+
 // startInteractiveChat starts an interactive chat session with the model
-// alias is used as the model name in the API request
-func startInteractiveChat(alias, endpoint string) error {
+// alias is used as the model name in the API request; systemPrompt is the
+// initial system prompt (empty for none)
+func startInteractiveChat(alias, endpoint string, httpClient *http.Client, systemPrompt string) error {
 	// Create readline instance with history support
 	rl, err := readline.NewEx(&readline.Config{
 		Prompt:          ">>> ",
@@ -352,8 +460,9 @@ func startInteractiveChat(alias, endpoint string) error {
 	session := &chatSession{
 		alias:        alias,
 		endpoint:     endpoint,
+		httpClient:   httpClient,
 		messages:     []map[string]string{},
-		systemPrompt: "", // Empty by default, model will use its default
+		systemPrompt: systemPrompt, // Modelfile SYSTEM by default; changed with /system
 		temperature:  0.7,
 		topP:         0.9,
 		maxTokens:    2048,
@@ -384,8 +493,18 @@ func startInteractiveChat(alias, endpoint string) error {
 			continue
 		}
 
+		// Multiline input: everything up to the closing """ is one message
+		multiline := strings.HasPrefix(userInput, `"""`)
+		if multiline {
+			text, ok := session.readMultiline(strings.TrimPrefix(userInput, `"""`))
+			if !ok || text == "" {
+				continue
+			}
+			userInput = text
+		}
+
 		// Check if it's a command (starts with /)
-		if strings.HasPrefix(userInput, "/") {
+		if !multiline && strings.HasPrefix(userInput, "/") {
 			if shouldExit := session.handleCommand(userInput); shouldExit {
 				break
 			}
@@ -452,6 +571,42 @@ func startInteractiveChat(alias, endpoint string) error {
 	return nil
 }
 
+// readMultiline reads the lines of a multiline message up to the line
+// ending with the closing """. Lines are kept as typed, without trimming
+// indentation.
+//
+// Parameters:
+//   - first: Text after the opening """ on the first line
+//
+// Returns:
+//   - Message text
+//   - False if input was interrupted with Ctrl+C or ended
+func (s *chatSession) readMultiline(first string) (string, bool) {
+	if strings.HasSuffix(first, `"""`) {
+		return strings.TrimSpace(strings.TrimSuffix(first, `"""`)), true
+	}
+
+	var lines []string
+	if first != "" {
+		lines = append(lines, first)
+	}
+
+	s.readline.SetPrompt("... ")
+	defer s.readline.SetPrompt(">>> ")
+
+	for {
+		line, err := s.readline.Readline()
+		if err != nil {
+			return "", false
+		}
+		if strings.HasSuffix(strings.TrimRight(line, " \t"), `"""`) {
+			lines = append(lines, strings.TrimSuffix(strings.TrimRight(line, " \t"), `"""`))
+			return strings.TrimSpace(strings.Join(lines, "\n")), true
+		}
+		lines = append(lines, line)
+	}
+}
+
 // handleCommand processes slash commands
 // Returns true if the session should exit
 func (s *chatSession) handleCommand(cmd string) bool {
@@ -474,6 +629,18 @@ func (s *chatSession) handleCommand(cmd string) bool {
 		s.messages = []map[string]string{}
 		fmt.Println("Context cleared.")
 
+	case "/system":
+		if len(parts) == 1 {
+			if s.systemPrompt == "" {
+				fmt.Println("No system prompt set.")
+			} else {
+				fmt.Printf("System prompt: %s\n", s.systemPrompt)
+			}
+			break
+		}
+		s.systemPrompt = strings.TrimSpace(strings.TrimPrefix(cmd, command))
+		fmt.Printf("System prompt set to: %s\n", s.systemPrompt)
+
 	case "/history":
 		s.showHistory()
 
@@ -546,6 +713,7 @@ func (s *chatSession) showHelp() {
 	fmt.Println("  /h, /?                  Show this help")
 	fmt.Println("  /quit                   Exit the chat session")
 	fmt.Println("  /clear                  Clear conversation history")
+	fmt.Println("  /system [<prompt>]      Show or set the system prompt")
 	fmt.Println("  /history                Show conversation history")
 	fmt.Println("  /show                   Show current configuration")
 	fmt.Println("  /set <param> <value>    Set a parameter:")
@@ -554,6 +722,9 @@ func (s *chatSession) showHelp() {
 	fmt.Println("    top-p <0-1>             Set top-p (default: 0.9)")
 	fmt.Println("    max-tokens <number>     Set max tokens (default: 2048)")
 	fmt.Println()
+	fmt.Println(`  Use """ to begin and end a multiline message.`)
+	fmt.Println("  Press Ctrl+C to interrupt a response.")
+	fmt.Println()
 }
 
 // showHistory displays the conversation history
@@ -650,11 +821,8 @@ func (s *chatSession) sendChatRequestWithContext(ctx context.Context) (string, e
 	}
 	req.Header.Set("Content-Type", "application/json")
 	req.Header.Set("Accept", "text/event-stream")
-
 	// No timeout - rely on context cancellation
-	client := &http.Client{}
-
-	resp, err := client.Do(req)
+	resp, err := s.httpClient.Do(req)
 	if err != nil {
 		return "", fmt.Errorf("failed to send request: %w", err)
 	}
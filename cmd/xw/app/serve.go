@@ -2,11 +2,17 @@ package app
 
 import (
 	"context"
+	"crypto/tls"
+	"errors"
 	"fmt"
+	"net"
 	"os"
+	"os/exec"
 	"os/signal"
 	"path/filepath"
+	"strconv"
 	"syscall"
+	"time"
 
 	"github.com/spf13/cobra"
 	"github.com/tsingmaoai/xw-cli/internal/config"
@@ -23,12 +29,51 @@ type ServeOptions struct {
 
 	// Port is the server port
 	Port int
+
+	// HostExplicit is true when --host was given; otherwise the host saved
+	// with 'xw config set host' is used, if any.
+	HostExplicit bool
+
+	// PortExplicit is true when --port was given. Only the default port is
+	// replaced by a free one when it is in use.
+	PortExplicit bool
+
+	// TLSCert is the TLS certificate file; with TLSKey, enables HTTPS
+	TLSCert string
+
+	// TLSKey is the TLS private key file
+	TLSKey string
+
+	// Socket is a Unix socket path to listen on instead of host and port
+	Socket string
 	
 	// DataDir is the data directory for storing models and runtime data
 	DataDir string
 	
 	// ConfigDir is the directory containing configuration files (YAML files)
 	ConfigDir string
+
+	// LogFile is the file to write server logs to (empty for stderr)
+	LogFile string
+
+	// LogMaxSize is the log file size in MB at which it is rotated
+	LogMaxSize int
+
+	// LogKeep is the number of rotated log files to keep
+	LogKeep int
+
+	// StopInstances stops all model instances when the server shuts down
+	StopInstances bool
+
+	// RequestTimeout limits non-streaming inference requests (0 for no limit)
+	RequestTimeout time.Duration
+
+	// StreamTimeout limits streaming inference requests (0 for no limit)
+	StreamTimeout time.Duration
+
+	// Foreground keeps the server attached to the terminal. With
+	// --foreground=false the server is started in the background.
+	Foreground bool
 }
 
 // NewServeCommand creates the serve command.
@@ -39,7 +84,8 @@ type ServeOptions struct {
 //
 // Usage:
 //
-//	xw serve [--host HOST] [--port PORT]
+//	xw serve [--host HOST] [--port PORT] [--tls-cert FILE --tls-key FILE]
+//	xw serve --socket PATH
 //
 // Examples:
 //
@@ -49,6 +95,9 @@ type ServeOptions struct {
 //	# Start server on specific host and port
 //	xw serve --host 0.0.0.0 --port 9090
 //
+//	# Serve HTTPS on all interfaces
+//	xw serve --host 0.0.0.0 --tls-cert server.crt --tls-key server.key
+//
 // Parameters:
 //   - globalOpts: Global options shared across commands
 //
@@ -68,7 +117,49 @@ This command is primarily intended for development and testing. In production
 deployments, use the dedicated xw-server binary with systemd.
 
 The server listens for HTTP requests and manages model execution on domestic
-chip devices. Press Ctrl+C to gracefully shut down the server.`,
+chip devices. The server runs in the foreground. Ctrl+C (SIGINT) or SIGTERM
+shuts it down gracefully: requests in flight are given time to complete and
+~/.xw/server.json is removed. A second signal exits immediately. Model
+instances keep running unless --stop-instances is given.
+
+--foreground=false starts the server in the background instead: it is
+detached from the terminal, logs to --log-file (default ~/.xw/logs/server.log),
+and the command returns once the server has recorded its address. Stop it
+with SIGTERM (kill PID). Service managers such as systemd should run the
+server in the foreground.
+
+If the default port 11581 is in use (e.g., by a stale server), the next free
+port is used and recorded in ~/.xw/server.json, where clients find it. An
+explicit --port is never replaced; the server fails if it is in use.
+
+When exposing the server beyond localhost, pass --tls-cert and --tls-key to
+serve HTTPS. Clients then connect with an https:// URL, e.g.
+XW_SERVER=https://host:11581 or --server https://host:11581. A certificate
+issued by a private CA is trusted with --ca-cert FILE (env: XW_CA_CERT).
+
+To avoid opening a TCP port at all, pass --socket PATH to listen on a Unix
+socket (mode 0600) instead. Its path is recorded in ~/.xw/server.json; other
+clients connect with XW_SERVER=unix:///run/xw.sock or --server unix:///run/xw.sock.
+
+Inference requests through /v1/* time out after --request-timeout (10m) or,
+when streaming, --stream-timeout (1h); clients then receive 504 Gateway
+Timeout in their API's error format.
+
+To require an API key on /api/* and /v1/*, run "xw config set api_key KEY"
+or set XW_API_KEY for the server. Clients send it via XW_API_KEY.
+
+The host and port saved with "xw config set host|port" are used when --host
+or --port is not given.
+
+Logging can be tuned with environment variables:
+  XW_LOG_FORMAT=json                 One JSON object per line
+  XW_LOG=runtime=debug,proxy=warn    Per-component levels (runtime, proxy,
+                                     handlers, device); a bare level such as
+                                     XW_LOG=debug sets the global level
+
+Configuration files can be overridden the same way:
+  XW_DEVICES_CONFIG=/path/devices.yaml  Use instead of the versioned devices.yaml
+  XW_MODELS_CONFIG=/path/models.yaml    Use instead of the versioned models.yaml`,
 		Example: `  # Start server on default settings (localhost:11581)
   xw serve
 
@@ -78,14 +169,49 @@ chip devices. Press Ctrl+C to gracefully shut down the server.`,
   # Start server on custom port
   xw serve --port 9090
 
+  # Serve HTTPS for a team on the LAN
+  xw serve --host 0.0.0.0 --tls-cert /etc/xw/server.crt --tls-key /etc/xw/server.key
+
+  # Listen on a Unix socket only
+  xw serve --socket /run/xw.sock
+
   # Start with verbose logging
-  xw serve -v`,
+  xw serve -v
+
+  # Stop model instances together with the server
+  xw serve --stop-instances
+
+  # Write logs to a rotating file
+  xw serve --log-file ~/.xw/logs/xw.log --log-max-size 100 --log-keep 5
+
+  # Run in the background, logging to ~/.xw/logs/server.log
+  xw serve --foreground=false`,
 		Args: cobra.NoArgs,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			// Validate port range
 			if opts.Port < 1 || opts.Port > 65535 {
 				return fmt.Errorf("invalid port number: %d (must be between 1-65535)", opts.Port)
 			}
+			// TLS needs both files; a lone one is almost certainly a mistake
+			if (opts.TLSCert == "") != (opts.TLSKey == "") {
+				return fmt.Errorf("--tls-cert and --tls-key must be specified together")
+			}
+			if opts.RequestTimeout < 0 || opts.StreamTimeout < 0 {
+				return fmt.Errorf("--request-timeout and --stream-timeout must not be negative")
+			}
+			if opts.Socket != "" && opts.TLSCert != "" {
+				return fmt.Errorf("--socket cannot be combined with --tls-cert and --tls-key")
+			}
+			if opts.TLSCert != "" {
+				if _, err := tls.LoadX509KeyPair(opts.TLSCert, opts.TLSKey); err != nil {
+					return fmt.Errorf("invalid TLS certificate or key: %w", err)
+				}
+			}
+			if !opts.Foreground {
+				return startServeInBackground(opts)
+			}
+			opts.HostExplicit = cmd.Flags().Changed("host")
+			opts.PortExplicit = cmd.Flags().Changed("port")
 			return runServe(opts)
 		},
 	}
@@ -94,10 +220,30 @@ chip devices. Press Ctrl+C to gracefully shut down the server.`,
 		"server host address")
 	cmd.Flags().IntVar(&opts.Port, "port", 11581,
 		"server port")
+	cmd.Flags().StringVar(&opts.TLSCert, "tls-cert", "",
+		"TLS certificate file (PEM); serve HTTPS together with --tls-key")
+	cmd.Flags().StringVar(&opts.TLSKey, "tls-key", "",
+		"TLS private key file (PEM)")
+	cmd.Flags().StringVar(&opts.Socket, "socket", "",
+		"listen on this Unix socket instead of --host and --port")
 	cmd.Flags().StringVar(&opts.DataDir, "data", "",
 		"data directory for models and runtime data (default: ~/.xw/data)")
 	cmd.Flags().StringVar(&opts.ConfigDir, "config", "",
 		"directory containing configuration files (default: ~/.xw)")
+	cmd.Flags().StringVar(&opts.LogFile, "log-file", "",
+		"write logs to this file instead of stderr, with size-based rotation")
+	cmd.Flags().IntVar(&opts.LogMaxSize, "log-max-size", 100,
+		"log file size in MB at which it is rotated")
+	cmd.Flags().IntVar(&opts.LogKeep, "log-keep", 5,
+		"number of rotated log files to keep")
+	cmd.Flags().BoolVar(&opts.StopInstances, "stop-instances", false,
+		"stop all model instances when the server shuts down")
+	cmd.Flags().DurationVar(&opts.RequestTimeout, "request-timeout", config.DefaultRequestTimeout,
+		"maximum duration of a non-streaming inference request (0 for no limit)")
+	cmd.Flags().DurationVar(&opts.StreamTimeout, "stream-timeout", config.DefaultStreamTimeout,
+		"maximum duration of a streaming inference request (0 for no limit)")
+	cmd.Flags().BoolVar(&opts.Foreground, "foreground", true,
+		"run attached to the terminal; --foreground=false runs the server in the background")
 	
 	// Mark unknown flags as errors
 	cmd.SetFlagErrorFunc(func(cmd *cobra.Command, err error) error {
@@ -122,6 +268,18 @@ chip devices. Press Ctrl+C to gracefully shut down the server.`,
 //   - nil on successful shutdown
 //   - error if server startup or shutdown fails
 func runServe(opts *ServeOptions) error {
+	// Redirect logs first so startup messages are captured as well
+	if opts.LogFile != "" {
+		logFile, err := filepath.Abs(opts.LogFile)
+		if err != nil {
+			return fmt.Errorf("invalid log file path: %w", err)
+		}
+		if err := logger.SetOutputFile(logFile, opts.LogMaxSize, opts.LogKeep); err != nil {
+			return fmt.Errorf("failed to set up log file: %w", err)
+		}
+		fmt.Fprintf(os.Stderr, "Logging to %s\n", logFile)
+	}
+
 	// Create configuration with custom directories if specified
 	cfg := config.NewConfigWithCustomDirs(opts.ConfigDir, opts.DataDir)
 	
@@ -129,6 +287,17 @@ func runServe(opts *ServeOptions) error {
 	cfg.BinaryVersion = GetVersion()
 	cfg.Server.Host = opts.Host
 	cfg.Server.Port = opts.Port
+	cfg.Server.TLSCert = opts.TLSCert
+	cfg.Server.TLSKey = opts.TLSKey
+	cfg.Server.RequestTimeout = opts.RequestTimeout
+	cfg.Server.StreamTimeout = opts.StreamTimeout
+	if opts.Socket != "" {
+		socket, err := filepath.Abs(opts.Socket)
+		if err != nil {
+			return fmt.Errorf("invalid socket path: %w", err)
+		}
+		cfg.Server.Socket = socket
+	}
 
 	// Ensure directories exist
 	if err := cfg.EnsureDirectories(); err != nil {
@@ -141,10 +310,27 @@ func runServe(opts *ServeOptions) error {
 		return fmt.Errorf("failed to get server identity: %w", err)
 	}
 	
+	// Use the address saved with 'xw config set' unless given as flags.
+	// A saved port is as deliberate as --port, so it is not replaced either.
+	if !opts.HostExplicit && identity.Host != "" {
+		opts.Host = identity.Host
+		cfg.Server.Host = identity.Host
+	}
+	if !opts.PortExplicit && identity.Port != 0 {
+		opts.Port = identity.Port
+		opts.PortExplicit = true
+		cfg.Server.Port = identity.Port
+	}
+	
 	// Update server config with identity
 	cfg.Server.Name = identity.Name
 	cfg.Server.Registry = identity.Registry
+	cfg.Server.RegistryMirror = identity.RegistryMirror
+	cfg.Server.APIKey = identity.APIKey
 	logger.Info("Server identity: %s", identity.Name)
+	if cfg.GetAPIKey() != "" {
+		logger.Info("API key authentication enabled")
+	}
 	logger.Info("Configuration version: %s", identity.ConfigVersion)
 	
 	// Construct versioned config path
@@ -203,8 +389,31 @@ func runServe(opts *ServeOptions) error {
 	// Set server name in runtime manager
 	runtimeMgr.SetServerName(identity.Name)
 	
+	// Fall back to the next free port unless the user asked for this one
+	if !opts.PortExplicit && cfg.Server.Socket == "" {
+		port, err := findFreePort(opts.Host, opts.Port)
+		if err != nil {
+			return err
+		}
+		if port != opts.Port {
+			logger.Warn("Port %d is already in use, using port %d instead", opts.Port, port)
+			opts.Port = port
+			cfg.Server.Port = port
+		}
+	}
+
 	// Create server with runtime manager
 	srv := server.NewServer(cfg, runtimeMgr, GetVersion())
+
+	// Record the address so clients can discover a non-default port
+	if err := config.WriteServerInfo(cfg.Storage.ConfigDir, &config.ServerInfo{
+		Address:   cfg.ClientAddress(),
+		PID:       os.Getpid(),
+		StartedAt: time.Now(),
+	}); err != nil {
+		logger.Warn("Failed to write %s: %v", config.ServerInfoFileName, err)
+	}
+	defer config.RemoveServerInfo(cfg.Storage.ConfigDir)
 	
 	// Set up signal handling for graceful shutdown
 	sigChan := make(chan os.Signal, 1)
@@ -221,6 +430,11 @@ func runServe(opts *ServeOptions) error {
 		logger.Info("Press Ctrl+C to stop")
 		if err := srv.Start(); err != nil {
 			// Check for common errors
+			if isAddressInUse(err) && cfg.Server.Socket != "" {
+				logger.Error("Socket %s is in use by another server", cfg.Server.Socket)
+				errChan <- fmt.Errorf("address already in use: %s", cfg.Server.Socket)
+				return
+			}
 			if isAddressInUse(err) {
 				logger.Error("Port %d is already in use", opts.Port)
 				logger.Error("Please stop the existing server or use a different port with --port")
@@ -236,13 +450,32 @@ func runServe(opts *ServeOptions) error {
 	
 	// Wait for shutdown signal or error
 	select {
-	case <-sigChan:
-		logger.Info("Received interrupt signal, shutting down...")
-		ctx, cancel := context.WithTimeout(context.Background(), 30)
+	case sig := <-sigChan:
+		logger.Info("Received %v, shutting down (press Ctrl+C again to exit immediately)...", sig)
+		go func() {
+			<-sigChan
+			logger.Warn("Received second signal, exiting without cleanup")
+			os.Exit(1)
+		}()
+
+		ctx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
 		defer cancel()
 		
-		if err := srv.Stop(ctx); err != nil {
-			return fmt.Errorf("server shutdown failed: %w", err)
+		// Long-running streams may outlast the timeout; clean up regardless
+		shutdownErr := srv.Stop(ctx)
+		if shutdownErr != nil {
+			logger.Warn("Requests still in flight were interrupted: %v", shutdownErr)
+		}
+
+		if opts.StopInstances {
+			stopCtx, stopCancel := context.WithTimeout(context.Background(), shutdownTimeout)
+			defer stopCancel()
+			logger.Info("Stopping model instances...")
+			logger.Info("Stopped %d instance(s)", runtimeMgr.StopAll(stopCtx))
+		}
+
+		if shutdownErr != nil {
+			return fmt.Errorf("server shutdown failed: %w", shutdownErr)
 		}
 		
 		logger.Info("Server stopped successfully")
@@ -256,6 +489,129 @@ func runServe(opts *ServeOptions) error {
 	}
 }
 
+// serveStartTimeout bounds how long xw serve --foreground=false waits for
+// the background server to record its address.
+const serveStartTimeout = 60 * time.Second
+
+// startServeInBackground starts the server as a detached copy of this
+// command and returns once it runs.
+//
+// The copy runs with --foreground in a new session, so it outlives the
+// terminal, and logs to --log-file or to server.log in the logs directory.
+// It is known to run once it records its PID in server.json.
+//
+// Parameters:
+//   - opts: Serve command options
+//
+// Returns:
+//   - Error if the server cannot be started or exits during startup
+func startServeInBackground(opts *ServeOptions) error {
+	executable, err := os.Executable()
+	if err != nil {
+		return fmt.Errorf("failed to find the xw executable: %w", err)
+	}
+
+	cfg := config.NewConfigWithCustomDirs(opts.ConfigDir, opts.DataDir)
+	if err := cfg.EnsureDirectories(); err != nil {
+		return fmt.Errorf("failed to create directories: %w", err)
+	}
+
+	// The last --foreground wins, so the copy runs in the foreground
+	args := append(os.Args[1:], "--foreground")
+	logFile := opts.LogFile
+	if logFile == "" {
+		logFile = filepath.Join(cfg.Storage.GetLogsDir(), "server.log")
+		args = append(args, "--log-file", logFile)
+	}
+	logFile, err = filepath.Abs(logFile)
+	if err != nil {
+		return fmt.Errorf("invalid log file path: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(logFile), 0755); err != nil {
+		return fmt.Errorf("failed to create log directory: %w", err)
+	}
+
+	// Output written before the logger is set up goes to the log as well
+	output, err := os.OpenFile(logFile, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to open log file: %w", err)
+	}
+	defer output.Close()
+
+	child := exec.Command(executable, args...)
+	child.Stdout = output
+	child.Stderr = output
+	child.SysProcAttr = &syscall.SysProcAttr{Setsid: true}
+	if err := child.Start(); err != nil {
+		return fmt.Errorf("failed to start the server: %w", err)
+	}
+	pid := child.Process.Pid
+
+	exited := make(chan error, 1)
+	go func() {
+		exited <- child.Wait()
+	}()
+
+	deadline := time.After(serveStartTimeout)
+	ticker := time.NewTicker(200 * time.Millisecond)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case err := <-exited:
+			return fmt.Errorf("server exited during startup (%v); see %s", err, logFile)
+		case <-deadline:
+			fmt.Printf("Server (PID %d) is still starting; see %s\n", pid, logFile)
+			return nil
+		case <-ticker.C:
+			info, err := config.ReadServerInfo(cfg.Storage.ConfigDir)
+			if err != nil || info.PID != pid {
+				continue
+			}
+			fmt.Printf("xw server running in the background at %s (PID %d)\n", info.Address, pid)
+			fmt.Printf("Logs: %s\n", logFile)
+			fmt.Printf("Stop it with: kill %d\n", pid)
+			return nil
+		}
+	}
+}
+
+// shutdownTimeout bounds each phase of a graceful shutdown: waiting for
+// requests in flight and, with --stop-instances, stopping instances.
+const shutdownTimeout = 30 * time.Second
+
+// maxPortProbes is the number of consecutive ports tried when the default
+// port is in use.
+const maxPortProbes = 20
+
+// findFreePort returns the first port from start upward that can be bound
+// on host, trying at most maxPortProbes ports.
+//
+// The probe listener is closed before returning, so another process could
+// in principle take the port before the server binds it; the server then
+// fails with the usual "address already in use" error.
+//
+// Parameters:
+//   - host: Host address the server will listen on
+//   - start: First port to try
+//
+// Returns:
+//   - A free port (start itself if it is free)
+//   - Error if no free port is found or binding fails for another reason
+func findFreePort(host string, start int) (int, error) {
+	for port := start; port < start+maxPortProbes && port <= 65535; port++ {
+		ln, err := net.Listen("tcp", net.JoinHostPort(host, strconv.Itoa(port)))
+		if err == nil {
+			ln.Close()
+			return port, nil
+		}
+		if !errors.Is(err, syscall.EADDRINUSE) {
+			return 0, fmt.Errorf("failed to listen on %s:%d: %w", host, port, err)
+		}
+	}
+	return 0, fmt.Errorf("no free port found in %d-%d", start, start+maxPortProbes-1)
+}
+
 // isAddressInUse checks if the error is due to address already in use
 func isAddressInUse(err error) bool {
 	return err != nil && (
@@ -305,6 +305,13 @@ func displayModelfile(info map[string]interface{}) {
 func displayParameters(info map[string]interface{}) {
 	if params, ok := info["inference_parameters"].(map[string]interface{}); ok && len(params) > 0 {
 		for key, value := range params {
+			// Repeated parameters (e.g., stop) are listed once per value
+			if values, ok := value.([]interface{}); ok {
+				for _, v := range values {
+					fmt.Printf("%s %v\n", key, v)
+				}
+				continue
+			}
 			fmt.Printf("%s %v\n", key, value)
 		}
 		return
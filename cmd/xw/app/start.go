@@ -5,12 +5,18 @@ import (
 	"fmt"
 	"os"
 	"os/signal"
+	"path/filepath"
+	goruntime "runtime"
 	"sort"
 	"strings"
 	"syscall"
+	"time"
 
+	"github.com/docker/go-units"
 	"github.com/spf13/cobra"
+	"github.com/tsingmaoai/xw-cli/cmd/xw/client"
 	"github.com/tsingmaoai/xw-cli/internal/api"
+	"github.com/tsingmaoai/xw-cli/internal/hooks"
 )
 
 // StartOptions holds options for the start command
@@ -23,7 +29,7 @@ type StartOptions struct {
 	// Alias is the instance alias for inference (defaults to model name)
 	Alias string
 	
-	// Engine is the inference engine in format "backend:mode" (e.g., "vllm:docker", "mindie:native")
+	// Engine is the inference engine in format "backend:mode" (e.g., "vllm:docker", "vllm:native")
 	Engine string
 
 	// Device is the device list (e.g., "0", "0,1,2,3")
@@ -35,8 +41,47 @@ type StartOptions struct {
 	// MaxConcurrent is the maximum number of concurrent requests (0 for unlimited)
 	MaxConcurrent int
 	
+	// MaxRetries is the proxy retry budget for transient backend failures
+	// (-1 to use the server default)
+	MaxRetries int
+	
+	// RestartMax is the number of automatic restarts allowed after crashes
+	// within the supervision window (0 disables auto-restart)
+	RestartMax int
+	
 	// Detach runs the instance in the background (default: false, run in foreground with logs)
 	Detach bool
+	
+	// DryRun prints the resolved docker run command instead of creating the container
+	DryRun bool
+	
+	// Arch selects the runtime image architecture (defaults to the host architecture)
+	Arch string
+	
+	// ImageArchive is a `docker save` archive to load if the runtime image is missing
+	ImageArchive string
+	
+	// Env holds extra container environment variables in KEY=VALUE form
+	Env []string
+	
+	// MaxModelLen is the maximum sequence length served by the engine (0 for engine default)
+	MaxModelLen int
+	
+	// GPUMemoryUtilization is the fraction of device memory the engine may use (0 for engine default)
+	GPUMemoryUtilization float64
+	
+	// ShmSize is the container shared memory size (e.g., "16g"; empty for the runtime default)
+	ShmSize string
+	
+	// KeepFailed keeps the container of an instance that fails to start
+	// so its logs can be inspected (removed later with 'xw rm --failed')
+	KeepFailed bool
+	
+	// Wait blocks until the instance answers its health endpoint
+	Wait bool
+	
+	// WaitTimeout bounds how long --wait waits for the instance to become ready
+	WaitTimeout time.Duration
 }
 
 // NewStartCommand creates the start command.
@@ -58,6 +103,9 @@ type StartOptions struct {
 //	# Start with custom alias
 //	xw start qwen2-7b --alias my-model
 //
+//	# Start a second, named instance of the same model
+//	xw start qwen2-7b --name qwen2-7b-b --device 1
+//
 // Parameters:
 //   - globalOpts: Global options shared across commands
 //
@@ -74,19 +122,22 @@ func NewStartCommand(globalOpts *GlobalOptions) *cobra.Command {
 		Long: `Start a model instance for inference.
 
 The start command manages the lifecycle of model instances, supporting both Docker
-and native deployment modes. Starting the same model multiple times will return 
-the existing instance rather than creating duplicates.
+and native deployment modes. Instances are identified by their alias, which
+defaults to the model name. Use --name to run several instances of the same
+model side by side (e.g., with different devices or settings); requests for the
+model name are balanced across them.
 
 Engine Selection:
-  Engine is specified as "backend:mode" (e.g., "vllm:docker", "mindie:native").
+  Engine is specified as "backend:mode" (e.g., "vllm:docker", "vllm:native").
   If not specified, xw will automatically select the best available engine
-  based on the model's preferences and your system configuration.
+  based on the model's preferences and your system configuration. The mode
+  may be omitted (e.g., --engine vllm), in which case it is taken from the
+  model's engine list, or docker if the model does not list the backend.
   
   Available engines:
     - vllm:docker   - vLLM in Docker container (recommended)
-    - vllm:native   - vLLM native installation
+    - vllm:native   - vLLM installed on the host (requires 'vllm' in the server's PATH)
     - mindie:docker - MindIE in Docker container
-    - mindie:native - MindIE native installation
 
 Device Selection:
   Specify which AI accelerator devices to use (e.g., --device 0 or --device 0,1,2,3)
@@ -96,11 +147,36 @@ Concurrency Control:
   Use --max-concurrent to limit concurrent inference requests per instance.
   Default: 0 (unlimited). Useful for controlling load on the inference service.
 
+Engine Limits:
+  Use --max-model-len to cap the context length served by the engine and
+  --gpu-memory-utilization (0-1] to set the share of device memory it may
+  reserve. Lower either one if the engine fails to start with out-of-memory
+  errors; both default to the engine's own settings.
+
+Environment Variables:
+  Use --env KEY=VALUE (repeatable) to pass extra environment variables to the
+  instance container, e.g. to raise engine log levels while diagnosing a
+  failed start. Device and model variables set by xw take precedence.
+
 Foreground vs Background:
   By default, the instance runs in foreground mode with log streaming.
   Press Ctrl+C to stop and remove the instance.
   Use -d/--detach to run in background mode (keeps running after command exits).
 
+Waiting for Readiness:
+  Engines can take many minutes to load weights after the container starts.
+  The start waits until the instance answers its health endpoint; if the
+  engine exits first or is not ready within 30 minutes, the start fails with
+  the last container log lines. Use --wait to change that limit with
+  --wait-timeout. Combine with -d in scripts that need a serveable model
+  before continuing.
+
+Keeping Failed Instances:
+  A container that fails to start, fails --wait or exits in the foreground
+  is normally removed together with its logs. Use --keep-failed to leave it
+  in place, listed as failed in 'xw ps', so 'xw logs' can show the crash
+  output. Remove kept instances with 'xw rm ALIAS' or 'xw rm --failed'.
+
 Examples:
   # Start in foreground (default) - shows logs, Ctrl+C to stop
   xw start qwen2-7b
@@ -108,11 +184,37 @@ Examples:
   # Start in background (detached)
   xw start qwen2-7b -d
 
+  # Start in background and return once the model can serve requests
+  xw start qwen2-7b -d --wait --wait-timeout 20m
+
   # Start with specific engine in foreground
   xw start qwen2-7b --engine vllm:docker
 
   # Start on specific devices with concurrency limit
-  xw start qwen2-72b --device 0,1,2,3 --max-concurrent 4`,
+  xw start qwen2-72b --device 0,1,2,3 --max-concurrent 4
+
+  # Show the docker run command without starting anything
+  xw start glm-ocr --dry-run
+
+  # Use the arm64 image configured for the chip (e.g., to test cross-arch images)
+  xw start qwen2-7b --arch arm64
+
+  # Load the runtime image from an archive on an air-gapped host
+  xw start qwen2-7b --image-archive /data/vllm-ascend.tar
+
+  # Serve a shorter context with more memory headroom
+  xw start qwen3-32b --max-model-len 16384 --gpu-memory-utilization 0.85
+
+  # Raise the Ascend log level to debug a failing container
+  xw start qwen2-7b --env ASCEND_GLOBAL_LOG_LEVEL=3 --env ASCEND_SLOG_PRINT_TO_STDOUT=1
+
+  # Keep the container if the engine crashes, then read its output
+  xw start qwen2-7b -d --wait --keep-failed
+  xw logs qwen2-7b
+
+  # Run two instances of the same model on different devices
+  xw start qwen3-32b --name qwen3-a --device 0,1
+  xw start qwen3-32b --name qwen3-b --device 2,3`,
 		Args: cobra.ExactArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			opts.Model = args[0]
@@ -122,16 +224,43 @@ Examples:
 	
 	cmd.Flags().StringVar(&opts.Alias, "alias", "", 
 		"instance alias for inference (defaults to model name)")
+	cmd.Flags().StringVar(&opts.Alias, "name", "", 
+		"instance name, same as --alias; allows several instances of one model")
+	cmd.MarkFlagsMutuallyExclusive("alias", "name")
 	cmd.Flags().StringVar(&opts.Engine, "engine", "", 
-		"inference engine in format backend:mode (e.g., vllm:docker, mindie:native)")
+		"inference engine in format backend:mode (e.g., vllm:docker, vllm:native)")
 	cmd.Flags().StringVar(&opts.Device, "device", "", 
 		"device list (e.g., 0 or 0,1,2,3)")
 	cmd.Flags().IntVar(&opts.TensorParallel, "tp", 0, 
 		"tensor parallelism degree (must be 1, 2, 4, or 8)")
 	cmd.Flags().IntVar(&opts.MaxConcurrent, "max-concurrent", 0, 
 		"maximum concurrent requests (0 for unlimited)")
+	cmd.Flags().IntVar(&opts.MaxRetries, "max-retries", -1,
+		"proxy retries on transient backend failures such as 502/503 (-1 for server default)")
+	cmd.Flags().IntVar(&opts.RestartMax, "restart-max", 0,
+		"restart the instance automatically up to N times within 10 minutes if it crashes (0 to disable)")
 	cmd.Flags().BoolVarP(&opts.Detach, "detach", "d", false,
 		"run instance in the background (default: run in foreground with logs)")
+	cmd.Flags().BoolVar(&opts.DryRun, "dry-run", false,
+		"print the equivalent docker run command without creating the container")
+	cmd.Flags().StringVar(&opts.Arch, "arch", goruntime.GOARCH,
+		"runtime image architecture to use from devices.yaml (arm64 or amd64)")
+	cmd.Flags().StringVar(&opts.ImageArchive, "image-archive", "",
+		"docker save archive (.tar) to load the runtime image from instead of pulling")
+	cmd.Flags().StringArrayVarP(&opts.Env, "env", "e", nil,
+		"set an environment variable in the instance container (KEY=VALUE, repeatable)")
+	cmd.Flags().IntVar(&opts.MaxModelLen, "max-model-len", 0,
+		"maximum sequence length served by the engine (0 for engine default)")
+	cmd.Flags().Float64Var(&opts.GPUMemoryUtilization, "gpu-memory-utilization", 0,
+		"fraction of device memory the engine may use, in (0, 1] (0 for engine default)")
+	cmd.Flags().StringVar(&opts.ShmSize, "shm-size", "",
+		"container shared memory size, e.g. 16g or 512m (default: runtime default, at most half of host memory)")
+	cmd.Flags().BoolVar(&opts.KeepFailed, "keep-failed", false,
+		"keep the container if the instance fails to start, for 'xw logs' (remove with 'xw rm --failed')")
+	cmd.Flags().BoolVar(&opts.Wait, "wait", false,
+		"wait until the instance is ready to serve requests")
+	cmd.Flags().DurationVar(&opts.WaitTimeout, "wait-timeout", 30*time.Minute,
+		"maximum time to wait for readiness with --wait")
 	
 	return cmd
 }
@@ -140,20 +269,37 @@ Examples:
 func runStart(opts *StartOptions) error {
 	client := getClient(opts.GlobalOptions)
 
-	// Parse engine string (format: "backend:mode")
+	// Parse engine string (format: "backend:mode" or "backend")
 	// Only basic format check, real validation happens on server side
 	var backendType api.BackendType
 	var deploymentMode api.DeploymentMode
 	
 	if opts.Engine != "" {
 		parts := strings.Split(opts.Engine, ":")
-		if len(parts) != 2 {
+		if len(parts) > 2 || parts[0] == "" {
 			fmt.Fprintf(os.Stderr, "Error: Invalid engine format: %s\n", opts.Engine)
-			fmt.Fprintf(os.Stderr, "Expected format: backend:mode (e.g., vllm:docker, mindie:native)\n")
+			fmt.Fprintf(os.Stderr, "Expected format: backend:mode or backend (e.g., vllm:docker, mindie)\n")
 			os.Exit(1)
 		}
 		backendType = api.BackendType(parts[0])
-		deploymentMode = api.DeploymentMode(parts[1])
+		if len(parts) == 2 {
+			deploymentMode = api.DeploymentMode(parts[1])
+		}
+	}
+
+	if opts.Wait && opts.WaitTimeout <= 0 {
+		return fmt.Errorf("--wait-timeout must be positive (got %s)", opts.WaitTimeout)
+	}
+	if opts.MaxModelLen < 0 {
+		return fmt.Errorf("--max-model-len must be positive (got %d)", opts.MaxModelLen)
+	}
+	if opts.GPUMemoryUtilization < 0 || opts.GPUMemoryUtilization > 1 {
+		return fmt.Errorf("--gpu-memory-utilization must be greater than 0 and at most 1 (got %g)", opts.GPUMemoryUtilization)
+	}
+	if opts.ShmSize != "" {
+		if size, err := units.RAMInBytes(opts.ShmSize); err != nil || size <= 0 {
+			return fmt.Errorf("--shm-size must be a positive size such as 16g or 512m (got %q)", opts.ShmSize)
+		}
 	}
 
 	// Prepare additional config for device and concurrency
@@ -167,6 +313,47 @@ func runStart(opts *StartOptions) error {
 	if opts.MaxConcurrent > 0 {
 		additionalConfig["max_concurrent"] = opts.MaxConcurrent
 	}
+	if opts.MaxRetries >= 0 {
+		additionalConfig["max_retries"] = opts.MaxRetries
+	}
+	if opts.RestartMax > 0 {
+		additionalConfig["restart_max"] = opts.RestartMax
+	}
+	if opts.MaxModelLen > 0 {
+		additionalConfig["max_model_len"] = opts.MaxModelLen
+	}
+	if opts.GPUMemoryUtilization > 0 {
+		additionalConfig["gpu_memory_utilization"] = opts.GPUMemoryUtilization
+	}
+	if opts.ShmSize != "" {
+		additionalConfig["shm_size"] = opts.ShmSize
+	}
+	if opts.KeepFailed {
+		additionalConfig["keep_failed"] = true
+	}
+	if opts.Wait {
+		// The server waits for readiness before it reports the start
+		additionalConfig["ready_timeout"] = int(opts.WaitTimeout.Seconds())
+	}
+	if opts.DryRun {
+		additionalConfig["dry_run"] = true
+	}
+	if opts.Arch != "" {
+		additionalConfig["arch"] = opts.Arch
+	}
+	if opts.ImageArchive != "" {
+		// The server resolves the path, so make it absolute
+		archive, err := filepath.Abs(opts.ImageArchive)
+		if err != nil {
+			return fmt.Errorf("invalid --image-archive path: %w", err)
+		}
+		additionalConfig["image_archive"] = archive
+	}
+
+	environment, err := parseEnvFlags(opts.Env)
+	if err != nil {
+		return err
+	}
 
 	// Prepare run options as a map matching server's expected JSON structure
 	runOpts := map[string]interface{}{
@@ -177,6 +364,9 @@ func runStart(opts *StartOptions) error {
 		"interactive":       false,
 		"additional_config": additionalConfig,
 	}
+	if len(environment) > 0 {
+		runOpts["environment"] = environment
+	}
 
 	// Display startup message
 	engineStr := string(backendType)
@@ -211,9 +401,7 @@ func runStart(opts *StartOptions) error {
 
 	// Start the model instance via server API with SSE streaming
 	progressDisplay := newProgressDisplay()
-	instanceInfo, err := client.RunModelWithSSEContext(ctx, runOpts, func(event string) {
-		progressDisplay.update(event)
-	})
+	instanceInfo, err := client.RunModelWithSSEEvents(ctx, runOpts, progressDisplay.update, progressDisplay.phase, progressDisplay.confirm)
 	progressDisplay.finish()
 	
 	// Stop signal handler
@@ -227,6 +415,17 @@ func runStart(opts *StartOptions) error {
 		os.Exit(1)
 	}
 	
+	// Dry run: show the resolved container configuration and stop here
+	if opts.DryRun {
+		command, _ := instanceInfo["dry_run_command"].(string)
+		if command == "" {
+			return fmt.Errorf("server did not return a dry run command (is the server up to date?)")
+		}
+		fmt.Println()
+		fmt.Println(command)
+		return nil
+	}
+	
 	// Get instance alias from response
 	var instanceAlias string
 	if instanceInfo != nil {
@@ -245,9 +444,24 @@ func runStart(opts *StartOptions) error {
 	
 	// Success
 	fmt.Println()
-	fmt.Println("✓ Resources pre-allocated. Initializing inference service...")
+	fmt.Println("✓ Inference service is ready.")
 	fmt.Println()
 	
+	// Optionally block until the engine has finished warming up
+	if opts.Wait {
+		if err := waitForInstanceReady(client, instanceAlias, opts.WaitTimeout); err != nil {
+			if opts.KeepFailed {
+				printKeptInstanceHint(instanceAlias)
+			} else if !opts.Detach {
+				fmt.Printf("Removing %s...\n", instanceAlias)
+				if rmErr := client.RemoveInstanceByAlias(instanceAlias, true, 0); rmErr != nil {
+					fmt.Fprintf(os.Stderr, "Warning: failed to remove instance: %v\n", rmErr)
+				}
+			}
+			return err
+		}
+	}
+	
 	// If detach mode, just show info and return
 	if opts.Detach {
 		fmt.Println("Use 'xw ps' to view running instances")
@@ -265,7 +479,7 @@ func runStart(opts *StartOptions) error {
 	// Start log streaming in a goroutine
 	logDone := make(chan error, 1)
 	go func() {
-		err := client.StreamInstanceLogs(instanceAlias, true, func(logLine string) {
+		err := client.StreamInstanceLogs(instanceAlias, true, 0, func(logLine string) {
 			fmt.Print(logLine)
 			// Force flush stdout for real-time output
 			os.Stdout.Sync()
@@ -280,7 +494,7 @@ func runStart(opts *StartOptions) error {
 		fmt.Printf("Removing %s...\n", instanceAlias)
 		
 		// Remove the instance directly (no need to stop first)
-		if err := client.RemoveInstanceByAlias(instanceAlias, true); err != nil {
+		if err := client.RemoveInstanceByAlias(instanceAlias, true, 0); err != nil {
 			fmt.Fprintf(os.Stderr, "Warning: failed to remove instance: %v\n", err)
 		} else {
 			fmt.Printf("Removed instance: %s\n", instanceAlias)
@@ -293,9 +507,15 @@ func runStart(opts *StartOptions) error {
 			fmt.Println("\nLog stream ended")
 		}
 		
+		// Keep an instance that exited on its own for inspection if requested
+		if opts.KeepFailed {
+			printKeptInstanceHint(instanceAlias)
+			return nil
+		}
+		
 		// Auto cleanup when log stream ends - remove directly
 		fmt.Printf("Cleaning up %s...\n", instanceAlias)
-		if err := client.RemoveInstanceByAlias(instanceAlias, true); err != nil {
+		if err := client.RemoveInstanceByAlias(instanceAlias, true, 0); err != nil {
 			fmt.Fprintf(os.Stderr, "Warning: failed to remove instance: %v\n", err)
 		} else {
 			fmt.Printf("Removed instance: %s\n", instanceAlias)
@@ -305,6 +525,207 @@ func runStart(opts *StartOptions) error {
 	return nil
 }
 
+// printKeptInstanceHint tells the user how to inspect and clean up an
+// instance kept by --keep-failed.
+func printKeptInstanceHint(alias string) {
+	fmt.Printf("Kept %s for inspection (--keep-failed)\n", alias)
+	fmt.Printf("  View its output:  xw logs %s\n", alias)
+	fmt.Printf("  Remove it:        xw rm %s (or 'xw rm --failed' for all failed instances)\n", alias)
+}
+
+// waitForInstanceReady polls an instance until it answers its health
+// endpoint, showing a spinner with the elapsed time.
+//
+// The instance's state is checked on every poll so that a crash during
+// warm-up is reported immediately instead of after the full timeout. On
+// timeout or failure the tail of the container logs is printed to help
+// diagnose why the engine did not come up.
+//
+// Parameters:
+//   - c: API client
+//   - alias: Alias of the instance to wait for
+//   - timeout: Maximum time to wait
+//
+// Returns:
+//   - nil once the instance is ready
+//   - Error if the instance failed, the timeout expired, or the wait was interrupted
+func waitForInstanceReady(c *client.Client, alias string, timeout time.Duration) error {
+	spinners := []string{"⠋", "⠙", "⠹", "⠸", "⠼", "⠴", "⠦", "⠧", "⠇", "⠏"}
+	const pollInterval = 3 * time.Second
+	
+	sigChan := make(chan os.Signal, 1)
+	signal.Notify(sigChan, os.Interrupt, syscall.SIGTERM)
+	defer signal.Stop(sigChan)
+	
+	spinnerTicker := time.NewTicker(100 * time.Millisecond)
+	defer spinnerTicker.Stop()
+	
+	start := time.Now()
+	deadline := time.After(timeout)
+	nextPoll := time.After(0)
+	lastMessage := ""
+	
+	for i := 0; ; i++ {
+		select {
+		case <-sigChan:
+			fmt.Print("\r\033[K")
+			return fmt.Errorf("interrupted while waiting for %s to become ready", alias)
+			
+		case <-deadline:
+			fmt.Print("\r\033[K")
+			fmt.Printf("✗ %s did not become ready within %s", alias, timeout)
+			if lastMessage != "" {
+				fmt.Printf(" (%s)", lastMessage)
+			}
+			fmt.Println()
+			printInstanceLogTail(c, alias)
+			return fmt.Errorf("timed out waiting for %s to become ready", alias)
+			
+		case <-nextPoll:
+			state, errMsg := instanceState(c, alias)
+			if state == "error" || state == "failed" {
+				fmt.Print("\r\033[K")
+				fmt.Printf("✗ %s failed to start (state: %s)\n", alias, state)
+				if errMsg != "" {
+					fmt.Printf("  Error: %s\n", errMsg)
+				}
+				printInstanceLogTail(c, alias)
+				return fmt.Errorf("instance %s failed to start", alias)
+			}
+			
+			ready, err := c.CheckInstanceReady(alias)
+			if err == nil && ready {
+				fmt.Print("\r\033[K")
+				fmt.Printf("✓ %s is ready (%s)\n", alias, time.Since(start).Round(time.Second))
+				return nil
+			}
+			if state != "" {
+				lastMessage = "state: " + state
+			}
+			nextPoll = time.After(pollInterval)
+			
+		case <-spinnerTicker.C:
+			fmt.Printf("\r%s Waiting for %s to be ready... %s", spinners[i%len(spinners)], alias,
+				time.Since(start).Round(time.Second))
+		}
+	}
+}
+
+// instanceState returns the current state and error message of an instance,
+// or empty strings if it cannot be found.
+func instanceState(c *client.Client, alias string) (string, string) {
+	instMap := findInstance(c, alias)
+	state, _ := instMap["state"].(string)
+	errMsg, _ := instMap["error"].(string)
+	return state, errMsg
+}
+
+// findInstance returns the listing of the instance with the given alias,
+// or nil if it cannot be found.
+func findInstance(c *client.Client, alias string) map[string]interface{} {
+	instances, err := c.ListInstances(true)
+	if err != nil {
+		return nil
+	}
+	for _, inst := range instances {
+		instMap, ok := inst.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		if instAlias, _ := instMap["alias"].(string); instAlias == alias {
+			return instMap
+		}
+	}
+	return nil
+}
+
+// printInstanceLogTail prints the last container log lines of an instance.
+func printInstanceLogTail(c *client.Client, alias string) {
+	const tailLines = 50
+	
+	fmt.Printf("\nLast %d log lines from %s:\n", tailLines, alias)
+	err := c.StreamInstanceLogs(alias, false, tailLines, func(logLine string) {
+		fmt.Print(logLine)
+	})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: failed to fetch logs: %v\n", err)
+	}
+	fmt.Println()
+	
+	printInstanceLogFiles(c, alias)
+}
+
+// printInstanceLogFiles prints the end of the newest engine log files of an
+// instance. Engines write their detailed errors (e.g., the CANN stack behind
+// "RuntimeError: Execute fail") to these files rather than to the container
+// output. If the directory cannot be read, e.g. because the server runs on
+// another host, only its location is printed.
+func printInstanceLogFiles(c *client.Client, alias string) {
+	const maxFiles = 3
+	const tailLines = 20
+	
+	logDir, _ := findInstance(c, alias)["log_dir"].(string)
+	if logDir == "" {
+		return
+	}
+	
+	type logFile struct {
+		path    string
+		modTime time.Time
+	}
+	var files []logFile
+	filepath.WalkDir(logDir, func(path string, d os.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return nil
+		}
+		if info, err := d.Info(); err == nil && info.Size() > 0 {
+			files = append(files, logFile{path: path, modTime: info.ModTime()})
+		}
+		return nil
+	})
+	if len(files) == 0 {
+		fmt.Printf("Engine log files are kept on the server in %s\n", logDir)
+		return
+	}
+	
+	sort.Slice(files, func(i, j int) bool {
+		return files[i].modTime.After(files[j].modTime)
+	})
+	if len(files) > maxFiles {
+		files = files[:maxFiles]
+	}
+	for _, f := range files {
+		data, err := os.ReadFile(f.path)
+		if err != nil {
+			continue
+		}
+		lines := strings.Split(strings.TrimRight(string(data), "\n"), "\n")
+		if len(lines) > tailLines {
+			lines = lines[len(lines)-tailLines:]
+		}
+		fmt.Printf("Last %d lines of %s:\n", len(lines), f.path)
+		fmt.Println(strings.Join(lines, "\n"))
+		fmt.Println()
+	}
+	fmt.Printf("All engine log files are kept in %s\n", logDir)
+}
+
+// parseEnvFlags converts repeated --env KEY=VALUE flags into a map.
+//
+// The value may be empty or contain '=' characters; only the key is
+// required. Later flags override earlier ones for the same key.
+func parseEnvFlags(flags []string) (map[string]string, error) {
+	env := make(map[string]string, len(flags))
+	for _, flag := range flags {
+		key, value, ok := strings.Cut(flag, "=")
+		key = strings.TrimSpace(key)
+		if !ok || key == "" {
+			return nil, fmt.Errorf("invalid --env value %q: expected KEY=VALUE", flag)
+		}
+		env[key] = value
+	}
+	return env, nil
+}
 
 // progressDisplay handles progress display
 type progressDisplay struct {
@@ -313,6 +734,7 @@ type progressDisplay struct {
 	dockerFirstLine bool              // Track if this is the first Docker output line
 	layers          map[string]string // Layer ID -> current status line
 	lastLineCount   int               // Number of lines last rendered
+	pullPercent     int               // Overall image pull percentage (0 if unknown)
 }
 
 // newProgressDisplay creates a new progress display
@@ -358,6 +780,7 @@ func (pd *progressDisplay) update(event string) {
 		pd.dockerFirstLine = true
 		pd.layers = make(map[string]string)
 		pd.lastLineCount = 0
+		pd.pullPercent = 0
 		fmt.Printf("\n▸ %s\n", event)
 		return
 	}
@@ -386,6 +809,37 @@ func (pd *progressDisplay) update(event string) {
 	fmt.Printf("▸ %s\n", event)
 }
 
+// phase processes a startup phase event.
+//
+// Image pull progress updates the overall percentage shown below the layer
+// lines; the start and end of the pull are already reported by progress
+// messages. Other phases are printed as steps.
+func (pd *progressDisplay) phase(event api.StartupEvent) {
+	if event.Phase == api.StartupPhasePullingImage {
+		if event.Percent > 0 && event.Percent < 100 && pd.isPulling {
+			pd.pullPercent = event.Percent
+			pd.renderLayers()
+		}
+		return
+	}
+	
+	message := event.Message
+	if message == "" {
+		message = strings.ReplaceAll(string(event.Phase), "_", " ")
+	}
+	fmt.Printf("▸ %s\n", message)
+}
+
+// confirm asks the user whether the server may install a missing
+// dependency. Without a terminal on stdin, installations are declined.
+func (pd *progressDisplay) confirm(ctx context.Context, confirmation api.InstallConfirmation) (bool, error) {
+	if !hooks.StdinIsTerminal() {
+		fmt.Printf("▸ %s\n", confirmation.Message)
+		return false, nil
+	}
+	return hooks.NewTerminalPrompter(os.Stdin, os.Stdout).Ask(ctx, confirmation.Name, confirmation.Message)
+}
+
 // isLayerLine checks if a line is a layer status line (layerID: status)
 func (pd *progressDisplay) isLayerLine(line string) bool {
 	parts := strings.SplitN(line, ":", 2)
@@ -446,6 +900,11 @@ func (pd *progressDisplay) renderLayers() {
 	// Sort for consistent display
 	sort.Strings(lines)
 	
+	// The overall percentage goes below the layers
+	if pd.pullPercent > 0 {
+		lines = append(lines, fmt.Sprintf("Total: %d%%", pd.pullPercent))
+	}
+	
 	for _, line := range lines {
 		// Clear line and print
 		fmt.Print("\r\033[K")
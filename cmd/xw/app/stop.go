@@ -2,6 +2,7 @@ package app
 
 import (
 	"fmt"
+	"time"
 
 	"github.com/spf13/cobra"
 )
@@ -15,6 +16,12 @@ type StopOptions struct {
 
 	// Force forces stop even if instance is in use
 	Force bool
+
+	// DrainTimeout is the maximum time to wait for in-flight requests
+	DrainTimeout time.Duration
+
+	// All stops every instance
+	All bool
 }
 
 // NewStopCommand creates the stop command.
@@ -24,6 +31,7 @@ type StopOptions struct {
 // Usage:
 //
 //	xw stop ALIAS [OPTIONS]
+//	xw stop --all [OPTIONS]
 //
 // Examples:
 //
@@ -33,6 +41,12 @@ type StopOptions struct {
 //	# Force stop and remove
 //	xw stop test --force
 //
+//	# Wait up to 2 minutes for in-flight requests
+//	xw stop my-model --drain-timeout 2m
+//
+//	# Stop and remove every instance
+//	xw stop --all
+//
 // Parameters:
 //   - globalOpts: Global options shared across commands
 //
@@ -44,7 +58,7 @@ func NewStopCommand(globalOpts *GlobalOptions) *cobra.Command {
 	}
 
 	cmd := &cobra.Command{
-		Use:   "stop ALIAS",
+		Use:   "stop ALIAS | --all",
 		Short: "Stop and remove a running model instance",
 		Long: `Stop and remove a running model instance by its alias.
 
@@ -53,21 +67,44 @@ The alias can be found using 'xw ps'. Stopping an instance will:
   - Remove the container and free resources
   - Permanently delete the instance
 
-Use --force to stop an instance even if it's currently processing requests.`,
+Before stopping, the instance is drained: new requests are no longer routed
+to it and requests already in flight are given up to --drain-timeout to
+complete. Use --force to stop immediately without draining.
+
+Use --all to stop every instance, e.g. to recover a host. Instances are
+stopped one by one; failures are reported and the remaining instances are
+still stopped.`,
 		Example: `  # Stop and remove an instance
   xw stop my-model
 
   # Force stop and remove
-  xw stop test --force`,
-		Args: cobra.ExactArgs(1),
+  xw stop test --force
+
+  # Wait up to 2 minutes for in-flight requests
+  xw stop my-model --drain-timeout 2m
+
+  # Stop and remove every instance
+  xw stop --all`,
+		Args: func(cmd *cobra.Command, args []string) error {
+			if opts.All {
+				return cobra.NoArgs(cmd, args)
+			}
+			return cobra.ExactArgs(1)(cmd, args)
+		},
 		RunE: func(cmd *cobra.Command, args []string) error {
-			opts.Alias = args[0]
+			if len(args) > 0 {
+				opts.Alias = args[0]
+			}
 			return runStop(opts)
 		},
 	}
 
 	cmd.Flags().BoolVarP(&opts.Force, "force", "f", false,
 		"force stop even if instance is in use")
+	cmd.Flags().DurationVar(&opts.DrainTimeout, "drain-timeout", 30*time.Second,
+		"maximum time to wait for in-flight requests before stopping (0 to skip draining)")
+	cmd.Flags().BoolVar(&opts.All, "all", false,
+		"stop and remove all instances")
 
 	return cmd
 }
@@ -77,9 +114,36 @@ Use --force to stop an instance even if it's currently processing requests.`,
 func runStop(opts *StopOptions) error {
 	client := getClient(opts.GlobalOptions)
 
+	if opts.DrainTimeout < 0 {
+		return fmt.Errorf("--drain-timeout must not be negative")
+	}
+
+	// --force skips draining and stops the instance immediately
+	drainTimeout := opts.DrainTimeout
+	if opts.Force {
+		drainTimeout = 0
+	}
+
+	if opts.All {
+		instances, err := listInstanceSummaries(client)
+		if err != nil {
+			return err
+		}
+		if len(instances) == 0 {
+			fmt.Println("No instances to stop")
+			return nil
+		}
+
+		aliases := make([]string, len(instances))
+		for i, inst := range instances {
+			aliases[i] = inst.alias
+		}
+		return removeInstances(client, aliases, drainTimeout, "Stopped and removed instance")
+	}
+
 	// Stop and remove the instance via server API (using alias)
 	// This now calls the remove API with force flag
-	err := client.RemoveInstanceByAlias(opts.Alias, true)
+	err := client.RemoveInstanceByAlias(opts.Alias, true, drainTimeout)
 	if err != nil {
 		return fmt.Errorf("failed to stop instance: %w", err)
 	}
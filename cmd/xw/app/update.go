@@ -143,7 +143,8 @@ func listVersions(c *client.Client) error {
 
 	if registryUnavailable {
 		fmt.Println("⚠ Unable to access remote package registry")
-		fmt.Println("  Showing locally installed versions only\n")
+		fmt.Println("  Showing locally installed versions only")
+		fmt.Println()
 		
 		if len(resp.InstalledVersions) > 0 {
 			fmt.Println("Installed versions:")
@@ -2,8 +2,11 @@ package app
 
 import (
 	"fmt"
+	"os"
+	"sort"
 
 	"github.com/spf13/cobra"
+	"github.com/tsingmaoai/xw-cli/internal/api"
 )
 
 // Version information (set by main package from ldflags)
@@ -69,8 +72,13 @@ func NewVersionCommand(globalOpts *GlobalOptions) *cobra.Command {
 		Short: "Display version information",
 		Long: `Display version information for the xw client and server.
 
-By default, shows version information for both the client and server. Use
---client or --server to show only one.`,
+By default, shows version information for both the client and server, and
+warns if they differ: a client and server of different versions may not
+agree on the API. Use --client or --server to show only one.
+
+With --verbose, the versions of the server's runtimes are shown as well,
+such as the Docker daemon used by Docker runtimes and the engines installed
+for native runtimes.`,
 		Example: `  # Show both client and server versions
   xw version
 
@@ -78,7 +86,10 @@ By default, shows version information for both the client and server. Use
   xw version --client
 
   # Show only server version
-  xw version --server`,
+  xw version --server
+
+  # Include the Docker and engine versions of the server's runtimes
+  xw version --verbose`,
 		Args: cobra.NoArgs,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			return runVersion(opts)
@@ -122,7 +133,13 @@ func runVersion(opts *VersionOptions) error {
 		}
 
 		client := getClient(opts.GlobalOptions)
-		resp, err := client.Version()
+		var resp *api.VersionResponse
+		var err error
+		if opts.Verbose {
+			resp, err = client.VersionWithRuntimes()
+		} else {
+			resp, err = client.Version()
+		}
 		if err != nil {
 			return fmt.Errorf("failed to get server version: %w", err)
 		}
@@ -130,6 +147,27 @@ func runVersion(opts *VersionOptions) error {
 		fmt.Println("Server Version:")
 		fmt.Printf("  Version:    %s\n", resp.Version)
 		fmt.Printf("  Build Time: %s\n", resp.BuildTime)
+
+		if opts.Verbose && len(resp.Runtimes) > 0 {
+			names := make([]string, 0, len(resp.Runtimes))
+			for name := range resp.Runtimes {
+				names = append(names, name)
+			}
+			sort.Strings(names)
+
+			fmt.Println()
+			fmt.Println("Runtimes:")
+			for _, name := range names {
+				fmt.Printf("  %-18s %s\n", name+":", resp.Runtimes[name])
+			}
+		}
+
+		if showClient && resp.Version != version {
+			fmt.Println()
+			fmt.Fprintf(os.Stderr, "Warning: client version %s differs from server version %s; "+
+				"commands may fail or behave unexpectedly. Use an xw client of the server's version.\n",
+				version, resp.Version)
+		}
 	}
 
 	return nil
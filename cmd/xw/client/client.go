@@ -20,7 +20,16 @@
 package client
 
 import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net"
 	"net/http"
+	"os"
+	"strings"
+
+	"github.com/tsingmaoai/xw-cli/internal/config"
 )
 
 // Client is the HTTP client for communicating with the xw server.
@@ -32,15 +41,27 @@ import (
 // The client uses a configurable HTTP client with sensible defaults for
 // timeouts and connection pooling.
 type Client struct {
-	// baseURL is the base URL of the xw server.
-	// Format: "http://host:port" (e.g., "http://localhost:11581")
+	// baseURL is the base URL requests are sent to.
+	// Format: "http://host:port" (e.g., "http://localhost:11581"), or
+	// unixSocketBaseURL for a server on a Unix socket
 	baseURL string
 
+	// address is the server address as given, shown in error messages.
+	address string
+
 	// httpClient is the underlying HTTP client used for requests.
 	// Configured with appropriate timeouts and connection settings.
 	httpClient *http.Client
 }
 
+// unixSocketScheme prefixes server addresses that are Unix socket paths,
+// e.g. "unix:///run/xw.sock".
+const unixSocketScheme = "unix://"
+
+// unixSocketBaseURL is the base URL of requests sent over a Unix socket.
+// The host is a placeholder; the transport always dials the socket.
+const unixSocketBaseURL = "http://xw"
+
 // NewClient creates a new client instance configured to communicate with
 // a specific xw server.
 //
@@ -48,9 +69,12 @@ type Client struct {
 //   - 30-second timeout for all requests
 //   - Automatic connection pooling and keep-alive
 //   - Automatic retry of idempotent requests
+//   - The XW_API_KEY environment variable, if set, sent as a bearer token
+//     to servers that require authentication
 //
 // Parameters:
-//   - baseURL: The base URL of the xw server (e.g., "http://localhost:11581")
+//   - baseURL: The base URL of the xw server (e.g., "http://localhost:11581"),
+//     or "unix://" followed by the path of the server's Unix socket
 //
 // Returns:
 //   - A pointer to a configured Client ready for use.
@@ -60,12 +84,90 @@ type Client struct {
 //	client := client.NewClient("http://localhost:11581")
 //	health, err := client.Health()
 func NewClient(baseURL string) *Client {
+	return newClient(baseURL, nil)
+}
+
+// NewClientWithCA creates a client like NewClient that also trusts the
+// certificate authorities in caFile for HTTPS servers.
+//
+// Servers on a LAN commonly use certificates issued by a private CA, which
+// the system certificate pool does not contain.
+//
+// Parameters:
+//   - baseURL: The base URL of the xw server (e.g., "https://xw.lan:11581")
+//   - caFile: Path of a PEM file with one or more CA certificates
+//
+// Returns:
+//   - A pointer to a configured Client ready for use
+//   - Error if the file cannot be read or holds no certificate
+func NewClientWithCA(baseURL, caFile string) (*Client, error) {
+	data, err := os.ReadFile(caFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read CA certificate: %w", err)
+	}
+
+	pool, err := x509.SystemCertPool()
+	if err != nil {
+		pool = x509.NewCertPool()
+	}
+	if !pool.AppendCertsFromPEM(data) {
+		return nil, fmt.Errorf("no PEM certificate found in %s", caFile)
+	}
+
+	return newClient(baseURL, &tls.Config{RootCAs: pool}), nil
+}
+
+// newClient creates a client, using tlsConfig for HTTPS servers if it is
+// not nil.
+func newClient(baseURL string, tlsConfig *tls.Config) *Client {
+	httpClient := &http.Client{
+		Timeout: 0, // No timeout for streaming operations (SSE)
+	}
+	address := baseURL
+
+	var transport http.RoundTripper = http.DefaultTransport
+	if socket, ok := strings.CutPrefix(baseURL, unixSocketScheme); ok {
+		transport = newUnixSocketTransport(socket)
+		baseURL = unixSocketBaseURL
+	} else if tlsConfig != nil {
+		tlsTransport := http.DefaultTransport.(*http.Transport).Clone()
+		tlsTransport.TLSClientConfig = tlsConfig
+		transport = tlsTransport
+	}
+	if key := strings.TrimSpace(os.Getenv(config.EnvAPIKey)); key != "" {
+		transport = &authTransport{key: key, base: transport}
+	}
+	httpClient.Transport = transport
+
 	return &Client{
-		baseURL: baseURL,
-		httpClient: &http.Client{
-			Timeout: 0, // No timeout for streaming operations (SSE)
-		},
+		baseURL:    baseURL,
+		address:    address,
+		httpClient: httpClient,
+	}
+}
+
+// newUnixSocketTransport returns a transport that sends every request over
+// the Unix socket at path, whatever the host in the request URL.
+func newUnixSocketTransport(path string) *http.Transport {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.DialContext = func(ctx context.Context, _, _ string) (net.Conn, error) {
+		var d net.Dialer
+		return d.DialContext(ctx, "unix", path)
 	}
+	return transport
+}
+
+// authTransport adds the server API key to every request.
+type authTransport struct {
+	key  string
+	base http.RoundTripper
+}
+
+// RoundTrip sets the Authorization header and forwards the request.
+func (t *authTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	req = req.Clone(req.Context())
+	req.Header.Set("Authorization", "Bearer "+t.key)
+	return t.base.RoundTrip(req)
 }
 
 // GetBaseURL returns the server's base URL.
@@ -74,9 +176,19 @@ func NewClient(baseURL string) *Client {
 // is configured to communicate with. The URL can be used directly for
 // API requests through the server's proxy.
 //
+// For a server on a Unix socket this is a placeholder URL, and requests
+// must be sent with HTTPClient.
+//
 // Returns:
 //   - The base URL string (e.g., "http://localhost:11581", "http://192.168.1.100:11581")
 func (c *Client) GetBaseURL() string {
 	return c.baseURL
 }
 
+// HTTPClient returns the HTTP client used to reach the server. It carries
+// the API key and, for a Unix socket address, dials the socket, so requests
+// built on GetBaseURL reach the server the same way API methods do.
+func (c *Client) HTTPClient() *http.Client {
+	return c.httpClient
+}
+
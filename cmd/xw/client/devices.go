@@ -11,17 +11,20 @@ import (
 
 // DeviceInfo represents device information returned from the server.
 type DeviceInfo struct {
-	VendorID            string   `json:"vendor_id"`
-	DeviceID            string   `json:"device_id"`
-	BusAddress          string   `json:"bus_address"`
-	ModelName           string   `json:"model_name"`
-	ConfigKey           string   `json:"config_key"`
-	DeviceType          string   `json:"device_type"`
-	Generation          string   `json:"generation"`
-	Capabilities        []string `json:"capabilities"`
-	PhysicalDeviceIndex int      `json:"physical_device_index"`
-	ChipIndex           int      `json:"chip_index"`
-	ChipsPerDevice      int      `json:"chips_per_device"`
+	VendorID            string            `json:"vendor_id"`
+	DeviceID            string            `json:"device_id"`
+	BusAddress          string            `json:"bus_address"`
+	ModelName           string            `json:"model_name"`
+	ConfigKey           string            `json:"config_key"`
+	VariantKey          string            `json:"variant_key,omitempty"`
+	DeviceType          string            `json:"device_type"`
+	Generation          string            `json:"generation"`
+	Capabilities        []string          `json:"capabilities"`
+	PhysicalDeviceIndex int               `json:"physical_device_index"`
+	ChipIndex           int               `json:"chip_index"`
+	ChipsPerDevice      int               `json:"chips_per_device"`
+	LogicalIndex        int               `json:"logical_index"`
+	Properties          map[string]string `json:"properties,omitempty"`
 }
 
 // ListDevices retrieves a list of devices detected on the server machine.
@@ -29,24 +32,32 @@ type DeviceInfo struct {
 // This method queries the server for all AI accelerator devices available
 // on the server hardware. It's used by the CLI to display device information.
 //
+// Parameters:
+//   - live: Also collect live utilization and memory metrics (slower, since
+//     the server runs the vendor monitoring tool)
+//
 // Returns:
 //   - A slice of DeviceInfo structs representing detected hardware
 //   - An error if the request fails or the server returns an error
 //
 // Example:
 //
-//	devices, err := client.ListDevices()
+//	devices, err := client.ListDevices(false)
 //	if err != nil {
 //	    log.Fatalf("Failed to list devices: %v", err)
 //	}
 //	for _, device := range devices {
 //	    fmt.Printf("%s: %s (available=%v)\n", device.Type, device.Name, device.Available)
 //	}
-func (c *Client) ListDevices() ([]DeviceInfo, error) {
+func (c *Client) ListDevices(live bool) ([]DeviceInfo, error) {
 	var resp struct {
 		Devices []DeviceInfo `json:"devices"`
 	}
-	if err := c.doRequest("GET", "/api/devices/list", nil, &resp); err != nil {
+	path := "/api/devices/list"
+	if live {
+		path += "?live=true"
+	}
+	if err := c.doRequest("GET", path, nil, &resp); err != nil {
 		return nil, err
 	}
 	return resp.Devices, nil
@@ -79,3 +90,17 @@ func (c *Client) GetSupportedDevices() ([]string, error) {
 	return resp.DeviceTypes, nil
 }
 
+
+// ListDeviceAllocations retrieves which instance each device on the server
+// is allocated to.
+//
+// Returns:
+//   - The allocation state of every device
+//   - An error if the request fails or the server returns an error
+func (c *Client) ListDeviceAllocations() (*api.DeviceAllocationsResponse, error) {
+	var resp api.DeviceAllocationsResponse
+	if err := c.doRequest("GET", "/api/devices/allocations", nil, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
@@ -14,6 +14,9 @@ import (
 	"io"
 	"net/http"
 	"strings"
+	"time"
+
+	"github.com/tsingmaoai/xw-cli/internal/api"
 )
 
 // RunModel starts a model instance.
@@ -62,12 +65,69 @@ func (c *Client) RunModelWithSSE(opts interface{}, progressCallback func(string)
 // Returns:
 //   - error if the request fails
 func (c *Client) RunModelWithSSEContext(ctx context.Context, opts interface{}, progressCallback func(string)) (map[string]interface{}, error) {
-	data, err := json.Marshal(opts)
+	return c.RunModelWithSSEEvents(ctx, opts, progressCallback, nil, nil)
+}
+
+// ConfirmFunc answers the server's question whether a missing dependency
+// may be installed on the server (see api.InstallConfirmation).
+type ConfirmFunc func(ctx context.Context, confirmation api.InstallConfirmation) (bool, error)
+
+// RunModelWithSSEEvents starts a model instance with SSE streaming, passing
+// typed startup phase events and free-form progress messages separately.
+//
+// Parameters:
+//   - ctx: Context for cancellation
+//   - opts: Runtime options for the model
+//   - progressCallback: Function called for each progress message
+//   - phaseCallback: Function called for each startup phase event; if nil,
+//     phase events are skipped (their messages duplicate progress messages)
+//   - confirmCallback: Function asked whether a missing dependency may be
+//     installed on the server; if nil, the server declines installations
+//
+// Returns:
+//   - Instance information from the final event
+//   - error if the request fails
+func (c *Client) RunModelWithSSEEvents(ctx context.Context, opts interface{}, progressCallback func(string), phaseCallback func(api.StartupEvent), confirmCallback ConfirmFunc) (map[string]interface{}, error) {
+	return c.streamStart(ctx, "/api/runtime/start", opts, progressCallback, phaseCallback, confirmCallback)
+}
+
+// RestartInstanceWithSSEEvents recreates a model instance on the server with
+// the options it was started with, streaming the progress of the new start
+// like RunModelWithSSEEvents.
+//
+// Parameters:
+//   - ctx: Context for cancellation
+//   - alias: Alias of the instance to restart
+//   - drainTimeout: Maximum time to wait for in-flight requests before
+//     stopping the instance (0 stops it immediately)
+//   - progressCallback: Function called for each progress message
+//   - phaseCallback: Function called for each startup phase event (may be nil)
+//   - confirmCallback: Function asked whether a missing dependency may be
+//     installed on the server (may be nil)
+//
+// Returns:
+//   - Instance information from the final event
+//   - error if the request fails
+func (c *Client) RestartInstanceWithSSEEvents(ctx context.Context, alias string, drainTimeout time.Duration, progressCallback func(string), phaseCallback func(api.StartupEvent), confirmCallback ConfirmFunc) (map[string]interface{}, error) {
+	reqBody := map[string]interface{}{
+		"alias":         alias,
+		"drain_timeout": drainTimeout.Seconds(),
+	}
+	return c.streamStart(ctx, "/api/runtime/restart", reqBody, progressCallback, phaseCallback, confirmCallback)
+}
+
+// streamStart posts a request to an endpoint that starts an instance and
+// reads its Server-Sent Events until the start completes or fails.
+//
+// Install confirmations are answered with confirmCallback and posted to
+// /api/runtime/confirm while the server waits; the stream resumes after.
+func (c *Client) streamStart(ctx context.Context, path string, body interface{}, progressCallback func(string), phaseCallback func(api.StartupEvent), confirmCallback ConfirmFunc) (map[string]interface{}, error) {
+	data, err := json.Marshal(body)
 	if err != nil {
 		return nil, fmt.Errorf("failed to marshal request: %w", err)
 	}
 
-	url := c.baseURL + "/api/runtime/start"
+	url := c.baseURL + path
 	req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewReader(data))
 	if err != nil {
 		return nil, fmt.Errorf("failed to create request: %w", err)
@@ -75,6 +135,9 @@ func (c *Client) RunModelWithSSEContext(ctx context.Context, opts interface{}, p
 
 	req.Header.Set("Content-Type", "application/json")
 	req.Header.Set("Accept", "text/event-stream")
+	if confirmCallback != nil {
+		req.Header.Set(api.ConfirmHeader, "true")
+	}
 
 	resp, err := c.httpClient.Do(req)
 	if err != nil {
@@ -82,7 +145,7 @@ func (c *Client) RunModelWithSSEContext(ctx context.Context, opts interface{}, p
 		if ctx.Err() != nil {
 			return nil, fmt.Errorf("operation cancelled")
 		}
-		return nil, fmt.Errorf("cannot connect to xw server at %s\n\nIs the server running? Start it with: xw serve", c.baseURL)
+		return nil, fmt.Errorf("cannot connect to xw server at %s\n\nIs the server running? Start it with: xw serve", c.address)
 	}
 	defer resp.Body.Close()
 
@@ -93,7 +156,8 @@ func (c *Client) RunModelWithSSEContext(ctx context.Context, opts interface{}, p
 
 	var instanceInfo map[string]interface{}
 
-	// Read SSE stream
+	// Read SSE stream; eventName is the name of the event being read
+	var eventName string
 	scanner := bufio.NewScanner(resp.Body)
 	for scanner.Scan() {
 		// Check for context cancellation
@@ -105,9 +169,47 @@ func (c *Client) RunModelWithSSEContext(ctx context.Context, opts interface{}, p
 		
 		line := scanner.Text()
 
+		if line == "" {
+			eventName = ""
+			continue
+		}
+		if strings.HasPrefix(line, "event: ") {
+			eventName = strings.TrimPrefix(line, "event: ")
+			continue
+		}
+
 		if strings.HasPrefix(line, "data: ") {
 			data := strings.TrimPrefix(line, "data: ")
 
+			// Startup phase event
+			if eventName == api.StartupEventSSEName {
+				var phase api.StartupEvent
+				if err := json.Unmarshal([]byte(data), &phase); err == nil && phaseCallback != nil {
+					phaseCallback(phase)
+				}
+				continue
+			}
+
+			// Install confirmation; the server waits for the answer
+			if eventName == api.InstallConfirmationSSEName && confirmCallback != nil {
+				var confirmation api.InstallConfirmation
+				if err := json.Unmarshal([]byte(data), &confirmation); err != nil {
+					return nil, fmt.Errorf("invalid confirmation event: %w", err)
+				}
+				confirmed, err := confirmCallback(ctx, confirmation)
+				if err != nil {
+					if ctx.Err() != nil {
+						return nil, fmt.Errorf("operation cancelled")
+					}
+					return nil, err
+				}
+				answer := api.InstallAnswer{ID: confirmation.ID, Confirmed: confirmed}
+				if err := c.doRequest("POST", "/api/runtime/confirm", answer, nil); err != nil {
+					return nil, fmt.Errorf("failed to send answer: %w", err)
+				}
+				continue
+			}
+
 			// Try to parse as JSON to extract instance info
 			var eventData map[string]interface{}
 			if err := json.Unmarshal([]byte(data), &eventData); err == nil {
@@ -251,18 +353,22 @@ func (c *Client) StopInstanceByAlias(alias string, force bool) error {
 // RemoveInstanceByAlias removes a model instance by its alias.
 //
 // This method sends a request to the server to remove the specified instance using alias.
-// The instance must be stopped first unless force is true.
+// The instance must be stopped first unless force is true. With a positive
+// drainTimeout the server stops routing new requests to the instance and
+// waits up to drainTimeout for in-flight requests before stopping it.
 //
 // Parameters:
 //   - alias: Alias of the instance to remove
 //   - force: If true, removes the instance even if it's running
+//   - drainTimeout: Maximum time to wait for in-flight requests (0 to skip draining)
 //
 // Returns:
 //   - Error if the request fails or the server returns an error
-func (c *Client) RemoveInstanceByAlias(alias string, force bool) error {
+func (c *Client) RemoveInstanceByAlias(alias string, force bool, drainTimeout time.Duration) error {
 	reqBody := map[string]interface{}{
-		"alias": alias,
-		"force": force,
+		"alias":         alias,
+		"force":         force,
+		"drain_timeout": drainTimeout.Seconds(),
 	}
 
 	var result map[string]interface{}
@@ -273,6 +379,51 @@ func (c *Client) RemoveInstanceByAlias(alias string, force bool) error {
 	return nil
 }
 
+// RenameInstance changes the alias of a model instance without restarting it.
+//
+// Parameters:
+//   - alias: Current alias of the instance
+//   - newAlias: Alias to assign
+//
+// Returns:
+//   - Error if the request fails or the server rejects the new alias
+func (c *Client) RenameInstance(alias, newAlias string) error {
+	reqBody := map[string]interface{}{
+		"alias":     alias,
+		"new_alias": newAlias,
+	}
+
+	var result map[string]interface{}
+	if err := c.doRequest("POST", "/api/runtime/rename", reqBody, &result); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// SetInstanceConcurrency changes the concurrency limit of a running model
+// instance without restarting it.
+//
+// Parameters:
+//   - alias: Alias of the instance
+//   - maxConcurrent: New concurrency limit (0 for unlimited)
+//
+// Returns:
+//   - Error if the request fails or the server returns an error
+func (c *Client) SetInstanceConcurrency(alias string, maxConcurrent int) error {
+	reqBody := map[string]interface{}{
+		"alias":          alias,
+		"max_concurrent": maxConcurrent,
+	}
+
+	var result map[string]interface{}
+	if err := c.doRequest("POST", "/api/runtime/concurrency", reqBody, &result); err != nil {
+		return err
+	}
+
+	return nil
+}
+
 // CheckInstanceReady checks if a model instance is ready to serve requests.
 //
 // This method verifies that the instance's endpoint is accessible and responding.
@@ -293,7 +444,7 @@ func (c *Client) CheckInstanceReady(alias string) (bool, error) {
 
 	resp, err := c.httpClient.Do(req)
 	if err != nil {
-		return false, fmt.Errorf("cannot connect to xw server at %s", c.baseURL)
+		return false, fmt.Errorf("cannot connect to xw server at %s", c.address)
 	}
 	defer resp.Body.Close()
 
@@ -322,12 +473,16 @@ func (c *Client) CheckInstanceReady(alias string) (bool, error) {
 // Parameters:
 //   - alias: Alias of the instance to stream logs from
 //   - follow: If true, stream logs in real-time; if false, return existing logs and exit
+//   - tail: Number of most recent lines to show first (0 for all)
 //   - logCallback: Function called for each log line
 //
 // Returns:
 //   - Error if the request fails or the stream is interrupted
-func (c *Client) StreamInstanceLogs(alias string, follow bool, logCallback func(string)) error {
+func (c *Client) StreamInstanceLogs(alias string, follow bool, tail int, logCallback func(string)) error {
 	url := fmt.Sprintf("%s/api/runtime/logs?alias=%s&follow=%t", c.baseURL, alias, follow)
+	if tail > 0 {
+		url += fmt.Sprintf("&tail=%d", tail)
+	}
 
 	req, err := http.NewRequest("GET", url, nil)
 	if err != nil {
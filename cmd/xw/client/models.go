@@ -59,11 +59,24 @@ func (c *Client) ListModels(deviceType api.DeviceType, showAll bool) ([]api.Mode
 //   - A pointer to ListModelsResponse containing models and statistics
 //   - An error if the request fails
 func (c *Client) ListModelsWithStats(deviceType api.DeviceType, showAll bool) (*api.ListModelsResponse, error) {
-	req := api.ListModelsRequest{
+	return c.QueryModels(api.ListModelsRequest{
 		DeviceType: deviceType,
 		ShowAll:    showAll,
-	}
+	})
+}
 
+// QueryModels queries models with the full set of list filters.
+//
+// Use this instead of ListModelsWithStats when filtering by fields other
+// than device type, such as capabilities.
+//
+// Parameters:
+//   - req: List request with the filters to apply
+//
+// Returns:
+//   - A pointer to ListModelsResponse containing models and statistics
+//   - An error if the request fails
+func (c *Client) QueryModels(req api.ListModelsRequest) (*api.ListModelsResponse, error) {
 	var resp api.ListModelsResponse
 	if err := c.doRequest("POST", "/api/models/list", req, &resp); err != nil {
 		return nil, err
@@ -119,7 +132,7 @@ func (c *Client) GetModel(modelID string) (map[string]interface{}, error) {
 
 	resp, err := c.httpClient.Do(req)
 	if err != nil {
-		return nil, fmt.Errorf("cannot connect to xw server at %s", c.baseURL)
+		return nil, fmt.Errorf("cannot connect to xw server at %s", c.address)
 	}
 	defer resp.Body.Close()
 
@@ -139,6 +152,99 @@ func (c *Client) GetModel(modelID string) (map[string]interface{}, error) {
 	return result, nil
 }
 
+// UpdateModelfile replaces the Modelfile of a downloaded model.
+//
+// Parameters:
+//   - modelID: The unique model identifier
+//   - content: Complete new Modelfile content
+//
+// Returns:
+//   - Error if the model has no Modelfile or the request fails
+func (c *Client) UpdateModelfile(modelID, content string) error {
+	reqBody := map[string]interface{}{
+		"model":   modelID,
+		"content": content,
+	}
+
+	var result map[string]interface{}
+	if err := c.doRequest("POST", "/api/models/modelfile", reqBody, &result); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// RevertModelfile restores the most recent Modelfile backup of a model.
+//
+// Parameters:
+//   - modelID: The unique model identifier
+//
+// Returns:
+//   - Name of the restored backup
+//   - Number of backups remaining after the restore
+//   - Error if the model has no backup or the request fails
+func (c *Client) RevertModelfile(modelID string) (string, int, error) {
+	reqBody := map[string]interface{}{
+		"model": modelID,
+	}
+
+	var result struct {
+		Backup    string `json:"backup"`
+		Remaining int    `json:"remaining"`
+	}
+	if err := c.doRequest("POST", "/api/models/modelfile/revert", reqBody, &result); err != nil {
+		return "", 0, err
+	}
+
+	return result.Backup, result.Remaining, nil
+}
+
+// CopyModel creates a model that serves the weights of a downloaded model,
+// or a local checkpoint, with its own copy of the Modelfile.
+//
+// Parameters:
+//   - source: The model to copy
+//   - destination: The new model name
+//   - from: Absolute path of a local checkpoint to serve instead of the
+//     source's weights (empty for the source's weights)
+//
+// Returns:
+//   - ID of the base model whose weights the copy serves
+//   - Error if the source is not downloaded, the name is taken, or the request fails
+func (c *Client) CopyModel(source, destination, from string) (string, error) {
+	reqBody := map[string]interface{}{
+		"source":      source,
+		"destination": destination,
+	}
+	if from != "" {
+		reqBody["from"] = from
+	}
+
+	var result struct {
+		Base string `json:"base"`
+	}
+	if err := c.doRequest("POST", "/api/models/copy", reqBody, &result); err != nil {
+		return "", err
+	}
+
+	return result.Base, nil
+}
+
+// RemoveModel removes a model created with CopyModel. The weights it
+// serves are kept.
+//
+// Parameters:
+//   - model: Model ID given to CopyModel as the destination
+//
+// Returns:
+//   - Error if the model is not a copy, is in use, or cannot be removed
+func (c *Client) RemoveModel(model string) error {
+	reqBody := map[string]interface{}{
+		"model": model,
+	}
+	return c.doRequest("POST", "/api/models/remove", reqBody, nil)
+}
+
 // Pull downloads and installs a model with streaming progress updates.
 //
 // This method downloads a model from ModelScope with real-time progress
@@ -147,8 +253,10 @@ func (c *Client) GetModel(modelID string) (map[string]interface{}, error) {
 //
 // Parameters:
 //   - model: The ModelScope model ID (e.g., "Qwen/Qwen2-7B")
-//   - version: The specific version (empty string for latest)
-//   - progressCallback: Function called for each progress message
+//   - version: Source revision (branch, tag, or commit; empty for the default branch)
+//   - jobs: Number of files to download concurrently (0 for the server default)
+//   - progressCallback: Function called for each status, progress, and
+//     summary message
 //
 // Returns:
 //   - A pointer to PullResponse with final status
@@ -156,10 +264,10 @@ func (c *Client) GetModel(modelID string) (map[string]interface{}, error) {
 //
 // Example:
 //
-//	resp, err := client.Pull("Qwen/Qwen2-7B", "", func(msg string) {
-//	    fmt.Println(msg)
+//	resp, err := client.Pull("Qwen/Qwen2-7B", "", 0, func(msg client.SSEMessage) {
+//	    fmt.Println(msg.Message)
 //	})
-func (c *Client) Pull(model, version string, progressCallback func(string)) (*api.PullResponse, error) {
-	return c.pullWithSSE(model, version, progressCallback)
+func (c *Client) Pull(model, version string, jobs int, progressCallback func(SSEMessage)) (*api.PullResponse, error) {
+	return c.pullWithSSE(model, version, jobs, progressCallback)
 }
 
@@ -59,7 +59,7 @@ func (c *Client) doRequest(method, path string, reqBody, respBody interface{}) e
 
 	resp, err := c.httpClient.Do(req)
 	if err != nil {
-		return fmt.Errorf("cannot connect to xw server at %s\n\nIs the server running? Start it with: xw serve", c.baseURL)
+		return fmt.Errorf("cannot connect to xw server at %s\n\nIs the server running? Start it with: xw serve", c.address)
 	}
 	defer resp.Body.Close()
 
@@ -25,6 +25,7 @@ import (
 // Message types:
 //   - "status": General status update
 //   - "progress": Download progress update
+//   - "summary": Overall download progress with transfer rate and ETA
 //   - "heartbeat": Keep-alive signal
 //   - "error": Error occurred during operation
 //   - "complete": Operation completed successfully
@@ -41,6 +42,21 @@ type SSEMessage struct {
 
 	// Path contains the file or resource path (if applicable)
 	Path string `json:"path,omitempty"`
+
+	// Percent is the overall completion percentage ("summary" type)
+	Percent float64 `json:"percent,omitempty"`
+
+	// Completed is the number of bytes downloaded ("summary" type)
+	Completed int64 `json:"completed,omitempty"`
+
+	// Total is the total download size in bytes ("summary" type)
+	Total int64 `json:"total,omitempty"`
+
+	// Rate is the recent transfer rate in bytes per second ("summary" type)
+	Rate float64 `json:"rate,omitempty"`
+
+	// ETASeconds is the estimated time remaining ("summary" type, 0 if unknown)
+	ETASeconds int64 `json:"eta_seconds,omitempty"`
 }
 
 // pullWithSSE performs a model pull operation with Server-Sent Events streaming.
@@ -58,7 +74,8 @@ type SSEMessage struct {
 //
 // Parameters:
 //   - model: Model identifier (e.g., "qwen2-7b")
-//   - version: Model version (empty string for latest)
+//   - version: Source revision (empty string for the default branch)
+//   - jobs: Number of files to download concurrently (0 for the server default)
 //   - progressCallback: Optional callback function for progress updates
 //
 // Returns:
@@ -67,14 +84,15 @@ type SSEMessage struct {
 //
 // Example:
 //
-//	resp, err := client.pullWithSSE("qwen2-7b", "", func(msg string) {
-//	    fmt.Println("Progress:", msg)
+//	resp, err := client.pullWithSSE("qwen2-7b", "", 0, func(msg SSEMessage) {
+//	    fmt.Println("Progress:", msg.Message)
 //	})
-func (c *Client) pullWithSSE(model, version string, progressCallback func(string)) (*api.PullResponse, error) {
+func (c *Client) pullWithSSE(model, version string, jobs int, progressCallback func(SSEMessage)) (*api.PullResponse, error) {
 	// Construct pull request
 	req := api.PullRequest{
 		Model:   model,
 		Version: version,
+		Jobs:    jobs,
 	}
 
 	// Serialize request body
@@ -102,7 +120,7 @@ func (c *Client) pullWithSSE(model, version string, progressCallback func(string
 	resp, err := c.httpClient.Do(httpReq)
 	if err != nil {
 		logger.Error("Failed to connect to server at %s: %v", c.baseURL, err)
-		return nil, fmt.Errorf("cannot connect to xw server at %s\n\nIs the server running? Start it with: xw serve", c.baseURL)
+		return nil, fmt.Errorf("cannot connect to xw server at %s\n\nIs the server running? Start it with: xw serve", c.address)
 	}
 	defer resp.Body.Close()
 
@@ -131,7 +149,7 @@ func (c *Client) pullWithSSE(model, version string, progressCallback func(string
 // Returns:
 //   - PullResponse with final result
 //   - Error if stream parsing fails or error message received
-func (c *Client) processSSEStream(body interface{ Read([]byte) (int, error) }, progressCallback func(string)) (*api.PullResponse, error) {
+func (c *Client) processSSEStream(body interface{ Read([]byte) (int, error) }, progressCallback func(SSEMessage)) (*api.PullResponse, error) {
 	scanner := bufio.NewScanner(body)
 	var finalResponse *api.PullResponse
 
@@ -154,16 +172,16 @@ func (c *Client) processSSEStream(body interface{ Read([]byte) (int, error) }, p
 
 		// Handle message based on type
 		switch msg.Type {
-		case "status", "progress":
+		case "status", "progress", "summary":
 			// Status and progress updates - forward to callback
 			if progressCallback != nil {
-				progressCallback(msg.Message)
+				progressCallback(msg)
 			}
 
 		case "heartbeat":
 			// Heartbeat signal to keep connection alive
 			// No action needed, just continue reading
-			logger.Debug("Received heartbeat signal")
+			logger.Debug("Received heartbeat: %s", msg.Message)
 
 		case "error":
 			// Error occurred during download
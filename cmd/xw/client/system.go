@@ -34,6 +34,38 @@ func (c *Client) Version() (*api.VersionResponse, error) {
 	return &resp, nil
 }
 
+// VersionWithRuntimes retrieves the server version information together
+// with the versions of its runtimes (Docker daemon, native engines).
+//
+// Querying the runtimes can take a few seconds, so Version should be used
+// when only the server version is needed.
+//
+// Returns:
+//   - A pointer to VersionResponse with Runtimes populated
+//   - An error if the request fails
+func (c *Client) VersionWithRuntimes() (*api.VersionResponse, error) {
+	var resp api.VersionResponse
+	if err := c.doRequest("GET", "/api/version?verbose=true", nil, &resp); err != nil {
+		return nil, err
+	}
+
+	return &resp, nil
+}
+
+// Doctor runs the server's diagnostic checks.
+//
+// Returns:
+//   - A pointer to DoctorResponse with the result of each check
+//   - An error if the request fails
+func (c *Client) Doctor() (*api.DoctorResponse, error) {
+	var resp api.DoctorResponse
+	if err := c.doRequest("GET", "/api/doctor", nil, &resp); err != nil {
+		return nil, err
+	}
+
+	return &resp, nil
+}
+
 // Health checks the server's health and readiness status.
 //
 // This method performs a health check to verify that the server is running
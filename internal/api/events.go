@@ -0,0 +1,136 @@
+package api
+
+import (
+	"encoding/json"
+	"strings"
+)
+
+// StartupPhase identifies a step of starting a model instance.
+//
+// Phases are reported on the /api/runtime/start event stream in the order
+// below; pulling_image is skipped if the image is already present.
+type StartupPhase string
+
+const (
+	// StartupPhaseCheckingDocker checks (and if needed installs) Docker
+	StartupPhaseCheckingDocker StartupPhase = "checking_docker"
+
+	// StartupPhasePullingImage pulls the runtime image; events carry the
+	// overall download percentage
+	StartupPhasePullingImage StartupPhase = "pulling_image"
+
+	// StartupPhaseCreatingContainer creates the instance container
+	StartupPhaseCreatingContainer StartupPhase = "creating_container"
+
+	// StartupPhaseWarmingUp means the container is running and the engine
+	// is loading the model. The start waits until the engine is ready and
+	// fails if it exits first.
+	StartupPhaseWarmingUp StartupPhase = "warming_up"
+
+	// StartupPhaseReady means the instance answers its health endpoint.
+	// It is the last event of a successful start.
+	StartupPhaseReady StartupPhase = "ready"
+)
+
+// StartupEvent is a typed progress event of an instance start.
+//
+// The server sends it as an SSE event named "phase" whose data is the JSON
+// encoding, e.g. {"phase":"pulling_image","percent":42}. Free-form progress
+// messages are still sent as unnamed events, so clients that do not know a
+// phase can show Message, or the plain messages, instead.
+type StartupEvent struct {
+	// Phase is the current startup phase.
+	Phase StartupPhase `json:"phase"`
+
+	// Percent is the completion percentage of the phase (0-100), if known.
+	Percent int `json:"percent,omitempty"`
+
+	// Message is a human-readable description of the phase.
+	Message string `json:"message,omitempty"`
+}
+
+// StartupEventSSEName is the SSE event name of startup phase events.
+const StartupEventSSEName = "phase"
+
+// startupEventTag prefixes startup events on the server's internal event
+// channel, which carries strings (like the "DOCKER_CR|" pull output lines).
+const startupEventTag = "PHASE|"
+
+// Tagged encodes the event for the server's internal event channel.
+func (e StartupEvent) Tagged() string {
+	data, _ := json.Marshal(e)
+	return startupEventTag + string(data)
+}
+
+// ParseTaggedStartupEvent decodes an event encoded by Tagged.
+//
+// Parameters:
+//   - s: Message from the event channel
+//
+// Returns:
+//   - The JSON encoding of the startup event
+//   - false if s is not a tagged startup event
+func ParseTaggedStartupEvent(s string) (string, bool) {
+	if !strings.HasPrefix(s, startupEventTag) {
+		return "", false
+	}
+	return strings.TrimPrefix(s, startupEventTag), true
+}
+
+// InstallConfirmation asks the client of a start stream whether a missing
+// dependency (e.g., Docker) may be installed on the server.
+//
+// The server sends it as an SSE event named "confirm" to clients that set
+// the ConfirmHeader request header, and waits for an InstallAnswer posted
+// to /api/runtime/confirm. For other clients the installation is declined.
+type InstallConfirmation struct {
+	// ID identifies the question; the answer must carry it.
+	ID string `json:"id"`
+
+	// Name is the name of the missing dependency.
+	Name string `json:"name"`
+
+	// Message describes what will be installed.
+	Message string `json:"message"`
+}
+
+// InstallAnswer is the client's answer to an InstallConfirmation.
+type InstallAnswer struct {
+	// ID is the ID of the question answered.
+	ID string `json:"id"`
+
+	// Confirmed is true if the dependency may be installed.
+	Confirmed bool `json:"confirmed"`
+}
+
+// InstallConfirmationSSEName is the SSE event name of install confirmations.
+const InstallConfirmationSSEName = "confirm"
+
+// ConfirmHeader is set to "true" by start stream clients that answer
+// install confirmations.
+const ConfirmHeader = "X-XW-Confirm"
+
+// installConfirmationTag prefixes install confirmations on the server's
+// internal event channel.
+const installConfirmationTag = "CONFIRM|"
+
+// Tagged encodes the confirmation for the server's internal event channel.
+func (c InstallConfirmation) Tagged() string {
+	data, _ := json.Marshal(c)
+	return installConfirmationTag + string(data)
+}
+
+// ParseTaggedInstallConfirmation decodes a confirmation encoded by Tagged.
+//
+// Parameters:
+//   - s: Message from the event channel
+//
+// Returns:
+//   - The JSON encoding of the install confirmation
+//   - false if s is not a tagged install confirmation
+func ParseTaggedInstallConfirmation(s string) (string, bool) {
+	if !strings.HasPrefix(s, installConfirmationTag) {
+		return "", false
+	}
+	return strings.TrimPrefix(s, installConfirmationTag), true
+}
@@ -110,6 +110,15 @@ type Model struct {
 	// ModifiedAt is the last modification time in RFC3339 format
 	// Empty for models not downloaded yet
 	ModifiedAt string `json:"modified_at,omitempty"`
+	
+	// DiskSize is the actual size of the downloaded model files in bytes.
+	// Unlike Size, which is estimated from the parameter count, it is
+	// measured on disk. Zero for models not downloaded yet
+	DiskSize int64 `json:"disk_size,omitempty"`
+	
+	// Capabilities lists the model's supported features
+	// Examples: "completion", "vision", "tool_use"
+	Capabilities []string `json:"capabilities,omitempty"`
 }
 
 // ListModelsRequest represents a request to list available models.
@@ -125,6 +134,25 @@ type ListModelsRequest struct {
 	// ShowAll indicates whether to show all models regardless of device type.
 	// When true, the DeviceType filter is ignored.
 	ShowAll bool `json:"show_all,omitempty"`
+
+	// Capabilities restricts results to models that have all of the listed
+	// capabilities (e.g., ["vision"]). Empty means no capability filter.
+	Capabilities []string `json:"capabilities,omitempty"`
+
+	// NamePrefix restricts results to models whose name starts with the
+	// given prefix (case-insensitive). Empty means no name filter.
+	NamePrefix string `json:"name_prefix,omitempty"`
+
+	// Group restricts results to the members of a model group defined
+	// under model_groups in models.yaml. Empty means no group filter.
+	Group string `json:"group,omitempty"`
+
+	// Limit is the maximum number of models to return.
+	// Zero or negative returns all matching models.
+	Limit int `json:"limit,omitempty"`
+
+	// Offset is the number of matching models to skip, for paging.
+	Offset int `json:"offset,omitempty"`
 }
 
 // ListModelsResponse represents the response containing a list of models.
@@ -134,8 +162,13 @@ type ListModelsRequest struct {
 type ListModelsResponse struct {
 	// Models is the array of models matching the request filters.
 	// May be empty if no models match the criteria.
+	// Sorted by name; holds a single page when Limit or Offset is set.
 	Models []Model `json:"models"`
 	
+	// Total is the number of models matching the request filters,
+	// before Limit and Offset are applied
+	Total int `json:"total"`
+	
 	// TotalModels is the total number of models in the registry
 	TotalModels int `json:"total_models"`
 	
@@ -216,10 +249,15 @@ type PullRequest struct {
 	// Must match a registered model name in the registry.
 	Model string `json:"model"`
 
-	// Version is the specific version to download.
-	// If empty, the latest version is pulled.
-	// Format: "major.minor.patch" (e.g., "1.0.0")
+	// Version is the source revision to download: a branch, tag, or commit
+	// on the model hub (e.g., "v1.0" or "a1b2c3d").
+	// If empty, the hub's default branch is pulled ("master" on ModelScope,
+	// "main" on HuggingFace).
 	Version string `json:"version,omitempty"`
+
+	// Jobs is the number of model files downloaded concurrently.
+	// If zero, the server default is used.
+	Jobs int `json:"jobs,omitempty"`
 }
 
 // PullResponse represents the response from a model pull operation.
@@ -254,6 +292,11 @@ type VersionResponse struct {
 	// BuildTime is the timestamp when the server binary was built.
 	// Format: RFC3339 (e.g., "2026-01-26T10:00:00Z")
 	BuildTime string `json:"build_time"`
+
+	// Runtimes maps runtime names (e.g., "vllm:docker") to the version of
+	// the engine or container platform behind them. Only included when
+	// requested with ?verbose=true.
+	Runtimes map[string]string `json:"runtimes,omitempty"`
 }
 
 // HealthResponse represents the server health status.
@@ -297,6 +340,43 @@ type DeviceListResponse struct {
 	Devices interface{} `json:"devices"`
 }
 
+// DeviceAllocation describes a device and the instance it is allocated to.
+type DeviceAllocation struct {
+	// Index is the device index used by --device and xw.device_indices.
+	Index int `json:"index"`
+
+	// Type is the device type (e.g., "ascend-910b").
+	Type string `json:"type"`
+
+	// ModelName is the chip model name.
+	ModelName string `json:"model_name"`
+
+	// BusAddress is the PCI bus address.
+	BusAddress string `json:"bus_address"`
+
+	// InstanceID is the instance using the device, empty if the device is free.
+	InstanceID string `json:"instance_id,omitempty"`
+
+	// Pending is true if the device is reserved for an instance whose
+	// container is not running yet.
+	Pending bool `json:"pending,omitempty"`
+}
+
+// DeviceAllocationsResponse represents the device allocator state.
+//
+// Allocations and Free summarize Devices: together they list every device
+// index exactly once.
+type DeviceAllocationsResponse struct {
+	// Allocations maps each allocated device index to its instance ID.
+	Allocations map[int]string `json:"allocations"`
+
+	// Free lists the indices of unallocated devices.
+	Free []int `json:"free"`
+
+	// Devices describes every detected device in index order.
+	Devices []DeviceAllocation `json:"devices"`
+}
+
 // SupportedDevicesRequest represents a request to query supported device types.
 //
 // This optional request allows filtering or querying specific device
@@ -316,3 +396,41 @@ type SupportedDevicesResponse struct {
 	// Examples: ["ascend-910b", "ascend-310p"]
 	DeviceTypes []string `json:"device_types"`
 }
+
+// DoctorStatus is the outcome of a diagnostic check.
+type DoctorStatus string
+
+const (
+	// DoctorPass means the check found no problem.
+	DoctorPass DoctorStatus = "pass"
+
+	// DoctorWarn means the check found something that may cause problems.
+	DoctorWarn DoctorStatus = "warn"
+
+	// DoctorFail means the check found a problem that breaks model serving.
+	DoctorFail DoctorStatus = "fail"
+)
+
+// DoctorCheck is the result of one diagnostic check run by the server.
+type DoctorCheck struct {
+	// Name identifies the check (e.g., "docker", "devices").
+	Name string `json:"name"`
+
+	// Status is the outcome of the check.
+	Status DoctorStatus `json:"status"`
+
+	// Message describes what was found.
+	Message string `json:"message"`
+
+	// Hint suggests how to fix a warning or failure. Empty when passing.
+	Hint string `json:"hint,omitempty"`
+}
+
+// DoctorResponse represents the diagnostics report of the server.
+//
+// The checks cover the server's environment (Docker, devices, disk space,
+// configuration) and its instances, in a fixed order.
+type DoctorResponse struct {
+	// Checks lists the diagnostic results.
+	Checks []DoctorCheck `json:"checks"`
+}
@@ -6,6 +6,24 @@ import (
 	"strings"
 )
 
+// ConvertOptions adjusts request conversion to the capabilities of the
+// backend model.
+type ConvertOptions struct {
+	// ToolResultImages passes images returned in tool_result blocks (e.g.,
+	// screenshots) to the backend as image_url parts. When false, each image
+	// is replaced by a text placeholder, for backends that reject images.
+	ToolResultImages bool
+
+	// ToolMessages sends tool results as OpenAI tool messages linked to the
+	// assistant's tool call by tool_call_id. When false, tool results are
+	// flattened into a plain-text user message, which every backend accepts.
+	ToolMessages bool
+}
+
+// toolResultImagePlaceholder replaces a tool_result image when the backend
+// does not accept images.
+const toolResultImagePlaceholder = "[image omitted: the model does not accept images]"
+
 // ConvertRequest translates an Anthropic MessagesRequest into an OpenAI
 // ChatCompletionRequest body (JSON-encoded).
 //
@@ -18,14 +36,15 @@ import (
 //
 // The returned []byte is ready to be forwarded to an OpenAI-compatible backend.
 // The modelOverride parameter allows replacing the model name with the backend
-// instance's actual model identifier.
-func ConvertRequest(req *MessagesRequest, modelOverride string) ([]byte, error) {
+// instance's actual model identifier; opts adapts the conversion to the
+// backend model.
+func ConvertRequest(req *MessagesRequest, modelOverride string, opts ConvertOptions) ([]byte, error) {
 	model := req.Model
 	if modelOverride != "" {
 		model = modelOverride
 	}
 
-	messages, err := convertMessages(req.System, req.Messages)
+	messages, err := convertMessages(req.System, req.Messages, opts)
 	if err != nil {
 		return nil, fmt.Errorf("converting messages: %w", err)
 	}
@@ -61,7 +80,7 @@ func ConvertRequest(req *MessagesRequest, modelOverride string) ([]byte, error)
 
 // convertMessages builds the OpenAI messages array from an Anthropic system
 // prompt and conversation history.
-func convertMessages(system json.RawMessage, msgs []Message) ([]OpenAIMessage, error) {
+func convertMessages(system json.RawMessage, msgs []Message, opts ConvertOptions) ([]OpenAIMessage, error) {
 	var out []OpenAIMessage
 
 	// System prompt
@@ -76,7 +95,7 @@ func convertMessages(system json.RawMessage, msgs []Message) ([]OpenAIMessage, e
 	}
 
 	for _, msg := range msgs {
-		converted, err := convertOneMessage(msg)
+		converted, err := convertOneMessage(msg, opts)
 		if err != nil {
 			return nil, fmt.Errorf("message role=%q: %w", msg.Role, err)
 		}
@@ -116,7 +135,7 @@ func parseSystemPrompt(raw json.RawMessage) (string, error) {
 // convertOneMessage converts a single Anthropic message to one or more OpenAI
 // messages. A single Anthropic message may produce multiple OpenAI messages
 // when tool results are involved.
-func convertOneMessage(msg Message) ([]OpenAIMessage, error) {
+func convertOneMessage(msg Message, opts ConvertOptions) ([]OpenAIMessage, error) {
 	// Try to unmarshal content as a plain string.
 	var textContent string
 	if err := json.Unmarshal(msg.Content, &textContent); err == nil {
@@ -130,17 +149,18 @@ func convertOneMessage(msg Message) ([]OpenAIMessage, error) {
 	}
 
 	if msg.Role == "user" {
-		return convertUserBlocks(blocks)
+		return convertUserBlocks(blocks, opts)
 	}
 	return convertAssistantBlocks(blocks)
 }
 
 // convertUserBlocks handles user messages containing text, images, and tool results.
 //
-// Anthropic places tool_result blocks inside user messages. For OpenAI-compatible
-// backends, we flatten tool results into plain text within a single user message,
-// as most backends do not support the OpenAI tool-result message type.
-func convertUserBlocks(blocks []ContentBlock) ([]OpenAIMessage, error) {
+// Anthropic places tool_result blocks inside user messages. By default, we
+// flatten tool results into plain text within a single user message, as not
+// every backend supports the OpenAI tool-result message type; with
+// opts.ToolMessages they become tool messages instead.
+func convertUserBlocks(blocks []ContentBlock, opts ConvertOptions) ([]OpenAIMessage, error) {
 	hasToolResult := false
 	for _, b := range blocks {
 		if b.Type == "tool_result" {
@@ -149,8 +169,11 @@ func convertUserBlocks(blocks []ContentBlock) ([]OpenAIMessage, error) {
 		}
 	}
 
+	if hasToolResult && opts.ToolMessages {
+		return convertToolMessages(blocks, opts)
+	}
 	if hasToolResult {
-		return convertUserToolResults(blocks)
+		return convertUserToolResults(blocks, opts)
 	}
 
 	// Standard user message with text/image blocks.
@@ -179,8 +202,21 @@ func convertUserBlocks(blocks []ContentBlock) ([]OpenAIMessage, error) {
 // convertUserToolResults extracts tool results from user message blocks and
 // flattens them into a single plain-text user message. This approach maximises
 // compatibility across different inference backends.
-func convertUserToolResults(blocks []ContentBlock) ([]OpenAIMessage, error) {
+//
+// Images returned by tools become image_url parts of a multimodal user
+// message, placed after the text of their tool result, if
+// opts.ToolResultImages is set; otherwise they are replaced by a placeholder.
+func convertUserToolResults(blocks []ContentBlock, opts ConvertOptions) ([]OpenAIMessage, error) {
 	var sb strings.Builder
+	var parts []OpenAIContentPart
+
+	// flushText moves the text collected so far into a text part.
+	flushText := func() {
+		if text := strings.TrimSpace(sb.String()); text != "" {
+			parts = append(parts, OpenAIContentPart{Type: "text", Text: text})
+		}
+		sb.Reset()
+	}
 
 	for _, b := range blocks {
 		switch b.Type {
@@ -188,14 +224,32 @@ func convertUserToolResults(blocks []ContentBlock) ([]OpenAIMessage, error) {
 			sb.WriteString(b.Text)
 			sb.WriteByte('\n')
 		case "tool_result":
+			text, images := extractToolResultContent(b.Content)
 			sb.WriteString("Tool result for ")
 			sb.WriteString(b.ToolUseID)
 			sb.WriteString(":\n")
-			sb.WriteString(extractToolResultContent(b.Content))
+			sb.WriteString(text)
 			sb.WriteByte('\n')
+			for _, url := range images {
+				if !opts.ToolResultImages {
+					sb.WriteString(toolResultImagePlaceholder)
+					sb.WriteByte('\n')
+					continue
+				}
+				flushText()
+				parts = append(parts, OpenAIContentPart{
+					Type:     "image_url",
+					ImageURL: &OpenAIImageURL{URL: url},
+				})
+			}
 		}
 	}
 
+	if len(parts) > 0 {
+		flushText()
+		return []OpenAIMessage{{Role: "user", Content: parts}}, nil
+	}
+
 	text := strings.TrimSpace(sb.String())
 	if text == "" {
 		text = "..."
@@ -203,6 +257,52 @@ func convertUserToolResults(blocks []ContentBlock) ([]OpenAIMessage, error) {
 	return []OpenAIMessage{{Role: "user", Content: text}}, nil
 }
 
+// convertToolMessages converts tool results to OpenAI tool messages, one per
+// tool_result block, with tool_call_id set from tool_use_id so the backend
+// can pair each result with the call in the preceding assistant message.
+//
+// Tool messages carry text only. Text blocks of the user message, and images
+// returned by tools if opts.ToolResultImages is set, follow in a user message
+// after the tool messages; otherwise images are replaced by a placeholder.
+func convertToolMessages(blocks []ContentBlock, opts ConvertOptions) ([]OpenAIMessage, error) {
+	var out []OpenAIMessage
+	var parts []OpenAIContentPart
+
+	for _, b := range blocks {
+		switch b.Type {
+		case "text":
+			if b.Text != "" {
+				parts = append(parts, OpenAIContentPart{Type: "text", Text: b.Text})
+			}
+		case "tool_result":
+			text, images := extractToolResultContent(b.Content)
+			for _, url := range images {
+				if !opts.ToolResultImages {
+					text = strings.TrimSpace(text + "\n" + toolResultImagePlaceholder)
+					continue
+				}
+				parts = append(parts, OpenAIContentPart{
+					Type:     "image_url",
+					ImageURL: &OpenAIImageURL{URL: url},
+				})
+			}
+			out = append(out, OpenAIMessage{
+				Role:       "tool",
+				ToolCallID: b.ToolUseID,
+				Content:    text,
+			})
+		}
+	}
+
+	switch {
+	case len(parts) == 1 && parts[0].Type == "text":
+		out = append(out, OpenAIMessage{Role: "user", Content: parts[0].Text})
+	case len(parts) > 0:
+		out = append(out, OpenAIMessage{Role: "user", Content: parts})
+	}
+	return out, nil
+}
+
 // convertAssistantBlocks handles assistant messages containing text and tool_use blocks.
 // Tool use blocks are converted to OpenAI tool_calls on the assistant message.
 func convertAssistantBlocks(blocks []ContentBlock) ([]OpenAIMessage, error) {
@@ -244,38 +344,45 @@ func convertAssistantBlocks(blocks []ContentBlock) ([]OpenAIMessage, error) {
 }
 
 // extractToolResultContent normalises the polymorphic content field of a
-// tool_result block into a plain string.
-func extractToolResultContent(raw json.RawMessage) string {
+// tool_result block into a plain string and the URLs (or data URIs) of the
+// images it contains.
+func extractToolResultContent(raw json.RawMessage) (string, []string) {
 	if len(raw) == 0 {
-		return ""
+		return "", nil
 	}
 
 	// Try plain string.
 	var s string
 	if err := json.Unmarshal(raw, &s); err == nil {
-		return s
+		return s, nil
 	}
 
 	// Try array of content blocks.
 	var blocks []ContentBlock
 	if err := json.Unmarshal(raw, &blocks); err == nil {
 		var parts []string
+		var images []string
 		for _, b := range blocks {
-			if b.Type == "text" {
+			switch b.Type {
+			case "text":
 				parts = append(parts, b.Text)
+			case "image":
+				if url := buildImageURL(b.Source); url != "" {
+					images = append(images, url)
+				}
 			}
 		}
-		return strings.Join(parts, "\n")
+		return strings.Join(parts, "\n"), images
 	}
 
 	// Try arbitrary object → serialise as JSON.
 	var obj map[string]any
 	if err := json.Unmarshal(raw, &obj); err == nil {
 		data, _ := json.Marshal(obj)
-		return string(data)
+		return string(data), nil
 	}
 
-	return string(raw)
+	return string(raw), nil
 }
 
 // buildImageURL constructs a data URI or URL from an Anthropic image source.
@@ -0,0 +1,137 @@
+package apiformat
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+// toolConversation is a tool round trip: the assistant calls two tools,
+// the user returns both results with a question, and the assistant answers.
+// The results come back in a different order than the calls.
+const toolConversation = `[
+	{"role": "user", "content": "What is the weather in Paris and Rome?"},
+	{"role": "assistant", "content": [
+		{"type": "text", "text": "Checking both cities."},
+		{"type": "tool_use", "id": "toolu_paris", "name": "get_weather", "input": {"city": "Paris"}},
+		{"type": "tool_use", "id": "toolu_rome", "name": "get_weather", "input": {"city": "Rome"}}
+	]},
+	{"role": "user", "content": [
+		{"type": "tool_result", "tool_use_id": "toolu_rome", "content": [{"type": "text", "text": "21C"}]},
+		{"type": "tool_result", "tool_use_id": "toolu_paris", "content": "18C"},
+		{"type": "text", "text": "Which is warmer?"}
+	]},
+	{"role": "assistant", "content": "Rome is warmer."}
+]`
+
+// screenshotConversation returns a tool result with text and an image.
+const screenshotConversation = `[
+	{"role": "assistant", "content": [
+		{"type": "tool_use", "id": "toolu_shot", "name": "screenshot", "input": {}}
+	]},
+	{"role": "user", "content": [
+		{"type": "tool_result", "tool_use_id": "toolu_shot", "content": [
+			{"type": "text", "text": "Screenshot taken"},
+			{"type": "image", "source": {"type": "base64", "media_type": "image/png", "data": "iVBOR"}}
+		]}
+	]}
+]`
+
+// assistantToolCalls is the converted assistant message of toolConversation.
+const assistantToolCalls = `{"role": "assistant", "content": "Checking both cities.", "tool_calls": [
+	{"id": "toolu_paris", "type": "function", "function": {"name": "get_weather", "arguments": "{\"city\":\"Paris\"}"}},
+	{"id": "toolu_rome", "type": "function", "function": {"name": "get_weather", "arguments": "{\"city\":\"Rome\"}"}}
+]}`
+
+func TestConvertMessagesToolConversations(t *testing.T) {
+	tests := []struct {
+		name     string
+		messages string
+		opts     ConvertOptions
+		want     string
+	}{
+		{
+			name:     "tool messages",
+			messages: toolConversation,
+			opts:     ConvertOptions{ToolMessages: true},
+			want: `[
+				{"role": "user", "content": "What is the weather in Paris and Rome?"},
+				` + assistantToolCalls + `,
+				{"role": "tool", "tool_call_id": "toolu_rome", "content": "21C"},
+				{"role": "tool", "tool_call_id": "toolu_paris", "content": "18C"},
+				{"role": "user", "content": "Which is warmer?"},
+				{"role": "assistant", "content": "Rome is warmer."}
+			]`,
+		},
+		{
+			name:     "flattened tool results",
+			messages: toolConversation,
+			opts:     ConvertOptions{},
+			want: `[
+				{"role": "user", "content": "What is the weather in Paris and Rome?"},
+				` + assistantToolCalls + `,
+				{"role": "user", "content": "Tool result for toolu_rome:\n21C\nTool result for toolu_paris:\n18C\nWhich is warmer?"},
+				{"role": "assistant", "content": "Rome is warmer."}
+			]`,
+		},
+		{
+			name:     "tool message image placeholder",
+			messages: screenshotConversation,
+			opts:     ConvertOptions{ToolMessages: true},
+			want: `[
+				{"role": "assistant", "content": null, "tool_calls": [
+					{"id": "toolu_shot", "type": "function", "function": {"name": "screenshot", "arguments": "{}"}}
+				]},
+				{"role": "tool", "tool_call_id": "toolu_shot", "content": "Screenshot taken\n[image omitted: the model does not accept images]"}
+			]`,
+		},
+		{
+			name:     "tool message image passed after tool messages",
+			messages: screenshotConversation,
+			opts:     ConvertOptions{ToolMessages: true, ToolResultImages: true},
+			want: `[
+				{"role": "assistant", "content": null, "tool_calls": [
+					{"id": "toolu_shot", "type": "function", "function": {"name": "screenshot", "arguments": "{}"}}
+				]},
+				{"role": "tool", "tool_call_id": "toolu_shot", "content": "Screenshot taken"},
+				{"role": "user", "content": [{"type": "image_url", "image_url": {"url": "data:image/png;base64,iVBOR"}}]}
+			]`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var msgs []Message
+			if err := json.Unmarshal([]byte(tt.messages), &msgs); err != nil {
+				t.Fatalf("invalid test messages: %v", err)
+			}
+
+			out, err := convertMessages(nil, msgs, tt.opts)
+			if err != nil {
+				t.Fatalf("convertMessages() failed: %v", err)
+			}
+
+			got, err := json.Marshal(out)
+			if err != nil {
+				t.Fatalf("failed to marshal messages: %v", err)
+			}
+			assertJSONEqual(t, string(got), tt.want)
+		})
+	}
+}
+
+// assertJSONEqual fails the test if two JSON documents differ.
+func assertJSONEqual(t *testing.T, got, want string) {
+	t.Helper()
+
+	var gotValue, wantValue interface{}
+	if err := json.Unmarshal([]byte(got), &gotValue); err != nil {
+		t.Fatalf("invalid JSON %s: %v", got, err)
+	}
+	if err := json.Unmarshal([]byte(want), &wantValue); err != nil {
+		t.Fatalf("invalid expected JSON %s: %v", want, err)
+	}
+	if !reflect.DeepEqual(gotValue, wantValue) {
+		t.Errorf("got  %s\nwant %s", got, want)
+	}
+}
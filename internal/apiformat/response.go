@@ -3,9 +3,17 @@ package apiformat
 import (
 	"crypto/rand"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"strings"
 )
 
+// ErrMultipleChoices is returned when the backend answers with more than one
+// choice. Anthropic messages carry a single response and converted requests
+// never ask for more (n is not set), so extra choices are reported as an
+// error rather than silently dropped.
+var ErrMultipleChoices = errors.New("backend returned more than one choice; Anthropic responses support exactly one")
+
 // ConvertResponse translates a non-streaming OpenAI ChatCompletion response
 // body into an Anthropic MessagesResponse.
 //
@@ -13,7 +21,11 @@ import (
 //   - body: raw JSON response from the OpenAI-compatible backend
 //   - requestModel: the model name to echo back in the Anthropic response
 //     (typically the original model from the client request)
-func ConvertResponse(body []byte, requestModel string) (*MessagesResponse, error) {
+//   - stopSequences: the request's stop_sequences, used to report which one
+//     ended the response (see matchStopSequence)
+//
+// A response with more than one choice yields ErrMultipleChoices.
+func ConvertResponse(body []byte, requestModel string, stopSequences []string) (*MessagesResponse, error) {
 	var resp OpenAIChatResponse
 	if err := json.Unmarshal(body, &resp); err != nil {
 		return nil, fmt.Errorf("parsing OpenAI response: %w", err)
@@ -22,10 +34,17 @@ func ConvertResponse(body []byte, requestModel string) (*MessagesResponse, error
 	if len(resp.Choices) == 0 {
 		return nil, fmt.Errorf("OpenAI response contains no choices")
 	}
+	if len(resp.Choices) > 1 {
+		return nil, fmt.Errorf("%w (got %d)", ErrMultipleChoices, len(resp.Choices))
+	}
 
 	choice := resp.Choices[0]
 	content := buildContentBlocks(choice.Message)
 	stopReason := mapFinishReason(choice.FinishReason)
+	stopSequence := matchStopSequence(choice.FinishReason, choice.StopReason, messageText(choice.Message), stopSequences)
+	if stopSequence != nil {
+		stopReason = "stop_sequence"
+	}
 
 	return &MessagesResponse{
 		ID:           coalesce(resp.ID, generateMessageID()),
@@ -34,7 +53,7 @@ func ConvertResponse(body []byte, requestModel string) (*MessagesResponse, error
 		Model:        requestModel,
 		Content:      content,
 		StopReason:   stopReason,
-		StopSequence: nil,
+		StopSequence: stopSequence,
 		Usage: Usage{
 			InputTokens:  resp.Usage.PromptTokens,
 			OutputTokens: resp.Usage.CompletionTokens,
@@ -127,6 +146,42 @@ func mapFinishReason(reason string) string {
 	}
 }
 
+// matchStopSequence returns the stop sequence that ended a response, or nil
+// if it ended for another reason.
+//
+// OpenAI reports a stop-sequence hit as finish_reason "stop", the same as a
+// natural end of turn, and strips the sequence from the output. vLLM adds
+// the matched string as stop_reason, which is used when present. Otherwise
+// a sequence is recognized only if the backend left it at the end of the
+// text.
+//
+// Parameters:
+//   - finishReason: OpenAI finish_reason
+//   - backendStop: the backend's stop_reason field (may be nil)
+//   - text: generated text, or at least its tail
+//   - stopSequences: the request's stop_sequences
+//
+// Returns:
+//   - The matched stop sequence, or nil
+func matchStopSequence(finishReason string, backendStop any, text string, stopSequences []string) *string {
+	if finishReason != "stop" || len(stopSequences) == 0 {
+		return nil
+	}
+	if matched, ok := backendStop.(string); ok {
+		for _, seq := range stopSequences {
+			if seq == matched {
+				return &seq
+			}
+		}
+	}
+	for _, seq := range stopSequences {
+		if seq != "" && strings.HasSuffix(text, seq) {
+			return &seq
+		}
+	}
+	return nil
+}
+
 // generateMessageID produces a unique ID in Anthropic's msg_ format.
 func generateMessageID() string {
 	return "msg_" + randomHex(12)
@@ -0,0 +1,73 @@
+package apiformat
+
+import (
+	"errors"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestConvertResponseRejectsMultipleChoices(t *testing.T) {
+	body := `{"id": "chatcmpl-1", "choices": [
+		{"index": 0, "message": {"role": "assistant", "content": "Hello"}, "finish_reason": "stop"},
+		{"index": 1, "message": {"role": "assistant", "content": "Hi"}, "finish_reason": "stop"}
+	], "usage": {"prompt_tokens": 5, "completion_tokens": 2, "total_tokens": 7}}`
+
+	resp, err := ConvertResponse([]byte(body), "test-model", nil)
+	if !errors.Is(err, ErrMultipleChoices) {
+		t.Fatalf("ConvertResponse() error = %v, want ErrMultipleChoices", err)
+	}
+	if resp != nil {
+		t.Errorf("ConvertResponse() returned a response with the error: %+v", resp)
+	}
+}
+
+func TestConvertResponseSingleChoice(t *testing.T) {
+	body := `{"id": "chatcmpl-1", "choices": [
+		{"index": 0, "message": {"role": "assistant", "content": "Let me check.", "tool_calls": [
+			{"id": "call_1", "type": "function", "function": {"name": "get_weather", "arguments": "{\"city\":\"Paris\"}"}}
+		]}, "finish_reason": "tool_calls"}
+	], "usage": {"prompt_tokens": 12, "completion_tokens": 8, "total_tokens": 20}}`
+
+	resp, err := ConvertResponse([]byte(body), "test-model", nil)
+	if err != nil {
+		t.Fatalf("ConvertResponse() failed: %v", err)
+	}
+
+	if resp.ID != "chatcmpl-1" || resp.Model != "test-model" || resp.Role != "assistant" {
+		t.Errorf("got id=%q model=%q role=%q", resp.ID, resp.Model, resp.Role)
+	}
+	if resp.StopReason != "tool_use" {
+		t.Errorf("StopReason = %q, want tool_use", resp.StopReason)
+	}
+	if resp.Usage.InputTokens != 12 || resp.Usage.OutputTokens != 8 {
+		t.Errorf("Usage = %+v, want 12 input and 8 output tokens", resp.Usage)
+	}
+	if len(resp.Content) != 2 {
+		t.Fatalf("got %d content blocks, want 2", len(resp.Content))
+	}
+	if text := resp.Content[0]; text.Type != "text" || text.Text != "Let me check." {
+		t.Errorf("first block = %+v, want text \"Let me check.\"", text)
+	}
+	tool := resp.Content[1]
+	if tool.Type != "tool_use" || tool.ID != "call_1" || tool.Name != "get_weather" || tool.Input["city"] != "Paris" {
+		t.Errorf("second block = %+v, want tool_use call_1 get_weather(city=Paris)", tool)
+	}
+}
+
+func TestStreamAdapterRejectsMultipleChoices(t *testing.T) {
+	stream := strings.Join([]string{
+		`data: {"id": "chatcmpl-1", "choices": [{"index": 0, "delta": {"content": "Hello"}, "finish_reason": null}]}`,
+		`data: {"id": "chatcmpl-1", "choices": [{"index": 1, "delta": {"content": "Hi"}, "finish_reason": null}]}`,
+		`data: [DONE]`,
+	}, "\n\n")
+
+	rec := httptest.NewRecorder()
+	err := NewStreamAdapter("test-model", nil).Transform(strings.NewReader(stream), rec, rec)
+	if !errors.Is(err, ErrMultipleChoices) {
+		t.Fatalf("Transform() error = %v, want ErrMultipleChoices", err)
+	}
+	if !strings.Contains(rec.Body.String(), "event: error") {
+		t.Errorf("stream does not end with an error event:\n%s", rec.Body.String())
+	}
+}
@@ -49,14 +49,22 @@ type StreamAdapter struct {
 	inputTokens    int
 	outputTokens   int
 	finished       bool
+
+	// Stop sequence detection (see matchStopSequence).
+	stopSequences []string
+	textTail      string // end of the emitted text, long enough to hold any stop sequence
+	backendStop   any    // stop_reason reported by the backend, if any
 }
 
 // NewStreamAdapter creates a StreamAdapter for converting a single streaming
-// response. The requestModel is echoed in the Anthropic response metadata.
-func NewStreamAdapter(requestModel string) *StreamAdapter {
+// response. The requestModel is echoed in the Anthropic response metadata;
+// stopSequences are the request's stop_sequences, so the final message_delta
+// can report which one ended the response.
+func NewStreamAdapter(requestModel string, stopSequences []string) *StreamAdapter {
 	return &StreamAdapter{
-		requestModel: requestModel,
-		messageID:    generateMessageID(),
+		requestModel:  requestModel,
+		messageID:     generateMessageID(),
+		stopSequences: stopSequences,
 	}
 }
 
@@ -95,6 +103,16 @@ func (sa *StreamAdapter) Transform(reader io.Reader, w http.ResponseWriter, flus
 			continue // skip malformed chunks
 		}
 
+		// A choice other than the first means the backend produced several
+		// completions; end the stream with an error instead of mixing them.
+		for _, choice := range chunk.Choices {
+			if choice.Index > 0 {
+				err := fmt.Errorf("%w (got choice index %d)", ErrMultipleChoices, choice.Index)
+				sa.emitError(w, flusher, err.Error())
+				return err
+			}
+		}
+
 		sa.processChunk(chunk, w, flusher)
 	}
 
@@ -122,6 +140,7 @@ func (sa *StreamAdapter) processChunk(chunk OpenAIChatChunk, w http.ResponseWrit
 	if delta.Content != nil && *delta.Content != "" {
 		if sa.textBlockOpen && !sa.textBlockDone {
 			sa.emitTextDelta(w, flusher, 0, *delta.Content)
+			sa.trackText(*delta.Content)
 		}
 	}
 
@@ -131,11 +150,30 @@ func (sa *StreamAdapter) processChunk(chunk OpenAIChatChunk, w http.ResponseWrit
 	}
 
 	// --- Finish reason ---
+	if choice.StopReason != nil {
+		sa.backendStop = choice.StopReason
+	}
 	if choice.FinishReason != nil {
 		sa.handleFinish(*choice.FinishReason, w, flusher)
 	}
 }
 
+// trackText keeps the end of the emitted text, as long as the longest stop
+// sequence, so a sequence left in the output can be recognized at the end.
+func (sa *StreamAdapter) trackText(text string) {
+	longest := 0
+	for _, seq := range sa.stopSequences {
+		longest = max(longest, len(seq))
+	}
+	if longest == 0 {
+		return
+	}
+	sa.textTail += text
+	if len(sa.textTail) > longest {
+		sa.textTail = sa.textTail[len(sa.textTail)-longest:]
+	}
+}
+
 // processToolCalls handles tool_call deltas. Each new tool call opens a new
 // Anthropic content block; argument fragments are emitted as input_json_delta.
 func (sa *StreamAdapter) processToolCalls(toolCalls []OpenAIToolCall, w http.ResponseWriter, flusher http.Flusher) {
@@ -197,7 +235,11 @@ func (sa *StreamAdapter) handleFinish(reason string, w http.ResponseWriter, flus
 	}
 
 	stopReason := mapFinishReason(reason)
-	sa.emitMessageDelta(w, flusher, stopReason)
+	stopSequence := matchStopSequence(reason, sa.backendStop, sa.textTail, sa.stopSequences)
+	if stopSequence != nil {
+		stopReason = "stop_sequence"
+	}
+	sa.emitMessageDelta(w, flusher, stopReason, stopSequence)
 	sa.emitMessageStop(w, flusher)
 }
 
@@ -292,14 +334,16 @@ func (sa *StreamAdapter) emitContentBlockStop(w http.ResponseWriter, flusher htt
 }
 
 // emitMessageDelta sends the final message-level metadata including the
-// stop_reason (end_turn / max_tokens / tool_use) and accumulated output token
-// usage. This event immediately precedes message_stop.
-func (sa *StreamAdapter) emitMessageDelta(w http.ResponseWriter, flusher http.Flusher, stopReason string) {
+// stop_reason (end_turn / max_tokens / tool_use / stop_sequence), the
+// matched stop sequence (nil unless stop_reason is stop_sequence) and
+// accumulated output token usage. This event immediately precedes
+// message_stop.
+func (sa *StreamAdapter) emitMessageDelta(w http.ResponseWriter, flusher http.Flusher, stopReason string, stopSequence *string) {
 	data := map[string]any{
 		"type": "message_delta",
 		"delta": map[string]any{
 			"stop_reason":   stopReason,
-			"stop_sequence": nil,
+			"stop_sequence": stopSequence,
 		},
 		"usage": map[string]any{
 			"output_tokens": sa.outputTokens,
@@ -318,6 +362,18 @@ func (sa *StreamAdapter) emitMessageStop(w http.ResponseWriter, flusher http.Flu
 	flusher.Flush()
 }
 
+// emitError sends an error event, which ends the stream without a
+// message_stop.
+func (sa *StreamAdapter) emitError(w http.ResponseWriter, flusher http.Flusher, message string) {
+	writeSSE(w, flusher, "error", AnthropicError{
+		Type: "error",
+		Error: AnthropicErrorBody{
+			Type:    "api_error",
+			Message: message,
+		},
+	})
+}
+
 // emitPing sends a keep-alive ping event. Anthropic's API sends these
 // periodically to prevent connection timeouts.
 func (sa *StreamAdapter) emitPing(w http.ResponseWriter, flusher http.Flusher) {
@@ -124,6 +124,22 @@ type AnthropicErrorBody struct {
 	Message string `json:"message"`
 }
 
+// ModelInfo describes a single model in the Anthropic models list response.
+type ModelInfo struct {
+	Type        string `json:"type"`
+	ID          string `json:"id"`
+	DisplayName string `json:"display_name"`
+	CreatedAt   string `json:"created_at"`
+}
+
+// ModelList is the Anthropic GET /v1/models response.
+type ModelList struct {
+	Data    []ModelInfo `json:"data"`
+	HasMore bool        `json:"has_more"`
+	FirstID string      `json:"first_id,omitempty"`
+	LastID  string      `json:"last_id,omitempty"`
+}
+
 // ---------------------------------------------------------------------------
 // OpenAI Chat Completions API types (minimal subset for conversion)
 // Only the fields required for request construction and response parsing.
@@ -211,6 +227,7 @@ type OpenAIChoice struct {
 	Index        int           `json:"index"`
 	Message      OpenAIMessage `json:"message"`
 	FinishReason string        `json:"finish_reason"`
+	StopReason   any           `json:"stop_reason,omitempty"` // vLLM: matched stop string or token ID
 }
 
 // OpenAIUsage reports token consumption in OpenAI format.
@@ -232,6 +249,7 @@ type OpenAIChunkChoice struct {
 	Index        int              `json:"index"`
 	Delta        OpenAIChunkDelta `json:"delta"`
 	FinishReason *string          `json:"finish_reason"`
+	StopReason   any              `json:"stop_reason,omitempty"` // vLLM: matched stop string or token ID
 }
 
 // OpenAIChunkDelta carries incremental content or tool call fragments.
@@ -240,3 +258,34 @@ type OpenAIChunkDelta struct {
 	Content   *string          `json:"content,omitempty"`
 	ToolCalls []OpenAIToolCall `json:"tool_calls,omitempty"`
 }
+
+// OpenAIModel is a single model object in the OpenAI models list response.
+//
+// ContextLength is an extension reported by GET /v1/models/{id} when the
+// model's context window is known.
+type OpenAIModel struct {
+	ID            string `json:"id"`
+	Object        string `json:"object"`
+	Created       int64  `json:"created"`
+	OwnedBy       string `json:"owned_by"`
+	ContextLength int    `json:"context_length,omitempty"`
+}
+
+// OpenAIModelList is the OpenAI GET /v1/models response.
+type OpenAIModelList struct {
+	Object string        `json:"object"`
+	Data   []OpenAIModel `json:"data"`
+}
+
+// OpenAIError is the OpenAI API error envelope.
+type OpenAIError struct {
+	Error OpenAIErrorBody `json:"error"`
+}
+
+// OpenAIErrorBody is the inner error object.
+type OpenAIErrorBody struct {
+	Message string  `json:"message"`
+	Type    string  `json:"type"`
+	Param   *string `json:"param"`
+	Code    string  `json:"code,omitempty"`
+}
@@ -0,0 +1,43 @@
+package config
+
+import (
+	"os"
+	"strings"
+)
+
+// EnvAPIKey overrides the api_key setting from server.conf. The xw CLI reads
+// the same variable to authenticate its requests.
+const EnvAPIKey = "XW_API_KEY"
+
+// GetAPIKey returns the effective API key clients must present.
+//
+// The XW_API_KEY environment variable takes precedence over the api_key
+// value in server.conf. An empty result means authentication is disabled.
+func (c *Config) GetAPIKey() string {
+	if v := strings.TrimSpace(os.Getenv(EnvAPIKey)); v != "" {
+		return v
+	}
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.Server.APIKey
+}
+
+// SetAPIKey replaces the server.conf API key while the server runs.
+// An empty key disables authentication unless XW_API_KEY is set.
+func (c *Config) SetAPIKey(key string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.Server.APIKey = key
+}
+
+// MaskAPIKey hides all but the last four characters of an API key for
+// display, e.g. "****3f9a". Short keys are masked completely.
+func MaskAPIKey(key string) string {
+	if key == "" {
+		return ""
+	}
+	if len(key) <= 8 {
+		return "****"
+	}
+	return "****" + key[len(key)-4:]
+}
@@ -0,0 +1,39 @@
+package config
+
+import (
+	"sync"
+	"testing"
+)
+
+func TestSetAPIKey(t *testing.T) {
+	t.Setenv(EnvAPIKey, "")
+	c := &Config{}
+
+	// Requests authenticate while the key is changed (run with -race)
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 100; i++ {
+			c.SetAPIKey("secret")
+		}
+	}()
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 100; i++ {
+			c.GetAPIKey()
+		}
+	}()
+	wg.Wait()
+
+	if got := c.GetAPIKey(); got != "secret" {
+		t.Errorf("GetAPIKey() = %q, want %q", got, "secret")
+	}
+
+	// XW_API_KEY takes precedence over the key set at runtime
+	t.Setenv(EnvAPIKey, "from-env")
+	c.SetAPIKey("")
+	if got := c.GetAPIKey(); got != "from-env" {
+		t.Errorf("GetAPIKey() = %q, want %q", got, "from-env")
+	}
+}
@@ -13,8 +13,8 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
-
-	"gopkg.in/yaml.v3"
+	"sync"
+	"time"
 )
 
 const (
@@ -26,6 +26,15 @@ const (
 	// Port 11581 is used as it doesn't require root privileges.
 	DefaultServerPort = 11581
 
+	// DefaultRequestTimeout is the default maximum duration of a
+	// non-streaming inference request through the proxy.
+	DefaultRequestTimeout = 10 * time.Minute
+
+	// DefaultStreamTimeout is the default maximum duration of a streaming
+	// inference request through the proxy. Long generations stream for a
+	// while, so the limit only guards against hung backends.
+	DefaultStreamTimeout = time.Hour
+
 	// DefaultRegistry is the default configuration package registry URL.
 	DefaultRegistry = "https://xw.tsingmao.com/packages.json"
 
@@ -40,6 +49,16 @@ const (
 	// DefaultModelsDir is the default models directory name.
 	// Model files are stored in this subdirectory within the data directory.
 	DefaultModelsDir = "models"
+
+	// DefaultImagesDir is the default image archives directory name.
+	// Offline Docker image archives (*.tar from `docker save`) placed in this
+	// subdirectory of the config directory are loaded before pulling.
+	DefaultImagesDir = "images"
+
+	// DefaultLogsDir is the default instance logs directory name.
+	// Each instance container gets a subdirectory of it, named after the
+	// instance ID, for the engine's log files.
+	DefaultLogsDir = "logs"
 )
 
 // Config represents the complete application configuration.
@@ -58,10 +77,18 @@ type Config struct {
 	// RuntimeParams holds runtime parameter templates loaded at startup.
 	RuntimeParams *RuntimeParamsConfig `json:"-"`
 	
+	// modelAliases holds client-facing model name aliases loaded from
+	// aliases.yaml in the config directory (optional). It is replaced on
+	// reload while requests read it; see GetModelAliases.
+	modelAliases *ModelAliasesConfig
+	
 	// BinaryVersion is the version of the xw binary (e.g., "v0.0.1").
 	// Set from main.Version during initialization.
 	// Used as the default config_version if not specified in server.conf.
 	BinaryVersion string `json:"-"`
+
+	// mu guards the settings that change while the server runs.
+	mu sync.RWMutex
 }
 
 // ServerConfig represents the HTTP server configuration.
@@ -75,6 +102,16 @@ type ServerConfig struct {
 	// Registry is the configuration package registry URL.
 	Registry string `json:"registry"`
 
+	// RegistryMirror rewrites Docker image registry hosts before pulling
+	// (e.g., "quay.io=quay.mirror.example.com"). Empty disables mirroring.
+	RegistryMirror string `json:"registry_mirror"`
+
+	// APIKey, when set, must be presented by clients as a bearer token on
+	// /api/* and /v1/* requests. Empty disables authentication. Never
+	// serialized; see GetAPIKey for the effective value. It changes while
+	// the server runs, so use GetAPIKey and SetAPIKey.
+	APIKey string `json:"-"`
+
 	// Host is the server host address (e.g., "localhost", "0.0.0.0").
 	// Using "localhost" restricts access to local clients only.
 	// Using "0.0.0.0" allows access from any network interface.
@@ -84,12 +121,46 @@ type ServerConfig struct {
 	// Common values are 11581 (default) or other non-privileged ports.
 	Port int `json:"port"`
 
+	// TLSCert is the path to the PEM-encoded TLS certificate (chain).
+	// The server serves HTTPS when both TLSCert and TLSKey are set.
+	TLSCert string `json:"tls_cert,omitempty"`
+
+	// TLSKey is the path to the PEM-encoded private key for TLSCert.
+	TLSKey string `json:"tls_key,omitempty"`
+
+	// RequestTimeout is the maximum duration of a non-streaming inference
+	// request proxied to an instance. Zero means no limit.
+	RequestTimeout time.Duration `json:"-"`
+
+	// StreamTimeout is the maximum duration of a streaming inference request
+	// proxied to an instance. Zero means no limit.
+	StreamTimeout time.Duration `json:"-"`
+
+	// Socket is the path of a Unix domain socket to listen on instead of
+	// Host and Port. Only local processes with access to the socket file
+	// can reach the server.
+	Socket string `json:"socket,omitempty"`
+
 	// Address is the computed full server address.
 	// This field is not serialized and is computed from Host and Port.
-	// Format: "http://host:port"
+	// Format: "http://host:port", "https://host:port" or "unix:///path"
 	Address string `json:"-"`
 }
 
+// TLSEnabled reports whether the server is configured to serve HTTPS.
+func (s *ServerConfig) TLSEnabled() bool {
+	return s.TLSCert != "" && s.TLSKey != ""
+}
+
+// Scheme returns the URL scheme the server is reachable with: "https" when
+// TLS is enabled, "http" otherwise.
+func (s *ServerConfig) Scheme() string {
+	if s.TLSEnabled() {
+		return "https"
+	}
+	return "http"
+}
+
 // StorageConfig represents the storage and persistence configuration.
 //
 // This configuration defines where the application stores its data
@@ -113,6 +184,21 @@ func (s *StorageConfig) GetModelsDir() string {
 	return filepath.Join(s.DataDir, DefaultModelsDir)
 }
 
+// GetImagesDir returns the offline Docker image archives directory path.
+// Image archives are stored in an "images" subdirectory within the config directory.
+// Example: ~/.xw/images
+func (s *StorageConfig) GetImagesDir() string {
+	return filepath.Join(s.ConfigDir, DefaultImagesDir)
+}
+
+// GetLogsDir returns the instance logs directory path.
+// Engine log files of each instance are kept in a subdirectory named after
+// the instance ID, so they survive the removal of its container.
+// Example: ~/.xw/logs
+func (s *StorageConfig) GetLogsDir() string {
+	return filepath.Join(s.ConfigDir, DefaultLogsDir)
+}
+
 // NewDefaultConfig creates a new configuration instance with default values.
 //
 // This function initializes a Config struct with sensible defaults suitable
@@ -142,6 +228,9 @@ func NewDefaultConfig() *Config {
 			Host:    DefaultServerHost,
 			Port:    DefaultServerPort,
 			Address: fmt.Sprintf("http://%s:%d", DefaultServerHost, DefaultServerPort),
+
+			RequestTimeout: DefaultRequestTimeout,
+			StreamTimeout:  DefaultStreamTimeout,
 		},
 		Storage: StorageConfig{
 			ConfigDir: configDir,
@@ -191,6 +280,9 @@ func NewConfigWithCustomDirs(configDir, dataDir string) *Config {
 			Host:    DefaultServerHost,
 			Port:    DefaultServerPort,
 			Address: fmt.Sprintf("http://%s:%d", DefaultServerHost, DefaultServerPort),
+
+			RequestTimeout: DefaultRequestTimeout,
+			StreamTimeout:  DefaultStreamTimeout,
 		},
 		Storage: StorageConfig{
 			ConfigDir: configDir,
@@ -201,19 +293,23 @@ func NewConfigWithCustomDirs(configDir, dataDir string) *Config {
 
 // GetServerAddress returns the complete HTTP server address.
 //
-// This method constructs the full server URL from the host and port
+// This method constructs the full server URL from the scheme, host and port
 // configuration. The returned address can be used by HTTP clients to
 // connect to the server.
 //
 // Returns:
-//   - A string in the format "http://host:port"
+//   - A string in the format "http://host:port", or "https://host:port"
+//     when TLS is enabled, or "unix:///path" when listening on a Unix socket
 //
 // Example:
 //
 //	addr := cfg.GetServerAddress()
 //	// Returns: "http://localhost:11581"
 func (c *Config) GetServerAddress() string {
-	return fmt.Sprintf("http://%s:%d", c.Server.Host, c.Server.Port)
+	if c.Server.Socket != "" {
+		return "unix://" + c.Server.Socket
+	}
+	return fmt.Sprintf("%s://%s:%d", c.Server.Scheme(), c.Server.Host, c.Server.Port)
 }
 
 // EnsureDirectories creates all required directories if they don't exist.
@@ -258,6 +354,11 @@ func (c *Config) EnsureDirectories() error {
 //   - devices.yaml: Device and runtime images config (cached globally)
 //   - models.yaml: Model definitions (registered globally via loadModels callback)
 //
+// The optional aliases.yaml in the config directory is loaded as well.
+//
+// The XW_DEVICES_CONFIG and XW_MODELS_CONFIG environment variables, when
+// set, replace the versioned devices.yaml and models.yaml paths.
+//
 // After this call, all configurations are loaded and ready to use throughout
 // the application lifecycle. No further path handling or file loading needed.
 //
@@ -282,14 +383,21 @@ func (c *Config) LoadVersionedConfigs(configVersion string, loadModels func(stri
 	}
 	c.RuntimeParams = runtimeParams
 	
+	// Load aliases.yaml (optional, lives outside the versioned directory)
+	modelAliases, err := LoadModelAliasesConfigFrom(filepath.Join(c.Storage.ConfigDir, ModelAliasesFileName))
+	if err != nil {
+		return fmt.Errorf("failed to load %s: %w", ModelAliasesFileName, err)
+	}
+	c.setModelAliases(modelAliases)
+	
 	// Load devices.yaml (internally cached globally)
-	devicesPath := filepath.Join(versionedDir, "devices.yaml")
+	devicesPath := resolveConfigPath(EnvDevicesConfig, filepath.Join(versionedDir, "devices.yaml"))
 	if _, err := LoadRuntimeImagesConfigFrom(devicesPath); err != nil {
 		return fmt.Errorf("failed to load devices.yaml: %w", err)
 	}
 	
 	// Load models.yaml (registered globally via callback)
-	modelsPath := filepath.Join(versionedDir, "models.yaml")
+	modelsPath := resolveConfigPath(EnvModelsConfig, filepath.Join(versionedDir, "models.yaml"))
 	if err := loadModels(modelsPath); err != nil {
 		return fmt.Errorf("failed to load models.yaml: %w", err)
 	}
@@ -309,6 +417,9 @@ func (c *Config) LoadVersionedConfigs(configVersion string, loadModels func(stri
 //   - devices.yaml: Device and runtime images config (cache cleared and reloaded)
 //   - models.yaml: Model definitions (re-registered, overwrites existing)
 //
+// As with LoadVersionedConfigs, XW_DEVICES_CONFIG and XW_MODELS_CONFIG
+// override the devices.yaml and models.yaml paths.
+//
 // This method should be called when configuration needs to be updated without
 // restarting the server (e.g., after running 'xw update').
 //
@@ -334,14 +445,20 @@ func (c *Config) ReloadVersionedConfigs(configVersion string, loadModels func(st
 		return fmt.Errorf("failed to load runtime_params.yaml: %w", err)
 	}
 	
+	// Load and validate aliases.yaml
+	newModelAliases, err := LoadModelAliasesConfigFrom(filepath.Join(c.Storage.ConfigDir, ModelAliasesFileName))
+	if err != nil {
+		return fmt.Errorf("failed to load %s: %w", ModelAliasesFileName, err)
+	}
+	
 	// Validate devices.yaml by parsing without updating cache
-	devicesPath := filepath.Join(versionedDir, "devices.yaml")
+	devicesPath := resolveConfigPath(EnvDevicesConfig, filepath.Join(versionedDir, "devices.yaml"))
 	if err := validateDevicesFile(devicesPath); err != nil {
 		return fmt.Errorf("failed to validate devices.yaml: %w", err)
 	}
 	
 	// Validate models.yaml by parsing without registering
-	modelsPath := filepath.Join(versionedDir, "models.yaml")
+	modelsPath := resolveConfigPath(EnvModelsConfig, filepath.Join(versionedDir, "models.yaml"))
 	if err := validateModelsFile(modelsPath); err != nil {
 		return fmt.Errorf("failed to validate models.yaml: %w", err)
 	}
@@ -350,6 +467,7 @@ func (c *Config) ReloadVersionedConfigs(configVersion string, loadModels func(st
 	
 	// Update runtime params in Config
 	c.RuntimeParams = newRuntimeParams
+	c.setModelAliases(newModelAliases)
 	
 	// Reload devices config (clears cache and reloads from file)
 	if _, err := ReloadDevicesConfig(devicesPath); err != nil {
@@ -375,8 +493,12 @@ func validateDevicesFile(path string) error {
 	}
 	
 	var config DevicesConfig
-	if err := yaml.Unmarshal(data, &config); err != nil {
-		return fmt.Errorf("failed to parse YAML: %w", err)
+	if err := unmarshalConfigFile(path, data, &config); err != nil {
+		return fmt.Errorf("failed to parse: %w", err)
+	}
+	
+	if err := migrateDevicesConfig(&config); err != nil {
+		return err
 	}
 	
 	return validateDevicesConfig(&config)
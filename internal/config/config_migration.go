@@ -0,0 +1,100 @@
+package config
+
+import (
+	"fmt"
+
+	"github.com/tsingmaoai/xw-cli/internal/logger"
+)
+
+// ConfigSchemaVersion is the schema version of devices.yaml and models.yaml
+// understood by this build. Files declaring an older version are migrated
+// to it after parsing and before validation.
+const ConfigSchemaVersion = "1.1"
+
+// configMigration transforms a parsed configuration from one schema version
+// to the next. A nil transform means the step needs no changes to that file
+// type beyond bumping the version.
+type configMigration struct {
+	// To is the schema version produced by this migration
+	To string
+
+	// Description summarizes what the migration changes, for logging
+	Description string
+
+	// Devices transforms a devices configuration (optional)
+	Devices func(*DevicesConfig) error
+
+	// Models transforms a models configuration (optional)
+	Models func(*ModelsConfig) error
+}
+
+// configMigrations maps a schema version to the migration that upgrades it.
+// Migrations are applied in a chain until ConfigSchemaVersion is reached.
+var configMigrations = map[string]configMigration{
+	"1.0": {
+		To:          "1.1",
+		Description: "no structural changes (device_count added as optional field)",
+	},
+}
+
+// migrateDevicesConfig upgrades a parsed devices configuration to
+// ConfigSchemaVersion in place.
+//
+// Parameters:
+//   - config: Parsed configuration to migrate
+//
+// Returns:
+//   - Error if the version is unknown or a migration step fails
+func migrateDevicesConfig(config *DevicesConfig) error {
+	return migrateConfig("device", &config.Version, func(m configMigration) error {
+		if m.Devices == nil {
+			return nil
+		}
+		return m.Devices(config)
+	})
+}
+
+// migrateModelsConfig upgrades a parsed models configuration to
+// ConfigSchemaVersion in place.
+//
+// Parameters:
+//   - config: Parsed configuration to migrate
+//
+// Returns:
+//   - Error if the version is unknown or a migration step fails
+func migrateModelsConfig(config *ModelsConfig) error {
+	return migrateConfig("model", &config.Version, func(m configMigration) error {
+		if m.Models == nil {
+			return nil
+		}
+		return m.Models(config)
+	})
+}
+
+// migrateConfig walks the migration chain starting at *version, calling apply
+// for each step and updating *version as it goes.
+//
+// An empty version is left untouched so that validation reports it.
+func migrateConfig(kind string, version *string, apply func(configMigration) error) error {
+	if *version == "" || *version == ConfigSchemaVersion {
+		return nil
+	}
+
+	from := *version
+	for steps := 0; *version != ConfigSchemaVersion; steps++ {
+		m, ok := configMigrations[*version]
+		if !ok || steps >= len(configMigrations) {
+			return fmt.Errorf("unsupported %s configuration schema version %q (current: %s)",
+				kind, *version, ConfigSchemaVersion)
+		}
+		if err := apply(m); err != nil {
+			return fmt.Errorf("failed to migrate %s configuration from %s to %s: %w",
+				kind, *version, m.To, err)
+		}
+		logger.Debug("Migrated %s configuration schema %s -> %s: %s", kind, *version, m.To, m.Description)
+		*version = m.To
+	}
+
+	logger.Info("Migrated %s configuration from schema %s to %s", kind, from, ConfigSchemaVersion)
+	return nil
+}
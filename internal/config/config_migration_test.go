@@ -0,0 +1,111 @@
+package config
+
+import (
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// devicesSchema10 is a devices.yaml written for schema 1.0, which had no
+// device_count and described the card count in a comment.
+const devicesSchema10 = `
+version: "1.0"
+
+vendors:
+  - vendor_name: Huawei
+    vendor_id: "0x19e5"
+    chip_models:
+      - config_key: ascend-310p
+        model_name: Ascend 310P
+        device_id: "0xd500"
+        generation: Ascend 3xx
+        chips_per_device: 2  # Each PCI device contains 2 AI chips
+        # Assumes 4 physical cards (8 logical chips total)
+        topology:
+          boxes:
+            - devices: [0, 1]
+            - devices: [2, 3]
+        runtime_images:
+          vllm:
+            arm64: quay.io/ascend/vllm-ascend:v0.11.0rc0-310p
+            amd64: NONE
+`
+
+func TestMigrateDevicesConfigFromSchema10(t *testing.T) {
+	var cfg DevicesConfig
+	if err := unmarshalConfigFile("devices.yaml", []byte(devicesSchema10), &cfg); err != nil {
+		t.Fatalf("failed to parse schema 1.0 config: %v", err)
+	}
+
+	if err := migrateDevicesConfig(&cfg); err != nil {
+		t.Fatalf("migrateDevicesConfig() failed: %v", err)
+	}
+	if cfg.Version != ConfigSchemaVersion {
+		t.Errorf("Version = %q, want %q", cfg.Version, ConfigSchemaVersion)
+	}
+	if err := validateDevicesConfig(&cfg); err != nil {
+		t.Errorf("migrated config is invalid: %v", err)
+	}
+
+	if len(cfg.Vendors) != 1 || len(cfg.Vendors[0].ChipModels) != 1 {
+		t.Fatalf("got %d vendors, want 1 with 1 chip model", len(cfg.Vendors))
+	}
+	model := cfg.Vendors[0].ChipModels[0]
+	if model.ConfigKey != "ascend-310p" || model.DeviceID != "0xd500" || model.ChipsPerDevice != 2 {
+		t.Errorf("chip model = %s %s x%d, want ascend-310p 0xd500 x2",
+			model.ConfigKey, model.DeviceID, model.ChipsPerDevice)
+	}
+	if model.DeviceCount != 0 {
+		t.Errorf("DeviceCount = %d, want 0 (unset, using the default)", model.DeviceCount)
+	}
+	wantBoxes := []TopologyBox{{Devices: []int{0, 1}}, {Devices: []int{2, 3}}}
+	if model.Topology == nil || !reflect.DeepEqual(model.Topology.Boxes, wantBoxes) {
+		t.Errorf("Topology = %+v, want boxes %v", model.Topology, wantBoxes)
+	}
+}
+
+func TestMigrateConfigVersions(t *testing.T) {
+	tests := []struct {
+		version string
+		want    string
+		wantErr bool
+	}{
+		{version: "1.0", want: ConfigSchemaVersion},
+		{version: ConfigSchemaVersion, want: ConfigSchemaVersion},
+		{version: "", want: ""},
+		{version: "0.9", wantErr: true},
+		{version: "2.0", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		cfg := &ModelsConfig{Version: tt.version}
+		err := migrateModelsConfig(cfg)
+		if tt.wantErr {
+			if err == nil || !strings.Contains(err.Error(), "unsupported model configuration schema version") {
+				t.Errorf("version %q: error = %v, want unsupported version", tt.version, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("version %q: migrateModelsConfig() failed: %v", tt.version, err)
+		}
+		if cfg.Version != tt.want {
+			t.Errorf("version %q: migrated to %q, want %q", tt.version, cfg.Version, tt.want)
+		}
+	}
+}
+
+func TestShippedConfigVersionsLoad(t *testing.T) {
+	dirs, err := filepath.Glob(filepath.Join("..", "..", "configs", "*.*.*"))
+	if err != nil || len(dirs) == 0 {
+		t.Fatalf("no shipped config versions found: %v", err)
+	}
+
+	for _, dir := range dirs {
+		report := ValidateConfigFiles(filepath.Join(dir, "devices.yaml"), filepath.Join(dir, "models.yaml"))
+		if !report.OK() {
+			t.Errorf("%s: %s", filepath.Base(dir), strings.Join(report.Errors, "; "))
+		}
+	}
+}
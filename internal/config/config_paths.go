@@ -0,0 +1,220 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	// EnvDevicesConfig overrides the path of devices.yaml.
+	EnvDevicesConfig = "XW_DEVICES_CONFIG"
+
+	// EnvModelsConfig overrides the path of models.yaml.
+	EnvModelsConfig = "XW_MODELS_CONFIG"
+)
+
+// resolveConfigPath returns the configuration file path to load.
+//
+// A non-empty value of the environment variable envVar takes precedence over
+// defaultPath, so a single file can be swapped out for testing or per-user
+// overrides without changing the versioned config directory.
+//
+// Parameters:
+//   - envVar: Environment variable consulted first (e.g., XW_DEVICES_CONFIG)
+//   - defaultPath: Path used when the variable is unset or empty
+//
+// Returns:
+//   - The effective configuration file path
+func resolveConfigPath(envVar, defaultPath string) string {
+	if v := strings.TrimSpace(os.Getenv(envVar)); v != "" {
+		return filepath.Clean(v)
+	}
+	return defaultPath
+}
+
+// unmarshalConfigFile decodes a configuration file into v, choosing the
+// format from the file extension: ".json" files are decoded as JSON and
+// everything else as YAML. Both formats share the same field names.
+//
+// Environment variable references in string values are expanded after the
+// file is parsed (see configEnvExpander), so one file can serve several
+// sites and a value can never change the structure of the file.
+//
+// Parameters:
+//   - path: Path the data was read from, used only to pick the format
+//   - data: Raw file contents
+//   - v: Pointer to the destination struct
+//
+// Returns:
+//   - Error naming the format if the data cannot be decoded, or the
+//     undefined environment variables
+func unmarshalConfigFile(path string, data []byte, v interface{}) error {
+	isJSON := strings.EqualFold(filepath.Ext(path), ".json")
+	expandEnv := bytes.Contains(data, []byte("${"))
+
+	if isJSON {
+		if expandEnv {
+			var doc interface{}
+			decoder := json.NewDecoder(bytes.NewReader(data))
+			decoder.UseNumber()
+			if err := decoder.Decode(&doc); err != nil {
+				return fmt.Errorf("invalid JSON: %w", err)
+			}
+			e := newConfigEnvExpander()
+			doc = e.expandJSON(doc)
+			if err := e.err(); err != nil {
+				return err
+			}
+			data, _ = json.Marshal(doc)
+		}
+		if err := json.Unmarshal(data, v); err != nil {
+			return fmt.Errorf("invalid JSON: %w", err)
+		}
+		return nil
+	}
+
+	if !expandEnv {
+		if err := yaml.Unmarshal(data, v); err != nil {
+			return fmt.Errorf("invalid YAML: %w", err)
+		}
+		return nil
+	}
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return fmt.Errorf("invalid YAML: %w", err)
+	}
+	if doc.Kind == 0 {
+		return nil
+	}
+	e := newConfigEnvExpander()
+	e.expandYAML(&doc)
+	if err := e.err(); err != nil {
+		return err
+	}
+	if err := doc.Decode(v); err != nil {
+		return fmt.Errorf("invalid YAML: %w", err)
+	}
+	return nil
+}
+
+// configEnvPattern matches environment variable references in configuration
+// files: ${VAR} and ${VAR:-fallback}.
+var configEnvPattern = regexp.MustCompile(`\$\{([A-Za-z_][A-Za-z0-9_]*)(:-([^}]*))?\}`)
+
+// configEnvExpander replaces environment variable references in the string
+// values of a parsed configuration file with values from the process
+// environment.
+//
+// ${VAR} is replaced by the value of VAR, which must be set. ${VAR:-fallback}
+// is replaced by the value of VAR, or by fallback if VAR is unset or empty.
+// Write $${ for a literal ${. Comments are not values, so commented-out
+// settings need no variables.
+//
+// Since references are expanded after parsing, a value containing quotes,
+// colons or newlines stays part of the string it was referenced in. An
+// unquoted YAML scalar is typed after expansion, so "port: ${PORT}" still
+// decodes into a number.
+type configEnvExpander struct {
+	undefined []string
+	seen      map[string]bool
+}
+
+// newConfigEnvExpander creates an expander with no undefined variables.
+func newConfigEnvExpander() *configEnvExpander {
+	return &configEnvExpander{seen: make(map[string]bool)}
+}
+
+// expand expands the references in a single value. Undefined variables
+// without a fallback are left as they are and recorded for err.
+func (e *configEnvExpander) expand(value string) string {
+	if !strings.Contains(value, "${") {
+		return value
+	}
+
+	// Protect escaped references, expand, then unescape
+	value = strings.ReplaceAll(value, "$${", "\x00{")
+	value = configEnvPattern.ReplaceAllStringFunc(value, func(ref string) string {
+		m := configEnvPattern.FindStringSubmatch(ref)
+		name := m[1]
+		if v := os.Getenv(name); v != "" {
+			return v
+		}
+		if m[2] != "" {
+			return m[3]
+		}
+		if _, set := os.LookupEnv(name); set {
+			return ""
+		}
+		if !e.seen[name] {
+			e.seen[name] = true
+			e.undefined = append(e.undefined, name)
+		}
+		return ref
+	})
+	return strings.ReplaceAll(value, "\x00{", "${")
+}
+
+// expandYAML expands the references in every scalar of a YAML node tree.
+// Untagged plain scalars are re-typed from their expanded value; quoted
+// and explicitly tagged scalars keep their type.
+func (e *configEnvExpander) expandYAML(n *yaml.Node) {
+	if n.Kind == yaml.ScalarNode {
+		expanded := e.expand(n.Value)
+		if expanded != n.Value {
+			n.Value = expanded
+			if n.Style&(yaml.TaggedStyle|yaml.DoubleQuotedStyle|yaml.SingleQuotedStyle|yaml.LiteralStyle|yaml.FoldedStyle) == 0 {
+				n.Tag = ""
+			}
+		}
+		return
+	}
+	for _, child := range n.Content {
+		e.expandYAML(child)
+	}
+}
+
+// expandJSON expands the references in every string of a decoded JSON
+// value, including object keys, and returns the expanded value.
+func (e *configEnvExpander) expandJSON(v interface{}) interface{} {
+	switch v := v.(type) {
+	case string:
+		return e.expand(v)
+	case []interface{}:
+		for i, item := range v {
+			v[i] = e.expandJSON(item)
+		}
+		return v
+	case map[string]interface{}:
+		// Sorted, so undefined variables are reported in a stable order
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		expanded := make(map[string]interface{}, len(v))
+		for _, key := range keys {
+			expanded[e.expand(key)] = e.expandJSON(v[key])
+		}
+		return expanded
+	default:
+		return v
+	}
+}
+
+// err returns an error naming every undefined variable without a fallback,
+// or nil if all references were expanded.
+func (e *configEnvExpander) err() error {
+	if len(e.undefined) == 0 {
+		return nil
+	}
+	return fmt.Errorf("undefined environment variable(s): %s (set them or use ${VAR:-default})",
+		strings.Join(e.undefined, ", "))
+}
@@ -0,0 +1,104 @@
+package config
+
+import (
+	"strings"
+	"testing"
+)
+
+// envTestConfig is a configuration file layout for the expansion tests.
+type envTestConfig struct {
+	Registry string   `json:"registry" yaml:"registry"`
+	Port     int      `json:"port" yaml:"port"`
+	Token    string   `json:"token" yaml:"token"`
+	Args     []string `json:"args" yaml:"args"`
+}
+
+func TestUnmarshalConfigFileExpandsEnv(t *testing.T) {
+	t.Setenv("XW_TEST_REGISTRY", "harbor.lan:5000")
+	t.Setenv("XW_TEST_PORT", "8000")
+	t.Setenv("XW_TEST_EMPTY", "")
+	t.Setenv("XW_TEST_TOKEN", "a\"b: c\n- d")
+
+	tests := []struct {
+		name string
+		path string
+		data string
+		want envTestConfig
+	}{
+		{
+			name: "variable",
+			path: "devices.yaml",
+			data: "registry: ${XW_TEST_REGISTRY}/ascend\n",
+			want: envTestConfig{Registry: "harbor.lan:5000/ascend"},
+		},
+		{
+			name: "unquoted number",
+			path: "devices.yaml",
+			data: "port: ${XW_TEST_PORT}\n",
+			want: envTestConfig{Port: 8000},
+		},
+		{
+			name: "fallback for unset and empty variables",
+			path: "devices.yaml",
+			data: "registry: ${XW_TEST_UNSET:-quay.io}\ntoken: ${XW_TEST_EMPTY:-none}\n",
+			want: envTestConfig{Registry: "quay.io", Token: "none"},
+		},
+		{
+			name: "set but empty variable",
+			path: "devices.yaml",
+			data: "token: '${XW_TEST_EMPTY}'\n",
+			want: envTestConfig{},
+		},
+		{
+			name: "escaped reference",
+			path: "devices.yaml",
+			data: "args: ['--chat-template=$${TEMPLATE}']\n",
+			want: envTestConfig{Args: []string{"--chat-template=${TEMPLATE}"}},
+		},
+		{
+			name: "commented-out reference",
+			path: "devices.yaml",
+			data: "# registry: ${XW_TEST_UNSET}\nregistry: quay.io # ${XW_TEST_UNSET}\n",
+			want: envTestConfig{Registry: "quay.io"},
+		},
+		{
+			// A value is substituted into a string, not into the file
+			name: "yaml special characters",
+			path: "devices.yaml",
+			data: "token: ${XW_TEST_TOKEN}\nargs: [\"${XW_TEST_TOKEN}\"]\n",
+			want: envTestConfig{Token: "a\"b: c\n- d", Args: []string{"a\"b: c\n- d"}},
+		},
+		{
+			name: "json",
+			path: "devices.json",
+			data: `{"registry": "${XW_TEST_REGISTRY}", "token": "${XW_TEST_TOKEN}", "port": 1}`,
+			want: envTestConfig{Registry: "harbor.lan:5000", Token: "a\"b: c\n- d", Port: 1},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got envTestConfig
+			if err := unmarshalConfigFile(tt.path, []byte(tt.data), &got); err != nil {
+				t.Fatalf("unmarshalConfigFile() failed: %v", err)
+			}
+			if got.Registry != tt.want.Registry || got.Port != tt.want.Port || got.Token != tt.want.Token ||
+				strings.Join(got.Args, "|") != strings.Join(tt.want.Args, "|") {
+				t.Errorf("unmarshalConfigFile() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestUnmarshalConfigFileUndefinedEnv(t *testing.T) {
+	for _, path := range []string{"devices.yaml", "devices.json"} {
+		data := `{"registry": "${XW_TEST_UNSET_A}", "token": "${XW_TEST_UNSET_B}", "args": ["${XW_TEST_UNSET_A}"]}`
+		var got envTestConfig
+		err := unmarshalConfigFile(path, []byte(data), &got)
+		if err == nil {
+			t.Fatalf("unmarshalConfigFile(%s) succeeded with undefined variables", path)
+		}
+		if !strings.Contains(err.Error(), "XW_TEST_UNSET_A, XW_TEST_UNSET_B") {
+			t.Errorf("unmarshalConfigFile(%s) = %v, want each undefined variable named once", path, err)
+		}
+	}
+}
@@ -0,0 +1,223 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/tsingmaoai/xw-cli/internal/api"
+)
+
+// ValidationErrors collects every violation found by a validation pass, so
+// a configuration with several problems is reported in one go rather than
+// one problem per reload.
+type ValidationErrors []error
+
+// Error lists the violations, one per line when there are several.
+func (e ValidationErrors) Error() string {
+	if len(e) == 1 {
+		return e[0].Error()
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "%d problems:", len(e))
+	for _, err := range e {
+		b.WriteString("\n  - ")
+		b.WriteString(err.Error())
+	}
+	return b.String()
+}
+
+// Unwrap returns the individual violations, for errors.Is and errors.As.
+func (e ValidationErrors) Unwrap() []error {
+	return e
+}
+
+// add records a violation.
+func (e *ValidationErrors) add(format string, args ...interface{}) {
+	*e = append(*e, fmt.Errorf(format, args...))
+}
+
+// errOrNil returns the collected violations as an error, or nil if there
+// are none.
+func (e ValidationErrors) errOrNil() error {
+	if len(e) == 0 {
+		return nil
+	}
+	return e
+}
+
+// ValidationReport is the result of validating configuration files with
+// ValidateConfigFiles.
+//
+// Errors make a file unusable: the server would refuse to load it. Warnings
+// point at settings that load fine but likely do not do what was intended,
+// such as a model engine for a chip that devices.yaml does not define.
+type ValidationReport struct {
+	// Errors lists the problems that prevent loading, prefixed with the file
+	Errors []string
+
+	// Warnings lists suspicious but loadable settings
+	Warnings []string
+}
+
+// OK reports whether the validated files have no errors.
+func (r *ValidationReport) OK() bool {
+	return len(r.Errors) == 0
+}
+
+// ValidateConfigFiles validates device and model configuration files
+// without loading them into the server's configuration caches.
+//
+// Each file is parsed, migrated and validated as LoadDevicesConfigFrom and
+// LoadModelsConfig would. When both files are given and valid, they are
+// also checked against each other.
+//
+// Parameters:
+//   - devicesPath: Path to devices.yaml (empty to skip)
+//   - modelsPath: Path to models.yaml (empty to skip)
+//
+// Returns:
+//   - Report of the errors and warnings found
+func ValidateConfigFiles(devicesPath, modelsPath string) *ValidationReport {
+	report := &ValidationReport{}
+
+	var devices *DevicesConfig
+	if devicesPath != "" {
+		var config DevicesConfig
+		if err := readConfigFile(devicesPath, &config); err != nil {
+			report.Errors = append(report.Errors, err.Error())
+		} else if err := migrateDevicesConfig(&config); err != nil {
+			report.Errors = append(report.Errors, fmt.Sprintf("%s: %v", devicesPath, err))
+		} else if err := validateDevicesConfig(&config); err != nil {
+			report.addErrors(devicesPath, err)
+		} else {
+			devices = &config
+		}
+	}
+
+	var models *ModelsConfig
+	if modelsPath != "" {
+		var config ModelsConfig
+		if err := readConfigFile(modelsPath, &config); err != nil {
+			report.Errors = append(report.Errors, err.Error())
+		} else if err := migrateModelsConfig(&config); err != nil {
+			report.Errors = append(report.Errors, fmt.Sprintf("%s: %v", modelsPath, err))
+		} else if err := validateModelsConfig(&config); err != nil {
+			report.addErrors(modelsPath, err)
+		} else {
+			models = &config
+		}
+	}
+
+	if models != nil {
+		report.Warnings = append(report.Warnings, modelGroupWarnings(models)...)
+	}
+	if models != nil && devices != nil {
+		report.Warnings = append(report.Warnings, modelDeviceWarnings(models, devices)...)
+	}
+
+	return report
+}
+
+// addErrors records a validation error of a file, one entry per violation.
+func (r *ValidationReport) addErrors(path string, err error) {
+	var errs ValidationErrors
+	if !errors.As(err, &errs) {
+		errs = ValidationErrors{err}
+	}
+	for _, e := range errs {
+		r.Errors = append(r.Errors, fmt.Sprintf("%s: %v", path, e))
+	}
+}
+
+// ValidateVersionedConfigs validates the devices.yaml and models.yaml the
+// server loads for a configuration version, honoring XW_DEVICES_CONFIG and
+// XW_MODELS_CONFIG as LoadVersionedConfigs does.
+//
+// Parameters:
+//   - configVersion: Configuration version (e.g., "v0.0.5")
+//
+// Returns:
+//   - Report of the errors and warnings found
+func (c *Config) ValidateVersionedConfigs(configVersion string) *ValidationReport {
+	versionedDir := filepath.Join(c.Storage.ConfigDir, configVersion)
+	devicesPath := resolveConfigPath(EnvDevicesConfig, filepath.Join(versionedDir, "devices.yaml"))
+	modelsPath := resolveConfigPath(EnvModelsConfig, filepath.Join(versionedDir, "models.yaml"))
+	return ValidateConfigFiles(devicesPath, modelsPath)
+}
+
+// readConfigFile reads and decodes a configuration file.
+func readConfigFile(path string, v interface{}) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	if err := unmarshalConfigFile(path, data, v); err != nil {
+		return fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	return nil
+}
+
+// modelGroupWarnings reports model groups that have no members.
+// validateModelsConfig accepts them, so they are warnings, not errors.
+func modelGroupWarnings(models *ModelsConfig) []string {
+	var warnings []string
+	for _, name := range GetModelGroupNames(models) {
+		if len(models.ModelGroups[name]) == 0 {
+			warnings = append(warnings, fmt.Sprintf("model group %s has no members", name))
+		}
+	}
+	return warnings
+}
+
+// modelDeviceWarnings reports model engines that can never be used with
+// the given devices: engines for a device config_key that devices.yaml does
+// not define, and docker engines whose backend has no runtime image for the
+// device.
+func modelDeviceWarnings(models *ModelsConfig, devices *DevicesConfig) []string {
+	// Chip models by config key; variants share their base model's images
+	chips := make(map[string]*ChipModelConfig)
+	for i := range devices.Vendors {
+		for j := range devices.Vendors[i].ChipModels {
+			chip := &devices.Vendors[i].ChipModels[j]
+			chips[chip.ConfigKey] = chip
+			for _, variant := range chip.Variants {
+				chips[variant.VariantKey] = chip
+			}
+		}
+	}
+
+	var warnings []string
+	for _, model := range models.Models {
+		deviceKeys := make([]string, 0, len(model.SupportedDevices))
+		for device := range model.SupportedDevices {
+			deviceKeys = append(deviceKeys, device)
+		}
+		sort.Strings(deviceKeys)
+
+		for _, device := range deviceKeys {
+			chip, ok := chips[device]
+			if !ok {
+				warnings = append(warnings, fmt.Sprintf(
+					"model %s: device %s is not defined in the device configuration, its engines are never used",
+					model.ModelID, device))
+				continue
+			}
+			for _, engine := range model.SupportedDevices[device] {
+				backend, mode, _ := strings.Cut(engine, ":")
+				if api.DeploymentMode(mode) != api.DeploymentModeDocker {
+					continue
+				}
+				if _, ok := chip.RuntimeImages[backend]; !ok {
+					warnings = append(warnings, fmt.Sprintf(
+						"model %s: engine %s has no runtime image for device %s",
+						model.ModelID, engine, device))
+				}
+			}
+		}
+	}
+	return warnings
+}
@@ -0,0 +1,52 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestValidateConfigFilesModelGroups(t *testing.T) {
+	const models = `
+version: "1.1"
+
+models:
+  - model_id: qwen3-8b
+    source_id: Qwen/Qwen3-8B
+    supported_devices:
+      ascend-910b:
+        - vllm:docker
+
+model_groups:
+  qwen3:
+    - qwen3-8b
+  empty: []
+  unset:
+`
+	path := filepath.Join(t.TempDir(), "models.yaml")
+	if err := os.WriteFile(path, []byte(models), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	// A group without members loads, but is reported
+	report := ValidateConfigFiles("", path)
+	if !report.OK() {
+		t.Fatalf("ValidateConfigFiles() errors = %v, want none", report.Errors)
+	}
+	want := []string{"model group empty has no members", "model group unset has no members"}
+	if !reflect.DeepEqual(report.Warnings, want) {
+		t.Errorf("ValidateConfigFiles() warnings = %v, want %v", report.Warnings, want)
+	}
+
+	// Members must be defined models
+	unknown := strings.Replace(models, "  empty: []", "  empty: [qwen3-9b]", 1)
+	if err := os.WriteFile(path, []byte(unknown), 0644); err != nil {
+		t.Fatal(err)
+	}
+	report = ValidateConfigFiles("", path)
+	if len(report.Errors) != 1 || !strings.Contains(report.Errors[0], "model group empty, member[0]: unknown model_id 'qwen3-9b'") {
+		t.Errorf("ValidateConfigFiles() errors = %v, want the unknown member", report.Errors)
+	}
+}
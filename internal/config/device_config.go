@@ -1,7 +1,7 @@
 // Package config - device_config.go implements device configuration loading and management.
 //
 // This module provides a flexible configuration system for AI chip detection and management.
-// Device configurations are loaded from YAML files, allowing easy addition of new chip
+// Device configurations are loaded from YAML (or JSON) files, allowing easy addition of new chip
 // types without code changes.
 //
 // The configuration system supports multiple bus types (PCIe, CXL, virtual) and provides
@@ -12,6 +12,8 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strconv"
+	"strings"
 	"sync"
 
 	"gopkg.in/yaml.v3"
@@ -29,15 +31,15 @@ import (
 type ChipVariant struct {
 	// SubsystemDeviceID is the PCIe subsystem device identifier (16-bit hex value)
 	// Example: "0x0001" for 910B1, "0x0002" for 910B2
-	SubsystemDeviceID string `yaml:"subsystem_device_id"`
+	SubsystemDeviceID string `yaml:"subsystem_device_id" json:"subsystem_device_id"`
 	
 	// VariantKey is the unique identifier for this variant
 	// Example: "ascend-910b1", "ascend-910b2"
-	VariantKey string `yaml:"variant_key"`
+	VariantKey string `yaml:"variant_key" json:"variant_key"`
 	
 	// VariantName is the human-readable variant name
 	// Example: "910B1", "910B2"
-	VariantName string `yaml:"variant_name,omitempty"`
+	VariantName string `yaml:"variant_name,omitempty" json:"variant_name,omitempty"`
 }
 
 // ChipModelConfig defines configuration for a specific chip model.
@@ -53,57 +55,63 @@ type ChipModelConfig struct {
 	// ConfigKey is the unique identifier used in runtime configuration
 	// This key maps to runtime images and deployment settings
 	// Example: "ascend-910b", "ascend-310p"
-	ConfigKey string `yaml:"config_key"`
+	ConfigKey string `yaml:"config_key" json:"config_key"`
 	
 	// ModelName is the human-readable chip model name
 	// Example: "Ascend 910B", "Ascend 310P"
-	ModelName string `yaml:"model_name"`
+	ModelName string `yaml:"model_name" json:"model_name"`
 	
 	// DeviceID is the PCIe device identifier (16-bit hex value)
 	// Example: "0xd802" for Ascend 910B
-	DeviceID string `yaml:"device_id"`
+	DeviceID string `yaml:"device_id" json:"device_id"`
 	
 	// SubsystemDeviceID is the PCIe subsystem device identifier (16-bit hex value, optional)
 	// DEPRECATED: Use Variants instead for chip sub-versions
 	// When empty, matching is based on VendorID and DeviceID only
-	SubsystemDeviceID string `yaml:"subsystem_device_id,omitempty"`
+	SubsystemDeviceID string `yaml:"subsystem_device_id,omitempty" json:"subsystem_device_id,omitempty"`
 	
 	// Variants defines a list of chip sub-versions with different subsystem_device_id
 	// Used to distinguish between models like 910B1, 910B2, etc.
 	// If hardware matches a variant, the variant's config_key is used
 	// All variants share the same runtime_images from base model config
 	// If no variant matches, the base model config is used as fallback
-	Variants []ChipVariant `yaml:"variants,omitempty"`
+	Variants []ChipVariant `yaml:"variants,omitempty" json:"variants,omitempty"`
 	
 	// Generation groups related chip models (optional)
 	// Example: "Ascend 9xx", "Ascend 3xx"
-	Generation string `yaml:"generation,omitempty"`
+	Generation string `yaml:"generation,omitempty" json:"generation,omitempty"`
 	
 	// Capabilities lists supported features and precision modes
 	// Example: ["int8", "fp16", "inference"]
-	Capabilities []string `yaml:"capabilities,omitempty"`
+	Capabilities []string `yaml:"capabilities,omitempty" json:"capabilities,omitempty"`
 	
 	// ChipsPerDevice specifies how many AI chips are on each physical PCI device
 	// Default: 1 (single-chip card)
 	// Example: 2 for dual-chip cards like Ascend 910B Duo
 	// This allows proper device enumeration where one PCI device contains multiple inference cores
-	ChipsPerDevice int `yaml:"chips_per_device,omitempty"`
+	ChipsPerDevice int `yaml:"chips_per_device,omitempty" json:"chips_per_device,omitempty"`
+	
+	// DeviceCount is the expected number of physical PCI devices in a host
+	// Default: 16 (defaultDeviceCount)
+	// Together with ChipsPerDevice it bounds the logical chip indices that
+	// topology boxes may reference
+	DeviceCount int `yaml:"device_count,omitempty" json:"device_count,omitempty"`
 	
 	// Topology defines the physical topology for this chip model
 	// Used for topology-aware allocation specific to this chip type
-	Topology *TopologyConfig `yaml:"topology,omitempty"`
+	Topology *TopologyConfig `yaml:"topology,omitempty" json:"topology,omitempty"`
 	
 	// RuntimeImages maps inference engines to their Docker images by architecture
 	// Structure: engine_name -> architecture -> image_url
 	// Example: {"vllm": {"arm64": "quay.io/...", "amd64": "..."}}
 	// All variants of this chip model share the same runtime images
-	RuntimeImages map[string]map[string]string `yaml:"runtime_images,omitempty"`
+	RuntimeImages map[string]map[string]string `yaml:"runtime_images,omitempty" json:"runtime_images,omitempty"`
 	
 	// ExtSandboxes defines configuration-based sandboxes for this chip model (optional)
 	// Contains both common configuration and engine-specific configs
 	// Common fields (devices, volumes, runtime) are shared by all engines
 	// Engine configs are merged with common settings
-	ExtSandboxes *ExtSandboxesConfig `yaml:"ext_sandboxes,omitempty"`
+	ExtSandboxes *ExtSandboxesConfig `yaml:"ext_sandboxes,omitempty" json:"ext_sandboxes,omitempty"`
 }
 
 // ChipVendorConfig defines configuration for a chip vendor.
@@ -113,14 +121,14 @@ type ChipModelConfig struct {
 type ChipVendorConfig struct {
 	// VendorName is the company/organization name
 	// Example: "Huawei", "Baidu", "Cambricon"
-	VendorName string `yaml:"vendor_name"`
+	VendorName string `yaml:"vendor_name" json:"vendor_name"`
 	
 	// VendorID is the PCIe vendor identifier (16-bit hex value)
 	// Example: "0x19e5" for Huawei
-	VendorID string `yaml:"vendor_id"`
+	VendorID string `yaml:"vendor_id" json:"vendor_id"`
 	
 	// ChipModels lists all chip models from this vendor
-	ChipModels []ChipModelConfig `yaml:"chip_models"`
+	ChipModels []ChipModelConfig `yaml:"chip_models" json:"chip_models"`
 }
 
 // TopologyBox represents a group of devices with high-speed interconnection.
@@ -132,7 +140,7 @@ type TopologyBox struct {
 	// Devices is a list of logical chip indices in this box
 	// Example: [0, 1, 2, 3] means logical chips 0-3 have high-speed interconnect
 	// Note: Use logical chip indices, NOT physical device indices
-	Devices []int `yaml:"devices"`
+	Devices []int `yaml:"devices" json:"devices"`
 }
 
 // TopologyConfig defines the physical topology of devices.
@@ -143,7 +151,7 @@ type TopologyConfig struct {
 	// Boxes is a list of device groups with high-speed interconnection
 	// Each box contains devices with zero intra-box distance
 	// Inter-box distance equals |box_index_a - box_index_b|
-	Boxes []TopologyBox `yaml:"boxes,omitempty"`
+	Boxes []TopologyBox `yaml:"boxes,omitempty" json:"boxes,omitempty"`
 }
 
 // DevicesConfig is the root configuration structure for device definitions.
@@ -153,11 +161,11 @@ type TopologyConfig struct {
 type DevicesConfig struct {
 	// Version specifies the configuration schema version
 	// Used for compatibility checking and migration
-	Version string `yaml:"version"`
+	Version string `yaml:"version" json:"version"`
 	
 	// Vendors contains all supported chip vendors and their models
 	// Each vendor's chip models can define their own topology configuration
-	Vendors []ChipVendorConfig `yaml:"vendors"`
+	Vendors []ChipVendorConfig `yaml:"vendors" json:"vendors"`
 }
 
 // DeviceConfigLoader handles loading and caching of device configurations.
@@ -207,7 +215,8 @@ func LoadDevicesConfig() (*DevicesConfig, error) {
 // with caching.
 //
 // Parameters:
-//   - configPath: Path to configuration file (empty string for default)
+//   - configPath: Path to configuration file (empty string to use the
+//     XW_DEVICES_CONFIG environment variable)
 //
 // Returns:
 //   - Pointer to loaded DevicesConfig
@@ -232,7 +241,10 @@ func LoadDevicesConfigFrom(configPath string) (*DevicesConfig, error) {
 	// Determine config file path
 	path := configPath
 	if path == "" {
-		return nil, fmt.Errorf("config path cannot be empty - device configuration must be loaded explicitly")
+		path = resolveConfigPath(EnvDevicesConfig, "")
+	}
+	if path == "" {
+		return nil, fmt.Errorf("config path cannot be empty - device configuration must be loaded explicitly or via %s", EnvDevicesConfig)
 	}
 	
 	// Check if file exists
@@ -246,10 +258,15 @@ func LoadDevicesConfigFrom(configPath string) (*DevicesConfig, error) {
 		return nil, fmt.Errorf("failed to read device config file %s: %w", path, err)
 	}
 	
-	// Parse YAML
+	// Parse YAML or JSON, depending on the file extension
 	var config DevicesConfig
-	if err := yaml.Unmarshal(data, &config); err != nil {
-		return nil, fmt.Errorf("failed to parse device config YAML: %w", err)
+	if err := unmarshalConfigFile(path, data, &config); err != nil {
+		return nil, fmt.Errorf("failed to parse device config %s: %w", path, err)
+	}
+	
+	// Upgrade older schema versions before validating
+	if err := migrateDevicesConfig(&config); err != nil {
+		return nil, err
 	}
 	
 	// Validate configuration
@@ -308,6 +325,10 @@ func ReloadDevicesConfig(configPath string) (*DevicesConfig, error) {
 	return LoadDevicesConfigFrom(configPath)
 }
 
+// defaultDeviceCount is the number of physical PCI devices assumed per host
+// when a chip model does not set device_count.
+const defaultDeviceCount = 16
+
 // validateDevicesConfig performs validation on the loaded configuration.
 //
 // Validation checks:
@@ -316,57 +337,117 @@ func ReloadDevicesConfig(configPath string) (*DevicesConfig, error) {
 //   - Each vendor has valid identifiers
 //   - Each chip model has required fields
 //   - No duplicate config keys
+//   - Topology box indices are valid logical chips and not repeated
+//
+// Every violation is collected, so a single pass reports all of them.
 //
 // Parameters:
 //   - config: Configuration to validate
 //
 // Returns:
 //   - nil if valid
-//   - Error describing validation failure
+//   - ValidationErrors listing every violation found
 func validateDevicesConfig(config *DevicesConfig) error {
+	var errs ValidationErrors
+	
 	if config.Version == "" {
-		return fmt.Errorf("configuration version is required")
+		errs.add("configuration version is required")
 	}
 	
 	if len(config.Vendors) == 0 {
-		return fmt.Errorf("at least one vendor must be defined")
+		errs.add("at least one vendor must be defined")
 	}
 	
 	// Track config keys to detect duplicates
 	configKeys := make(map[string]bool)
 	
 	for i, vendor := range config.Vendors {
-		if vendor.VendorName == "" {
-			return fmt.Errorf("vendor[%d]: vendor_name is required", i)
+		vendorName := vendor.VendorName
+		if vendorName == "" {
+			errs.add("vendor[%d]: vendor_name is required", i)
+			vendorName = fmt.Sprintf("[%d]", i)
 		}
 		
 		if vendor.VendorID == "" {
-			return fmt.Errorf("vendor %s: vendor_id is required", vendor.VendorName)
+			errs.add("vendor %s: vendor_id is required", vendorName)
 		}
 		
 		if len(vendor.ChipModels) == 0 {
-			return fmt.Errorf("vendor %s: at least one chip model must be defined", vendor.VendorName)
+			errs.add("vendor %s: at least one chip model must be defined", vendorName)
 		}
 		
 		for j, model := range vendor.ChipModels {
 			if model.ConfigKey == "" {
-				return fmt.Errorf("vendor %s, model[%d]: config_key is required", vendor.VendorName, j)
+				errs.add("vendor %s, model[%d]: config_key is required", vendorName, j)
+				continue
 			}
 			
 			if model.ModelName == "" {
-				return fmt.Errorf("vendor %s, model %s: model_name is required", vendor.VendorName, model.ConfigKey)
+				errs.add("vendor %s, model %s: model_name is required", vendorName, model.ConfigKey)
 			}
 			
 			// Check for duplicate config keys
 			if configKeys[model.ConfigKey] {
-				return fmt.Errorf("duplicate config_key: %s", model.ConfigKey)
+				errs.add("duplicate config_key: %s", model.ConfigKey)
 			}
 			configKeys[model.ConfigKey] = true
 			
 			if model.DeviceID == "" {
-				return fmt.Errorf("vendor %s, model %s: device_id is required", 
-					vendor.VendorName, model.ConfigKey)
+				errs.add("vendor %s, model %s: device_id is required", 
+					vendorName, model.ConfigKey)
+			}
+			
+			if err := validateTopology(&model); err != nil {
+				errs.add("vendor %s, model %s: %v", vendorName, model.ConfigKey, err)
+			}
+		}
+	}
+	
+	return errs.errOrNil()
+}
+
+// validateTopology checks that every topology box of a chip model references
+// logical chips in [0, device_count*chips_per_device) and that no chip is
+// placed in more than one box.
+//
+// Parameters:
+//   - model: Chip model whose topology is validated
+//
+// Returns:
+//   - nil if the model has no topology or the topology is valid
+//   - Error naming the offending chip index
+func validateTopology(model *ChipModelConfig) error {
+	if model.Topology == nil {
+		return nil
+	}
+	
+	if model.DeviceCount < 0 {
+		return fmt.Errorf("device_count must not be negative, got %d", model.DeviceCount)
+	}
+	if model.ChipsPerDevice < 0 {
+		return fmt.Errorf("chips_per_device must not be negative, got %d", model.ChipsPerDevice)
+	}
+	
+	deviceCount := model.DeviceCount
+	if deviceCount == 0 {
+		deviceCount = defaultDeviceCount
+	}
+	chipsPerDevice := model.ChipsPerDevice
+	if chipsPerDevice == 0 {
+		chipsPerDevice = 1
+	}
+	chips := deviceCount * chipsPerDevice
+	
+	seen := make(map[int]int) // chip index → box index
+	for b, box := range model.Topology.Boxes {
+		for _, idx := range box.Devices {
+			if idx < 0 || idx >= chips {
+				return fmt.Errorf("topology box %d references chip %d, valid range is [0, %d)", b, idx, chips)
+			}
+			if prev, dup := seen[idx]; dup {
+				return fmt.Errorf("topology chip %d appears in both box %d and box %d", idx, prev, b)
 			}
+			seen[idx] = b
 		}
 	}
 	
@@ -446,7 +527,7 @@ func FindChipModelByIdentifier(config *DevicesConfig, vendorID, deviceID, subsys
 	var fallbackModel *ChipModelConfig
 	
 	for i := range config.Vendors {
-		if config.Vendors[i].VendorID != vendorID {
+		if !PCIIDEqual(config.Vendors[i].VendorID, vendorID) {
 			continue
 		}
 		
@@ -455,7 +536,7 @@ func FindChipModelByIdentifier(config *DevicesConfig, vendorID, deviceID, subsys
 			model := &config.Vendors[i].ChipModels[j]
 			
 			// Device ID must match
-			if model.DeviceID != deviceID {
+			if !PCIIDEqual(model.DeviceID, deviceID) {
 				continue
 			}
 			
@@ -463,7 +544,7 @@ func FindChipModelByIdentifier(config *DevicesConfig, vendorID, deviceID, subsys
 			if len(model.Variants) > 0 && subsystemDeviceID != "" {
 				for k := range model.Variants {
 					variant := &model.Variants[k]
-					if variant.SubsystemDeviceID == subsystemDeviceID {
+					if PCIIDEqual(variant.SubsystemDeviceID, subsystemDeviceID) {
 						// Variant matched! Return base model + variant
 						return &config.Vendors[i], model, variant
 					}
@@ -478,7 +559,7 @@ func FindChipModelByIdentifier(config *DevicesConfig, vendorID, deviceID, subsys
 			
 			// Phase 2: Legacy exact match - config has subsystem_device_id (deprecated format)
 			if model.SubsystemDeviceID != "" {
-				if model.SubsystemDeviceID == subsystemDeviceID {
+				if PCIIDEqual(model.SubsystemDeviceID, subsystemDeviceID) {
 					// Exact match found! Return immediately (no variant)
 					return &config.Vendors[i], model, nil
 				}
@@ -499,6 +580,37 @@ func FindChipModelByIdentifier(config *DevicesConfig, vendorID, deviceID, subsys
 	return fallbackVendor, fallbackModel, nil
 }
 
+// NormalizePCIID converts a PCIe identifier to the canonical form used by
+// sysfs: lowercase, "0x"-prefixed, and zero-padded to 4 hex digits.
+//
+// Configuration files are written by hand, so IDs such as "0x1D94", "1d94",
+// or "0x370" must still match what detection reads from sysfs ("0x1d94",
+// "0x0370"). Values that are not valid hex are returned trimmed and
+// lowercased.
+//
+// Parameters:
+//   - id: PCIe identifier in any of the accepted forms
+//
+// Returns:
+//   - Canonical identifier (e.g., "0x0370")
+func NormalizePCIID(id string) string {
+	id = strings.ToLower(strings.TrimSpace(id))
+	value, err := strconv.ParseUint(strings.TrimPrefix(id, "0x"), 16, 16)
+	if err != nil {
+		return id
+	}
+	return fmt.Sprintf("0x%04x", value)
+}
+
+// PCIIDEqual reports whether two PCIe identifiers refer to the same ID,
+// ignoring case, "0x" prefix, and zero padding. Empty IDs never match.
+func PCIIDEqual(a, b string) bool {
+	if a == "" || b == "" {
+		return false
+	}
+	return NormalizePCIID(a) == NormalizePCIID(b)
+}
+
 // GetAllConfigKeys returns a list of all config keys defined in the configuration.
 //
 // Useful for validation and displaying available chip types.
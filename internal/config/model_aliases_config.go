@@ -0,0 +1,140 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/tsingmaoai/xw-cli/internal/logger"
+)
+
+const (
+	// ModelAliasesFileName is the name of the client-facing model alias file.
+	// It lives directly in the config directory (e.g., ~/.xw/aliases.yaml)
+	// because it is operator-specific rather than part of a config version.
+	ModelAliasesFileName = "aliases.yaml"
+
+	// ModelAliasAnyInstance is a special target meaning "any running instance".
+	// It is useful as the default when exactly one model is being served.
+	ModelAliasAnyInstance = "*"
+)
+
+// ModelAliasRule maps a client-facing model name to a local instance.
+//
+// Example:
+//
+//	- pattern: claude-3-5-sonnet-*
+//	  target: qwen3-32b
+type ModelAliasRule struct {
+	// Pattern is the model name requested by the client. A trailing "*"
+	// matches any suffix (e.g., "claude-*" matches "claude-3-5-sonnet-20241022").
+	Pattern string `yaml:"pattern"`
+
+	// Target is the alias (or model ID) of the local instance to route to,
+	// or "*" to route to any running instance.
+	Target string `yaml:"target"`
+}
+
+// ModelAliasesConfig is the root configuration for client-facing model aliases.
+//
+// Clients such as Claude Code hardcode model names that never match a local
+// instance. Rules are evaluated in order and the first match wins. When no
+// rule matches and no instance serves the requested name, Default is used.
+type ModelAliasesConfig struct {
+	// Aliases contains the ordered alias rules
+	Aliases []ModelAliasRule `yaml:"aliases"`
+
+	// Default is the fallback target for unknown model names (optional).
+	// Use "*" to route to whichever instance is running.
+	Default string `yaml:"default"`
+}
+
+// LoadModelAliasesConfigFrom loads model alias rules from a specific file.
+//
+// Parameters:
+//   - configPath: Path to the aliases.yaml file
+//
+// Returns:
+//   - Model aliases configuration
+//   - Error if file exists but cannot be parsed (returns empty config if file doesn't exist)
+func LoadModelAliasesConfigFrom(configPath string) (*ModelAliasesConfig, error) {
+	// If file doesn't exist, return empty config (this is optional)
+	if _, err := os.Stat(configPath); os.IsNotExist(err) {
+		logger.Debug("Model aliases config not found at %s, using empty config", configPath)
+		return &ModelAliasesConfig{}, nil
+	}
+
+	data, err := os.ReadFile(configPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read model aliases config: %w", err)
+	}
+
+	var cfg ModelAliasesConfig
+	if err := yaml.Unmarshal(data, &cfg); err != nil {
+		return nil, fmt.Errorf("failed to parse model aliases config: %w", err)
+	}
+
+	if err := validateModelAliasesConfig(&cfg); err != nil {
+		return nil, fmt.Errorf("invalid model aliases config: %w", err)
+	}
+
+	logger.Debug("Loaded model aliases config with %d rule(s)", len(cfg.Aliases))
+	return &cfg, nil
+}
+
+// validateModelAliasesConfig validates the model aliases configuration.
+func validateModelAliasesConfig(cfg *ModelAliasesConfig) error {
+	for i, rule := range cfg.Aliases {
+		if strings.TrimSpace(rule.Pattern) == "" {
+			return fmt.Errorf("aliases[%d]: pattern cannot be empty", i)
+		}
+		if strings.TrimSpace(rule.Target) == "" {
+			return fmt.Errorf("aliases[%d]: target cannot be empty", i)
+		}
+		if idx := strings.Index(rule.Pattern, "*"); idx >= 0 && idx != len(rule.Pattern)-1 {
+			return fmt.Errorf("aliases[%d]: wildcard is only supported as a suffix: %s", i, rule.Pattern)
+		}
+	}
+	return nil
+}
+
+// GetModelAliases returns the client-facing model alias rules, or nil if
+// none are loaded. The rules must not be modified; a reload replaces them.
+func (c *Config) GetModelAliases() *ModelAliasesConfig {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.modelAliases
+}
+
+// setModelAliases replaces the model alias rules.
+func (c *Config) setModelAliases(aliases *ModelAliasesConfig) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.modelAliases = aliases
+}
+
+// Resolve returns the target for the first rule matching the model name.
+// Matching is case-insensitive. The second return value reports whether
+// any rule matched; Default is not consulted here.
+func (c *ModelAliasesConfig) Resolve(modelName string) (string, bool) {
+	if c == nil {
+		return "", false
+	}
+
+	nameLower := strings.ToLower(modelName)
+	for _, rule := range c.Aliases {
+		pattern := strings.ToLower(rule.Pattern)
+		if prefix, ok := strings.CutSuffix(pattern, "*"); ok {
+			if strings.HasPrefix(nameLower, prefix) {
+				return rule.Target, true
+			}
+			continue
+		}
+		if nameLower == pattern {
+			return rule.Target, true
+		}
+	}
+	return "", false
+}
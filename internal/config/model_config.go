@@ -12,6 +12,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
 	"sync"
 
@@ -20,6 +21,11 @@ import (
 	"github.com/tsingmaoai/xw-cli/internal/logger"
 )
 
+// Model sources supported by ModelConfig.Source.
+const (
+	ModelSourceModelScope  = "modelscope"
+	ModelSourceHuggingFace = "huggingface"
+)
 
 // ModelConfig defines configuration for an AI model.
 //
@@ -29,20 +35,24 @@ type ModelConfig struct {
 	
 	// ModelID is the unique identifier for this model
 	// Convention: lowercase, hyphen-separated (e.g., "qwen2-7b")
-	ModelID string `yaml:"model_id"`
+	ModelID string `yaml:"model_id" json:"model_id"`
 	
 	// SourceID is the model ID on the source platform
 	// Examples: "qwen/Qwen2-7B" (ModelScope), "Qwen/Qwen2-7B" (HuggingFace)
-	SourceID string `yaml:"source_id"`
+	SourceID string `yaml:"source_id" json:"source_id"`
+	
+	// Source is the model hub the model is downloaded from
+	// Values: "modelscope" (default) or "huggingface"
+	Source string `yaml:"source,omitempty" json:"source,omitempty"`
 	
 	// Model specifications
 	
 	// Parameters is the model size in billions of parameters
 	// Example: 7.0 for 7B model
-	Parameters float64 `yaml:"parameters,omitempty"`
+	Parameters float64 `yaml:"parameters,omitempty" json:"parameters,omitempty"`
 	
 	// ContextLength is the maximum context window size in tokens
-	ContextLength int `yaml:"context_length,omitempty"`
+	ContextLength int `yaml:"context_length,omitempty" json:"context_length,omitempty"`
 	
 	// Deployment configuration
 	
@@ -53,14 +63,20 @@ type ModelConfig struct {
 	//   ascend-910b:
 	//     - vllm:docker
 	//     - mindie:docker
-	SupportedDevices map[string][]string `yaml:"supported_devices"`
+	SupportedDevices map[string][]string `yaml:"supported_devices" json:"supported_devices"`
 	
 	// Tag specifies the model variant (e.g., "main", "int8", "fp16")
-	Tag string `yaml:"tag,omitempty"`
+	Tag string `yaml:"tag,omitempty" json:"tag,omitempty"`
 	
 	// Capabilities lists the model's supported features
 	// Common values: "completion", "vision", "tool_use", "function_calling"
-	Capabilities []string `yaml:"capabilities,omitempty"`
+	// With "vision", images returned by tools are passed to the model by the
+	// Anthropic proxy; without it they are replaced by a text placeholder.
+	// With "tool_messages", tool results are sent as OpenAI tool messages
+	// (tool_call_id set); without it they are flattened into user text.
+	// It is opt-in: set it only if the engine's chat template renders tool
+	// messages, which "tool_use" does not imply.
+	Capabilities []string `yaml:"capabilities,omitempty" json:"capabilities,omitempty"`
 }
 
 // ModelsConfig is the root configuration structure for model definitions.
@@ -70,14 +86,14 @@ type ModelConfig struct {
 type ModelsConfig struct {
 	// Version specifies the configuration schema version
 	// Used for compatibility checking and migration
-	Version string `yaml:"version"`
+	Version string `yaml:"version" json:"version"`
 	
 	// Models contains all available model configurations
-	Models []ModelConfig `yaml:"models"`
+	Models []ModelConfig `yaml:"models" json:"models"`
 	
 	// ModelGroups provides logical grouping of related models (optional)
 	// Example: {"qwen2": ["qwen2-0.5b", "qwen2-7b", "qwen2-72b"]}
-	ModelGroups map[string][]string `yaml:"model_groups,omitempty"`
+	ModelGroups map[string][]string `yaml:"model_groups,omitempty" json:"model_groups,omitempty"`
 }
 
 // ModelConfigLoader handles loading and caching of model configurations.
@@ -107,7 +123,8 @@ var (
 // cached configuration without re-reading the file.
 //
 // Parameters:
-//   - configPath: Path to configuration file (must not be empty)
+//   - configPath: Path to configuration file (empty string to use the
+//     XW_MODELS_CONFIG environment variable)
 //
 // Returns:
 //   - Pointer to loaded ModelsConfig
@@ -132,10 +149,13 @@ func LoadModelsConfig(configPath string) (*ModelsConfig, error) {
 		return modelConfigLoader.config, nil
 	}
 	
-	// Require explicit config path
+	// Require explicit config path or environment override
 	path := configPath
 	if path == "" {
-		return nil, fmt.Errorf("config path cannot be empty - model configuration must be loaded explicitly")
+		path = resolveConfigPath(EnvModelsConfig, "")
+	}
+	if path == "" {
+		return nil, fmt.Errorf("config path cannot be empty - model configuration must be loaded explicitly or via %s", EnvModelsConfig)
 	}
 	
 	// Check if file exists
@@ -149,10 +169,15 @@ func LoadModelsConfig(configPath string) (*ModelsConfig, error) {
 		return nil, fmt.Errorf("failed to read model config file %s: %w", path, err)
 	}
 	
-	// Parse YAML
+	// Parse YAML or JSON, depending on the file extension
 	var config ModelsConfig
-	if err := yaml.Unmarshal(data, &config); err != nil {
-		return nil, fmt.Errorf("failed to parse model config YAML: %w", err)
+	if err := unmarshalConfigFile(path, data, &config); err != nil {
+		return nil, fmt.Errorf("failed to parse model config %s: %w", path, err)
+	}
+	
+	// Upgrade older schema versions before validating
+	if err := migrateModelsConfig(&config); err != nil {
+		return nil, err
 	}
 	
 	// Validate configuration
@@ -227,20 +252,26 @@ func ReloadModelsConfig(configPath string) (*ModelsConfig, error) {
 //   - No duplicate model IDs
 //   - Runtime configs are valid
 //   - Hardware requirements are reasonable
+//   - Model group members reference defined models (a group without
+//     members is allowed; ValidateConfigFiles warns about it)
+//
+// Every violation is collected, so a single pass reports all of them.
 //
 // Parameters:
 //   - config: Configuration to validate
 //
 // Returns:
 //   - nil if valid
-//   - Error describing validation failure
+//   - ValidationErrors listing every violation found
 func validateModelsConfig(config *ModelsConfig) error {
+	var errs ValidationErrors
+	
 	if config.Version == "" {
-		return fmt.Errorf("configuration version is required")
+		errs.add("configuration version is required")
 	}
 	
 	if len(config.Models) == 0 {
-		return fmt.Errorf("at least one model must be defined")
+		errs.add("at least one model must be defined")
 	}
 	
 	// Track model IDs to detect duplicates
@@ -248,45 +279,74 @@ func validateModelsConfig(config *ModelsConfig) error {
 	
 	for i, model := range config.Models {
 		if model.ModelID == "" {
-			return fmt.Errorf("model[%d]: model_id is required", i)
+			errs.add("model[%d]: model_id is required", i)
+			continue
 		}
 		
 		// Check for duplicate model IDs
 		if modelIDs[model.ModelID] {
-			return fmt.Errorf("duplicate model_id: %s", model.ModelID)
+			errs.add("duplicate model_id: %s", model.ModelID)
 		}
 		modelIDs[model.ModelID] = true
 		
 		// Validate source ID
 		if model.SourceID == "" {
-			return fmt.Errorf("model %s: source_id is required", model.ModelID)
+			errs.add("model %s: source_id is required", model.ModelID)
+		}
+		
+		// Validate source
+		switch model.Source {
+		case "", ModelSourceModelScope, ModelSourceHuggingFace:
+		default:
+			errs.add("model %s: invalid source '%s', expected '%s' or '%s'",
+				model.ModelID, model.Source, ModelSourceModelScope, ModelSourceHuggingFace)
 		}
 		
 		// Validate supported devices
 		if len(model.SupportedDevices) == 0 {
-			return fmt.Errorf("model %s: at least one supported device is required", model.ModelID)
+			errs.add("model %s: at least one supported device is required", model.ModelID)
 		}
 		
-		// Validate each device's engines
-		for device, engines := range model.SupportedDevices {
+		// Validate each device's engines, in device order for a stable report
+		devices := make([]string, 0, len(model.SupportedDevices))
+		for device := range model.SupportedDevices {
+			devices = append(devices, device)
+		}
+		sort.Strings(devices)
+		for _, device := range devices {
+			engines := model.SupportedDevices[device]
 			if len(engines) == 0 {
-				return fmt.Errorf("model %s, device %s: at least one engine is required", model.ModelID, device)
+				errs.add("model %s, device %s: at least one engine is required", model.ModelID, device)
 			}
 			
 			for j, engine := range engines {
 				if engine == "" {
-					return fmt.Errorf("model %s, device %s, engine[%d]: engine string is required", model.ModelID, device, j)
+					errs.add("model %s, device %s, engine[%d]: engine string is required", model.ModelID, device, j)
+					continue
 				}
 				// Basic format validation (should be "backend:mode")
 				if !strings.Contains(engine, ":") {
-					return fmt.Errorf("model %s, device %s, engine[%d]: invalid format '%s', expected 'backend:mode' (e.g., 'vllm:docker')", 
+					errs.add("model %s, device %s, engine[%d]: invalid format '%s', expected 'backend:mode' (e.g., 'vllm:docker')", 
 						model.ModelID, device, j, engine)
 				}
 			}
 		}
 	}
 	
-	return nil
+	// Validate model groups
+	for _, name := range GetModelGroupNames(config) {
+		if strings.TrimSpace(name) == "" {
+			errs.add("model_groups: group name is required")
+			continue
+		}
+		for j, member := range config.ModelGroups[name] {
+			if !modelIDs[member] {
+				errs.add("model group %s, member[%d]: unknown model_id '%s'", name, j, member)
+			}
+		}
+	}
+	
+	return errs.errOrNil()
 }
 
 // FindModelByID searches for a model by its ID.
@@ -331,6 +391,23 @@ func FindModelsByDeviceType(config *ModelsConfig, deviceConfigKey string) []*Mod
 }
 
 
+// GetModelGroupNames returns the names of the model groups defined in the
+// configuration, sorted alphabetically.
+//
+// Parameters:
+//   - config: ModelsConfig to extract group names from
+//
+// Returns:
+//   - Sorted slice of group names
+func GetModelGroupNames(config *ModelsConfig) []string {
+	names := make([]string, 0, len(config.ModelGroups))
+	for name := range config.ModelGroups {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
 // GetAllModelIDs returns a list of all model IDs defined in the configuration.
 //
 // Useful for validation and displaying available models.
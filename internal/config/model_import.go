@@ -0,0 +1,57 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// EnvModelImportDirs lists directories, separated like PATH, whose local
+// model checkpoints may be served through a Modelfile FROM path, in
+// addition to the models directory.
+const EnvModelImportDirs = "XW_MODEL_IMPORT_DIRS"
+
+// GetModelImportDirs returns the directories a Modelfile FROM path may
+// point into: the models directory followed by the directories listed in
+// XW_MODEL_IMPORT_DIRS. Relative entries are ignored.
+func (c *Config) GetModelImportDirs() []string {
+	dirs := []string{c.Storage.GetModelsDir()}
+	for _, dir := range filepath.SplitList(os.Getenv(EnvModelImportDirs)) {
+		dir = strings.TrimSpace(dir)
+		if dir != "" && filepath.IsAbs(dir) {
+			dirs = append(dirs, filepath.Clean(dir))
+		}
+	}
+	return dirs
+}
+
+// CheckModelImportPath checks that a local model path lies within one of
+// the model import directories (see GetModelImportDirs). Symbolic links are
+// resolved first, so a link cannot lead out of an import directory.
+//
+// Parameters:
+//   - path: Absolute path of a model directory
+//
+// Returns:
+//   - Error naming the allowed directories if path lies outside all of them
+func (c *Config) CheckModelImportPath(path string) error {
+	resolved, err := filepath.EvalSymlinks(path)
+	if err != nil {
+		return err
+	}
+
+	dirs := c.GetModelImportDirs()
+	for _, dir := range dirs {
+		root, err := filepath.EvalSymlinks(dir)
+		if err != nil {
+			continue
+		}
+		rel, err := filepath.Rel(root, resolved)
+		if err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			return nil
+		}
+	}
+	return fmt.Errorf("%s is outside the model import directories (%s); add its parent to %s on the server",
+		path, strings.Join(dirs, ", "), EnvModelImportDirs)
+}
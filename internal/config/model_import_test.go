@@ -0,0 +1,54 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestCheckModelImportPath(t *testing.T) {
+	dataDir := t.TempDir()
+	importDir := t.TempDir()
+	outsideDir := t.TempDir()
+
+	c := &Config{Storage: StorageConfig{DataDir: dataDir}}
+	modelsDir := c.Storage.GetModelsDir()
+	for _, dir := range []string{
+		filepath.Join(modelsDir, "qwen3-8b", "latest"),
+		filepath.Join(importDir, "finetune"),
+		filepath.Join(outsideDir, "secret"),
+	} {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// A link inside an import directory must not lead out of it
+	escape := filepath.Join(importDir, "escape")
+	if err := os.Symlink(filepath.Join(outsideDir, "secret"), escape); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv(EnvModelImportDirs, importDir+string(filepath.ListSeparator)+"relative/dir")
+
+	tests := []struct {
+		path    string
+		allowed bool
+	}{
+		{filepath.Join(modelsDir, "qwen3-8b", "latest"), true},
+		{filepath.Join(importDir, "finetune"), true},
+		{filepath.Join(importDir, "finetune", "..", "finetune"), true},
+		{filepath.Join(outsideDir, "secret"), false},
+		{filepath.Join(importDir, "..", filepath.Base(outsideDir), "secret"), false},
+		{escape, false},
+		{filepath.Join(importDir, "missing"), false},
+	}
+	for _, tt := range tests {
+		err := c.CheckModelImportPath(tt.path)
+		if (err == nil) != tt.allowed {
+			t.Errorf("CheckModelImportPath(%s) = %v, want allowed %v", tt.path, err, tt.allowed)
+		}
+	}
+
+	if dirs := c.GetModelImportDirs(); len(dirs) != 2 || dirs[0] != modelsDir || dirs[1] != importDir {
+		t.Errorf("GetModelImportDirs() = %v, want [%s %s]", dirs, modelsDir, importDir)
+	}
+}
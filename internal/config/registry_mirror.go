@@ -0,0 +1,126 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+// EnvRegistryMirror overrides the registry_mirror setting from server.conf.
+const EnvRegistryMirror = "XW_REGISTRY_MIRROR"
+
+// defaultImageRegistry is the registry Docker uses for unqualified images.
+const defaultImageRegistry = "docker.io"
+
+// GetRegistryMirror returns the effective registry mirror specification.
+//
+// The XW_REGISTRY_MIRROR environment variable takes precedence over the
+// registry_mirror value in server.conf. An empty result means no mirror.
+func (c *Config) GetRegistryMirror() string {
+	if v := strings.TrimSpace(os.Getenv(EnvRegistryMirror)); v != "" {
+		return v
+	}
+	return c.Server.RegistryMirror
+}
+
+// ValidateRegistryMirror checks a registry mirror specification.
+//
+// The specification is a comma-separated list of entries. Each entry is
+// either "registry=prefix", which mirrors a single registry, or a bare
+// "prefix", which mirrors every registry without an explicit entry:
+//
+//	quay.io=quay.mirror.example.com,docker.io=hub.mirror.example.com
+//	mirror.example.com/proxy
+func ValidateRegistryMirror(spec string) error {
+	_, _, err := parseRegistryMirror(spec)
+	return err
+}
+
+// RewriteImageRegistry replaces the registry host of imageName according to
+// the mirror specification (see ValidateRegistryMirror).
+//
+// Images from registries without a matching entry, and all images when the
+// specification is empty or invalid, are returned unchanged.
+//
+// Example:
+//
+//	RewriteImageRegistry("quay.io/ascend/vllm-ascend:v0.11.0rc0", "quay.io=quay.m.daocloud.io")
+//	// Returns: "quay.m.daocloud.io/ascend/vllm-ascend:v0.11.0rc0"
+func RewriteImageRegistry(imageName, spec string) string {
+	mirrors, fallback, err := parseRegistryMirror(spec)
+	if err != nil || (len(mirrors) == 0 && fallback == "") {
+		return imageName
+	}
+
+	registry, path := splitImageRegistry(imageName)
+	prefix, ok := mirrors[registry]
+	if !ok {
+		prefix = fallback
+	}
+	if prefix == "" {
+		return imageName
+	}
+	return prefix + "/" + path
+}
+
+// parseRegistryMirror parses a mirror specification into per-registry
+// prefixes and an optional fallback prefix for all other registries.
+func parseRegistryMirror(spec string) (map[string]string, string, error) {
+	mirrors := make(map[string]string)
+	fallback := ""
+
+	for _, entry := range strings.Split(spec, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		registry, prefix, hasRegistry := strings.Cut(entry, "=")
+		if !hasRegistry {
+			registry, prefix = "", registry
+		}
+		registry = strings.TrimSpace(registry)
+		prefix = strings.TrimSuffix(strings.TrimSpace(prefix), "/")
+		prefix = strings.TrimPrefix(strings.TrimPrefix(prefix, "https://"), "http://")
+
+		if prefix == "" || strings.ContainsAny(prefix, " \t") {
+			return nil, "", fmt.Errorf("invalid registry mirror entry %q", entry)
+		}
+
+		if !hasRegistry {
+			if fallback != "" {
+				return nil, "", fmt.Errorf("multiple default registry mirrors: %s and %s", fallback, prefix)
+			}
+			fallback = prefix
+			continue
+		}
+		if registry == "" {
+			return nil, "", fmt.Errorf("invalid registry mirror entry %q: missing registry", entry)
+		}
+		mirrors[registry] = prefix
+	}
+
+	return mirrors, fallback, nil
+}
+
+// ImageRegistry returns the registry host of an image reference
+// (e.g., "quay.io" for "quay.io/ascend/vllm-ascend:v1", "docker.io" for
+// "ubuntu:22.04").
+func ImageRegistry(imageName string) string {
+	registry, _ := splitImageRegistry(imageName)
+	return registry
+}
+
+// splitImageRegistry splits an image reference into its registry host and
+// the remaining repository path. Unqualified images belong to docker.io,
+// with single-component names under "library/".
+func splitImageRegistry(imageName string) (string, string) {
+	first, rest, found := strings.Cut(imageName, "/")
+	if found && (strings.ContainsAny(first, ".:") || first == "localhost") {
+		return first, rest
+	}
+	if !found {
+		return defaultImageRegistry, "library/" + imageName
+	}
+	return defaultImageRegistry, imageName
+}
@@ -3,6 +3,8 @@ package config
 import (
 	"fmt"
 	"runtime"
+	"sort"
+	"strings"
 )
 
 // RuntimeImagesConfig represents the configuration for Docker images
@@ -117,13 +119,127 @@ func GetImageForChipAndEngine(config RuntimeImagesConfig, chipModel, engine, arc
 	}
 	
 	image, ok := archMap[arch]
-	if !ok {
-		return "", fmt.Errorf("architecture %s not found for chip model %s and engine %s", arch, chipModel, engine)
+	if !ok || IsUnsetImage(image) {
+		return "", fmt.Errorf("no %s image configured for %s on %s; add runtime_images.%s.%s to devices.yaml",
+			engine, arch, chipModel, engine, arch)
 	}
 	
 	return image, nil
 }
 
+// IsUnsetImage reports whether a runtime image entry means "no image".
+//
+// devices.yaml uses the NONE placeholder for architectures a chip model has
+// no image for; an empty value is treated the same way.
+func IsUnsetImage(image string) bool {
+	image = strings.TrimSpace(image)
+	return image == "" || strings.EqualFold(image, "none")
+}
+
+// ArchImageWarning reports a chip model that cannot serve models with
+// Docker engines on an architecture because no image is configured for it.
+//
+// Without an image the engine would otherwise fail only when the container
+// is created, with an invalid reference such as "library/NONE". The warning
+// names the devices.yaml entry to add.
+//
+// Parameters:
+//   - config: RuntimeImagesConfig to query
+//   - chipModel: Base chip config key (e.g., "ascend-310p")
+//   - engine: Engine to check (e.g., "vllm"), or empty to warn only if no
+//     engine has an image for arch
+//   - arch: Image architecture ("arm64" or "amd64")
+//
+// Returns:
+//   - Warning message, or empty string if an image is configured
+//
+// Example:
+//   ArchImageWarning(config, "ascend-310p", "", "amd64")
+//   // "ascend-310p has no amd64 image configured; models will fail to start (add
+//   //  runtime_images.vllm.amd64 under vendors[].chip_models[config_key=ascend-310p] in devices.yaml)"
+func ArchImageWarning(config RuntimeImagesConfig, chipModel, engine, arch string) string {
+	engineMap := config[chipModel]
+
+	engines := []string{engine}
+	if engine == "" {
+		engines = make([]string, 0, len(engineMap))
+		for name, archMap := range engineMap {
+			if !IsUnsetImage(archMap[arch]) {
+				return ""
+			}
+			engines = append(engines, name)
+		}
+		sort.Strings(engines)
+		if len(engines) == 0 {
+			engines = []string{"<engine>"}
+		}
+	} else if !IsUnsetImage(engineMap[engine][arch]) {
+		return ""
+	}
+
+	paths := make([]string, len(engines))
+	for i, name := range engines {
+		paths[i] = fmt.Sprintf("runtime_images.%s.%s", name, arch)
+	}
+	subject := chipModel
+	if engine != "" {
+		subject = fmt.Sprintf("%s (%s)", chipModel, engine)
+	}
+	return fmt.Sprintf("%s has no %s image configured; models will fail to start "+
+		"(add %s under vendors[].chip_models[config_key=%s] in devices.yaml)",
+		subject, arch, strings.Join(paths, " or "), chipModel)
+}
+
+// SplitImageDigest splits a pinned image reference into the image name and
+// the expected content digest.
+//
+// Runtime images may be pinned to a digest with the standard Docker syntax,
+// "name[:tag]@sha256:...", so that every host runs the identical build even
+// if the tag has moved or a stale image is cached locally.
+//
+// Parameters:
+//   - image: Image reference (e.g., "quay.io/ascend/vllm-ascend:v0.11.0@sha256:ab12...")
+//
+// Returns:
+//   - Image name without the digest (e.g., "quay.io/ascend/vllm-ascend:v0.11.0")
+//   - Digest (e.g., "sha256:ab12..."), or empty string if the image is not pinned
+func SplitImageDigest(image string) (string, string) {
+	name, digest, found := strings.Cut(strings.TrimSpace(image), "@")
+	if !found {
+		return image, ""
+	}
+	return name, digest
+}
+
+// ImageRepository returns an image name without its tag
+// (e.g., "registry:5000/vllm:v1" → "registry:5000/vllm").
+func ImageRepository(name string) string {
+	// A tag is a colon after the last slash (a colon before it is a registry port)
+	slash := strings.LastIndex(name, "/")
+	if colon := strings.LastIndex(name, ":"); colon > slash {
+		return name[:colon]
+	}
+	return name
+}
+
+// PinnedImageReference returns the reference that pulls a pinned image by
+// digest ("repository@sha256:...").
+func PinnedImageReference(name, digest string) string {
+	return ImageRepository(name) + "@" + digest
+}
+
+// HasImageDigest reports whether a local image's RepoDigests (as reported
+// by docker image inspect, e.g. "quay.io/ascend/vllm-ascend@sha256:...")
+// include the expected digest.
+func HasImageDigest(repoDigests []string, digest string) bool {
+	for _, repoDigest := range repoDigests {
+		if _, d, ok := strings.Cut(repoDigest, "@"); ok && d == digest {
+			return true
+		}
+	}
+	return false
+}
+
 // GetImageForChipAndEngineAuto automatically detects the system architecture
 // and returns the appropriate Docker image.
 //
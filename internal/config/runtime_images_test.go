@@ -0,0 +1,137 @@
+package config
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestArchImageWarning(t *testing.T) {
+	images := RuntimeImagesConfig{
+		"ascend-910b": {
+			"vllm":   {"arm64": "quay.io/ascend/vllm-ascend:v0.11.0rc0-arm64", "amd64": "quay.io/ascend/vllm-ascend:v0.11.0rc0-amd64"},
+			"mindie": {"arm64": "harbor.tsingmao.com/xuanwu/mindie:2.2.RC1-arm64", "amd64": "NONE"},
+		},
+		"ascend-310p": {
+			"vllm":   {"arm64": "quay.io/ascend/vllm-ascend:v0.11.0rc0-310p-arm64", "amd64": "none"},
+			"mindie": {"arm64": "harbor.tsingmao.com/xuanwu/mindie:2.2.RC1-300I-arm64", "amd64": " "},
+		},
+		"ascend-950": {},
+	}
+
+	tests := []struct {
+		name      string
+		chipModel string
+		engine    string
+		arch      string
+		want      []string // substrings of the warning; nil for no warning
+	}{
+		{
+			name:      "engine with an image",
+			chipModel: "ascend-910b", engine: "vllm", arch: "amd64",
+		},
+		{
+			name:      "engine with a none image",
+			chipModel: "ascend-910b", engine: "mindie", arch: "amd64",
+			want: []string{"ascend-910b (mindie) has no amd64 image", "add runtime_images.mindie.amd64 under", "config_key=ascend-910b"},
+		},
+		{
+			name:      "engine missing from the chip model",
+			chipModel: "ascend-910b", engine: "sglang", arch: "arm64",
+			want: []string{"ascend-910b (sglang) has no arm64 image", "runtime_images.sglang.arm64"},
+		},
+		{
+			name:      "any engine with one image",
+			chipModel: "ascend-910b", arch: "amd64",
+		},
+		{
+			name:      "any engine without images",
+			chipModel: "ascend-310p", arch: "amd64",
+			want: []string{"ascend-310p has no amd64 image", "add runtime_images.mindie.amd64 or runtime_images.vllm.amd64 under"},
+		},
+		{
+			name:      "empty engine map",
+			chipModel: "ascend-950", arch: "arm64",
+			want: []string{"ascend-950 has no arm64 image", "runtime_images.<engine>.arm64"},
+		},
+		{
+			name:      "unknown chip model",
+			chipModel: "ascend-910c", engine: "vllm", arch: "arm64",
+			want: []string{"ascend-910c (vllm) has no arm64 image"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ArchImageWarning(images, tt.chipModel, tt.engine, tt.arch)
+			if tt.want == nil {
+				if got != "" {
+					t.Errorf("ArchImageWarning() = %q, want no warning", got)
+				}
+				return
+			}
+			for _, want := range tt.want {
+				if !strings.Contains(got, want) {
+					t.Errorf("ArchImageWarning() = %q, want it to contain %q", got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestImageDigestReferences(t *testing.T) {
+	const digest = "sha256:ab12cd34"
+
+	tests := []struct {
+		image      string
+		wantName   string
+		wantDigest string
+		wantRepo   string
+	}{
+		{"quay.io/ascend/vllm-ascend:v0.11.0", "quay.io/ascend/vllm-ascend:v0.11.0", "", "quay.io/ascend/vllm-ascend"},
+		{"quay.io/ascend/vllm-ascend:v0.11.0@" + digest, "quay.io/ascend/vllm-ascend:v0.11.0", digest, "quay.io/ascend/vllm-ascend"},
+		{"vllm-ascend@" + digest, "vllm-ascend", digest, "vllm-ascend"},
+		{"host:5000/img@" + digest, "host:5000/img", digest, "host:5000/img"},
+		{"host:5000/img:v1@" + digest, "host:5000/img:v1", digest, "host:5000/img"},
+		{"host:5000/img", "host:5000/img", "", "host:5000/img"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.image, func(t *testing.T) {
+			name, gotDigest := SplitImageDigest(tt.image)
+			if name != tt.wantName || gotDigest != tt.wantDigest {
+				t.Errorf("SplitImageDigest() = %q, %q, want %q, %q", name, gotDigest, tt.wantName, tt.wantDigest)
+			}
+			if repo := ImageRepository(name); repo != tt.wantRepo {
+				t.Errorf("ImageRepository(%q) = %q, want %q", name, repo, tt.wantRepo)
+			}
+			if gotDigest == "" {
+				return
+			}
+			if ref := PinnedImageReference(name, gotDigest); ref != tt.wantRepo+"@"+digest {
+				t.Errorf("PinnedImageReference() = %q, want %q", ref, tt.wantRepo+"@"+digest)
+			}
+		})
+	}
+}
+
+func TestHasImageDigest(t *testing.T) {
+	const digest = "sha256:ab12cd34"
+
+	tests := []struct {
+		name        string
+		repoDigests []string
+		want        bool
+	}{
+		{"matching digest", []string{"quay.io/ascend/vllm-ascend@" + digest}, true},
+		{"registry with a port", []string{"host:5000/img@sha256:ffff", "host:5000/img@" + digest}, true},
+		{"other digest", []string{"host:5000/img@sha256:ffff"}, false},
+		{"digest prefix", []string{"host:5000/img@sha256:ab12"}, false},
+		{"no digest", []string{"host:5000/img"}, false},
+		{"no repo digests", nil, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := HasImageDigest(tt.repoDigests, digest); got != tt.want {
+				t.Errorf("HasImageDigest(%v) = %v, want %v", tt.repoDigests, got, tt.want)
+			}
+		})
+	}
+}
@@ -9,7 +9,10 @@
 // chip model's ext_sandboxes field.
 package config
 
-import "fmt"
+import (
+	"encoding/json"
+	"fmt"
+)
 
 // ExtSandboxesConfig contains both common configuration and engine-specific configs.
 //
@@ -35,13 +38,13 @@ import "fmt"
 //	    privileged: true
 type ExtSandboxesConfig struct {
 	// Common configuration shared by all engines
-	Devices []string `yaml:"devices,omitempty"`
-	Volumes []string `yaml:"volumes,omitempty"`
-	Runtime string   `yaml:"runtime,omitempty"`
+	Devices []string `yaml:"devices,omitempty" json:"devices,omitempty"`
+	Volumes []string `yaml:"volumes,omitempty" json:"volumes,omitempty"`
+	Runtime string   `yaml:"runtime,omitempty" json:"runtime,omitempty"`
 	
 	// Engine-specific configurations  
 	// Populated during YAML unmarshaling with all non-common keys
-	Engines map[string]*ExtSandboxConfig `yaml:"-"`
+	Engines map[string]*ExtSandboxConfig `yaml:"-" json:"-"`
 }
 
 // UnmarshalYAML implements custom YAML unmarshaling for ExtSandboxesConfig.
@@ -53,6 +56,23 @@ func (c *ExtSandboxesConfig) UnmarshalYAML(unmarshal func(interface{}) error) er
 		return err
 	}
 	
+	return c.fromRaw(raw)
+}
+
+// UnmarshalJSON implements custom JSON unmarshaling for ExtSandboxesConfig.
+// It applies the same common/engine split as UnmarshalYAML.
+func (c *ExtSandboxesConfig) UnmarshalJSON(data []byte) error {
+	var raw map[string]interface{}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	
+	return c.fromRaw(raw)
+}
+
+// fromRaw populates the config from a generic decoded map, separating the
+// common fields from engine-specific configs.
+func (c *ExtSandboxesConfig) fromRaw(raw map[string]interface{}) error {
 	// Initialize engines map
 	c.Engines = make(map[string]*ExtSandboxConfig)
 	
@@ -127,6 +147,11 @@ func remarshalYAML(from interface{}, to interface{}) error {
 			if cfg, ok := to.(*ExtSandboxConfig); ok {
 				cfg.ShmSizeGB = shm
 			}
+		} else if shm, ok := m["shm_size_gb"].(float64); ok {
+			// JSON numbers decode as float64
+			if cfg, ok := to.(*ExtSandboxConfig); ok {
+				cfg.ShmSizeGB = int(shm)
+			}
 		}
 		if runtime, ok := m["runtime"].(string); ok {
 			if cfg, ok := to.(*ExtSandboxConfig); ok {
@@ -229,7 +254,7 @@ type ExtSandboxConfig struct {
 	//   - NVIDIA GPU: CUDA_VISIBLE_DEVICES
 	//   - Kunlun XPU: XPU_VISIBLE_DEVICES
 	//   - Cambricon MLU: MLU_VISIBLE_DEVICES
-	DeviceEnv string `yaml:"device_env"`
+	DeviceEnv string `yaml:"device_env" json:"device_env"`
 
 	// Environment contains additional static environment variables to set
 	// in the container. These are set as-is without any template processing.
@@ -238,7 +263,7 @@ type ExtSandboxConfig struct {
 	//   environment:
 	//     LOG_LEVEL: "3"
 	//     WORKER_METHOD: spawn
-	Environment map[string]string `yaml:"environment"`
+	Environment map[string]string `yaml:"environment" json:"environment"`
 
 	// Devices lists all device node paths that may need to be mounted.
 	//
@@ -253,7 +278,7 @@ type ExtSandboxConfig struct {
 	//     - /dev/xpu0          # Mounted only when device 0 is allocated
 	//     - /dev/xpu1          # Mounted only when device 1 is allocated
 	//     - /dev/xpu_ctl       # Always mounted (shared control device)
-	Devices []string `yaml:"devices"`
+	Devices []string `yaml:"devices" json:"devices"`
 
 	// Volumes defines host-to-container volume mounts in "host:container" format.
 	// If no container path is specified (no colon), the same path is used for both.
@@ -262,18 +287,18 @@ type ExtSandboxConfig struct {
 	//   volumes:
 	//     - /usr/local/xpu:/usr/local/xpu
 	//     - /root/.cache:/root/.cache
-	Volumes []string `yaml:"volumes"`
+	Volumes []string `yaml:"volumes" json:"volumes"`
 
 	// Privileged indicates whether the container requires privileged mode.
 	//
 	// Privileged mode grants the container extended permissions, including
 	// access to all devices and the ability to modify system settings.
 	// While less secure, some accelerators require it for proper operation.
-	Privileged bool `yaml:"privileged"`
+	Privileged bool `yaml:"privileged" json:"privileged"`
 
 	// Runtime specifies the Docker runtime to use (e.g., "runc", "nvidia").
 	// Defaults to "runc" if not specified.
-	Runtime string `yaml:"runtime"`
+	Runtime string `yaml:"runtime" json:"runtime"`
 
 	// ShmSizeGB specifies the shared memory size in gigabytes.
 	// If not specified or zero, defaults to 16GB.
@@ -282,7 +307,7 @@ type ExtSandboxConfig struct {
 	//   - Inter-process communication in distributed inference
 	//   - PyTorch DataLoader workers
 	//   - Model tensor sharing
-	ShmSizeGB int `yaml:"shm_size_gb,omitempty"`
+	ShmSizeGB int `yaml:"shm_size_gb,omitempty" json:"shm_size_gb,omitempty"`
 
 	// Capabilities lists Linux capabilities required by the container.
 	//
@@ -291,7 +316,7 @@ type ExtSandboxConfig struct {
 	//   - SYS_RAWIO: Direct device I/O access
 	//   - IPC_LOCK: Memory locking for device buffers
 	//   - SYS_RESOURCE: Resource limit adjustments
-	Capabilities []string `yaml:"capabilities"`
+	Capabilities []string `yaml:"capabilities" json:"capabilities"`
 }
 
 // LoadExtSandboxesFromDevices extracts extended sandbox configurations from device config.
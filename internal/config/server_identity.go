@@ -3,9 +3,15 @@ package config
 import (
 	"crypto/rand"
 	"fmt"
+	"net"
+	"net/url"
 	"os"
 	"path/filepath"
+	"regexp"
+	"strconv"
 	"strings"
+
+	"github.com/tsingmaoai/xw-cli/internal/logger"
 )
 
 const (
@@ -31,6 +37,22 @@ type ServerIdentity struct {
 	// Defaults to the binary version (main.Version) if not specified.
 	// Format: vX.Y.Z (e.g., "v0.0.1")
 	ConfigVersion string `json:"config_version"`
+	
+	// RegistryMirror rewrites Docker image registry hosts before pulling.
+	// Optional; see ValidateRegistryMirror for the format.
+	RegistryMirror string `json:"registry_mirror"`
+	
+	// APIKey is the key clients must present as a bearer token.
+	// Optional; empty disables authentication.
+	APIKey string `json:"api_key"`
+	
+	// Host is the address 'xw serve' listens on when --host is not given.
+	// Optional; empty uses the --host default. Read only at server start.
+	Host string `json:"host"`
+	
+	// Port is the port 'xw serve' listens on when --port is not given.
+	// Optional; zero uses the --port default. Read only at server start.
+	Port int `json:"port"`
 }
 
 // GenerateServerName generates a random 6-character server name
@@ -72,6 +94,10 @@ func (c *Config) GetOrCreateServerIdentity() (*ServerIdentity, error) {
 		if identity.Registry == "" {
 			identity.Registry = DefaultRegistry
 			needsUpdate = true
+		} else if err := ValidateRegistryURL(identity.Registry); err != nil {
+			// Keep the file as the user wrote it, but do not fetch from junk
+			logger.Warn("Ignoring registry in %s: %v; using %s", confPath, err, DefaultRegistry)
+			identity.Registry = DefaultRegistry
 		}
 		
 		if identity.ConfigVersion == "" {
@@ -104,6 +130,28 @@ func (c *Config) GetOrCreateServerIdentity() (*ServerIdentity, error) {
 	return identity, nil
 }
 
+// ValidateRegistryURL checks that a configuration package registry URL is
+// an absolute http or https URL with a host.
+//
+// Parameters:
+//   - registry: Registry URL (e.g., "https://xw.tsingmao.com/packages.json")
+//
+// Returns:
+//   - Error describing why the URL cannot be used
+func ValidateRegistryURL(registry string) error {
+	u, err := url.Parse(registry)
+	if err != nil {
+		return fmt.Errorf("invalid registry URL %q: %v", registry, err)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return fmt.Errorf("invalid registry URL %q: must start with http:// or https://", registry)
+	}
+	if u.Host == "" {
+		return fmt.Errorf("invalid registry URL %q: host is missing", registry)
+	}
+	return nil
+}
+
 // getBinaryVersion returns the binary version for use as default config version.
 // This is stored in the Config during initialization from main.Version.
 func (c *Config) getBinaryVersion() string {
@@ -146,6 +194,32 @@ func (c *Config) readServerIdentity(path string) (*ServerIdentity, error) {
 			identity.Registry = value
 		case "config_version":
 			identity.ConfigVersion = value
+		case "registry_mirror":
+			identity.RegistryMirror = value
+		case "api_key":
+			identity.APIKey = value
+		case "host":
+			if value == "" {
+				continue
+			}
+			if err := ValidateServerHost(value); err != nil {
+				logger.Warn("Ignoring host in %s: %v", path, err)
+				continue
+			}
+			identity.Host = value
+		case "port":
+			if value == "" {
+				continue
+			}
+			port, err := strconv.Atoi(value)
+			if err == nil {
+				err = ValidateServerPort(port)
+			}
+			if err != nil {
+				logger.Warn("Ignoring port in %s: invalid port %q", path, value)
+				continue
+			}
+			identity.Port = port
 		}
 	}
 	
@@ -170,9 +244,27 @@ registry=%s
 
 # Configuration version currently in use
 config_version=%s
-`, identity.Name, identity.Registry, identity.ConfigVersion)
+
+# Docker registry mirror for image pulls (optional)
+# Format: registry=prefix[,registry=prefix...] or a single prefix for all registries
+registry_mirror=%s
+
+# API key clients must send as "Authorization: Bearer <key>" (optional)
+# Leave empty to allow unauthenticated access
+api_key=%s
+
+# Address and port the server listens on (optional, applied on restart)
+# Command-line flags --host and --port take precedence
+host=%s
+port=%s
+`, identity.Name, identity.Registry, identity.ConfigVersion, identity.RegistryMirror, identity.APIKey,
+		identity.Host, formatServerPort(identity.Port))
 	
-	return os.WriteFile(path, []byte(content), 0644)
+	// Owner-only permissions: the file may hold the API key
+	if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+		return err
+	}
+	return os.Chmod(path, 0600)
 }
 
 // LoadServerConfig loads server configuration from server.conf
@@ -184,16 +276,115 @@ func (c *Config) LoadServerConfig() error {
 	
 	c.Server.Name = identity.Name
 	c.Server.Registry = identity.Registry
+	c.Server.RegistryMirror = identity.RegistryMirror
+	c.Server.APIKey = identity.APIKey
 	return nil
 }
 
 // SaveServerConfig saves current server configuration to server.conf
 func (c *Config) SaveServerConfig() error {
 	confPath := filepath.Join(c.Storage.DataDir, ServerConfFileName)
+	
+	// Keep the active config version and the address for the next start,
+	// which are not part of c.Server (it holds the address in use)
+	var configVersion, host string
+	var port int
+	if existing, err := c.readServerIdentity(confPath); err == nil {
+		configVersion = existing.ConfigVersion
+		host = existing.Host
+		port = existing.Port
+	}
+	
+	c.mu.RLock()
+	apiKey := c.Server.APIKey
+	c.mu.RUnlock()
+	
 	identity := &ServerIdentity{
-		Name:     c.Server.Name,
-		Registry: c.Server.Registry,
+		Name:           c.Server.Name,
+		Registry:       c.Server.Registry,
+		ConfigVersion:  configVersion,
+		RegistryMirror: c.Server.RegistryMirror,
+		APIKey:         apiKey,
+		Host:           host,
+		Port:           port,
+	}
+	return c.writeServerIdentity(confPath, identity)
+}
+
+// SaveServerAddress records the host and port the server listens on from
+// its next start, without changing the address in use.
+//
+// Parameters:
+//   - host: Listen address (empty to keep the recorded one)
+//   - port: Listen port (zero to keep the recorded one)
+//
+// Returns:
+//   - Error if the value is invalid or server.conf cannot be written
+func (c *Config) SaveServerAddress(host string, port int) error {
+	if host != "" {
+		if err := ValidateServerHost(host); err != nil {
+			return err
+		}
+	}
+	if port != 0 {
+		if err := ValidateServerPort(port); err != nil {
+			return err
+		}
+	}
+	
+	confPath := filepath.Join(c.Storage.DataDir, ServerConfFileName)
+	identity, err := c.readServerIdentity(confPath)
+	if err != nil {
+		return err
+	}
+	if host != "" {
+		identity.Host = host
+	}
+	if port != 0 {
+		identity.Port = port
 	}
 	return c.writeServerIdentity(confPath, identity)
 }
 
+// ValidateServerHost checks that a listen address is an IP address or a
+// valid host name.
+//
+// Parameters:
+//   - host: Address (e.g., "0.0.0.0", "localhost", "xw.example.com")
+//
+// Returns:
+//   - Error describing why the address cannot be used
+func ValidateServerHost(host string) error {
+	if net.ParseIP(host) != nil {
+		return nil
+	}
+	if len(host) == 0 || len(host) > 253 {
+		return fmt.Errorf("invalid host %q: must be an IP address or host name", host)
+	}
+	for _, label := range strings.Split(host, ".") {
+		if !hostLabelPattern.MatchString(label) {
+			return fmt.Errorf("invalid host %q: must be an IP address or host name", host)
+		}
+	}
+	return nil
+}
+
+// ValidateServerPort checks that a listen port is in the TCP port range.
+func ValidateServerPort(port int) error {
+	if port < 1 || port > 65535 {
+		return fmt.Errorf("invalid port %d: must be between 1 and 65535", port)
+	}
+	return nil
+}
+
+// hostLabelPattern matches one dot-separated label of a host name.
+var hostLabelPattern = regexp.MustCompile(`^[A-Za-z0-9]([A-Za-z0-9-]{0,61}[A-Za-z0-9])?$`)
+
+// formatServerPort formats a port for server.conf, leaving unset ports empty.
+func formatServerPort(port int) string {
+	if port == 0 {
+		return ""
+	}
+	return strconv.Itoa(port)
+}
+
@@ -0,0 +1,206 @@
+package config
+
+import (
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// ServerInfoFileName is the name of the file a running server writes to the
+// configuration directory so clients can discover its address.
+const ServerInfoFileName = "server.json"
+
+// ServerInfo describes a running server instance.
+//
+// It is written by "xw serve" once the listening port is known and removed
+// on shutdown. Unlike server.conf it holds no settings, only runtime state.
+type ServerInfo struct {
+	// Address is the URL clients connect to (e.g., "http://localhost:11582"
+	// or "unix:///run/xw.sock").
+	Address string `json:"address"`
+
+	// PID is the process ID of the server.
+	PID int `json:"pid"`
+
+	// StartedAt is when the server started.
+	StartedAt time.Time `json:"started_at"`
+}
+
+// ClientAddress returns the address clients should use to reach the server.
+//
+// A server listening on all interfaces (0.0.0.0, ::, or an empty host) is
+// reached through localhost, or with TLS through the host name its
+// certificate is issued for, since certificates for a LAN server rarely
+// cover localhost and clients would reject the connection.
+//
+// Returns:
+//   - A string in the format "http://host:port", "https://host:port" or
+//     "unix:///path"
+func (c *Config) ClientAddress() string {
+	if c.Server.Socket != "" {
+		return c.GetServerAddress()
+	}
+	host := c.Server.Host
+	if ip := net.ParseIP(host); host == "" || (ip != nil && ip.IsUnspecified()) {
+		host = DefaultServerHost
+		if c.Server.TLSEnabled() {
+			if name := certificateHost(c.Server.TLSCert); name != "" {
+				host = name
+			}
+		}
+	}
+	return fmt.Sprintf("%s://%s", c.Server.Scheme(), net.JoinHostPort(host, strconv.Itoa(c.Server.Port)))
+}
+
+// certificateHost returns a host name the certificate in a PEM file is
+// issued for: localhost if it covers it, otherwise its first DNS name or IP
+// address. Wildcard names are skipped.
+//
+// Parameters:
+//   - certFile: Path of a PEM-encoded certificate
+//
+// Returns:
+//   - Host name, or "" if the file cannot be read or names no host
+func certificateHost(certFile string) string {
+	data, err := os.ReadFile(certFile)
+	if err != nil {
+		return ""
+	}
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return ""
+	}
+	cert, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		return ""
+	}
+
+	if cert.VerifyHostname(DefaultServerHost) == nil {
+		return DefaultServerHost
+	}
+	for _, name := range cert.DNSNames {
+		if !strings.HasPrefix(name, "*") {
+			return name
+		}
+	}
+	if len(cert.IPAddresses) > 0 {
+		return cert.IPAddresses[0].String()
+	}
+	return ""
+}
+
+// ProcessAlive reports whether the server process recorded in the info is
+// still running. A server that crashed leaves server.json behind; a dead
+// PID marks that file as stale.
+func (info *ServerInfo) ProcessAlive() bool {
+	if info.PID <= 0 {
+		return false
+	}
+	process, err := os.FindProcess(info.PID)
+	if err != nil {
+		return false
+	}
+	// Signal 0 checks for existence without affecting the process. EPERM
+	// means the process exists but belongs to another user.
+	err = process.Signal(syscall.Signal(0))
+	return err == nil || errors.Is(err, syscall.EPERM)
+}
+
+// WriteServerInfo records the running server's address in server.json.
+//
+// Parameters:
+//   - configDir: Configuration directory (e.g., ~/.xw)
+//   - info: Server information to write
+//
+// Returns:
+//   - Error if the file cannot be written
+func WriteServerInfo(configDir string, info *ServerInfo) error {
+	data, err := json.MarshalIndent(info, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode server info: %w", err)
+	}
+
+	path := filepath.Join(configDir, ServerInfoFileName)
+	tmpPath := path + ".tmp"
+	if err := os.WriteFile(tmpPath, data, 0644); err != nil {
+		return fmt.Errorf("failed to write server info: %w", err)
+	}
+	if err := os.Rename(tmpPath, path); err != nil {
+		os.Remove(tmpPath)
+		return fmt.Errorf("failed to write server info: %w", err)
+	}
+
+	return nil
+}
+
+// ReadServerInfo reads server.json from the configuration directory.
+//
+// Parameters:
+//   - configDir: Configuration directory (e.g., ~/.xw)
+//
+// Returns:
+//   - Server information
+//   - Error if the file does not exist or cannot be parsed
+func ReadServerInfo(configDir string) (*ServerInfo, error) {
+	data, err := os.ReadFile(filepath.Join(configDir, ServerInfoFileName))
+	if err != nil {
+		return nil, err
+	}
+
+	var info ServerInfo
+	if err := json.Unmarshal(data, &info); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", ServerInfoFileName, err)
+	}
+	if info.Address == "" {
+		return nil, fmt.Errorf("%s has no address", ServerInfoFileName)
+	}
+
+	return &info, nil
+}
+
+// RemoveStaleServerInfo deletes server.json if the server that wrote it is
+// no longer running.
+//
+// Parameters:
+//   - configDir: Configuration directory (e.g., ~/.xw)
+//
+// Returns:
+//   - true if a stale file was removed
+func RemoveStaleServerInfo(configDir string) bool {
+	info, err := ReadServerInfo(configDir)
+	if err != nil || info.ProcessAlive() {
+		return false
+	}
+	return os.Remove(filepath.Join(configDir, ServerInfoFileName)) == nil
+}
+
+// RemoveServerInfo deletes server.json if it was written by this process.
+//
+// Checking the PID keeps a stopping server from deleting the file of
+// another server that has started since.
+//
+// Parameters:
+//   - configDir: Configuration directory (e.g., ~/.xw)
+//
+// Returns:
+//   - Error if the file exists but cannot be removed
+func RemoveServerInfo(configDir string) error {
+	info, err := ReadServerInfo(configDir)
+	if err != nil || info.PID != os.Getpid() {
+		return nil
+	}
+
+	if err := os.Remove(filepath.Join(configDir, ServerInfoFileName)); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,74 @@
+package config
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// writeCertificate writes a self-signed certificate for the given names and
+// returns its path.
+func writeCertificate(t *testing.T, dnsNames []string, ips []net.IP) string {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "xw"},
+		NotBefore:    time.Now(),
+		NotAfter:     time.Now().Add(time.Hour),
+		DNSNames:     dnsNames,
+		IPAddresses:  ips,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	path := filepath.Join(t.TempDir(), "server.crt")
+	if err := os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestClientAddress(t *testing.T) {
+	tests := []struct {
+		name string
+		host string
+		cert string
+		want string
+	}{
+		{"plain http on all interfaces", "0.0.0.0", "", "http://localhost:11581"},
+		{"configured host", "192.168.1.10", "", "http://192.168.1.10:11581"},
+		{"tls with configured host", "xw.lan", writeCertificate(t, []string{"xw.lan"}, nil), "https://xw.lan:11581"},
+		{"tls covering localhost", "0.0.0.0", writeCertificate(t, []string{"xw.lan", "localhost"}, nil), "https://localhost:11581"},
+		{"tls for a lan name", "", writeCertificate(t, []string{"*.lan", "xw.lan"}, nil), "https://xw.lan:11581"},
+		{"tls for an ip address", "::", writeCertificate(t, nil, []net.IP{net.ParseIP("192.168.1.10")}), "https://192.168.1.10:11581"},
+		{"unreadable certificate", "0.0.0.0", filepath.Join(t.TempDir(), "missing.crt"), "https://localhost:11581"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &Config{Server: ServerConfig{Host: tt.host, Port: 11581}}
+			if tt.cert != "" {
+				c.Server.TLSCert = tt.cert
+				c.Server.TLSKey = "server.key"
+			}
+			if got := c.ClientAddress(); got != tt.want {
+				t.Errorf("ClientAddress() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -3,6 +3,8 @@ package device
 import (
 	"context"
 	"fmt"
+	"os"
+	"path/filepath"
 	"sort"
 	"strings"
 	"sync"
@@ -12,7 +14,6 @@ import (
 	"github.com/docker/docker/api/types/filters"
 	"github.com/docker/docker/client"
 	"github.com/tsingmaoai/xw-cli/internal/config"
-	"github.com/tsingmaoai/xw-cli/internal/logger"
 )
 
 // DeviceInfo represents information about a device for runtime use.
@@ -111,6 +112,12 @@ func (dt *DeviceTopology) GetDistance(chipA, chipB int) int {
 // It scans for available AI accelerators and dynamically tracks which devices are
 // allocated by querying running Docker containers.
 //
+// Between allocation and the container starting, Docker does not yet know
+// about the devices, so the allocator also holds in-process reservations.
+// Allocation checks Docker and the reservations under a single lock, making
+// the query+allocate step atomic: concurrent starts can never be handed the
+// same device.
+//
 // The allocator supports topology-aware allocation to optimize device placement
 // for high-speed interconnected devices (e.g., NVLink, HCCS).
 type Allocator struct {
@@ -118,6 +125,8 @@ type Allocator struct {
 	devices          []DeviceInfo                   // All detected and available devices
 	dockerClient     *client.Client                 // Docker client for querying container device usage
 	topologyByType   map[string]*DeviceTopology     // Topology per device type (e.g., "ascend-910b" -> topology)
+	reserved         map[int]string                 // Device index -> instance ID, until its container is running
+	ownerSources     []func() map[int]string        // Device owners outside Docker (e.g., native processes)
 }
 
 // NewAllocator creates and initializes a new DeviceAllocator.
@@ -138,10 +147,10 @@ func NewAllocator() (*Allocator, error) {
 	// Convert detected chips to DeviceInfo and collect all devices
 	var allDevices []DeviceInfo
 	for deviceType, chips := range chipsByType {
-		for idx, chip := range chips {
+		for _, chip := range chips {
 			deviceInfo := DeviceInfo{
 				Type:       deviceType,
-				Index:      idx,
+				Index:      chip.LogicalIndex,
 				BusAddress: chip.BusAddress,
 				ModelName:  chip.ModelName,
 				ConfigKey:  chip.ConfigKey,
@@ -155,6 +164,9 @@ func NewAllocator() (*Allocator, error) {
 					"chips_per_device":      fmt.Sprintf("%d", chip.ChipsPerDevice),
 				},
 			}
+			if node := chip.Properties[PropNUMANode]; node != "" {
+				deviceInfo.Properties[PropNUMANode] = node
+			}
 			allDevices = append(allDevices, deviceInfo)
 		}
 	}
@@ -187,7 +199,7 @@ func NewAllocator() (*Allocator, error) {
 					topology := NewDeviceTopology(chipModel.Topology)
 					if topology != nil {
 						topologyByType[chipModel.ConfigKey] = topology
-						logger.Info("Loaded topology for %s: %d boxes", chipModel.ConfigKey, len(chipModel.Topology.Boxes))
+						log.Info("Loaded topology for %s: %d boxes", chipModel.ConfigKey, len(chipModel.Topology.Boxes))
 					}
 				}
 			}
@@ -198,11 +210,12 @@ func NewAllocator() (*Allocator, error) {
 		devices:        allDevices,
 		dockerClient:   dockerClient,
 		topologyByType: topologyByType,
+		reserved:       make(map[int]string),
 	}
 
-	logger.Info("Device allocator initialized with %d devices (dynamic allocation from Docker)", len(allDevices))
+	log.Info("Device allocator initialized with %d devices (dynamic allocation from Docker)", len(allDevices))
 	for i, dev := range allDevices {
-		logger.Debug("Device %d: %s[%d] @ %s (%s)", 
+		log.Debug("Device %d: %s[%d] @ %s (%s)", 
 			i, dev.Type, dev.Index, dev.BusAddress, dev.ModelName)
 	}
 
@@ -228,10 +241,16 @@ func (a *Allocator) Allocate(instanceID string, count int) ([]DeviceInfo, error)
 	// Get currently allocated devices from Docker containers
 	allocatedDevices, err := a.getAllocatedDevicesFromDocker()
 	if err != nil {
-		logger.Warn("Failed to query Docker for device allocations: %v", err)
+		log.Warn("Failed to query Docker for device allocations: %v", err)
 		// Continue anyway, assuming no allocations
 		allocatedDevices = make(map[int]bool)
 	}
+	// Devices handed out to instances whose containers are not running yet
+	for idx, owner := range a.reserved {
+		if owner != instanceID {
+			allocatedDevices[idx] = true
+		}
+	}
 
 	// Group free devices by ConfigKey (chip model)
 	// This ensures we allocate devices of the same model, each with their own topology
@@ -276,13 +295,14 @@ func (a *Allocator) Allocate(instanceID string, count int) ([]DeviceInfo, error)
 	// Select best devices using topology-aware allocation (within same chip model)
 	allocatedIndices := a.selectBestDevices(freeIndices, count, selectedConfigKey)
 
-	// Prepare result
+	// Prepare result and hold the devices until the container is running
 	result := make([]DeviceInfo, len(allocatedIndices))
 	for i, idx := range allocatedIndices {
 		result[i] = a.devices[idx]
+		a.reserved[idx] = instanceID
 	}
 
-	logger.Info("Allocated %d %s device(s) to instance %s: indices %v (from %d free of this model)", 
+	log.Info("Allocated %d %s device(s) to instance %s: indices %v (from %d free of this model)", 
 		count, selectedConfigKey, instanceID, allocatedIndices, len(freeIndices))
 
 	return result, nil
@@ -292,11 +312,14 @@ func (a *Allocator) Allocate(instanceID string, count int) ([]DeviceInfo, error)
 //
 // This method implements topology-aware chip selection to minimize total distance
 // between allocated chips. The algorithm:
-//   1. If no topology for chip model: select first N chips (backward compatible)
-//   2. With topology: find chip combination with minimum total distance
+//   1. Candidates are runs of consecutive free chips, starting with the first N
+//   2. The candidate with minimum total distance wins (all equal without topology)
+//   3. Ties are broken by the number of NUMA nodes the chips span, so that
+//      a multi-chip instance stays on one CPU socket when possible
 //
 // For N chips, total distance = sum of all pairwise distances.
 // This ensures allocated chips are as close as possible in physical topology.
+// Without topology or NUMA information the first N chips are selected.
 //
 // Parameters:
 //   - freeIndices: Indices into a.devices array (NOT logical chip indices) of available chips
@@ -306,23 +329,17 @@ func (a *Allocator) Allocate(instanceID string, count int) ([]DeviceInfo, error)
 // Returns:
 //   - Selected array indices (into a.devices) optimized for topology
 func (a *Allocator) selectBestDevices(freeIndices []int, count int, configKey string) []int {
-	// Get topology for this chip model
+	// Get topology for this chip model (nil: all chips are equally close)
 	topology := a.topologyByType[configKey]
 	
-	// No topology or single chip: use simple selection
-	if topology == nil || count == 1 {
+	// Single chip: use simple selection
+	if count == 1 {
 		return freeIndices[:count]
 	}
 	
-	// Try to find chips all in same box (distance = 0)
 	bestIndices := freeIndices[:count]
 	bestDistance := a.calculateTotalDistance(bestIndices, topology)
-	
-	// If best distance is already 0, we found optimal allocation (all in same box)
-	if bestDistance == 0 {
-		logger.Debug("Topology-aware allocation for %s: found %d chips in same box (distance=0)", configKey, count)
-		return bestIndices
-	}
+	bestNodes := a.countNUMANodes(bestIndices)
 	
 	// Try different combinations to find better allocation
 	// For small counts, try a few different starting positions
@@ -332,24 +349,45 @@ func (a *Allocator) selectBestDevices(freeIndices []int, count int, configKey st
 	}
 	
 	for start := 1; start < maxAttempts && start+count <= len(freeIndices); start++ {
+		// Optimal allocation found (same box, one NUMA node)
+		if bestDistance == 0 && bestNodes <= 1 {
+			break
+		}
+		
 		candidate := freeIndices[start : start+count]
 		distance := a.calculateTotalDistance(candidate, topology)
+		nodes := a.countNUMANodes(candidate)
 		
-		if distance < bestDistance {
+		if distance < bestDistance || (distance == bestDistance && nodes < bestNodes) {
 			bestDistance = distance
+			bestNodes = nodes
 			bestIndices = candidate
-			
-			// Found optimal allocation (same box)
-			if bestDistance == 0 {
-				break
-			}
 		}
 	}
 	
-	logger.Debug("Topology-aware allocation for %s: selected %d chips with total distance=%d", configKey, count, bestDistance)
+	log.Debug("Topology-aware allocation for %s: selected %d chips with total distance=%d across %d NUMA node(s)",
+		configKey, count, bestDistance, bestNodes)
 	return bestIndices
 }
 
+// countNUMANodes returns the number of distinct NUMA nodes of a chip set.
+// Chips whose node is unknown are not counted.
+//
+// Parameters:
+//   - deviceArrayIndices: Indices into a.devices array (NOT logical chip indices)
+//
+// Returns:
+//   - Number of NUMA nodes (0 if none is known)
+func (a *Allocator) countNUMANodes(deviceArrayIndices []int) int {
+	nodes := make(map[string]bool)
+	for _, idx := range deviceArrayIndices {
+		if node := a.devices[idx].Properties[PropNUMANode]; node != "" {
+			nodes[node] = true
+		}
+	}
+	return len(nodes)
+}
+
 // calculateTotalDistance calculates the sum of pairwise distances for a chip set.
 //
 // Parameters:
@@ -376,10 +414,11 @@ func (a *Allocator) calculateTotalDistance(deviceArrayIndices []int, topology *D
 	return totalDistance
 }
 
-// Release frees devices previously allocated to an instance.
+// Release drops the in-process reservations held for an instance.
 //
-// Since devices are tracked via Docker containers, this method only logs
-// the release. The actual device freeing happens when the container is stopped/removed.
+// Call it once the instance's container is running (Docker labels then
+// track the allocation) or when creation failed. Devices of running
+// containers are freed when the container is stopped/removed.
 //
 // Parameters:
 //   - instanceID: Unique identifier for the instance
@@ -387,12 +426,31 @@ func (a *Allocator) calculateTotalDistance(deviceArrayIndices []int, topology *D
 // Returns:
 //   - Always returns nil (kept for API compatibility)
 func (a *Allocator) Release(instanceID string) error {
-	// No-op: devices are automatically released when container is stopped/removed
-	// The device allocation is tracked dynamically via Docker API
-	logger.Debug("Release called for instance %s (devices auto-released via container lifecycle)", instanceID)
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	for idx, owner := range a.reserved {
+		if owner == instanceID {
+			delete(a.reserved, idx)
+		}
+	}
+	log.Debug("Released device reservations for instance %s", instanceID)
 	return nil
 }
 
+// AddDeviceOwnerSource registers a source of device allocations that are
+// not visible as Docker containers, such as instances running as native
+// host processes. Devices it reports are treated like devices of running
+// containers.
+//
+// Parameters:
+//   - source: Function returning a map from device index to instance ID
+func (a *Allocator) AddDeviceOwnerSource(source func() map[int]string) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.ownerSources = append(a.ownerSources, source)
+}
+
 // getAllocatedDevicesFromDocker queries Docker for containers with xw labels
 // and extracts their device allocations.
 //
@@ -400,38 +458,152 @@ func (a *Allocator) Release(instanceID string) error {
 //   - Map of device indices that are currently allocated
 //   - Error if Docker query fails
 func (a *Allocator) getAllocatedDevicesFromDocker() (map[int]bool, error) {
+	owners, err := a.getDeviceOwnersFromDocker()
+	if err != nil {
+		return nil, err
+	}
+
+	allocated := make(map[int]bool, len(owners))
+	for idx := range owners {
+		allocated[idx] = true
+	}
+
+	return allocated, nil
+}
+
+// getDeviceOwnersFromDocker queries Docker for running xw containers and
+// maps each allocated device index to the instance using it. Devices
+// reported by the sources registered with AddDeviceOwnerSource are
+// included.
+//
+// Returns:
+//   - Map from device index to instance ID
+//   - Error if Docker query fails
+func (a *Allocator) getDeviceOwnersFromDocker() (map[int]string, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
-	// Query for all xw-managed containers (running or stopped)
+	owners := make(map[int]string)
+
+	// Devices of instances that do not run in containers
+	for _, source := range a.ownerSources {
+		for idx, instanceID := range source() {
+			owners[idx] = instanceID
+		}
+	}
+
+	// Query for all xw-managed containers (running or stopped). Without a
+	// Docker daemon (e.g., hosts using only native runtimes) there are no
+	// containers to account for.
 	containers, err := a.dockerClient.ContainerList(ctx, container.ListOptions{
 		All: true,
 		Filters: filters.NewArgs(
 			filters.Arg("label", "xw.runtime"),
 		),
 	})
+	if client.IsErrConnectionFailed(err) {
+		return owners, nil
+	}
 	if err != nil {
 		return nil, fmt.Errorf("failed to list containers: %w", err)
 	}
 
-	allocated := make(map[int]bool)
-
 	for _, c := range containers {
 		// Only count running containers
 		if c.State != "running" {
 			continue
 		}
 
+		instanceID := c.Labels["xw.instance_id"]
+		if instanceID == "" && len(c.Names) > 0 {
+			instanceID = strings.TrimPrefix(c.Names[0], "/")
+		}
+
 		// Extract device indices from labels
 		if deviceIndicesStr, ok := c.Labels["xw.device_indices"]; ok && deviceIndicesStr != "" {
-			indices := parseDeviceIndices(deviceIndicesStr)
-		for _, idx := range indices {
-			allocated[idx] = true
+			for _, idx := range parseDeviceIndices(deviceIndicesStr) {
+				owners[idx] = instanceID
+			}
 		}
 	}
+
+	return owners, nil
+}
+
+// SelectDevices validates user-requested device indices and returns the
+// corresponding devices.
+//
+// Each index must refer to a detected device that is still present on the
+// PCI bus and is not in use by another running or starting instance. The
+// devices are reserved for instanceID, as with Allocate. Errors name the
+// offending device and, for busy devices, the instance holding it, so that
+// two users picking the same device get a precise explanation instead of a
+// silent double allocation.
+//
+// Parameters:
+//   - instanceID: Instance the devices are requested for (its own
+//     allocations do not count as conflicts)
+//   - indices: Requested device indices
+//
+// Returns:
+//   - Slice of DeviceInfo in the requested order
+//   - Error if any index is out of range, duplicated, missing, or busy
+func (a *Allocator) SelectDevices(instanceID string, indices []int) ([]DeviceInfo, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	owners, err := a.getDeviceOwnersFromDocker()
+	if err != nil {
+		log.Warn("Failed to query Docker for device allocations: %v", err)
+		owners = make(map[int]string)
+	}
+	for idx, owner := range a.reserved {
+		owners[idx] = owner
 	}
 
-	return allocated, nil
+	seen := make(map[int]bool, len(indices))
+	result := make([]DeviceInfo, 0, len(indices))
+	for _, idx := range indices {
+		if idx < 0 || idx >= len(a.devices) {
+			return nil, fmt.Errorf("device index %d out of range (available: %d devices)", idx, len(a.devices))
+		}
+		if seen[idx] {
+			return nil, fmt.Errorf("device %d is listed more than once", idx)
+		}
+		seen[idx] = true
+
+		dev := a.devices[idx]
+		if !devicePresent(dev) {
+			return nil, fmt.Errorf("device %d (%s @ %s) is no longer present on the PCI bus", idx, dev.ModelName, dev.BusAddress)
+		}
+		if owner, busy := owners[idx]; busy && owner != instanceID {
+			return nil, fmt.Errorf("device %d is already allocated to instance %s", idx, owner)
+		}
+
+		result = append(result, dev)
+	}
+
+	// Hold the devices until the container is running
+	for _, idx := range indices {
+		a.reserved[idx] = instanceID
+	}
+
+	return result, nil
+}
+
+// devicePresent reports whether a detected device is still visible in sysfs.
+//
+// Devices without a bus address, or systems where sysfs is not readable,
+// are assumed present.
+func devicePresent(dev DeviceInfo) bool {
+	if dev.BusAddress == "" {
+		return true
+	}
+	if _, err := os.Stat(pciDevicesPath); err != nil {
+		return true
+	}
+	_, err := os.Stat(filepath.Join(pciDevicesPath, dev.BusAddress))
+	return !os.IsNotExist(err)
 }
 
 // parseDeviceIndices parses a comma-separated string of device indices.
@@ -470,7 +642,7 @@ func (a *Allocator) GetAvailableDevices() []DeviceInfo {
 	// Get currently allocated devices from Docker
 	allocatedDevices, err := a.getAllocatedDevicesFromDocker()
 	if err != nil {
-		logger.Warn("Failed to query Docker for device allocations: %v", err)
+		log.Warn("Failed to query Docker for device allocations: %v", err)
 		// Return all devices if we can't query Docker
 		return a.devices
 	}
@@ -478,7 +650,7 @@ func (a *Allocator) GetAvailableDevices() []DeviceInfo {
 	// Find free devices
 	var available []DeviceInfo
 	for i, dev := range a.devices {
-		if !allocatedDevices[i] {
+		if _, reserved := a.reserved[i]; !allocatedDevices[i] && !reserved {
 			available = append(available, dev)
 		}
 	}
@@ -520,7 +692,7 @@ func (a *Allocator) GetAllocations() map[string][]DeviceInfo {
 		),
 	})
 	if err != nil {
-		logger.Warn("Failed to list containers: %v", err)
+		log.Warn("Failed to list containers: %v", err)
 		return make(map[string][]DeviceInfo)
 	}
 
@@ -556,3 +728,43 @@ func (a *Allocator) GetAllocations() map[string][]DeviceInfo {
 }
 
 
+
+// Allocation is the allocation state of a single device.
+type Allocation struct {
+	Index      int        // Allocator index of the device, as in xw.device_indices
+	Device     DeviceInfo // The device
+	InstanceID string     // Instance using the device, empty if free
+	Pending    bool       // Reserved for an instance whose container is not running yet
+}
+
+// ListAllocations returns every detected device together with the instance
+// using it.
+//
+// A device is in use when a running xw container carries it in its
+// xw.device_indices label, or when it is reserved for an instance that is
+// still starting. Both sources are consulted by Allocate and SelectDevices,
+// so this is the view that decides whether a device can be allocated.
+//
+// Returns:
+//   - One Allocation per device, in device index order
+//   - Error if Docker cannot be queried
+func (a *Allocator) ListAllocations() ([]Allocation, error) {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	owners, err := a.getDeviceOwnersFromDocker()
+	if err != nil {
+		return nil, err
+	}
+
+	allocations := make([]Allocation, len(a.devices))
+	for i, dev := range a.devices {
+		allocations[i] = Allocation{Index: i, Device: dev, InstanceID: owners[i]}
+		if owner, reserved := a.reserved[i]; reserved && allocations[i].InstanceID == "" {
+			allocations[i].InstanceID = owner
+			allocations[i].Pending = true
+		}
+	}
+
+	return allocations, nil
+}
@@ -0,0 +1,155 @@
+package device
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/docker/docker/client"
+)
+
+// newTestAllocator returns an allocator over count fake devices of one chip
+// model. Its Docker client points at a socket that does not exist, so
+// allocations are tracked only by reservations.
+func newTestAllocator(t *testing.T, count int) *Allocator {
+	t.Helper()
+
+	dockerClient, err := client.NewClientWithOpts(
+		client.WithHost("unix://" + t.TempDir() + "/docker.sock"),
+	)
+	if err != nil {
+		t.Fatalf("failed to create Docker client: %v", err)
+	}
+
+	devices := make([]DeviceInfo, count)
+	for i := range devices {
+		devices[i] = DeviceInfo{
+			Type:       "test-chip",
+			Index:      i,
+			BusAddress: fmt.Sprintf("0000:%02x:00.0", i+1),
+			ModelName:  "Test Chip",
+			ConfigKey:  "test-chip",
+			Properties: map[string]string{},
+		}
+	}
+
+	return &Allocator{
+		devices:        devices,
+		dockerClient:   dockerClient,
+		topologyByType: make(map[string]*DeviceTopology),
+		reserved:       make(map[int]string),
+	}
+}
+
+func TestAllocateConcurrentNeverSharesDevices(t *testing.T) {
+	a := newTestAllocator(t, 8)
+	// Devices 0 and 1 are reserved for an instance that is still starting
+	a.reserved[0] = "starting"
+	a.reserved[1] = "starting"
+
+	const instances = 4
+	results := make([][]DeviceInfo, instances)
+	errs := make([]error, instances)
+
+	var wg sync.WaitGroup
+	for i := 0; i < instances; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			results[i], errs[i] = a.Allocate(fmt.Sprintf("instance-%d", i), 2)
+		}(i)
+	}
+	wg.Wait()
+
+	owners := make(map[int]string)
+	failed := 0
+	for i := 0; i < instances; i++ {
+		if errs[i] != nil {
+			failed++
+			continue
+		}
+		if len(results[i]) != 2 {
+			t.Fatalf("instance-%d: got %d devices, want 2", i, len(results[i]))
+		}
+		for _, dev := range results[i] {
+			if dev.Index == 0 || dev.Index == 1 {
+				t.Errorf("instance-%d: got device %d reserved for another instance", i, dev.Index)
+			}
+			if owner, ok := owners[dev.Index]; ok {
+				t.Errorf("device %d allocated to both %s and instance-%d", dev.Index, owner, i)
+			}
+			owners[dev.Index] = fmt.Sprintf("instance-%d", i)
+		}
+	}
+
+	// Six free devices fit three instances of two
+	if failed != 1 {
+		t.Errorf("%d allocations failed, want 1", failed)
+	}
+}
+
+func TestReleaseFreesReservedDevices(t *testing.T) {
+	a := newTestAllocator(t, 2)
+
+	first, err := a.Allocate("first", 2)
+	if err != nil {
+		t.Fatalf("Allocate(first) failed: %v", err)
+	}
+	if _, err := a.Allocate("second", 1); err == nil {
+		t.Fatal("Allocate(second) succeeded while all devices are reserved")
+	}
+
+	if err := a.Release("first"); err != nil {
+		t.Fatalf("Release(first) failed: %v", err)
+	}
+	second, err := a.Allocate("second", 2)
+	if err != nil {
+		t.Fatalf("Allocate(second) after release failed: %v", err)
+	}
+	for i := range second {
+		if second[i].Index != first[i].Index {
+			t.Errorf("device %d: got index %d, want %d", i, second[i].Index, first[i].Index)
+		}
+	}
+}
+
+func TestAllocatePrefersSingleNUMANode(t *testing.T) {
+	a := newTestAllocator(t, 8)
+	// Two NUMA nodes of four chips and no topology
+	for i := range a.devices {
+		a.devices[i].Properties[PropNUMANode] = fmt.Sprint(i / 4)
+	}
+	// Chips 0-2 are taken, so the first free pair (3, 4) spans both nodes
+	for _, idx := range []int{0, 1, 2} {
+		a.reserved[idx] = "other"
+	}
+
+	got, err := a.Allocate("instance", 2)
+	if err != nil {
+		t.Fatalf("Allocate() failed: %v", err)
+	}
+	if len(got) != 2 || got[0].Index != 4 || got[1].Index != 5 {
+		t.Errorf("Allocate() = %v, want chips 4 and 5 on NUMA node 1", got)
+	}
+}
+
+func TestAllocateSkipsDevicesOfOwnerSources(t *testing.T) {
+	a := newTestAllocator(t, 4)
+	// Devices 0 and 1 are held by an instance outside Docker
+	a.AddDeviceOwnerSource(func() map[int]string {
+		return map[int]string{0: "native", 1: "native"}
+	})
+
+	got, err := a.Allocate("instance", 2)
+	if err != nil {
+		t.Fatalf("Allocate() failed: %v", err)
+	}
+	for _, dev := range got {
+		if dev.Index == 0 || dev.Index == 1 {
+			t.Errorf("got device %d held by the owner source", dev.Index)
+		}
+	}
+	if _, err := a.Allocate("other", 1); err == nil {
+		t.Error("Allocate(other) succeeded while all devices are in use")
+	}
+}
@@ -7,7 +7,6 @@ package device
 import (
 	"github.com/tsingmaoai/xw-cli/internal/api"
 	"github.com/tsingmaoai/xw-cli/internal/config"
-	"github.com/tsingmaoai/xw-cli/internal/logger"
 )
 
 // LoadVendorsFromConfig loads vendor information from device configuration.
@@ -39,7 +38,7 @@ func LoadVendorsFromConfig() []ChipVendor {
 		})
 	}
 	
-	logger.Debug("Loaded %d vendor(s) from configuration", len(vendors))
+	log.Debug("Loaded %d vendor(s) from configuration", len(vendors))
 	return vendors
 }
 
@@ -77,7 +76,7 @@ func LoadChipsFromConfig() []ChipModel {
 		})
 	}
 	
-	logger.Debug("Loaded %d chip model(s) from configuration", len(chips))
+	log.Debug("Loaded %d chip model(s) from configuration", len(chips))
 	return chips
 }
 
@@ -104,7 +103,7 @@ func LoadChipsFromConfig() []ChipModel {
 func GetChipByID(vendorID, deviceID string) *ChipModel {
 	chips := LoadChipsFromConfig()
 	for i := range chips {
-		if chips[i].VendorID == vendorID && chips[i].DeviceID == deviceID {
+		if config.PCIIDEqual(chips[i].VendorID, vendorID) && config.PCIIDEqual(chips[i].DeviceID, deviceID) {
 			return &chips[i]
 		}
 	}
@@ -130,7 +129,7 @@ func GetChipsByVendor(vendorID string) []ChipModel {
 	chips := LoadChipsFromConfig()
 	var vendorChips []ChipModel
 	for _, chip := range chips {
-		if chip.VendorID == vendorID {
+		if config.PCIIDEqual(chip.VendorID, vendorID) {
 			vendorChips = append(vendorChips, chip)
 		}
 	}
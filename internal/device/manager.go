@@ -14,6 +14,7 @@ package device
 
 import (
 	"fmt"
+	"sort"
 	"strings"
 	"sync"
 
@@ -22,6 +23,9 @@ import (
 	"github.com/tsingmaoai/xw-cli/internal/logger"
 )
 
+// log is the device component logger (XW_LOG=device=LEVEL).
+var log = logger.Named("device")
+
 // Device represents a detected domestic chip device with its metadata.
 //
 // A Device instance contains information about a specific hardware device
@@ -274,7 +278,7 @@ func (m *Manager) GetSupportedTypes() []api.DeviceType {
 	types, err := config.GetSupportedDeviceTypes()
 	if err != nil {
 		// Configuration is required
-		logger.Warn("Failed to load device types from configuration: %v", err)
+		log.Warn("Failed to load device types from configuration: %v", err)
 		return []api.DeviceType{}
 	}
 	return types
@@ -320,7 +324,8 @@ func (m *Manager) GetDetectedDeviceTypes() []api.DeviceType {
 // one entry per physical chip.
 //
 // Returns:
-//   - A slice of DetectedChip with details for each physical chip
+//   - A slice of DetectedChip with details for each logical chip, ordered
+//     by device type and logical index
 //   - An error if hardware scanning fails
 //
 // Example:
@@ -339,10 +344,17 @@ func (m *Manager) ListDetectedChips() ([]DetectedChip, error) {
 		return nil, fmt.Errorf("failed to find AI chips: %w", err)
 	}
 
-	// Flatten the map into a single slice
+	// Flatten the map into a single slice, ordered by type and logical
+	// index so the listing is the same on every call
+	types := make([]string, 0, len(chipsMap))
+	for deviceType := range chipsMap {
+		types = append(types, deviceType)
+	}
+	sort.Strings(types)
+
 	var allChips []DetectedChip
-	for _, chips := range chipsMap {
-		allChips = append(allChips, chips...)
+	for _, deviceType := range types {
+		allChips = append(allChips, chipsMap[deviceType]...)
 	}
 
 	return allChips, nil
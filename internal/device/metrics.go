@@ -0,0 +1,165 @@
+package device
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/tsingmaoai/xw-cli/internal/config"
+)
+
+// Live metric property keys set on DetectedChip.Properties by CollectChipMetrics.
+const (
+	PropHealth        = "health"          // Vendor-reported health (e.g., "OK")
+	PropUtilization   = "utilization"     // Compute utilization in percent
+	PropMemoryUsedMB  = "memory_used_mb"  // Device memory in use, in MB
+	PropMemoryTotalMB = "memory_total_mb" // Total device memory, in MB
+)
+
+// metricsTimeout bounds a single invocation of a vendor monitoring tool.
+const metricsTimeout = 10 * time.Second
+
+// chipMetrics holds live metrics for one chip as reported by a vendor tool.
+type chipMetrics struct {
+	health        string
+	utilization   int
+	memoryUsedMB  int64
+	memoryTotalMB int64
+}
+
+// metricsCollector queries a vendor monitoring tool and returns metrics for
+// each chip, keyed by lowercase PCI bus address. Chips sharing a bus address
+// (multi-chip cards) are listed in chip order.
+type metricsCollector func(ctx context.Context) (map[string][]chipMetrics, error)
+
+// metricsCollectors maps PCI vendor IDs to their monitoring tool collector.
+// Vendors without an entry report no live metrics.
+var metricsCollectors = map[string]metricsCollector{
+	"0x19e5": collectAscendMetrics, // Huawei Ascend (npu-smi)
+}
+
+// CollectChipMetrics fills live utilization and memory properties of chips
+// from the vendor monitoring tools (e.g., npu-smi for Ascend).
+//
+// Chips whose vendor has no supported tool, or whose tool is not installed
+// or fails, are left without metric properties; callers should display them
+// as unavailable rather than treating this as an error.
+//
+// Parameters:
+//   - chips: Detected chips, updated in place
+func CollectChipMetrics(chips []DetectedChip) {
+	ctx, cancel := context.WithTimeout(context.Background(), metricsTimeout)
+	defer cancel()
+
+	results := make(map[string]map[string][]chipMetrics) // vendor ID → bus → metrics
+	for i := range chips {
+		chip := &chips[i]
+		vendorID := config.NormalizePCIID(chip.VendorID)
+
+		byBus, done := results[vendorID]
+		if !done {
+			collect, ok := metricsCollectors[vendorID]
+			if !ok {
+				results[vendorID] = nil
+				continue
+			}
+			var err error
+			byBus, err = collect(ctx)
+			if err != nil {
+				log.Debug("Live metrics unavailable for vendor %s: %v", vendorID, err)
+			}
+			results[vendorID] = byBus
+		}
+
+		entries := byBus[strings.ToLower(chip.BusAddress)]
+		if chip.ChipIndex >= len(entries) {
+			continue
+		}
+		m := entries[chip.ChipIndex]
+
+		if chip.Properties == nil {
+			chip.Properties = make(map[string]string)
+		}
+		if m.health != "" {
+			chip.Properties[PropHealth] = m.health
+		}
+		chip.Properties[PropUtilization] = strconv.Itoa(m.utilization)
+		if m.memoryTotalMB > 0 {
+			chip.Properties[PropMemoryUsedMB] = strconv.FormatInt(m.memoryUsedMB, 10)
+			chip.Properties[PropMemoryTotalMB] = strconv.FormatInt(m.memoryTotalMB, 10)
+		}
+	}
+}
+
+// collectAscendMetrics runs `npu-smi info` and parses per-chip metrics.
+func collectAscendMetrics(ctx context.Context) (map[string][]chipMetrics, error) {
+	if _, err := exec.LookPath("npu-smi"); err != nil {
+		return nil, fmt.Errorf("npu-smi not found in PATH")
+	}
+
+	output, err := exec.CommandContext(ctx, "npu-smi", "info").Output()
+	if err != nil {
+		return nil, fmt.Errorf("npu-smi info failed: %w", err)
+	}
+
+	return parseNpuSmiInfo(string(output)), nil
+}
+
+var (
+	busIDPattern    = regexp.MustCompile(`^[0-9A-Fa-f]{4}:[0-9A-Fa-f]{2}:[0-9A-Fa-f]{2}\.[0-9A-Fa-f]$`)
+	memUsagePattern = regexp.MustCompile(`(\d+)\s*/\s*(\d+)`)
+)
+
+// parseNpuSmiInfo parses the chip table printed by `npu-smi info`.
+//
+// Each chip occupies two table rows: an NPU row with the health status,
+// followed by a chip row with the bus ID, AICore utilization, and memory
+// usage. On chips with HBM (e.g., 910B) the HBM column is reported as the
+// device memory, since that is what models are loaded into:
+//
+//	| 0     910B3       | OK            | 93.5        40                0    / 0             |
+//	| 0                 | 0000:C1:00.0  | 0           0    / 0          3161 / 65536         |
+//
+// Rows that do not match this layout (headers, process tables) are ignored.
+func parseNpuSmiInfo(output string) map[string][]chipMetrics {
+	result := make(map[string][]chipMetrics)
+	health := ""
+
+	for _, line := range strings.Split(output, "\n") {
+		cols := strings.Split(line, "|")
+		if len(cols) < 4 {
+			continue
+		}
+		second := strings.TrimSpace(cols[2])
+		third := strings.TrimSpace(cols[3])
+
+		if !busIDPattern.MatchString(second) {
+			// NPU row: remember health for the chip row that follows
+			health = ""
+			if fields := strings.Fields(second); len(fields) > 0 {
+				health = fields[0]
+			}
+			continue
+		}
+
+		m := chipMetrics{health: health}
+		if fields := strings.Fields(third); len(fields) > 0 {
+			m.utilization, _ = strconv.Atoi(fields[0])
+		}
+		if pairs := memUsagePattern.FindAllStringSubmatch(third, -1); len(pairs) > 0 {
+			last := pairs[len(pairs)-1]
+			m.memoryUsedMB, _ = strconv.ParseInt(last[1], 10, 64)
+			m.memoryTotalMB, _ = strconv.ParseInt(last[2], 10, 64)
+		}
+
+		bus := strings.ToLower(second)
+		result[bus] = append(result[bus], m)
+		health = ""
+	}
+
+	return result
+}
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
 	
 	"github.com/tsingmaoai/xw-cli/internal/api"
@@ -30,8 +31,19 @@ type PCIDevice struct {
 	
 	// Class is the PCI device class
 	Class string
+	
+	// NUMANode is the NUMA node the device is attached to (e.g., "0"),
+	// empty if unknown or the host is not NUMA
+	NUMANode string
 }
 
+// PropNUMANode is the DetectedChip.Properties (and DeviceInfo.Properties)
+// key of the chip's NUMA node, set if the node is known.
+const PropNUMANode = "numa_node"
+
+// pciDevicesPath is the sysfs directory listing PCI devices.
+var pciDevicesPath = "/sys/bus/pci/devices"
+
 // ScanPCIDevices scans the system for PCI devices
 //
 // This function reads PCI device information from /sys/bus/pci/devices
@@ -41,8 +53,6 @@ type PCIDevice struct {
 //   - Slice of PCIDevice found on the system
 //   - Error if scanning fails
 func ScanPCIDevices() ([]PCIDevice, error) {
-	const pciDevicesPath = "/sys/bus/pci/devices"
-	
 	// Check if PCI sysfs path exists
 	if _, err := os.Stat(pciDevicesPath); os.IsNotExist(err) {
 		return nil, fmt.Errorf("PCI devices path not found: %s", pciDevicesPath)
@@ -106,6 +116,11 @@ func readPCIDevice(devicePath, busAddress string) (PCIDevice, error) {
 		device.Class = strings.TrimSpace(class)
 	}
 	
+	// Read NUMA node (optional; -1 when the platform does not report one)
+	if node, err := readPCIFile(filepath.Join(devicePath, "numa_node")); err == nil && !strings.HasPrefix(node, "-") {
+		device.NUMANode = node
+	}
+	
 	return device, nil
 }
 
@@ -143,6 +158,30 @@ func FindAIChips() (map[string][]DetectedChip, error) {
 		return nil, fmt.Errorf("failed to load device configuration: %w", err)
 	}
 	
+	return MatchAIChips(devices, devConfig), nil
+}
+
+// MatchAIChips identifies the AI chips among PCI devices and expands
+// multi-chip cards into logical chips.
+//
+// Devices are numbered in bus address order, so logical indices are stable
+// across scans as long as the cards stay in their slots. A card with
+// chips_per_device N at physical index P provides logical chips P*N to
+// P*N+N-1 of its device type, the indices used by topology boxes, by
+// --device and for the /dev nodes of each chip.
+//
+// Parameters:
+//   - devices: PCI devices (e.g., from ScanPCIDevices)
+//   - devConfig: Device configuration to match them against
+//
+// Returns:
+//   - Map of device type to its logical chips, ordered by LogicalIndex
+func MatchAIChips(devices []PCIDevice, devConfig *config.DevicesConfig) map[string][]DetectedChip {
+	devices = append([]PCIDevice(nil), devices...)
+	sort.SliceStable(devices, func(i, j int) bool {
+		return devices[i].BusAddress < devices[j].BusAddress
+	})
+	
 	detected := make(map[string][]DetectedChip)
 	physicalDeviceCount := make(map[string]int) // Track physical device count per device type
 	
@@ -199,13 +238,17 @@ func FindAIChips() (map[string][]DetectedChip, error) {
 				PhysicalDeviceIndex: physicalIdx,
 				ChipIndex:           chipIdx,
 				ChipsPerDevice:      chipsPerDevice,
+				LogicalIndex:        len(detected[deviceType]),
+			}
+			if device.NUMANode != "" {
+				detectedChip.Properties = map[string]string{PropNUMANode: device.NUMANode}
 			}
 			
-		detected[deviceType] = append(detected[deviceType], detectedChip)
+			detected[deviceType] = append(detected[deviceType], detectedChip)
 		}
 	}
 	
-	return detected, nil
+	return detected
 }
 
 // DetectedChip represents a detected AI chip with full information
@@ -250,6 +293,16 @@ type DetectedChip struct {
 	
 	// ChipsPerDevice indicates total chips on this physical device
 	ChipsPerDevice int `json:"chips_per_device"`
+	
+	// LogicalIndex is the index of the chip among the chips of its device
+	// type (0-based), as allocated and selected with --device. Chips of a
+	// multi-chip card have consecutive indices.
+	LogicalIndex int `json:"logical_index"`
+	
+	// Properties holds the chip's NUMA node (PropNUMANode) if known, and
+	// live metrics (utilization, memory) when requested, see
+	// CollectChipMetrics
+	Properties map[string]string `json:"properties,omitempty"`
 }
 
 // ParseLspciOutput parses the output of `lspci -nn` command
@@ -0,0 +1,230 @@
+package device
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/tsingmaoai/xw-cli/internal/config"
+)
+
+// dualChipConfig returns a device configuration with one dual-chip card.
+func dualChipConfig() *config.DevicesConfig {
+	return &config.DevicesConfig{
+		Version: "test",
+		Vendors: []config.ChipVendorConfig{
+			{
+				VendorName: "Test",
+				VendorID:   "0x1234",
+				ChipModels: []config.ChipModelConfig{
+					{
+						ConfigKey:      "test-duo",
+						ModelName:      "Test Duo",
+						DeviceID:       "0x0002",
+						ChipsPerDevice: 2,
+					},
+				},
+			},
+		},
+	}
+}
+
+func TestMatchAIChipsNumbersMultiChipCardsByBusAddress(t *testing.T) {
+	// Scan order is deliberately not bus address order, and an unknown
+	// device sits between the cards
+	devices := []PCIDevice{
+		{VendorID: "0x1234", DeviceID: "0x0002", BusAddress: "0000:81:00.0"},
+		{VendorID: "0x8086", DeviceID: "0x1521", BusAddress: "0000:02:00.0"},
+		{VendorID: "0x1234", DeviceID: "0x0002", BusAddress: "0000:01:00.0"},
+		{VendorID: "0x1234", DeviceID: "0x0002", BusAddress: "0000:41:00.0"},
+	}
+
+	chips := MatchAIChips(devices, dualChipConfig())["test-duo"]
+
+	want := []struct {
+		busAddress    string
+		physicalIndex int
+		chipIndex     int
+	}{
+		{"0000:01:00.0", 0, 0},
+		{"0000:01:00.0", 0, 1},
+		{"0000:41:00.0", 1, 0},
+		{"0000:41:00.0", 1, 1},
+		{"0000:81:00.0", 2, 0},
+		{"0000:81:00.0", 2, 1},
+	}
+	if len(chips) != len(want) {
+		t.Fatalf("got %d chips, want %d", len(chips), len(want))
+	}
+	for i, w := range want {
+		chip := chips[i]
+		if chip.LogicalIndex != i {
+			t.Errorf("chip %d: LogicalIndex = %d, want %d", i, chip.LogicalIndex, i)
+		}
+		if chip.BusAddress != w.busAddress {
+			t.Errorf("chip %d: BusAddress = %s, want %s", i, chip.BusAddress, w.busAddress)
+		}
+		if chip.PhysicalDeviceIndex != w.physicalIndex {
+			t.Errorf("chip %d: PhysicalDeviceIndex = %d, want %d", i, chip.PhysicalDeviceIndex, w.physicalIndex)
+		}
+		if chip.ChipIndex != w.chipIndex {
+			t.Errorf("chip %d: ChipIndex = %d, want %d", i, chip.ChipIndex, w.chipIndex)
+		}
+		if chip.ChipsPerDevice != 2 {
+			t.Errorf("chip %d: ChipsPerDevice = %d, want 2", i, chip.ChipsPerDevice)
+		}
+	}
+}
+
+func TestMatchAIChipsIsStableAcrossScanOrders(t *testing.T) {
+	devices := []PCIDevice{
+		{VendorID: "0x1234", DeviceID: "0x0002", BusAddress: "0000:41:00.0", NUMANode: "1"},
+		{VendorID: "0x1234", DeviceID: "0x0002", BusAddress: "0000:01:00.0", NUMANode: "0"},
+	}
+	reversed := []PCIDevice{devices[1], devices[0]}
+
+	first := MatchAIChips(devices, dualChipConfig())["test-duo"]
+	second := MatchAIChips(reversed, dualChipConfig())["test-duo"]
+	if len(first) != 4 || len(second) != 4 {
+		t.Fatalf("got %d and %d chips, want 4", len(first), len(second))
+	}
+	for i := range first {
+		if first[i].BusAddress != second[i].BusAddress || first[i].ChipIndex != second[i].ChipIndex {
+			t.Errorf("chip %d differs between scan orders: %s/%d vs %s/%d", i,
+				first[i].BusAddress, first[i].ChipIndex, second[i].BusAddress, second[i].ChipIndex)
+		}
+		wantNode := "0"
+		if i >= 2 {
+			wantNode = "1"
+		}
+		if node := first[i].Properties[PropNUMANode]; node != wantNode {
+			t.Errorf("chip %d: NUMA node = %q, want %q", i, node, wantNode)
+		}
+	}
+}
+
+// writeSysfsDevice creates a fake sysfs PCI device directory.
+func writeSysfsDevice(t *testing.T, root, busAddress string, files map[string]string) {
+	t.Helper()
+
+	dir := filepath.Join(root, busAddress)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		t.Fatalf("failed to create %s: %v", dir, err)
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content+"\n"), 0644); err != nil {
+			t.Fatalf("failed to write %s/%s: %v", busAddress, name, err)
+		}
+	}
+}
+
+func TestScanPCIDevicesDetectsHygonDCU(t *testing.T) {
+	root := t.TempDir()
+	writeSysfsDevice(t, root, "0000:3d:00.0", map[string]string{
+		"vendor": "0x1d94", "device": "0x55b7", "subsystem_vendor": "0x1d94",
+		"subsystem_device": "0x0001", "class": "0x120000", "numa_node": "1",
+	})
+	writeSysfsDevice(t, root, "0000:1c:00.0", map[string]string{
+		"vendor": "0x1d94", "device": "0x55b7", "class": "0x120000", "numa_node": "-1",
+	})
+	// A network card and an entry without readable IDs are not AI chips
+	writeSysfsDevice(t, root, "0000:02:00.0", map[string]string{
+		"vendor": "0x8086", "device": "0x1521", "class": "0x020000",
+	})
+	writeSysfsDevice(t, root, "0000:03:00.0", map[string]string{"class": "0x060400"})
+
+	saved := pciDevicesPath
+	pciDevicesPath = root
+	defer func() { pciDevicesPath = saved }()
+
+	devices, err := ScanPCIDevices()
+	if err != nil {
+		t.Fatalf("ScanPCIDevices() failed: %v", err)
+	}
+	if len(devices) != 3 {
+		t.Fatalf("got %d PCI devices, want 3: %+v", len(devices), devices)
+	}
+
+	// IDs in the configuration are written differently from sysfs
+	devConfig := &config.DevicesConfig{
+		Version: "test",
+		Vendors: []config.ChipVendorConfig{
+			{
+				VendorName: "Hygon",
+				VendorID:   "1D94",
+				ChipModels: []config.ChipModelConfig{
+					{
+						ConfigKey:    "hygon-dcu-z100",
+						ModelName:    "Hygon DCU Z100",
+						DeviceID:     "0x55B7",
+						Generation:   "Hygon DCU",
+						Capabilities: []string{"fp16", "bf16"},
+					},
+				},
+			},
+		},
+	}
+
+	detected := MatchAIChips(devices, devConfig)
+	if len(detected) != 1 {
+		t.Fatalf("got %d device types, want only hygon-dcu-z100", len(detected))
+	}
+	chips := detected["hygon-dcu-z100"]
+	if len(chips) != 2 {
+		t.Fatalf("got %d Hygon chips, want 2", len(chips))
+	}
+
+	for i, busAddress := range []string{"0000:1c:00.0", "0000:3d:00.0"} {
+		chip := chips[i]
+		if chip.BusAddress != busAddress || chip.LogicalIndex != i {
+			t.Errorf("chip %d: %s index %d, want %s index %d", i, chip.BusAddress, chip.LogicalIndex, busAddress, i)
+		}
+		if chip.ConfigKey != "hygon-dcu-z100" || chip.DeviceType != "hygon-dcu-z100" || chip.ModelName != "Hygon DCU Z100" {
+			t.Errorf("chip %d: config key %q, type %q, model %q", i, chip.ConfigKey, chip.DeviceType, chip.ModelName)
+		}
+		if !reflect.DeepEqual(chip.Capabilities, []string{"fp16", "bf16"}) {
+			t.Errorf("chip %d: capabilities = %v, want [fp16 bf16]", i, chip.Capabilities)
+		}
+	}
+
+	// numa_node -1 means unknown
+	if node, ok := chips[0].Properties[PropNUMANode]; ok {
+		t.Errorf("chip 0: NUMA node = %q, want none", node)
+	}
+	if node := chips[1].Properties[PropNUMANode]; node != "1" {
+		t.Errorf("chip 1: NUMA node = %q, want 1", node)
+	}
+}
+
+func TestLatestShippedConfigDetectsHygonAndCambricon(t *testing.T) {
+	paths, err := filepath.Glob(filepath.Join("..", "..", "configs", "*", "devices.yaml"))
+	if err != nil || len(paths) == 0 {
+		t.Fatalf("no shipped devices.yaml found: %v", err)
+	}
+	sort.Strings(paths)
+	path := paths[len(paths)-1]
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var devConfig config.DevicesConfig
+	if err := yaml.Unmarshal(data, &devConfig); err != nil {
+		t.Fatalf("failed to parse %s: %v", path, err)
+	}
+
+	devices := []PCIDevice{
+		{VendorID: "0x1d94", DeviceID: "0x55b7", BusAddress: "0000:3d:00.0"},
+		{VendorID: "0xcabc", DeviceID: "0x0370", BusAddress: "0000:5e:00.0"},
+	}
+	detected := MatchAIChips(devices, &devConfig)
+	for _, key := range []string{"hygon-dcu-z100", "cambricon-mlu370"} {
+		if len(detected[key]) != 1 {
+			t.Errorf("%s: %s detected %d times, want 1", path, key, len(detected[key]))
+		}
+	}
+}
@@ -3,13 +3,17 @@ package hooks
 import (
 	"context"
 	"fmt"
+	
+	"github.com/tsingmaoai/xw-cli/internal/api"
+	"github.com/tsingmaoai/xw-cli/internal/config"
+	"github.com/tsingmaoai/xw-cli/internal/logger"
 )
 
 // DockerHook implements hooks.Hook for Docker availability checking and installation.
 //
 // This hook ensures Docker is installed and running before attempting to
 // create Docker-based model instances. If Docker is not available, it
-// attempts automatic installation (Ubuntu only).
+// attempts automatic installation (Ubuntu, Debian, CentOS/RHEL, openEuler).
 type DockerHook struct {
 	installer *DockerInstaller
 	eventCh   chan<- string
@@ -43,12 +47,19 @@ func (h *DockerHook) Name() string {
 //   - Docker CLI is available
 //   - Docker daemon is responsive
 //
+// It reports the checking_docker startup phase on the event channel.
+//
 // Parameters:
 //   - ctx: Context for cancellation
 //
 // Returns:
 //   - error if Docker is not available
 func (h *DockerHook) Check(ctx context.Context) error {
+	h.installer.sendEvent(api.StartupEvent{
+		Phase:   api.StartupPhaseCheckingDocker,
+		Message: "Checking Docker",
+	}.Tagged())
+	
 	dockerOK, err := h.installer.CheckDocker()
 	if err != nil {
 		return err
@@ -65,7 +76,7 @@ func (h *DockerHook) Check(ctx context.Context) error {
 //
 // This method:
 //   - Detects the operating system
-//   - Installs Docker if on Ubuntu
+//   - Installs Docker on Ubuntu, Debian, CentOS/RHEL, or openEuler
 //   - Returns error if OS is not supported
 //
 // Parameters:
@@ -83,7 +94,7 @@ func (h *DockerHook) Install(ctx context.Context) error {
 //   - Human-readable hook description
 func (h *DockerHook) Message() string {
 	return "Docker is required for running models in container mode. " +
-		"The system will attempt to install Docker automatically (Ubuntu only)."
+		"The system will attempt to install Docker automatically (Ubuntu, Debian, CentOS/RHEL, openEuler)."
 }
 
 // Interactive indicates whether user confirmation is recommended.
@@ -100,27 +111,35 @@ func (h *DockerHook) Interactive() bool {
 // DockerImageHook implements hooks.Hook for Docker image availability checking.
 //
 // This hook ensures the required Docker image is available locally before
-// attempting to create a container. If the image is not present, it pulls
-// the image from the registry.
+// attempting to create a container. If the image is not present, it is
+// loaded from a local image archive when one contains it, and pulled from
+// the registry otherwise.
+//
+// An image pinned to a digest ("name[:tag]@sha256:...") is only considered
+// available if the local image matches the digest; otherwise it is pulled
+// by digest and tagged as name.
 type DockerImageHook struct {
-	installer *DockerInstaller
-	imageName string
-	eventCh   chan<- string
+	installer  *DockerInstaller
+	imageName  string
+	archiveDir string // Directory of `docker save` archives checked before pulling
+	eventCh    chan<- string
 }
 
 // NewDockerImageHook creates a new Docker image hook.
 //
 // Parameters:
 //   - imageName: Docker image to check/pull
+//   - archiveDir: Directory of image archives to try before pulling (empty to always pull)
 //   - eventCh: Channel for sending progress events
 //
 // Returns:
 //   - Hook instance
-func NewDockerImageHook(imageName string, eventCh chan<- string) Hook {
+func NewDockerImageHook(imageName, archiveDir string, eventCh chan<- string) Hook {
 	return &DockerImageHook{
-		installer: NewDockerInstaller(eventCh),
-		imageName: imageName,
-		eventCh:   eventCh,
+		installer:  NewDockerInstaller(eventCh),
+		imageName:  imageName,
+		archiveDir: archiveDir,
+		eventCh:    eventCh,
 	}
 }
 
@@ -132,7 +151,8 @@ func (h *DockerImageHook) Name() string {
 	return fmt.Sprintf("docker-image:%s", h.imageName)
 }
 
-// Check verifies if the Docker image exists locally.
+// Check verifies if the Docker image exists locally and, for a pinned
+// image, matches its digest.
 //
 // Parameters:
 //   - ctx: Context for cancellation
@@ -140,6 +160,17 @@ func (h *DockerImageHook) Name() string {
 // Returns:
 //   - error if image is not available locally
 func (h *DockerImageHook) Check(ctx context.Context) error {
+	if name, digest := config.SplitImageDigest(h.imageName); digest != "" {
+		matches, err := h.installer.CheckDockerImageDigest(ctx, name, digest)
+		if err != nil {
+			return err
+		}
+		if !matches {
+			return fmt.Errorf("Docker image %s does not match pinned digest %s", name, digest)
+		}
+		return nil
+	}
+	
 	exists, err := h.installer.CheckDockerImage(ctx, h.imageName)
 	if err != nil {
 		return err
@@ -152,17 +183,39 @@ func (h *DockerImageHook) Check(ctx context.Context) error {
 	return nil
 }
 
-// Install pulls the Docker image from the registry.
+// Install makes the Docker image available locally.
 //
-// This method uses PTY to capture Docker's native progress output,
-// including progress bars for each layer being downloaded.
+// If an archive in the archive directory contains the image, it is loaded
+// with `docker load`, which works on air-gapped hosts. Otherwise the image
+// is pulled from the registry, using PTY to capture Docker's native progress
+// output including progress bars for each layer being downloaded.
+//
+// Pinned images are always pulled by digest, since image archives do not
+// record digests, and then tagged with the image name.
 //
 // Parameters:
 //   - ctx: Context for cancellation
 //
 // Returns:
-//   - error if pull fails
+//   - error if loading or pulling fails
 func (h *DockerImageHook) Install(ctx context.Context) error {
+	if name, digest := config.SplitImageDigest(h.imageName); digest != "" {
+		pinned := config.PinnedImageReference(name, digest)
+		if err := h.installer.PullDockerImage(ctx, pinned); err != nil {
+			return err
+		}
+		return h.installer.TagDockerImage(ctx, pinned, name)
+	}
+	
+	if h.archiveDir != "" {
+		archive, err := h.installer.FindImageArchive(h.archiveDir, h.imageName)
+		if err != nil {
+			logger.Warn("Failed to search image archives in %s: %v", h.archiveDir, err)
+		} else if archive != "" {
+			return h.installer.LoadImageFromTar(ctx, archive)
+		}
+	}
+	
 	return h.installer.PullDockerImage(ctx, h.imageName)
 }
 
@@ -171,6 +224,10 @@ func (h *DockerImageHook) Install(ctx context.Context) error {
 // Returns:
 //   - Human-readable hook description
 func (h *DockerImageHook) Message() string {
+	if h.archiveDir != "" {
+		return fmt.Sprintf("Docker image '%s' will be loaded from %s if archived there, or pulled from the registry.",
+			h.imageName, h.archiveDir)
+	}
 	return fmt.Sprintf("Docker image '%s' will be pulled from the registry.", h.imageName)
 }
 
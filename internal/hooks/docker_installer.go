@@ -1,26 +1,31 @@
 package hooks
 
 import (
+	"archive/tar"
 	"context"
+	"encoding/json"
 	"fmt"
 	"io"
 	"os"
 	"os/exec"
+	"path/filepath"
 	"runtime"
+	"sort"
 	"strings"
 	
 	"github.com/creack/pty"
+	"github.com/tsingmaoai/xw-cli/internal/config"
 	"github.com/tsingmaoai/xw-cli/internal/logger"
 )
 
 // DockerInstaller handles Docker installation and image management.
 //
-// Currently only supports Ubuntu Linux systems. Other Linux distributions
-// (Debian, CentOS, RHEL, Fedora, Arch) and other operating systems
-// (macOS, Windows) are not supported for automatic Docker installation.
+// Supports Ubuntu, Debian, and the RHEL family (CentOS, RHEL, openEuler)
+// on Linux. Other Linux distributions (Fedora, Arch) and other operating
+// systems (macOS, Windows) are not supported for automatic Docker installation.
 //
 // Features:
-//   - Automatic Docker installation on Ubuntu
+//   - Automatic Docker installation on Ubuntu, Debian, CentOS/RHEL, and openEuler
 //   - Docker image checking and pulling with progress streaming
 //   - Context-aware cancellation support (Ctrl+C handling)
 type DockerInstaller struct {
@@ -81,7 +86,7 @@ func (d *DockerInstaller) InstallDocker() error {
 	d.sendEvent(fmt.Sprintf("Detecting OS: %s", runtime.GOOS))
 	
 	if runtime.GOOS != "linux" {
-		return fmt.Errorf("automatic Docker installation only supported on Linux (Ubuntu). Current OS: %s", runtime.GOOS)
+		return fmt.Errorf("automatic Docker installation only supported on Linux. Current OS: %s", runtime.GOOS)
 	}
 	
 	return d.installDockerLinux()
@@ -95,21 +100,50 @@ func (d *DockerInstaller) installDockerLinux() error {
 	distro := d.detectLinuxDistro()
 	d.sendEvent(fmt.Sprintf("Detected distribution: %s", distro))
 	
-	if distro == "ubuntu" {
+	switch distro {
+	case "ubuntu":
 		return d.installDockerUbuntu()
+	case "debian":
+		return d.installDockerDebian()
+	case "centos", "rhel", "openeuler":
+		return d.installDockerRHEL(distro)
 	}
 	
-	return fmt.Errorf("unsupported Linux distribution: %s. Only Ubuntu is currently supported", distro)
+	return fmt.Errorf("unsupported Linux distribution: %s. Supported: Ubuntu, Debian, CentOS, RHEL, openEuler", distro)
 }
 
 // detectLinuxDistro detects the Linux distribution.
+//
+// The ID field of /etc/os-release is checked first, then ID_LIKE, so
+// derivatives (e.g., Rocky Linux with ID_LIKE="rhel centos fedora") map to
+// the distribution whose installation method they share.
+//
+// Returns one of "ubuntu", "debian", "centos", "rhel", "openeuler", or "unknown".
 func (d *DockerInstaller) detectLinuxDistro() string {
-	// Try reading /etc/os-release
 	data, err := os.ReadFile("/etc/os-release")
-	if err == nil {
-		content := string(data)
-		if strings.Contains(strings.ToLower(content), "ubuntu") {
-			return "ubuntu"
+	if err != nil {
+		return "unknown"
+	}
+	
+	var id, idLike string
+	for _, line := range strings.Split(string(data), "\n") {
+		key, value, ok := strings.Cut(strings.TrimSpace(line), "=")
+		if !ok {
+			continue
+		}
+		value = strings.ToLower(strings.Trim(value, `"'`))
+		switch key {
+		case "ID":
+			id = value
+		case "ID_LIKE":
+			idLike = value
+		}
+	}
+	
+	for _, candidate := range append([]string{id}, strings.Fields(idLike)...) {
+		switch candidate {
+		case "ubuntu", "debian", "centos", "rhel", "openeuler":
+			return candidate
 		}
 	}
 	
@@ -141,6 +175,72 @@ func (d *DockerInstaller) installDockerUbuntu() error {
 	return d.executeCommands(commands)
 }
 
+// installDockerDebian installs Docker on Debian systems.
+//
+// The steps mirror installDockerUbuntu but use Docker's Debian repository.
+func (d *DockerInstaller) installDockerDebian() error {
+	commands := [][]string{
+		// Update package index
+		{"apt-get", "update"},
+		// Install prerequisites
+		{"apt-get", "install", "-y", "ca-certificates", "curl", "gnupg"},
+		// Add Docker's official GPG key
+		{"install", "-m", "0755", "-d", "/etc/apt/keyrings"},
+		{"sh", "-c", "curl -fsSL https://download.docker.com/linux/debian/gpg | gpg --dearmor -o /etc/apt/keyrings/docker.gpg"},
+		{"chmod", "a+r", "/etc/apt/keyrings/docker.gpg"},
+		// Add Docker repository
+		{"sh", "-c", `echo "deb [arch=$(dpkg --print-architecture) signed-by=/etc/apt/keyrings/docker.gpg] https://download.docker.com/linux/debian $(. /etc/os-release && echo "$VERSION_CODENAME") stable" | tee /etc/apt/sources.list.d/docker.list > /dev/null`},
+		// Update package index again
+		{"apt-get", "update"},
+		// Install Docker
+		{"apt-get", "install", "-y", "docker-ce", "docker-ce-cli", "containerd.io", "docker-buildx-plugin", "docker-compose-plugin"},
+		// Start Docker service
+		{"systemctl", "start", "docker"},
+		{"systemctl", "enable", "docker"},
+	}
+	
+	return d.executeCommands(commands)
+}
+
+// installDockerRHEL installs Docker on RHEL-family systems.
+//
+// CentOS and RHEL use Docker's yum repository for their distribution.
+// openEuler is not covered by Docker's repositories and ships its own
+// "docker" package, which is installed from the distribution repositories.
+// dnf is used when available, falling back to yum on older releases.
+func (d *DockerInstaller) installDockerRHEL(distro string) error {
+	pkgMgr := "yum"
+	if _, err := exec.LookPath("dnf"); err == nil {
+		pkgMgr = "dnf"
+	}
+	
+	var commands [][]string
+	if distro == "openeuler" {
+		commands = [][]string{
+			// Install Docker from the openEuler repositories
+			{pkgMgr, "install", "-y", "docker"},
+		}
+	} else {
+		repoURL := fmt.Sprintf("https://download.docker.com/linux/%s/docker-ce.repo", distro)
+		commands = [][]string{
+			// Install repository management tools
+			{pkgMgr, "install", "-y", "yum-utils"},
+			// Add Docker repository
+			{"yum-config-manager", "--add-repo", repoURL},
+			// Install Docker
+			{pkgMgr, "install", "-y", "docker-ce", "docker-ce-cli", "containerd.io", "docker-buildx-plugin", "docker-compose-plugin"},
+		}
+	}
+	
+	commands = append(commands,
+		// Start Docker service
+		[]string{"systemctl", "start", "docker"},
+		[]string{"systemctl", "enable", "docker"},
+	)
+	
+	return d.executeCommands(commands)
+}
+
 // executeCommands executes a sequence of commands.
 func (d *DockerInstaller) executeCommands(commands [][]string) error {
 	for _, cmdArgs := range commands {
@@ -265,6 +365,62 @@ func (d *DockerInstaller) CheckDockerImage(ctx context.Context, imageName string
 	return exists, nil
 }
 
+// CheckDockerImageDigest checks if a local Docker image matches a pinned
+// digest, i.e. the image exists and its RepoDigests contain the digest.
+//
+// Parameters:
+//   - ctx: Context for cancellation
+//   - imageName: Docker image name without the digest
+//   - digest: Expected digest (e.g., "sha256:ab12...")
+//
+// Returns:
+//   - true if the local image matches the digest
+//   - error if the check fails
+func (d *DockerInstaller) CheckDockerImageDigest(ctx context.Context, imageName, digest string) (bool, error) {
+	d.sendEvent(fmt.Sprintf("Checking Docker image: %s@%s", imageName, digest))
+	
+	cmd := exec.CommandContext(ctx, "docker", "image", "inspect", "--format", "{{json .RepoDigests}}", imageName)
+	output, err := cmd.Output()
+	if err != nil {
+		if ctx.Err() != nil {
+			return false, fmt.Errorf("operation cancelled")
+		}
+		// docker image inspect fails if the image does not exist
+		d.sendEvent(fmt.Sprintf("Docker image %s not found locally", imageName))
+		return false, nil
+	}
+	
+	var repoDigests []string
+	if err := json.Unmarshal(output, &repoDigests); err != nil {
+		return false, fmt.Errorf("failed to parse image digests: %w", err)
+	}
+	
+	if !config.HasImageDigest(repoDigests, digest) {
+		d.sendEvent(fmt.Sprintf("Docker image %s does not match pinned digest %s", imageName, digest))
+		return false, nil
+	}
+	
+	d.sendEvent(fmt.Sprintf("Docker image %s found locally (digest %s verified)", imageName, digest))
+	return true, nil
+}
+
+// TagDockerImage tags a local Docker image.
+//
+// Parameters:
+//   - ctx: Context for cancellation
+//   - source: Existing image reference
+//   - target: New tag
+//
+// Returns:
+//   - error if tagging fails
+func (d *DockerInstaller) TagDockerImage(ctx context.Context, source, target string) error {
+	output, err := exec.CommandContext(ctx, "docker", "tag", source, target).CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("failed to tag %s as %s: %s", source, target, strings.TrimSpace(string(output)))
+	}
+	return nil
+}
+
 // PullDockerImage pulls a Docker image.
 //
 // This method uses PTY to capture Docker's progress output, including
@@ -307,13 +463,138 @@ func (d *DockerInstaller) PullDockerImage(ctx context.Context, imageName string)
 	return nil
 }
 
+// LoadImageFromTar loads a Docker image archive created by `docker save`.
+//
+// This is the offline alternative to PullDockerImage for hosts without
+// registry access. Output of `docker load -i` is streamed as events.
+//
+// Parameters:
+//   - ctx: Context for cancellation
+//   - tarPath: Path to the image archive
+//
+// Returns:
+//   - error if the archive cannot be read or loading fails
+func (d *DockerInstaller) LoadImageFromTar(ctx context.Context, tarPath string) error {
+	if _, err := os.Stat(tarPath); err != nil {
+		return fmt.Errorf("image archive not accessible: %w", err)
+	}
+	
+	d.sendEvent(fmt.Sprintf("Loading Docker image from archive: %s", tarPath))
+	
+	cmd := exec.CommandContext(ctx, "docker", "load", "-i", tarPath)
+	output, err := cmd.CombinedOutput()
+	for _, line := range strings.Split(string(output), "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			d.sendEvent(line)
+		}
+	}
+	
+	if err != nil {
+		if ctx.Err() != nil {
+			d.sendEvent("Docker load cancelled")
+			return fmt.Errorf("operation cancelled")
+		}
+		return fmt.Errorf("failed to load image archive %s: %w", tarPath, err)
+	}
+	
+	d.sendEvent(fmt.Sprintf("Successfully loaded image archive: %s", tarPath))
+	
+	return nil
+}
+
+// FindImageArchive searches a directory for a `docker save` archive (*.tar)
+// that contains the given image.
+//
+// Archives are matched by the RepoTags in their manifest.json, so no image is
+// loaded during the search. Image names without a tag match ":latest".
+//
+// Parameters:
+//   - dir: Directory containing image archives (e.g., ~/.xw/images)
+//   - imageName: Image reference to look for
+//
+// Returns:
+//   - Path to the matching archive, or empty string if none contains the image
+//   - error if the directory cannot be read
+func (d *DockerInstaller) FindImageArchive(dir, imageName string) (string, error) {
+	archives, err := filepath.Glob(filepath.Join(dir, "*.tar"))
+	if err != nil {
+		return "", err
+	}
+	sort.Strings(archives)
+	
+	want := normalizeImageTag(imageName)
+	for _, archive := range archives {
+		tags, err := readArchiveRepoTags(archive)
+		if err != nil {
+			logger.Debug("Skipping image archive %s: %v", archive, err)
+			continue
+		}
+		for _, tag := range tags {
+			if normalizeImageTag(tag) == want {
+				return archive, nil
+			}
+		}
+	}
+	
+	return "", nil
+}
+
+// readArchiveRepoTags returns the image tags recorded in an archive's manifest.json.
+func readArchiveRepoTags(archivePath string) ([]string, error) {
+	f, err := os.Open(archivePath)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	
+	tr := tar.NewReader(f)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return nil, fmt.Errorf("manifest.json not found")
+		}
+		if err != nil {
+			return nil, err
+		}
+		if hdr.Name != "manifest.json" {
+			continue
+		}
+		
+		var manifest []struct {
+			RepoTags []string `json:"RepoTags"`
+		}
+		if err := json.NewDecoder(tr).Decode(&manifest); err != nil {
+			return nil, fmt.Errorf("invalid manifest.json: %w", err)
+		}
+		
+		var tags []string
+		for _, entry := range manifest {
+			tags = append(tags, entry.RepoTags...)
+		}
+		return tags, nil
+	}
+}
+
+// normalizeImageTag adds the implicit ":latest" tag and strips the implicit
+// Docker Hub prefixes so equivalent references compare equal.
+func normalizeImageTag(ref string) string {
+	ref = strings.TrimPrefix(ref, "docker.io/")
+	ref = strings.TrimPrefix(ref, "library/")
+	
+	// A tag is a colon after the last slash (a colon before it is a registry port)
+	if !strings.Contains(ref[strings.LastIndex(ref, "/")+1:], ":") && !strings.Contains(ref, "@") {
+		ref += ":latest"
+	}
+	return ref
+}
+
 // sendEvent sends a progress event to the event channel.
 func (d *DockerInstaller) sendEvent(message string) {
 	if d.eventCh != nil {
 		select {
 		case d.eventCh <- message:
 		default:
-			// Channel full or closed, log instead
+			// Channel full, log instead
 			logger.Debug("Docker installer event: %s", message)
 		}
 	}
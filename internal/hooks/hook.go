@@ -28,7 +28,8 @@ const (
 	// ModeAuto automatically installs missing dependencies without prompting
 	ModeAuto Mode = "auto"
 	
-	// ModeInteractive prompts the user before installing dependencies
+	// ModeInteractive prompts the user before installing dependencies whose
+	// hook is interactive (see Runner.SetPrompter)
 	ModeInteractive Mode = "interactive"
 	
 	// ModeCheck only checks for dependencies without attempting installation
@@ -68,7 +69,8 @@ type Hook interface {
 // It checks each registered hook and optionally installs missing dependencies
 // based on the configured mode.
 type Runner struct {
-	hooks []Hook
+	hooks    []Hook
+	prompter Prompter // Confirms installations in ModeInteractive
 }
 
 // NewRunner creates a new hook runner with no hooks registered.
@@ -78,6 +80,17 @@ func NewRunner() *Runner {
 	}
 }
 
+// SetPrompter sets the prompter that confirms installations in
+// ModeInteractive. Without one, the user is prompted on the terminal if
+// stdin is a terminal, and installations are declined otherwise.
+//
+// The server passes a prompter that relays the question to the client of
+// the start stream, or DeclinePrompter if the client cannot answer, so that
+// a missing dependency fails instead of waiting for input.
+func (r *Runner) SetPrompter(prompter Prompter) {
+	r.prompter = prompter
+}
+
 // Register adds a hook to the runner.
 //
 // Hooks are executed in registration order. Dependencies should be registered
@@ -90,10 +103,12 @@ func (r *Runner) Register(hook Hook) {
 //
 // The behavior depends on the mode:
 //   - ModeAuto: Automatically installs missing dependencies
-//   - ModeInteractive: Prompts user before installing
+//   - ModeInteractive: Prompts user before installing interactive hooks;
+//     non-interactive hooks are installed without prompting
 //   - ModeCheck: Only checks, never installs
 //
-// Returns an error if any hook fails or if required dependencies are missing.
+// Returns an error if any hook fails, if required dependencies are missing,
+// or if the user declines an installation.
 func (r *Runner) Run(ctx context.Context, mode Mode) error {
 	for _, hook := range r.hooks {
 		// Check if dependency is satisfied
@@ -120,9 +135,21 @@ func (r *Runner) Run(ctx context.Context, mode Mode) error {
 			}
 			
 		case ModeInteractive:
-			// Interactive mode requires user confirmation
-			// This is typically handled by the caller
-			// For now, treat as auto
+			// Interactive hooks require user confirmation
+			if hook.Interactive() {
+				prompter := r.prompter
+				if prompter == nil {
+					prompter = defaultPrompter()
+				}
+				confirmed, err := prompter.Confirm(ctx, hook)
+				if err != nil {
+					return fmt.Errorf("failed to confirm installation of %s: %w", hook.Name(), err)
+				}
+				if !confirmed {
+					return fmt.Errorf("dependency %s is not satisfied and its installation was declined", hook.Name())
+				}
+			}
+			
 			if err := hook.Install(ctx); err != nil {
 				return fmt.Errorf("failed to install %s: %w", hook.Name(), err)
 			}
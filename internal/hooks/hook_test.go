@@ -0,0 +1,124 @@
+package hooks
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+// fakeHook is a missing dependency that is satisfied once installed.
+type fakeHook struct {
+	installed   bool
+	installs    int
+	interactive bool
+}
+
+func (h *fakeHook) Name() string    { return "fake" }
+func (h *fakeHook) Message() string { return "fake is required" }
+
+func (h *fakeHook) Check(ctx context.Context) error {
+	if !h.installed {
+		return errors.New("fake is not installed")
+	}
+	return nil
+}
+
+func (h *fakeHook) Install(ctx context.Context) error {
+	h.installs++
+	h.installed = true
+	return nil
+}
+
+func (h *fakeHook) Interactive() bool { return h.interactive }
+
+// answer returns a prompter giving a fixed answer and counting the prompts.
+func answer(confirmed bool, prompts *int) Prompter {
+	return PromptFunc(func(ctx context.Context, hook Hook) (bool, error) {
+		*prompts++
+		return confirmed, nil
+	})
+}
+
+func TestRunnerModes(t *testing.T) {
+	tests := []struct {
+		name        string
+		mode        Mode
+		interactive bool
+		confirmed   bool
+		wantPrompts int
+		wantInstall bool
+		wantErr     string
+	}{
+		{"interactive accepted", ModeInteractive, true, true, 1, true, ""},
+		{"interactive declined", ModeInteractive, true, false, 1, false, "installation was declined"},
+		{"interactive mode, non-interactive hook", ModeInteractive, false, false, 0, true, ""},
+		{"auto", ModeAuto, true, false, 0, true, ""},
+		{"check", ModeCheck, true, true, 0, false, "is not satisfied"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hook := &fakeHook{interactive: tt.interactive}
+			prompts := 0
+			runner := NewRunner()
+			runner.SetPrompter(answer(tt.confirmed, &prompts))
+			runner.Register(hook)
+
+			err := runner.Run(context.Background(), tt.mode)
+			if tt.wantErr == "" && err != nil {
+				t.Fatalf("Run() failed: %v", err)
+			}
+			if tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)) {
+				t.Fatalf("Run() = %v, want error containing %q", err, tt.wantErr)
+			}
+			if prompts != tt.wantPrompts {
+				t.Errorf("prompted %d times, want %d", prompts, tt.wantPrompts)
+			}
+			if hook.installed != tt.wantInstall {
+				t.Errorf("installed = %v, want %v", hook.installed, tt.wantInstall)
+			}
+		})
+	}
+}
+
+func TestRunnerSkipsSatisfiedHooks(t *testing.T) {
+	hook := &fakeHook{installed: true, interactive: true}
+	prompts := 0
+	runner := NewRunner()
+	runner.SetPrompter(answer(false, &prompts))
+	runner.Register(hook)
+
+	if err := runner.Run(context.Background(), ModeInteractive); err != nil {
+		t.Fatalf("Run() failed: %v", err)
+	}
+	if prompts != 0 || hook.installs != 0 {
+		t.Errorf("prompted %d times and installed %d times, want neither", prompts, hook.installs)
+	}
+}
+
+func TestTerminalPrompterAsk(t *testing.T) {
+	tests := []struct {
+		input string
+		want  bool
+	}{
+		{"y\n", true},
+		{"YES\n", true},
+		{"n\n", false},
+		{"\n", false},
+		{"", false},
+	}
+	for _, tt := range tests {
+		var out strings.Builder
+		prompter := NewTerminalPrompter(strings.NewReader(tt.input), &out)
+		got, err := prompter.Ask(context.Background(), "docker", "Docker is required.")
+		if err != nil {
+			t.Fatalf("Ask(%q) failed: %v", tt.input, err)
+		}
+		if got != tt.want {
+			t.Errorf("Ask(%q) = %v, want %v", tt.input, got, tt.want)
+		}
+		if !strings.Contains(out.String(), "Install docker now? [y/N]") {
+			t.Errorf("prompt = %q, want the install question", out.String())
+		}
+	}
+}
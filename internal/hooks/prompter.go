@@ -0,0 +1,129 @@
+package hooks
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// Prompter asks whether a missing dependency may be installed.
+//
+// The Runner consults its Prompter in ModeInteractive before installing a
+// hook whose Interactive method returns true.
+type Prompter interface {
+	// Confirm returns true if the hook's dependency may be installed.
+	// It returns an error if the answer cannot be obtained (e.g., the
+	// context is cancelled while waiting for input).
+	Confirm(ctx context.Context, hook Hook) (bool, error)
+}
+
+// PromptFunc adapts a function to the Prompter interface.
+type PromptFunc func(ctx context.Context, hook Hook) (bool, error)
+
+// Confirm calls f(ctx, hook).
+func (f PromptFunc) Confirm(ctx context.Context, hook Hook) (bool, error) {
+	return f(ctx, hook)
+}
+
+// DeclinePrompter declines every installation without prompting.
+//
+// It is used where no user can answer, such as in the server or when stdin
+// is not a terminal, so that interactive hooks fail instead of blocking.
+var DeclinePrompter Prompter = PromptFunc(func(ctx context.Context, hook Hook) (bool, error) {
+	return false, nil
+})
+
+// TerminalPrompter prompts the user with the hook's message and reads a
+// y/N answer. Anything but "y" or "yes" declines.
+type TerminalPrompter struct {
+	in  io.Reader
+	out io.Writer
+}
+
+// NewTerminalPrompter creates a prompter reading answers from in and
+// writing prompts to out.
+//
+// Parameters:
+//   - in: Source of the user's answers (e.g., os.Stdin)
+//   - out: Destination of the prompts (e.g., os.Stderr)
+//
+// Returns:
+//   - Prompter instance
+func NewTerminalPrompter(in io.Reader, out io.Writer) *TerminalPrompter {
+	return &TerminalPrompter{in: in, out: out}
+}
+
+// Confirm prints the hook's message and waits for a y/N answer.
+//
+// Parameters:
+//   - ctx: Context for cancellation; cancelling stops waiting for input
+//   - hook: Hook whose dependency is missing
+//
+// Returns:
+//   - true if the user answered yes
+//   - Error if the context is cancelled or input cannot be read
+func (p *TerminalPrompter) Confirm(ctx context.Context, hook Hook) (bool, error) {
+	return p.Ask(ctx, hook.Name(), hook.Message())
+}
+
+// Ask prints a message describing a missing dependency and waits for a
+// y/N answer. It lets clients prompt for dependencies of a remote host,
+// such as the server's, for which they have no Hook.
+//
+// Parameters:
+//   - ctx: Context for cancellation; cancelling stops waiting for input
+//   - name: Name of the missing dependency
+//   - message: Description of what will be installed
+//
+// Returns:
+//   - true if the user answered yes
+//   - Error if the context is cancelled or input cannot be read
+func (p *TerminalPrompter) Ask(ctx context.Context, name, message string) (bool, error) {
+	fmt.Fprintf(p.out, "%s\nInstall %s now? [y/N] ", message, name)
+
+	// Read in the background so that cancellation does not wait for input
+	answerCh := make(chan string, 1)
+	errCh := make(chan error, 1)
+	go func() {
+		answer, err := bufio.NewReader(p.in).ReadString('\n')
+		if err != nil && answer == "" {
+			errCh <- err
+			return
+		}
+		answerCh <- answer
+	}()
+
+	select {
+	case <-ctx.Done():
+		fmt.Fprintln(p.out)
+		return false, ctx.Err()
+	case err := <-errCh:
+		fmt.Fprintln(p.out)
+		if err == io.EOF {
+			return false, nil
+		}
+		return false, fmt.Errorf("failed to read answer: %w", err)
+	case answer := <-answerCh:
+		answer = strings.ToLower(strings.TrimSpace(answer))
+		return answer == "y" || answer == "yes", nil
+	}
+}
+
+// StdinIsTerminal reports whether stdin is a terminal a user can answer
+// prompts on.
+func StdinIsTerminal() bool {
+	info, err := os.Stdin.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+// defaultPrompter returns a terminal prompter on stdin if it is a terminal,
+// and DeclinePrompter otherwise.
+func defaultPrompter() Prompter {
+	if StdinIsTerminal() {
+		return NewTerminalPrompter(os.Stdin, os.Stderr)
+	}
+	return DeclinePrompter
+}
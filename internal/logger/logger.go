@@ -7,6 +7,11 @@
 //   - Global and instance-based loggers
 //   - Thread-safe operations
 //   - Color-coded console output (when appropriate)
+//   - Text or JSON output, selected with SetFormat or the XW_LOG_FORMAT
+//     environment variable ("text" or "json")
+//   - Named component loggers with independent levels, set with
+//     SetComponentLevel or the XW_LOG environment variable
+//     (e.g., XW_LOG=runtime=debug,proxy=warn)
 //
 // Example usage:
 //
@@ -16,6 +21,7 @@
 package logger
 
 import (
+	"encoding/json"
 	"fmt"
 	"io"
 	"log"
@@ -64,6 +70,37 @@ func (l Level) String() string {
 	}
 }
 
+// Format represents the output format of log lines
+type Format int
+
+const (
+	// FormatText writes human-readable lines:
+	// "2006-01-02 15:04:05 [INFO] message"
+	FormatText Format = iota
+
+	// FormatJSON writes one JSON object per line with the fields
+	// "ts", "level", "caller", and "msg", for log shippers such as Loki or ELK
+	FormatJSON
+)
+
+// EnvLogFormat is the environment variable that selects the log format of
+// the global logger at startup ("text" or "json").
+const EnvLogFormat = "XW_LOG_FORMAT"
+
+// ParseFormat converts a string to a log format.
+//
+// Supported values: "text", "json"
+//
+// Returns FormatText if the string is not recognized.
+func ParseFormat(s string) Format {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "json":
+		return FormatJSON
+	default:
+		return FormatText
+	}
+}
+
 // Logger represents a logger instance with configurable output and level
 type Logger struct {
 	mu          sync.Mutex
@@ -72,6 +109,16 @@ type Logger struct {
 	enableDebug bool
 	prefix      string
 	flags       int
+	format      Format
+}
+
+// jsonLine is a log line in FormatJSON.
+type jsonLine struct {
+	TS        string `json:"ts"`
+	Level     string `json:"level"`
+	Component string `json:"component,omitempty"`
+	Caller    string `json:"caller,omitempty"`
+	Msg       string `json:"msg"`
 }
 
 var (
@@ -79,6 +126,19 @@ var (
 	std = New(os.Stderr, "", log.LstdFlags)
 )
 
+// init applies the log format and levels from the environment, so the
+// server and the CLI honor them without extra flags.
+func init() {
+	if v := os.Getenv(EnvLogFormat); v != "" {
+		std.SetFormat(ParseFormat(v))
+	}
+	if v := os.Getenv(EnvLogLevels); v != "" {
+		if err := ApplyLevelSpec(v); err != nil {
+			fmt.Fprintf(os.Stderr, "ignoring invalid %s: %v\n", EnvLogLevels, err)
+		}
+	}
+}
+
 // New creates a new Logger instance.
 //
 // Parameters:
@@ -110,6 +170,13 @@ func (l *Logger) SetLevel(level Level) {
 	l.level = level
 }
 
+// SetFormat sets the output format for this logger.
+func (l *Logger) SetFormat(format Format) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.format = format
+}
+
 // SetDebug enables or disables debug logging.
 //
 // When enabled, DEBUG level messages are printed.
@@ -126,27 +193,72 @@ func (l *Logger) SetDebug(enable bool) {
 //
 // This is the core logging function used by all level-specific functions.
 func (l *Logger) Output(level Level, format string, v ...interface{}) {
+	l.output(3, "", level, format, v...)
+}
+
+// output writes a log message for a component ("" for none).
+//
+// calldepth is the number of stack frames between the caller to report
+// and this function. Components with a level override (see Named) are
+// filtered by that level instead of the logger's own level and debug flag.
+func (l *Logger) output(calldepth int, component string, level Level, format string, v ...interface{}) {
 	l.mu.Lock()
 	defer l.mu.Unlock()
 
-	// Filter by level
-	if level < l.level {
-		return
-	}
+	if override, ok := componentLevel(component); ok {
+		if level < override {
+			return
+		}
+	} else {
+		// Filter by level
+		if level < l.level {
+			return
+		}
 
-	// Skip debug messages if debug is not enabled
-	if level == DebugLevel && !l.enableDebug {
-		return
+		// Skip debug messages if debug is not enabled
+		if level == DebugLevel && !l.enableDebug {
+			return
+		}
 	}
 
 	// Build the log message
-	timestamp := time.Now().Format("2006-01-02 15:04:05")
+	now := time.Now()
 	message := fmt.Sprintf(format, v...)
 
+	// JSON lines always carry the caller so entries can be traced to source
+	if l.format == FormatJSON {
+		line := jsonLine{
+			TS:        now.Format(time.RFC3339Nano),
+			Level:     level.String(),
+			Component: component,
+			Msg:       l.prefix + message,
+		}
+		if _, file, lineNo, ok := runtime.Caller(calldepth); ok {
+			if l.flags&log.Llongfile == 0 {
+				if i := strings.LastIndex(file, "/"); i >= 0 {
+					file = file[i+1:]
+				}
+			}
+			line.Caller = fmt.Sprintf("%s:%d", file, lineNo)
+		}
+		data, err := json.Marshal(line)
+		if err != nil {
+			data = []byte(fmt.Sprintf(`{"ts":%q,"level":%q,"msg":%q}`, line.TS, line.Level, line.Msg))
+		}
+		l.out.Write(append(data, '\n'))
+
+		if level == FatalLevel {
+			os.Exit(1)
+		}
+		return
+	}
+
+	timestamp := now.Format("2006-01-02 15:04:05")
+
 	// Get caller information
 	caller := ""
 	if l.flags&(log.Lshortfile|log.Llongfile) != 0 {
-		_, file, line, ok := runtime.Caller(2)
+		_, file, line, ok := runtime.Caller(calldepth)
 		if ok {
 			if l.flags&log.Lshortfile != 0 {
 				short := file
@@ -162,6 +274,10 @@ func (l *Logger) Output(level Level, format string, v ...interface{}) {
 		}
 	}
 
+	if component != "" {
+		caller += " [" + component + "]"
+	}
+
 	// Format the complete log line
 	logLine := fmt.Sprintf("%s [%s]%s %s%s\n",
 		timestamp,
@@ -233,6 +349,11 @@ func SetLevel(level Level) {
 	std.SetLevel(level)
 }
 
+// SetFormat sets the output format for the global logger
+func SetFormat(format Format) {
+	std.SetFormat(format)
+}
+
 // SetDebug enables or disables debug mode for the global logger
 func SetDebug(enable bool) {
 	std.SetDebug(enable)
@@ -0,0 +1,142 @@
+package logger
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// EnvLogLevels is the environment variable that sets log levels at startup.
+//
+// It holds comma-separated component=level pairs, e.g.
+// "runtime=debug,proxy=warn". An entry without a component sets the
+// global level, e.g. "debug" or "warn,runtime=debug".
+const EnvLogLevels = "XW_LOG"
+
+var (
+	componentMu     sync.RWMutex
+	componentLevels = make(map[string]Level) // component name → level override
+)
+
+// NamedLogger logs messages for a named component through the global logger.
+//
+// Its level can be set independently with SetComponentLevel; when unset,
+// the global level applies. Output, format, and debug settings are shared
+// with the global logger.
+type NamedLogger struct {
+	name string
+}
+
+// Named returns a logger for the named component.
+//
+// Packages typically create one at package level:
+//
+//	var log = logger.Named("runtime")
+func Named(name string) *NamedLogger {
+	return &NamedLogger{name: name}
+}
+
+// Name returns the component name.
+func (n *NamedLogger) Name() string {
+	return n.name
+}
+
+// Debug logs a debug message for the component.
+func (n *NamedLogger) Debug(format string, v ...interface{}) {
+	std.output(2, n.name, DebugLevel, format, v...)
+}
+
+// Info logs an informational message for the component.
+func (n *NamedLogger) Info(format string, v ...interface{}) {
+	std.output(2, n.name, InfoLevel, format, v...)
+}
+
+// Warn logs a warning message for the component.
+func (n *NamedLogger) Warn(format string, v ...interface{}) {
+	std.output(2, n.name, WarnLevel, format, v...)
+}
+
+// Error logs an error message for the component.
+func (n *NamedLogger) Error(format string, v ...interface{}) {
+	std.output(2, n.name, ErrorLevel, format, v...)
+}
+
+// Fatal logs a fatal error message for the component and terminates the program.
+func (n *NamedLogger) Fatal(format string, v ...interface{}) {
+	std.output(2, n.name, FatalLevel, format, v...)
+}
+
+// SetComponentLevel sets the minimum log level for a named component,
+// overriding the global level for its messages.
+func SetComponentLevel(name string, level Level) {
+	componentMu.Lock()
+	defer componentMu.Unlock()
+	componentLevels[name] = level
+}
+
+// ClearComponentLevel removes the level override of a named component, so
+// the global level applies again.
+func ClearComponentLevel(name string) {
+	componentMu.Lock()
+	defer componentMu.Unlock()
+	delete(componentLevels, name)
+}
+
+// componentLevel returns the level override of a component, if any.
+func componentLevel(name string) (Level, bool) {
+	if name == "" {
+		return 0, false
+	}
+	componentMu.RLock()
+	defer componentMu.RUnlock()
+	level, ok := componentLevels[name]
+	return level, ok
+}
+
+// ApplyLevelSpec applies a level specification in the EnvLogLevels format.
+//
+// Example:
+//
+//	logger.ApplyLevelSpec("info,runtime=debug,proxy=warn")
+//
+// Returns an error naming the first malformed entry; entries before it
+// have already been applied.
+func ApplyLevelSpec(spec string) error {
+	for _, entry := range strings.Split(spec, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		name, levelStr, hasName := strings.Cut(entry, "=")
+		if !hasName {
+			levelStr, name = name, ""
+		}
+		name = strings.TrimSpace(name)
+
+		level, ok := parseLevelStrict(levelStr)
+		if !ok || (hasName && name == "") {
+			return fmt.Errorf("invalid log level entry %q", entry)
+		}
+
+		if name == "" {
+			std.SetLevel(level)
+			std.SetDebug(level == DebugLevel)
+			continue
+		}
+		SetComponentLevel(name, level)
+	}
+	return nil
+}
+
+// parseLevelStrict converts a string to a log level, reporting whether the
+// string was recognized (unlike ParseLevel, which defaults to InfoLevel).
+func parseLevelStrict(s string) (Level, bool) {
+	s = strings.ToLower(strings.TrimSpace(s))
+	switch s {
+	case "debug", "info", "warn", "warning", "error", "fatal":
+		return ParseLevel(s), true
+	default:
+		return InfoLevel, false
+	}
+}
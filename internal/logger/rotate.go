@@ -0,0 +1,138 @@
+package logger
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// rotatingFile is a log file that is rotated when it exceeds a size limit.
+//
+// When a write would grow the file beyond maxBytes, the file is renamed to
+// path.1, existing backups are shifted (path.1 → path.2, ...), and a new
+// file is opened. At most keep backups are retained.
+//
+// rotatingFile is not safe for concurrent use on its own; the Logger
+// serializes all writes under its mutex.
+type rotatingFile struct {
+	path     string
+	maxBytes int64
+	keep     int
+	file     *os.File
+	size     int64
+}
+
+// openRotatingFile opens (or creates) the log file at path for appending.
+func openRotatingFile(path string, maxBytes int64, keep int) (*rotatingFile, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, fmt.Errorf("failed to create log directory: %w", err)
+	}
+
+	rf := &rotatingFile{path: path, maxBytes: maxBytes, keep: keep}
+	if err := rf.open(); err != nil {
+		return nil, err
+	}
+	return rf, nil
+}
+
+// open opens the current log file and records its size.
+func (rf *rotatingFile) open() error {
+	f, err := os.OpenFile(rf.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to open log file: %w", err)
+	}
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return fmt.Errorf("failed to stat log file: %w", err)
+	}
+	rf.file = f
+	rf.size = info.Size()
+	return nil
+}
+
+// Write writes p to the log file, rotating first if p would exceed the
+// size limit. A single write larger than the limit is still written whole.
+func (rf *rotatingFile) Write(p []byte) (int, error) {
+	if rf.size > 0 && rf.size+int64(len(p)) > rf.maxBytes {
+		if err := rf.rotate(); err != nil {
+			// Keep logging to the current file rather than losing messages
+			fmt.Fprintf(os.Stderr, "log rotation failed: %v\n", err)
+		}
+	}
+
+	n, err := rf.file.Write(p)
+	rf.size += int64(n)
+	return n, err
+}
+
+// rotate shifts the backups, moves the current file to path.1, and opens
+// a new file.
+func (rf *rotatingFile) rotate() error {
+	if err := rf.file.Close(); err != nil {
+		return err
+	}
+
+	if rf.keep > 0 {
+		os.Remove(rf.backupPath(rf.keep))
+		for i := rf.keep - 1; i >= 1; i-- {
+			os.Rename(rf.backupPath(i), rf.backupPath(i+1))
+		}
+		if err := os.Rename(rf.path, rf.backupPath(1)); err != nil {
+			rf.open()
+			return err
+		}
+	} else if err := os.Remove(rf.path); err != nil {
+		rf.open()
+		return err
+	}
+
+	return rf.open()
+}
+
+// backupPath returns the path of the n-th backup file.
+func (rf *rotatingFile) backupPath(n int) string {
+	return fmt.Sprintf("%s.%d", rf.path, n)
+}
+
+// Close closes the current log file.
+func (rf *rotatingFile) Close() error {
+	return rf.file.Close()
+}
+
+// SetOutputFile directs this logger's output to a file with size-based
+// rotation, replacing the current output.
+//
+// Parameters:
+//   - path: Log file path (parent directories are created)
+//   - maxMB: Size in megabytes at which the file is rotated
+//   - keep: Number of rotated files to keep (path.1 ... path.keep)
+//
+// Returns:
+//   - Error if the file cannot be opened
+func (l *Logger) SetOutputFile(path string, maxMB, keep int) error {
+	if maxMB <= 0 {
+		return fmt.Errorf("log file size limit must be positive, got %d MB", maxMB)
+	}
+	if keep < 0 {
+		return fmt.Errorf("number of rotated log files must not be negative, got %d", keep)
+	}
+
+	rf, err := openRotatingFile(path, int64(maxMB)*1024*1024, keep)
+	if err != nil {
+		return err
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if previous, ok := l.out.(*rotatingFile); ok {
+		previous.Close()
+	}
+	l.out = rf
+	return nil
+}
+
+// SetOutputFile directs the global logger's output to a rotating log file
+func SetOutputFile(path string, maxMB, keep int) error {
+	return std.SetOutputFile(path, maxMB, keep)
+}
@@ -47,9 +47,16 @@ func LoadModelsFromConfig(configPath string) ([]ModelSpec, error) {
 	var specs []ModelSpec
 	
 	for _, model := range modConfig.Models {
+		// Models without an explicit source come from ModelScope
+		source := model.Source
+		if source == "" {
+			source = config.ModelSourceModelScope
+		}
+		
 		spec := ModelSpec{
 			ID:               model.ModelID,
 			SourceID:         model.SourceID,
+			Source:           source,
 			Parameters:       model.Parameters,
 			ContextLength:    model.ContextLength,
 			Tag:              model.Tag,
@@ -87,6 +94,32 @@ func LoadModelsFromConfig(configPath string) ([]ModelSpec, error) {
 	return specs, nil
 }
 
+// GetModelGroup returns the members of a model group defined under
+// model_groups in the loaded model configuration.
+//
+// Parameters:
+//   - name: Group name (e.g., "qwen2")
+//
+// Returns:
+//   - Model IDs of the group's members, in configuration order
+//   - Error if the configuration is not loaded or the group is not defined
+func GetModelGroup(name string) ([]string, error) {
+	modConfig, err := config.GetModelsConfig()
+	if err != nil {
+		return nil, err
+	}
+	
+	members, ok := modConfig.ModelGroups[name]
+	if !ok {
+		groups := config.GetModelGroupNames(modConfig)
+		if len(groups) == 0 {
+			return nil, fmt.Errorf("model group %s not found: no model groups are defined", name)
+		}
+		return nil, fmt.Errorf("model group %s not found (available: %s)", name, strings.Join(groups, ", "))
+	}
+	return members, nil
+}
+
 // parseEngine parses engine string in format "backend:mode" (e.g., "vllm:docker")
 func parseEngine(engine string) (BackendOption, error) {
 	parts := strings.SplitN(engine, ":", 2)
@@ -0,0 +1,131 @@
+package models
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/tsingmaoai/xw-cli/internal/logger"
+)
+
+// DerivedMarkerFile marks the directory of a model created with 'xw cp'.
+// It holds the ID of the base model, so derived models are registered
+// again when the server starts.
+const DerivedMarkerFile = ".derived_from"
+
+// modelIDPattern is the form of model IDs chosen by users for derived
+// models: lower-case letters, digits, dots, dashes and underscores, as in
+// the IDs of models.yaml.
+var modelIDPattern = regexp.MustCompile(`^[a-z0-9][a-z0-9._-]*$`)
+
+// ValidateModelID checks that a user-chosen model ID is well-formed and
+// not taken by a registered or derived model.
+//
+// Parameters:
+//   - id: New model ID
+//
+// Returns:
+//   - Error describing why the ID cannot be used
+func ValidateModelID(id string) error {
+	if !modelIDPattern.MatchString(id) {
+		return fmt.Errorf("invalid model name %q: use lower-case letters, digits, '.', '-' and '_'", id)
+	}
+	if GetModelSpec(id) != nil {
+		return fmt.Errorf("model %s already exists", id)
+	}
+	return nil
+}
+
+// RegisterDerivedModel registers a model that serves the weights of a base
+// model with its own Modelfile.
+//
+// The derived model shares the base model's specification (devices,
+// engines, capabilities) under its own ID. A derived model cannot be the
+// base of another one; copying a derived model derives from its base.
+//
+// Parameters:
+//   - id: Derived model ID
+//   - baseID: Base model ID
+//
+// Returns:
+//   - Error if the base model is not registered
+func RegisterDerivedModel(id, baseID string) error {
+	base := GetModelSpec(baseID)
+	if base == nil {
+		return fmt.Errorf("base model %s not found", baseID)
+	}
+	baseID = base.TemplateModelID()
+
+	defaultRegistry.mu.Lock()
+	defer defaultRegistry.mu.Unlock()
+
+	if defaultRegistry.derived == nil {
+		defaultRegistry.derived = make(map[string]string)
+	}
+	defaultRegistry.derived[id] = baseID
+	return nil
+}
+
+// UnregisterDerivedModel removes a model created with 'xw cp' from the
+// registry. Its directory is left to the caller.
+//
+// Parameters:
+//   - id: Derived model ID
+//
+// Returns:
+//   - Error if id is not a derived model
+func UnregisterDerivedModel(id string) error {
+	defaultRegistry.mu.Lock()
+	defer defaultRegistry.mu.Unlock()
+
+	if _, ok := defaultRegistry.derived[id]; !ok {
+		return fmt.Errorf("model %s was not created with 'xw cp'", id)
+	}
+	delete(defaultRegistry.derived, id)
+	return nil
+}
+
+// LoadDerivedModels registers the derived models found in the models
+// directory (models/{model_id}/latest with a DerivedMarkerFile).
+//
+// Parameters:
+//   - modelsDir: Models directory
+//
+// Returns:
+//   - Number of derived models registered
+func LoadDerivedModels(modelsDir string) int {
+	markers, err := filepath.Glob(filepath.Join(modelsDir, "*", "latest", DerivedMarkerFile))
+	if err != nil {
+		return 0
+	}
+
+	count := 0
+	for _, marker := range markers {
+		data, err := os.ReadFile(marker)
+		if err != nil {
+			continue
+		}
+		id := filepath.Base(filepath.Dir(filepath.Dir(marker)))
+		baseID := strings.TrimSpace(string(data))
+		if err := RegisterDerivedModel(id, baseID); err != nil {
+			logger.Warn("Skipping derived model %s: %v", id, err)
+			continue
+		}
+		count++
+	}
+	if count > 0 {
+		logger.Info("Registered %d derived model(s)", count)
+	}
+	return count
+}
+
+// derivedSpec returns the specification of a derived model: a copy of its
+// base model's spec under the derived ID.
+func derivedSpec(base *ModelSpec, id string) *ModelSpec {
+	spec := *base
+	spec.ID = id
+	spec.DerivedFrom = base.ID
+	return &spec
+}
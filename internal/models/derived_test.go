@@ -0,0 +1,117 @@
+package models
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/tsingmaoai/xw-cli/internal/api"
+)
+
+// registerTestBase registers a base model spec supported on device, and
+// removes it and the models derived from it when the test ends.
+func registerTestBase(t *testing.T, id string, device api.DeviceType) {
+	t.Helper()
+
+	RegisterModelSpec(&ModelSpec{
+		ID:           id,
+		Capabilities: []string{"chat"},
+		SupportedDevices: map[api.DeviceType][]BackendOption{
+			device: {{Type: api.BackendTypeVLLM, Mode: api.DeploymentModeDocker}},
+		},
+	})
+	t.Cleanup(func() {
+		defaultRegistry.mu.Lock()
+		defer defaultRegistry.mu.Unlock()
+		delete(defaultRegistry.specs, id)
+		delete(defaultRegistry.models, id)
+		for derivedID, baseID := range defaultRegistry.derived {
+			if baseID == id {
+				delete(defaultRegistry.derived, derivedID)
+			}
+		}
+	})
+}
+
+// listed reports whether a model named name is in models.
+func listed(models []api.Model, name string) bool {
+	for _, m := range models {
+		if m.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+func TestDerivedModelListedAndUnregistered(t *testing.T) {
+	const device = api.DeviceType("test-npu")
+	registerTestBase(t, "test-base-8b", device)
+
+	if err := RegisterDerivedModel("test-base-8b-translator", "test-base-8b"); err != nil {
+		t.Fatalf("RegisterDerivedModel() failed: %v", err)
+	}
+
+	r := GetDefaultRegistry()
+	if !listed(r.List(api.DeviceTypeAll, true), "test-base-8b-translator") {
+		t.Error("List() does not include the derived model")
+	}
+	available := r.ListAvailableModels([]api.DeviceType{device})
+	if !listed(available, "test-base-8b-translator") {
+		t.Error("ListAvailableModels() does not include the derived model")
+	}
+	if got := r.CountAvailableModels([]api.DeviceType{device}); got != len(available) {
+		t.Errorf("CountAvailableModels() = %d, want %d", got, len(available))
+	}
+	for _, m := range available {
+		if m.Name == "test-base-8b-translator" && (len(m.Capabilities) != 1 || m.Capabilities[0] != "chat") {
+			t.Errorf("derived model capabilities = %v, want the base model's", m.Capabilities)
+		}
+	}
+
+	if err := UnregisterDerivedModel("test-base-8b-translator"); err != nil {
+		t.Fatalf("UnregisterDerivedModel() failed: %v", err)
+	}
+	if GetModelSpec("test-base-8b-translator") != nil {
+		t.Error("GetModelSpec() finds the unregistered model")
+	}
+	if listed(r.List(api.DeviceTypeAll, true), "test-base-8b-translator") {
+		t.Error("List() includes the unregistered model")
+	}
+
+	// Only derived models can be unregistered
+	if err := UnregisterDerivedModel("test-base-8b"); err == nil {
+		t.Error("UnregisterDerivedModel() of a base model succeeded")
+	}
+	if !listed(r.List(api.DeviceTypeAll, true), "test-base-8b") {
+		t.Error("List() lost the base model")
+	}
+}
+
+func TestLoadDerivedModels(t *testing.T) {
+	registerTestBase(t, "test-base-8b", api.DeviceType("test-npu"))
+
+	modelsDir := t.TempDir()
+	for id, baseID := range map[string]string{
+		"test-base-8b-translator": "test-base-8b",
+		"orphan":                  "missing-base",
+	} {
+		dir := filepath.Join(modelsDir, id, "latest")
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, DerivedMarkerFile), []byte(baseID+"\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if got := LoadDerivedModels(modelsDir); got != 1 {
+		t.Errorf("LoadDerivedModels() = %d, want 1", got)
+	}
+	spec := GetModelSpec("test-base-8b-translator")
+	if spec == nil || spec.DerivedFrom != "test-base-8b" {
+		t.Fatalf("GetModelSpec() = %+v, want a spec derived from test-base-8b", spec)
+	}
+	if GetModelSpec("orphan") != nil {
+		t.Error("model with a missing base was registered")
+	}
+}
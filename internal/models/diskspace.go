@@ -0,0 +1,101 @@
+package models
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"syscall"
+)
+
+const (
+	// DiskSpaceHeadroom is the extra free space required on top of the
+	// estimated download size, covering temporary part files, generated
+	// configuration files, and filesystem overhead.
+	DiskSpaceHeadroom int64 = 2 * 1024 * 1024 * 1024
+
+	// bytesPerParameter is used to estimate a model's on-disk size from its
+	// declared parameter count. Registered models ship BF16/FP16 weights.
+	bytesPerParameter = 2
+)
+
+// InsufficientSpaceError reports that a filesystem does not have enough
+// free space for a download.
+type InsufficientSpaceError struct {
+	Path      string // Directory that was checked
+	Required  int64  // Bytes required, including headroom
+	Available int64  // Bytes currently free
+}
+
+// Error implements the error interface.
+func (e *InsufficientSpaceError) Error() string {
+	return fmt.Sprintf("insufficient disk space in %s: %s required, %s available",
+		e.Path, FormatBytes(e.Required), FormatBytes(e.Available))
+}
+
+// FreeDiskSpace returns the number of bytes available to unprivileged users
+// on the filesystem containing path.
+//
+// If path does not exist yet, the nearest existing parent directory is
+// checked instead, since that is where the path would be created.
+func FreeDiskSpace(path string) (int64, error) {
+	dir := filepath.Clean(path)
+	for {
+		if _, err := os.Stat(dir); err == nil {
+			break
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			break
+		}
+		dir = parent
+	}
+
+	var stat syscall.Statfs_t
+	if err := syscall.Statfs(dir, &stat); err != nil {
+		return 0, fmt.Errorf("failed to stat filesystem of %s: %w", dir, err)
+	}
+	return int64(stat.Bavail) * int64(stat.Bsize), nil
+}
+
+// CheckDiskSpace verifies that the filesystem containing path has at least
+// required bytes plus DiskSpaceHeadroom free.
+//
+// Returns:
+//   - nil if there is enough space
+//   - *InsufficientSpaceError if there is not
+//   - Other errors if free space cannot be determined
+func CheckDiskSpace(path string, required int64) error {
+	available, err := FreeDiskSpace(path)
+	if err != nil {
+		return err
+	}
+
+	required += DiskSpaceHeadroom
+	if available < required {
+		return &InsufficientSpaceError{Path: path, Required: required, Available: available}
+	}
+	return nil
+}
+
+// EstimatedSize estimates the on-disk size of the model's weights from its
+// declared parameter count. Returns 0 if the parameter count is unknown.
+func (s *ModelSpec) EstimatedSize() int64 {
+	if s == nil || s.Parameters <= 0 {
+		return 0
+	}
+	return int64(s.Parameters * 1e9 * bytesPerParameter)
+}
+
+// FormatBytes formats a byte count for display (e.g., "12.3 GB").
+func FormatBytes(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := int64(unit), 0
+	for v := n / unit; v >= unit; v /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %cB", float64(n)/float64(div), "KMGTPE"[exp])
+}
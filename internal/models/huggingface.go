@@ -0,0 +1,205 @@
+// Package models - huggingface.go adds HuggingFace Hub as a model source.
+//
+// HuggingFace downloads reuse the ModelScope Client (locking, resumable
+// transfers, progress reporting and SHA256 validation); this file only
+// provides the HuggingFace file listing and download URLs.
+//
+// Environment variables:
+//   - HF_TOKEN: Access token for gated or private repositories
+//   - HF_ENDPOINT: Alternative hub endpoint (e.g., a mirror such as https://hf-mirror.com)
+package models
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"regexp"
+	"strings"
+
+	"github.com/tsingmaoai/xw-cli/internal/config"
+)
+
+const (
+	// DefaultHuggingFaceEndpoint is the default HuggingFace Hub endpoint
+	DefaultHuggingFaceEndpoint = "https://huggingface.co"
+
+	// DefaultHuggingFaceRevision is the branch downloaded from HuggingFace repositories
+	DefaultHuggingFaceRevision = "main"
+
+	// EnvHuggingFaceToken names the environment variable holding the
+	// HuggingFace access token. It uses the name shared by HuggingFace tools
+	// so an existing setup works without extra configuration.
+	EnvHuggingFaceToken = "HF_TOKEN"
+
+	// EnvHuggingFaceEndpoint names the environment variable overriding the
+	// HuggingFace Hub endpoint.
+	EnvHuggingFaceEndpoint = "HF_ENDPOINT"
+)
+
+// Downloader downloads a model repository into the local model cache.
+//
+// Implementations stream progress through the ProgressFunc and return the
+// local model directory, cacheDir/{userModelID}/{tag}.
+type Downloader interface {
+	DownloadModel(ctx context.Context, sourceID, userModelID, tag, cacheDir string, progress ProgressFunc) (string, error)
+}
+
+// DownloadOptions configures a Downloader created by NewDownloader.
+type DownloadOptions struct {
+	// Revision is the branch, tag, or commit to download; empty selects the
+	// source's default branch (see DefaultRevision)
+	Revision string
+
+	// Jobs is the number of files downloaded concurrently; values below 1
+	// select DefaultDownloadJobs and values above MaxDownloadJobs are capped
+	Jobs int
+
+	// OnFileStart, if set, is called with the file's path in the repository
+	// when a file starts downloading. With concurrent downloads it is called
+	// from several goroutines.
+	OnFileStart func(name string)
+
+	// OnProgress, if set, receives the aggregate progress of the download
+	// (overall bytes, recent throughput, and ETA) about once per second.
+	OnProgress func(DownloadProgress)
+}
+
+// NewDownloader returns the downloader for a model source.
+//
+// Parameters:
+//   - source: Model source from the model configuration ("modelscope" or
+//     "huggingface"); empty selects ModelScope
+//   - opts: Revision and concurrency settings
+//
+// Returns:
+//   - Downloader for the source
+//   - Error if the source is not supported
+func NewDownloader(source string, opts DownloadOptions) (Downloader, error) {
+	var c *Client
+	switch source {
+	case "", config.ModelSourceModelScope:
+		c = NewClient()
+	case config.ModelSourceHuggingFace:
+		c = NewHuggingFaceClient()
+	default:
+		return nil, fmt.Errorf("unsupported model source: %s", source)
+	}
+	if opts.Revision != "" {
+		c.revision = opts.Revision
+	}
+	if opts.Jobs > 0 {
+		c.jobs = min(opts.Jobs, MaxDownloadJobs)
+	}
+	c.onFileStart = opts.OnFileStart
+	c.onProgress = opts.OnProgress
+	return c, nil
+}
+
+// DefaultRevision returns the branch downloaded from a model source when
+// no revision is requested.
+func DefaultRevision(source string) string {
+	if source == config.ModelSourceHuggingFace {
+		return DefaultHuggingFaceRevision
+	}
+	return DefaultModelScopeRevision
+}
+
+// NewHuggingFaceClient creates a client that downloads from HuggingFace Hub.
+//
+// The endpoint and access token are read from HF_ENDPOINT and HF_TOKEN.
+func NewHuggingFaceClient() *Client {
+	c := NewClient()
+	c.source = config.ModelSourceHuggingFace
+	c.endpoint = DefaultHuggingFaceEndpoint
+	c.revision = DefaultHuggingFaceRevision
+	if endpoint := strings.TrimSpace(os.Getenv(EnvHuggingFaceEndpoint)); endpoint != "" {
+		c.endpoint = strings.TrimRight(endpoint, "/")
+	}
+	c.token = strings.TrimSpace(os.Getenv(EnvHuggingFaceToken))
+	return c
+}
+
+// huggingFaceFileURL returns the resolve URL of a file in a HuggingFace repository.
+func (c *Client) huggingFaceFileURL(modelID, filePath string) string {
+	segments := strings.Split(filePath, "/")
+	for i, segment := range segments {
+		segments[i] = url.PathEscape(segment)
+	}
+	return fmt.Sprintf("%s/%s/resolve/%s/%s",
+		c.endpoint, modelID, url.PathEscape(c.revision), strings.Join(segments, "/"))
+}
+
+// nextLinkPattern extracts the next page URL from a Link response header.
+var nextLinkPattern = regexp.MustCompile(`<([^>]+)>;\s*rel="next"`)
+
+// getHuggingFaceFiles lists the files of a HuggingFace repository.
+//
+// The tree API is paginated; pages are followed via the Link header. Only
+// LFS files carry a SHA256 (small files are identified by their git blob
+// hash), so integrity validation applies to the LFS weight files.
+func (c *Client) getHuggingFaceFiles(ctx context.Context, modelID string) ([]FileInfo, error) {
+	pageURL := fmt.Sprintf("%s/api/models/%s/tree/%s?recursive=true",
+		c.endpoint, modelID, url.PathEscape(c.revision))
+
+	files := make([]FileInfo, 0)
+	for pageURL != "" {
+		req, err := c.newRequest(ctx, pageURL)
+		if err != nil {
+			return nil, err
+		}
+
+		resp, err := c.httpClient.Do(req)
+		if err != nil {
+			return nil, err
+		}
+
+		if resp.StatusCode != http.StatusOK {
+			body, _ := io.ReadAll(resp.Body)
+			resp.Body.Close()
+			if (resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden) && c.token == "" {
+				return nil, fmt.Errorf("API returned status %d: %s (gated or private repository, set %s to an access token)",
+					resp.StatusCode, strings.TrimSpace(string(body)), EnvHuggingFaceToken)
+			}
+			return nil, fmt.Errorf("API returned status %d: %s", resp.StatusCode, string(body))
+		}
+
+		// HuggingFace tree API returns [{type, path, size, lfs: {oid, size}}, ...]
+		var entries []struct {
+			Type string `json:"type"`
+			Path string `json:"path"`
+			Size int64  `json:"size"`
+			LFS  *struct {
+				Oid  string `json:"oid"` // SHA256 of the file content
+				Size int64  `json:"size"`
+			} `json:"lfs"`
+		}
+		err = json.NewDecoder(resp.Body).Decode(&entries)
+		resp.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse API response: %w", err)
+		}
+
+		for _, e := range entries {
+			if e.Type != "file" {
+				continue // Skip directories
+			}
+			file := FileInfo{Name: e.Path, Size: e.Size}
+			if e.LFS != nil {
+				file.Size = e.LFS.Size
+				file.Sha256 = e.LFS.Oid
+			}
+			files = append(files, file)
+		}
+
+		pageURL = ""
+		if m := nextLinkPattern.FindStringSubmatch(resp.Header.Get("Link")); m != nil {
+			pageURL = m[1]
+		}
+	}
+
+	return files, nil
+}
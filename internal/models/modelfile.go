@@ -0,0 +1,354 @@
+package models
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ModelfileDirective is a directive parsed from a Modelfile together with
+// the lines it spans, so callers can rewrite it in place.
+type ModelfileDirective struct {
+	Name  string // Directive name in upper case (FROM, SYSTEM, PARAMETER, ...)
+	Args  string // Arguments with surrounding quotes removed
+	Start int    // Index of the first line
+	End   int    // Index of the last line (inclusive)
+}
+
+// ModelfileParameter is a single PARAMETER directive.
+//
+// Value keeps the text as written so that unchanged parameters round-trip
+// byte for byte (e.g., "0.80" is not rewritten as "0.8"); use TypedValue
+// for its numeric or boolean meaning.
+type ModelfileParameter struct {
+	Key   string
+	Value string
+}
+
+// parameterKind is the value type of a known PARAMETER name.
+type parameterKind int
+
+const (
+	parameterInt parameterKind = iota + 1
+	parameterFloat
+	parameterBool
+	parameterString
+)
+
+// modelfileParameterKinds lists the value types of the PARAMETER names
+// known from Ollama. Values of other parameters are typed by their text.
+var modelfileParameterKinds = map[string]parameterKind{
+	"num_ctx":           parameterInt,
+	"num_batch":         parameterInt,
+	"num_gpu":           parameterInt,
+	"num_thread":        parameterInt,
+	"num_keep":          parameterInt,
+	"num_predict":       parameterInt,
+	"repeat_last_n":     parameterInt,
+	"top_k":             parameterInt,
+	"seed":              parameterInt,
+	"mirostat":          parameterInt,
+	"temperature":       parameterFloat,
+	"top_p":             parameterFloat,
+	"min_p":             parameterFloat,
+	"typical_p":         parameterFloat,
+	"tfs_z":             parameterFloat,
+	"repeat_penalty":    parameterFloat,
+	"presence_penalty":  parameterFloat,
+	"frequency_penalty": parameterFloat,
+	"mirostat_tau":      parameterFloat,
+	"mirostat_eta":      parameterFloat,
+	"use_mmap":          parameterBool,
+	"use_mlock":         parameterBool,
+	"numa":              parameterBool,
+	"stop":              parameterString,
+}
+
+// TypedValue interprets the parameter value according to its key.
+//
+// Known parameters get the type Ollama gives them, so a stop sequence such
+// as "10" or "true" stays a string and temperature 1 is a float. A value
+// that does not parse as the key's type is returned as written, for the
+// caller to reject. Unknown parameters are typed by their text.
+//
+// Returns:
+//   - int for integers (e.g., top_k 20)
+//   - float64 for other numbers (e.g., temperature 0.7)
+//   - bool for "true" and "false"
+//   - string otherwise (e.g., stop sequences)
+func (p ModelfileParameter) TypedValue() interface{} {
+	switch modelfileParameterKinds[p.Key] {
+	case parameterInt:
+		if n, err := strconv.Atoi(p.Value); err == nil {
+			return n
+		}
+		return p.Value
+	case parameterFloat:
+		if f, err := strconv.ParseFloat(p.Value, 64); err == nil {
+			return f
+		}
+		return p.Value
+	case parameterBool:
+		if b, err := strconv.ParseBool(p.Value); err == nil {
+			return b
+		}
+		return p.Value
+	case parameterString:
+		return p.Value
+	}
+
+	if n, err := strconv.Atoi(p.Value); err == nil {
+		return n
+	}
+	if f, err := strconv.ParseFloat(p.Value, 64); err == nil {
+		return f
+	}
+	if p.Value == "true" || p.Value == "false" {
+		return p.Value == "true"
+	}
+	return p.Value
+}
+
+// ModelfileParameters returns the typed PARAMETER values of a Modelfile.
+//
+// A key that appears once maps to its TypedValue. A key that appears
+// several times, such as stop, maps to a []interface{} of all its values
+// in file order.
+//
+// Parameters:
+//   - content: Modelfile content
+//
+// Returns:
+//   - Parameter values keyed by name (empty if there are none)
+func ModelfileParameters(content string) map[string]interface{} {
+	params := make(map[string]interface{})
+
+	for _, d := range ParseModelfileDirectives(content) {
+		if d.Name != "PARAMETER" {
+			continue
+		}
+		p, ok := ParseModelfileParameter(d.Args)
+		if !ok {
+			continue
+		}
+		switch existing := params[p.Key].(type) {
+		case nil:
+			params[p.Key] = p.TypedValue()
+		case []interface{}:
+			params[p.Key] = append(existing, p.TypedValue())
+		default:
+			params[p.Key] = []interface{}{existing, p.TypedValue()}
+		}
+	}
+
+	return params
+}
+
+// ParseModelfileDirectives splits a Modelfile into directives.
+//
+// Modelfiles use the Ollama directive format: each directive is a keyword
+// (FROM, TEMPLATE, SYSTEM, PARAMETER, LICENSE, ...) followed by its
+// arguments. Arguments may be bare, enclosed in double quotes, or enclosed
+// in triple quotes, which may span several lines. For a multi-line value,
+// the line break right after the opening quotes and right before the closing
+// quotes is not part of the value, and \"\"\" stands for a triple quote
+// inside it. Blank lines and comments are skipped, and lines inside a
+// triple-quoted value are never read as directives.
+//
+// This is the single parser for Modelfiles; the server and CLI both use it
+// so that what "xw edit" writes is what "xw show" reads.
+//
+// Parameters:
+//   - content: Modelfile content
+//
+// Returns:
+//   - Directives in file order
+func ParseModelfileDirectives(content string) []ModelfileDirective {
+	lines := strings.Split(content, "\n")
+	var directives []ModelfileDirective
+
+	for i := 0; i < len(lines); i++ {
+		trimmed := strings.TrimSpace(lines[i])
+		if trimmed == "" || strings.HasPrefix(trimmed, "#") {
+			continue
+		}
+
+		name, rest, _ := strings.Cut(trimmed, " ")
+		rest = strings.TrimSpace(rest)
+		d := ModelfileDirective{Name: strings.ToUpper(name), Start: i, End: i}
+
+		switch {
+		case strings.HasPrefix(rest, `"""`):
+			body := strings.TrimPrefix(rest, `"""`)
+			if idx := closingTripleQuote(body); idx >= 0 {
+				d.Args = unescapeTripleQuotes(body[:idx])
+				break
+			}
+			var parts []string
+			if body != "" {
+				parts = append(parts, body)
+			}
+			for j := i + 1; j < len(lines); j++ {
+				d.End = j
+				if idx := closingTripleQuote(lines[j]); idx >= 0 {
+					if ending := lines[j][:idx]; ending != "" {
+						parts = append(parts, ending)
+					}
+					break
+				}
+				parts = append(parts, lines[j])
+			}
+			d.Args = unescapeTripleQuotes(strings.Join(parts, "\n"))
+		case len(rest) >= 2 && strings.HasPrefix(rest, `"`) && strings.HasSuffix(rest, `"`):
+			d.Args = rest[1 : len(rest)-1]
+		default:
+			d.Args = rest
+		}
+
+		directives = append(directives, d)
+		i = d.End
+	}
+
+	return directives
+}
+
+// escapedTripleQuote is how a triple quote inside a triple-quoted value is
+// written, so that it does not end the value.
+const escapedTripleQuote = `\"\"\"`
+
+// closingTripleQuote returns the index of the triple quote that ends a
+// triple-quoted value in s, or -1 if s does not contain one. In a run of
+// more than three quotes the last three close the value, so a value may end
+// with a quote character.
+func closingTripleQuote(s string) int {
+	idx := strings.Index(s, `"""`)
+	if idx < 0 {
+		return -1
+	}
+	for idx+3 < len(s) && s[idx+3] == '"' {
+		idx++
+	}
+	return idx
+}
+
+// unescapeTripleQuotes restores the triple quotes of a value written by
+// FormatModelfileDirective.
+func unescapeTripleQuotes(s string) string {
+	return strings.ReplaceAll(s, escapedTripleQuote, `"""`)
+}
+
+// ModelfileDirectiveValue returns the arguments of the last occurrence of a
+// directive, matching Ollama where later directives override earlier ones.
+//
+// Parameters:
+//   - content: Modelfile content
+//   - name: Directive name (case-insensitive, e.g., "SYSTEM")
+//
+// Returns:
+//   - The directive arguments, or "" if the directive is absent
+func ModelfileDirectiveValue(content, name string) string {
+	value := ""
+	for _, d := range ParseModelfileDirectives(content) {
+		if d.Name == strings.ToUpper(name) {
+			value = d.Args
+		}
+	}
+	return value
+}
+
+// ParseModelfileParameter splits PARAMETER arguments into key and value.
+//
+// Parameters:
+//   - args: Directive arguments, e.g. `temperature 0.7` or `stop "<|im_end|>"`
+//
+// Returns:
+//   - The parameter with quotes removed from the value
+//   - false if the arguments do not contain both a key and a value
+func ParseModelfileParameter(args string) (ModelfileParameter, bool) {
+	fields := strings.Fields(args)
+	if len(fields) < 2 {
+		return ModelfileParameter{}, false
+	}
+	value := strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(args), fields[0]))
+	return ModelfileParameter{Key: fields[0], Value: strings.Trim(value, "\"")}, true
+}
+
+// FormatModelfileDirective renders a single-value directive such as SYSTEM
+// or TEMPLATE. Templates and multi-line or quoted values use triple quotes;
+// triple quotes inside the value are escaped as \"\"\" so that
+// ParseModelfileDirectives reads the value back unchanged.
+//
+// Parameters:
+//   - name: Directive name (e.g., "SYSTEM")
+//   - value: Directive value
+//
+// Returns:
+//   - The directive line(s) without a trailing newline
+func FormatModelfileDirective(name, value string) string {
+	if name == "TEMPLATE" || strings.ContainsAny(value, "\n\"") {
+		value = strings.ReplaceAll(value, `"""`, escapedTripleQuote)
+		return fmt.Sprintf("%s \"\"\"%s\"\"\"", name, value)
+	}
+	return fmt.Sprintf("%s %s", name, value)
+}
+
+// FormatModelfileParameter renders a PARAMETER directive, quoting values
+// that contain whitespace.
+//
+// Parameters:
+//   - p: Parameter to render
+//
+// Returns:
+//   - The directive line without a trailing newline
+func FormatModelfileParameter(p ModelfileParameter) string {
+	if p.Value == "" || strings.ContainsAny(p.Value, " \t") {
+		return fmt.Sprintf("PARAMETER %s \"%s\"", p.Key, p.Value)
+	}
+	return fmt.Sprintf("PARAMETER %s %s", p.Key, p.Value)
+}
+
+// modelfileRevisionPrefix starts the comment in which a generated Modelfile
+// records the source revision its model was pulled from.
+const modelfileRevisionPrefix = "# Revision:"
+
+// ModelfileRevision returns the source revision recorded in a Modelfile's
+// "# Revision:" comment (see FormatRevision), or empty string if there is
+// none.
+func ModelfileRevision(content string) string {
+	for _, line := range strings.Split(content, "\n") {
+		if strings.HasPrefix(line, modelfileRevisionPrefix) {
+			return strings.TrimSpace(strings.TrimPrefix(line, modelfileRevisionPrefix))
+		}
+	}
+	return ""
+}
+
+// SetModelfileRevision records a source revision in a Modelfile's
+// "# Revision:" comment, leaving every directive as it is.
+//
+// An existing comment is replaced; otherwise the comment is added after the
+// leading comment lines of the Modelfile.
+//
+// Parameters:
+//   - content: Modelfile content
+//   - revision: Revision to record (see FormatRevision)
+//
+// Returns:
+//   - Modelfile content with the revision recorded
+func SetModelfileRevision(content, revision string) string {
+	line := modelfileRevisionPrefix + " " + revision
+	lines := strings.Split(content, "\n")
+	for i, l := range lines {
+		if strings.HasPrefix(l, modelfileRevisionPrefix) {
+			lines[i] = line
+			return strings.Join(lines, "\n")
+		}
+	}
+
+	insert := 0
+	for insert < len(lines) && strings.HasPrefix(lines[insert], "#") {
+		insert++
+	}
+	lines = append(lines[:insert], append([]string{line}, lines[insert:]...)...)
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,176 @@
+package models
+
+import "strings"
+
+// EditableConfig is the user-editable part of a Modelfile.
+//
+// Directives outside this set (LICENSE, comments) are preserved
+// unchanged when an edited config is merged back into the Modelfile
+// (see MergeEditableConfig).
+type EditableConfig struct {
+	// From is the FROM model name or absolute path to a local model
+	From string
+
+	// System is the SYSTEM prompt
+	System string
+
+	// Template is the TEMPLATE prompt template
+	Template string
+
+	// Parameters lists PARAMETER directives in file order. A key may appear
+	// more than once (e.g., several stop sequences). Values keep their
+	// original text; validation interprets them via TypedValue.
+	Parameters []ModelfileParameter
+}
+
+// ParseEditableConfig extracts the editable directives from a Modelfile.
+//
+// Parameters:
+//   - content: Modelfile content
+//
+// Returns:
+//   - The SYSTEM, TEMPLATE, and PARAMETER values found in the file
+func ParseEditableConfig(content string) *EditableConfig {
+	cfg := &EditableConfig{}
+
+	for _, d := range ParseModelfileDirectives(content) {
+		switch d.Name {
+		case "FROM":
+			cfg.From = d.Args
+		case "SYSTEM":
+			cfg.System = d.Args
+		case "TEMPLATE":
+			cfg.Template = d.Args
+		case "PARAMETER":
+			if p, ok := ParseModelfileParameter(d.Args); ok {
+				cfg.Parameters = append(cfg.Parameters, p)
+			}
+		}
+	}
+
+	return cfg
+}
+
+// Equal reports whether two editable configs are identical.
+func (c *EditableConfig) Equal(other *EditableConfig) bool {
+	if c.From != other.From || c.System != other.System || c.Template != other.Template ||
+		len(c.Parameters) != len(other.Parameters) {
+		return false
+	}
+	for i := range c.Parameters {
+		if c.Parameters[i] != other.Parameters[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// MergeEditableConfig writes an edited config back into the original
+// Modelfile content.
+//
+// Changed SYSTEM and TEMPLATE directives are rewritten in place and
+// PARAMETER directives are replaced as a block at the position of the first
+// existing one. Directives that are new are inserted after FROM. Everything
+// else (FROM, LICENSE, comments, blank lines) is kept verbatim.
+//
+// Parameters:
+//   - original: Current Modelfile content
+//   - cfg: Edited config
+//
+// Returns:
+//   - The new Modelfile content
+func MergeEditableConfig(original string, cfg *EditableConfig) string {
+	lines := strings.Split(original, "\n")
+	current := ParseEditableConfig(original)
+
+	byStart := make(map[int]ModelfileDirective)
+	for _, d := range ParseModelfileDirectives(original) {
+		byStart[d.Start] = d
+	}
+
+	// Unchanged parameters keep their original line, including its quoting
+	paramLines := make(map[ModelfileParameter]string)
+	for _, d := range byStart {
+		if d.Name == "PARAMETER" {
+			if p, ok := ParseModelfileParameter(d.Args); ok {
+				paramLines[p] = lines[d.Start]
+			}
+		}
+	}
+	formatParam := func(p ModelfileParameter) string {
+		if line, ok := paramLines[p]; ok {
+			return line
+		}
+		return FormatModelfileParameter(p)
+	}
+
+	var out []string
+	insertAt := 0 // position in out for directives the file does not have yet
+	hasFrom, hasSystem, hasTemplate, hasParams := false, false, false, false
+
+	for i := 0; i < len(lines); i++ {
+		d, ok := byStart[i]
+		if !ok {
+			out = append(out, lines[i])
+			continue
+		}
+
+		switch {
+		case d.Name == "FROM" && cfg.From != current.From:
+			out = append(out, "FROM "+cfg.From)
+			insertAt = len(out)
+			hasFrom = true
+		case d.Name == "SYSTEM" && cfg.System != current.System:
+			if cfg.System != "" {
+				out = append(out, FormatModelfileDirective("SYSTEM", cfg.System))
+			}
+			hasSystem = true
+		case d.Name == "TEMPLATE" && cfg.Template != current.Template:
+			if cfg.Template != "" {
+				out = append(out, FormatModelfileDirective("TEMPLATE", cfg.Template))
+			}
+			hasTemplate = true
+		case d.Name == "PARAMETER":
+			if !hasParams {
+				for _, p := range cfg.Parameters {
+					out = append(out, formatParam(p))
+				}
+				hasParams = true
+			}
+		default:
+			out = append(out, lines[d.Start:d.End+1]...)
+			hasSystem = hasSystem || d.Name == "SYSTEM"
+			hasTemplate = hasTemplate || d.Name == "TEMPLATE"
+			if d.Name == "FROM" {
+				insertAt = len(out)
+				hasFrom = true
+			}
+		}
+		i = d.End
+	}
+
+	var missing []string
+	if !hasTemplate && cfg.Template != "" {
+		missing = append(missing, "", FormatModelfileDirective("TEMPLATE", cfg.Template))
+	}
+	if !hasSystem && cfg.System != "" {
+		missing = append(missing, "", FormatModelfileDirective("SYSTEM", cfg.System))
+	}
+	if !hasParams && len(cfg.Parameters) > 0 {
+		missing = append(missing, "")
+		for _, p := range cfg.Parameters {
+			missing = append(missing, formatParam(p))
+		}
+	}
+	if !hasFrom && cfg.From != "" {
+		missing = append([]string{"", "FROM " + cfg.From}, missing...)
+	}
+	if len(missing) > 0 {
+		if insertAt == 0 {
+			missing = append(missing[1:], "")
+		}
+		out = append(out[:insertAt], append(missing, out[insertAt:]...)...)
+	}
+
+	return strings.Join(out, "\n")
+}
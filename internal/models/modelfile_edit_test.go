@@ -0,0 +1,81 @@
+package models
+
+import (
+	"strings"
+	"testing"
+)
+
+// editTestModelfile is a generated Modelfile with directives xw edit must
+// keep verbatim (comments, LICENSE, a quoted parameter).
+var editTestModelfile = strings.Join([]string{
+	"# Modelfile generated by xw",
+	"FROM /models/test",
+	"",
+	FormatModelfileDirective("TEMPLATE", roundTripValues[4]),
+	"",
+	FormatModelfileDirective("SYSTEM", roundTripValues[3]),
+	"",
+	`PARAMETER temperature 0.80`,
+	`PARAMETER stop "<|im_end|>"`,
+	`PARAMETER stop "<|endoftext|>"`,
+	`LICENSE """Apache 2.0"""`,
+	"",
+}, "\n")
+
+func TestMergeEditableConfigRoundTrip(t *testing.T) {
+	tests := []struct {
+		name string
+		edit func(cfg *EditableConfig)
+	}{
+		{"system with triple quotes", func(cfg *EditableConfig) { cfg.System = roundTripValues[6] }},
+		{"system ending with triple quotes", func(cfg *EditableConfig) { cfg.System = roundTripValues[7] }},
+		{"removed system", func(cfg *EditableConfig) { cfg.System = "" }},
+		{"template", func(cfg *EditableConfig) { cfg.Template = "{{ .Prompt }}" }},
+		{"from", func(cfg *EditableConfig) { cfg.From = "/data/models/my-finetune" }},
+		{"parameters", func(cfg *EditableConfig) {
+			cfg.Parameters = []ModelfileParameter{{Key: "temperature", Value: "0.80"}, {Key: "stop", Value: "User: "}, {Key: "top_k", Value: "20"}}
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			edited := ParseEditableConfig(editTestModelfile)
+			tt.edit(edited)
+
+			merged := MergeEditableConfig(editTestModelfile, edited)
+			if got := ParseEditableConfig(merged); !got.Equal(edited) {
+				t.Errorf("parsed %+v, want %+v from:\n%s", got, edited, merged)
+			}
+			if got := ModelfileDirectiveValue(merged, "LICENSE"); got != "Apache 2.0" {
+				t.Errorf("LICENSE = %q, want %q", got, "Apache 2.0")
+			}
+			for _, kept := range []string{"# Modelfile generated by xw", "PARAMETER temperature 0.80"} {
+				if !strings.Contains(merged, kept+"\n") {
+					t.Errorf("merged Modelfile lost %q:\n%s", kept, merged)
+				}
+			}
+		})
+	}
+}
+
+func TestMergeEditableConfigAddsDirectives(t *testing.T) {
+	original := "FROM /models/test\n\nLICENSE \"\"\"Apache 2.0\"\"\"\n"
+	edited := &EditableConfig{
+		From:       "/models/test",
+		System:     roundTripValues[1],
+		Template:   roundTripValues[4],
+		Parameters: []ModelfileParameter{{Key: "stop", Value: "<|im_end|>"}},
+	}
+
+	merged := MergeEditableConfig(original, edited)
+	if got := ParseEditableConfig(merged); !got.Equal(edited) {
+		t.Errorf("parsed %+v, want %+v from:\n%s", got, edited, merged)
+	}
+	if !strings.HasPrefix(merged, "FROM /models/test\n\n") {
+		t.Errorf("new directives not inserted after FROM:\n%s", merged)
+	}
+
+	// An unchanged config leaves the file as it is
+	if got := MergeEditableConfig(editTestModelfile, ParseEditableConfig(editTestModelfile)); got != editTestModelfile {
+		t.Errorf("unchanged config rewrote the Modelfile:\n%s", got)
+	}
+}
@@ -0,0 +1,131 @@
+package models
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestModelfileParameterTypedValue(t *testing.T) {
+	tests := []struct {
+		key   string
+		value string
+		want  interface{}
+	}{
+		{"top_k", "20", 20},
+		{"num_ctx", "4096", 4096},
+		{"top_k", "many", "many"},
+		{"temperature", "0.7", 0.7},
+		{"temperature", "1", 1.0},
+		{"repeat_penalty", "high", "high"},
+		{"use_mmap", "true", true},
+		{"numa", "false", false},
+		{"use_mlock", "maybe", "maybe"},
+		{"stop", "<|im_end|>", "<|im_end|>"},
+		{"stop", "10", "10"},
+		{"stop", "true", "true"},
+		{"custom_int", "3", 3},
+		{"custom_float", "0.5", 0.5},
+		{"custom_bool", "true", true},
+		{"custom_text", "hello world", "hello world"},
+	}
+
+	for _, tt := range tests {
+		p := ModelfileParameter{Key: tt.key, Value: tt.value}
+		if got := p.TypedValue(); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s %s: TypedValue() = %#v, want %#v", tt.key, tt.value, got, tt.want)
+		}
+	}
+}
+
+func TestModelfileParameters(t *testing.T) {
+	content := `FROM /models/qwen
+PARAMETER temperature 1
+PARAMETER top_k 40
+PARAMETER use_mmap true
+PARAMETER stop "<|im_start|>"
+PARAMETER stop "<|im_end|>"
+PARAMETER stop 42
+PARAMETER seed 7
+PARAMETER seed 8
+`
+	want := map[string]interface{}{
+		"temperature": 1.0,
+		"top_k":       40,
+		"use_mmap":    true,
+		"stop":        []interface{}{"<|im_start|>", "<|im_end|>", "42"},
+		"seed":        []interface{}{7, 8},
+	}
+
+	if got := ModelfileParameters(content); !reflect.DeepEqual(got, want) {
+		t.Errorf("ModelfileParameters() = %#v, want %#v", got, want)
+	}
+}
+
+func TestModelfileParametersSingleStop(t *testing.T) {
+	got := ModelfileParameters("PARAMETER stop 0\n")
+	if stop, ok := got["stop"].(string); !ok || stop != "0" {
+		t.Errorf("stop = %#v, want string \"0\"", got["stop"])
+	}
+}
+
+// roundTripValues are SYSTEM and TEMPLATE values that must survive being
+// written and parsed back.
+var roundTripValues = []string{
+	"You are a helpful assistant.",
+	`Answer with "yes" or "no".`,
+	`Say "hi"`,
+	"Line one\nLine two\n\nLine four",
+	"{{ if .System }}<|im_start|>system\n{{ .System }}<|im_end|>\n{{ end }}<|im_start|>user\n{{ .Prompt }}<|im_end|>",
+	`Quote Python docstrings as """text""" verbatim.`,
+	"Multi-line with\n\"\"\"\nembedded triple quotes\n\"\"\"",
+	`Ends with triple quotes """`,
+}
+
+func TestFormatModelfileDirectiveRoundTrip(t *testing.T) {
+	for _, name := range []string{"SYSTEM", "TEMPLATE"} {
+		for _, value := range roundTripValues {
+			content := "FROM /models/test\n\n" + FormatModelfileDirective(name, value) + "\n\nPARAMETER top_k 20\n"
+
+			directives := ParseModelfileDirectives(content)
+			if len(directives) != 3 {
+				t.Errorf("%s %q: parsed %d directives, want 3:\n%s", name, value, len(directives), content)
+				continue
+			}
+			if d := directives[1]; d.Name != name || d.Args != value {
+				t.Errorf("%s %q: parsed %s %q:\n%s", name, value, d.Name, d.Args, content)
+			}
+			if d := directives[2]; d.Name != "PARAMETER" || d.Args != "top_k 20" {
+				t.Errorf("%s %q: following directive parsed as %s %q", name, value, d.Name, d.Args)
+			}
+		}
+	}
+}
+
+func TestFormatModelfileParameterRoundTrip(t *testing.T) {
+	params := []ModelfileParameter{
+		{Key: "temperature", Value: "0.80"},
+		{Key: "stop", Value: "<|im_start|>"},
+		{Key: "stop", Value: "<|im_end|>"},
+		{Key: "stop", Value: "User: "},
+		{Key: "num_ctx", Value: "8192"},
+	}
+
+	var lines []string
+	for _, p := range params {
+		lines = append(lines, FormatModelfileParameter(p))
+	}
+	content := strings.Join(lines, "\n")
+
+	var got []ModelfileParameter
+	for _, d := range ParseModelfileDirectives(content) {
+		p, ok := ParseModelfileParameter(d.Args)
+		if !ok {
+			t.Fatalf("failed to parse PARAMETER %q", d.Args)
+		}
+		got = append(got, p)
+	}
+	if !reflect.DeepEqual(got, params) {
+		t.Errorf("parameters = %#v, want %#v", got, params)
+	}
+}
@@ -7,7 +7,7 @@
 // Key features:
 //   - Pure Go implementation (no Python required)
 //   - Resumable downloads with progress tracking
-//   - Parallel file downloads for better performance
+//   - Concurrent file downloads for better performance
 //   - File integrity validation
 //   - Proper caching and directory structure
 //
@@ -26,6 +26,7 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"net/url"
 	"os"
 	"path/filepath"
 	"strings"
@@ -33,6 +34,8 @@ import (
 	"syscall"
 	"time"
 	"unsafe"
+
+	"github.com/tsingmaoai/xw-cli/internal/config"
 )
 
 const (
@@ -42,6 +45,9 @@ const (
 	// DefaultUserAgent is the user agent string for HTTP requests
 	DefaultUserAgent = "xw/1.0.0 (Go)"
 	
+	// DefaultModelScopeRevision is the branch downloaded from ModelScope repositories
+	DefaultModelScopeRevision = "master"
+	
 	// DefaultNamespace is the default namespace for models without an explicit namespace
 	DefaultNamespace = "default"
 	
@@ -56,13 +62,31 @@ const (
 	
 	// MaxParallelDownloads - maximum number of concurrent downloads
 	MaxParallelDownloads = 4
+	
+	// DefaultDownloadJobs - number of files downloaded concurrently by default
+	DefaultDownloadJobs = 4
+	
+	// MaxDownloadJobs - upper bound for concurrently downloaded files
+	MaxDownloadJobs = 16
 )
 
-// Client handles ModelScope API interactions and model downloads.
+// Client handles model hub API interactions and model downloads.
+//
+// The same client serves ModelScope (NewClient) and HuggingFace
+// (NewHuggingFaceClient); only file listing and download URLs differ
+// between the two, so locking, resume, progress and integrity checks
+// are shared.
 type Client struct {
 	endpoint   string
 	httpClient *http.Client
 	userAgent  string
+	source     string // config.ModelSourceModelScope or config.ModelSourceHuggingFace
+	token      string // Bearer token sent with every request (HuggingFace only)
+	revision   string // Branch, tag, or commit to download
+	jobs       int    // Number of files downloaded concurrently
+	
+	onFileStart func(name string)      // Optional hook called as each file starts
+	onProgress  func(DownloadProgress) // Optional hook for aggregate progress
 }
 
 // ProgressFunc is called periodically during download to report progress.
@@ -95,6 +119,9 @@ func NewClient() *Client {
 	return &Client{
 		endpoint:  DefaultEndpoint,
 		userAgent: DefaultUserAgent,
+		source:    config.ModelSourceModelScope,
+		revision:  DefaultModelScopeRevision,
+		jobs:      DefaultDownloadJobs,
 		httpClient: &http.Client{
 			Timeout: 0, // No timeout for large downloads
 			Transport: &http.Transport{
@@ -129,15 +156,19 @@ type FileInfo struct {
 // DownloadModel downloads a complete model from ModelScope.
 //
 // This function:
-//  1. Queries the ModelScope API for model file list
+//  1. Queries the model hub API (ModelScope or HuggingFace) for model file list
 //  2. Creates the local cache directory structure: cacheDir/{userModelID}/{tag}
-//  3. Downloads all files (with resume support)
+//  3. Downloads all files, several at a time (with resume support)
 //  4. Validates file integrity
 //  5. Returns the local path to the downloaded model
 //
+// DownloadModel does not lock the model directory; callers must not download
+// the same model twice at once (the server holds a download lock while
+// pulling, see handlers.PullModel).
+//
 // Parameters:
 //   - ctx: Context for cancellation
-//   - sourceID: ModelScope model identifier for API (e.g., "Qwen/Qwen2-0.5B")
+//   - sourceID: Model identifier on the hub (e.g., "Qwen/Qwen2-0.5B")
 //   - userModelID: User-friendly model identifier for directory structure (e.g., "qwen2-0.5b")
 //   - tag: Model version tag (e.g., "latest", "v1.0")
 //   - cacheDir: Base directory for caching models
@@ -164,15 +195,6 @@ func (c *Client) DownloadModel(
 		return "", fmt.Errorf("failed to create model directory: %w", err)
 	}
 	
-	// Acquire download lock to prevent concurrent downloads of the same model
-	// This protects against file corruption from multiple download processes
-	lockPath := filepath.Join(modelDir, ".download.lock")
-	if err := c.acquireLock(lockPath); err != nil {
-		return "", fmt.Errorf("failed to acquire download lock: %w", err)
-	}
-	// Ensure lock is released on function exit (success, error, or cancellation)
-	defer c.releaseLock(lockPath)
-	
 	// Get model file list from API using the sourceID (ModelScope identifier)
 	files, err := c.getModelFiles(ctx, sourceID)
 	if err != nil {
@@ -185,47 +207,82 @@ func (c *Client) DownloadModel(
 		totalBytes += file.Size
 	}
 	
-	// Track progress across all files (sequential, no locking needed)
-	var downloadedBytes int64
-	startTime := time.Now()
+	// Check free space against the exact download size. Files that are
+	// already complete (or partially downloaded) only need the remainder.
+	remainingBytes := totalBytes
+	for _, file := range files {
+		if info, err := os.Stat(filepath.Join(modelDir, file.Name)); err == nil && info.Size() <= file.Size {
+			remainingBytes -= info.Size()
+		}
+	}
+	if err := CheckDiskSpace(modelDir, remainingBytes); err != nil {
+		return "", err
+	}
+	
+	// Track progress across all files. Files download concurrently, so
+	// per-file byte counts are guarded by progressMu, which also serializes
+	// calls to the caller's progress callbacks.
+	var progressMu sync.Mutex
+	fileProgress := make(map[string]int64, len(files))
+	var reportedBytes int64
+	var completedFiles int
+	var meter throughputMeter
+	var rate float64
+	var lastSummary time.Time
 	
 	// Wrapper progress function that reports overall progress (ollama-style)
 	overallProgressFunc := func(filename string, fileDownloaded, fileTotal int64) {
-		if progress == nil {
+		if progress == nil && c.onProgress == nil {
 			return
 		}
 		
+		progressMu.Lock()
+		defer progressMu.Unlock()
+		
 		// For non-progress messages (validation, etc), pass through directly
 		if fileTotal == 0 {
-			progress(filename, 0, 0)
+			if progress != nil {
+				progress(filename, 0, 0)
+			}
 			return
 		}
 		
-		// Calculate overall progress
-		overall := downloadedBytes + fileDownloaded
+		// Calculate overall progress as the sum of bytes of every file.
+		// A file's count never decreases (e.g., the initial 0 reported
+		// before resuming a partial file), so the total is monotonic.
+		if fileDownloaded > fileProgress[filename] {
+			fileProgress[filename] = fileDownloaded
+		}
+		var overall int64
+		for _, n := range fileProgress {
+			overall += n
+		}
+		if overall < reportedBytes {
+			return
+		}
+		reportedBytes = overall
 		percent := float64(overall) / float64(totalBytes) * 100
 		
-		// Calculate average speed and ETA
-		elapsed := time.Since(startTime).Seconds()
-		var speedMBps float64
-		var eta string
-		
-		if elapsed > 0 && overall > 0 {
-			speedMBps = float64(overall) / elapsed / (1024 * 1024) // MB/s
-			
-			if speedMBps > 0 {
-				remainingBytes := totalBytes - overall
-				etaSeconds := int(float64(remainingBytes) / (speedMBps * 1024 * 1024))
-				if etaSeconds > 0 {
-					minutes := etaSeconds / 60
-					seconds := etaSeconds % 60
-					if minutes > 0 {
-						eta = fmt.Sprintf("%dm%ds", minutes, seconds)
-					} else {
-						eta = fmt.Sprintf("%ds", seconds)
-					}
-				}
+		// Report the aggregate summary (recent throughput and ETA) at most
+		// once per second, and always when the last byte arrives
+		now := time.Now()
+		rate = meter.add(now, overall)
+		if c.onProgress != nil && (now.Sub(lastSummary) >= time.Second || overall == totalBytes) {
+			summary := DownloadProgress{
+				CompletedBytes: overall,
+				TotalBytes:     totalBytes,
+				CompletedFiles: completedFiles,
+				TotalFiles:     len(files),
+				BytesPerSecond: rate,
 			}
+			if rate > 0 {
+				summary.ETA = time.Duration(float64(totalBytes-overall) / rate * float64(time.Second))
+			}
+			c.onProgress(summary)
+			lastSummary = now
+		}
+		if progress == nil {
+			return
 		}
 		
 		// Format sizes
@@ -234,9 +291,9 @@ func (c *Client) DownloadModel(
 		
 		// Get terminal width and calculate progress bar width
 		termWidth := getTerminalWidth()
-		// Reserve space for: "100% |" + "| 99.9/99.9 GB  999.9 MB/s  99m99s"
-		// Roughly: 5 + 3 + 42 = 50 chars
-		barWidth := termWidth - 50
+		// Reserve space for: "100% |" + "| 999.9/999.9 GB"
+		// Roughly: 6 + 17 = 23 chars, rounded up for margin
+		barWidth := termWidth - 30
 		if barWidth < 20 {
 			barWidth = 20 // Minimum bar width
 		}
@@ -247,109 +304,136 @@ func (c *Client) DownloadModel(
 		filled := int(float64(barWidth) * percent / 100)
 		bar := strings.Repeat("█", filled) + strings.Repeat("░", barWidth-filled)
 		
-		// Format message
-		var message string
-		if speedMBps > 0 && eta != "" {
-			message = fmt.Sprintf("%3.0f%% |%s| %.1f/%.1f GB  %.1f MB/s  %s", 
-				percent, bar, downloadedGB, totalGB, speedMBps, eta)
-		} else if speedMBps > 0 {
-			message = fmt.Sprintf("%3.0f%% |%s| %.1f/%.1f GB  %.1f MB/s", 
-				percent, bar, downloadedGB, totalGB, speedMBps)
-		} else {
-			message = fmt.Sprintf("%3.0f%% |%s| %.1f/%.1f GB", 
-				percent, bar, downloadedGB, totalGB)
-		}
+		// Format message; rate and ETA are reported in the summary
+		message := fmt.Sprintf("%3.0f%% |%s| %.1f/%.1f GB", 
+			percent, bar, downloadedGB, totalGB)
 		
 		progress(message, overall, totalBytes)
 	}
 	
-	// Download files sequentially (no parallel downloads)
-	// Model files are typically large, so parallel downloads don't help much
-	for _, file := range files {
-		// Check context before each file
-		select {
-		case <-ctx.Done():
-			return "", ctx.Err()
-		default:
-		}
-		
-		localPath := filepath.Join(modelDir, file.Name)
-		
-		// Download file using sourceID for API requests with overall progress tracking
-		if err := c.downloadFile(ctx, file, localPath, sourceID, overallProgressFunc); err != nil {
-			// Don't report error if context was cancelled
-			if ctx.Err() != nil {
-				return "", ctx.Err()
-			}
-			return "", fmt.Errorf("failed to download %s: %w", file.Name, err)
-		}
-		
-		// Update total downloaded bytes after completing this file
-		downloadedBytes += file.Size
-		
-		// Validate file integrity if SHA256 is available
-		if file.Sha256 != "" {
-			// Check context before validation
-			select {
-			case <-ctx.Done():
-				return "", ctx.Err()
-			default:
-			}
-			
-			// Silently validate (no progress message to avoid line breaks)
-			if err := c.validateFileIntegrity(localPath, file.Sha256); err != nil {
-				// Don't report error if context was cancelled
-				if ctx.Err() != nil {
-					return "", ctx.Err()
+	// Download up to c.jobs files at a time. Sharded models consist of many
+	// similarly sized files, so concurrent transfers make better use of
+	// high-bandwidth links than a single stream. The first failure cancels
+	// the remaining downloads.
+	jobs := c.jobs
+	if jobs < 1 {
+		jobs = 1
+	}
+	if jobs > len(files) {
+		jobs = len(files)
+	}
+	
+	downloadCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	
+	var (
+		wg       sync.WaitGroup
+		errOnce  sync.Once
+		firstErr error
+	)
+	queue := make(chan FileInfo)
+	
+	for i := 0; i < jobs; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for file := range queue {
+				if err := c.fetchFile(downloadCtx, file, modelDir, sourceID, overallProgressFunc); err != nil {
+					errOnce.Do(func() {
+						firstErr = err
+						cancel()
+					})
+					return
 				}
-				return "", fmt.Errorf("integrity check failed for %s: %w", file.Name, err)
+				progressMu.Lock()
+				completedFiles++
+				progressMu.Unlock()
 			}
+		}()
+	}
+	
+feed:
+	for _, file := range files {
+		select {
+		case queue <- file:
+		case <-downloadCtx.Done():
+			break feed
 		}
 	}
+	close(queue)
+	wg.Wait()
+	
+	// Don't report a download error if the caller cancelled
+	if ctx.Err() != nil {
+		return "", ctx.Err()
+	}
+	if firstErr != nil {
+		return "", firstErr
+	}
+	
+	// Final summary once every file is verified
+	if c.onProgress != nil {
+		c.onProgress(DownloadProgress{
+			CompletedBytes: totalBytes,
+			TotalBytes:     totalBytes,
+			CompletedFiles: len(files),
+			TotalFiles:     len(files),
+			BytesPerSecond: rate,
+		})
+	}
 	
 	return modelDir, nil
 }
 
-// acquireLock creates a lock file to prevent concurrent downloads of the same model.
-//
-// The lock file contains the process ID and timestamp for debugging purposes.
-// If a lock file already exists, this function returns an error.
+// fetchFile downloads one model file and validates its integrity.
 //
 // Parameters:
-//   - lockPath: Path to the lock file
+//   - ctx: Context for cancellation
+//   - file: File metadata from the model hub
+//   - modelDir: Local model directory
+//   - sourceID: Model identifier on the hub, used for download URLs
+//   - progress: Progress callback for the file
 //
 // Returns:
-//   - Error if lock cannot be acquired (e.g., already locked by another process)
-func (c *Client) acquireLock(lockPath string) error {
-	// Check if lock already exists
-	if _, err := os.Stat(lockPath); err == nil {
-		// Lock file exists, read it to provide helpful error message
-		data, _ := os.ReadFile(lockPath)
-		return fmt.Errorf("model download already in progress (lock: %s). If this is stale, remove the lock file manually: %s", 
-			string(data), lockPath)
+//   - Error if the download or integrity check fails
+func (c *Client) fetchFile(ctx context.Context, file FileInfo, modelDir, sourceID string, progress ProgressFunc) error {
+	localPath := filepath.Join(modelDir, file.Name)
+	
+	if c.onFileStart != nil {
+		c.onFileStart(file.Name)
+	}
+	
+	// Download file using sourceID for API requests with overall progress tracking
+	if err := c.downloadFile(ctx, file, localPath, sourceID, progress); err != nil {
+		// Don't report error if context was cancelled
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		return fmt.Errorf("failed to download %s: %w", file.Name, err)
 	}
 	
-	// Create lock file with PID and timestamp
-	lockInfo := fmt.Sprintf("pid=%d,time=%s", os.Getpid(), time.Now().Format(time.RFC3339))
-	if err := os.WriteFile(lockPath, []byte(lockInfo), 0644); err != nil {
-		return fmt.Errorf("failed to create lock file: %w", err)
+	// Validate file integrity if SHA256 is available
+	if file.Sha256 != "" {
+		// Check context before validation
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		default:
+		}
+		
+		// Silently validate (no progress message to avoid line breaks)
+		if err := c.validateFileIntegrity(localPath, file.Sha256); err != nil {
+			// Don't report error if context was cancelled
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			return fmt.Errorf("integrity check failed for %s: %w", file.Name, err)
+		}
 	}
 	
 	return nil
 }
 
-// releaseLock removes the lock file to allow future downloads.
-//
-// This function should be called when the download completes, fails, or is cancelled.
-// It's safe to call even if the lock file doesn't exist.
-//
-// Parameters:
-//   - lockPath: Path to the lock file
-func (c *Client) releaseLock(lockPath string) {
-	// Ignore errors - lock file might not exist if download was cancelled early
-	os.Remove(lockPath)
-}
-
 // validateFileIntegrity verifies the SHA256 hash of a downloaded file.
 //
 // If the hash doesn't match, the file is deleted to prevent corrupted files.
@@ -449,8 +533,7 @@ func (c *Client) downloadFileParallel(
 	}
 	
 	// Build download URL
-	downloadURL := fmt.Sprintf("%s/api/v1/models/%s/repo?Revision=master&FilePath=%s",
-		c.endpoint, modelID, file.Name)
+	downloadURL := c.fileURL(modelID, file.Name)
 	
 	// Calculate number of parts
 	numParts := int((file.Size + ParallelDownloadPartSize - 1) / ParallelDownloadPartSize)
@@ -557,13 +640,12 @@ func (c *Client) downloadFilePart(
 	progressCallback func(int64),
 ) error {
 	// Create HTTP request with Range header
-	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	req, err := c.newRequest(ctx, url)
 	if err != nil {
 		return fmt.Errorf("failed to create request: %w", err)
 	}
 	
 	req.Header.Set("Range", fmt.Sprintf("bytes=%d-%d", start, end))
-	req.Header.Set("User-Agent", c.userAgent)
 	
 	// Execute request
 	resp, err := c.httpClient.Do(req)
@@ -634,18 +716,49 @@ func (c *Client) downloadFilePart(
 	return nil
 }
 
-// getModelFiles queries the ModelScope API for the list of files in a model.
-func (c *Client) getModelFiles(ctx context.Context, modelID string) ([]FileInfo, error) {
-	// Build API URL - using the repo/files endpoint with master revision
-	url := fmt.Sprintf("%s/api/v1/models/%s/repo/files?Revision=master&Recursive=True", 
-		c.endpoint, modelID)
-	
+// newRequest creates a GET request carrying the client's user agent and,
+// if configured, its access token.
+func (c *Client) newRequest(ctx context.Context, url string) (*http.Request, error) {
 	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
 	if err != nil {
 		return nil, err
 	}
 	
 	req.Header.Set("User-Agent", c.userAgent)
+	if c.token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	}
+	
+	return req, nil
+}
+
+// fileURL returns the download URL of a single file in a model repository.
+func (c *Client) fileURL(modelID, filePath string) string {
+	if c.source == config.ModelSourceHuggingFace {
+		return c.huggingFaceFileURL(modelID, filePath)
+	}
+	
+	// URL encode the file path
+	encodedPath := strings.ReplaceAll(filePath, " ", "%20")
+	encodedPath = strings.ReplaceAll(encodedPath, "+", "%2B")
+	return fmt.Sprintf("%s/api/v1/models/%s/repo?Revision=%s&FilePath=%s",
+		c.endpoint, modelID, url.QueryEscape(c.revision), encodedPath)
+}
+
+// getModelFiles queries the model hub API for the list of files in a model.
+func (c *Client) getModelFiles(ctx context.Context, modelID string) ([]FileInfo, error) {
+	if c.source == config.ModelSourceHuggingFace {
+		return c.getHuggingFaceFiles(ctx, modelID)
+	}
+	
+	// Build API URL - using the repo/files endpoint at the requested revision
+	filesURL := fmt.Sprintf("%s/api/v1/models/%s/repo/files?Revision=%s&Recursive=True", 
+		c.endpoint, modelID, url.QueryEscape(c.revision))
+	
+	req, err := c.newRequest(ctx, filesURL)
+	if err != nil {
+		return nil, err
+	}
 	
 	resp, err := c.httpClient.Do(req)
 	if err != nil {
@@ -735,20 +848,14 @@ func (c *Client) downloadFile(
 		}
 	}
 	
-	// Build download URL using ModelScope API format
-	// URL encode the file path
-	encodedPath := strings.ReplaceAll(file.Name, " ", "%20")
-	encodedPath = strings.ReplaceAll(encodedPath, "+", "%2B")
-	downloadURL := fmt.Sprintf("%s/api/v1/models/%s/repo?Revision=master&FilePath=%s",
-		c.endpoint, modelID, encodedPath)
+	// Build download URL using the source's API format
+	downloadURL := c.fileURL(modelID, file.Name)
 	
-	req, err := http.NewRequestWithContext(ctx, "GET", downloadURL, nil)
+	req, err := c.newRequest(ctx, downloadURL)
 	if err != nil {
 		return err
 	}
 	
-	req.Header.Set("User-Agent", c.userAgent)
-	
 	// Set Range header for resume support
 	if resumeFrom > 0 {
 		req.Header.Set("Range", fmt.Sprintf("bytes=%d-", resumeFrom))
@@ -0,0 +1,70 @@
+// Package models - progress.go summarizes download progress across all
+// files of a model: overall bytes, recent throughput, and time remaining.
+package models
+
+import (
+	"fmt"
+	"time"
+)
+
+// throughputWindow is how far back throughput is measured. A short window
+// follows changes in link speed; averaging since the start would not.
+const throughputWindow = 10 * time.Second
+
+// DownloadProgress is an aggregate snapshot of a model download.
+type DownloadProgress struct {
+	CompletedBytes int64         // Bytes downloaded across all files
+	TotalBytes     int64         // Total size of all files
+	CompletedFiles int           // Files downloaded and verified
+	TotalFiles     int           // Number of files in the model
+	BytesPerSecond float64       // Recent transfer rate
+	ETA            time.Duration // Estimated time remaining (0 if unknown)
+}
+
+// Percent returns the overall completion percentage (0-100).
+func (p DownloadProgress) Percent() float64 {
+	if p.TotalBytes <= 0 {
+		return 0
+	}
+	return float64(p.CompletedBytes) / float64(p.TotalBytes) * 100
+}
+
+// String formats the rate, time remaining, and file count for display,
+// e.g. "85.2 MB/s  ETA 9m35s  3/14 files".
+func (p DownloadProgress) String() string {
+	s := fmt.Sprintf("%.1f MB/s", p.BytesPerSecond/(1024*1024))
+	if p.ETA > 0 {
+		s += "  ETA " + p.ETA.Round(time.Second).String()
+	}
+	return s + fmt.Sprintf("  %d/%d files", p.CompletedFiles, p.TotalFiles)
+}
+
+// throughputSample is the cumulative byte count at a point in time.
+type throughputSample struct {
+	at    time.Time
+	bytes int64
+}
+
+// throughputMeter measures the transfer rate over throughputWindow.
+// It is not safe for concurrent use.
+type throughputMeter struct {
+	samples []throughputSample
+}
+
+// add records the cumulative byte count and returns the current rate in
+// bytes per second (0 until two samples are available).
+func (m *throughputMeter) add(at time.Time, bytes int64) float64 {
+	m.samples = append(m.samples, throughputSample{at: at, bytes: bytes})
+
+	// Drop samples older than the window, keeping one as the baseline
+	for len(m.samples) > 2 && at.Sub(m.samples[1].at) >= throughputWindow {
+		m.samples = m.samples[1:]
+	}
+
+	first := m.samples[0]
+	elapsed := at.Sub(first.at).Seconds()
+	if elapsed <= 0 {
+		return 0
+	}
+	return float64(bytes-first.bytes) / elapsed
+}
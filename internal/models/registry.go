@@ -13,6 +13,7 @@ package models
 
 import (
 	"fmt"
+	"strings"
 	"sync"
 
 	"github.com/tsingmaoai/xw-cli/internal/api"
@@ -41,6 +42,11 @@ type Registry struct {
 	// specs maps model IDs to their detailed specifications.
 	// This is the new model specification system.
 	specs map[string]*ModelSpec
+	
+	// derived maps the IDs of models created with 'xw cp' to their base
+	// model IDs. Their specs are built from the base spec on lookup, so they
+	// follow models.yaml reloads.
+	derived map[string]string
 }
 
 // NewRegistry creates and initializes a new model registry.
@@ -109,7 +115,7 @@ func (r *Registry) List(deviceType api.DeviceType, showAll bool) []api.Model {
 
 	var result []api.Model
 
-	for _, model := range r.models {
+	for _, model := range r.allModels() {
 		// If showAll is true or deviceType is all, include the model
 		if showAll || deviceType == api.DeviceTypeAll {
 			result = append(result, *model)
@@ -125,6 +131,26 @@ func (r *Registry) List(deviceType api.DeviceType, showAll bool) []api.Model {
 	return result
 }
 
+// allModels returns the registered models followed by the models derived
+// from them with 'xw cp', which are listed with their base model's devices
+// and capabilities. The caller must hold r.mu.
+func (r *Registry) allModels() []*api.Model {
+	result := make([]*api.Model, 0, len(r.models)+len(r.derived))
+	for _, model := range r.models {
+		result = append(result, model)
+	}
+	for id, baseID := range r.derived {
+		base, ok := r.models[baseID]
+		if !ok {
+			continue
+		}
+		model := *base
+		model.Name = id
+		result = append(result, &model)
+	}
+	return result
+}
+
 // Get retrieves a specific model by its name.
 //
 // This method performs a case-sensitive lookup of a model in the registry.
@@ -325,7 +351,7 @@ func (r *Registry) ListAvailableModels(detectedDevices []api.DeviceType) []api.M
 
 	var result []api.Model
 
-	for _, model := range r.models {
+	for _, model := range r.allModels() {
 		if r.supportsAnyDevice(model, detectedDevices) {
 			result = append(result, *model)
 		}
@@ -334,6 +360,50 @@ func (r *Registry) ListAvailableModels(detectedDevices []api.DeviceType) []api.M
 	return result
 }
 
+// FilterByCapabilities returns the models that have every one of the
+// required capabilities.
+//
+// Capabilities are compared case-insensitively. An empty requirement list
+// returns the models unchanged, so callers can apply it unconditionally.
+//
+// Parameters:
+//   - models: Models to filter
+//   - required: Capabilities each returned model must have (AND semantics)
+//
+// Returns:
+//   - The matching models, in their original order
+func (r *Registry) FilterByCapabilities(models []api.Model, required []string) []api.Model {
+	if len(required) == 0 {
+		return models
+	}
+
+	result := make([]api.Model, 0, len(models))
+	for _, model := range models {
+		if hasAllCapabilities(model.Capabilities, required) {
+			result = append(result, model)
+		}
+	}
+
+	return result
+}
+
+// hasAllCapabilities reports whether have contains every capability in want.
+func hasAllCapabilities(have, want []string) bool {
+	for _, w := range want {
+		found := false
+		for _, h := range have {
+			if strings.EqualFold(strings.TrimSpace(w), h) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
 // CountAvailableModels counts models compatible with detected devices.
 //
 // This method counts how many models in the registry can run on at least
@@ -349,7 +419,7 @@ func (r *Registry) CountAvailableModels(detectedDevices []api.DeviceType) int {
 	defer r.mu.RUnlock()
 
 	count := 0
-	for _, model := range r.models {
+	for _, model := range r.allModels() {
 		if r.supportsAnyDevice(model, detectedDevices) {
 			count++
 		}
@@ -428,6 +498,13 @@ func GetModelSpec(modelID string) *ModelSpec {
 		}
 	}
 	
+	// Finally, models derived from a registered model with 'xw cp'
+	if baseID, ok := defaultRegistry.derived[modelID]; ok {
+		if base, ok := defaultRegistry.specs[baseID]; ok {
+			return derivedSpec(base, modelID)
+		}
+	}
+	
 	return nil
 }
 
@@ -485,6 +562,7 @@ func RegisterModelSpec(spec *ModelSpec) {
 		Version:          spec.Tag, // Tag is the version/variant
 		Size:             int64(spec.Parameters * 2 * 1000000000), // Rough estimate: params * 2 bytes * 1B
 		SupportedDevices: devices,
+		Capabilities:     spec.Capabilities,
 	}
 	defaultRegistry.models[spec.ID] = apiModel
 	
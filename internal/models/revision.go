@@ -0,0 +1,145 @@
+package models
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"github.com/tsingmaoai/xw-cli/internal/config"
+)
+
+// commitPattern matches a full git commit SHA.
+var commitPattern = regexp.MustCompile(`^[0-9a-f]{40}$`)
+
+// ResolveRevision resolves a branch or tag of a model repository to the
+// commit it currently points at, so that a pull records exactly what was
+// downloaded.
+//
+// Parameters:
+//   - ctx: Context for cancellation
+//   - source: Model source ("modelscope" or "huggingface"; empty selects ModelScope)
+//   - sourceID: Model identifier on the hub (e.g., "Qwen/Qwen2-0.5B")
+//   - revision: Branch, tag, or commit; empty selects the default branch
+//
+// Returns:
+//   - Commit SHA
+//   - Error if the hub cannot be queried or does not report the commit
+func ResolveRevision(ctx context.Context, source, sourceID, revision string) (string, error) {
+	downloader, err := NewDownloader(source, DownloadOptions{Revision: revision})
+	if err != nil {
+		return "", err
+	}
+	c := downloader.(*Client)
+
+	if commitPattern.MatchString(c.revision) {
+		return c.revision, nil
+	}
+	if c.source == config.ModelSourceHuggingFace {
+		return c.resolveHuggingFaceRevision(ctx, sourceID)
+	}
+	return c.resolveModelScopeRevision(ctx, sourceID)
+}
+
+// resolveHuggingFaceRevision reads the commit of the client's revision
+// from the HuggingFace model info API.
+func (c *Client) resolveHuggingFaceRevision(ctx context.Context, modelID string) (string, error) {
+	var info struct {
+		Sha string `json:"sha"`
+	}
+	infoURL := fmt.Sprintf("%s/api/models/%s/revision/%s", c.endpoint, modelID, url.PathEscape(c.revision))
+	if err := c.getJSON(ctx, infoURL, &info); err != nil {
+		return "", err
+	}
+	if info.Sha == "" {
+		return "", fmt.Errorf("no commit reported for revision %s", c.revision)
+	}
+	return info.Sha, nil
+}
+
+// resolveModelScopeRevision looks up the commit of the client's revision
+// among the branches and tags of a ModelScope repository.
+func (c *Client) resolveModelScopeRevision(ctx context.Context, modelID string) (string, error) {
+	type revisionEntry struct {
+		Revision string `json:"Revision"`
+		CommitId string `json:"CommitId"`
+	}
+	var result struct {
+		Data struct {
+			RevisionMap struct {
+				Branches []revisionEntry `json:"Branches"`
+				Tags     []revisionEntry `json:"Tags"`
+			} `json:"RevisionMap"`
+		} `json:"Data"`
+	}
+	revisionsURL := fmt.Sprintf("%s/api/v1/models/%s/revisions", c.endpoint, modelID)
+	if err := c.getJSON(ctx, revisionsURL, &result); err != nil {
+		return "", err
+	}
+
+	entries := append(result.Data.RevisionMap.Branches, result.Data.RevisionMap.Tags...)
+	for _, entry := range entries {
+		if entry.Revision == c.revision && entry.CommitId != "" {
+			return entry.CommitId, nil
+		}
+	}
+	return "", fmt.Errorf("no commit reported for revision %s", c.revision)
+}
+
+// getJSON fetches a hub API URL and decodes its JSON response into v.
+func (c *Client) getJSON(ctx context.Context, apiURL string, v interface{}) error {
+	req, err := c.newRequest(ctx, apiURL)
+	if err != nil {
+		return err
+	}
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("API returned status %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+	if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+		return fmt.Errorf("failed to parse API response: %w", err)
+	}
+	return nil
+}
+
+// FormatRevision formats the source revision of a pull as recorded in the
+// "# Revision:" header of a generated Modelfile: the requested branch or
+// tag followed by the commit it resolved to, e.g. "v1.0 (3f2a...)". If the
+// commit is unknown or was requested directly, only one of them is given.
+func FormatRevision(revision, commit string) string {
+	switch {
+	case commit == "" || commit == revision:
+		if revision == "" {
+			return commit
+		}
+		return revision
+	case revision == "":
+		return commit
+	default:
+		return fmt.Sprintf("%s (%s)", revision, commit)
+	}
+}
+
+// ParseRevision splits a recorded revision (see FormatRevision) into the
+// requested branch or tag and its commit. The commit is empty if it was not
+// recorded; a bare commit SHA is returned as both.
+func ParseRevision(recorded string) (string, string) {
+	recorded = strings.TrimSpace(recorded)
+	if ref, rest, ok := strings.Cut(recorded, " ("); ok {
+		return ref, strings.TrimSuffix(rest, ")")
+	}
+	if commitPattern.MatchString(recorded) {
+		return recorded, recorded
+	}
+	return recorded, ""
+}
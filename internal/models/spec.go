@@ -56,6 +56,9 @@ type ModelSpec struct {
 	// This is used when downloading models from external repositories
 	SourceID string
 	
+	// Source is the model hub SourceID refers to ("modelscope" or "huggingface")
+	Source string
+	
 	// Model specifications
 	
 	// Parameters is the model size in billions of parameters
@@ -82,8 +85,33 @@ type ModelSpec struct {
 	Tag string
 	
 	// Capabilities lists the model's supported features
-	// Common values: "completion", "vision", "tool_use", "function_calling"
+	// Common values: "completion", "vision", "tool_use", "function_calling", "embedding"
 	Capabilities []string
+	
+	// DerivedFrom is the ID of the base model for models created with
+	// 'xw cp', which serve the base model's weights with their own Modelfile.
+	// Empty for models defined in models.yaml.
+	DerivedFrom string
+}
+
+// TemplateModelID returns the model ID to look up runtime templates and
+// other per-model configuration with: the base model's for derived models,
+// the model's own otherwise.
+func (m *ModelSpec) TemplateModelID() string {
+	if m.DerivedFrom != "" {
+		return m.DerivedFrom
+	}
+	return m.ID
+}
+
+// HasCapability reports whether the model lists a capability (e.g., "vision").
+func (m *ModelSpec) HasCapability(capability string) bool {
+	for _, c := range m.Capabilities {
+		if c == capability {
+			return true
+		}
+	}
+	return false
 }
 
 // SupportsDevice checks if the model supports a specific device type
@@ -0,0 +1,61 @@
+package runtime
+
+import (
+	"context"
+	"fmt"
+)
+
+// SetConcurrencyListener registers the function called when the
+// concurrency limit of an instance is changed with SetMaxConcurrent. The
+// proxy registers its concurrency manager here so request semaphores are
+// resized immediately.
+func (m *Manager) SetConcurrencyListener(listener func(instanceID string, maxConcurrent int)) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.concurrencyListener = listener
+}
+
+// SetMaxConcurrent changes the concurrency limit of a live instance.
+//
+// The limit set at creation (the xw.max_concurrent label) is overridden
+// without restarting the container, and the registered concurrency
+// listener is notified. A limit of 0 removes the limit.
+//
+// Lowering the limit below the number of requests in flight does not
+// interrupt them: they complete normally, and new requests wait until the
+// in-flight count falls below the new limit.
+//
+// Parameters:
+//   - ctx: Context for cancellation
+//   - alias: Alias of the instance
+//   - maxConcurrent: New concurrency limit (0 for unlimited)
+//
+// Returns:
+//   - The updated instance
+//   - Error if the instance is not found or the limit cannot be saved
+func (m *Manager) SetMaxConcurrent(ctx context.Context, alias string, maxConcurrent int) (*Instance, error) {
+	if maxConcurrent < 0 {
+		return nil, fmt.Errorf("concurrency limit must not be negative")
+	}
+
+	inst, err := m.findInstanceByAlias(ctx, alias)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := m.overrides.setMaxConcurrent(inst.ID, maxConcurrent); err != nil {
+		return nil, err
+	}
+	m.overrides.apply([]*Instance{inst})
+
+	m.mu.RLock()
+	listener := m.concurrencyListener
+	m.mu.RUnlock()
+	if listener != nil {
+		listener(inst.ID, maxConcurrent)
+	}
+	m.notifyInstancesChanged()
+
+	log.Info("Set concurrency limit of instance %s to %d", inst.ID, maxConcurrent)
+	return inst, nil
+}
@@ -3,20 +3,28 @@ package runtime
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"io"
 	"os/exec"
 	runtimePkg "runtime"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
 
-	"github.com/creack/pty"
+	cerrdefs "github.com/containerd/errdefs"
 	"github.com/docker/docker/api/types/container"
 	"github.com/docker/docker/api/types/filters"
+	"github.com/docker/docker/api/types/image"
 	"github.com/docker/docker/client"
+	"github.com/docker/docker/pkg/jsonmessage"
+	"github.com/docker/go-units"
 
-	"github.com/tsingmaoai/xw-cli/internal/logger"
+	"github.com/tsingmaoai/xw-cli/internal/api"
+	"github.com/tsingmaoai/xw-cli/internal/config"
+	"github.com/tsingmaoai/xw-cli/internal/hooks"
+	"github.com/tsingmaoai/xw-cli/internal/models"
 )
 
 // CreateContainerWithLabels creates a Docker container with automatic common label injection.
@@ -33,9 +41,16 @@ import (
 //   - xw.deployment_mode: Deployment mode (e.g., "docker")
 //   - xw.server_name: Server identifier for multi-server support
 //   - xw.max_concurrent: Max concurrent requests (if specified in ExtraConfig)
+//   - xw.max_retries: Proxy retry budget for transient failures (if specified in ExtraConfig)
+//   - xw.restart_max: Crash restart budget (if specified in ExtraConfig)
+//   - xw.log_dir: Host directory with the engine's log files (if params.LogDir is set)
+//   - xw.run_spec: Start request the instance was created from (if params.RunSpec is set)
 //
 // Runtime-specific labels can be passed via the extraLabels parameter.
 //
+// If params.DryRun is set, no container is created; instead a *DryRunResult
+// error carrying the equivalent `docker run` command is returned.
+//
 // Parameters:
 //   - ctx: Context for cancellation and timeout
 //   - params: Creation parameters containing model info and configuration
@@ -67,10 +82,30 @@ func (b *DockerRuntimeBase) CreateContainerWithLabels(
 	}
 	
 	// Add max_concurrent label if specified (used by proxy for concurrency control)
-	if maxConcurrent, ok := params.ExtraConfig["max_concurrent"].(int); ok && maxConcurrent > 0 {
+	if maxConcurrent, ok := ConfigInt(params.ExtraConfig, "max_concurrent"); ok && maxConcurrent > 0 {
 		commonLabels["xw.max_concurrent"] = fmt.Sprintf("%d", maxConcurrent)
 	}
 	
+	// Add max_retries label if specified (used by proxy for transient failure retries)
+	if maxRetries, ok := ConfigInt(params.ExtraConfig, "max_retries"); ok && maxRetries >= 0 {
+		commonLabels["xw.max_retries"] = fmt.Sprintf("%d", maxRetries)
+	}
+	
+	// Add restart_max label if specified (used by manager to restart crashed instances)
+	if restartMax, ok := ConfigInt(params.ExtraConfig, "restart_max"); ok && restartMax > 0 {
+		commonLabels["xw.restart_max"] = fmt.Sprintf("%d", restartMax)
+	}
+	
+	// Add log_dir label if the instance has a host log directory
+	if params.LogDir != "" {
+		commonLabels["xw.log_dir"] = params.LogDir
+	}
+	
+	// Add run_spec label so the instance can be recreated by `xw restart`
+	if params.RunSpec != "" {
+		commonLabels["xw.run_spec"] = params.RunSpec
+	}
+	
 	// Merge common labels with extra labels (extra labels can override if needed)
 	if containerConfig.Labels == nil {
 		containerConfig.Labels = make(map[string]string)
@@ -84,7 +119,18 @@ func (b *DockerRuntimeBase) CreateContainerWithLabels(
 		containerConfig.Labels[k] = v
 	}
 	
+	// Dry run: report the resolved configuration instead of creating it
+	if params.DryRun {
+		return container.CreateResponse{}, &DryRunResult{
+			Command: FormatDockerRunCommand(containerName, containerConfig, hostConfig),
+		}
+	}
+	
 	// Create container via Docker API
+	sendStartupEvent(params.EventChannel, api.StartupEvent{
+		Phase:   api.StartupPhaseCreatingContainer,
+		Message: fmt.Sprintf("Creating container %s", containerName),
+	})
 	return b.client.ContainerCreate(ctx, containerConfig, hostConfig, nil, nil, containerName)
 }
 
@@ -171,7 +217,7 @@ func NewDockerRuntimeBase(runtimeName string) (*DockerRuntimeBase, error) {
 		runtimeName: runtimeName,
 	}
 
-	logger.Info("Docker runtime base initialized: %s", runtimeName)
+	log.Info("Docker runtime base initialized: %s", runtimeName)
 
 	return base, nil
 }
@@ -193,7 +239,7 @@ func (b *DockerRuntimeBase) SetServerName(name string) {
 	b.mu.Lock()
 	defer b.mu.Unlock()
 	b.serverName = name
-	logger.Debug("Server name set to: %s for runtime: %s", name, b.runtimeName)
+	log.Debug("Server name set to: %s for runtime: %s", name, b.runtimeName)
 }
 
 // GetServerName returns the current server name.
@@ -230,7 +276,7 @@ func (b *DockerRuntimeBase) GetServerName() string {
 //	})
 func (b *DockerRuntimeBase) RegisterCoreSandboxes(sandboxes []func() DeviceSandbox) {
 	b.coreSandboxes = sandboxes
-	logger.Debug("Registered %d core sandbox(es) for %s", len(sandboxes), b.runtimeName)
+	log.Debug("Registered %d core sandbox(es) for %s", len(sandboxes), b.runtimeName)
 }
 
 // SelectSandbox selects an appropriate sandbox for the given device type.
@@ -266,7 +312,7 @@ func (b *DockerRuntimeBase) SelectSandbox(deviceType string) (DeviceSandbox, err
 	for _, constructor := range b.extSandboxes {
 		sb := constructor()
 		if sb.Supports(deviceType) {
-			logger.Debug("Selected extended sandbox for %s: %T", deviceType, sb)
+			log.Debug("Selected extended sandbox for %s: %T", deviceType, sb)
 			return sb, nil
 		}
 	}
@@ -276,7 +322,7 @@ func (b *DockerRuntimeBase) SelectSandbox(deviceType string) (DeviceSandbox, err
 	for _, constructor := range b.coreSandboxes {
 		sb := constructor()
 		if sb.Supports(deviceType) {
-			logger.Debug("Selected core sandbox for %s: %T", deviceType, sb)
+			log.Debug("Selected core sandbox for %s: %T", deviceType, sb)
 			return sb, nil
 		}
 	}
@@ -306,7 +352,7 @@ func (b *DockerRuntimeBase) loadExtendedSandboxes() {
 		b.extSandboxes = LoadExtendedSandboxes(engineName)
 		
 		if len(b.extSandboxes) > 0 {
-			logger.Info("Loaded %d extended sandbox(es) for %s", len(b.extSandboxes), b.runtimeName)
+			log.Info("Loaded %d extended sandbox(es) for %s", len(b.extSandboxes), b.runtimeName)
 		}
 	})
 }
@@ -345,7 +391,7 @@ func (b *DockerRuntimeBase) Start(ctx context.Context, instanceID string) error
 		return fmt.Errorf("container ID not found for instance: %s", instanceID)
 	}
 
-	logger.Info("Starting Docker container: %s (instance: %s)", containerID[:12], instanceID)
+	log.Info("Starting Docker container: %s (instance: %s)", containerID[:12], instanceID)
 
 	if err := b.client.ContainerStart(ctx, containerID, container.StartOptions{}); err != nil {
 		return fmt.Errorf("failed to start container: %w", err)
@@ -357,7 +403,7 @@ func (b *DockerRuntimeBase) Start(ctx context.Context, instanceID string) error
 	instance.StartedAt = time.Now()
 	b.mu.Unlock()
 
-	logger.Info("Docker container started successfully: %s", instanceID)
+	log.Info("Docker container started successfully: %s", instanceID)
 
 	return nil
 }
@@ -395,7 +441,7 @@ func (b *DockerRuntimeBase) Stop(ctx context.Context, instanceID string) error {
 	}
 
 	containerID := instance.Metadata["container_id"]
-	logger.Info("Stopping Docker container: %s (instance: %s)", containerID[:12], instanceID)
+	log.Info("Stopping Docker container: %s (instance: %s)", containerID[:12], instanceID)
 
 	// Configure graceful shutdown with 30-second timeout
 	// After timeout, Docker sends SIGKILL to force termination
@@ -412,7 +458,7 @@ func (b *DockerRuntimeBase) Stop(ctx context.Context, instanceID string) error {
 	instance.StoppedAt = time.Now()
 	b.mu.Unlock()
 
-	logger.Info("Docker container stopped successfully: %s (container preserved)", instanceID)
+	log.Info("Docker container stopped successfully: %s (container preserved)", instanceID)
 
 	return nil
 }
@@ -447,7 +493,7 @@ func (b *DockerRuntimeBase) Remove(ctx context.Context, instanceID string) error
 	}
 
 	containerID := instance.Metadata["container_id"]
-	logger.Info("Removing Docker container: %s (instance: %s)", containerID[:12], instanceID)
+	log.Info("Removing Docker container: %s (instance: %s)", containerID[:12], instanceID)
 
 	removeOptions := container.RemoveOptions{
 		Force:         true, // Force remove even if running
@@ -463,7 +509,7 @@ func (b *DockerRuntimeBase) Remove(ctx context.Context, instanceID string) error
 	delete(b.instances, instanceID)
 	b.mu.Unlock()
 
-	logger.Info("Docker container removed successfully: %s", instanceID)
+	log.Info("Docker container removed successfully: %s", instanceID)
 
 	return nil
 }
@@ -580,7 +626,7 @@ func (b *DockerRuntimeBase) List(ctx context.Context) ([]*Instance, error) {
 		// Inspect container to get detailed state
 		stateInfo, err := InspectContainerState(ctx, b.client, c.ID)
 		if err != nil {
-			logger.Debug("Failed to inspect container %s during list: %v", c.ID[:12], err)
+			log.Debug("Failed to inspect container %s during list: %v", c.ID[:12], err)
 			stateInfo = &ContainerStateInfo{
 				State:        StateUnknown,
 				ErrorMessage: fmt.Sprintf("Failed to inspect: %v", err),
@@ -616,10 +662,35 @@ func (b *DockerRuntimeBase) List(ctx context.Context) ([]*Instance, error) {
 			"deployment_mode": c.Labels["xw.deployment_mode"],
 		}
 		
+		// Copy device_indices from label if present
+		if deviceIndices := c.Labels["xw.device_indices"]; deviceIndices != "" {
+			metadata["device_indices"] = deviceIndices
+		}
+		
 		// Copy max_concurrent from label if present
 		if maxConcurrent := c.Labels["xw.max_concurrent"]; maxConcurrent != "" {
 			metadata["max_concurrent"] = maxConcurrent
 		}
+		
+		// Copy max_retries from label if present
+		if maxRetries := c.Labels["xw.max_retries"]; maxRetries != "" {
+			metadata["max_retries"] = maxRetries
+		}
+		
+		// Copy restart_max from label if present
+		if restartMax := c.Labels["xw.restart_max"]; restartMax != "" {
+			metadata["restart_max"] = restartMax
+		}
+		
+		// Copy log_dir from label if present
+		if logDir := c.Labels["xw.log_dir"]; logDir != "" {
+			metadata["log_dir"] = logDir
+		}
+		
+		// Copy run_spec from label if present
+		if runSpec := c.Labels["xw.run_spec"]; runSpec != "" {
+			metadata["run_spec"] = runSpec
+		}
 
 		instance := &Instance{
 			ID:          instanceID,
@@ -653,14 +724,14 @@ func (b *DockerRuntimeBase) List(ctx context.Context) ([]*Instance, error) {
 // Parameters:
 //   - ctx: Context for cancellation and timeout control
 //   - instanceID: Unique identifier of the instance
-//   - follow: If true, stream continues with new logs; if false, return existing logs and close
+//   - opts: Log options; Follow streams new logs, Tail limits history to the last N lines
 //
 // Returns:
 //   - LogStream for reading log data
 //   - Error if instance not found or Docker operation fails
 //
 // Example:
-//   stream, err := runtime.Logs(ctx, "my-instance", true)
+//   stream, err := runtime.Logs(ctx, "my-instance", LogOptions{Follow: true, Tail: 100})
 //   if err != nil {
 //       return err
 //   }
@@ -668,7 +739,7 @@ func (b *DockerRuntimeBase) List(ctx context.Context) ([]*Instance, error) {
 //   io.Copy(os.Stdout, stream)
 //
 // Thread Safety: Safe for concurrent calls
-func (b *DockerRuntimeBase) Logs(ctx context.Context, instanceID string, follow bool) (LogStream, error) {
+func (b *DockerRuntimeBase) Logs(ctx context.Context, instanceID string, opts LogOptions) (LogStream, error) {
 	b.mu.RLock()
 	instance, exists := b.instances[instanceID]
 	b.mu.RUnlock()
@@ -677,13 +748,19 @@ func (b *DockerRuntimeBase) Logs(ctx context.Context, instanceID string, follow
 		return nil, fmt.Errorf("instance not found: %s", instanceID)
 	}
 
+	// Return all historical logs unless a tail is requested
+	tail := "all"
+	if opts.Tail > 0 {
+		tail = strconv.Itoa(opts.Tail)
+	}
+
 	containerID := instance.Metadata["container_id"]
 	options := container.LogsOptions{
-		ShowStdout: true,  // Include stdout stream
-		ShowStderr: true,  // Include stderr stream
-		Follow:     follow, // Stream new logs if true
-		Timestamps: true,  // Prepend RFC3339Nano timestamps
-		Tail:       "all", // Return all historical logs
+		ShowStdout: true,        // Include stdout stream
+		ShowStderr: true,        // Include stderr stream
+		Follow:     opts.Follow, // Stream new logs if true
+		Timestamps: true,        // Prepend RFC3339Nano timestamps
+		Tail:       tail,        // Number of historical lines
 	}
 
 	reader, err := b.client.ContainerLogs(ctx, containerID, options)
@@ -745,7 +822,7 @@ func (b *DockerRuntimeBase) LoadExistingContainers(ctx context.Context) error {
 	for _, c := range containers {
 		instanceID := c.Labels["xw.instance_id"]
 		if instanceID == "" {
-			logger.Warn("Skipping container %s: missing xw.instance_id label", c.ID[:12])
+			log.Warn("Skipping container %s: missing xw.instance_id label", c.ID[:12])
 			continue
 		}
 
@@ -753,7 +830,7 @@ func (b *DockerRuntimeBase) LoadExistingContainers(ctx context.Context) error {
 		if b.serverName != "" {
 			containerServerName := c.Labels["xw.server_name"]
 			if containerServerName != b.serverName {
-				logger.Debug("Skipping container %s: belongs to server '%s', not '%s'",
+				log.Debug("Skipping container %s: belongs to server '%s', not '%s'",
 					c.ID[:12], containerServerName, b.serverName)
 				continue
 			}
@@ -762,7 +839,7 @@ func (b *DockerRuntimeBase) LoadExistingContainers(ctx context.Context) error {
 		// Inspect container to get detailed state using centralized state management
 		stateInfo, err := InspectContainerState(ctx, b.client, c.ID)
 		if err != nil {
-			logger.Warn("Failed to inspect container %s (instance %s) during load: %v",
+			log.Warn("Failed to inspect container %s (instance %s) during load: %v",
 				c.ID[:12], instanceID, err)
 			// Fallback: use basic state from list
 			stateInfo = &ContainerStateInfo{
@@ -783,7 +860,7 @@ func (b *DockerRuntimeBase) LoadExistingContainers(ctx context.Context) error {
 
 		// Warn if no port mapping found (cannot be accessed via API)
 		if port == 0 {
-			logger.Warn("Container %s (instance: %s) has no port mapping - "+
+			log.Warn("Container %s (instance: %s) has no port mapping - "+
 				"it cannot be accessed via proxy API. Consider recreating with port allocation.",
 				c.ID[:12], instanceID)
 		} else {
@@ -831,15 +908,15 @@ func (b *DockerRuntimeBase) LoadExistingContainers(ctx context.Context) error {
 		loadedCount++
 
 		if stateInfo.ErrorMessage != "" {
-			logger.Warn("Loaded container %s (instance %s) in error state: %s [port: %d]",
+			log.Warn("Loaded container %s (instance %s) in error state: %s [port: %d]",
 				c.ID[:12], instanceID, stateInfo.ErrorMessage, port)
 		} else {
-			logger.Info("Loaded container %s (instance %s) [state: %s, port: %d]",
+			log.Info("Loaded container %s (instance %s) [state: %s, port: %d]",
 				c.ID[:12], instanceID, stateInfo.State, port)
 		}
 	}
 
-	logger.Info("Loaded %d existing containers for runtime: %s", loadedCount, b.runtimeName)
+	log.Info("Loaded %d existing containers for runtime: %s", loadedCount, b.runtimeName)
 
 	return nil
 }
@@ -868,7 +945,7 @@ func (b *DockerRuntimeBase) ReloadContainers(ctx context.Context) error {
 	b.instances = make(map[string]*Instance)
 	b.mu.Unlock()
 
-	logger.Info("Reloading containers for runtime: %s", b.runtimeName)
+	log.Info("Reloading containers for runtime: %s", b.runtimeName)
 
 	// Reload with current server name filter
 	return b.LoadExistingContainers(ctx)
@@ -1120,11 +1197,12 @@ type DeviceSandbox interface {
 	//
 	// Parameters:
 	//   - devices: List of devices to get the image for
+	//   - arch: Image architecture ("arm64" or "amd64"); empty for the host architecture
 	//
 	// Returns:
 	//   - Docker image URL (e.g., "quay.io/ascend/vllm-ascend:v0.11.0rc0")
 	//   - Error if image configuration is not found or invalid
-	GetDefaultImage(devices []DeviceInfo) (string, error)
+	GetDefaultImage(devices []DeviceInfo, arch string) (string, error)
 
 	// GetDockerRuntime returns the Docker runtime to use for this device type.
 	//
@@ -1162,9 +1240,10 @@ func BoolPtr(b bool) *bool {
 // the appropriate Docker image based on device information and engine type. It:
 //   1. Extracts chip model name from device list
 //   2. Maps model name to configuration key
-//   3. Auto-detects system architecture
+//   3. Uses the requested architecture, or auto-detects the system architecture
 //   4. Looks up image from RuntimeImagesConfig
-//   5. Returns error if any step fails (no fallback)
+//   5. Returns error if any step fails (no fallback), including when the
+//      image for the host architecture is missing or set to NONE
 //
 // This is used by both vLLM and MindIE sandbox implementations to avoid code duplication.
 //
@@ -1172,11 +1251,12 @@ func BoolPtr(b bool) *bool {
 //   - runtimeImages: RuntimeImagesConfig instance (as interface{} to avoid import cycle)
 //   - devices: List of devices (uses first device's ModelName for chip identification)
 //   - engineName: Inference engine name (e.g., "vllm", "mindie")
+//   - arch: Image architecture ("arm64" or "amd64"); empty to auto-detect
 //
 // Returns:
 //   - Docker image URL if found
 //   - Error if configuration is invalid or image not found
-func GetImageForEngine(configMap map[string]map[string]map[string]string, devices []DeviceInfo, engineName string) (string, error) {
+func GetImageForEngine(configMap map[string]map[string]map[string]string, devices []DeviceInfo, engineName, arch string) (string, error) {
 	if configMap == nil {
 		return "", fmt.Errorf("invalid runtime images configuration")
 	}
@@ -1192,11 +1272,14 @@ func GetImageForEngine(configMap map[string]map[string]map[string]string, device
 		return "", fmt.Errorf("device config key is empty")
 	}
 	
-	// Get image for this chip model and engine (auto-detect architecture)
-	// We need to call the GetImageForChipAndEngineAuto logic inline to avoid import cycle
-	arch, err := getSystemArch()
-	if err != nil {
-		return "", fmt.Errorf("failed to detect system architecture: %w", err)
+	// Get image for this chip model and engine (auto-detect architecture
+	// unless one was requested, e.g. via `xw start --arch`)
+	if arch == "" {
+		var err error
+		arch, err = getSystemArch()
+		if err != nil {
+			return "", fmt.Errorf("failed to detect system architecture: %w", err)
+		}
 	}
 	
 	engineMap, ok := configMap[configKey]
@@ -1204,29 +1287,38 @@ func GetImageForEngine(configMap map[string]map[string]map[string]string, device
 		return "", fmt.Errorf("chip model %s not found in configuration", configKey)
 	}
 	
-	archMap, ok := engineMap[engineName]
-	if !ok {
-		return "", fmt.Errorf("engine %s not found for chip model %s", engineName, configKey)
+	// A missing engine or architecture entry, an empty value, and the NONE
+	// placeholder all mean there is no image for this host. Report what to
+	// configure instead of letting Docker fail on an invalid reference.
+	image := engineMap[engineName][arch]
+	if config.IsUnsetImage(image) {
+		return "", fmt.Errorf("no %s image configured for %s on %s; add runtime_images.%s.%s to devices.yaml",
+			engineName, arch, configKey, engineName, arch)
 	}
 	
-	image, ok := archMap[arch]
-	if !ok {
-		return "", fmt.Errorf("architecture %s not found for chip model %s and engine %s", arch, configKey, engineName)
-	}
-	
-	logger.Debug("Selected image for %s (%s): %s", configKey, engineName, image)
+	log.Debug("Selected image for %s (%s): %s", configKey, engineName, image)
 	return image, nil
 }
 
 // getSystemArch returns the current system architecture
 func getSystemArch() (string, error) {
-	switch runtimePkg.GOARCH {
+	return NormalizeArch(runtimePkg.GOARCH)
+}
+
+// NormalizeArch maps an architecture name to the key used in runtime_images
+// ("arm64" or "amd64"), accepting common aliases such as aarch64 and x86_64.
+//
+// Returns:
+//   - Normalized architecture name
+//   - Error if the architecture is not supported
+func NormalizeArch(arch string) (string, error) {
+	switch strings.ToLower(arch) {
 	case "arm64", "aarch64":
 		return "arm64", nil
 	case "amd64", "x86_64":
 		return "amd64", nil
 	default:
-		return "", fmt.Errorf("unsupported architecture: %s", runtimePkg.GOARCH)
+		return "", fmt.Errorf("unsupported architecture: %s (supported: arm64, amd64)", arch)
 	}
 }
 
@@ -1249,7 +1341,7 @@ func CheckDockerImageExists(ctx context.Context, imageName string) (bool, error)
 		return false, fmt.Errorf("image name cannot be empty")
 	}
 	
-	logger.Debug("Checking if Docker image exists: %s", imageName)
+	log.Debug("Checking if Docker image exists: %s", imageName)
 	
 	cmd := exec.CommandContext(ctx, "docker", "images", "-q", imageName)
 	output, err := cmd.Output()
@@ -1263,28 +1355,33 @@ func CheckDockerImageExists(ctx context.Context, imageName string) (bool, error)
 	
 	exists := len(strings.TrimSpace(string(output))) > 0
 	if exists {
-		logger.Debug("Docker image found locally: %s", imageName)
+		log.Debug("Docker image found locally: %s", imageName)
 	} else {
-		logger.Debug("Docker image not found locally: %s", imageName)
+		log.Debug("Docker image not found locally: %s", imageName)
 	}
 	
 	return exists, nil
 }
 
-// PullDockerImage pulls a Docker image from registry using docker CLI with PTY.
+// PullDockerImage pulls a Docker image from registry through the Docker API.
 //
 // This function pulls an image from the configured registry (or Docker Hub by default).
 // The pull operation shows progress through an optional event channel.
 //
 // Progress Events:
-//   - "DOCKER_CR|..." - Carriage return update (overwrite current line)
-//   - "DOCKER_LF|..." - Line feed update (new line)
+//   - "DOCKER_CR|<layer>: <status> <progress>" - Layer update (overwrites the layer's line)
+//   - "DOCKER_LF|..." - Pull status messages (new line)
 //   - Regular messages for status updates
+//   - pulling_image startup events with the overall percentage
 //
-// The function uses PTY to capture Docker's native progress bars and formatting.
+// The daemon's JSON progress stream is decoded (pkg/jsonmessage), so layer
+// status and the overall percentage are exact rather than parsed from
+// terminal output. Registry credentials stored by `docker login` are used
+// (see registryAuthFor). Cancelling ctx aborts the pull promptly.
 //
 // Parameters:
 //   - ctx: Context for cancellation and timeout control
+//   - cli: Docker API client
 //   - imageName: Full image name to pull
 //   - eventCh: Optional channel for progress events (can be nil)
 //
@@ -1293,134 +1390,96 @@ func CheckDockerImageExists(ctx context.Context, imageName string) (bool, error)
 //   - Error if pull fails or is cancelled
 //
 // Thread Safety: Safe for concurrent calls
-func PullDockerImage(ctx context.Context, imageName string, eventCh chan<- string) error {
+func PullDockerImage(ctx context.Context, cli client.APIClient, imageName string, eventCh chan<- string) error {
 	if imageName == "" {
 		return fmt.Errorf("image name cannot be empty")
 	}
 	
-	logger.Info("Pulling Docker image: %s", imageName)
+	log.Info("Pulling Docker image: %s", imageName)
 	sendEvent := func(msg string) {
 		if eventCh != nil {
 			select {
 			case eventCh <- msg:
 			default:
-				// Channel full or closed, skip
+				// Channel full, skip
 			}
 		}
 	}
 	
 	sendEvent(fmt.Sprintf("Pulling Docker image: %s", imageName))
+	sendStartupEvent(eventCh, api.StartupEvent{
+		Phase:   api.StartupPhasePullingImage,
+		Message: fmt.Sprintf("Pulling Docker image %s", imageName),
+	})
 	
-	cmd := exec.CommandContext(ctx, "docker", "pull", imageName)
-	
-	// Use PTY to capture native Docker progress bars
-	ptmx, err := pty.Start(cmd)
+	body, err := cli.ImagePull(ctx, imageName, image.PullOptions{
+		RegistryAuth: registryAuthFor(imageName),
+	})
 	if err != nil {
-		return fmt.Errorf("failed to start docker pull with pty: %w", err)
+		if ctx.Err() != nil {
+			sendEvent("Docker pull cancelled")
+			return fmt.Errorf("pull operation cancelled")
+		}
+		return fmt.Errorf("failed to pull image: %w", err)
 	}
-	defer ptmx.Close()
-	
-	// Read byte by byte to detect \r and \n separately
-	var line []byte
-	buf := make([]byte, 1)
+	defer body.Close()
 	
-	// Set read deadline to check context periodically
-	ptmx.SetReadDeadline(time.Now().Add(100 * time.Millisecond))
+	// Closing the body aborts a read blocked on a stalled registry
+	stop := context.AfterFunc(ctx, func() { body.Close() })
+	defer stop()
 	
+	progress := newPullProgress()
+	decoder := json.NewDecoder(body)
 	for {
-		// Check if context is cancelled
-		select {
-		case <-ctx.Done():
-			// Context cancelled, kill the process
-			if cmd.Process != nil {
-				cmd.Process.Kill()
+		var msg jsonmessage.JSONMessage
+		if err := decoder.Decode(&msg); err != nil {
+			if err == io.EOF {
+				break
 			}
-			return fmt.Errorf("pull operation cancelled")
-		default:
-		}
-		
-		n, err := ptmx.Read(buf)
-		if n > 0 {
-			ch := buf[0]
-			
-			if ch == '\r' {
-				// Carriage return - check if next char is \n (CRLF sequence)
-				if len(line) > 0 {
-					// Peek at next byte
-					next := make([]byte, 1)
-					ptmx.SetReadDeadline(time.Now().Add(1 * time.Millisecond))
-					nn, _ := ptmx.Read(next)
-					ptmx.SetReadDeadline(time.Now().Add(100 * time.Millisecond))
-					
-					if nn > 0 && next[0] == '\n' {
-						// CRLF sequence - treat as newline
-						sendEvent("DOCKER_LF|" + string(line))
-					} else {
-						// Just CR - overwrite current line
-						sendEvent("DOCKER_CR|" + string(line))
-						// Put back the peeked byte if it's not \n
-						if nn > 0 {
-							line = append(line[:0], next[0])
-							continue
-						}
-					}
-					line = line[:0]
-				}
-			} else if ch == '\n' {
-				// Line feed - send line with LF marker (for new line)
-				if len(line) > 0 {
-					sendEvent("DOCKER_LF|" + string(line))
-					line = line[:0]
-				}
-			} else {
-				line = append(line, ch)
+			if ctx.Err() != nil {
+				sendEvent("Docker pull cancelled")
+				return fmt.Errorf("pull operation cancelled")
 			}
-			
-			// Reset deadline after successful read
-			ptmx.SetReadDeadline(time.Now().Add(100 * time.Millisecond))
+			return fmt.Errorf("failed to read pull progress: %w", err)
+		}
+		if msg.Error != nil {
+			return fmt.Errorf("failed to pull image: %s", msg.Error.Message)
 		}
 		
-		if err == io.EOF {
-			if len(line) > 0 {
-				sendEvent("DOCKER_LF|" + string(line))
+		// Messages without a layer ID are pull-wide status lines, as is
+		// "<tag>: Pulling from <repository>"
+		if msg.ID == "" || strings.HasPrefix(msg.Status, "Pulling from") {
+			if msg.Status != "" {
+				sendEvent("DOCKER_LF|" + strings.TrimSpace(msg.ID+" "+msg.Status))
 			}
-			break
+			continue
 		}
-		if err != nil {
-			// Check if it's a timeout error (expected for context checking)
-			if netErr, ok := err.(interface{ Timeout() bool }); ok && netErr.Timeout() {
-				// Timeout is expected, continue to next iteration
-				ptmx.SetReadDeadline(time.Now().Add(100 * time.Millisecond))
-				continue
-			}
-			
-			// Other errors might be due to PTY closing when process ends
-			// Check if process has exited
-			if cmd.ProcessState != nil && cmd.ProcessState.Exited() {
-				// Process already exited, PTY closed naturally
-				break
-			}
-			
-			// If context was cancelled, this is expected
-			if ctx.Err() != nil {
-				break
+		
+		line := fmt.Sprintf("%s: %s", msg.ID, msg.Status)
+		var current, total int64
+		if msg.Progress != nil {
+			current, total = msg.Progress.Current, msg.Progress.Total
+			if total > 0 {
+				line += fmt.Sprintf(" %s/%s", units.HumanSize(float64(current)), units.HumanSize(float64(total)))
 			}
-			
-			// Real error - but could also be PTY closing, so just break
-			// and let cmd.Wait() determine if there was an actual error
-			break
 		}
-	}
-	
-	if err := cmd.Wait(); err != nil {
-		if ctx.Err() != nil {
-			return fmt.Errorf("pull operation cancelled")
+		sendEvent("DOCKER_CR|" + line)
+		
+		if percent, ok := progress.update(msg.ID, msg.Status, current, total); ok {
+			sendStartupEvent(eventCh, api.StartupEvent{
+				Phase:   api.StartupPhasePullingImage,
+				Percent: percent,
+			})
 		}
-		return fmt.Errorf("failed to pull image: %w", err)
 	}
 	
 	sendEvent(fmt.Sprintf("Successfully pulled image: %s", imageName))
-	logger.Info("Successfully pulled Docker image: %s", imageName)
+	sendStartupEvent(eventCh, api.StartupEvent{
+		Phase:   api.StartupPhasePullingImage,
+		Percent: 100,
+		Message: fmt.Sprintf("Pulled Docker image %s", imageName),
+	})
+	log.Info("Successfully pulled Docker image: %s", imageName)
 	
 	return nil
 }
@@ -1432,10 +1491,23 @@ func PullDockerImage(ctx context.Context, imageName string, eventCh chan<- strin
 // called by concrete runtime implementations (vllm:docker, mindie:docker) in
 // their Create methods.
 //
+// A missing image is loaded from CreateParams.ImageArchive if set, or from
+// an archive in CreateParams.ImageArchiveDir that contains it, before
+// falling back to a registry pull. This lets air-gapped hosts run models.
+// Pulls go through CreateParams.RegistryMirror when configured; the pulled
+// image is tagged with the original name so containers reference it as usual.
+//
+// An image pinned to a digest ("name[:tag]@sha256:...", see
+// config.SplitImageDigest) is only accepted if the local image's RepoDigests
+// contain that digest; otherwise it is pulled by digest and tagged as name,
+// replacing a stale or modified local copy. Archives are only used for
+// pinned images when given explicitly, as `docker save` archives do not
+// record digests.
+//
 // The method sends progress events through the CreateParams.EventChannel:
 //   - Checking image availability
 //   - Image found/not found status
-//   - Pull progress (if needed)
+//   - Archive load or pull progress (if needed)
 //
 // Parameters:
 //   - ctx: Context for cancellation and timeout control
@@ -1457,6 +1529,11 @@ func (b *DockerRuntimeBase) EnsureImage(ctx context.Context, imageName string, p
 		return nil // Skip if no image name provided
 	}
 	
+	// Dry runs must not pull images
+	if params != nil && params.DryRun {
+		return nil
+	}
+	
 	// Get event channel from params
 	var eventCh chan<- string
 	if params != nil {
@@ -1468,34 +1545,201 @@ func (b *DockerRuntimeBase) EnsureImage(ctx context.Context, imageName string, p
 			select {
 			case eventCh <- msg:
 			default:
-				// Channel full or closed, skip
+				// Channel full, skip
 			}
 		}
 	}
 	
 	sendEvent("Checking Docker image availability...")
-	logger.Debug("Ensuring Docker image is available: %s", imageName)
+	log.Debug("Ensuring Docker image is available: %s", imageName)
 	
-	// Check if image exists locally
-	exists, err := CheckDockerImageExists(ctx, imageName)
-	if err != nil {
-		return fmt.Errorf("failed to check Docker image: %w", err)
+	// Pinned images must match the expected digest, not just exist
+	name, digest := config.SplitImageDigest(imageName)
+	if digest != "" {
+		matches, err := b.imageHasDigest(ctx, name, digest)
+		if err != nil {
+			return fmt.Errorf("failed to check Docker image: %w", err)
+		}
+		if matches {
+			sendEvent(fmt.Sprintf("Docker image %s found locally (digest %s verified)", name, digest))
+			log.Debug("Docker image %s matches pinned digest %s", name, digest)
+			return nil
+		}
+		sendEvent(fmt.Sprintf("Local image %s does not match pinned digest %s", name, digest))
+		log.Info("Docker image %s is missing or does not match pinned digest %s", name, digest)
+	} else {
+		// Check if image exists locally
+		exists, err := CheckDockerImageExists(ctx, imageName)
+		if err != nil {
+			return fmt.Errorf("failed to check Docker image: %w", err)
+		}
+		
+		if exists {
+			sendEvent(fmt.Sprintf("Docker image %s found locally", imageName))
+			log.Debug("Docker image %s already exists locally", imageName)
+			return nil
+		}
 	}
 	
-	if exists {
-		sendEvent(fmt.Sprintf("Docker image %s found locally", imageName))
-		logger.Debug("Docker image %s already exists locally", imageName)
-		return nil
+	// Image doesn't exist, try offline archives first
+	if params != nil && (digest == "" || params.ImageArchive != "") {
+		loaded, err := loadImageFromArchive(ctx, name, params)
+		if err != nil {
+			return err
+		}
+		if loaded {
+			if digest != "" {
+				log.Warn("Cannot verify digest %s of %s loaded from %s", digest, name, params.ImageArchive)
+				sendEvent(fmt.Sprintf("Warning: image archives do not record digests; %s was not verified against %s", name, digest))
+			}
+			return nil
+		}
 	}
 	
-	// Image doesn't exist, pull it
-	if err := PullDockerImage(ctx, imageName, eventCh); err != nil {
+	// No archive available, pull it (through the registry mirror if configured).
+	// Pinned images are pulled by digest and then tagged with their name.
+	pullName := imageName
+	tagName := imageName
+	if digest != "" {
+		pullName = config.PinnedImageReference(name, digest)
+		tagName = name
+	}
+	if params != nil {
+		mirrored := config.RewriteImageRegistry(pullName, params.RegistryMirror)
+		if mirrored != pullName {
+			log.Info("Pulling %s via registry mirror: %s", pullName, mirrored)
+			sendEvent(fmt.Sprintf("Using registry mirror: %s", mirrored))
+			pullName = mirrored
+		}
+	}
+	
+	b.warnLowImageSpace(ctx, imageName, sendEvent)
+	
+	if err := PullDockerImage(ctx, b.client, pullName, eventCh); err != nil {
 		return fmt.Errorf("failed to pull Docker image: %w", err)
 	}
 	
+	if pullName != tagName {
+		if err := b.client.ImageTag(ctx, pullName, tagName); err != nil {
+			return fmt.Errorf("failed to tag pulled image %s as %s: %w", pullName, tagName, err)
+		}
+	}
+	
+	// A mirror serves the same manifest, so the digest must still match
+	if digest != "" {
+		matches, err := b.imageHasDigest(ctx, name, digest)
+		if err != nil {
+			return fmt.Errorf("failed to verify Docker image: %w", err)
+		}
+		if !matches {
+			return fmt.Errorf("pulled image %s does not match pinned digest %s", pullName, digest)
+		}
+		sendEvent(fmt.Sprintf("Verified digest %s of %s", digest, name))
+	}
+	
 	return nil
 }
 
+// imageHasDigest reports whether the local image name exists and its
+// RepoDigests contain the expected digest.
+func (b *DockerRuntimeBase) imageHasDigest(ctx context.Context, name, digest string) (bool, error) {
+	inspect, err := b.client.ImageInspect(ctx, name)
+	if err != nil {
+		if cerrdefs.IsNotFound(err) {
+			return false, nil
+		}
+		return false, err
+	}
+	return config.HasImageDigest(inspect.RepoDigests, digest), nil
+}
+
+// Version returns the version of the Docker daemon the runtime uses.
+//
+// Parameters:
+//   - ctx: Context for the request
+//
+// Returns:
+//   - Version string (e.g., "Docker 27.1.1 (API 1.46)")
+//   - Error if the daemon cannot be reached
+func (b *DockerRuntimeBase) Version(ctx context.Context) (string, error) {
+	v, err := b.client.ServerVersion(ctx)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("Docker %s (API %s)", v.Version, v.APIVersion), nil
+}
+
+// expectedImageSize is the assumed size of an inference runtime image.
+// Registries do not report the unpacked size before pulling, and these
+// images (CUDA/CANN toolkits plus the engine) are typically 15-30 GB.
+const expectedImageSize int64 = 20 * 1024 * 1024 * 1024
+
+// warnLowImageSpace warns if the Docker data root has less free space than
+// an image pull is expected to need.
+//
+// This is advisory only: the pull proceeds regardless, because the data
+// root may belong to a remote daemon or the image may share layers that
+// are already present.
+func (b *DockerRuntimeBase) warnLowImageSpace(ctx context.Context, imageName string, sendEvent func(string)) {
+	info, err := b.client.Info(ctx)
+	if err != nil || info.DockerRootDir == "" {
+		return
+	}
+	
+	available, err := models.FreeDiskSpace(info.DockerRootDir)
+	if err != nil {
+		log.Debug("Could not check free space in %s: %v", info.DockerRootDir, err)
+		return
+	}
+	
+	if available < expectedImageSize {
+		msg := fmt.Sprintf("Warning: only %s free in %s; pulling %s may need about %s",
+			models.FormatBytes(available), info.DockerRootDir, imageName, models.FormatBytes(expectedImageSize))
+		log.Warn("%s", msg)
+		sendEvent(msg)
+	}
+}
+
+// loadImageFromArchive loads imageName from an offline image archive.
+//
+// An explicit params.ImageArchive must provide the image; otherwise an error
+// is returned. Archives in params.ImageArchiveDir are only used if one of
+// them contains the image.
+//
+// Returns:
+//   - true if the image was loaded
+//   - Error if loading fails or the explicit archive lacks the image
+func loadImageFromArchive(ctx context.Context, imageName string, params *CreateParams) (bool, error) {
+	installer := hooks.NewDockerInstaller(params.EventChannel)
+	
+	archive := params.ImageArchive
+	if archive == "" && params.ImageArchiveDir != "" {
+		found, err := installer.FindImageArchive(params.ImageArchiveDir, imageName)
+		if err != nil {
+			log.Warn("Failed to search image archives in %s: %v", params.ImageArchiveDir, err)
+			return false, nil
+		}
+		archive = found
+	}
+	if archive == "" {
+		return false, nil
+	}
+	
+	log.Info("Loading Docker image %s from archive %s", imageName, archive)
+	if err := installer.LoadImageFromTar(ctx, archive); err != nil {
+		return false, err
+	}
+	
+	exists, err := CheckDockerImageExists(ctx, imageName)
+	if err != nil {
+		return false, fmt.Errorf("failed to check Docker image: %w", err)
+	}
+	if !exists {
+		return false, fmt.Errorf("image archive %s does not contain %s", archive, imageName)
+	}
+	return true, nil
+}
+
 // ApplyTemplateParams applies template parameters from CreateParams to the environment map.
 //
 // This is a common Docker operation that converts template parameters (key=value format)
@@ -1521,7 +1765,7 @@ func (b *DockerRuntimeBase) ApplyTemplateParams(env map[string]string, params *C
 		return
 	}
 	
-	logger.Debug("Applying %d template parameter(s) to Docker environment", len(params.TemplateParams))
+	log.Debug("Applying %d template parameter(s) to Docker environment", len(params.TemplateParams))
 	
 	templateEnv := convertTemplateParamsToEnv(params.TemplateParams)
 	
@@ -1529,9 +1773,9 @@ func (b *DockerRuntimeBase) ApplyTemplateParams(env map[string]string, params *C
 	for k, v := range templateEnv {
 		if _, exists := env[k]; !exists {
 			env[k] = v
-			logger.Debug("Applied template param: %s=%s", k, v)
+			log.Debug("Applied template param: %s=%s", k, v)
 		} else {
-			logger.Debug("Template param %s=%s skipped (already set in environment)", k, v)
+			log.Debug("Template param %s=%s skipped (already set in environment)", k, v)
 		}
 	}
 }
@@ -0,0 +1,116 @@
+package runtime
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// drainPollInterval is how often in-flight requests are checked while draining.
+const drainPollInterval = 200 * time.Millisecond
+
+// drainTracker records instances that are being drained before a stop.
+//
+// A draining instance is reported as StateDraining, so the proxy stops
+// routing new requests to it while requests already in flight complete.
+type drainTracker struct {
+	mu       sync.Mutex
+	draining map[string]bool             // instanceID → draining
+	inFlight func(instanceID string) int // In-flight request counter, set by the proxy
+}
+
+// newDrainTracker creates an empty drain tracker.
+func newDrainTracker() *drainTracker {
+	return &drainTracker{
+		draining: make(map[string]bool),
+	}
+}
+
+// apply reports running instances that are being drained as StateDraining.
+func (t *drainTracker) apply(instances []*Instance) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	for _, inst := range instances {
+		if t.draining[inst.ID] && inst.State == StateRunning {
+			inst.State = StateDraining
+		}
+	}
+}
+
+// clear forgets the draining mark of an instance.
+func (t *drainTracker) clear(instanceID string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	delete(t.draining, instanceID)
+}
+
+// count returns the number of requests in flight for an instance, or zero
+// if no counter has been registered.
+func (t *drainTracker) count(instanceID string) int {
+	t.mu.Lock()
+	counter := t.inFlight
+	t.mu.Unlock()
+
+	if counter == nil {
+		return 0
+	}
+	return counter(instanceID)
+}
+
+// SetInFlightCounter registers the function used to count requests in
+// flight for an instance. The proxy registers its concurrency tracker here
+// so that Drain can wait for requests to complete.
+func (m *Manager) SetInFlightCounter(counter func(instanceID string) int) {
+	m.drain.mu.Lock()
+	defer m.drain.mu.Unlock()
+	m.drain.inFlight = counter
+}
+
+// Drain stops routing new requests to an instance and waits for the
+// requests already in flight to complete.
+//
+// The instance is marked as draining immediately, so it is listed as
+// StateDraining and the proxy no longer selects it. Drain returns once no
+// requests are in flight, or when the timeout or context expires; in that
+// case the remaining requests are abandoned and the caller proceeds with
+// the stop anyway. The draining mark is kept until the instance is removed.
+//
+// Parameters:
+//   - ctx: Context for cancellation
+//   - instanceID: ID of the instance to drain
+//   - timeout: Maximum time to wait for in-flight requests
+func (m *Manager) Drain(ctx context.Context, instanceID string, timeout time.Duration) {
+	m.drain.mu.Lock()
+	m.drain.draining[instanceID] = true
+	m.drain.mu.Unlock()
+	m.notifyInstancesChanged()
+
+	remaining := m.drain.count(instanceID)
+	if remaining == 0 {
+		return
+	}
+	log.Info("Draining instance %s: waiting for %d in-flight request(s) (timeout %v)",
+		instanceID, remaining, timeout)
+
+	deadline := time.NewTimer(timeout)
+	defer deadline.Stop()
+	ticker := time.NewTicker(drainPollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			if remaining = m.drain.count(instanceID); remaining == 0 {
+				log.Info("Instance %s drained", instanceID)
+				return
+			}
+		case <-deadline.C:
+			log.Warn("Drain timeout for instance %s, stopping with %d request(s) in flight",
+				instanceID, remaining)
+			return
+		case <-ctx.Done():
+			return
+		}
+	}
+}
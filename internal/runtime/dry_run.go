@@ -0,0 +1,127 @@
+package runtime
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/go-connections/nat"
+)
+
+// DryRunResult is returned as an error by CreateContainerWithLabels when
+// CreateParams.DryRun is set. It carries the fully resolved container
+// configuration rendered as an equivalent `docker run` command instead of
+// creating the container.
+//
+// Runtimes return it unchanged from Create, so callers can detect a dry run
+// with errors.As and report the command to the user.
+type DryRunResult struct {
+	Command string
+}
+
+// Error implements the error interface.
+func (r *DryRunResult) Error() string {
+	return "dry run: container not created"
+}
+
+// FormatDockerRunCommand renders a container configuration as a `docker run`
+// command line, one option per line, for inspection and debugging.
+//
+// Only the options xw sets are rendered: name, labels, environment, ports,
+// devices, mounts, runtime, privileges, shared memory, init, and the image
+// with its command. Empty values (such as a missing image) are kept visible
+// so configuration mistakes stand out.
+func FormatDockerRunCommand(name string, cfg *container.Config, hostCfg *container.HostConfig) string {
+	args := []string{"docker run -d"}
+	add := func(format string, a ...interface{}) {
+		args = append(args, fmt.Sprintf(format, a...))
+	}
+
+	add("--name %s", shellQuote(name))
+
+	labelKeys := make([]string, 0, len(cfg.Labels))
+	for k := range cfg.Labels {
+		labelKeys = append(labelKeys, k)
+	}
+	sort.Strings(labelKeys)
+	for _, k := range labelKeys {
+		add("--label %s", shellQuote(k+"="+cfg.Labels[k]))
+	}
+
+	env := append([]string(nil), cfg.Env...)
+	sort.Strings(env)
+	for _, e := range env {
+		add("-e %s", shellQuote(e))
+	}
+
+	if hostCfg != nil {
+		ports := make([]string, 0, len(hostCfg.PortBindings))
+		for port := range hostCfg.PortBindings {
+			ports = append(ports, string(port))
+		}
+		sort.Strings(ports)
+		for _, p := range ports {
+			for _, b := range hostCfg.PortBindings[nat.Port(p)] {
+				add("-p %s:%s:%s", b.HostIP, b.HostPort, nat.Port(p).Port())
+			}
+		}
+
+		for _, d := range hostCfg.Devices {
+			add("--device %s:%s:%s", d.PathOnHost, d.PathInContainer, d.CgroupPermissions)
+		}
+
+		for _, m := range hostCfg.Mounts {
+			spec := fmt.Sprintf("type=%s,source=%s,target=%s", m.Type, m.Source, m.Target)
+			if m.ReadOnly {
+				spec += ",readonly"
+			}
+			add("--mount %s", shellQuote(spec))
+		}
+		for _, bind := range hostCfg.Binds {
+			add("-v %s", shellQuote(bind))
+		}
+
+		if hostCfg.NetworkMode != "" {
+			add("--network %s", hostCfg.NetworkMode)
+		}
+		if hostCfg.Runtime != "" {
+			add("--runtime %s", hostCfg.Runtime)
+		}
+		if hostCfg.Privileged {
+			add("--privileged")
+		}
+		if hostCfg.IpcMode != "" {
+			add("--ipc %s", hostCfg.IpcMode)
+		}
+		if hostCfg.ShmSize > 0 {
+			add("--shm-size %d", hostCfg.ShmSize)
+		}
+		if hostCfg.Init != nil && *hostCfg.Init {
+			add("--init")
+		}
+	}
+
+	if cfg.Entrypoint != nil {
+		add("--entrypoint %s", shellQuote(strings.Join(cfg.Entrypoint, " ")))
+	}
+
+	image := cfg.Image
+	if image == "" {
+		image = "<no image configured>"
+	}
+	add("%s", image)
+	for _, c := range cfg.Cmd {
+		args[len(args)-1] += " " + shellQuote(c)
+	}
+
+	return strings.Join(args, " \\\n  ")
+}
+
+// shellQuote quotes s for a POSIX shell if it contains special characters.
+func shellQuote(s string) string {
+	if s != "" && !strings.ContainsAny(s, " \t\n'\"\\$`!*?;&|<>()[]{}#~") {
+		return s
+	}
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
@@ -11,7 +11,6 @@ import (
 	"strings"
 
 	"github.com/tsingmaoai/xw-cli/internal/config"
-	"github.com/tsingmaoai/xw-cli/internal/logger"
 )
 
 // ExtSandbox is a configuration-driven implementation of the DeviceSandbox interface.
@@ -141,7 +140,7 @@ func (s *ExtSandbox) PrepareEnvironment(devices []DeviceInfo) (map[string]string
 		env[s.conf.DeviceEnv] = strings.Join(indices, ",")
 	}
 
-	logger.Debug("Prepared environment for %s: %d variables, %d devices",
+	log.Debug("Prepared environment for %s: %d variables, %d devices",
 		s.deviceType, len(env), len(devices))
 
 	return env, nil
@@ -204,7 +203,7 @@ func (s *ExtSandbox) GetDeviceMounts(devices []DeviceInfo) ([]string, error) {
 		}
 	}
 
-	logger.Debug("Device mounts for %s: %d paths (%d indexed, %d shared)",
+	log.Debug("Device mounts for %s: %d paths (%d indexed, %d shared)",
 		s.deviceType, len(mounts), len(devices), len(mounts)-len(devices))
 
 	return mounts, nil
@@ -297,19 +296,19 @@ func (s *ExtSandbox) GetCapabilities() []string {
 // is required by the DeviceSandbox interface.
 //
 // The actual image lookup is performed by the runtime using the device's
-// ConfigKey and engine name.
+// ConfigKey, engine name, and the requested architecture (host if empty).
 //
 // Returns:
 //   - Empty string (image selection handled by runtime)
 //   - Error indicating this method is not supported
-func (s *ExtSandbox) GetDefaultImage(devices []DeviceInfo) (string, error) {
+func (s *ExtSandbox) GetDefaultImage(devices []DeviceInfo, arch string) (string, error) {
 	// Load runtime images configuration
 	runtimeImages, err := config.LoadRuntimeImagesConfig()
 	if err != nil {
 		return "", fmt.Errorf("failed to load runtime images config: %w", err)
 	}
 	
-	return GetImageForEngine(runtimeImages, devices, s.engineName)
+	return GetImageForEngine(runtimeImages, devices, s.engineName, arch)
 }
 
 // GetDockerRuntime returns the Docker runtime to use for this device.
@@ -428,7 +427,7 @@ func LoadExtendedSandboxes(engineName string) []func() DeviceSandbox {
 	extConfigs := config.LoadExtSandboxesFromDevices(engineName)
 
 	if len(extConfigs) == 0 {
-		logger.Debug("No extended sandboxes configured for %s", engineName)
+		log.Debug("No extended sandboxes configured for %s", engineName)
 		return nil
 	}
 
@@ -445,7 +444,7 @@ func LoadExtendedSandboxes(engineName string) []func() DeviceSandbox {
 			return NewExtSandbox(dt, eng, cfg)
 		})
 
-		logger.Info("Loaded extended sandbox for %s: %s", engineName, deviceType)
+		log.Info("Loaded extended sandbox for %s: %s", engineName, deviceType)
 	}
 
 	return sandboxes
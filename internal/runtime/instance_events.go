@@ -0,0 +1,41 @@
+package runtime
+
+// SetInstanceListener registers the function called when instances are
+// started, stopped, removed, renamed, drained or reconfigured. The proxy
+// registers its instance cache here so routing never waits for the cache
+// to expire after a lifecycle change made through the manager.
+func (m *Manager) SetInstanceListener(listener func()) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.instanceListener = listener
+}
+
+// notifyInstancesChanged calls the registered instance listener, if any.
+func (m *Manager) notifyInstancesChanged() {
+	m.mu.RLock()
+	listener := m.instanceListener
+	m.mu.RUnlock()
+	if listener != nil {
+		listener()
+	}
+}
+
+// SetInstanceRemovedListener registers the function called with the ID of
+// an instance after it is removed. The proxy drops the instance's metric
+// series and concurrency state here, so removed instances do not
+// accumulate.
+func (m *Manager) SetInstanceRemovedListener(listener func(instanceID string)) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.removedListener = listener
+}
+
+// notifyInstanceRemoved calls the registered removal listener, if any.
+func (m *Manager) notifyInstanceRemoved(instanceID string) {
+	m.mu.RLock()
+	listener := m.removedListener
+	m.mu.RUnlock()
+	if listener != nil {
+		listener(instanceID)
+	}
+}
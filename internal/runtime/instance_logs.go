@@ -0,0 +1,67 @@
+package runtime
+
+import (
+	"path/filepath"
+
+	"github.com/docker/docker/api/types/mount"
+)
+
+// ContainerLogDir is where an instance's host log directory is mounted
+// inside its container.
+const ContainerLogDir = "/var/log/xw"
+
+// InstanceLogEnv points the engine's log files at ContainerLogDir.
+//
+// Engines write their detailed logs (e.g., the CANN plog holding the stack
+// of an "Execute fail" error) to files inside the container, which are lost
+// when the container is removed. Redirecting them to the mounted host
+// directory keeps them available after a failed start. Variables already
+// set, e.g. with --env, are not overridden.
+//
+// Parameters:
+//   - params: Instance creation parameters
+//   - env: Container environment, updated in place
+func InstanceLogEnv(params *CreateParams, env map[string]string) {
+	if params.LogDir == "" {
+		return
+	}
+	instanceLogEnvAt(ContainerLogDir, env)
+}
+
+// instanceLogEnvAt points the engine's log files at dir, without overriding
+// variables already set. Native instances use the host log directory
+// directly.
+func instanceLogEnvAt(dir string, env map[string]string) {
+	logEnv := map[string]string{
+		"XW_LOG_DIR":              dir,
+		"ASCEND_PROCESS_LOG_PATH": filepath.Join(dir, "ascend"), // CANN runtime logs
+		"MINDIE_LOG_PATH":         filepath.Join(dir, "mindie"), // MindIE service logs
+	}
+	for k, v := range logEnv {
+		if _, set := env[k]; !set {
+			env[k] = v
+		}
+	}
+}
+
+// InstanceLogMounts returns the bind mount of the instance's host log
+// directory (params.LogDir) at ContainerLogDir, or nil if the instance has
+// no log directory.
+//
+// Parameters:
+//   - params: Instance creation parameters
+//
+// Returns:
+//   - Mounts to add to the container's host configuration
+func InstanceLogMounts(params *CreateParams) []mount.Mount {
+	if params.LogDir == "" {
+		return nil
+	}
+	return []mount.Mount{
+		{
+			Type:   mount.TypeBind,
+			Source: params.LogDir,
+			Target: ContainerLogDir,
+		},
+	}
+}
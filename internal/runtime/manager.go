@@ -7,7 +7,10 @@ package runtime
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"os"
+	"path/filepath"
 	"strconv"
 	"strings"
 	"sync"
@@ -20,6 +23,9 @@ import (
 	"github.com/tsingmaoai/xw-cli/internal/models"
 )
 
+// log is the runtime component logger (XW_LOG=runtime=LEVEL).
+var log = logger.Named("runtime")
+
 // Manager manages multiple runtime implementations.
 type Manager struct {
 	mu              sync.RWMutex
@@ -31,6 +37,13 @@ type Manager struct {
 	stopCh          chan struct{}
 	wg              sync.WaitGroup
 	serverName      string              // Server unique identifier for multi-server support
+	readiness       *readinessTracker   // Endpoint readiness probe results
+	supervisor      *restartSupervisor  // Crash restart budgets
+	drain           *drainTracker       // Instances draining before stop
+	overrides       *instanceOverrides  // Settings changed on live instances
+	concurrencyListener func(instanceID string, maxConcurrent int) // Notified of concurrency limit changes
+	instanceListener    func()                                    // Notified of instance lifecycle changes
+	removedListener     func(instanceID string)                   // Notified of removed instances
 }
 
 // NewManager creates a new runtime manager with the given server name and configuration.
@@ -47,6 +60,10 @@ func NewManager(serverName string, cfg *config.Config) (*Manager, error) {
 		config:          cfg,
 		stopCh:          make(chan struct{}),
 		serverName:      serverName,
+		readiness:       newReadinessTracker(),
+		supervisor:      newRestartSupervisor(),
+		drain:           newDrainTracker(),
+		overrides:       newInstanceOverrides(instanceOverridesPath(cfg)),
 	}, nil
 }
 
@@ -75,7 +92,7 @@ func (m *Manager) SetServerName(name string) {
 		if reloadable, ok := rt.(interface{ ReloadContainers(context.Context) error }); ok {
 			ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 			if err := reloadable.ReloadContainers(ctx); err != nil {
-				logger.Warn("Failed to reload containers for runtime: %v", err)
+				log.Warn("Failed to reload containers for runtime: %v", err)
 			}
 			cancel()
 		}
@@ -95,11 +112,79 @@ func (m *Manager) getOrCreateAllocator(configDir string) (*device.Allocator, err
 			return nil, fmt.Errorf("failed to create device allocator: %w", err)
 		}
 		m.deviceAllocator = allocator
+		
+		// Devices of runtimes that do not use containers (e.g., native
+		// processes) are not visible to the allocator's Docker query
+		allocator.AddDeviceOwnerSource(m.nativeDeviceOwners)
 	}
 	
 	return m.deviceAllocator, nil
 }
 
+// nativeDeviceOwners maps the devices used by instances of runtimes that
+// track device ownership themselves (see NativeRuntimeBase.DeviceOwners)
+// to their instance IDs.
+//
+// Returns:
+//   - Map from device index to instance ID
+func (m *Manager) nativeDeviceOwners() map[int]string {
+	type deviceOwnerRuntime interface {
+		DeviceOwners() map[int]string
+	}
+	
+	m.mu.RLock()
+	var sources []deviceOwnerRuntime
+	for _, rt := range m.runtimes {
+		if src, ok := rt.(deviceOwnerRuntime); ok {
+			sources = append(sources, src)
+		}
+	}
+	m.mu.RUnlock()
+	
+	owners := make(map[int]string)
+	for _, src := range sources {
+		for idx, instanceID := range src.DeviceOwners() {
+			owners[idx] = instanceID
+		}
+	}
+	return owners
+}
+
+// RuntimeVersions returns the versions reported by the registered runtimes
+// that can report one, such as the Docker daemon behind Docker runtimes or
+// the engine installed for native runtimes.
+//
+// Parameters:
+//   - ctx: Context bounding the version queries
+//
+// Returns:
+//   - Map from runtime name to version, or to the error if the query failed
+func (m *Manager) RuntimeVersions(ctx context.Context) map[string]string {
+	type versionRuntime interface {
+		Version(ctx context.Context) (string, error)
+	}
+	
+	m.mu.RLock()
+	sources := make(map[string]versionRuntime)
+	for name, rt := range m.runtimes {
+		if src, ok := rt.(versionRuntime); ok {
+			sources[name] = src
+		}
+	}
+	m.mu.RUnlock()
+	
+	versions := make(map[string]string, len(sources))
+	for name, src := range sources {
+		version, err := src.Version(ctx)
+		if err != nil {
+			versions[name] = fmt.Sprintf("unavailable: %v", err)
+			continue
+		}
+		versions[name] = version
+	}
+	return versions
+}
+
 // RegisterRuntime registers a runtime implementation.
 func (m *Manager) RegisterRuntime(runtime Runtime) error {
 	if runtime == nil {
@@ -188,7 +273,7 @@ func (m *Manager) Create(ctx context.Context, runtimeName string, params *Create
 		
 		tensorParallel = configTP
 		worldSize = configTP
-		logger.Info("Using specified tensor_parallel: TP=%d, WORLD_SIZE=%d", tensorParallel, worldSize)
+		log.Info("Using specified tensor_parallel: TP=%d, WORLD_SIZE=%d", tensorParallel, worldSize)
 		
 	} else if hasDevice {
 		// Priority 2: Only --device specified
@@ -199,7 +284,7 @@ func (m *Manager) Create(ctx context.Context, runtimeName string, params *Create
 		tensorParallel = deviceCount
 		worldSize = deviceCount
 		needDeviceAllocation = false // Devices already specified
-		logger.Info("Using specified devices: TP=%d, WORLD_SIZE=%d, Devices=%d", 
+		log.Info("Using specified devices: TP=%d, WORLD_SIZE=%d, Devices=%d", 
 			tensorParallel, worldSize, deviceCount)
 			
 	} else if templateWorldSize > 0 {
@@ -211,14 +296,14 @@ func (m *Manager) Create(ctx context.Context, runtimeName string, params *Create
 		tensorParallel = templateWorldSize
 		worldSize = templateWorldSize
 		needDeviceAllocation = true // Need to allocate devices based on template
-		logger.Info("Using template world_size: TP=%d, WORLD_SIZE=%d", tensorParallel, worldSize)
+		log.Info("Using template world_size: TP=%d, WORLD_SIZE=%d", tensorParallel, worldSize)
 		
 	} else {
 		// Priority 4: Nothing specified - no device allocation
 		tensorParallel = 0
 		worldSize = 0
 		needDeviceAllocation = false
-		logger.Info("No parallelism parameters specified, world_size=0, no device allocation")
+		log.Info("No parallelism parameters specified, world_size=0, no device allocation")
 	}
 	
 	// Allocate devices if needed
@@ -253,7 +338,7 @@ func (m *Manager) Create(ctx context.Context, runtimeName string, params *Create
 			}
 		}
 		
-		logger.Info("Allocated %d device(s) for instance %s", worldSize, params.InstanceID)
+		log.Info("Allocated %d device(s) for instance %s", worldSize, params.InstanceID)
 	}
 	
 	// Set computed parameters in CreateParams for runtime use
@@ -270,7 +355,7 @@ func (m *Manager) Create(ctx context.Context, runtimeName string, params *Create
 	// Template parameters are passed to runtime implementation
 	// Each runtime (docker/native) decides how to apply them (env vars, config files, etc.)
 	if len(params.TemplateParams) > 0 {
-		logger.Debug("Passing %d template parameter(s) to runtime implementation", len(params.TemplateParams))
+		log.Debug("Passing %d template parameter(s) to runtime implementation", len(params.TemplateParams))
 	}
 	
 	// Create the instance via runtime implementation
@@ -284,7 +369,16 @@ func (m *Manager) Start(ctx context.Context, instanceID string) error {
 	if err != nil {
 		return err
 	}
-	return rt.Start(ctx, instanceID)
+	defer m.notifyInstancesChanged()
+	if err := rt.Start(ctx, instanceID); err != nil {
+		return err
+	}
+	
+	// A kept container that starts now no longer counts as failed
+	if err := m.overrides.clearStartError(instanceID); err != nil {
+		log.Warn("Failed to clear start failure of %s: %v", instanceID, err)
+	}
+	return nil
 }
 
 // Stop stops an instance and releases its allocated devices.
@@ -296,6 +390,7 @@ func (m *Manager) Stop(ctx context.Context, instanceID string) error {
 	if err != nil {
 		return err
 	}
+	defer m.notifyInstancesChanged()
 	
 	// Stop the instance (which now also removes the container)
 	if err := rt.Stop(ctx, instanceID); err != nil {
@@ -305,7 +400,7 @@ func (m *Manager) Stop(ctx context.Context, instanceID string) error {
 	// Release allocated devices if allocator is initialized
 	if m.deviceAllocator != nil {
 		if err := m.deviceAllocator.Release(instanceID); err != nil {
-			logger.Warn("Failed to release devices for instance %s: %v", instanceID, err)
+			log.Warn("Failed to release devices for instance %s: %v", instanceID, err)
 		}
 	}
 	
@@ -318,16 +413,20 @@ func (m *Manager) Remove(ctx context.Context, instanceID string) error {
 	if err != nil {
 		return err
 	}
+	defer m.notifyInstancesChanged()
 	
 	// Remove the instance from runtime
 	if err := rt.Remove(ctx, instanceID); err != nil {
 		return err
 	}
+	m.drain.clear(instanceID)
+	m.overrides.clear(instanceID)
+	m.notifyInstanceRemoved(instanceID)
 	
 	// Release allocated devices if allocator is initialized
 	if m.deviceAllocator != nil {
 		if err := m.deviceAllocator.Release(instanceID); err != nil {
-			logger.Warn("Failed to release devices for instance %s: %v", instanceID, err)
+			log.Warn("Failed to release devices for instance %s: %v", instanceID, err)
 		}
 	}
 	
@@ -348,7 +447,38 @@ func (m *Manager) Get(ctx context.Context, instanceID string) (*Instance, error)
 }
 
 // List lists all instances across all runtimes.
+//
+// Running instances whose endpoint has not yet answered a readiness probe
+// are reported as StateStarting with the probe error in Metadata["health_error"].
+// List does not wait for probes; it reports the result of the last one.
+// Instances being drained are reported as StateDraining, and containers
+// kept after a failed start (keep_failed) as StateFailed.
 func (m *Manager) List(ctx context.Context) ([]*Instance, error) {
+	allInstances := m.listAll(ctx)
+	
+	// Renamed instances are reported under their new alias, and live
+	// concurrency limit changes replace the limit set at creation.
+	m.overrides.apply(allInstances)
+	
+	// Running containers are only reported as running once the inference
+	// endpoint answers; until then they are reported as starting. Probes run
+	// in the background, so this reports the last known result.
+	m.readiness.apply(allInstances)
+	
+	// Report restart counts and instances whose restart budget is exhausted.
+	m.supervisor.apply(allInstances)
+	
+	// Instances being drained before a stop no longer receive new requests.
+	m.drain.apply(allInstances)
+	
+	// Containers that failed to start and were kept for inspection.
+	m.overrides.applyStartFailures(allInstances)
+	
+	return allInstances, nil
+}
+
+// listAll aggregates instances from all runtimes without readiness mapping.
+func (m *Manager) listAll(ctx context.Context) []*Instance {
 	m.mu.RLock()
 	runtimes := make([]Runtime, 0, len(m.runtimes))
 	for _, rt := range m.runtimes {
@@ -360,30 +490,82 @@ func (m *Manager) List(ctx context.Context) ([]*Instance, error) {
 	for _, rt := range runtimes {
 		instances, err := rt.List(ctx)
 		if err != nil {
-			logger.Warn("Failed to list from %s: %v", rt.Name(), err)
+			log.Warn("Failed to list from %s: %v", rt.Name(), err)
 			continue
 		}
 		allInstances = append(allInstances, instances...)
 	}
 	
-	return allInstances, nil
+	return allInstances
 }
 
 // StartBackgroundTasks starts background maintenance tasks.
 func (m *Manager) StartBackgroundTasks() {
-	m.wg.Add(1)
+	m.wg.Add(2)
 	go m.maintenanceLoop()
-	logger.Info("Started runtime manager background tasks")
+	go m.readinessLoop()
+	log.Info("Started runtime manager background tasks")
+}
+
+// readinessLoop probes instances that are running but not yet ready.
+//
+// Probing in the background keeps List cheap and lets instances move from
+// starting to running shortly after their engine finishes warming up.
+func (m *Manager) readinessLoop() {
+	defer m.wg.Done()
+	
+	ticker := time.NewTicker(readinessProbeInterval)
+	defer ticker.Stop()
+	
+	for {
+		// Probe right away so instances that survived a server restart
+		// are reported as running again without waiting for a tick
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		m.readiness.probePending(ctx, m.listAll(ctx))
+		cancel()
+		
+		select {
+		case <-ticker.C:
+		case <-m.stopCh:
+			return
+		}
+	}
 }
 
 // Close shuts down the manager.
 func (m *Manager) Close() error {
 	close(m.stopCh)
 	m.wg.Wait()
-	logger.Info("Runtime manager shut down")
+	log.Info("Runtime manager shut down")
 	return nil
 }
 
+// StopAll stops every instance that is not already stopped.
+//
+// Used on server shutdown when instances should not outlive the server.
+// Errors are logged and do not stop the remaining instances.
+//
+// Parameters:
+//   - ctx: Context for cancellation and timeout
+//
+// Returns:
+//   - Number of instances stopped
+func (m *Manager) StopAll(ctx context.Context) int {
+	stopped := 0
+	for _, inst := range m.listAll(ctx) {
+		if inst.State == StateStopped {
+			continue
+		}
+		if err := m.Stop(ctx, inst.ID); err != nil {
+			log.Warn("Failed to stop instance %s: %v", inst.ID, err)
+			continue
+		}
+		log.Info("Stopped instance %s", inst.ID)
+		stopped++
+	}
+	return stopped
+}
+
 // findInstanceRuntime searches all registered runtimes for an instance.
 //
 // This method iterates through all runtimes to find the one that manages
@@ -408,6 +590,7 @@ func (m *Manager) findInstanceRuntime(ctx context.Context, instanceID string) (R
 	for _, rt := range runtimes {
 		instance, err := rt.Get(ctx, instanceID)
 		if err == nil {
+			m.overrides.apply([]*Instance{instance})
 			return rt, instance, nil
 		}
 	}
@@ -417,19 +600,21 @@ func (m *Manager) findInstanceRuntime(ctx context.Context, instanceID string) (R
 
 // maintenanceLoop runs periodic maintenance tasks in the background.
 //
-// This goroutine performs periodic maintenance such as checking instance
-// health, cleaning up stale resources, etc. It runs until the manager
+// This goroutine performs periodic maintenance such as restarting crashed
+// instances within their restart budget. It runs until the manager
 // is closed.
 func (m *Manager) maintenanceLoop() {
 	defer m.wg.Done()
 	
-	ticker := time.NewTicker(1 * time.Minute)
+	ticker := time.NewTicker(15 * time.Second)
 	defer ticker.Stop()
 	
 	for {
 		select {
 		case <-ticker.C:
-			// Periodic maintenance tasks
+			ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+			m.superviseRestarts(ctx)
+			cancel()
 		case <-m.stopCh:
 			return
 		}
@@ -476,11 +661,84 @@ func (m *Manager) Run(configDir, dataDir string, opts *RunOptions) (*RunInstance
 		}
 	}
 	
+	// Check that the model supports the chip and engine before allocating
+	// devices or pulling images, and choose the engine if none was given
+	if spec := models.GetModelSpec(opts.ModelID); spec != nil {
+		deviceList, _ := opts.AdditionalConfig["device"].(string)
+		chipKey := m.ChipConfigKey(configDir, deviceList)
+		if opts.BackendType == "" {
+			requestedArch, _ := opts.AdditionalConfig["arch"].(string)
+			if requestedArch != "" {
+				requestedArch, _ = NormalizeArch(requestedArch)
+			}
+			engine, err := m.selectEngine(spec, chipKey, opts.DeploymentMode, requestedArch)
+			if err != nil {
+				return nil, err
+			}
+			opts.BackendType = string(engine.Type)
+			opts.DeploymentMode = string(engine.Mode)
+			msg := fmt.Sprintf("Using engine %s:%s", opts.BackendType, opts.DeploymentMode)
+			if chipKey != "" {
+				msg += fmt.Sprintf(" (selected for %s)", chipKey)
+			}
+			log.Info("%s for %s", msg, opts.ModelID)
+			if opts.EventChannel != nil {
+				select {
+				case opts.EventChannel <- msg:
+				default:
+				}
+			}
+		} else {
+			engine, err := CheckModelCompatibility(spec, chipKey, opts.BackendType, opts.DeploymentMode)
+			if err != nil {
+				return nil, err
+			}
+			// Derive an omitted mode from the model's engine entry
+			// (e.g., "vllm" → "vllm:docker")
+			if opts.DeploymentMode == "" {
+				opts.DeploymentMode = string(engine.Mode)
+			}
+		}
+	}
+	
+	// Docker is the only deployment mode of models without an engine entry
+	if opts.BackendType != "" && opts.DeploymentMode == "" {
+		opts.DeploymentMode = string(api.DeploymentModeDocker)
+	}
+	
+	// Record the start request before normalizing it, so that
+	// `xw restart` can recreate the instance with identical parameters
+	runSpec := newRunSpec(opts)
+	
+	// Validate engine tuning options (--max-model-len, --gpu-memory-utilization)
+	if err := normalizeEngineLimits(opts); err != nil {
+		return nil, err
+	}
+	if err := normalizeShmSize(opts); err != nil {
+		return nil, err
+	}
+	
+	// Dry runs resolve the container configuration without creating anything
+	dryRun, _ := opts.AdditionalConfig["dry_run"].(bool)
+	
+	// Runtime image architecture override (defaults to the host architecture)
+	var arch string
+	if requested, ok := opts.AdditionalConfig["arch"].(string); ok && requested != "" {
+		normalized, err := NormalizeArch(requested)
+		if err != nil {
+			return nil, err
+		}
+		if host, err := getSystemArch(); err == nil && host != normalized {
+			log.Warn("Using %s runtime image on %s host; the container may fail to start without emulation", normalized, host)
+		}
+		arch = normalized
+	}
+	
 	// Check if an instance with this alias already exists
 	ctx := context.Background()
 	instances, err := m.List(ctx)
 	if err != nil {
-		logger.Warn("Failed to check existing instances: %v", err)
+		log.Warn("Failed to check existing instances: %v", err)
 	} else {
 		for _, inst := range instances {
 			existingAlias := inst.Alias
@@ -488,15 +746,25 @@ func (m *Manager) Run(configDir, dataDir string, opts *RunOptions) (*RunInstance
 				existingAlias = inst.ModelID // Backward compatibility
 			}
 			
+			if existingAlias != opts.Alias && inst.ID == opts.Alias {
+				// The instance ID (and container name) is derived from the
+				// alias it was created with, which a rename does not change.
+				return nil, fmt.Errorf("name '%s' is still used as the ID of instance '%s'; choose a different --name", 
+					opts.Alias, existingAlias)
+			}
+			
 			if existingAlias == opts.Alias {
-				// Found instance with same alias
-				if inst.State == StateRunning {
-					// Already running - error
-					return nil, fmt.Errorf("alias '%s' is already running. Stop it first with 'xw stop %s' or use a different --alias", 
-						opts.Alias, opts.Alias)
-				} else if inst.State == StateStopped {
+				// Found instance with same alias. Several instances of the
+				// same model may run side by side, but aliases must be unique.
+				if inst.State != StateStopped {
+					// Still present (running, warming up, draining or crashed) - error
+					return nil, fmt.Errorf("alias '%s' is already in use by an instance of %s (%s). Stop it first with 'xw stop %s' or use a different --name", 
+						opts.Alias, inst.ModelID, inst.State, opts.Alias)
+				} else if dryRun {
+					return nil, fmt.Errorf("alias '%s' belongs to a stopped instance that would be restarted; remove it or use a different --name to preview a new one", opts.Alias)
+				} else {
 					// Stopped - restart it
-					logger.Info("Found stopped instance with alias '%s', restarting it", opts.Alias)
+					log.Info("Found stopped instance with alias '%s', restarting it", opts.Alias)
 					
 					// Get the runtime for this instance
 					runtimeName := inst.RuntimeName
@@ -515,6 +783,7 @@ func (m *Manager) Run(configDir, dataDir string, opts *RunOptions) (*RunInstance
 					if err := rt.Start(startCtx, inst.ID); err != nil {
 						return nil, fmt.Errorf("failed to start existing instance: %w", err)
 					}
+					m.notifyInstancesChanged()
 					
 					// Refresh instance data
 					refreshedInst, err := rt.Get(startCtx, inst.ID)
@@ -526,7 +795,7 @@ func (m *Manager) Run(configDir, dataDir string, opts *RunOptions) (*RunInstance
 				return &RunInstance{
 						ID:             refreshedInst.ID,
 						ModelID:        refreshedInst.ModelID,
-						Alias:          refreshedInst.Alias,
+						Alias:          opts.Alias,
 						BackendType:    refreshedInst.Metadata["backend_type"],
 						DeploymentMode: refreshedInst.Metadata["deployment_mode"],
 						State:          refreshedInst.State,
@@ -552,8 +821,9 @@ func (m *Manager) Run(configDir, dataDir string, opts *RunOptions) (*RunInstance
 	m.mu.RUnlock()
 	
 	if !exists {
-		return nil, fmt.Errorf("runtime %s not available", runtimeName)
-}
+		return nil, fmt.Errorf("runtime %s not available (available runtimes: %s)",
+			runtimeName, strings.Join(m.availableRuntimes(), ", "))
+	}
 
 	// Generate unique instance ID
 	// If alias is set, use it as the instance ID; otherwise generate with timestamp
@@ -562,6 +832,16 @@ func (m *Manager) Run(configDir, dataDir string, opts *RunOptions) (*RunInstance
 		instanceID = fmt.Sprintf("%s-%d", opts.ModelID, time.Now().Unix())
 	}
 	
+	// Devices selected or allocated below are reserved in the allocator so
+	// that concurrent runs cannot pick them. Once this returns, either the
+	// container is running (Docker labels track its devices) or nothing was
+	// created, so the reservation is no longer needed in both cases.
+	defer func() {
+		if m.deviceAllocator != nil {
+			_ = m.deviceAllocator.Release(instanceID)
+		}
+	}()
+	
 	// Validate model path
 	if opts.ModelPath == "" {
 		return nil, fmt.Errorf("model path is required")
@@ -585,16 +865,14 @@ func (m *Manager) Run(configDir, dataDir string, opts *RunOptions) (*RunInstance
 			return nil, fmt.Errorf("invalid device list: %w", err)
 		}
 		
-		// Get all devices from the system
-		allDevices := allocator.GetAllDevices()
+		// Validate the requested devices against detection and current allocations
+		selected, err := allocator.SelectDevices(instanceID, deviceIndices)
+		if err != nil {
+			return nil, err
+		}
 		
-		// Select the requested devices
-		devices = make([]DeviceInfo, 0, len(deviceIndices))
-		for _, idx := range deviceIndices {
-			if idx >= len(allDevices) {
-				return nil, fmt.Errorf("device index %d out of range (available: %d devices)", idx, len(allDevices))
-			}
-			dev := allDevices[idx]
+		devices = make([]DeviceInfo, 0, len(selected))
+		for _, dev := range selected {
 			devices = append(devices, DeviceInfo{
 				Type:       api.DeviceType(dev.Type),
 				Index:      dev.Index,
@@ -606,7 +884,7 @@ func (m *Manager) Run(configDir, dataDir string, opts *RunOptions) (*RunInstance
 			})
 		}
 		
-		logger.Info("Using user-specified devices: %v", deviceIndices)
+		log.Info("Using user-specified devices: %v", deviceIndices)
 	}
 	// If no --device specified, devices will be empty
 	// Create() will allocate devices based on --tp, template world_size, or skip allocation
@@ -643,23 +921,29 @@ func (m *Manager) Run(configDir, dataDir string, opts *RunOptions) (*RunInstance
 	if chipConfigKey != "" {
 		backendName := opts.BackendType // Use backend name without mode (e.g., "vllm", not "vllm:docker")
 		
+		// Models created with 'xw cp' use their base model's templates
+		templateModelID := opts.ModelID
+		if spec := models.GetModelSpec(opts.ModelID); spec != nil {
+			templateModelID = spec.TemplateModelID()
+		}
+		
 		// Try variant-specific template first, fallback to base model template
 		lookupKey := chipVariantKey
 		if lookupKey == "" {
 			lookupKey = chipConfigKey
 		}
 		
-		templateParams = config.GetTemplateParams(m.config.RuntimeParams, lookupKey, opts.ModelID, backendName)
+		templateParams = config.GetTemplateParams(m.config.RuntimeParams, lookupKey, templateModelID, backendName)
 		
 		// If no variant-specific template found and we have a variant, try base model template
 		if len(templateParams) == 0 && chipVariantKey != "" && chipVariantKey != chipConfigKey {
-			logger.Debug("No variant-specific template for %s, trying base model %s", chipVariantKey, chipConfigKey)
-			templateParams = config.GetTemplateParams(m.config.RuntimeParams, chipConfigKey, opts.ModelID, backendName)
+			log.Debug("No variant-specific template for %s, trying base model %s", chipVariantKey, chipConfigKey)
+			templateParams = config.GetTemplateParams(m.config.RuntimeParams, chipConfigKey, templateModelID, backendName)
 		}
 		
 		if len(templateParams) > 0 {
-			logger.Info("Applied runtime template: %s_%s_%s with %d parameter(s)", 
-				lookupKey, opts.ModelID, backendName, len(templateParams))
+			log.Info("Applied runtime template: %s_%s_%s with %d parameter(s)", 
+				lookupKey, templateModelID, backendName, len(templateParams))
 		}
 	}
 	
@@ -671,7 +955,7 @@ func (m *Manager) Run(configDir, dataDir string, opts *RunOptions) (*RunInstance
 			// Extract image name and set it in ExtraConfig
 			imageName := strings.TrimPrefix(param, "image=")
 			extraConfig["image"] = imageName
-			logger.Info("Using custom Docker image from runtime template: %s", imageName)
+			log.Info("Using custom Docker image from runtime template: %s", imageName)
 			// Don't add to filteredTemplateParams to avoid it becoming an env var
 		} else {
 			// Keep all other parameters
@@ -679,6 +963,30 @@ func (m *Manager) Run(configDir, dataDir string, opts *RunOptions) (*RunInstance
 		}
 	}
 	
+	// User-supplied environment variables from --env (optional).
+	// Runtimes apply device and model variables on top, so these cannot
+	// break device visibility.
+	environment := make(map[string]string, len(opts.Environment))
+	for k, v := range opts.Environment {
+		environment[k] = v
+	}
+	if len(environment) > 0 {
+		log.Info("Passing %d user environment variable(s) to the instance", len(environment))
+	}
+	
+	// Offline image archive from --image-archive (optional)
+	imageArchive, _ := opts.AdditionalConfig["image_archive"].(string)
+	
+	// Host directory for the engine's log files, kept after the container
+	// is removed so crashes during warm-up can be diagnosed
+	logDir := filepath.Join(m.config.Storage.GetLogsDir(), instanceID)
+	if !dryRun {
+		if err := os.MkdirAll(logDir, 0755); err != nil {
+			log.Warn("Failed to create log directory %s: %v", logDir, err)
+			logDir = ""
+		}
+	}
+	
 	params := &CreateParams{
 		InstanceID:     instanceID,
 		ModelID:        opts.ModelID,
@@ -689,12 +997,19 @@ func (m *Manager) Run(configDir, dataDir string, opts *RunOptions) (*RunInstance
 		DeploymentMode: opts.DeploymentMode, // Pass deployment mode
 		ServerName:     m.serverName,        // Pass server name for container naming
 		DataDir:        m.dataDir,           // Pass data directory for runtime files
+		LogDir:         logDir,              // Engine log files on the host
 		Devices:        devices,
 		Port:           opts.Port,
-		Environment:    make(map[string]string),
+		Environment:    environment,
 		ExtraConfig:    extraConfig,
 		TemplateParams: filteredTemplateParams, // Use filtered params (image= extracted to ExtraConfig)
 		EventChannel:   opts.EventChannel,      // Pass event channel for progress updates
+		DryRun:          dryRun,
+		Arch:            arch,
+		ImageArchive:    imageArchive,
+		ImageArchiveDir: m.config.Storage.GetImagesDir(), // Archives checked before pulling
+		RegistryMirror:  m.config.GetRegistryMirror(),
+		RunSpec:         runSpec,
 	}
 
 	// Create context with timeout
@@ -703,19 +1018,59 @@ func (m *Manager) Run(configDir, dataDir string, opts *RunOptions) (*RunInstance
 	
 	// Create the instance using Manager.Create to apply unified parallelism management
 	instance, err := m.Create(ctx, runtimeName, params)
+	var dryRunResult *DryRunResult
+	if errors.As(err, &dryRunResult) {
+		// Nothing was created; devices reserved for the preview are
+		// released on return
+		return &RunInstance{
+			ID:             instanceID,
+			ModelID:        opts.ModelID,
+			Alias:          opts.Alias,
+			BackendType:    opts.BackendType,
+			DeploymentMode: opts.DeploymentMode,
+			Port:           opts.Port,
+			Config:         opts.AdditionalConfig,
+			DryRunCommand:  dryRunResult.Command,
+		}, nil
+	}
 	if err != nil {
 		return nil, err
 	}
 	
+	// failStart keeps the container for inspection if requested, otherwise
+	// removes it, and returns the start error
+	failStart := func(err error) error {
+		if keepFailed, _ := opts.AdditionalConfig["keep_failed"].(bool); keepFailed {
+			// Recorded persistently, so the supervisor never restarts it
+			if recordErr := m.overrides.setStartError(instanceID, err); recordErr != nil {
+				log.Warn("Failed to record start failure of %s: %v", instanceID, recordErr)
+			}
+			m.notifyInstancesChanged()
+			return fmt.Errorf("failed to start instance: %w (container kept for inspection: "+
+				"view its output with 'xw logs %s', remove it with 'xw rm --failed')", err, instanceID)
+		}
+		_ = rt.Remove(context.Background(), instanceID)
+		m.notifyInstanceRemoved(instanceID)
+		return fmt.Errorf("failed to start instance: %w", err)
+	}
+	
 	// Start the instance
 	if err := rt.Start(ctx, instanceID); err != nil {
-		// Clean up on failure
-		_ = rt.Remove(context.Background(), instanceID)
-		// Release allocated devices (if any were allocated in Create())
-		if m.deviceAllocator != nil {
-			_ = m.deviceAllocator.Release(instanceID)
-		}
-		return nil, fmt.Errorf("failed to start instance: %w", err)
+		return nil, failStart(err)
+	}
+	m.notifyInstancesChanged()
+	
+	// Engines that crash while loading the model (e.g., MindIE warm-up
+	// failures) exit before they become ready; report that here with the
+	// engine's output instead of returning a "running" instance
+	if err := m.watchEarlyExit(rt, instance, startupReadyTimeout(opts.AdditionalConfig), opts.EventChannel); err != nil {
+		return nil, failStart(err)
+	}
+	
+	// A failure recorded for an earlier instance with this ID (e.g. one
+	// restarted after --keep-failed) does not apply to this one
+	if err := m.overrides.clearStartError(instanceID); err != nil {
+		log.Warn("Failed to clear start failure of %s: %v", instanceID, err)
 	}
 	
 	// Convert to RunInstance for legacy API
@@ -733,14 +1088,59 @@ func (m *Manager) Run(configDir, dataDir string, opts *RunOptions) (*RunInstance
 		Config:         opts.AdditionalConfig,
 	}
 	
-	logger.Debug("Run returning: ID=%s, BackendType=%s, DeploymentMode=%s, Port=%d, opts.BackendType=%s", 
+	log.Debug("Run returning: ID=%s, BackendType=%s, DeploymentMode=%s, Port=%d, opts.BackendType=%s", 
 		runInstance.ID, runInstance.BackendType, runInstance.DeploymentMode, runInstance.Port, opts.BackendType)
-	logger.Debug("Instance metadata: backend_type=%s, deployment_mode=%s", 
+	log.Debug("Instance metadata: backend_type=%s, deployment_mode=%s", 
 		instance.Metadata["backend_type"], instance.Metadata["deployment_mode"])
 	
 	return runInstance, nil
 }
 
+// normalizeEngineLimits validates the engine tuning options in
+// opts.AdditionalConfig and stores them with the types runtimes expect.
+//
+// Values decoded from JSON arrive as float64, so max_model_len is converted
+// to int and gpu_memory_utilization to float64. A max_model_len larger than
+// the model's declared context length is allowed (some engines extrapolate)
+// but produces a warning, since it usually fails at engine startup.
+//
+// Parameters:
+//   - opts: Run options whose AdditionalConfig is updated in place
+//
+// Returns:
+//   - Error if a value is present but out of range
+func normalizeEngineLimits(opts *RunOptions) error {
+	if _, present := opts.AdditionalConfig["max_model_len"]; present {
+		maxLen, ok := ConfigInt(opts.AdditionalConfig, "max_model_len")
+		if !ok || maxLen <= 0 {
+			return fmt.Errorf("max_model_len must be a positive integer (got %v)", opts.AdditionalConfig["max_model_len"])
+		}
+		opts.AdditionalConfig["max_model_len"] = maxLen
+		
+		if spec := models.GetModelSpec(opts.ModelID); spec != nil && spec.ContextLength > 0 && maxLen > spec.ContextLength {
+			msg := fmt.Sprintf("Warning: max model length %d exceeds the context length of %s (%d); the engine may refuse to start",
+				maxLen, opts.ModelID, spec.ContextLength)
+			log.Warn("%s", msg)
+			if opts.EventChannel != nil {
+				select {
+				case opts.EventChannel <- msg:
+				default:
+				}
+			}
+		}
+	}
+	
+	if _, present := opts.AdditionalConfig["gpu_memory_utilization"]; present {
+		util, ok := ConfigFloat(opts.AdditionalConfig, "gpu_memory_utilization")
+		if !ok || util <= 0 || util > 1 {
+			return fmt.Errorf("gpu_memory_utilization must be greater than 0 and at most 1 (got %v)", opts.AdditionalConfig["gpu_memory_utilization"])
+		}
+		opts.AdditionalConfig["gpu_memory_utilization"] = util
+	}
+	
+	return nil
+}
+
 // ListCompat lists all instances in legacy API format.
 //
 // This method provides backward compatibility with the legacy API by
@@ -759,6 +1159,14 @@ func (m *Manager) ListCompat() []*RunInstance {
 	
 	result := make([]*RunInstance, 0, len(instances))
 	for _, inst := range instances {
+		restartCount, _ := strconv.Atoi(inst.Metadata["restart_count"])
+		// Live changes, such as a new concurrency limit, survive a restart
+		runSpec := parseRunSpec(inst.Metadata["run_spec"])
+		m.overrides.applyRunSpec(inst.ID, runSpec)
+		var endpoint string
+		if inst.Port > 0 {
+			endpoint = fmt.Sprintf("http://localhost:%d", inst.Port)
+		}
 		result = append(result, &RunInstance{
 			ID:             inst.ID,
 			ModelID:        inst.ModelID,
@@ -769,13 +1177,32 @@ func (m *Manager) ListCompat() []*RunInstance {
 			CreatedAt:      inst.CreatedAt,
 			StartedAt:      inst.StartedAt,
 			Port:           inst.Port,
+			Endpoint:       endpoint,
+			DeviceIndices:  parseDeviceIndices(inst.Metadata["device_indices"]),
 			ContainerID:    inst.Metadata["container_id"], // Docker container ID
 			Error:          inst.Error,
+			HealthError:    inst.Metadata["health_error"], // Last readiness probe failure
+			RestartCount:   restartCount,
+			LastLogs:       inst.Metadata["last_logs"],
+			LogDir:         inst.Metadata["log_dir"],
+			RunSpec:        runSpec,
 		})
 	}
 	return result
 }
 
+// parseDeviceIndices parses the comma-separated device_indices metadata
+// value. Invalid entries are skipped; an empty value yields nil.
+func parseDeviceIndices(s string) []int {
+	var indices []int
+	for _, part := range strings.Split(s, ",") {
+		if idx, err := strconv.Atoi(strings.TrimSpace(part)); err == nil {
+			indices = append(indices, idx)
+		}
+	}
+	return indices
+}
+
 // StopCompat stops an instance with legacy API compatibility.
 //
 // This method provides backward compatibility by wrapping the Stop
@@ -878,16 +1305,19 @@ func (m *Manager) StopByAliasCompat(alias string) error {
 //
 // This method provides a convenient way to remove instances using their
 // alias instead of the internal instance ID. If force is true, it stops
-// the instance before removing it.
+// the instance before removing it. A positive drainTimeout first drains
+// the instance: new requests are no longer routed to it and in-flight
+// requests are given up to drainTimeout to complete.
 //
 // Parameters:
 //   - alias: The alias of the instance to remove
 //   - force: If true, stops the instance before removing
+//   - drainTimeout: Maximum time to wait for in-flight requests (0 to skip draining)
 //
 // Returns:
 //   - Error if the instance is not found or remove fails
-func (m *Manager) RemoveByAliasCompat(alias string, force bool) error {
-	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+func (m *Manager) RemoveByAliasCompat(alias string, force bool, drainTimeout time.Duration) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second+drainTimeout)
 	defer cancel()
 	
 	// Find instance by alias
@@ -896,6 +1326,11 @@ func (m *Manager) RemoveByAliasCompat(alias string, force bool) error {
 		return err
 	}
 	
+	// Let in-flight requests complete before the container goes away
+	if drainTimeout > 0 {
+		m.Drain(ctx, inst.ID, drainTimeout)
+	}
+	
 	// If force is true, stop the instance first
 	if force {
 		// Ignore errors from stop - instance might already be stopped
@@ -975,7 +1410,7 @@ func extractWorldSizeFromTemplate(templateParams []string) int {
 		// Check for world_size variations
 		if key == "world_size" || key == "worldsize" {
 			if ws, err := strconv.Atoi(value); err == nil && ws > 0 {
-				logger.Debug("Extracted world_size=%d from template parameters", ws)
+				log.Debug("Extracted world_size=%d from template parameters", ws)
 				return ws
 			}
 		}
@@ -989,12 +1424,12 @@ func extractWorldSizeFromTemplate(templateParams []string) int {
 // Parameters:
 //   - ctx: Context for cancellation and timeout
 //   - alias: Instance alias
-//   - follow: If true, stream logs in real-time
+//   - opts: Log options (follow, tail)
 //
 // Returns:
 //   - LogStream reader
 //   - Error if instance not found
-func (m *Manager) GetLogsByAlias(ctx context.Context, alias string, follow bool) (LogStream, error) {
+func (m *Manager) GetLogsByAlias(ctx context.Context, alias string, opts LogOptions) (LogStream, error) {
 	m.mu.RLock()
 	defer m.mu.RUnlock()
 	
@@ -1015,5 +1450,18 @@ func (m *Manager) GetLogsByAlias(ctx context.Context, alias string, follow bool)
 	}
 	
 	// Get logs from runtime
-	return rt.Logs(ctx, instance.ID, follow)
+	return rt.Logs(ctx, instance.ID, opts)
+}
+
+// DeviceAllocations returns the allocation state of every detected device.
+//
+// Returns:
+//   - One allocation per device, in device index order
+//   - Error if the allocator cannot be created or Docker cannot be queried
+func (m *Manager) DeviceAllocations() ([]device.Allocation, error) {
+	allocator, err := m.getOrCreateAllocator(m.configDir)
+	if err != nil {
+		return nil, err
+	}
+	return allocator.ListAllocations()
 }
@@ -31,6 +31,9 @@ import (
 	"github.com/tsingmaoai/xw-cli/internal/runtime"
 )
 
+// log is the runtime component logger (XW_LOG=runtime=LEVEL).
+var log = logger.Named("runtime")
+
 // Runtime implements the runtime.Runtime interface for MindIE with Docker.
 //
 // This runtime manages MindIE model instances running in Docker containers.
@@ -88,10 +91,10 @@ func NewRuntime() (*Runtime, error) {
 	defer cancel()
 
 	if err := rt.LoadExistingContainers(ctx); err != nil {
-		logger.Warn("Failed to load existing MindIE containers: %v", err)
+		log.Warn("Failed to load existing MindIE containers: %v", err)
 	}
 
-	logger.Info("MindIE Docker runtime initialized successfully (config-driven mode)")
+	log.Info("MindIE Docker runtime initialized successfully (config-driven mode)")
 
 	return rt, nil
 }
@@ -161,7 +164,7 @@ func (r *Runtime) Create(ctx context.Context, params *runtime.CreateParams) (*ru
 		return nil, fmt.Errorf("invalid parameters: instance ID is required")
 	}
 
-	logger.Info("Creating MindIE Docker instance: %s for model: %s",
+	log.Info("Creating MindIE Docker instance: %s for model: %s",
 		params.InstanceID, params.ModelID)
 
 	// Check for duplicate instance ID
@@ -221,6 +224,17 @@ func (r *Runtime) Create(ctx context.Context, params *runtime.CreateParams) (*ru
 	}
 	env["MODEL_NAME"] = modelName
 
+	// Engine log files go to the instance's host log directory
+	runtime.InstanceLogEnv(params, env)
+
+	// MAX_MODEL_LEN: Maximum sequence length (optional, from ExtraConfig)
+	if maxLen, ok := runtime.ConfigInt(params.ExtraConfig, "max_model_len"); ok && maxLen > 0 {
+		env["MAX_MODEL_LEN"] = fmt.Sprintf("%d", maxLen)
+	}
+	if _, ok := params.ExtraConfig["gpu_memory_utilization"]; ok {
+		log.Warn("gpu_memory_utilization is not supported by MindIE and will be ignored")
+	}
+
 	// Configure SERVER_PORT environment variable for MindIE
 	// MindIE will listen on the port specified by SERVER_PORT (default: 8000)
 	env["SERVER_PORT"] = "8000"
@@ -265,18 +279,18 @@ func (r *Runtime) Create(ctx context.Context, params *runtime.CreateParams) (*ru
 	if img, ok := params.ExtraConfig["image"]; ok {
 		if imgStr, ok := img.(string); ok {
 			imageName = imgStr
-			logger.Info("Using custom Docker image: %s", imageName)
+			log.Info("Using custom Docker image: %s", imageName)
 		}
 	}
 	
 	if imageName == "" {
 		// Get image from configuration
 		var err error
-		imageName, err = sandbox.GetDefaultImage(params.Devices)
+		imageName, err = sandbox.GetDefaultImage(params.Devices, params.Arch)
 		if err != nil {
 			return nil, fmt.Errorf("failed to get Docker image: %w", err)
 		}
-		logger.Info("Using configured Docker image: %s", imageName)
+		log.Info("Using configured Docker image: %s", imageName)
 	}
 
 	// Ensure Docker image is available (check and pull if needed)
@@ -358,12 +372,12 @@ func (r *Runtime) Create(ctx context.Context, params *runtime.CreateParams) (*ru
 		})
 	}
 
+	// Instance log directory, kept on the host after the container is removed
+	mounts = append(mounts, runtime.InstanceLogMounts(params)...)
+
 	// Get shared memory size for distributed inference
 	// MindIE requires large shared memory for multi-device communication
-	var shmSize int64 = 16 * 1024 * 1024 * 1024 // Default 16GB
-	if shmProvider, ok := sandbox.(interface{ GetSharedMemorySize() int64 }); ok {
-		shmSize = shmProvider.GetSharedMemorySize()
-	}
+	shmSize := runtime.ResolveShmSize(params, sandbox, 16*1024*1024*1024) // Default 16GB
 
 	// Build host configuration with MindIE-specific settings
 	hostConfig := &container.HostConfig{
@@ -433,7 +447,7 @@ func (r *Runtime) Create(ctx context.Context, params *runtime.CreateParams) (*ru
 	instances[params.InstanceID] = instance
 	mu.Unlock()
 
-	logger.Info("MindIE Docker instance created successfully: %s (container: %s)",
+	log.Info("MindIE Docker instance created successfully: %s (container: %s)",
 		params.InstanceID, resp.ID[:12])
 
 	return instance, nil
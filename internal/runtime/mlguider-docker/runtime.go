@@ -46,6 +46,9 @@ import (
 	"github.com/tsingmaoai/xw-cli/internal/runtime"
 )
 
+// log is the runtime component logger (XW_LOG=runtime=LEVEL).
+var log = logger.Named("runtime")
+
 // Runtime implements the Runtime interface for MLGuider with Docker deployment.
 //
 // MLGuider Runtime Architecture:
@@ -112,11 +115,11 @@ func NewRuntime() (*Runtime, error) {
 
 	// Load existing containers to restore state after server restart
 	if err := rt.LoadExistingContainers(context.Background()); err != nil {
-		logger.Warn("Failed to load existing MLGuider containers: %v", err)
+		log.Warn("Failed to load existing MLGuider containers: %v", err)
 		// Non-fatal: we can still create new instances
 	}
 
-	logger.Info("MLGuider Docker runtime initialized successfully")
+	log.Info("MLGuider Docker runtime initialized successfully")
 	return rt, nil
 }
 
@@ -174,7 +177,7 @@ func (r *Runtime) Create(ctx context.Context, params *runtime.CreateParams) (*ru
 		return nil, fmt.Errorf("invalid parameters: instance ID is required")
 	}
 
-	logger.Info("Creating MLGuider Docker instance: %s for model: %s",
+	log.Info("Creating MLGuider Docker instance: %s for model: %s",
 		params.InstanceID, params.ModelID)
 
 	// Check for duplicate instance ID
@@ -242,7 +245,7 @@ func (r *Runtime) Create(ctx context.Context, params *runtime.CreateParams) (*ru
 	// MAX_MODEL_LEN: Maximum sequence length for inference
 	// Default: 8192, configurable via ExtraConfig
 	maxModelLen := 8192
-	if configLen, ok := params.ExtraConfig["max_model_len"].(int); ok && configLen > 0 {
+	if configLen, ok := runtime.ConfigInt(params.ExtraConfig, "max_model_len"); ok && configLen > 0 {
 		maxModelLen = configLen
 	}
 	env["MAX_MODEL_LEN"] = fmt.Sprintf("%d", maxModelLen)
@@ -278,6 +281,9 @@ func (r *Runtime) Create(ctx context.Context, params *runtime.CreateParams) (*ru
 	}
 	env["MODEL_NAME"] = modelName
 
+	// Engine log files go to the instance's host log directory
+	runtime.InstanceLogEnv(params, env)
+
 	// Convert environment map to Docker format (KEY=VALUE strings)
 	envList := make([]string, 0, len(env))
 	for k, v := range env {
@@ -311,13 +317,13 @@ func (r *Runtime) Create(ctx context.Context, params *runtime.CreateParams) (*ru
 	// Get default image from sandbox if not specified
 	if imageName == "" {
 		var err error
-		imageName, err = sandbox.GetDefaultImage(params.Devices)
+		imageName, err = sandbox.GetDefaultImage(params.Devices, params.Arch)
 		if err != nil {
 			return nil, fmt.Errorf("failed to get Docker image: %w", err)
 		}
 	}
 
-	logger.Info("Using MLGuider Docker image: %s", imageName)
+	log.Info("Using MLGuider Docker image: %s", imageName)
 
 	// Prepare device indices label for MLGuider
 	var deviceIndicesStr string
@@ -382,6 +388,9 @@ func (r *Runtime) Create(ctx context.Context, params *runtime.CreateParams) (*ru
 		})
 	}
 
+	// Instance log directory, kept on the host after the container is removed
+	mounts = append(mounts, runtime.InstanceLogMounts(params)...)
+
 	// Build container name with server suffix for multi-server support
 	containerName := params.InstanceID
 	if params.ServerName != "" {
@@ -401,10 +410,7 @@ func (r *Runtime) Create(ctx context.Context, params *runtime.CreateParams) (*ru
 
 	// Get shared memory size for inference workloads
 	// MLGuider requires adequate shared memory for DataLoader workers and model tensor sharing
-	var shmSize int64 = 16 * 1024 * 1024 * 1024 // Default 16GB
-	if shmProvider, ok := sandbox.(interface{ GetSharedMemorySize() int64 }); ok {
-		shmSize = shmProvider.GetSharedMemorySize()
-	}
+	shmSize := runtime.ResolveShmSize(params, sandbox, 16*1024*1024*1024) // Default 16GB
 
 	// Create host configuration with networking, devices, and security settings
 	hostConfig := &container.HostConfig{
@@ -443,13 +449,13 @@ func (r *Runtime) Create(ctx context.Context, params *runtime.CreateParams) (*ru
 	}
 	
 	// Create the Docker container via base method (automatically adds common labels)
-	logger.Debug("Creating MLGuider container: %s", containerName)
+	log.Debug("Creating MLGuider container: %s", containerName)
 	resp, err := r.CreateContainerWithLabels(ctx, params, containerConfig, hostConfig, containerName, extraLabels)
 	if err != nil {
 		return nil, err
 	}
 
-	logger.Info("Created MLGuider container: %s (ID: %s)", containerName, resp.ID[:12])
+	log.Info("Created MLGuider container: %s (ID: %s)", containerName, resp.ID[:12])
 
 	// Create instance metadata
 	instance := &runtime.Instance{
@@ -483,7 +489,7 @@ func (r *Runtime) Create(ctx context.Context, params *runtime.CreateParams) (*ru
 	instances[params.InstanceID] = instance
 	mu.Unlock()
 
-	logger.Info("MLGuider instance created successfully: %s", params.InstanceID)
+	log.Info("MLGuider instance created successfully: %s", params.InstanceID)
 	return instance, nil
 }
 
@@ -529,7 +535,7 @@ func ensureMLGuiderModelDir(dataDir, modelID string) (string, error) {
 			return "", fmt.Errorf("%s exists but is not a directory", convertedDir)
 		}
 		// Directory exists, nothing to do
-		logger.Debug("MLGuider model directory already exists: %s", convertedDir)
+		log.Debug("MLGuider model directory already exists: %s", convertedDir)
 		return convertedDir, nil
 	}
 	
@@ -539,7 +545,7 @@ func ensureMLGuiderModelDir(dataDir, modelID string) (string, error) {
 		return "", fmt.Errorf("failed to create directory %s: %w", convertedDir, err)
 	}
 	
-	logger.Info("Created MLGuider model directory: %s", convertedDir)
+	log.Info("Created MLGuider model directory: %s", convertedDir)
 	return convertedDir, nil
 }
 
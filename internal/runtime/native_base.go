@@ -0,0 +1,880 @@
+package runtime
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/docker/docker/pkg/stdcopy"
+)
+
+const (
+	// nativeRecordFile describes a native instance in its state directory.
+	nativeRecordFile = "instance.json"
+
+	// nativeOutputFile holds the combined stdout and stderr of the engine.
+	nativeOutputFile = "output.log"
+
+	// nativeStopTimeout is how long a stopping engine is given to exit
+	// after SIGTERM before it is killed, as for Docker containers.
+	nativeStopTimeout = 30 * time.Second
+
+	// nativeLogPollInterval is how often followed logs are checked for new output.
+	nativeLogPollInterval = 500 * time.Millisecond
+)
+
+// nativeInheritedEnv are the server environment variables passed on to
+// native engines, so that the engine and its Python environment are found.
+var nativeInheritedEnv = map[string]bool{
+	"PATH":            true,
+	"HOME":            true,
+	"USER":            true,
+	"LANG":            true,
+	"LC_ALL":          true,
+	"TMPDIR":          true,
+	"LD_LIBRARY_PATH": true,
+	"PYTHONPATH":      true,
+	"PYTHONHOME":      true,
+	"VIRTUAL_ENV":     true,
+	"CONDA_PREFIX":    true,
+}
+
+// nativeInheritedEnvPrefixes are the prefixes of vendor toolkit variables
+// (e.g., ASCEND_TOOLKIT_HOME, CUDA_HOME) passed on to native engines.
+var nativeInheritedEnvPrefixes = []string{"ASCEND_", "ATB_", "CUDA_", "ROCM_", "NEUWARE_"}
+
+// NativeCommandFunc builds the command line that launches the engine of a
+// native instance.
+//
+// Parameters:
+//   - params: Instance creation parameters (devices, port, model path, ...)
+//   - env: Process environment, including device exports; may be modified
+//
+// Returns:
+//   - Command line; the first element is the program to run
+//   - Error if the command cannot be built
+type NativeCommandFunc func(params *CreateParams, env map[string]string) ([]string, error)
+
+// nativeRecord is the persisted description of a native instance. It lets
+// the server find instances again after a restart, like container labels do
+// for Docker instances.
+type nativeRecord struct {
+	ID           string            `json:"id"`
+	ModelID      string            `json:"model_id"`
+	Alias        string            `json:"alias"`
+	ModelVersion string            `json:"model_version"`
+	Port         int               `json:"port"`
+	CreatedAt    time.Time         `json:"created_at"`
+	StartedAt    time.Time         `json:"started_at,omitempty"`
+	Command      []string          `json:"command"`
+	Env          map[string]string `json:"env"`
+	PID          int               `json:"pid,omitempty"`
+	Metadata     map[string]string `json:"metadata"`
+}
+
+// nativeProcess tracks a native instance and its engine process.
+type nativeProcess struct {
+	record  *nativeRecord
+	dir     string        // State directory (record and engine output)
+	state   InstanceState // Last known state
+	err     string        // Exit reason if the process exited unexpectedly
+	done    chan struct{} // Closed when the process exits; nil if not started by this server
+	stopped time.Time
+}
+
+// NativeRuntimeBase runs model instances as engine processes on the host,
+// for environments where Docker is not available or not allowed.
+//
+// It mirrors DockerRuntimeBase: engines get the same device exports (from
+// the device sandboxes in devices.yaml, e.g. ASCEND_RT_VISIBLE_DEVICES),
+// template parameters and instance metadata as containers do. Each instance
+// has a state directory holding its description and the engine's output.
+// Engines run in their own process group, so they survive a server restart
+// and are found again from their state directory, and stopping an instance
+// also stops the worker processes the engine spawned.
+//
+// Runtime implementations embed NativeRuntimeBase and supply the engine's
+// command line.
+type NativeRuntimeBase struct {
+	mu           sync.RWMutex
+	runtimeName  string                    // Runtime name (e.g., "vllm:native")
+	stateDir     string                    // One subdirectory per instance
+	command      NativeCommandFunc         // Builds the engine command line
+	processes    map[string]*nativeProcess // instanceID → process
+	sandboxOnce  sync.Once
+	extSandboxes []func() DeviceSandbox
+}
+
+// NewNativeRuntimeBase creates a native runtime base and loads the
+// instances of previous server runs from stateDir.
+//
+// Parameters:
+//   - runtimeName: Runtime name (e.g., "vllm:native")
+//   - stateDir: Directory for instance state, created if missing
+//   - command: Builds the engine command line
+//
+// Returns:
+//   - Runtime base
+//   - Error if the state directory cannot be created
+func NewNativeRuntimeBase(runtimeName, stateDir string, command NativeCommandFunc) (*NativeRuntimeBase, error) {
+	if err := os.MkdirAll(stateDir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create state directory: %w", err)
+	}
+
+	b := &NativeRuntimeBase{
+		runtimeName: runtimeName,
+		stateDir:    stateDir,
+		command:     command,
+		processes:   make(map[string]*nativeProcess),
+	}
+	b.loadExisting()
+	return b, nil
+}
+
+// Name returns the runtime name.
+func (b *NativeRuntimeBase) Name() string {
+	return b.runtimeName
+}
+
+// loadExisting loads the instances recorded in the state directory.
+// Instances whose process is still running are adopted; the others are
+// reported as exited.
+func (b *NativeRuntimeBase) loadExisting() {
+	entries, err := os.ReadDir(b.stateDir)
+	if err != nil {
+		log.Warn("Failed to read native instances from %s: %v", b.stateDir, err)
+		return
+	}
+
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		dir := filepath.Join(b.stateDir, entry.Name())
+		data, err := os.ReadFile(filepath.Join(dir, nativeRecordFile))
+		if err != nil {
+			continue
+		}
+		var record nativeRecord
+		if err := json.Unmarshal(data, &record); err != nil || record.ID == "" {
+			log.Warn("Ignoring invalid native instance record in %s: %v", dir, err)
+			continue
+		}
+
+		p := &nativeProcess{record: &record, dir: dir, state: StateCreated}
+		if record.PID > 0 {
+			if processAlive(record.PID, record.Command) {
+				p.state = StateRunning
+			} else {
+				p.state = StateError
+				p.err = "Process exited while the server was not running"
+			}
+		}
+		b.processes[record.ID] = p
+		log.Info("Loaded native instance %s (%s)", record.ID, p.state)
+	}
+}
+
+// selectSandbox returns the device sandbox for a chip config key, loading
+// the sandboxes configured in devices.yaml for the engine on first use.
+func (b *NativeRuntimeBase) selectSandbox(deviceType string) (DeviceSandbox, error) {
+	b.sandboxOnce.Do(func() {
+		engineName := b.runtimeName
+		if idx := strings.Index(engineName, ":"); idx > 0 {
+			engineName = engineName[:idx]
+		}
+		b.extSandboxes = LoadExtendedSandboxes(engineName)
+	})
+
+	for _, constructor := range b.extSandboxes {
+		sb := constructor()
+		if sb.Supports(deviceType) {
+			return sb, nil
+		}
+	}
+	return nil, fmt.Errorf("no sandbox found for device type: %s", deviceType)
+}
+
+// Create prepares a native instance without starting it.
+//
+// The engine environment is built like a container's: user variables, then
+// the device sandbox's exports (which take precedence), then template
+// parameters from runtime_params.yaml and the parallelism, engine limit,
+// model and log directory variables.
+//
+// If params.DryRun is set, nothing is created; a *DryRunResult error
+// carrying the equivalent shell command is returned.
+//
+// Parameters:
+//   - ctx: Context for cancellation
+//   - params: Instance creation parameters
+//
+// Returns:
+//   - Created instance
+//   - Error if the instance exists or cannot be prepared
+func (b *NativeRuntimeBase) Create(ctx context.Context, params *CreateParams) (*Instance, error) {
+	if params == nil || params.InstanceID == "" {
+		return nil, fmt.Errorf("invalid parameters: instance ID is required")
+	}
+
+	b.mu.RLock()
+	_, exists := b.processes[params.InstanceID]
+	b.mu.RUnlock()
+	if exists {
+		return nil, fmt.Errorf("instance %s already exists", params.InstanceID)
+	}
+
+	env := make(map[string]string)
+	for k, v := range params.Environment {
+		env[k] = v
+	}
+
+	// Device exports (e.g., ASCEND_RT_VISIBLE_DEVICES) from the sandbox
+	var deviceType string
+	if len(params.Devices) > 0 {
+		deviceType = params.Devices[0].ConfigKey
+		sandbox, err := b.selectSandbox(deviceType)
+		if err != nil {
+			return nil, err
+		}
+		sandboxEnv, err := sandbox.PrepareEnvironment(params.Devices)
+		if err != nil {
+			return nil, fmt.Errorf("failed to prepare environment: %w", err)
+		}
+		for k, v := range sandboxEnv {
+			env[k] = v
+		}
+	}
+
+	// Template parameters do not override variables already set
+	for k, v := range convertTemplateParamsToEnv(params.TemplateParams) {
+		if _, set := env[k]; !set {
+			env[k] = v
+		}
+	}
+
+	if params.TensorParallel > 0 {
+		env["TENSOR_PARALLEL"] = fmt.Sprintf("%d", params.TensorParallel)
+	}
+	if params.WorldSize > 0 {
+		env["WORLD_SIZE"] = fmt.Sprintf("%d", params.WorldSize)
+	}
+	if maxLen, ok := ConfigInt(params.ExtraConfig, "max_model_len"); ok && maxLen > 0 {
+		env["MAX_MODEL_LEN"] = fmt.Sprintf("%d", maxLen)
+	}
+	if util, ok := ConfigFloat(params.ExtraConfig, "gpu_memory_utilization"); ok && util > 0 {
+		env["GPU_MEMORY_UTILIZATION"] = fmt.Sprintf("%g", util)
+	}
+
+	modelName := params.Alias
+	if modelName == "" {
+		modelName = params.ModelID
+	}
+	env["MODEL_PATH"] = params.ModelPath
+	env["MODEL_NAME"] = modelName
+
+	// Engine log files go directly to the instance's host log directory
+	if params.LogDir != "" {
+		instanceLogEnvAt(params.LogDir, env)
+	}
+
+	command, err := b.command(params, env)
+	if err != nil {
+		return nil, err
+	}
+	if len(command) == 0 {
+		return nil, fmt.Errorf("empty engine command")
+	}
+
+	if params.DryRun {
+		return nil, &DryRunResult{Command: formatNativeCommand(env, command)}
+	}
+
+	metadata := nativeMetadata(params)
+	if deviceType != "" {
+		metadata["device_type"] = deviceType
+	}
+
+	record := &nativeRecord{
+		ID:           params.InstanceID,
+		ModelID:      params.ModelID,
+		Alias:        params.Alias,
+		ModelVersion: params.ModelVersion,
+		Port:         params.Port,
+		CreatedAt:    time.Now(),
+		Command:      command,
+		Env:          env,
+		Metadata:     metadata,
+	}
+	p := &nativeProcess{
+		record: record,
+		dir:    filepath.Join(b.stateDir, params.InstanceID),
+		state:  StateCreated,
+	}
+	if err := os.MkdirAll(p.dir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create instance directory: %w", err)
+	}
+	if err := p.save(); err != nil {
+		os.RemoveAll(p.dir)
+		return nil, err
+	}
+
+	b.mu.Lock()
+	b.processes[params.InstanceID] = p
+	b.mu.Unlock()
+
+	log.Info("Native instance created: %s (%s)", params.InstanceID, strings.Join(command, " "))
+
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	return p.instance(b.runtimeName), nil
+}
+
+// Start launches the engine process of an instance.
+//
+// The process runs in its own process group with its output appended to
+// the instance's output file, which Logs reads. It gets the instance's
+// environment on top of the few server variables the engine needs to run
+// (see nativeBaseEnv), not the server's whole environment.
+//
+// Parameters:
+//   - ctx: Context for cancellation (not bound to the process lifetime)
+//   - instanceID: Instance to start
+//
+// Returns:
+//   - Error if the instance is unknown, already running, or cannot be launched
+func (b *NativeRuntimeBase) Start(ctx context.Context, instanceID string) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	p, exists := b.processes[instanceID]
+	if !exists {
+		return fmt.Errorf("instance not found: %s", instanceID)
+	}
+	if p.alive() {
+		return fmt.Errorf("instance %s is already running", instanceID)
+	}
+
+	output, err := os.OpenFile(filepath.Join(p.dir, nativeOutputFile), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to open engine output file: %w", err)
+	}
+
+	record := p.record
+	cmd := exec.Command(record.Command[0], record.Command[1:]...)
+	cmd.Env = append(nativeBaseEnv(os.Environ()), sortedEnv(record.Env)...)
+	cmd.Dir = p.dir
+	cmd.Stdout = output
+	cmd.Stderr = output
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+
+	if err := cmd.Start(); err != nil {
+		output.Close()
+		return fmt.Errorf("failed to start %s: %w", record.Command[0], err)
+	}
+
+	record.PID = cmd.Process.Pid
+	record.StartedAt = time.Now()
+	p.state = StateRunning
+	p.err = ""
+	p.done = make(chan struct{})
+	if err := p.save(); err != nil {
+		log.Warn("Failed to record PID of instance %s: %v", instanceID, err)
+	}
+
+	go b.wait(p, cmd, output, p.done)
+
+	log.Info("Native instance started: %s (PID %d)", instanceID, record.PID)
+	return nil
+}
+
+// wait reaps an engine process and records how it exited.
+func (b *NativeRuntimeBase) wait(p *nativeProcess, cmd *exec.Cmd, output *os.File, done chan struct{}) {
+	err := cmd.Wait()
+	output.Close()
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if p.state == StateRunning {
+		p.state = StateError
+		p.err = fmt.Sprintf("Process exited unexpectedly with code %d", cmd.ProcessState.ExitCode())
+		if err != nil && cmd.ProcessState.ExitCode() < 0 {
+			p.err = fmt.Sprintf("Process exited unexpectedly: %v", err)
+		}
+		log.Warn("Native instance %s: %s", p.record.ID, p.err)
+	}
+	close(done)
+}
+
+// Stop stops the engine process of an instance.
+//
+// The process group is sent SIGTERM and given nativeStopTimeout to exit
+// before it is killed. The instance is kept and can be started again.
+//
+// Parameters:
+//   - ctx: Context for cancellation
+//   - instanceID: Instance to stop
+//
+// Returns:
+//   - Error if the instance is unknown
+func (b *NativeRuntimeBase) Stop(ctx context.Context, instanceID string) error {
+	b.mu.Lock()
+	p, exists := b.processes[instanceID]
+	if !exists {
+		b.mu.Unlock()
+		return fmt.Errorf("instance not found: %s", instanceID)
+	}
+	alive := p.alive()
+	p.state = StateStopping
+	pid, command, done := p.record.PID, p.record.Command, p.done
+	b.mu.Unlock()
+
+	if alive {
+		log.Info("Stopping native instance %s (PID %d)", instanceID, pid)
+		terminateProcessGroup(ctx, pid, command, done)
+	}
+
+	b.mu.Lock()
+	p.state = StateStopped
+	p.stopped = time.Now()
+	b.mu.Unlock()
+
+	log.Info("Native instance stopped: %s", instanceID)
+	return nil
+}
+
+// Remove stops an instance if it is running and deletes its state
+// directory, including the engine output.
+//
+// Parameters:
+//   - ctx: Context for cancellation
+//   - instanceID: Instance to remove
+//
+// Returns:
+//   - Error if the instance is unknown or its directory cannot be removed
+func (b *NativeRuntimeBase) Remove(ctx context.Context, instanceID string) error {
+	if err := b.Stop(ctx, instanceID); err != nil {
+		return err
+	}
+
+	b.mu.Lock()
+	p := b.processes[instanceID]
+	delete(b.processes, instanceID)
+	b.mu.Unlock()
+
+	if err := os.RemoveAll(p.dir); err != nil {
+		return fmt.Errorf("failed to remove instance directory: %w", err)
+	}
+
+	log.Info("Native instance removed: %s", instanceID)
+	return nil
+}
+
+// Get returns an instance with its current state.
+//
+// Parameters:
+//   - ctx: Context for cancellation
+//   - instanceID: Instance to look up
+//
+// Returns:
+//   - Instance
+//   - Error if the instance is unknown
+func (b *NativeRuntimeBase) Get(ctx context.Context, instanceID string) (*Instance, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	p, exists := b.processes[instanceID]
+	if !exists {
+		return nil, fmt.Errorf("instance not found: %s", instanceID)
+	}
+	p.refresh()
+	return p.instance(b.runtimeName), nil
+}
+
+// List returns all instances of this runtime with their current state.
+//
+// Parameters:
+//   - ctx: Context for cancellation
+//
+// Returns:
+//   - Instances
+//   - Error (always nil)
+func (b *NativeRuntimeBase) List(ctx context.Context) ([]*Instance, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	instances := make([]*Instance, 0, len(b.processes))
+	for _, p := range b.processes {
+		p.refresh()
+		instances = append(instances, p.instance(b.runtimeName))
+	}
+	return instances, nil
+}
+
+// Logs returns the engine output of an instance.
+//
+// The output is framed in Docker's multiplexed log format (as stdout), so
+// consumers handle native and container logs the same way with stdcopy.
+// With opts.Follow, new output is streamed until the context is cancelled
+// or the process exits.
+//
+// Parameters:
+//   - ctx: Context for cancellation
+//   - instanceID: Instance whose output to read
+//   - opts: Tail and follow options
+//
+// Returns:
+//   - Log stream; the caller must close it
+//   - Error if the instance is unknown or its output cannot be read
+func (b *NativeRuntimeBase) Logs(ctx context.Context, instanceID string, opts LogOptions) (LogStream, error) {
+	b.mu.RLock()
+	p, exists := b.processes[instanceID]
+	b.mu.RUnlock()
+	if !exists {
+		return nil, fmt.Errorf("instance not found: %s", instanceID)
+	}
+
+	f, err := os.OpenFile(filepath.Join(p.dir, nativeOutputFile), os.O_CREATE|os.O_RDONLY, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open engine output: %w", err)
+	}
+	if opts.Tail > 0 {
+		offset, err := tailOffset(f, opts.Tail)
+		if err != nil {
+			f.Close()
+			return nil, fmt.Errorf("failed to read engine output: %w", err)
+		}
+		if _, err := f.Seek(offset, io.SeekStart); err != nil {
+			f.Close()
+			return nil, fmt.Errorf("failed to read engine output: %w", err)
+		}
+	}
+
+	pr, pw := io.Pipe()
+	go func() {
+		defer f.Close()
+		w := stdcopy.NewStdWriter(pw, stdcopy.Stdout)
+		for {
+			if _, err := io.Copy(w, f); err != nil {
+				pw.CloseWithError(err)
+				return
+			}
+			if !opts.Follow {
+				break
+			}
+
+			b.mu.Lock()
+			p.refresh()
+			running := p.alive()
+			b.mu.Unlock()
+			if !running {
+				// Drain output written just before the exit
+				if _, err := io.Copy(w, f); err != nil {
+					pw.CloseWithError(err)
+					return
+				}
+				break
+			}
+
+			select {
+			case <-ctx.Done():
+				pw.Close()
+				return
+			case <-time.After(nativeLogPollInterval):
+			}
+		}
+		pw.Close()
+	}()
+
+	return pr, nil
+}
+
+// DeviceOwners maps the devices of running instances to their instance IDs,
+// for the device allocator (see device.Allocator.AddDeviceOwnerSource).
+//
+// Returns:
+//   - Map from device index to instance ID
+func (b *NativeRuntimeBase) DeviceOwners() map[int]string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	owners := make(map[int]string)
+	for id, p := range b.processes {
+		p.refresh()
+		if !p.alive() {
+			continue
+		}
+		for _, idx := range parseDeviceIndices(p.record.Metadata["device_indices"]) {
+			owners[idx] = id
+		}
+	}
+	return owners
+}
+
+// alive reports whether the engine process is running.
+// The caller must hold the runtime lock.
+func (p *nativeProcess) alive() bool {
+	if p.record.PID <= 0 {
+		return false
+	}
+	if p.done != nil {
+		select {
+		case <-p.done:
+			return false
+		default:
+			return true
+		}
+	}
+	return processAlive(p.record.PID, p.record.Command)
+}
+
+// refresh updates the state of an instance whose process was adopted from
+// a previous server run and has since exited. Processes started by this
+// server are tracked by wait. The caller must hold the runtime lock.
+func (p *nativeProcess) refresh() {
+	if p.done == nil && p.state == StateRunning && !p.alive() {
+		p.state = StateError
+		p.err = "Process exited unexpectedly"
+	}
+}
+
+// instance returns the instance view of a native process.
+// The caller must hold the runtime lock.
+func (p *nativeProcess) instance(runtimeName string) *Instance {
+	metadata := make(map[string]string, len(p.record.Metadata)+1)
+	for k, v := range p.record.Metadata {
+		metadata[k] = v
+	}
+	if p.record.PID > 0 {
+		metadata["pid"] = fmt.Sprintf("%d", p.record.PID)
+	}
+
+	var endpoint string
+	if p.record.Port > 0 {
+		endpoint = fmt.Sprintf("http://localhost:%d", p.record.Port)
+	}
+	return &Instance{
+		ID:           p.record.ID,
+		RuntimeName:  runtimeName,
+		CreatedAt:    p.record.CreatedAt,
+		ModelID:      p.record.ModelID,
+		Alias:        p.record.Alias,
+		ModelVersion: p.record.ModelVersion,
+		State:        p.state,
+		StartedAt:    p.record.StartedAt,
+		StoppedAt:    p.stopped,
+		Error:        p.err,
+		Port:         p.record.Port,
+		Endpoint:     endpoint,
+		Metadata:     metadata,
+	}
+}
+
+// save writes the instance record. It may hold secrets passed with --env,
+// so it is only readable by the server user.
+func (p *nativeProcess) save() error {
+	data, err := json.MarshalIndent(p.record, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode instance record: %w", err)
+	}
+	if err := os.WriteFile(filepath.Join(p.dir, nativeRecordFile), data, 0600); err != nil {
+		return fmt.Errorf("failed to write instance record: %w", err)
+	}
+	return nil
+}
+
+// nativeMetadata returns the metadata a Docker instance would carry in its
+// container labels (see CreateContainerWithLabels).
+func nativeMetadata(params *CreateParams) map[string]string {
+	metadata := map[string]string{
+		"backend_type":    params.BackendType,
+		"deployment_mode": params.DeploymentMode,
+		"server_name":     params.ServerName,
+	}
+
+	indices := make([]string, len(params.Devices))
+	for i, dev := range params.Devices {
+		indices[i] = fmt.Sprintf("%d", dev.Index)
+	}
+	if len(indices) > 0 {
+		metadata["device_indices"] = strings.Join(indices, ",")
+	}
+
+	if maxConcurrent, ok := ConfigInt(params.ExtraConfig, "max_concurrent"); ok && maxConcurrent > 0 {
+		metadata["max_concurrent"] = fmt.Sprintf("%d", maxConcurrent)
+	}
+	if maxRetries, ok := ConfigInt(params.ExtraConfig, "max_retries"); ok && maxRetries >= 0 {
+		metadata["max_retries"] = fmt.Sprintf("%d", maxRetries)
+	}
+	if restartMax, ok := ConfigInt(params.ExtraConfig, "restart_max"); ok && restartMax > 0 {
+		metadata["restart_max"] = fmt.Sprintf("%d", restartMax)
+	}
+	if params.LogDir != "" {
+		metadata["log_dir"] = params.LogDir
+	}
+	if params.RunSpec != "" {
+		metadata["run_spec"] = params.RunSpec
+	}
+	return metadata
+}
+
+// processAlive reports whether pid is a running process of the given
+// command. The command check guards against PID reuse after the engine
+// exited while the server was not running.
+func processAlive(pid int, command []string) bool {
+	err := syscall.Kill(pid, 0)
+	if err != nil && !errors.Is(err, syscall.EPERM) {
+		return false
+	}
+
+	cmdline, err := os.ReadFile(fmt.Sprintf("/proc/%d/cmdline", pid))
+	if err != nil || len(command) == 0 {
+		// No procfs (or the process just exited); trust the signal check
+		return err == nil || !os.IsNotExist(err) || len(command) == 0
+	}
+	return bytes.Contains(cmdline, []byte(filepath.Base(command[0])))
+}
+
+// terminateProcessGroup sends SIGTERM to a process group and SIGKILL if it
+// has not exited after nativeStopTimeout.
+//
+// Parameters:
+//   - ctx: Context for cancellation; cancelling kills the group immediately
+//   - pid: Process group leader
+//   - command: Command of the process (for the liveness check)
+//   - done: Closed when the process exits, or nil if it is not a child
+func terminateProcessGroup(ctx context.Context, pid int, command []string, done chan struct{}) {
+	exited := func() bool {
+		if done != nil {
+			select {
+			case <-done:
+				return true
+			default:
+				return false
+			}
+		}
+		return !processAlive(pid, command)
+	}
+
+	_ = syscall.Kill(-pid, syscall.SIGTERM)
+
+	deadline := time.NewTimer(nativeStopTimeout)
+	defer deadline.Stop()
+	ticker := time.NewTicker(200 * time.Millisecond)
+	defer ticker.Stop()
+
+	for !exited() {
+		select {
+		case <-ctx.Done():
+			_ = syscall.Kill(-pid, syscall.SIGKILL)
+			return
+		case <-deadline.C:
+			log.Warn("Process %d did not exit within %s, killing it", pid, nativeStopTimeout)
+			_ = syscall.Kill(-pid, syscall.SIGKILL)
+			return
+		case <-ticker.C:
+		}
+	}
+
+	// Worker processes the engine spawned may outlive it
+	_ = syscall.Kill(-pid, syscall.SIGKILL)
+}
+
+// tailOffset returns the offset of the last n lines of a file.
+func tailOffset(f *os.File, n int) (int64, error) {
+	info, err := f.Stat()
+	if err != nil {
+		return 0, err
+	}
+	size := info.Size()
+
+	const chunkSize = 64 * 1024
+	buf := make([]byte, chunkSize)
+	lines := 0
+	offset := size
+	for offset > 0 {
+		readSize := int64(chunkSize)
+		if offset < readSize {
+			readSize = offset
+		}
+		offset -= readSize
+		if _, err := f.ReadAt(buf[:readSize], offset); err != nil && err != io.EOF {
+			return 0, err
+		}
+		for i := readSize - 1; i >= 0; i-- {
+			if buf[i] != '\n' {
+				continue
+			}
+			// A newline ending the file does not start a line
+			if offset+i == size-1 {
+				continue
+			}
+			lines++
+			if lines == n {
+				return offset + i + 1, nil
+			}
+		}
+	}
+	return 0, nil
+}
+
+// nativeBaseEnv returns the variables of environ that native engines
+// inherit (see nativeInheritedEnv). Device visibility variables, such as
+// ASCEND_RT_VISIBLE_DEVICES, are never inherited; the device sandbox sets
+// them for the instance's devices.
+func nativeBaseEnv(environ []string) []string {
+	var env []string
+	for _, kv := range environ {
+		name, _, ok := strings.Cut(kv, "=")
+		if !ok || strings.HasSuffix(name, "VISIBLE_DEVICES") {
+			continue
+		}
+		inherit := nativeInheritedEnv[name]
+		for _, prefix := range nativeInheritedEnvPrefixes {
+			if strings.HasPrefix(name, prefix) {
+				inherit = true
+			}
+		}
+		if inherit {
+			env = append(env, kv)
+		}
+	}
+	return env
+}
+
+// sortedEnv converts an environment map to KEY=VALUE strings in a stable order.
+func sortedEnv(env map[string]string) []string {
+	list := make([]string, 0, len(env))
+	for k, v := range env {
+		list = append(list, k+"="+v)
+	}
+	sort.Strings(list)
+	return list
+}
+
+// formatNativeCommand renders a native engine launch as a shell command,
+// one variable per line, for dry runs.
+func formatNativeCommand(env map[string]string, command []string) string {
+	args := make([]string, 0, len(env)+1)
+	for _, e := range sortedEnv(env) {
+		args = append(args, shellQuote(e))
+	}
+	quoted := make([]string, len(command))
+	for i, c := range command {
+		quoted[i] = shellQuote(c)
+	}
+	args = append(args, strings.Join(quoted, " "))
+	return "env " + strings.Join(args, " \\\n  ")
+}
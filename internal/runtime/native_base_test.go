@@ -0,0 +1,239 @@
+package runtime
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+// shellCommand returns a NativeCommandFunc running a shell script.
+func shellCommand(script string) NativeCommandFunc {
+	return func(params *CreateParams, env map[string]string) ([]string, error) {
+		return []string{"/bin/sh", "-c", script}, nil
+	}
+}
+
+// waitFor polls cond until it holds or five seconds pass.
+func waitFor(t *testing.T, what string, cond func() bool) {
+	t.Helper()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for %s", what)
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+}
+
+// readPID waits for a script to write a PID to a file and returns it.
+func readPID(t *testing.T, path string) int {
+	t.Helper()
+
+	var pid int
+	waitFor(t, path, func() bool {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return false
+		}
+		pid, err = strconv.Atoi(strings.TrimSpace(string(data)))
+		return err == nil
+	})
+	return pid
+}
+
+func TestNativeRuntimeStateDirectory(t *testing.T) {
+	stateDir := t.TempDir()
+	b, err := NewNativeRuntimeBase("vllm:native", stateDir, shellCommand("echo $$ > engine.pid; sleep 60"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx := context.Background()
+	if _, err := b.Create(ctx, &CreateParams{
+		InstanceID:  "qwen3-8b-1",
+		ModelID:     "qwen3-8b",
+		Alias:       "qwen3-8b",
+		Port:        18000,
+		Environment: map[string]string{"API_TOKEN": "secret"},
+	}); err != nil {
+		t.Fatalf("Create() failed: %v", err)
+	}
+
+	// The record holds --env secrets and is only readable by the server user
+	dir := filepath.Join(stateDir, "qwen3-8b-1")
+	info, err := os.Stat(filepath.Join(dir, nativeRecordFile))
+	if err != nil {
+		t.Fatalf("instance record not written: %v", err)
+	}
+	if perm := info.Mode().Perm(); perm != 0600 {
+		t.Errorf("instance record mode = %o, want 600", perm)
+	}
+
+	if err := b.Start(ctx, "qwen3-8b-1"); err != nil {
+		t.Fatalf("Start() failed: %v", err)
+	}
+	defer b.Remove(ctx, "qwen3-8b-1")
+	readPID(t, filepath.Join(dir, "engine.pid"))
+
+	var record nativeRecord
+	data, _ := os.ReadFile(filepath.Join(dir, nativeRecordFile))
+	if err := json.Unmarshal(data, &record); err != nil {
+		t.Fatal(err)
+	}
+	if record.PID == 0 || record.Port != 18000 || record.Env["API_TOKEN"] != "secret" {
+		t.Errorf("record = %+v, want the PID, port and environment", record)
+	}
+
+	// A restarted server adopts the running engine from the state directory
+	adopted, err := NewNativeRuntimeBase("vllm:native", stateDir, shellCommand(""))
+	if err != nil {
+		t.Fatal(err)
+	}
+	inst, err := adopted.Get(ctx, "qwen3-8b-1")
+	if err != nil {
+		t.Fatalf("instance not loaded from the state directory: %v", err)
+	}
+	if inst.State != StateRunning || inst.Port != 18000 || inst.Alias != "qwen3-8b" {
+		t.Errorf("adopted instance = %+v, want running on port 18000", inst)
+	}
+
+	if err := b.Remove(ctx, "qwen3-8b-1"); err != nil {
+		t.Fatalf("Remove() failed: %v", err)
+	}
+	if _, err := os.Stat(dir); !os.IsNotExist(err) {
+		t.Errorf("state directory %s still exists after Remove()", dir)
+	}
+}
+
+func TestNativeRuntimeStopsProcessGroup(t *testing.T) {
+	stateDir := t.TempDir()
+	// The worker ignores SIGTERM, so only the group kill after the engine
+	// exits stops it
+	b, err := NewNativeRuntimeBase("vllm:native", stateDir,
+		shellCommand(`(trap "" TERM; exec sleep 60) & echo $! > worker.pid; wait`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx := context.Background()
+	if _, err := b.Create(ctx, &CreateParams{InstanceID: "engine-1", ModelID: "qwen3-8b"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := b.Start(ctx, "engine-1"); err != nil {
+		t.Fatalf("Start() failed: %v", err)
+	}
+	defer b.Remove(ctx, "engine-1")
+
+	worker := readPID(t, filepath.Join(stateDir, "engine-1", "worker.pid"))
+	if !processAlive(worker, []string{"sleep"}) {
+		t.Fatal("worker is not running")
+	}
+
+	if err := b.Stop(ctx, "engine-1"); err != nil {
+		t.Fatalf("Stop() failed: %v", err)
+	}
+	waitFor(t, "the worker to exit", func() bool {
+		return !processAlive(worker, []string{"sleep"})
+	})
+
+	inst, err := b.Get(ctx, "engine-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if inst.State != StateStopped {
+		t.Errorf("state after Stop() = %s, want %s", inst.State, StateStopped)
+	}
+}
+
+func TestNativeRuntimeEngineEnvironment(t *testing.T) {
+	t.Setenv("XW_TEST_SERVER_SECRET", "leaked")
+	t.Setenv("ASCEND_TOOLKIT_HOME", "/usr/local/Ascend/ascend-toolkit/latest")
+	t.Setenv("ASCEND_RT_VISIBLE_DEVICES", "0,1,2,3")
+
+	stateDir := t.TempDir()
+	b, err := NewNativeRuntimeBase("vllm:native", stateDir, shellCommand("env > engine.env"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx := context.Background()
+	if _, err := b.Create(ctx, &CreateParams{
+		InstanceID:  "engine-1",
+		ModelID:     "qwen3-8b",
+		Environment: map[string]string{"VLLM_LOGGING_LEVEL": "DEBUG"},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if err := b.Start(ctx, "engine-1"); err != nil {
+		t.Fatalf("Start() failed: %v", err)
+	}
+	defer b.Remove(ctx, "engine-1")
+
+	var env string
+	waitFor(t, "the engine environment", func() bool {
+		data, err := os.ReadFile(filepath.Join(stateDir, "engine-1", "engine.env"))
+		env = string(data)
+		return err == nil && strings.Contains(env, "MODEL_NAME=")
+	})
+
+	for _, want := range []string{
+		"PATH=",
+		"ASCEND_TOOLKIT_HOME=/usr/local/Ascend/ascend-toolkit/latest",
+		"VLLM_LOGGING_LEVEL=DEBUG",
+		"MODEL_NAME=qwen3-8b",
+	} {
+		if !strings.Contains(env, want) {
+			t.Errorf("engine environment lacks %s", want)
+		}
+	}
+	for _, unwanted := range []string{"XW_TEST_SERVER_SECRET", "ASCEND_RT_VISIBLE_DEVICES"} {
+		if strings.Contains(env, unwanted) {
+			t.Errorf("engine environment inherits %s from the server", unwanted)
+		}
+	}
+}
+
+func TestNativeRuntimeDeviceOwners(t *testing.T) {
+	b, err := NewNativeRuntimeBase("vllm:native", t.TempDir(), shellCommand(""))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	running := make(chan struct{})
+	exited := make(chan struct{})
+	close(exited)
+	b.processes = map[string]*nativeProcess{
+		"running": {
+			record: &nativeRecord{ID: "running", PID: os.Getpid(), Metadata: nativeMetadata(&CreateParams{
+				Devices: []DeviceInfo{{Index: 0}, {Index: 1}},
+			})},
+			state: StateRunning,
+			done:  running,
+		},
+		"exited": {
+			record: &nativeRecord{ID: "exited", PID: os.Getpid(), Metadata: nativeMetadata(&CreateParams{
+				Devices: []DeviceInfo{{Index: 2}},
+			})},
+			state: StateError,
+			done:  exited,
+		},
+		"created": {
+			record: &nativeRecord{ID: "created", Metadata: nativeMetadata(&CreateParams{
+				Devices: []DeviceInfo{{Index: 3}},
+			})},
+			state: StateCreated,
+		},
+	}
+
+	// Only the devices of running engines are owned
+	owners := b.DeviceOwners()
+	if len(owners) != 2 || owners[0] != "running" || owners[1] != "running" {
+		t.Errorf("DeviceOwners() = %v, want devices 0 and 1 owned by running", owners)
+	}
+}
@@ -53,7 +53,8 @@
 //     - ASCEND_RT_VISIBLE_DEVICES: Set automatically based on allocated devices
 //
 //  3. Large Shared Memory:
-//     Default 500GB shared memory (--shm-size=500g)
+//     Default 500GB shared memory (--shm-size=500g), capped to half of
+//     host memory on smaller hosts; xw start --shm-size overrides it
 //     Required for large model inference workloads
 //
 //  4. Minimal Volume Mounts:
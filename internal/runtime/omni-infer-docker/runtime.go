@@ -30,6 +30,9 @@ import (
 	"github.com/tsingmaoai/xw-cli/internal/runtime"
 )
 
+// log is the runtime component logger (XW_LOG=runtime=LEVEL).
+var log = logger.Named("runtime")
+
 // Runtime implements the runtime.Runtime interface for Omni-Infer with Docker.
 //
 // This runtime manages Omni-Infer model instances running in Docker containers.
@@ -76,10 +79,10 @@ func NewRuntime() (*Runtime, error) {
 	defer cancel()
 
 	if err := rt.LoadExistingContainers(ctx); err != nil {
-		logger.Warn("Failed to load existing Omni-Infer containers: %v", err)
+		log.Warn("Failed to load existing Omni-Infer containers: %v", err)
 	}
 
-	logger.Info("Omni-Infer Docker runtime initialized successfully (config-driven mode)")
+	log.Info("Omni-Infer Docker runtime initialized successfully (config-driven mode)")
 
 	return rt, nil
 }
@@ -119,7 +122,7 @@ func (r *Runtime) Create(ctx context.Context, params *runtime.CreateParams) (*ru
 		return nil, fmt.Errorf("invalid parameters: instance ID is required")
 	}
 
-	logger.Info("Creating Omni-Infer Docker instance: %s for model: %s",
+	log.Info("Creating Omni-Infer Docker instance: %s for model: %s",
 		params.InstanceID, params.ModelID)
 
 	// Check for duplicate instance ID
@@ -176,6 +179,9 @@ func (r *Runtime) Create(ctx context.Context, params *runtime.CreateParams) (*ru
 	}
 	env["MODEL_NAME"] = modelName
 
+	// Engine log files go to the instance's host log directory
+	runtime.InstanceLogEnv(params, env)
+
 	// TENSOR_PARALLEL_SIZE: Number of devices for tensor parallelism
 	if params.TensorParallel > 0 {
 		env["TENSOR_PARALLEL_SIZE"] = fmt.Sprintf("%d", params.TensorParallel)
@@ -184,10 +190,15 @@ func (r *Runtime) Create(ctx context.Context, params *runtime.CreateParams) (*ru
 	}
 
 	// MAX_MODEL_LEN: Maximum sequence length (from ExtraConfig or default)
-	if maxLen, ok := params.ExtraConfig["max_model_len"].(int); ok && maxLen > 0 {
+	if maxLen, ok := runtime.ConfigInt(params.ExtraConfig, "max_model_len"); ok && maxLen > 0 {
 		env["MAX_MODEL_LEN"] = fmt.Sprintf("%d", maxLen)
 	}
 
+	// GPU_MEMORY_UTILIZATION: Fraction of device memory for the engine (optional)
+	if util, ok := runtime.ConfigFloat(params.ExtraConfig, "gpu_memory_utilization"); ok && util > 0 {
+		env["GPU_MEMORY_UTILIZATION"] = fmt.Sprintf("%g", util)
+	}
+
 	// SERVER_PORT: Fixed to 8000 inside container (will be mapped to host port)
 	// Omni-Infer will listen on port 8000 inside container
 	env["SERVER_PORT"] = "8000"
@@ -259,17 +270,17 @@ func (r *Runtime) Create(ctx context.Context, params *runtime.CreateParams) (*ru
 		})
 	}
 
+	// Instance log directory, kept on the host after the container is removed
+	mounts = append(mounts, runtime.InstanceLogMounts(params)...)
+
 	// Get Docker image
-	imageName, err := sandbox.GetDefaultImage(params.Devices)
+	imageName, err := sandbox.GetDefaultImage(params.Devices, params.Arch)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get default image: %w", err)
 	}
 
-	// Get shared memory size (default: 500GB for Omni-Infer)
-	shmSize := int64(500 * 1024 * 1024 * 1024) // 500GB
-	if shmSizer, ok := sandbox.(interface{ GetSharedMemorySize() int64 }); ok {
-		shmSize = shmSizer.GetSharedMemorySize()
-	}
+	// Get shared memory size (default: 500GB for Omni-Infer, capped to host memory)
+	shmSize := runtime.ResolveShmSize(params, sandbox, 500*1024*1024*1024)
 
 	// Prepare container configuration
 	containerConfig := &container.Config{
@@ -356,7 +367,7 @@ func (r *Runtime) Create(ctx context.Context, params *runtime.CreateParams) (*ru
 	instances[params.InstanceID] = instance
 	mu.Unlock()
 
-	logger.Info("Omni-Infer Docker instance created successfully: %s (container: %s)",
+	log.Info("Omni-Infer Docker instance created successfully: %s (container: %s)",
 		params.InstanceID, resp.ID[:12])
 
 	return instance, nil
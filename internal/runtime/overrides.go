@@ -0,0 +1,248 @@
+package runtime
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+
+	"github.com/tsingmaoai/xw-cli/internal/config"
+)
+
+// instanceOverridesFile stores settings changed on live instances,
+// relative to the data directory.
+const instanceOverridesFile = "instance_overrides.json"
+
+// instanceOverride holds the settings of an instance that were changed
+// after it was created. Unset fields keep the value from creation.
+type instanceOverride struct {
+	Alias         string `json:"alias,omitempty"`
+	MaxConcurrent *int   `json:"max_concurrent,omitempty"`
+
+	// StartError is set when the instance's container failed to start and
+	// was kept for inspection (`xw start --keep-failed`) instead of removed
+	StartError string `json:"start_error,omitempty"`
+}
+
+// instanceOverrides records settings changed on live instances.
+//
+// Settings of a Docker instance, such as its alias and concurrency limit,
+// are stored in container labels (xw.alias, xw.max_concurrent), and Docker
+// does not allow labels of an existing container to be changed. Changed
+// settings are therefore kept here, keyed by instance ID, and override the
+// labels whenever instances are listed. Overrides are persisted so a server
+// restart does not revert them.
+//
+// The same applies to the failure of a container kept after a failed start:
+// it is recorded here, as Docker cannot add a label to the container once
+// the start failed, so that after a server restart the instance is still
+// reported as failed and not restarted as an ordinary crash.
+type instanceOverrides struct {
+	mu        sync.Mutex
+	path      string                       // Persistence file; empty disables persistence
+	instances map[string]*instanceOverride // instanceID → override
+}
+
+// instanceOverridesPath returns the persistence file for instance
+// overrides, or "" if no data directory is configured.
+func instanceOverridesPath(cfg *config.Config) string {
+	if cfg.Storage.DataDir == "" {
+		return ""
+	}
+	return filepath.Join(cfg.Storage.DataDir, instanceOverridesFile)
+}
+
+// newInstanceOverrides loads instance overrides from path.
+//
+// A missing or unreadable file yields an empty set of overrides; they are
+// not critical enough to prevent the server from starting.
+func newInstanceOverrides(path string) *instanceOverrides {
+	o := &instanceOverrides{
+		path:      path,
+		instances: make(map[string]*instanceOverride),
+	}
+	if path == "" {
+		return o
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			log.Warn("Failed to read instance overrides from %s: %v", path, err)
+		}
+		return o
+	}
+	if err := json.Unmarshal(data, &o.instances); err != nil {
+		log.Warn("Failed to parse instance overrides in %s: %v", path, err)
+		o.instances = make(map[string]*instanceOverride)
+	}
+	return o
+}
+
+// apply replaces the settings of instances that were changed after creation.
+func (o *instanceOverrides) apply(instances []*Instance) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	for _, inst := range instances {
+		override, ok := o.instances[inst.ID]
+		if !ok {
+			continue
+		}
+		if override.Alias != "" {
+			inst.Alias = override.Alias
+		}
+		if override.MaxConcurrent != nil {
+			if inst.Metadata == nil {
+				inst.Metadata = make(map[string]string)
+			}
+			inst.Metadata["max_concurrent"] = strconv.Itoa(*override.MaxConcurrent)
+		}
+	}
+}
+
+// applyRunSpec merges the settings changed on a live instance into the
+// start request it was created from, so an instance recreated from the
+// spec (xw restart) keeps them. The alias is not merged; the restart keeps
+// the instance's current alias itself.
+func (o *instanceOverrides) applyRunSpec(instanceID string, spec *RunSpec) {
+	if spec == nil {
+		return
+	}
+
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	override, ok := o.instances[instanceID]
+	if !ok || override.MaxConcurrent == nil {
+		return
+	}
+	config := make(map[string]interface{}, len(spec.AdditionalConfig)+1)
+	for k, v := range spec.AdditionalConfig {
+		config[k] = v
+	}
+	config["max_concurrent"] = *override.MaxConcurrent
+	spec.AdditionalConfig = config
+}
+
+// applyStartFailures reports instances kept after a failed start as
+// StateFailed with the start error. A kept container that was later started
+// successfully (state running) is reported as is.
+func (o *instanceOverrides) applyStartFailures(instances []*Instance) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	for _, inst := range instances {
+		override, ok := o.instances[inst.ID]
+		if !ok || override.StartError == "" || inst.State == StateRunning {
+			continue
+		}
+		inst.State = StateFailed
+		inst.Error = fmt.Sprintf("failed to start: %s", override.StartError)
+	}
+}
+
+// startFailed reports whether an instance failed to start and was kept.
+func (o *instanceOverrides) startFailed(instanceID string) bool {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	override, ok := o.instances[instanceID]
+	return ok && override.StartError != ""
+}
+
+// setStartError records that an instance's container failed to start and
+// was kept for inspection, and persists the overrides.
+func (o *instanceOverrides) setStartError(instanceID string, err error) error {
+	return o.update(instanceID, func(override *instanceOverride) {
+		override.StartError = err.Error()
+	})
+}
+
+// clearStartError forgets the start failure of an instance whose container
+// has since been started successfully, so it is again reported with its
+// actual state and restarted by the supervisor if it crashes. The overrides
+// are only persisted if a failure was recorded.
+func (o *instanceOverrides) clearStartError(instanceID string) error {
+	if !o.startFailed(instanceID) {
+		return nil
+	}
+	return o.update(instanceID, func(override *instanceOverride) {
+		override.StartError = ""
+	})
+}
+
+// setAlias records a new alias for an instance and persists the overrides.
+func (o *instanceOverrides) setAlias(instanceID, alias string) error {
+	return o.update(instanceID, func(override *instanceOverride) {
+		override.Alias = alias
+	})
+}
+
+// setMaxConcurrent records a new concurrency limit for an instance and
+// persists the overrides.
+func (o *instanceOverrides) setMaxConcurrent(instanceID string, maxConcurrent int) error {
+	return o.update(instanceID, func(override *instanceOverride) {
+		override.MaxConcurrent = &maxConcurrent
+	})
+}
+
+// update applies fn to the override of an instance and persists the
+// result. The change is rolled back if it cannot be saved.
+func (o *instanceOverrides) update(instanceID string, fn func(*instanceOverride)) error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	previous, existed := o.instances[instanceID]
+	updated := &instanceOverride{}
+	if existed {
+		*updated = *previous
+	}
+	fn(updated)
+
+	o.instances[instanceID] = updated
+	if err := o.save(); err != nil {
+		if existed {
+			o.instances[instanceID] = previous
+		} else {
+			delete(o.instances, instanceID)
+		}
+		return err
+	}
+	return nil
+}
+
+// clear forgets the overrides of an instance, e.g. when it is removed.
+func (o *instanceOverrides) clear(instanceID string) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	if _, ok := o.instances[instanceID]; !ok {
+		return
+	}
+	delete(o.instances, instanceID)
+	if err := o.save(); err != nil {
+		log.Warn("Failed to save instance overrides: %v", err)
+	}
+}
+
+// save writes the overrides to disk. The caller must hold o.mu.
+func (o *instanceOverrides) save() error {
+	if o.path == "" {
+		return nil
+	}
+
+	data, err := json.MarshalIndent(o.instances, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode instance overrides: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(o.path), 0755); err != nil {
+		return fmt.Errorf("failed to create directory for instance overrides: %w", err)
+	}
+	if err := os.WriteFile(o.path, data, 0644); err != nil {
+		return fmt.Errorf("failed to write instance overrides: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,79 @@
+package runtime
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+)
+
+func TestInstanceOverridesClearStartError(t *testing.T) {
+	path := filepath.Join(t.TempDir(), instanceOverridesFile)
+	o := newInstanceOverrides(path)
+	if err := o.setAlias("qwen3-8b-1", "translator"); err != nil {
+		t.Fatal(err)
+	}
+	if err := o.setStartError("qwen3-8b-1", errors.New("engine exited")); err != nil {
+		t.Fatal(err)
+	}
+
+	inst := &Instance{ID: "qwen3-8b-1", State: StateStopped}
+	o.applyStartFailures([]*Instance{inst})
+	if inst.State != StateFailed {
+		t.Fatalf("state with a start failure = %s, want %s", inst.State, StateFailed)
+	}
+
+	if err := o.clearStartError("qwen3-8b-1"); err != nil {
+		t.Fatalf("clearStartError() failed: %v", err)
+	}
+
+	// The failure is gone after a server restart too; other settings stay
+	reloaded := newInstanceOverrides(path)
+	if reloaded.startFailed("qwen3-8b-1") {
+		t.Error("start failure still recorded after clearStartError()")
+	}
+	inst = &Instance{ID: "qwen3-8b-1", Alias: "qwen3-8b", State: StateStopped}
+	reloaded.apply([]*Instance{inst})
+	reloaded.applyStartFailures([]*Instance{inst})
+	if inst.State != StateStopped || inst.Alias != "translator" {
+		t.Errorf("instance = %s %s, want translator %s", inst.Alias, inst.State, StateStopped)
+	}
+
+	// Instances without a failure are left alone
+	if err := o.clearStartError("qwen3-8b-2"); err != nil {
+		t.Fatalf("clearStartError() of an instance without a failure failed: %v", err)
+	}
+	if _, ok := o.instances["qwen3-8b-2"]; ok {
+		t.Error("clearStartError() added an override for an instance without a failure")
+	}
+}
+
+func TestInstanceOverridesApplyRunSpec(t *testing.T) {
+	o := newInstanceOverrides("")
+	if err := o.setMaxConcurrent("qwen3-8b-1", 8); err != nil {
+		t.Fatal(err)
+	}
+
+	recorded := map[string]interface{}{"max_concurrent": float64(4), "device": "0,1"}
+	spec := &RunSpec{ModelID: "qwen3-8b", AdditionalConfig: recorded}
+	o.applyRunSpec("qwen3-8b-1", spec)
+	if spec.AdditionalConfig["max_concurrent"] != 8 || spec.AdditionalConfig["device"] != "0,1" {
+		t.Errorf("additional_config = %v, want the live limit and the recorded device", spec.AdditionalConfig)
+	}
+	if recorded["max_concurrent"] != float64(4) {
+		t.Error("applyRunSpec() modified the recorded config")
+	}
+
+	// A spec without a recorded config gets the live limit too
+	spec = &RunSpec{ModelID: "qwen3-8b"}
+	o.applyRunSpec("qwen3-8b-1", spec)
+	if spec.AdditionalConfig["max_concurrent"] != 8 {
+		t.Errorf("additional_config = %v, want the live limit", spec.AdditionalConfig)
+	}
+
+	// Instances without overrides keep their spec
+	spec = &RunSpec{ModelID: "qwen3-8b", AdditionalConfig: recorded}
+	o.applyRunSpec("qwen3-8b-2", spec)
+	if spec.AdditionalConfig["max_concurrent"] != float64(4) {
+		t.Errorf("additional_config = %v, want the recorded limit", spec.AdditionalConfig)
+	}
+}
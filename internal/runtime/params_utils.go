@@ -1,9 +1,8 @@
 package runtime
 
 import (
+	"strconv"
 	"strings"
-	
-	"github.com/tsingmaoai/xw-cli/internal/logger"
 )
 
 // convertTemplateParamsToEnv converts template parameters to environment variables.
@@ -31,7 +30,7 @@ func convertTemplateParamsToEnv(templateParams []string) map[string]string {
 		// Split on first '=' to separate key and value
 		parts := strings.SplitN(param, "=", 2)
 		if len(parts) != 2 {
-			logger.Warn("Invalid template parameter format (expected key=value): %s", param)
+			log.Warn("Invalid template parameter format (expected key=value): %s", param)
 			continue
 		}
 		
@@ -39,7 +38,7 @@ func convertTemplateParamsToEnv(templateParams []string) map[string]string {
 		value := strings.TrimSpace(parts[1])
 		
 		if key == "" {
-			logger.Warn("Empty key in template parameter: %s", param)
+			log.Warn("Empty key in template parameter: %s", param)
 			continue
 		}
 		
@@ -47,7 +46,7 @@ func convertTemplateParamsToEnv(templateParams []string) map[string]string {
 		envKey := convertToEnvVarName(key)
 		env[envKey] = value
 		
-		logger.Debug("Template param: %s=%s -> %s=%s", key, value, envKey, value)
+		log.Debug("Template param: %s=%s -> %s=%s", key, value, envKey, value)
 	}
 	
 	return env
@@ -87,3 +86,61 @@ func convertToEnvVarName(key string) string {
 	return strings.ToUpper(result.String())
 }
 
+// ConfigInt reads an integer value from an ExtraConfig map.
+//
+// ExtraConfig values arrive from JSON request bodies, so numbers may be
+// decoded as float64 rather than int. This helper accepts int, int64,
+// float64, and numeric strings.
+//
+// Parameters:
+//   - cfg: Configuration map (may be nil)
+//   - key: Key to look up
+//
+// Returns:
+//   - The integer value and true if the key exists and is numeric
+//   - 0 and false otherwise
+func ConfigInt(cfg map[string]interface{}, key string) (int, bool) {
+	switch v := cfg[key].(type) {
+	case int:
+		return v, true
+	case int64:
+		return int(v), true
+	case float64:
+		return int(v), true
+	case string:
+		if n, err := strconv.Atoi(strings.TrimSpace(v)); err == nil {
+			return n, true
+		}
+	}
+	return 0, false
+}
+
+// ConfigFloat reads a floating point value from an ExtraConfig map.
+//
+// Like ConfigInt, this accepts the numeric types produced by JSON decoding
+// as well as numeric strings.
+//
+// Parameters:
+//   - cfg: Configuration map (may be nil)
+//   - key: Key to look up
+//
+// Returns:
+//   - The value and true if the key exists and is numeric
+//   - 0 and false otherwise
+func ConfigFloat(cfg map[string]interface{}, key string) (float64, bool) {
+	switch v := cfg[key].(type) {
+	case float64:
+		return v, true
+	case float32:
+		return float64(v), true
+	case int:
+		return float64(v), true
+	case int64:
+		return float64(v), true
+	case string:
+		if f, err := strconv.ParseFloat(strings.TrimSpace(v), 64); err == nil {
+			return f, true
+		}
+	}
+	return 0, false
+}
@@ -5,8 +5,6 @@ import (
 	"fmt"
 	"net"
 	"sync"
-
-	"github.com/tsingmaoai/xw-cli/internal/logger"
 )
 
 // PortAllocator manages dynamic port allocation for model instances.
@@ -62,7 +60,7 @@ func (pa *PortAllocator) GetFreePort() (int, error) {
 		// Check if port is available
 		if pa.isPortAvailable(p) {
 			pa.allocated[p] = true
-			logger.Debug("Allocated port %d", p)
+			log.Debug("Allocated port %d", p)
 			return p, nil
 		}
 	}
@@ -80,7 +78,7 @@ func (pa *PortAllocator) ReleasePort(port int) {
 	
 	if pa.allocated[port] {
 		delete(pa.allocated, port)
-		logger.Debug("Released port %d", port)
+		log.Debug("Released port %d", port)
 	}
 }
 
@@ -100,7 +98,7 @@ func (pa *PortAllocator) MarkPortUsed(port int) {
 	defer pa.mu.Unlock()
 	
 	pa.allocated[port] = true
-	logger.Debug("Marked port %d as used", port)
+	log.Debug("Marked port %d as used", port)
 }
 
 // isPortAvailable checks if a specific port is available.
@@ -138,7 +136,7 @@ var (
 func GetGlobalPortAllocator() *PortAllocator {
 	globalPortAllocatorOnce.Do(func() {
 		globalPortAllocator = NewPortAllocator(10881, 11881)
-		logger.Info("Initialized global port allocator (range: 10881-11881)")
+		log.Info("Initialized global port allocator (range: 10881-11881)")
 	})
 	return globalPortAllocator
 }
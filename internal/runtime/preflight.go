@@ -0,0 +1,277 @@
+package runtime
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/tsingmaoai/xw-cli/internal/api"
+	"github.com/tsingmaoai/xw-cli/internal/config"
+	"github.com/tsingmaoai/xw-cli/internal/models"
+)
+
+// nativeBackends are the backends with a native runtime (see
+// NativeRuntimeBase); native mode is only accepted for these.
+var nativeBackends = map[string]bool{
+	string(api.BackendTypeVLLM): true,
+}
+
+// ChipConfigKey returns the config key (e.g., "ascend-910b") of the chip an
+// instance would run on.
+//
+// The chip is that of the first device in deviceList if devices are
+// selected explicitly, and the first detected device otherwise, matching
+// the chip Run uses for template lookup.
+//
+// Parameters:
+//   - configDir: Configuration directory (for the device allocator)
+//   - deviceList: Explicit device selection (e.g., "0,1"), or empty
+//
+// Returns:
+//   - Chip config key, or empty string if no device is detected
+func (m *Manager) ChipConfigKey(configDir, deviceList string) string {
+	allocator, err := m.getOrCreateAllocator(configDir)
+	if err != nil {
+		return ""
+	}
+	allDevices := allocator.GetAllDevices()
+	if len(allDevices) == 0 {
+		return ""
+	}
+
+	if deviceList != "" {
+		if indices, err := parseDeviceList(deviceList); err == nil && len(indices) > 0 {
+			for _, dev := range allDevices {
+				if dev.Index == indices[0] {
+					return dev.ConfigKey
+				}
+			}
+		}
+	}
+	return allDevices[0].ConfigKey
+}
+
+// CheckModelCompatibility checks that a model can be served on a chip with
+// the requested engine, and resolves the engine to use.
+//
+// This is a preflight run before any image is pulled or device allocated,
+// so that an unsupported combination fails immediately with the engines
+// that would work instead of after a long image pull.
+//
+// If backendType is empty, the model's preferred engine for the chip is
+// returned; if only deploymentMode is empty, the preferred engine with that
+// backend is. Native mode is accepted for any backend the model supports
+// that has a native runtime (see nativeBackends). If chipKey is empty (no device detected), the engine is
+// checked against every device the model supports.
+//
+// Parameters:
+//   - spec: Model specification
+//   - chipKey: Chip config key (see ChipConfigKey), or empty
+//   - backendType: Requested backend (e.g., "vllm"), or empty for the default
+//   - deploymentMode: Requested deployment mode (e.g., "docker"), or empty for the default
+//
+// Returns:
+//   - Engine to use
+//   - Error listing the supported devices or engines if the combination is unsupported
+func CheckModelCompatibility(spec *models.ModelSpec, chipKey, backendType, deploymentMode string) (*models.BackendOption, error) {
+	engines, err := compatibleEngines(spec, chipKey)
+	if err != nil {
+		return nil, err
+	}
+
+	if backendType == "" {
+		return &engines[0], nil
+	}
+	if deploymentMode == string(api.DeploymentModeNative) && !nativeBackends[backendType] {
+		return nil, fmt.Errorf("backend %s has no native mode (native engines: %s)",
+			backendType, formatNativeEngines())
+	}
+	for i := range engines {
+		if string(engines[i].Type) != backendType {
+			continue
+		}
+		if deploymentMode == "" || string(engines[i].Mode) == deploymentMode {
+			return &engines[i], nil
+		}
+	}
+
+	// Models list their container engines; a backend the model supports
+	// can also run natively, with the engine installed on the host, if xw
+	// has a native runtime for it
+	if deploymentMode == string(api.DeploymentModeNative) {
+		for i := range engines {
+			if string(engines[i].Type) == backendType {
+				engine := engines[i]
+				engine.Mode = api.DeploymentModeNative
+				return &engine, nil
+			}
+		}
+	}
+
+	if deploymentMode == "" {
+		return nil, fmt.Errorf("backend %s not available for model %s (supported engines: %s)",
+			backendType, spec.ID, formatEngines(engines))
+	}
+	if chipKey == "" {
+		return nil, fmt.Errorf("backend %s (%s mode) not available for model %s (supported engines: %s)",
+			backendType, deploymentMode, spec.ID, formatEngines(engines))
+	}
+	return nil, fmt.Errorf("backend %s (%s mode) not available for model %s on %s devices (supported engines for %s: %s)",
+		backendType, deploymentMode, spec.ID, chipKey, chipKey, formatEngines(engines))
+}
+
+// compatibleEngines returns the engines that can serve a model on a chip,
+// in the model's priority order.
+//
+// If chipKey is empty (no device detected) or the model declares no
+// per-device engines, the engines of every supported device are returned.
+func compatibleEngines(spec *models.ModelSpec, chipKey string) ([]models.BackendOption, error) {
+	var engines []models.BackendOption
+	if chipKey != "" {
+		deviceType := api.DeviceType(chipKey)
+		if !spec.SupportsDevice(deviceType) {
+			return nil, fmt.Errorf("model %s does not support %s devices (supported devices: %s)",
+				spec.ID, chipKey, formatSupportedDevices(spec))
+		}
+		engines = spec.GetEnginesForDevice(deviceType)
+	}
+	if len(engines) == 0 {
+		for _, device := range sortedDeviceTypes(spec) {
+			engines = append(engines, spec.SupportedDevices[device]...)
+		}
+	}
+	if len(engines) == 0 {
+		return nil, fmt.Errorf("no backends available for model %s", spec.ID)
+	}
+	return engines, nil
+}
+
+// selectEngine chooses the engine for a start request that does not
+// specify one.
+//
+// The model's engines for the chip are tried in priority order, and the
+// first one is chosen whose runtime is registered and, for Docker
+// runtimes, for which an image is configured for the chip and architecture
+// (i.e. that can be pulled). If deploymentMode is set, only engines with
+// that mode are considered.
+//
+// Parameters:
+//   - spec: Model specification
+//   - chipKey: Chip config key (see ChipConfigKey), or empty
+//   - deploymentMode: Requested deployment mode, or empty for any
+//   - arch: Runtime image architecture, or empty for the host architecture
+//
+// Returns:
+//   - Engine to use
+//   - Error listing why each engine was skipped if none is usable
+func (m *Manager) selectEngine(spec *models.ModelSpec, chipKey, deploymentMode, arch string) (*models.BackendOption, error) {
+	engines, err := compatibleEngines(spec, chipKey)
+	if err != nil {
+		return nil, err
+	}
+
+	// Image availability can only be checked for a known chip
+	var images config.RuntimeImagesConfig
+	if chipKey != "" {
+		if arch == "" {
+			arch, _ = getSystemArch()
+		}
+		images, err = config.LoadRuntimeImagesConfig()
+		if err != nil {
+			log.Warn("Failed to load runtime images, not checking image availability: %v", err)
+		}
+	}
+
+	var skipped []string
+	for i := range engines {
+		engine := &engines[i]
+		name := fmt.Sprintf("%s:%s", engine.Type, engine.Mode)
+		if deploymentMode != "" && string(engine.Mode) != deploymentMode {
+			continue
+		}
+
+		m.mu.RLock()
+		_, registered := m.runtimes[name]
+		m.mu.RUnlock()
+		if !registered {
+			skipped = append(skipped, fmt.Sprintf("%s (runtime not available)", name))
+			continue
+		}
+
+		if images != nil && engine.Mode == api.DeploymentModeDocker {
+			if _, err := config.GetImageForChipAndEngine(images, chipKey, string(engine.Type), arch); err != nil {
+				skipped = append(skipped, fmt.Sprintf("%s (no %s image for %s)", name, arch, chipKey))
+				continue
+			}
+		}
+
+		return engine, nil
+	}
+
+	if len(skipped) == 0 {
+		return nil, fmt.Errorf("model %s has no %s mode engine (supported engines: %s)",
+			spec.ID, deploymentMode, formatEngines(engines))
+	}
+	return nil, fmt.Errorf("no usable engine for model %s: %s", spec.ID, strings.Join(skipped, ", "))
+}
+
+// formatNativeEngines lists the native engines, for errors.
+func formatNativeEngines() string {
+	names := make([]string, 0, len(nativeBackends))
+	for backend := range nativeBackends {
+		names = append(names, backend+":"+string(api.DeploymentModeNative))
+	}
+	sort.Strings(names)
+	return strings.Join(names, ", ")
+}
+
+// sortedDeviceTypes returns the devices a model supports in a stable order.
+func sortedDeviceTypes(spec *models.ModelSpec) []api.DeviceType {
+	devices := spec.GetAllSupportedDevices()
+	sort.Slice(devices, func(i, j int) bool { return devices[i] < devices[j] })
+	return devices
+}
+
+// formatSupportedDevices lists the devices a model supports, for errors.
+func formatSupportedDevices(spec *models.ModelSpec) string {
+	devices := sortedDeviceTypes(spec)
+	names := make([]string, len(devices))
+	for i, device := range devices {
+		names[i] = string(device)
+	}
+	return strings.Join(names, ", ")
+}
+
+// formatEngines lists engines in the backend:mode form used by --engine,
+// without duplicates.
+func formatEngines(engines []models.BackendOption) string {
+	seen := make(map[string]bool, len(engines))
+	names := make([]string, 0, len(engines))
+	for _, engine := range engines {
+		name := fmt.Sprintf("%s:%s", engine.Type, engine.Mode)
+		if !seen[name] {
+			seen[name] = true
+			names = append(names, name)
+		}
+	}
+	return strings.Join(names, ", ")
+}
+
+// RuntimeNames returns the names of the registered runtimes, sorted
+// (e.g., ["mindie:docker", "vllm:docker", "vllm:native"]).
+func (m *Manager) RuntimeNames() []string {
+	return m.availableRuntimes()
+}
+
+// availableRuntimes returns the names of the registered runtimes, sorted.
+func (m *Manager) availableRuntimes() []string {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	names := make([]string, 0, len(m.runtimes))
+	for name := range m.runtimes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,45 @@
+package runtime
+
+import (
+	"testing"
+
+	"github.com/tsingmaoai/xw-cli/internal/api"
+	"github.com/tsingmaoai/xw-cli/internal/models"
+)
+
+func TestCheckModelCompatibilityNativeMode(t *testing.T) {
+	spec := &models.ModelSpec{
+		ID: "qwen3-8b",
+		SupportedDevices: map[api.DeviceType][]models.BackendOption{
+			"ascend-910b": {
+				{Type: api.BackendTypeVLLM, Mode: api.DeploymentModeDocker},
+				{Type: api.BackendTypeMindIE, Mode: api.DeploymentModeDocker},
+			},
+		},
+	}
+
+	tests := []struct {
+		backend string
+		wantErr bool
+	}{
+		{"vllm", false},
+		{"mindie", true},
+		{"mlguider", true},
+	}
+	for _, tt := range tests {
+		engine, err := CheckModelCompatibility(spec, "ascend-910b", tt.backend, "native")
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("%s:native accepted, want error", tt.backend)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s:native rejected: %v", tt.backend, err)
+			continue
+		}
+		if string(engine.Type) != tt.backend || engine.Mode != api.DeploymentModeNative {
+			t.Errorf("%s:native resolved to %s:%s", tt.backend, engine.Type, engine.Mode)
+		}
+	}
+}
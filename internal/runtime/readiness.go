@@ -0,0 +1,219 @@
+package runtime
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"syscall"
+	"time"
+)
+
+const (
+	// readinessProbeInterval is how often the manager probes instances that
+	// are running but have not yet answered their health endpoint.
+	readinessProbeInterval = 2 * time.Second
+
+	// readinessProbeTimeout bounds a single probe request.
+	readinessProbeTimeout = 1 * time.Second
+)
+
+// readinessProbePaths are tried in order; the first 2xx answer marks the
+// instance ready. /v1/models covers engines that do not expose /health.
+var readinessProbePaths = []string{"/health", "/v1/models"}
+
+// readinessStatus is the last known readiness of an instance.
+type readinessStatus struct {
+	ready     bool
+	lastError string
+	checkedAt time.Time
+}
+
+// readinessTracker records readiness probe results per instance.
+//
+// A running container is only reported as StateRunning once its inference
+// endpoint has answered a probe. Until then it is reported as StateStarting
+// together with the last probe error, so clients see that the engine is
+// still warming up instead of a misleading "running" state. Readiness is
+// latched: once an instance has answered, later probe failures under load
+// do not take it out of rotation.
+type readinessTracker struct {
+	mu       sync.Mutex
+	statuses map[string]*readinessStatus // instanceID → status
+	probing  map[string]bool             // instanceIDs with a background probe in flight
+	client   *http.Client
+}
+
+// newReadinessTracker creates an empty readiness tracker.
+func newReadinessTracker() *readinessTracker {
+	return &readinessTracker{
+		statuses: make(map[string]*readinessStatus),
+		probing:  make(map[string]bool),
+		client:   &http.Client{Timeout: readinessProbeTimeout},
+	}
+}
+
+// apply rewrites the state of running instances according to readiness.
+//
+// apply never probes itself, so listing instances does not wait on engines.
+// Instances that have never been probed (e.g., after a server restart) are
+// reported as starting and probed in the background; the next listing
+// reports the result. Tracked statuses for instances that no longer exist
+// are dropped.
+func (t *readinessTracker) apply(instances []*Instance) {
+	seen := make(map[string]bool, len(instances))
+
+	for _, inst := range instances {
+		seen[inst.ID] = true
+		if inst.State != StateRunning {
+			continue
+		}
+
+		t.mu.Lock()
+		status, ok := t.statuses[inst.ID]
+		t.mu.Unlock()
+
+		if !ok {
+			t.probeAsync(inst)
+			status = &readinessStatus{}
+		}
+
+		if status.ready {
+			continue
+		}
+
+		inst.State = StateStarting
+		if inst.Metadata == nil {
+			inst.Metadata = make(map[string]string)
+		}
+		if status.lastError != "" {
+			inst.Metadata["health_error"] = status.lastError
+		}
+	}
+
+	t.mu.Lock()
+	for id := range t.statuses {
+		if !seen[id] {
+			delete(t.statuses, id)
+		}
+	}
+	t.mu.Unlock()
+}
+
+// reset forgets the readiness of an instance, e.g. after it is restarted,
+// so it is reported as starting until it answers again.
+func (t *readinessTracker) reset(instanceID string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	delete(t.statuses, instanceID)
+}
+
+// probePending probes every running instance that is not yet ready.
+func (t *readinessTracker) probePending(ctx context.Context, instances []*Instance) {
+	for _, inst := range instances {
+		if inst.State != StateRunning {
+			continue
+		}
+
+		t.mu.Lock()
+		status, ok := t.statuses[inst.ID]
+		t.mu.Unlock()
+
+		if ok && status.ready {
+			continue
+		}
+		t.probe(ctx, inst)
+	}
+}
+
+// probeAsync probes an instance in the background unless a background
+// probe of it is already in flight.
+func (t *readinessTracker) probeAsync(inst *Instance) {
+	t.mu.Lock()
+	if t.probing[inst.ID] {
+		t.mu.Unlock()
+		return
+	}
+	t.probing[inst.ID] = true
+	t.mu.Unlock()
+
+	// The caller goes on to rewrite the listed instance; probe a copy
+	target := &Instance{ID: inst.ID, Port: inst.Port}
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(),
+			time.Duration(len(readinessProbePaths))*readinessProbeTimeout)
+		defer cancel()
+		t.probe(ctx, target)
+
+		t.mu.Lock()
+		delete(t.probing, target.ID)
+		t.mu.Unlock()
+	}()
+}
+
+// probe checks the instance endpoint and records the result.
+func (t *readinessTracker) probe(ctx context.Context, inst *Instance) *readinessStatus {
+	status := &readinessStatus{checkedAt: time.Now()}
+
+	if inst.Port == 0 {
+		status.lastError = "no port assigned"
+	} else if err := t.probeEndpoint(ctx, inst.Port); err != nil {
+		status.lastError = err.Error()
+	} else {
+		status.ready = true
+		log.Info("Instance %s is ready on port %d", inst.ID, inst.Port)
+	}
+
+	t.mu.Lock()
+	t.statuses[inst.ID] = status
+	t.mu.Unlock()
+
+	return status
+}
+
+// probeEndpoint returns nil if any readiness path answers with 2xx.
+// The returned error is condensed for display (e.g., "connection refused").
+func (t *readinessTracker) probeEndpoint(ctx context.Context, port int) error {
+	var lastErr error
+	for _, path := range readinessProbePaths {
+		url := fmt.Sprintf("http://localhost:%d%s", port, path)
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+		if err != nil {
+			return err
+		}
+
+		resp, err := t.client.Do(req)
+		if err != nil {
+			// Connection-level failures apply to every path; stop early.
+			return condenseProbeError(err)
+		}
+		resp.Body.Close()
+
+		if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+			return nil
+		}
+		lastErr = fmt.Errorf("%s returned HTTP %d", path, resp.StatusCode)
+	}
+	return lastErr
+}
+
+// condenseProbeError maps transport errors to short human-readable reasons.
+func condenseProbeError(err error) error {
+	switch {
+	case errors.Is(err, syscall.ECONNREFUSED):
+		return errors.New("connection refused")
+	case errors.Is(err, syscall.ECONNRESET):
+		return errors.New("connection reset")
+	case errors.Is(err, context.DeadlineExceeded) || isTimeout(err):
+		return errors.New("timeout")
+	default:
+		return err
+	}
+}
+
+// isTimeout reports whether err is a network timeout.
+func isTimeout(err error) bool {
+	var te interface{ Timeout() bool }
+	return errors.As(err, &te) && te.Timeout()
+}
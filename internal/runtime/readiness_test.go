@@ -0,0 +1,42 @@
+package runtime
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestReadinessApplyDoesNotProbe(t *testing.T) {
+	release := make(chan struct{})
+	engine := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+	}))
+	defer engine.Close()
+	u, _ := url.Parse(engine.URL)
+	port, _ := strconv.Atoi(u.Port())
+
+	tracker := newReadinessTracker()
+	listed := func() *Instance {
+		inst := &Instance{ID: "qwen3-8b-1", Port: port, State: StateRunning}
+		tracker.apply([]*Instance{inst})
+		return inst
+	}
+
+	// The engine has not answered yet: apply reports starting at once
+	start := time.Now()
+	if inst := listed(); inst.State != StateStarting {
+		t.Errorf("state before the probe answers = %s, want %s", inst.State, StateStarting)
+	}
+	listed()
+	if elapsed := time.Since(start); elapsed > readinessProbeTimeout/2 {
+		t.Errorf("apply() took %v, want it not to wait for the probe", elapsed)
+	}
+
+	close(release)
+	waitFor(t, "the background probe", func() bool {
+		return listed().State == StateRunning
+	})
+}
@@ -0,0 +1,130 @@
+package runtime
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/docker/docker/api/types/registry"
+
+	"github.com/tsingmaoai/xw-cli/internal/config"
+)
+
+// dockerHubAuthKey is the key under which the Docker CLI stores Docker Hub
+// credentials in config.json.
+const dockerHubAuthKey = "https://index.docker.io/v1/"
+
+// dockerConfigFile is the subset of the Docker CLI's config.json used to
+// find registry credentials.
+type dockerConfigFile struct {
+	Auths map[string]struct {
+		Auth          string `json:"auth"`
+		IdentityToken string `json:"identitytoken"`
+	} `json:"auths"`
+	CredsStore  string            `json:"credsStore"`
+	CredHelpers map[string]string `json:"credHelpers"`
+}
+
+// registryAuthFor returns the encoded credentials for pulling an image
+// through the Docker API, as stored by `docker login`.
+//
+// Pulls through the API do not read the Docker CLI configuration, so the
+// credentials are looked up the way the CLI does: a per-registry credential
+// helper, then the global credential store, then the auths section of
+// $DOCKER_CONFIG/config.json (default ~/.docker/config.json).
+//
+// Parameters:
+//   - imageName: Image to pull
+//
+// Returns:
+//   - Value for the X-Registry-Auth header, or empty string for anonymous pulls
+func registryAuthFor(imageName string) string {
+	host := config.ImageRegistry(imageName)
+	key := host
+	if host == "docker.io" {
+		key = dockerHubAuthKey
+	}
+
+	cfg, err := readDockerConfig()
+	if err != nil {
+		return ""
+	}
+
+	var auth registry.AuthConfig
+	if helper := cfg.CredHelpers[host]; helper != "" {
+		auth, err = credentialsFromHelper(helper, key)
+	} else if cfg.CredsStore != "" {
+		auth, err = credentialsFromHelper(cfg.CredsStore, key)
+	} else if entry, ok := cfg.Auths[key]; ok {
+		auth.IdentityToken = entry.IdentityToken
+		if decoded, decodeErr := base64.StdEncoding.DecodeString(entry.Auth); decodeErr == nil {
+			auth.Username, auth.Password, _ = strings.Cut(string(decoded), ":")
+		}
+	} else {
+		return ""
+	}
+	if err != nil {
+		log.Debug("No registry credentials for %s: %v", host, err)
+		return ""
+	}
+
+	auth.ServerAddress = key
+	encoded, err := registry.EncodeAuthConfig(auth)
+	if err != nil {
+		return ""
+	}
+	return encoded
+}
+
+// readDockerConfig reads the Docker CLI configuration file.
+func readDockerConfig() (*dockerConfigFile, error) {
+	dir := os.Getenv("DOCKER_CONFIG")
+	if dir == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return nil, err
+		}
+		dir = filepath.Join(home, ".docker")
+	}
+
+	data, err := os.ReadFile(filepath.Join(dir, "config.json"))
+	if err != nil {
+		return nil, err
+	}
+
+	var cfg dockerConfigFile
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return nil, err
+	}
+	return &cfg, nil
+}
+
+// credentialsFromHelper asks a docker-credential-<helper> program for the
+// credentials of a registry.
+func credentialsFromHelper(helper, serverURL string) (registry.AuthConfig, error) {
+	cmd := exec.Command("docker-credential-"+helper, "get")
+	cmd.Stdin = strings.NewReader(serverURL)
+	var out bytes.Buffer
+	cmd.Stdout = &out
+	if err := cmd.Run(); err != nil {
+		return registry.AuthConfig{}, err
+	}
+
+	var creds struct {
+		Username string `json:"Username"`
+		Secret   string `json:"Secret"`
+	}
+	if err := json.Unmarshal(out.Bytes(), &creds); err != nil {
+		return registry.AuthConfig{}, err
+	}
+
+	// Helpers return "<token>" as the username for identity tokens
+	if creds.Username == "<token>" {
+		return registry.AuthConfig{IdentityToken: creds.Secret}, nil
+	}
+	return registry.AuthConfig{Username: creds.Username, Password: creds.Secret}, nil
+}
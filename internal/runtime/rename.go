@@ -0,0 +1,69 @@
+package runtime
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/tsingmaoai/xw-cli/internal/models"
+)
+
+// Rename changes the alias of an instance without restarting it.
+//
+// The instance keeps its ID, container, port, and in-flight requests; only
+// the name used for routing changes, so traffic can be repointed without
+// downtime. The new alias is subject to the same rules as at creation: it
+// must not be used by another instance, and it must not be the ID of a
+// different registered model.
+//
+// Parameters:
+//   - ctx: Context for cancellation
+//   - oldAlias: Current alias of the instance
+//   - newAlias: Alias to assign
+//
+// Returns:
+//   - The renamed instance
+//   - Error if the instance is not found or the new alias is not available
+func (m *Manager) Rename(ctx context.Context, oldAlias, newAlias string) (*Instance, error) {
+	if newAlias == "" {
+		return nil, fmt.Errorf("new alias cannot be empty")
+	}
+	if newAlias == oldAlias {
+		return nil, fmt.Errorf("instance is already named '%s'", newAlias)
+	}
+
+	inst, err := m.findInstanceByAlias(ctx, oldAlias)
+	if err != nil {
+		return nil, err
+	}
+
+	if newAlias != inst.ModelID && models.GetModelSpec(newAlias) != nil {
+		return nil, fmt.Errorf("alias '%s' conflicts with an existing model ID, please choose a different alias", newAlias)
+	}
+
+	instances, err := m.List(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list instances: %w", err)
+	}
+	for _, other := range instances {
+		if other.ID == inst.ID {
+			continue
+		}
+		otherAlias := other.Alias
+		if otherAlias == "" {
+			otherAlias = other.ModelID // Backward compatibility
+		}
+		if otherAlias == newAlias {
+			return nil, fmt.Errorf("alias '%s' is already used by instance %s (state: %s)", newAlias, other.ID, other.State)
+		}
+	}
+
+	if err := m.overrides.setAlias(inst.ID, newAlias); err != nil {
+		return nil, err
+	}
+
+	m.notifyInstancesChanged()
+
+	log.Info("Renamed instance %s from '%s' to '%s'", inst.ID, oldAlias, newAlias)
+	inst.Alias = newAlias
+	return inst, nil
+}
@@ -0,0 +1,81 @@
+package runtime
+
+import (
+	"encoding/json"
+)
+
+// RunSpec is the start request an instance was created from.
+//
+// It is recorded on the instance (as the xw.run_spec container label) so the
+// instance can be recreated with identical parameters by `xw restart`. The
+// JSON field names match the body of the start API, so a recorded spec can
+// be submitted as a start request unchanged. The spec is kept out of
+// instance listings because its environment variables may hold secrets; the
+// server recreates the instance from it (see the /api/runtime/restart
+// endpoint).
+type RunSpec struct {
+	ModelID          string                 `json:"model_id"`
+	Alias            string                 `json:"alias"`
+	BackendType      string                 `json:"backend_type"`
+	DeploymentMode   string                 `json:"deployment_mode"`
+	AdditionalConfig map[string]interface{} `json:"additional_config,omitempty"` // Device selection, concurrency, engine limits, ...
+	Environment      map[string]string      `json:"environment,omitempty"`
+}
+
+// newRunSpec records the start request of an instance.
+//
+// It must be called before the run options are normalized, so that the
+// user's original values (e.g. "16g" rather than a byte count, or "auto"
+// device selection rather than the allocated devices) are recorded.
+//
+// Parameters:
+//   - opts: Run options as received from the start request
+//
+// Returns:
+//   - Encoded run spec, or empty string if it cannot be encoded
+func newRunSpec(opts *RunOptions) string {
+	spec := RunSpec{
+		ModelID:        opts.ModelID,
+		Alias:          opts.Alias,
+		BackendType:    opts.BackendType,
+		DeploymentMode: opts.DeploymentMode,
+		Environment:    opts.Environment,
+	}
+	if len(opts.AdditionalConfig) > 0 {
+		spec.AdditionalConfig = make(map[string]interface{}, len(opts.AdditionalConfig))
+		for k, v := range opts.AdditionalConfig {
+			// A recreated instance is real even if the spec is recorded
+			// for the command printed by a dry run
+			if k == "dry_run" {
+				continue
+			}
+			spec.AdditionalConfig[k] = v
+		}
+	}
+
+	data, err := json.Marshal(spec)
+	if err != nil {
+		log.Warn("Failed to record run options of %s: %v", opts.Alias, err)
+		return ""
+	}
+	return string(data)
+}
+
+// parseRunSpec decodes the run_spec metadata value of an instance.
+//
+// Parameters:
+//   - s: Encoded run spec
+//
+// Returns:
+//   - Run spec, or nil if s is empty or invalid (e.g., instances created
+//     before run specs were recorded)
+func parseRunSpec(s string) *RunSpec {
+	if s == "" {
+		return nil
+	}
+	var spec RunSpec
+	if err := json.Unmarshal([]byte(s), &spec); err != nil {
+		return nil
+	}
+	return &spec
+}
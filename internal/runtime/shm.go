@@ -0,0 +1,144 @@
+package runtime
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/docker/go-units"
+)
+
+// maxShmHostFraction is the largest share of host memory a default shared
+// memory size may claim. Runtime and sandbox defaults are sized for large
+// inference servers (up to 500GB) and would exceed the memory of smaller
+// hosts; an explicit shm_size is never reduced.
+const maxShmHostFraction = 0.5
+
+// meminfoPath is the kernel memory statistics file read for the host
+// memory size.
+const meminfoPath = "/proc/meminfo"
+
+// ParseShmSize parses a shared memory size as accepted by docker run
+// --shm-size: a byte count with an optional unit suffix (e.g., "16g",
+// "512m", "1073741824").
+//
+// Parameters:
+//   - s: Size string
+//
+// Returns:
+//   - Size in bytes
+//   - Error if the string is not a valid positive size
+func ParseShmSize(s string) (int64, error) {
+	size, err := units.RAMInBytes(strings.TrimSpace(s))
+	if err != nil {
+		return 0, fmt.Errorf("invalid shared memory size %q (expected e.g. 16g or 512m)", s)
+	}
+	if size <= 0 {
+		return 0, fmt.Errorf("shared memory size must be positive (got %q)", s)
+	}
+	return size, nil
+}
+
+// normalizeShmSize validates the shm_size option in opts.AdditionalConfig
+// and stores it as a byte count. Sizes arrive from the CLI as strings such
+// as "16g", or from JSON clients as plain numbers of bytes.
+//
+// Parameters:
+//   - opts: Run options whose AdditionalConfig is updated in place
+//
+// Returns:
+//   - Error if a value is present but not a valid size
+func normalizeShmSize(opts *RunOptions) error {
+	value, present := opts.AdditionalConfig["shm_size"]
+	if !present {
+		return nil
+	}
+
+	var size int64
+	if s, ok := value.(string); ok {
+		parsed, err := ParseShmSize(s)
+		if err != nil {
+			return err
+		}
+		size = parsed
+	} else if n, ok := ConfigInt(opts.AdditionalConfig, "shm_size"); ok && n > 0 {
+		size = int64(n)
+	} else {
+		return fmt.Errorf("shm_size must be a positive size (got %v)", value)
+	}
+
+	if total := hostMemoryBytes(); total > 0 && size > total {
+		log.Warn("Requested shared memory size %s exceeds host memory %s",
+			units.BytesSize(float64(size)), units.BytesSize(float64(total)))
+	}
+
+	opts.AdditionalConfig["shm_size"] = size
+	return nil
+}
+
+// ResolveShmSize returns the shared memory size for an instance container
+// and logs the value used.
+//
+// An explicit ExtraConfig["shm_size"] (from --shm-size) is used as is.
+// Otherwise the runtime's default, or the sandbox's if it implements
+// GetSharedMemorySize, is capped at maxShmHostFraction of host memory, so
+// instances can start on hosts with less memory than the default assumes.
+//
+// Parameters:
+//   - params: Instance creation parameters
+//   - sandbox: Device sandbox of the instance (may provide a default)
+//   - defaultSize: Runtime default in bytes
+//
+// Returns:
+//   - Shared memory size in bytes
+func ResolveShmSize(params *CreateParams, sandbox interface{}, defaultSize int64) int64 {
+	if size, ok := ConfigInt(params.ExtraConfig, "shm_size"); ok && size > 0 {
+		log.Info("Using shared memory size %s for instance %s (--shm-size)",
+			units.BytesSize(float64(size)), params.InstanceID)
+		return int64(size)
+	}
+
+	size := defaultSize
+	if provider, ok := sandbox.(interface{ GetSharedMemorySize() int64 }); ok {
+		size = provider.GetSharedMemorySize()
+	}
+
+	if total := hostMemoryBytes(); total > 0 {
+		if limit := int64(float64(total) * maxShmHostFraction); size > limit {
+			log.Info("Using shared memory size %s for instance %s (default %s capped to %.0f%% of host memory %s)",
+				units.BytesSize(float64(limit)), params.InstanceID, units.BytesSize(float64(size)),
+				maxShmHostFraction*100, units.BytesSize(float64(total)))
+			return limit
+		}
+	}
+
+	log.Info("Using shared memory size %s for instance %s (default)",
+		units.BytesSize(float64(size)), params.InstanceID)
+	return size
+}
+
+// hostMemoryBytes returns the total memory of the host in bytes, or 0 if
+// it cannot be determined.
+func hostMemoryBytes() int64 {
+	f, err := os.Open(meminfoPath)
+	if err != nil {
+		return 0
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		// Format: "MemTotal:       263772124 kB"
+		fields := strings.Fields(scanner.Text())
+		if len(fields) >= 2 && fields[0] == "MemTotal:" {
+			kb, err := strconv.ParseInt(fields[1], 10, 64)
+			if err != nil {
+				return 0
+			}
+			return kb * 1024
+		}
+	}
+	return 0
+}
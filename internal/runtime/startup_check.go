@@ -0,0 +1,121 @@
+package runtime
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/tsingmaoai/xw-cli/internal/api"
+)
+
+const (
+	// defaultStartupReadyTimeout is how long Run waits for a started
+	// instance to become ready unless the start sets ready_timeout. It
+	// matches the default of xw start --wait-timeout.
+	defaultStartupReadyTimeout = 30 * time.Minute
+
+	// startupLogTailLines is the number of log lines included in the error
+	// of an instance that exits during startup.
+	startupLogTailLines = 50
+)
+
+// startupCheckInterval is how often the instance state is checked while
+// waiting for readiness.
+var startupCheckInterval = 1 * time.Second
+
+// startupReadyTimeout returns how long Run waits for an instance to become
+// ready: the ready_timeout option in seconds (set by xw start --wait), or
+// defaultStartupReadyTimeout.
+func startupReadyTimeout(config map[string]interface{}) time.Duration {
+	if seconds, ok := ConfigInt(config, "ready_timeout"); ok && seconds > 0 {
+		return time.Duration(seconds) * time.Second
+	}
+	return defaultStartupReadyTimeout
+}
+
+// watchEarlyExit watches a started instance until its engine answers the
+// readiness probe, and returns an error if its container exits before, or
+// if it is not ready within timeout. The warming_up phase is reported when
+// the watch begins and the ready phase when the probe passes.
+//
+// Engines that fail while loading the model (a missing operator, too
+// little device memory, a MindIE "Execute fail" during warm-up) exit
+// before they become ready, often only after minutes of loading weights.
+// Without this check Run would report such an instance as started and the
+// failure would only show up later as requests failing. The error includes
+// the tail of the container's logs, which normally holds the engine's
+// stack trace.
+//
+// Parameters:
+//   - rt: Runtime the instance belongs to
+//   - inst: Started instance
+//   - timeout: Maximum time to wait for readiness (see startupReadyTimeout)
+//   - eventCh: Progress event channel (may be nil)
+//
+// Returns:
+//   - Error with the exit reason and log tail if the container exited or
+//     did not become ready in time
+func (m *Manager) watchEarlyExit(rt Runtime, inst *Instance, timeout time.Duration, eventCh chan<- string) error {
+	sendStartupEvent(eventCh, api.StartupEvent{
+		Phase:   api.StartupPhaseWarmingUp,
+		Message: "Container started, engine is loading the model",
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	ticker := time.NewTicker(startupCheckInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			log.Warn("Instance %s did not become ready within %s", inst.ID, timeout)
+			return startupError(rt, inst.ID, fmt.Sprintf("not ready within %s", timeout))
+		case <-ticker.C:
+		}
+
+		current, err := rt.Get(ctx, inst.ID)
+		if err != nil {
+			if ctx.Err() != nil {
+				continue
+			}
+			// The container state is unknown; leave it to the supervisor
+			log.Debug("Failed to check instance %s during startup: %v", inst.ID, err)
+			return nil
+		}
+
+		switch current.State {
+		case StateError, StateStopped, StateFailed:
+			reason := current.Error
+			if reason == "" {
+				reason = "container exited"
+			}
+			log.Warn("Instance %s exited during startup: %s", inst.ID, reason)
+			return startupError(rt, inst.ID, "exited during startup: "+reason)
+
+		case StateRunning:
+			if m.readiness.probe(ctx, current).ready {
+				sendStartupEvent(eventCh, api.StartupEvent{
+					Phase:   api.StartupPhaseReady,
+					Message: "Engine is ready to serve requests",
+				})
+				return nil
+			}
+		}
+	}
+}
+
+// startupError returns the error of an instance that failed to start,
+// with the tail of its logs.
+func startupError(rt Runtime, instanceID, reason string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	logs := captureLogTail(ctx, rt, instanceID, startupLogTailLines)
+	cancel()
+
+	if logs == "" {
+		return fmt.Errorf("instance %s", reason)
+	}
+	return fmt.Errorf("instance %s\n\nLast %d log lines:\n%s",
+		reason, startupLogTailLines, logs)
+}
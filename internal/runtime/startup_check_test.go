@@ -0,0 +1,145 @@
+package runtime
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/tsingmaoai/xw-cli/internal/api"
+)
+
+// startEngine starts a native instance running script and returns it.
+func startEngine(t *testing.T, script string, port int) (Runtime, *Instance) {
+	t.Helper()
+
+	rt, err := NewNativeRuntimeBase("vllm:native", t.TempDir(), shellCommand(script))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+	inst, err := rt.Create(ctx, &CreateParams{InstanceID: "engine-1", ModelID: "qwen3-8b", Port: port})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := rt.Start(ctx, inst.ID); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { rt.Remove(context.Background(), inst.ID) })
+	return rt, inst
+}
+
+// unusedPort returns a local port nothing listens on.
+func unusedPort(t *testing.T) int {
+	t.Helper()
+
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	port := l.Addr().(*net.TCPAddr).Port
+	l.Close()
+	return port
+}
+
+func TestWatchEarlyExit(t *testing.T) {
+	saved := startupCheckInterval
+	startupCheckInterval = 20 * time.Millisecond
+	defer func() { startupCheckInterval = saved }()
+
+	health := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer health.Close()
+	healthPort := health.Listener.Addr().(*net.TCPAddr).Port
+
+	tests := []struct {
+		name       string
+		script     string
+		port       int
+		timeout    time.Duration
+		wantErr    []string
+		wantPhases []api.StartupPhase
+	}{
+		{
+			name:       "ready",
+			script:     "sleep 60",
+			port:       healthPort,
+			timeout:    5 * time.Second,
+			wantPhases: []api.StartupPhase{api.StartupPhaseWarmingUp, api.StartupPhaseReady},
+		},
+		{
+			// The exit comes well after the first checks; the watch must
+			// last until readiness, not a fixed window
+			name:       "exits while loading",
+			script:     "echo 'RuntimeError: Execute fail'; sleep 0.5; exit 1",
+			port:       unusedPort(t),
+			timeout:    5 * time.Second,
+			wantErr:    []string{"exited during startup", "RuntimeError: Execute fail"},
+			wantPhases: []api.StartupPhase{api.StartupPhaseWarmingUp},
+		},
+		{
+			name:       "not ready in time",
+			script:     "echo 'loading weights'; sleep 60",
+			port:       unusedPort(t),
+			timeout:    300 * time.Millisecond,
+			wantErr:    []string{"not ready within 300ms", "loading weights"},
+			wantPhases: []api.StartupPhase{api.StartupPhaseWarmingUp},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rt, inst := startEngine(t, tt.script, tt.port)
+			m := &Manager{readiness: newReadinessTracker()}
+
+			eventCh := make(chan string, 10)
+
+			err := m.watchEarlyExit(rt, inst, tt.timeout, eventCh)
+			close(eventCh)
+			var phases []api.StartupPhase
+			for tagged := range eventCh {
+				var event api.StartupEvent
+				if data, ok := api.ParseTaggedStartupEvent(tagged); ok && json.Unmarshal([]byte(data), &event) == nil {
+					phases = append(phases, event.Phase)
+				}
+			}
+			if !reflect.DeepEqual(phases, tt.wantPhases) {
+				t.Errorf("phases = %v, want %v", phases, tt.wantPhases)
+			}
+
+			if len(tt.wantErr) == 0 {
+				if err != nil {
+					t.Fatalf("watchEarlyExit() failed: %v", err)
+				}
+				return
+			}
+			if err == nil {
+				t.Fatal("watchEarlyExit() succeeded, want error")
+			}
+			for _, want := range tt.wantErr {
+				if !strings.Contains(err.Error(), want) {
+					t.Errorf("error %q does not contain %q", err, want)
+				}
+			}
+		})
+	}
+}
+
+func TestStartupReadyTimeout(t *testing.T) {
+	tests := []struct {
+		config map[string]interface{}
+		want   time.Duration
+	}{
+		{nil, defaultStartupReadyTimeout},
+		{map[string]interface{}{"ready_timeout": float64(1200)}, 20 * time.Minute},
+		{map[string]interface{}{"ready_timeout": 0}, defaultStartupReadyTimeout},
+	}
+	for _, tt := range tests {
+		if got := startupReadyTimeout(tt.config); got != tt.want {
+			t.Errorf("startupReadyTimeout(%v) = %s, want %s", tt.config, got, tt.want)
+		}
+	}
+}
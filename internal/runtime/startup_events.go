@@ -0,0 +1,94 @@
+package runtime
+
+import (
+	"github.com/tsingmaoai/xw-cli/internal/api"
+)
+
+// sendStartupEvent sends a startup phase event to a progress event channel.
+// The send never blocks; events are dropped if the channel is full.
+//
+// The channel must stay open while the start runs: a send on a closed
+// channel panics. The start handler closes it only after the start returns.
+//
+// Parameters:
+//   - eventCh: Progress event channel (may be nil)
+//   - event: Startup phase event
+func sendStartupEvent(eventCh chan<- string, event api.StartupEvent) {
+	if eventCh == nil {
+		return
+	}
+	select {
+	case eventCh <- event.Tagged():
+	default:
+		// Channel full, skip
+	}
+}
+
+// layerProgress is the downloaded and total size of an image layer.
+type layerProgress struct {
+	current int64
+	total   int64
+}
+
+// pullProgress derives the overall percentage of an image pull from the
+// per-layer progress reported by the Docker daemon.
+//
+// Layer sizes only become known once a layer starts downloading, so the
+// percentage is an estimate until all layers have started; it never
+// decreases.
+type pullProgress struct {
+	layers  map[string]*layerProgress // Layer ID → progress
+	percent int                       // Last reported percentage
+}
+
+// newPullProgress creates an empty pull progress tracker.
+func newPullProgress() *pullProgress {
+	return &pullProgress{
+		layers: make(map[string]*layerProgress),
+	}
+}
+
+// update records a layer progress message.
+//
+// Parameters:
+//   - id: Layer ID
+//   - status: Layer status (e.g., "Downloading", "Download complete")
+//   - current: Bytes downloaded (only meaningful while downloading)
+//   - total: Layer size (only meaningful while downloading)
+//
+// Returns:
+//   - The overall percentage
+//   - true if the percentage increased
+func (p *pullProgress) update(id, status string, current, total int64) (int, bool) {
+	switch status {
+	case "Downloading":
+		if total <= 0 {
+			return p.percent, false
+		}
+		if current > total {
+			current = total
+		}
+		p.layers[id] = &layerProgress{current: current, total: total}
+	case "Verifying Checksum", "Download complete", "Extracting", "Pull complete":
+		if layer, ok := p.layers[id]; ok {
+			layer.current = layer.total
+		}
+	default:
+		return p.percent, false
+	}
+
+	var done, size int64
+	for _, layer := range p.layers {
+		done += layer.current
+		size += layer.total
+	}
+	if size <= 0 {
+		return p.percent, false
+	}
+	percent := int(done * 100 / size)
+	if percent <= p.percent {
+		return p.percent, false
+	}
+	p.percent = percent
+	return percent, true
+}
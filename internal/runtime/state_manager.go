@@ -7,8 +7,6 @@ import (
 	"github.com/docker/docker/api/types"
 	"github.com/docker/docker/api/types/container"
 	"github.com/docker/docker/client"
-	
-	"github.com/tsingmaoai/xw-cli/internal/logger"
 )
 
 // ContainerStateInfo holds the result of container state inspection.
@@ -132,7 +130,7 @@ func UpdateInstanceStateFromContainer(ctx context.Context, dockerClient *client.
 	// Get current container state
 	stateInfo, err := InspectContainerState(ctx, dockerClient, containerID)
 	if err != nil {
-		logger.Warn("Failed to inspect container %s (instance %s): %v", 
+		log.Warn("Failed to inspect container %s (instance %s): %v", 
 			containerID[:min(len(containerID), 12)], instance.ID, err)
 		return false
 	}
@@ -143,7 +141,7 @@ func UpdateInstanceStateFromContainer(ctx context.Context, dockerClient *client.
 		instance.State = stateInfo.State
 		instance.Error = stateInfo.ErrorMessage
 		
-		logger.Warn("Container %s (instance %s) changed from %s to %s: %s",
+		log.Warn("Container %s (instance %s) changed from %s to %s: %s",
 			containerID[:min(len(containerID), 12)], instance.ID, 
 			oldState, stateInfo.State, stateInfo.ErrorMessage)
 		
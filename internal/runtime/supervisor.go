@@ -0,0 +1,170 @@
+package runtime
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/docker/docker/pkg/stdcopy"
+)
+
+const (
+	// restartWindow is the sliding window in which the restart budget applies.
+	// An instance may be restarted at most restart_max times within it.
+	restartWindow = 10 * time.Minute
+
+	// restartLogTailLines is the number of log lines kept from a crashed instance.
+	restartLogTailLines = 20
+)
+
+// restartRecord tracks supervised restarts of a single instance.
+type restartRecord struct {
+	restarts   []time.Time // Restart timestamps within restartWindow
+	total      int         // Total restarts since the server started
+	failed     bool        // Budget exhausted; no further restarts
+	exitReason string      // Exit reason of the last crash
+	lastLogs   string      // Tail of the logs captured at the last crash
+}
+
+// restartSupervisor restarts crashed instances within a restart budget.
+//
+// Instances opt in with a positive "restart_max" metadata value (set via
+// `xw start --restart-max N`). When such an instance's container exits
+// unexpectedly it is restarted, up to restart_max times within
+// restartWindow. Once the budget is exhausted the instance is reported as
+// StateFailed and left alone so operators can inspect it.
+type restartSupervisor struct {
+	mu      sync.Mutex
+	records map[string]*restartRecord // instanceID → record
+}
+
+// newRestartSupervisor creates an empty restart supervisor.
+func newRestartSupervisor() *restartSupervisor {
+	return &restartSupervisor{
+		records: make(map[string]*restartRecord),
+	}
+}
+
+// superviseRestarts restarts crashed instances that still have restart budget.
+// It is called periodically from the maintenance loop.
+func (m *Manager) superviseRestarts(ctx context.Context) {
+	for _, inst := range m.listAll(ctx) {
+		if inst.State != StateError || m.overrides.startFailed(inst.ID) {
+			continue
+		}
+
+		maxRestarts, err := strconv.Atoi(inst.Metadata["restart_max"])
+		if err != nil || maxRestarts <= 0 {
+			continue
+		}
+
+		m.supervisor.mu.Lock()
+		record, ok := m.supervisor.records[inst.ID]
+		if !ok {
+			record = &restartRecord{}
+			m.supervisor.records[inst.ID] = record
+		}
+		failed := record.failed
+		m.supervisor.mu.Unlock()
+
+		if failed {
+			continue
+		}
+
+		rt, _, err := m.findInstanceRuntime(ctx, inst.ID)
+		if err != nil {
+			log.Warn("Cannot supervise instance %s: %v", inst.ID, err)
+			continue
+		}
+
+		lastLogs := captureLogTail(ctx, rt, inst.ID, restartLogTailLines)
+
+		m.supervisor.mu.Lock()
+		record.exitReason = inst.Error
+		record.lastLogs = lastLogs
+		cutoff := time.Now().Add(-restartWindow)
+		recent := record.restarts[:0]
+		for _, t := range record.restarts {
+			if t.After(cutoff) {
+				recent = append(recent, t)
+			}
+		}
+		record.restarts = recent
+		if len(record.restarts) >= maxRestarts {
+			record.failed = true
+			m.supervisor.mu.Unlock()
+			log.Error("Instance %s crashed %d times within %v, giving up: %s",
+				inst.ID, len(recent), restartWindow, inst.Error)
+			continue
+		}
+		record.restarts = append(record.restarts, time.Now())
+		record.total++
+		attempt := len(record.restarts)
+		m.supervisor.mu.Unlock()
+
+		log.Warn("Instance %s exited unexpectedly (%s), restarting (%d/%d)",
+			inst.ID, inst.Error, attempt, maxRestarts)
+
+		m.readiness.reset(inst.ID)
+		if err := rt.Start(ctx, inst.ID); err != nil {
+			log.Error("Failed to restart instance %s: %v", inst.ID, err)
+		}
+		m.notifyInstancesChanged()
+	}
+}
+
+// apply overlays supervision results onto listed instances: restart counts,
+// the last exit reason and log tail, and StateFailed once the budget is spent.
+// Records for instances that no longer exist are dropped.
+func (s *restartSupervisor) apply(instances []*Instance) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	seen := make(map[string]bool, len(instances))
+	for _, inst := range instances {
+		seen[inst.ID] = true
+		record, ok := s.records[inst.ID]
+		if !ok {
+			continue
+		}
+
+		if inst.Metadata == nil {
+			inst.Metadata = make(map[string]string)
+		}
+		inst.Metadata["restart_count"] = strconv.Itoa(record.total)
+		if record.lastLogs != "" {
+			inst.Metadata["last_logs"] = record.lastLogs
+		}
+		if record.failed && inst.State == StateError {
+			inst.State = StateFailed
+			inst.Error = fmt.Sprintf("restart budget exhausted: %s", record.exitReason)
+		}
+	}
+
+	for id := range s.records {
+		if !seen[id] {
+			delete(s.records, id)
+		}
+	}
+}
+
+// captureLogTail returns the last lines of an instance's logs, or an empty
+// string if they cannot be read.
+func captureLogTail(ctx context.Context, rt Runtime, instanceID string, lines int) string {
+	stream, err := rt.Logs(ctx, instanceID, LogOptions{Tail: lines})
+	if err != nil {
+		log.Debug("Failed to read logs for instance %s: %v", instanceID, err)
+		return ""
+	}
+	defer stream.Close()
+
+	var buf bytes.Buffer
+	if _, err := stdcopy.StdCopy(&buf, &buf, stream); err != nil {
+		log.Debug("Failed to demultiplex logs for instance %s: %v", instanceID, err)
+	}
+	return strings.TrimSpace(buf.String())
+}
@@ -19,10 +19,16 @@ type Runtime interface {
 	Remove(ctx context.Context, instanceID string) error
 	Get(ctx context.Context, instanceID string) (*Instance, error)
 	List(ctx context.Context) ([]*Instance, error)
-	Logs(ctx context.Context, instanceID string, follow bool) (LogStream, error)
+	Logs(ctx context.Context, instanceID string, opts LogOptions) (LogStream, error)
 	Name() string
 }
 
+// LogOptions controls which instance logs are returned.
+type LogOptions struct {
+	Follow bool // Stream new logs in real-time after the existing ones
+	Tail   int  // Number of most recent lines to return (0 for all)
+}
+
 // CreateParams contains standard parameters for creating an instance.
 type CreateParams struct {
 	InstanceID       string
@@ -34,10 +40,16 @@ type CreateParams struct {
 	DeploymentMode   string // Deployment mode (e.g., "docker")
 	ServerName       string // Server unique identifier (added as container name suffix)
 	DataDir          string // Data directory for runtime files (e.g., converted models)
+	LogDir           string // Host directory for the engine's log files (see InstanceLogMounts)
 	Devices          []DeviceInfo
 	Port             int
 	Environment      map[string]string
 	ExtraConfig      map[string]interface{}
+	Arch             string // Runtime image architecture ("arm64"/"amd64"); empty for the host architecture
+	ImageArchive     string // Image archive to load if the image is missing (from --image-archive)
+	ImageArchiveDir  string // Directory searched for image archives before pulling
+	RegistryMirror   string // Registry mirror specification applied to image pulls
+	RunSpec          string // Encoded start request, recorded for `xw restart` (see RunSpec)
 	
 	// Template parameters from runtime_params.yaml
 	// Format: ["key=value", "tensor_parallel=4"]
@@ -51,6 +63,10 @@ type CreateParams struct {
 	
 	// EventChannel for sending progress messages to client (optional, for SSE streams)
 	EventChannel     chan<- string
+	
+	// DryRun resolves the full container configuration without pulling the
+	// image or creating the container. Create returns a *DryRunResult error.
+	DryRun           bool
 }
 
 // DeviceInfo contains information about a hardware device.
@@ -92,11 +108,13 @@ const (
 	StateCreated   InstanceState = "created"
 	StateStarting  InstanceState = "starting"
 	StateRunning   InstanceState = "running"
+	StateDraining  InstanceState = "draining"  // Running, finishing in-flight requests before stop
 	StateReady     InstanceState = "ready"     // Running and endpoint is accessible
 	StateUnhealthy InstanceState = "unhealthy" // Running but endpoint is not accessible
 	StateStopping  InstanceState = "stopping"
 	StateStopped   InstanceState = "stopped"
 	StateError     InstanceState = "error"
+	StateFailed    InstanceState = "failed"    // Crashed and restart budget exhausted
 	StateUnknown   InstanceState = "unknown"   // Unable to determine real state
 )
 
@@ -116,6 +134,7 @@ type RunOptions struct {
 	Port             int
 	Interactive      bool
 	AdditionalConfig map[string]interface{}
+	Environment      map[string]string // Extra container environment variables (e.g., from --env)
 	EventChannel     chan<- string // Optional: for sending progress events via SSE
 }
 
@@ -130,8 +149,16 @@ type RunInstance struct {
 	CreatedAt      time.Time              `json:"created_at"`
 	StartedAt      time.Time              `json:"started_at,omitempty"`
 	Port           int                    `json:"port"`
+	Endpoint       string                 `json:"endpoint,omitempty"`       // Inference endpoint URL on the host
+	DeviceIndices  []int                  `json:"device_indices,omitempty"` // Allocated device indices
 	ContainerID    string                 `json:"container_id,omitempty"` // Docker container ID
 	Error          string                 `json:"error,omitempty"`
+	HealthError    string                 `json:"health_error,omitempty"` // Last readiness probe failure while starting
+	RestartCount   int                    `json:"restart_count,omitempty"` // Supervised restarts after crashes
+	LastLogs       string                 `json:"last_logs,omitempty"`     // Log tail captured at the last crash
+	LogDir         string                 `json:"log_dir,omitempty"`       // Host directory with the engine's log files
+	DryRunCommand  string                 `json:"dry_run_command,omitempty"` // Equivalent docker run command (dry run only)
+	RunSpec        *RunSpec               `json:"-"`                         // Start request the instance was created from; not listed since its environment may hold secrets
 	Config         map[string]interface{} `json:"config,omitempty"`
 }
 
@@ -126,12 +126,12 @@
 //	}
 //
 //	// GetDefaultImage returns Docker image for this device type.
-//	func (s *CustomSandbox) GetDefaultImage(devices []runtime.DeviceInfo) (string, error) {
+//	func (s *CustomSandbox) GetDefaultImage(devices []runtime.DeviceInfo, arch string) (string, error) {
 //	    runtimeImages, err := config.LoadRuntimeImagesConfig()
 //	    if err != nil {
 //	        return "", fmt.Errorf("failed to load runtime images: %w", err)
 //	    }
-//	    return runtime.GetImageForEngine(runtimeImages, devices, "vllm")
+//	    return runtime.GetImageForEngine(runtimeImages, devices, "vllm", arch)
 //	}
 //
 //	// GetDockerRuntime returns Docker runtime to use.
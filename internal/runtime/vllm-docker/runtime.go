@@ -25,6 +25,9 @@ import (
 	"github.com/tsingmaoai/xw-cli/internal/runtime"
 )
 
+// log is the runtime component logger (XW_LOG=runtime=LEVEL).
+var log = logger.Named("runtime")
+
 // Runtime implements the runtime.Runtime interface for vLLM with Docker.
 //
 // This runtime manages vLLM model instances running in Docker containers.
@@ -81,10 +84,10 @@ func NewRuntime() (*Runtime, error) {
 	defer cancel()
 
 	if err := rt.LoadExistingContainers(ctx); err != nil {
-		logger.Warn("Failed to load existing vLLM containers: %v", err)
+		log.Warn("Failed to load existing vLLM containers: %v", err)
 	}
 	
-	logger.Info("vLLM Docker runtime initialized successfully (config-driven mode)")
+	log.Info("vLLM Docker runtime initialized successfully (config-driven mode)")
 	
 	return rt, nil
 }
@@ -140,7 +143,7 @@ func (r *Runtime) Create(ctx context.Context, params *runtime.CreateParams) (*ru
 		return nil, fmt.Errorf("invalid parameters: instance ID is required")
 	}
 	
-	logger.Info("Creating vLLM Docker instance: %s for model: %s", 
+	log.Info("Creating vLLM Docker instance: %s for model: %s", 
 		params.InstanceID, params.ModelID)
 	
 	// Check for duplicate instance ID
@@ -192,11 +195,19 @@ func (r *Runtime) Create(ctx context.Context, params *runtime.CreateParams) (*ru
 	// These control tensor parallelism across multiple devices
 	if params.TensorParallel > 0 {
 		env["TENSOR_PARALLEL"] = fmt.Sprintf("%d", params.TensorParallel)
-		logger.Debug("Set TENSOR_PARALLEL=%d", params.TensorParallel)
+		log.Debug("Set TENSOR_PARALLEL=%d", params.TensorParallel)
 	}
 	if params.WorldSize > 0 {
 		env["WORLD_SIZE"] = fmt.Sprintf("%d", params.WorldSize)
-		logger.Debug("Set WORLD_SIZE=%d", params.WorldSize)
+		log.Debug("Set WORLD_SIZE=%d", params.WorldSize)
+	}
+	
+	// Engine limits from --max-model-len / --gpu-memory-utilization (optional)
+	if maxLen, ok := runtime.ConfigInt(params.ExtraConfig, "max_model_len"); ok && maxLen > 0 {
+		env["MAX_MODEL_LEN"] = fmt.Sprintf("%d", maxLen)
+	}
+	if util, ok := runtime.ConfigFloat(params.ExtraConfig, "gpu_memory_utilization"); ok && util > 0 {
+		env["GPU_MEMORY_UTILIZATION"] = fmt.Sprintf("%g", util)
 	}
 	
 	// MODEL_PATH: Container-internal path where model files are mounted
@@ -209,7 +220,10 @@ func (r *Runtime) Create(ctx context.Context, params *runtime.CreateParams) (*ru
 		modelName = params.ModelID
 	}
 	env["MODEL_NAME"] = modelName
-	logger.Debug("Set MODEL_NAME=%s", modelName)
+	log.Debug("Set MODEL_NAME=%s", modelName)
+	
+	// Engine log files go to the instance's host log directory
+	runtime.InstanceLogEnv(params, env)
 	
 	// Convert environment map to Docker format (KEY=VALUE strings)
 	envList := make([]string, 0, len(env))
@@ -239,18 +253,18 @@ func (r *Runtime) Create(ctx context.Context, params *runtime.CreateParams) (*ru
 	if img, ok := params.ExtraConfig["image"]; ok {
 		if imgStr, ok := img.(string); ok {
 			imageName = imgStr
-			logger.Info("Using custom Docker image: %s", imageName)
+			log.Info("Using custom Docker image: %s", imageName)
 		}
 	}
 	
 	if imageName == "" {
 		// Get image from configuration
 		var err error
-		imageName, err = sandbox.GetDefaultImage(params.Devices)
+		imageName, err = sandbox.GetDefaultImage(params.Devices, params.Arch)
 		if err != nil {
 			return nil, fmt.Errorf("failed to get Docker image: %w", err)
 		}
-		logger.Info("Using configured Docker image: %s", imageName)
+		log.Info("Using configured Docker image: %s", imageName)
 	}
 	
 	// Ensure Docker image is available (check and pull if needed)
@@ -319,12 +333,12 @@ func (r *Runtime) Create(ctx context.Context, params *runtime.CreateParams) (*ru
 		})
 	}
 	
+	// Instance log directory, kept on the host after the container is removed
+	mounts = append(mounts, runtime.InstanceLogMounts(params)...)
+	
 	// Get shared memory size for inference workloads
 	// vLLM requires adequate shared memory for DataLoader workers and KV cache management
-	var shmSize int64 = 16 * 1024 * 1024 * 1024 // Default 16GB
-	if shmProvider, ok := sandbox.(interface{ GetSharedMemorySize() int64 }); ok {
-		shmSize = shmProvider.GetSharedMemorySize()
-	}
+	shmSize := runtime.ResolveShmSize(params, sandbox, 16*1024*1024*1024) // Default 16GB
 
 	// Build host configuration with device-specific settings
 	hostConfig := &container.HostConfig{
@@ -393,7 +407,7 @@ func (r *Runtime) Create(ctx context.Context, params *runtime.CreateParams) (*ru
 	instances[params.InstanceID] = instance
 	mu.Unlock()
 	
-	logger.Info("vLLM Docker instance created successfully: %s (container: %s)", 
+	log.Info("vLLM Docker instance created successfully: %s (container: %s)", 
 		params.InstanceID, resp.ID[:12])
 	
 	return instance, nil
@@ -0,0 +1,128 @@
+// Package vllmnative implements vLLM runtime with native deployment.
+//
+// This package runs vLLM as a process on the host instead of in a Docker
+// container, for machines where Docker is not available or not allowed. It
+// requires vLLM (with the plugin for the host's accelerator, e.g.
+// vllm-ascend) to be installed and the `vllm` command to be in the server's
+// PATH.
+//
+// Device selection uses the same sandbox configuration as the Docker
+// runtime (configs/devices.yaml), so the engine sees only its allocated
+// devices through the chip's visibility variable (e.g.
+// ASCEND_RT_VISIBLE_DEVICES). Process lifecycle, logs and state are handled
+// by the embedded NativeRuntimeBase.
+package vllmnative
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+	"strings"
+
+	"github.com/tsingmaoai/xw-cli/internal/logger"
+	"github.com/tsingmaoai/xw-cli/internal/runtime"
+)
+
+// log is the runtime component logger (XW_LOG=runtime=LEVEL).
+var log = logger.Named("runtime")
+
+// Runtime implements the runtime.Runtime interface for vLLM running
+// natively on the host.
+//
+// Thread Safety:
+//
+//	All public methods are thread-safe via inherited mutex protection.
+type Runtime struct {
+	*runtime.NativeRuntimeBase // Embedded base provides process management
+}
+
+// NewRuntime creates a new vLLM native runtime instance.
+//
+// Instances of previous server runs are loaded from stateDir; those whose
+// engine is still running are adopted.
+//
+// Parameters:
+//   - stateDir: Directory for instance state and engine output
+//
+// Returns:
+//   - Configured runtime instance ready for use
+//   - Error if vLLM is not installed or the state directory is unusable
+func NewRuntime(stateDir string) (*Runtime, error) {
+	if _, err := exec.LookPath("vllm"); err != nil {
+		return nil, fmt.Errorf("vllm command not found in PATH: %w", err)
+	}
+
+	base, err := runtime.NewNativeRuntimeBase("vllm:native", stateDir, buildCommand)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize native base: %w", err)
+	}
+
+	log.Info("vLLM native runtime initialized successfully")
+	return &Runtime{NativeRuntimeBase: base}, nil
+}
+
+// Name returns the unique identifier for this runtime.
+//
+// Returns:
+//   - "vllm:native" to distinguish from other implementations
+func (r *Runtime) Name() string {
+	return "vllm:native"
+}
+
+// Version returns the version of the installed vLLM.
+//
+// Parameters:
+//   - ctx: Context bounding the `vllm --version` call
+//
+// Returns:
+//   - Version string (e.g., "vLLM 0.11.0")
+//   - Error if vLLM cannot report its version
+func (r *Runtime) Version(ctx context.Context) (string, error) {
+	out, err := exec.CommandContext(ctx, "vllm", "--version").Output()
+	if err != nil {
+		return "", fmt.Errorf("vllm --version: %w", err)
+	}
+	// Plugins may log before the version; it is the last line
+	lines := strings.Split(strings.TrimSpace(string(out)), "\n")
+	return "vLLM " + strings.TrimSpace(lines[len(lines)-1]), nil
+}
+
+// buildCommand builds the `vllm serve` command line of an instance.
+//
+// The engine serves on the instance port on localhost only, like the port
+// binding of Docker instances. Parallelism and engine limits are passed as
+// flags from the variables prepared by the base, which the Docker images'
+// entrypoints translate the same way.
+//
+// Parameters:
+//   - params: Instance creation parameters
+//   - env: Engine environment prepared by NativeRuntimeBase
+//
+// Returns:
+//   - Command line
+//   - Error if no model path or port is set
+func buildCommand(params *runtime.CreateParams, env map[string]string) ([]string, error) {
+	if params.ModelPath == "" {
+		return nil, fmt.Errorf("model path is required")
+	}
+	if params.Port <= 0 {
+		return nil, fmt.Errorf("port is required")
+	}
+
+	command := []string{
+		"vllm", "serve", env["MODEL_PATH"],
+		"--served-model-name", env["MODEL_NAME"],
+		"--host", "127.0.0.1",
+		"--port", fmt.Sprintf("%d", params.Port),
+	}
+	if tp := env["TENSOR_PARALLEL"]; tp != "" {
+		command = append(command, "--tensor-parallel-size", tp)
+	}
+	if maxLen := env["MAX_MODEL_LEN"]; maxLen != "" {
+		command = append(command, "--max-model-len", maxLen)
+	}
+	if util := env["GPU_MEMORY_UTILIZATION"]; util != "" {
+		command = append(command, "--gpu-memory-utilization", util)
+	}
+	return command, nil
+}
@@ -0,0 +1,94 @@
+package server
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/tsingmaoai/xw-cli/internal/apiformat"
+)
+
+// unauthenticatedPaths are reachable without an API key so that load
+// balancers and monitoring can probe the server.
+var unauthenticatedPaths = map[string]bool{
+	"/api/health": true,
+	"/v1/health":  true,
+}
+
+// authMiddleware requires the configured API key on every request except
+// health checks. Without a configured key (see config.GetAPIKey) requests
+// pass through unchanged.
+//
+// The key is accepted as "Authorization: Bearer <key>" (OpenAI clients and
+// the xw CLI) or as an "x-api-key" header (Anthropic clients). Rejected
+// requests get a 401 in the error shape their API expects.
+//
+// Parameters:
+//   - next: The handler to protect
+//
+// Returns:
+//   - An http.Handler enforcing the API key
+func (s *Server) authMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		key := s.config.GetAPIKey()
+		if key == "" || unauthenticatedPaths[r.URL.Path] {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		token := r.Header.Get("x-api-key")
+		if auth := r.Header.Get("Authorization"); auth != "" {
+			if bearer, ok := strings.CutPrefix(auth, "Bearer "); ok {
+				token = strings.TrimSpace(bearer)
+			}
+		}
+
+		if token != "" && subtle.ConstantTimeCompare([]byte(token), []byte(key)) == 1 {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		message := "Invalid API key"
+		if token == "" {
+			message = "Missing API key: send \"Authorization: Bearer <key>\""
+		}
+		writeUnauthorized(w, r, message)
+	})
+}
+
+// writeUnauthorized writes a 401 response in the error format of the API
+// the request belongs to: Anthropic for /v1/messages and for requests with
+// an "anthropic-version" header, OpenAI for other /v1/* paths, and the xw
+// API error shape otherwise.
+func writeUnauthorized(w http.ResponseWriter, r *http.Request, message string) {
+	var body interface{}
+	switch {
+	case strings.HasPrefix(r.URL.Path, "/v1/messages") || r.Header.Get("anthropic-version") != "":
+		body = apiformat.AnthropicError{
+			Type: "error",
+			Error: apiformat.AnthropicErrorBody{
+				Type:    "authentication_error",
+				Message: message,
+			},
+		}
+	case strings.HasPrefix(r.URL.Path, "/v1/"):
+		body = map[string]interface{}{
+			"error": map[string]interface{}{
+				"message": message,
+				"type":    "invalid_request_error",
+				"code":    "invalid_api_key",
+			},
+		}
+	default:
+		body = map[string]string{
+			"error": message,
+			"code":  "401",
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("WWW-Authenticate", "Bearer")
+	w.WriteHeader(http.StatusUnauthorized)
+	json.NewEncoder(w).Encode(body)
+}
@@ -2,6 +2,7 @@ package server
 
 import (
 	"fmt"
+	"path/filepath"
 	
 	"github.com/tsingmaoai/xw-cli/internal/config"
 	"github.com/tsingmaoai/xw-cli/internal/logger"
@@ -11,6 +12,7 @@ import (
 	mlguiderdocker "github.com/tsingmaoai/xw-cli/internal/runtime/mlguider-docker"
 	omniinferdocker "github.com/tsingmaoai/xw-cli/internal/runtime/omni-infer-docker"
 	vllmdocker "github.com/tsingmaoai/xw-cli/internal/runtime/vllm-docker"
+	vllmnative "github.com/tsingmaoai/xw-cli/internal/runtime/vllm-native"
 )
 
 // InitializeModels loads and registers models from configuration.
@@ -109,7 +111,17 @@ func InitializeRuntimeManager(cfg *config.Config) (*runtime.Manager, error) {
 		}
 	}
 	
-	// TODO: Register additional runtimes as implemented (e.g., native deployments)
+	// Register vLLM native runtime (engine runs on the host, without Docker)
+	if rt, err := vllmnative.NewRuntime(filepath.Join(cfg.Storage.DataDir, "native")); err != nil {
+		logger.Debug("vLLM native runtime unavailable: %v", err)
+	} else {
+		if err := mgr.RegisterRuntime(rt); err != nil {
+			logger.Warn("Failed to register vLLM native runtime: %v", err)
+		} else {
+			registeredCount++
+			logger.Info("Registered runtime: %s", rt.Name())
+		}
+	}
 	
 	if registeredCount == 0 {
 		logger.Warn("No runtimes available - model execution will not be possible")
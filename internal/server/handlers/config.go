@@ -5,8 +5,9 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"strconv"
 
-	"github.com/tsingmaoai/xw-cli/internal/logger"
+	"github.com/tsingmaoai/xw-cli/internal/config"
 )
 
 // ConfigInfoResponse represents the response structure for the config info endpoint.
@@ -20,6 +21,9 @@ type ConfigInfoResponse struct {
 	// Registry is the URL to the configuration package registry.
 	Registry string `json:"registry"`
 
+	// RegistryMirror is the effective Docker registry mirror (empty if none).
+	RegistryMirror string `json:"registry_mirror,omitempty"`
+
 	// ConfigVersion is the currently active configuration version.
 	ConfigVersion string `json:"config_version"`
 
@@ -102,19 +106,20 @@ func (h *Handler) ConfigInfo(w http.ResponseWriter, r *http.Request) {
 	// Get current config version
 	identity, err := h.config.GetOrCreateServerIdentity()
 	if err != nil {
-		logger.Error("Failed to get server identity: %v", err)
+		log.Error("Failed to get server identity: %v", err)
 		h.WriteError(w, "failed to get server identity", http.StatusInternalServerError)
 		return
 	}
 
 	response := ConfigInfoResponse{
-		Name:          h.config.Server.Name,
-		Registry:      h.config.Server.Registry,
-		ConfigVersion: identity.ConfigVersion,
-		Host:          h.config.Server.Host,
-		Port:          h.config.Server.Port,
-		ConfigDir:     h.config.Storage.ConfigDir,
-		DataDir:       h.config.Storage.DataDir,
+		Name:           h.config.Server.Name,
+		Registry:       h.config.Server.Registry,
+		RegistryMirror: h.config.GetRegistryMirror(),
+		ConfigVersion:  identity.ConfigVersion,
+		Host:           h.config.Server.Host,
+		Port:           h.config.Server.Port,
+		ConfigDir:      h.config.Storage.ConfigDir,
+		DataDir:        h.config.Storage.DataDir,
 	}
 
 	h.WriteJSON(w, response, http.StatusOK)
@@ -128,6 +133,10 @@ func (h *Handler) ConfigInfo(w http.ResponseWriter, r *http.Request) {
 // Currently supported configuration keys:
 //   - "name": Server instance identifier
 //   - "registry": Configuration package registry URL
+//   - "registry_mirror": Docker registry mirror for image pulls ("none" to clear)
+//   - "api_key": API key clients must present ("none" to disable authentication)
+//   - "host", "port": Listen address and port, recorded for the next server
+//     start; the running server keeps its current address
 //
 // HTTP Method: POST
 // Path: /api/config/set
@@ -186,8 +195,57 @@ func (h *Handler) ConfigSet(w http.ResponseWriter, r *http.Request) {
 		return
 
 	case "registry":
+		if err := config.ValidateRegistryURL(req.Value); err != nil {
+			h.WriteError(w, err.Error(), http.StatusBadRequest)
+			return
+		}
 		h.config.Server.Registry = req.Value
-		logger.Info("Registry URL updated to: %s", req.Value)
+		log.Info("Registry URL updated to: %s", req.Value)
+
+	case "registry_mirror":
+		mirror := req.Value
+		if mirror == "none" {
+			mirror = ""
+		}
+		if err := config.ValidateRegistryMirror(mirror); err != nil {
+			h.WriteError(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		h.config.Server.RegistryMirror = mirror
+		log.Info("Registry mirror updated to: %q", mirror)
+
+	case "api_key":
+		key := req.Value
+		if key == "none" {
+			key = ""
+		}
+		h.config.SetAPIKey(key)
+		if key == "" {
+			log.Info("API key authentication disabled")
+		} else {
+			log.Info("API key updated")
+		}
+
+	case "host", "port":
+		// Recorded for the next start only: rebinding the listener would cut
+		// off clients of the running server
+		var err error
+		if req.Key == "host" {
+			err = h.config.SaveServerAddress(req.Value, 0)
+		} else if port, convErr := strconv.Atoi(req.Value); convErr != nil {
+			err = fmt.Errorf("invalid port %q: must be a number", req.Value)
+		} else {
+			err = h.config.SaveServerAddress("", port)
+		}
+		if err != nil {
+			h.WriteError(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		log.Info("Server %s set to %s (applied on restart)", req.Key, req.Value)
+		h.WriteJSON(w, map[string]string{
+			"message": "Configuration saved; restart the server to apply it",
+		}, http.StatusOK)
+		return
 
 	default:
 		h.WriteError(w, fmt.Sprintf("unsupported configuration key: %s", req.Key), http.StatusBadRequest)
@@ -196,7 +254,7 @@ func (h *Handler) ConfigSet(w http.ResponseWriter, r *http.Request) {
 
 	// Persist configuration to disk
 	if err := h.config.SaveServerConfig(); err != nil {
-		logger.Error("Failed to save server configuration: %v", err)
+		log.Error("Failed to save server configuration: %v", err)
 		h.WriteError(w, fmt.Sprintf("failed to save configuration: %v", err), http.StatusInternalServerError)
 		return
 	}
@@ -214,6 +272,8 @@ func (h *Handler) ConfigSet(w http.ResponseWriter, r *http.Request) {
 // Currently supported configuration keys:
 //   - "name": Server instance identifier
 //   - "registry": Configuration package registry URL
+//   - "registry_mirror": Effective Docker registry mirror (XW_REGISTRY_MIRROR or server.conf)
+//   - "api_key": Effective API key, masked (XW_API_KEY or server.conf)
 //   - "host": Server host address
 //   - "port": Server port number
 //   - "config_dir": Configuration directory path
@@ -272,6 +332,12 @@ func (h *Handler) ConfigGet(w http.ResponseWriter, r *http.Request) {
 	case "registry":
 		value = h.config.Server.Registry
 
+	case "registry_mirror":
+		value = h.config.GetRegistryMirror()
+
+	case "api_key":
+		value = config.MaskAPIKey(h.config.GetAPIKey())
+
 	case "host":
 		value = h.config.Server.Host
 
@@ -328,20 +394,20 @@ func (h *Handler) ConfigReload(w http.ResponseWriter, r *http.Request) {
 	// Get current config version
 	identity, err := h.config.GetOrCreateServerIdentity()
 	if err != nil {
-		logger.Error("Failed to get server identity: %v", err)
+		log.Error("Failed to get server identity: %v", err)
 		h.WriteError(w, "failed to get server identity", http.StatusInternalServerError)
 		return
 	}
 
 	// Reload all versioned configs (clears all caches and reloads)
-	logger.Info("Reloading configurations for version: %s", identity.ConfigVersion)
+	log.Info("Reloading configurations for version: %s", identity.ConfigVersion)
 	if err := h.config.ReloadVersionedConfigs(identity.ConfigVersion, h.loadModelsFunc); err != nil {
-		logger.Error("Failed to reload configurations: %v", err)
+		log.Error("Failed to reload configurations: %v", err)
 		h.WriteError(w, fmt.Sprintf("failed to reload configurations: %v", err), http.StatusInternalServerError)
 		return
 	}
 
-	logger.Info("Configuration reloaded successfully")
+	log.Info("Configuration reloaded successfully")
 
 	h.WriteJSON(w, map[string]string{
 		"message":        "Configuration reloaded successfully",
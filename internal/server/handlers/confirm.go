@@ -0,0 +1,105 @@
+package handlers
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/tsingmaoai/xw-cli/internal/api"
+	"github.com/tsingmaoai/xw-cli/internal/hooks"
+)
+
+// installConfirmTimeout bounds the wait for the answer to an install
+// confirmation. An unanswered confirmation fails the start, so that a
+// client that stopped reading does not hold the start forever.
+var installConfirmTimeout = 5 * time.Minute
+
+// streamPrompter returns a prompter that asks the client of a start stream
+// whether a dependency may be installed.
+//
+// The question is sent on eventCh as an api.InstallConfirmation and the
+// prompter waits until the answer is posted to ConfirmInstall, the start
+// is cancelled, or installConfirmTimeout passes.
+//
+// Parameters:
+//   - eventCh: Event channel of the start stream
+//
+// Returns:
+//   - Prompter for the start's hook runner
+func (h *Handler) streamPrompter(eventCh chan<- string) hooks.Prompter {
+	return hooks.PromptFunc(func(ctx context.Context, hook hooks.Hook) (bool, error) {
+		id := newConfirmationID()
+		answerCh := make(chan bool, 1)
+		h.confirmations.Store(id, answerCh)
+		defer h.confirmations.Delete(id)
+
+		eventCh <- api.InstallConfirmation{
+			ID:      id,
+			Name:    hook.Name(),
+			Message: hook.Message(),
+		}.Tagged()
+
+		timer := time.NewTimer(installConfirmTimeout)
+		defer timer.Stop()
+
+		select {
+		case confirmed := <-answerCh:
+			return confirmed, nil
+		case <-ctx.Done():
+			return false, ctx.Err()
+		case <-timer.C:
+			return false, fmt.Errorf("no answer within %v", installConfirmTimeout)
+		}
+	})
+}
+
+// newConfirmationID returns a random ID for an install confirmation.
+func newConfirmationID() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+	return fmt.Sprintf("%x", b)
+}
+
+// ConfirmInstall handles the answer of a start stream client to an install
+// confirmation (see StartModel).
+//
+// HTTP Method: POST
+// Path: /api/runtime/confirm
+// Content-Type: application/json
+func (h *Handler) ConfirmInstall(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		h.WriteError(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var reqBody api.InstallAnswer
+	if err := json.NewDecoder(r.Body).Decode(&reqBody); err != nil {
+		h.WriteError(w, fmt.Sprintf("Invalid request body: %v", err), http.StatusBadRequest)
+		return
+	}
+	if reqBody.ID == "" {
+		h.WriteError(w, "id is required", http.StatusBadRequest)
+		return
+	}
+
+	value, ok := h.confirmations.Load(reqBody.ID)
+	if !ok {
+		h.WriteError(w, fmt.Sprintf("No pending confirmation %s", reqBody.ID), http.StatusNotFound)
+		return
+	}
+
+	// Only the first answer counts
+	select {
+	case value.(chan bool) <- reqBody.Confirmed:
+	default:
+		h.WriteError(w, fmt.Sprintf("Confirmation %s is already answered", reqBody.ID), http.StatusConflict)
+		return
+	}
+
+	h.WriteJSON(w, map[string]interface{}{
+		"message": "Answer received",
+	}, http.StatusOK)
+}
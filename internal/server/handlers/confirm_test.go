@@ -0,0 +1,120 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/tsingmaoai/xw-cli/internal/api"
+)
+
+// confirmHook is a hook the stream prompter asks about.
+type confirmHook struct{}
+
+func (confirmHook) Name() string                      { return "docker" }
+func (confirmHook) Message() string                   { return "Docker is required." }
+func (confirmHook) Check(ctx context.Context) error   { return nil }
+func (confirmHook) Install(ctx context.Context) error { return nil }
+func (confirmHook) Interactive() bool                 { return true }
+
+// askStream asks through a stream prompter and returns the confirmation
+// sent on the stream and a channel receiving the prompter's result.
+func askStream(t *testing.T, h *Handler, ctx context.Context) (api.InstallConfirmation, <-chan error, <-chan bool) {
+	t.Helper()
+
+	eventCh := make(chan string, 1)
+	errCh := make(chan error, 1)
+	confirmedCh := make(chan bool, 1)
+	go func() {
+		confirmed, err := h.streamPrompter(eventCh).Confirm(ctx, confirmHook{})
+		errCh <- err
+		confirmedCh <- confirmed
+	}()
+
+	var confirmation api.InstallConfirmation
+	select {
+	case event := <-eventCh:
+		data, ok := api.ParseTaggedInstallConfirmation(event)
+		if !ok {
+			t.Fatalf("event %q is not an install confirmation", event)
+		}
+		if err := json.Unmarshal([]byte(data), &confirmation); err != nil {
+			t.Fatal(err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("no install confirmation sent")
+	}
+	if confirmation.ID == "" || confirmation.Name != "docker" || confirmation.Message != "Docker is required." {
+		t.Errorf("confirmation = %+v, want the hook's name and message and an ID", confirmation)
+	}
+	return confirmation, errCh, confirmedCh
+}
+
+// postAnswer posts an answer to ConfirmInstall and returns the status code.
+func postAnswer(h *Handler, id string, confirmed bool) int {
+	body, _ := json.Marshal(api.InstallAnswer{ID: id, Confirmed: confirmed})
+	req := httptest.NewRequest(http.MethodPost, "/api/runtime/confirm", strings.NewReader(string(body)))
+	rec := httptest.NewRecorder()
+	h.ConfirmInstall(rec, req)
+	return rec.Code
+}
+
+func TestStreamPrompterRelaysAnswer(t *testing.T) {
+	for _, confirmed := range []bool{true, false} {
+		h := &Handler{}
+		confirmation, errCh, confirmedCh := askStream(t, h, context.Background())
+
+		if code := postAnswer(h, confirmation.ID, confirmed); code != http.StatusOK {
+			t.Fatalf("ConfirmInstall() = %d, want %d", code, http.StatusOK)
+		}
+		if err := <-errCh; err != nil {
+			t.Fatalf("Confirm() failed: %v", err)
+		}
+		if got := <-confirmedCh; got != confirmed {
+			t.Errorf("Confirm() = %v, want %v", got, confirmed)
+		}
+
+		// The question is gone once answered
+		if code := postAnswer(h, confirmation.ID, confirmed); code != http.StatusNotFound {
+			t.Errorf("second ConfirmInstall() = %d, want %d", code, http.StatusNotFound)
+		}
+	}
+}
+
+func TestStreamPrompterStopsOnCancel(t *testing.T) {
+	h := &Handler{}
+	ctx, cancel := context.WithCancel(context.Background())
+	confirmation, errCh, _ := askStream(t, h, ctx)
+
+	cancel()
+	if err := <-errCh; err == nil {
+		t.Fatal("Confirm() succeeded after cancellation, want error")
+	}
+	if code := postAnswer(h, confirmation.ID, true); code != http.StatusNotFound {
+		t.Errorf("ConfirmInstall() after cancellation = %d, want %d", code, http.StatusNotFound)
+	}
+}
+
+func TestStreamPrompterTimesOut(t *testing.T) {
+	saved := installConfirmTimeout
+	installConfirmTimeout = 10 * time.Millisecond
+	defer func() { installConfirmTimeout = saved }()
+
+	_, errCh, confirmedCh := askStream(t, &Handler{}, context.Background())
+	if err := <-errCh; err == nil {
+		t.Fatal("Confirm() succeeded without an answer, want error")
+	}
+	if <-confirmedCh {
+		t.Error("Confirm() = true without an answer, want false")
+	}
+}
+
+func TestConfirmInstallUnknownID(t *testing.T) {
+	if code := postAnswer(&Handler{}, "unknown", true); code != http.StatusNotFound {
+		t.Errorf("ConfirmInstall() = %d, want %d", code, http.StatusNotFound)
+	}
+}
@@ -0,0 +1,267 @@
+// Package handlers - copy.go implements copying a model under a new name.
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/tsingmaoai/xw-cli/internal/models"
+)
+
+// CopyModel handles requests to create a model variant from a downloaded
+// model.
+//
+// The copy is a lightweight model entry: its directory holds only a
+// Modelfile, cloned from the source model with FROM pointing at the source
+// model's weights, and a marker naming the base model. It is registered
+// with the base model's specification, so it can be started, edited with
+// 'xw edit' and listed like any downloaded model, without downloading the
+// weights again.
+//
+// With "from", the copy serves a local checkpoint instead (an absolute path
+// within the model import directories, see resolveModelfileFrom). The
+// source then only supplies the specification (devices, engines,
+// capabilities) and does not need to be downloaded, so a fine-tuned
+// checkpoint can be run without adding it to models.yaml.
+//
+// HTTP Method: POST
+// Endpoint: /api/models/copy
+//
+// Request body:
+//
+//	{
+//	  "source": "qwen3-8b",
+//	  "destination": "qwen3-8b-translator",
+//	  "from": "/data/models/my-finetune"  // optional
+//	}
+//
+// Response: 200 OK
+//
+//	{
+//	  "message": "Copied qwen3-8b to qwen3-8b-translator",
+//	  "base": "qwen3-8b"
+//	}
+func (h *Handler) CopyModel(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		h.WriteError(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req struct {
+		Source      string `json:"source"`
+		Destination string `json:"destination"`
+		From        string `json:"from"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.WriteError(w, fmt.Sprintf("Invalid request body: %v", err), http.StatusBadRequest)
+		return
+	}
+	if req.Source == "" || req.Destination == "" {
+		h.WriteError(w, "source and destination are required", http.StatusBadRequest)
+		return
+	}
+
+	spec := models.GetModelSpec(req.Source)
+	if spec == nil {
+		h.WriteError(w, "Model not found: "+req.Source, http.StatusNotFound)
+		return
+	}
+	modelsDir := h.config.Storage.GetModelsDir()
+	sourcePath := h.getModelPath(modelsDir, spec.ID)
+	if req.From != "" && !filepath.IsAbs(req.From) {
+		h.WriteError(w, fmt.Sprintf("from must be an absolute path: %s", req.From), http.StatusBadRequest)
+		return
+	}
+	if req.From == "" && !h.hasModelFiles(sourcePath) {
+		h.WriteError(w, fmt.Sprintf("Model %s is not downloaded, pull it first", req.Source), http.StatusNotFound)
+		return
+	}
+
+	if err := models.ValidateModelID(req.Destination); err != nil {
+		status := http.StatusBadRequest
+		if models.GetModelSpec(req.Destination) != nil {
+			status = http.StatusConflict
+		}
+		h.WriteError(w, err.Error(), status)
+		return
+	}
+	destDir := filepath.Join(modelsDir, req.Destination)
+	if _, err := os.Stat(destDir); err == nil {
+		h.WriteError(w, fmt.Sprintf("Directory %s already exists", destDir), http.StatusConflict)
+		return
+	}
+
+	// A copy of a copy serves the same weights and derives from the same base
+	var weightsPath string
+	var err error
+	if req.From != "" {
+		weightsPath, err = h.resolveModelfileFrom(req.From)
+	} else {
+		weightsPath, err = h.resolveModelPath(spec.ID)
+	}
+	if err != nil {
+		h.WriteError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	baseID := spec.TemplateModelID()
+
+	content, _ := h.readModelfile(sourcePath)
+	content = setModelfileFrom(content, weightsPath)
+
+	destPath := h.getModelPath(modelsDir, req.Destination)
+	if err := writeDerivedModel(destPath, content, baseID); err != nil {
+		os.RemoveAll(destDir)
+		h.WriteError(w, fmt.Sprintf("Failed to copy model: %v", err), http.StatusInternalServerError)
+		return
+	}
+	if err := models.RegisterDerivedModel(req.Destination, baseID); err != nil {
+		os.RemoveAll(destDir)
+		h.WriteError(w, fmt.Sprintf("Failed to register model: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	log.Info("Copied model %s to %s (base %s, weights %s)", req.Source, req.Destination, baseID, weightsPath)
+
+	h.WriteJSON(w, map[string]interface{}{
+		"message": fmt.Sprintf("Copied %s to %s", req.Source, req.Destination),
+		"base":    baseID,
+	}, http.StatusOK)
+}
+
+// RemoveModel handles requests to remove a model created with CopyModel.
+//
+// The model is unregistered and its directory, which only holds the
+// Modelfile and markers, is deleted; the weights belong to the base model
+// or the imported checkpoint and are kept. A model with instances is not
+// removed, since they would lose their Modelfile.
+//
+// HTTP Method: POST
+// Endpoint: /api/models/remove
+//
+// Request body:
+//
+//	{
+//	  "model": "qwen3-8b-translator"
+//	}
+//
+// Response: 200 OK
+//
+//	{
+//	  "message": "Removed qwen3-8b-translator"
+//	}
+func (h *Handler) RemoveModel(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		h.WriteError(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req struct {
+		Model string `json:"model"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.WriteError(w, fmt.Sprintf("Invalid request body: %v", err), http.StatusBadRequest)
+		return
+	}
+	if req.Model == "" {
+		h.WriteError(w, "model is required", http.StatusBadRequest)
+		return
+	}
+
+	spec := models.GetModelSpec(req.Model)
+	if spec == nil {
+		h.WriteError(w, "Model not found: "+req.Model, http.StatusNotFound)
+		return
+	}
+	if spec.DerivedFrom == "" {
+		h.WriteError(w, fmt.Sprintf("Model %s was not created with 'xw cp' and cannot be removed", req.Model),
+			http.StatusBadRequest)
+		return
+	}
+
+	instances, err := h.runtimeManager.List(r.Context())
+	if err != nil {
+		h.WriteError(w, fmt.Sprintf("Failed to list instances: %v", err), http.StatusInternalServerError)
+		return
+	}
+	for _, inst := range instances {
+		if inst.ModelID == spec.ID {
+			h.WriteError(w, fmt.Sprintf("Model %s is used by instance %s, remove the instance first",
+				spec.ID, inst.Alias), http.StatusConflict)
+			return
+		}
+	}
+
+	if err := models.UnregisterDerivedModel(spec.ID); err != nil {
+		h.WriteError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err := os.RemoveAll(filepath.Join(h.config.Storage.GetModelsDir(), spec.ID)); err != nil {
+		h.WriteError(w, fmt.Sprintf("Failed to remove model directory: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	log.Info("Removed model %s (base %s)", spec.ID, spec.DerivedFrom)
+
+	h.WriteJSON(w, map[string]interface{}{
+		"message": fmt.Sprintf("Removed %s", spec.ID),
+	}, http.StatusOK)
+}
+
+// writeDerivedModel creates the directory of a derived model.
+//
+// Parameters:
+//   - modelPath: Directory to create (models/{id}/latest)
+//   - content: Modelfile content
+//   - baseID: Base model ID, recorded in models.DerivedMarkerFile
+//
+// Returns:
+//   - Error if a file cannot be written
+func writeDerivedModel(modelPath, content, baseID string) error {
+	if err := os.MkdirAll(modelPath, 0755); err != nil {
+		return fmt.Errorf("failed to create model directory: %w", err)
+	}
+	if err := writeModelfile(modelPath, content); err != nil {
+		return err
+	}
+	if err := os.WriteFile(filepath.Join(modelPath, models.DerivedMarkerFile), []byte(baseID+"\n"), 0644); err != nil {
+		return fmt.Errorf("failed to write base model marker: %w", err)
+	}
+	// Listed as downloaded: the weights are the base model's
+	marker := fmt.Sprintf("Copied at: %s\n", time.Now().Format(time.RFC3339))
+	if err := os.WriteFile(filepath.Join(modelPath, ".downloaded"), []byte(marker), 0644); err != nil {
+		return fmt.Errorf("failed to write download marker: %w", err)
+	}
+	return nil
+}
+
+// setModelfileFrom points the FROM directive of a Modelfile at a model
+// directory, adding the directive if the Modelfile has none.
+func setModelfileFrom(content, path string) string {
+	from := "FROM " + path
+	if content == "" {
+		return from + "\n"
+	}
+
+	lines := strings.Split(content, "\n")
+	found := false
+	for _, d := range models.ParseModelfileDirectives(content) {
+		if d.Name != "FROM" {
+			continue
+		}
+		// Later FROM directives override earlier ones, so replace them all
+		lines[d.Start] = from
+		for i := d.Start + 1; i <= d.End; i++ {
+			lines[i] = ""
+		}
+		found = true
+	}
+	if !found {
+		return from + "\n\n" + content
+	}
+	return strings.Join(lines, "\n")
+}
@@ -6,7 +6,7 @@ import (
 	"net/http"
 
 	"github.com/tsingmaoai/xw-cli/internal/api"
-	"github.com/tsingmaoai/xw-cli/internal/logger"
+	"github.com/tsingmaoai/xw-cli/internal/device"
 )
 
 // ListDevices handles GET /api/devices/list requests.
@@ -25,22 +25,30 @@ import (
 //	    }
 //	  ]
 //	}
+//
+// With ?live=true, each chip also carries live metrics in "properties"
+// (utilization, memory_used_mb, memory_total_mb, health) read from the
+// vendor monitoring tool. Chips without a supported tool omit them.
 func (h *Handler) ListDevices(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodGet && r.Method != http.MethodPost {
 		h.WriteError(w, "Method not allowed", http.StatusMethodNotAllowed)
 		return
 	}
 
-	logger.Debug("Listing devices on server")
+	log.Debug("Listing devices on server")
 
 	// Get detailed chip information (one entry per physical chip)
 	chips, err := h.deviceManager.ListDetectedChips()
 	if err != nil {
-		logger.Error("Failed to list devices: %v", err)
+		log.Error("Failed to list devices: %v", err)
 		h.WriteError(w, "Failed to list devices", http.StatusInternalServerError)
 		return
 	}
 
+	if r.URL.Query().Get("live") == "true" {
+		device.CollectChipMetrics(chips)
+	}
+
 	resp := api.DeviceListResponse{
 		Devices: chips,
 	}
@@ -76,7 +84,7 @@ func (h *Handler) GetSupportedDevices(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
-	logger.Debug("Getting supported device types")
+	log.Debug("Getting supported device types")
 
 	// Get supported device types from device manager configuration
 	deviceTypes := h.deviceManager.GetSupportedTypes()
@@ -94,3 +102,58 @@ func (h *Handler) GetSupportedDevices(w http.ResponseWriter, r *http.Request) {
 	h.WriteJSON(w, resp, http.StatusOK)
 }
 
+
+// ListDeviceAllocations handles GET /api/devices/allocations requests.
+// It returns which instance each device is allocated to, as seen by the
+// device allocator when it places new instances.
+//
+// Response format:
+//
+//	{
+//	  "allocations": {"0": "qwen3-8b-a1b2", "1": "qwen3-8b-a1b2"},
+//	  "free": [2, 3],
+//	  "devices": [
+//	    {"index": 0, "type": "ascend", "model_name": "Ascend 910B", "bus_address": "0000:c1:00.0", "instance_id": "qwen3-8b-a1b2"},
+//	    ...
+//	  ]
+//	}
+//
+// Devices reserved for an instance that is still starting carry
+// "pending": true.
+func (h *Handler) ListDeviceAllocations(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		h.WriteError(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	allocations, err := h.runtimeManager.DeviceAllocations()
+	if err != nil {
+		log.Error("Failed to list device allocations: %v", err)
+		h.WriteError(w, "Failed to list device allocations: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	resp := api.DeviceAllocationsResponse{
+		Allocations: make(map[int]string),
+		Free:        []int{},
+		Devices:     make([]api.DeviceAllocation, 0, len(allocations)),
+	}
+	for _, alloc := range allocations {
+		idx := alloc.Index
+		if alloc.InstanceID == "" {
+			resp.Free = append(resp.Free, idx)
+		} else {
+			resp.Allocations[idx] = alloc.InstanceID
+		}
+		resp.Devices = append(resp.Devices, api.DeviceAllocation{
+			Index:      idx,
+			Type:       alloc.Device.Type,
+			ModelName:  alloc.Device.ModelName,
+			BusAddress: alloc.Device.BusAddress,
+			InstanceID: alloc.InstanceID,
+			Pending:    alloc.Pending,
+		})
+	}
+
+	h.WriteJSON(w, resp, http.StatusOK)
+}
@@ -0,0 +1,283 @@
+// Package handlers - doctor.go implements the server diagnostics endpoint.
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/tsingmaoai/xw-cli/internal/api"
+	"github.com/tsingmaoai/xw-cli/internal/config"
+	"github.com/tsingmaoai/xw-cli/internal/hooks"
+	"github.com/tsingmaoai/xw-cli/internal/models"
+	"github.com/tsingmaoai/xw-cli/internal/runtime"
+)
+
+// doctorLowDiskSpace is the free space in the models directory below which
+// the disk check warns; a single large model needs tens of gigabytes.
+const doctorLowDiskSpace int64 = 50 * 1024 * 1024 * 1024
+
+// doctorTimeout bounds the checks that call out to Docker.
+const doctorTimeout = 15 * time.Second
+
+// Doctor handles requests for a diagnostics report of the server.
+//
+// The report checks, in order: Docker, detected devices, runtime images
+// for the detected chips on the host architecture, free disk space for
+// models, the validity of the loaded configuration, and the readiness of
+// each instance. Every failed or suspicious check carries a hint on how
+// to fix it. Checks reuse the server's own machinery: the Docker hook used
+// before starting instances, device detection, and the readiness probe
+// results reported by 'xw ps'.
+//
+// HTTP Method: GET
+// Endpoint: /api/doctor
+//
+// Response: 200 OK
+//
+//	{
+//	  "checks": [
+//	    {"name": "docker", "status": "pass", "message": "Docker is installed and running"},
+//	    {"name": "instance qwen3-8b", "status": "warn", "message": "...", "hint": "..."}
+//	  ]
+//	}
+func (h *Handler) Doctor(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		h.WriteError(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), doctorTimeout)
+	defer cancel()
+
+	checks := []api.DoctorCheck{
+		h.checkDocker(ctx),
+		h.checkDevices(),
+	}
+	checks = append(checks, h.checkRuntimeImages()...)
+	checks = append(checks, h.checkDiskSpace(), h.checkConfig())
+	checks = append(checks, h.checkInstances()...)
+
+	h.WriteJSON(w, api.DoctorResponse{Checks: checks}, http.StatusOK)
+}
+
+// checkDocker checks that Docker is installed and running. Without Docker,
+// only native runtimes can serve models, so the check fails unless one is
+// registered.
+func (h *Handler) checkDocker(ctx context.Context) api.DoctorCheck {
+	check := api.DoctorCheck{Name: "docker"}
+
+	if err := hooks.NewDockerHook(nil).Check(ctx); err != nil {
+		check.Status = api.DoctorFail
+		check.Message = err.Error()
+		check.Hint = "Install Docker and start it ('sudo systemctl start docker'); " +
+			"'xw start' installs it automatically on Ubuntu, Debian, CentOS/RHEL and openEuler"
+		for _, name := range h.runtimeManager.RuntimeNames() {
+			if strings.HasSuffix(name, ":"+string(api.DeploymentModeNative)) {
+				check.Status = api.DoctorWarn
+				check.Message += "; only native runtimes can be used"
+				break
+			}
+		}
+		return check
+	}
+
+	check.Status = api.DoctorPass
+	check.Message = "Docker is installed and running"
+	return check
+}
+
+// checkDevices checks that AI accelerators were detected.
+func (h *Handler) checkDevices() api.DoctorCheck {
+	check := api.DoctorCheck{Name: "devices"}
+
+	chips, err := h.deviceManager.ListDetectedChips()
+	if err != nil {
+		check.Status = api.DoctorFail
+		check.Message = fmt.Sprintf("Device detection failed: %v", err)
+		check.Hint = "Check that /sys/bus/pci is readable by the server"
+		return check
+	}
+	if len(chips) == 0 {
+		check.Status = api.DoctorFail
+		check.Message = "No supported AI accelerator detected"
+		check.Hint = "Check that the chip driver is installed ('lspci' should list the card) " +
+			"and that devices.yaml defines its vendor_id and device_id"
+		return check
+	}
+
+	counts := make(map[string]int)
+	var order []string
+	for _, chip := range chips {
+		if counts[chip.ModelName] == 0 {
+			order = append(order, chip.ModelName)
+		}
+		counts[chip.ModelName]++
+	}
+	parts := make([]string, 0, len(order))
+	for _, name := range order {
+		parts = append(parts, fmt.Sprintf("%d x %s", counts[name], name))
+	}
+
+	check.Status = api.DoctorPass
+	check.Message = "Detected " + strings.Join(parts, ", ")
+	return check
+}
+
+// checkRuntimeImages checks that each detected chip has a runtime image
+// for the host architecture, so that its models do not fail only when the
+// container is created. Nothing is reported if no chip is detected; the
+// devices check covers that.
+func (h *Handler) checkRuntimeImages() []api.DoctorCheck {
+	chips, err := h.deviceManager.ListDetectedChips()
+	if err != nil || len(chips) == 0 {
+		return nil
+	}
+
+	check := api.DoctorCheck{Name: "images"}
+	arch, err := config.GetSystemArchitecture()
+	if err != nil {
+		check.Status = api.DoctorWarn
+		check.Message = err.Error()
+		return []api.DoctorCheck{check}
+	}
+	images, err := config.LoadRuntimeImagesConfig()
+	if err != nil {
+		check.Status = api.DoctorWarn
+		check.Message = fmt.Sprintf("Could not load runtime images: %v", err)
+		return []api.DoctorCheck{check}
+	}
+
+	var checks []api.DoctorCheck
+	seen := make(map[string]bool)
+	for _, chip := range chips {
+		if chip.ConfigKey == "" || seen[chip.ConfigKey] {
+			continue
+		}
+		seen[chip.ConfigKey] = true
+		if warning := config.ArchImageWarning(images, chip.ConfigKey, "", arch); warning != "" {
+			checks = append(checks, api.DoctorCheck{
+				Name:    "images " + chip.ConfigKey,
+				Status:  api.DoctorWarn,
+				Message: warning,
+				Hint:    "Set an image built for " + arch + ", or run a native engine ('xw start --engine vllm:native')",
+			})
+		}
+	}
+
+	if len(checks) == 0 {
+		check.Status = api.DoctorPass
+		check.Message = fmt.Sprintf("Runtime images are configured for %s", arch)
+		checks = append(checks, check)
+	}
+	return checks
+}
+
+// checkDiskSpace checks the free space in the models directory.
+func (h *Handler) checkDiskSpace() api.DoctorCheck {
+	check := api.DoctorCheck{Name: "disk"}
+	modelsDir := h.config.Storage.GetModelsDir()
+
+	available, err := models.FreeDiskSpace(modelsDir)
+	if err != nil {
+		check.Status = api.DoctorWarn
+		check.Message = fmt.Sprintf("Could not check free space in %s: %v", modelsDir, err)
+		return check
+	}
+
+	check.Message = fmt.Sprintf("%s free in %s", models.FormatBytes(available), modelsDir)
+	if available < doctorLowDiskSpace {
+		check.Status = api.DoctorWarn
+		check.Hint = "Free up space on this disk or move the data directory ('xw serve --data'); " +
+			"large models need tens of gigabytes to download"
+		return check
+	}
+	check.Status = api.DoctorPass
+	return check
+}
+
+// checkConfig validates the device and model configuration files of the
+// configuration version in use.
+func (h *Handler) checkConfig() api.DoctorCheck {
+	check := api.DoctorCheck{Name: "config"}
+
+	identity, err := h.config.GetOrCreateServerIdentity()
+	if err != nil {
+		check.Status = api.DoctorFail
+		check.Message = fmt.Sprintf("Failed to read server identity: %v", err)
+		return check
+	}
+
+	report := h.config.ValidateVersionedConfigs(identity.ConfigVersion)
+	configDir := filepath.Join(h.config.Storage.ConfigDir, identity.ConfigVersion)
+	switch {
+	case !report.OK():
+		check.Status = api.DoctorFail
+		check.Message = fmt.Sprintf("Configuration %s has %d error(s): %s",
+			identity.ConfigVersion, len(report.Errors), strings.Join(report.Errors, "; "))
+		check.Hint = fmt.Sprintf("Fix the files in %s (check them with 'xw config validate'), then run 'xw reload'", configDir)
+	case len(report.Warnings) > 0:
+		check.Status = api.DoctorWarn
+		check.Message = fmt.Sprintf("Configuration %s has %d warning(s): %s",
+			identity.ConfigVersion, len(report.Warnings), strings.Join(report.Warnings, "; "))
+		check.Hint = fmt.Sprintf("Review the files in %s", configDir)
+	default:
+		check.Status = api.DoctorPass
+		check.Message = fmt.Sprintf("Configuration %s is valid", identity.ConfigVersion)
+	}
+	return check
+}
+
+// checkInstances reports the readiness of each instance that is not
+// stopped, as determined by the readiness probe.
+func (h *Handler) checkInstances() []api.DoctorCheck {
+	var checks []api.DoctorCheck
+	for _, inst := range h.runtimeManager.ListCompat() {
+		if inst.State == runtime.StateStopped {
+			continue
+		}
+		alias := inst.Alias
+		if alias == "" {
+			alias = inst.ModelID
+		}
+		check := api.DoctorCheck{Name: "instance " + alias}
+
+		switch inst.State {
+		case runtime.StateReady, runtime.StateDraining, runtime.StateStopping:
+			check.Status = api.DoctorPass
+			check.Message = fmt.Sprintf("%s (%s)", inst.State, inst.Endpoint)
+		case runtime.StateCreating, runtime.StateCreated, runtime.StateStarting, runtime.StateRunning:
+			check.Status = api.DoctorWarn
+			check.Message = "Not ready yet"
+			if !inst.StartedAt.IsZero() {
+				check.Message += fmt.Sprintf(", starting for %s", time.Since(inst.StartedAt).Round(time.Second))
+			}
+			if inst.HealthError != "" {
+				check.Message += ": " + inst.HealthError
+			}
+			check.Hint = fmt.Sprintf("Large models take several minutes to load; follow progress with 'xw logs -f %s'", alias)
+		default:
+			check.Status = api.DoctorFail
+			check.Message = string(inst.State)
+			if inst.Error != "" {
+				check.Message += ": " + inst.Error
+			} else if inst.HealthError != "" {
+				check.Message += ": " + inst.HealthError
+			}
+			check.Hint = fmt.Sprintf("Check 'xw logs %s', then 'xw restart %s' or 'xw rm %s'", alias, alias, alias)
+		}
+		checks = append(checks, check)
+	}
+
+	if len(checks) == 0 {
+		checks = append(checks, api.DoctorCheck{
+			Name:    "instances",
+			Status:  api.DoctorPass,
+			Message: "No instances running",
+		})
+	}
+	return checks
+}
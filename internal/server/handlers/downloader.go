@@ -7,9 +7,11 @@ import (
 	"fmt"
 	"net/http"
 	"os"
+	"path/filepath"
+	"strings"
+	"sync"
 	"time"
 
-	"github.com/tsingmaoai/xw-cli/internal/logger"
 	"github.com/tsingmaoai/xw-cli/internal/models"
 )
 
@@ -21,7 +23,7 @@ import (
 //  3. Executes the script as a subprocess with unbuffered output
 //  4. Captures stdout and stderr from the download process
 //  5. Streams each output line as an SSE progress message
-//  6. Sends periodic heartbeat messages to keep the connection alive
+//  6. Sends periodic heartbeat messages with the current file and elapsed time
 //  7. Waits for download completion and returns the model path
 //
 // The function uses Python's ModelScope library to handle the actual download,
@@ -35,8 +37,12 @@ import (
 // during file extraction or verification).
 //
 // Parameters:
-//   - modelName: The ModelScope model identifier (e.g., "Qwen/Qwen2-7B")
-//   - version: Model version or git branch (currently unused, defaults to "main")
+//   - source: Model hub to download from ("modelscope" or "huggingface")
+//   - modelName: The model identifier on the hub (e.g., "Qwen/Qwen2-7B")
+//   - version: Local version tag; files are stored in modelsDir/modelID/version
+//   - opts: Source revision and number of concurrent file downloads
+//   - estimatedSize: Expected size of the model in bytes (0 if unknown), used
+//     for the pre-flight disk space check
 //   - w: HTTP response writer for sending SSE messages
 //   - flusher: HTTP flusher to immediately push SSE data to client
 //
@@ -47,17 +53,20 @@ import (
 // SSE Message Format:
 // All messages are sent as JSON objects with a "type" field:
 //   - {"type":"progress","message":"..."}  - Download progress updates
-//   - {"type":"heartbeat","message":"..."}  - Keep-alive messages
+//   - {"type":"summary","message":"...","percent":42.0,"completed":...,"total":...,"rate":...,"eta_seconds":...}
+//     - Overall progress with recent transfer rate and time remaining
+//   - {"type":"heartbeat","message":"..."}  - Keep-alive messages naming the
+//     current file and elapsed time, e.g. "Downloading model-00003-of-00014.safetensors (4m12s elapsed)"
 //
 // Example:
 //
-//	path, err := h.downloadModelStreaming("Qwen/Qwen2-7B", "main", w, flusher)
+//	path, err := h.downloadModelStreaming(ctx, "modelscope", "Qwen/Qwen2-7B", "qwen2-7b", "latest", models.DownloadOptions{}, 0, w, flusher)
 //	if err != nil {
-//	    logger.Error("Download failed: %v", err)
+//	    log.Error("Download failed: %v", err)
 //	    return
 //	}
-//	logger.Info("Model downloaded to: %s", path)
-func (h *Handler) downloadModelStreaming(ctx context.Context, modelName, modelID, version string, w http.ResponseWriter, flusher http.Flusher) (string, error) {
+//	log.Info("Model downloaded to: %s", path)
+func (h *Handler) downloadModelStreaming(ctx context.Context, source, modelName, modelID, version string, opts models.DownloadOptions, estimatedSize int64, w http.ResponseWriter, flusher http.Flusher) (string, error) {
 	// Ensure the models storage directory exists
 	// This directory is configured in the server config (typically ~/.xw/models/)
 	modelsDir := h.config.Storage.GetModelsDir()
@@ -65,10 +74,65 @@ func (h *Handler) downloadModelStreaming(ctx context.Context, modelName, modelID
 		return "", fmt.Errorf("failed to create models directory: %w", err)
 	}
 
-	logger.Info("Starting Go-native download for model %s (ID: %s, tag: %s) to %s", modelName, modelID, version, modelsDir)
+	// Fail fast if the declared model size cannot fit, rather than leaving a
+	// partial download behind. Files already on disk from an earlier
+	// attempt are not downloaded again, so they do not count.
+	if estimatedSize > 0 {
+		if existing, err := getDirSize(filepath.Join(modelsDir, modelID, version)); err == nil {
+			estimatedSize -= existing
+		}
+		if err := models.CheckDiskSpace(modelsDir, estimatedSize); err != nil {
+			return "", err
+		}
+	}
+
+	log.Info("Starting Go-native download for model %s from %s (revision: %s, jobs: %d, ID: %s, tag: %s) to %s", modelName, source, opts.Revision, opts.Jobs, modelID, version, modelsDir)
 
-	// Create ModelScope client
-	client := models.NewClient()
+	// Track the file being downloaded so the heartbeat can name it. The
+	// downloader reports file starts from its worker goroutines.
+	var (
+		fileMu      sync.Mutex
+		currentFile string
+	)
+	opts.OnFileStart = func(name string) {
+		fileMu.Lock()
+		currentFile = name
+		fileMu.Unlock()
+	}
+	startTime := time.Now()
+	
+	// Aggregate progress across all files, sent as a "summary" SSE message
+	// with the overall percentage, recent transfer rate, and time remaining
+	opts.OnProgress = func(p models.DownloadProgress) {
+		if ctx.Err() != nil {
+			return // Don't try to write if connection is closed
+		}
+		
+		defer func() {
+			if r := recover(); r != nil {
+				log.Debug("Summary callback panic (client likely disconnected): %v", r)
+			}
+		}()
+		
+		sseMsg := map[string]interface{}{
+			"type":        "summary",
+			"message":     p.String(),
+			"percent":     p.Percent(),
+			"completed":   p.CompletedBytes,
+			"total":       p.TotalBytes,
+			"rate":        p.BytesPerSecond,
+			"eta_seconds": int64(p.ETA.Seconds()),
+		}
+		msgJSON, _ := json.Marshal(sseMsg)
+		fmt.Fprintf(w, "data: %s\n\n", msgJSON)
+		flusher.Flush()
+	}
+	
+	// Create the downloader for the model's hub (ModelScope or HuggingFace)
+	client, err := models.NewDownloader(source, opts)
+	if err != nil {
+		return "", err
+	}
 	
 	// Use the request context - it will be cancelled when client disconnects
 	// This ensures downloads are stopped when the client disconnects (Ctrl+C)
@@ -84,9 +148,18 @@ func (h *Handler) downloadModelStreaming(ctx context.Context, modelName, modelID
 		for {
 			select {
 			case <-heartbeatTicker.C:
+				fileMu.Lock()
+				file := currentFile
+				fileMu.Unlock()
+				
+				elapsed := time.Since(startTime).Round(time.Second)
+				message := fmt.Sprintf("Download in progress (%s elapsed)", elapsed)
+				if file != "" {
+					message = fmt.Sprintf("Downloading %s (%s elapsed)", file, elapsed)
+				}
 				msg := map[string]string{
 					"type":    "heartbeat",
-					"message": "Download in progress...",
+					"message": message,
 				}
 				msgJSON, _ := json.Marshal(msg)
 				fmt.Fprintf(w, "data: %s\n\n", msgJSON)
@@ -113,7 +186,7 @@ func (h *Handler) downloadModelStreaming(ctx context.Context, modelName, modelID
 		// Add panic recovery to prevent server crash on write errors
 		defer func() {
 			if r := recover(); r != nil {
-				logger.Debug("Progress callback panic (client likely disconnected): %v", r)
+				log.Debug("Progress callback panic (client likely disconnected): %v", r)
 			}
 		}()
 		
@@ -140,14 +213,124 @@ func (h *Handler) downloadModelStreaming(ctx context.Context, modelName, modelID
 	if err != nil {
 		// Check if error is due to context cancellation (client disconnect)
 		if ctx.Err() == context.Canceled {
-			logger.Info("Download of %s cancelled by client disconnect", modelName)
+			log.Info("Download of %s cancelled by client disconnect", modelName)
 			return "", fmt.Errorf("download cancelled")
 		}
 		return "", fmt.Errorf("download failed: %w", err)
 	}
 	
 	// Use Debug level since client will display success via SSE complete message
-	logger.Debug("Model %s downloaded successfully to %s", modelName, modelPath)
+	log.Debug("Model %s downloaded successfully to %s", modelName, modelPath)
 	return modelPath, nil
 }
 
+
+// downloadLockFile marks a model directory whose download is in progress.
+const downloadLockFile = ".download.lock"
+
+// downloadLockStaleAfter is how long a lock file may go without being
+// refreshed before it is considered abandoned (e.g., after a server crash)
+// and can be reclaimed. The holder refreshes it every downloadLockRefresh.
+const (
+	downloadLockStaleAfter = 2 * time.Minute
+	downloadLockRefresh    = 30 * time.Second
+)
+
+// downloadLock is a held download lock on a model directory.
+type downloadLock struct {
+	path string
+	stop chan struct{}
+	done chan struct{}
+}
+
+// acquireDownloadLock locks a model directory for downloading.
+//
+// The lock file is created atomically and records the server PID and start
+// time. While held, its modification time is refreshed periodically, so a
+// lock that has not been refreshed for downloadLockStaleAfter belongs to a
+// process that died and is reclaimed.
+//
+// Parameters:
+//   - modelPath: Model directory to lock (created if missing)
+//
+// Returns:
+//   - The held lock; call release when the download finishes
+//   - Error if another download holds the lock or the file cannot be created
+func acquireDownloadLock(modelPath string) (*downloadLock, error) {
+	if err := os.MkdirAll(modelPath, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create model directory: %w", err)
+	}
+
+	lockPath := filepath.Join(modelPath, downloadLockFile)
+	lockInfo := fmt.Sprintf("pid=%d,time=%s", os.Getpid(), time.Now().Format(time.RFC3339))
+
+	for attempt := 0; attempt < 2; attempt++ {
+		f, err := os.OpenFile(lockPath, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644)
+		if err == nil {
+			_, err = f.WriteString(lockInfo)
+			f.Close()
+			if err != nil {
+				os.Remove(lockPath)
+				return nil, fmt.Errorf("failed to write lock file: %w", err)
+			}
+
+			lock := &downloadLock{path: lockPath, stop: make(chan struct{}), done: make(chan struct{})}
+			go lock.refresh()
+			return lock, nil
+		}
+		if !os.IsExist(err) {
+			return nil, fmt.Errorf("failed to create lock file: %w", err)
+		}
+
+		if isDownloadLocked(modelPath) {
+			data, _ := os.ReadFile(lockPath)
+			return nil, fmt.Errorf("download already in progress (%s)", strings.TrimSpace(string(data)))
+		}
+
+		log.Warn("Reclaiming stale download lock %s", lockPath)
+		if err := os.Remove(lockPath); err != nil && !os.IsNotExist(err) {
+			return nil, fmt.Errorf("failed to remove stale lock file: %w", err)
+		}
+	}
+
+	return nil, fmt.Errorf("download already in progress")
+}
+
+// refresh keeps the lock file's modification time current until release.
+func (l *downloadLock) refresh() {
+	defer close(l.done)
+
+	ticker := time.NewTicker(downloadLockRefresh)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			now := time.Now()
+			if err := os.Chtimes(l.path, now, now); err != nil {
+				log.Warn("Failed to refresh download lock %s: %v", l.path, err)
+			}
+		case <-l.stop:
+			return
+		}
+	}
+}
+
+// release stops refreshing the lock and removes the lock file.
+func (l *downloadLock) release() {
+	close(l.stop)
+	<-l.done
+	if err := os.Remove(l.path); err != nil && !os.IsNotExist(err) {
+		log.Warn("Failed to remove download lock %s: %v", l.path, err)
+	}
+}
+
+// isDownloadLocked reports whether a model directory holds a live download
+// lock. Stale locks (see downloadLockStaleAfter) do not count.
+func isDownloadLocked(modelPath string) bool {
+	info, err := os.Stat(filepath.Join(modelPath, downloadLockFile))
+	if err != nil {
+		return false
+	}
+	return time.Since(info.ModTime()) < downloadLockStaleAfter
+}
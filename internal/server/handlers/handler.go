@@ -19,6 +19,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"sync"
 
 	"github.com/tsingmaoai/xw-cli/internal/api"
 	"github.com/tsingmaoai/xw-cli/internal/config"
@@ -28,6 +29,9 @@ import (
 	"github.com/tsingmaoai/xw-cli/internal/runtime"
 )
 
+// log is the API handlers component logger (XW_LOG=handlers=LEVEL).
+var log = logger.Named("handlers")
+
 // Handler encapsulates all dependencies required by API handlers.
 //
 // This structure provides a clean way to pass server state and dependencies
@@ -57,6 +61,10 @@ type Handler struct {
 
 	// buildTime is the timestamp when the server was built.
 	buildTime string
+
+	// confirmations maps the IDs of unanswered install confirmations to
+	// the channels their answers are sent on.
+	confirmations sync.Map
 }
 
 // NewHandler creates a new Handler instance with the provided dependencies.
@@ -118,7 +126,7 @@ func (h *Handler) WriteJSON(w http.ResponseWriter, data interface{}, statusCode
 	w.WriteHeader(statusCode)
 
 	if err := json.NewEncoder(w).Encode(data); err != nil {
-		logger.Error("Failed to encode JSON response: %v", err)
+		log.Error("Failed to encode JSON response: %v", err)
 	}
 }
 
@@ -0,0 +1,379 @@
+// Package handlers - modelfile.go implements updating a model's Modelfile.
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/tsingmaoai/xw-cli/internal/models"
+)
+
+// maxModelfileBackups is the number of previous Modelfile versions kept per
+// model. Older backups are deleted when a new one is written.
+const maxModelfileBackups = 5
+
+// modelfileBackupPrefix prefixes backup file names in the model directory.
+// The suffix is a fixed-width timestamp, so names sort chronologically.
+const modelfileBackupPrefix = "Modelfile.bak."
+
+// UpdateModelfile handles requests to replace the Modelfile of a downloaded model.
+//
+// The Modelfile is the user-editable configuration layer (system prompt,
+// template, inference parameters) generated when the model is pulled.
+// Clients read the current content via /api/models/show, apply their edits,
+// and send the complete new content here. The file is replaced atomically
+// so a failed write never leaves a truncated Modelfile behind.
+//
+// The previous content is saved as a backup first (see RevertModelfile);
+// at most maxModelfileBackups backups are kept per model.
+//
+// HTTP Method: POST
+// Endpoint: /api/models/modelfile
+//
+// Request body:
+//
+//	{
+//	  "model": "qwen2.5-7b-instruct",
+//	  "content": "FROM ...\nSYSTEM ...\n"
+//	}
+//
+// Response: 200 OK
+//
+//	{
+//	  "message": "Modelfile updated"
+//	}
+func (h *Handler) UpdateModelfile(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		h.WriteError(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req struct {
+		Model   string `json:"model"`
+		Content string `json:"content"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.WriteError(w, fmt.Sprintf("Invalid request body: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	if req.Model == "" {
+		h.WriteError(w, "model is required", http.StatusBadRequest)
+		return
+	}
+	if req.Content == "" {
+		h.WriteError(w, "content cannot be empty", http.StatusBadRequest)
+		return
+	}
+
+	if models.GetModelSpec(req.Model) == nil {
+		h.WriteError(w, "Model not found: "+req.Model, http.StatusNotFound)
+		return
+	}
+
+	modelPath := h.getModelPath(h.config.Storage.GetModelsDir(), req.Model)
+	if _, exists := h.readModelfile(modelPath); !exists {
+		h.WriteError(w, fmt.Sprintf("Modelfile not found for %s, pull the model first", req.Model), http.StatusNotFound)
+		return
+	}
+
+	if err := h.validateModelfileContent(req.Content); err != nil {
+		h.WriteError(w, fmt.Sprintf("Invalid Modelfile: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	if err := backupModelfile(modelPath); err != nil {
+		h.WriteError(w, fmt.Sprintf("Failed to back up Modelfile: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	if err := writeModelfile(modelPath, req.Content); err != nil {
+		h.WriteError(w, fmt.Sprintf("Failed to update Modelfile: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	log.Info("Updated Modelfile for %s", req.Model)
+
+	h.WriteJSON(w, map[string]interface{}{
+		"message": "Modelfile updated",
+	}, http.StatusOK)
+}
+
+// RevertModelfile handles requests to restore the most recent Modelfile backup.
+//
+// The backup replaces the current Modelfile and is then removed, so
+// reverting repeatedly steps back through older versions until no backups
+// remain.
+//
+// HTTP Method: POST
+// Endpoint: /api/models/modelfile/revert
+//
+// Request body:
+//
+//	{
+//	  "model": "qwen2.5-7b-instruct"
+//	}
+//
+// Response: 200 OK
+//
+//	{
+//	  "message": "Modelfile reverted",
+//	  "backup": "Modelfile.bak.20261015-103000.000000",
+//	  "remaining": 2
+//	}
+func (h *Handler) RevertModelfile(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		h.WriteError(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req struct {
+		Model string `json:"model"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.WriteError(w, fmt.Sprintf("Invalid request body: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	if req.Model == "" {
+		h.WriteError(w, "model is required", http.StatusBadRequest)
+		return
+	}
+
+	if models.GetModelSpec(req.Model) == nil {
+		h.WriteError(w, "Model not found: "+req.Model, http.StatusNotFound)
+		return
+	}
+
+	modelPath := h.getModelPath(h.config.Storage.GetModelsDir(), req.Model)
+	backups, err := listModelfileBackups(modelPath)
+	if err != nil {
+		h.WriteError(w, fmt.Sprintf("Failed to list Modelfile backups: %v", err), http.StatusInternalServerError)
+		return
+	}
+	if len(backups) == 0 {
+		h.WriteError(w, fmt.Sprintf("No Modelfile backup found for %s", req.Model), http.StatusNotFound)
+		return
+	}
+
+	latest := backups[len(backups)-1]
+	content, err := os.ReadFile(filepath.Join(modelPath, latest))
+	if err != nil {
+		h.WriteError(w, fmt.Sprintf("Failed to read Modelfile backup: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	if err := writeModelfile(modelPath, string(content)); err != nil {
+		h.WriteError(w, fmt.Sprintf("Failed to restore Modelfile: %v", err), http.StatusInternalServerError)
+		return
+	}
+	if err := os.Remove(filepath.Join(modelPath, latest)); err != nil {
+		log.Warn("Failed to remove restored Modelfile backup %s: %v", latest, err)
+	}
+
+	log.Info("Reverted Modelfile for %s to %s", req.Model, latest)
+
+	h.WriteJSON(w, map[string]interface{}{
+		"message":   "Modelfile reverted",
+		"backup":    latest,
+		"remaining": len(backups) - 1,
+	}, http.StatusOK)
+}
+
+// backupModelfile copies the current Modelfile to a timestamped backup and
+// prunes backups beyond maxModelfileBackups.
+//
+// Parameters:
+//   - modelPath: Path to the model directory
+//
+// Returns:
+//   - Error if the backup cannot be written
+func backupModelfile(modelPath string) error {
+	content, err := os.ReadFile(filepath.Join(modelPath, "Modelfile"))
+	if err != nil {
+		return err
+	}
+
+	name := modelfileBackupPrefix + time.Now().Format("20060102-150405.000000")
+	if err := os.WriteFile(filepath.Join(modelPath, name), content, 0644); err != nil {
+		return err
+	}
+
+	backups, err := listModelfileBackups(modelPath)
+	if err != nil {
+		return err
+	}
+	for len(backups) > maxModelfileBackups {
+		if err := os.Remove(filepath.Join(modelPath, backups[0])); err != nil {
+			log.Warn("Failed to remove old Modelfile backup %s: %v", backups[0], err)
+		}
+		backups = backups[1:]
+	}
+
+	return nil
+}
+
+// listModelfileBackups returns the Modelfile backup names in a model
+// directory, oldest first.
+func listModelfileBackups(modelPath string) ([]string, error) {
+	entries, err := os.ReadDir(modelPath)
+	if err != nil {
+		return nil, err
+	}
+
+	var backups []string
+	for _, entry := range entries {
+		if !entry.IsDir() && strings.HasPrefix(entry.Name(), modelfileBackupPrefix) {
+			backups = append(backups, entry.Name())
+		}
+	}
+	sort.Strings(backups)
+
+	return backups, nil
+}
+
+// validateModelfileContent checks a Modelfile before it is saved.
+//
+// FROM is required. It is either a registered model name, whose downloaded
+// weights are served, or an absolute path to a local model directory
+// (e.g., a fine-tuned checkpoint) within the model import directories.
+//
+// Parameters:
+//   - content: Modelfile content
+//
+// Returns:
+//   - Error describing the problem, or nil if the content is valid
+func (h *Handler) validateModelfileContent(content string) error {
+	from := models.ModelfileDirectiveValue(content, "FROM")
+	if from == "" {
+		return fmt.Errorf("FROM directive with a model name or path is required")
+	}
+
+	_, err := h.resolveModelfileFrom(from)
+	return err
+}
+
+// resolveModelfileFrom returns the model directory a FROM value refers to.
+//
+// A registered model name refers to the downloaded weights of that model
+// (of its base model, for a model created with 'xw cp'). An absolute path
+// must lie within the model import directories (see
+// config.CheckModelImportPath), since it is mounted into the engine
+// container, and must hold a model.
+//
+// Parameters:
+//   - from: FROM directive value
+//
+// Returns:
+//   - Path to the model files
+//   - Error if FROM names an unknown or undownloaded model, or an invalid path
+func (h *Handler) resolveModelfileFrom(from string) (string, error) {
+	if !filepath.IsAbs(from) {
+		spec := models.GetModelSpec(from)
+		if spec == nil {
+			return "", fmt.Errorf("FROM %s: unknown model (use a registered model name or an absolute path)", from)
+		}
+		path := h.getModelPath(h.config.Storage.GetModelsDir(), spec.TemplateModelID())
+		if !h.hasModelFiles(path) {
+			return "", fmt.Errorf("FROM %s: model is not downloaded, pull it first", from)
+		}
+		return path, nil
+	}
+
+	dir := filepath.Clean(from)
+	if err := h.validateLocalModelDir(dir); err != nil {
+		return "", err
+	}
+	if err := h.config.CheckModelImportPath(dir); err != nil {
+		return "", fmt.Errorf("FROM %w", err)
+	}
+	return dir, nil
+}
+
+// validateLocalModelDir checks that an absolute FROM path is a directory
+// holding a model: either one downloaded by xw (see hasModelFiles) or a
+// Hugging Face style checkpoint with a config.json.
+func (h *Handler) validateLocalModelDir(dir string) error {
+	info, err := os.Stat(dir)
+	if err != nil {
+		return fmt.Errorf("FROM %s: %w", dir, err)
+	}
+	if !info.IsDir() {
+		return fmt.Errorf("FROM %s: not a directory", dir)
+	}
+	if h.hasModelFiles(dir) {
+		return nil
+	}
+	if _, err := os.Stat(filepath.Join(dir, "config.json")); err == nil {
+		return nil
+	}
+	return fmt.Errorf("FROM %s: no model files found (expected config.json)", dir)
+}
+
+// resolveModelPath returns the directory to mount for a model.
+//
+// By default this is the model's download directory. If the model's
+// Modelfile has a FROM naming another model or a local checkpoint (see
+// resolveModelfileFrom), that directory is used instead, so a checkpoint
+// can be served under a model's name.
+//
+// Parameters:
+//   - modelID: Registered model ID
+//
+// Returns:
+//   - Path to the model files
+//   - Error if the Modelfile's FROM cannot be served, rather than silently
+//     serving the download directory instead
+func (h *Handler) resolveModelPath(modelID string) (string, error) {
+	modelPath := h.getModelPath(h.config.Storage.GetModelsDir(), modelID)
+
+	content, exists := h.readModelfile(modelPath)
+	if !exists {
+		return modelPath, nil
+	}
+
+	from := models.ModelfileDirectiveValue(content, "FROM")
+	if from == "" || filepath.Clean(from) == filepath.Clean(modelPath) {
+		return modelPath, nil
+	}
+	path, err := h.resolveModelfileFrom(from)
+	if err != nil {
+		return "", fmt.Errorf("Modelfile of %s: %w", modelID, err)
+	}
+
+	if path != modelPath {
+		log.Info("Using model path %s from Modelfile of %s", path, modelID)
+	}
+	return path, nil
+}
+
+// writeModelfile atomically replaces the Modelfile in a model directory.
+//
+// Parameters:
+//   - modelPath: Path to the model directory
+//   - content: New Modelfile content
+//
+// Returns:
+//   - Error if the file cannot be written
+func writeModelfile(modelPath, content string) error {
+	modelfilePath := filepath.Join(modelPath, "Modelfile")
+	tmpPath := modelfilePath + ".tmp"
+
+	if err := os.WriteFile(tmpPath, []byte(content), 0644); err != nil {
+		return fmt.Errorf("failed to write temporary file: %w", err)
+	}
+	if err := os.Rename(tmpPath, modelfilePath); err != nil {
+		os.Remove(tmpPath)
+		return fmt.Errorf("failed to replace Modelfile: %w", err)
+	}
+
+	return nil
+}
@@ -7,11 +7,11 @@ import (
 	"net/http"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
 	"time"
 
 	"github.com/tsingmaoai/xw-cli/internal/api"
-	"github.com/tsingmaoai/xw-cli/internal/logger"
 	"github.com/tsingmaoai/xw-cli/internal/models"
 )
 
@@ -21,6 +21,12 @@ import (
 // match the specified criteria. Clients can filter models by:
 //   - Device type: Only show models compatible with specific AI chips
 //   - Show all: Include all models regardless of local device availability
+//   - Capabilities: Only show models with all listed capabilities
+//   - Name prefix: Only show models whose name starts with a prefix
+//
+// Results are sorted by name. For paging, set limit and offset; "total" in
+// the response counts all matching models. Without limit/offset every
+// matching model is returned.
 //
 // The returned model list includes comprehensive metadata for each model:
 //   - Basic info: Name, version, description
@@ -37,7 +43,12 @@ import (
 //
 //	{
 //	  "device_type": "ascend",  // Optional: Filter by device type
-//	  "show_all": false         // Optional: Show all or only available models
+//	  "show_all": false,        // Optional: Show all or only available models
+//	  "capabilities": ["vision"], // Optional: Required capabilities
+//	  "name_prefix": "qwen",    // Optional: Model name prefix
+//	  "group": "qwen2",         // Optional: Model group (model_groups)
+//	  "limit": 20,              // Optional: Page size (0 for all)
+//	  "offset": 0               // Optional: Models to skip
 //	}
 //
 // Response: 200 OK with ListModelsResponse JSON
@@ -54,7 +65,8 @@ import (
 //	      "supported_devices": ["ascend", "kunlun"],
 //	      "tags": ["chat", "general"]
 //	    }
-//	  ]
+//	  ],
+//	  "total": 1
 //	}
 //
 // Example usage:
@@ -76,6 +88,20 @@ func (h *Handler) ListModels(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Resolve the model group filter, if any
+	var inGroup map[string]bool
+	if req.Group != "" {
+		members, err := models.GetModelGroup(req.Group)
+		if err != nil {
+			h.WriteError(w, err.Error(), http.StatusNotFound)
+			return
+		}
+		inGroup = make(map[string]bool, len(members))
+		for _, id := range members {
+			inGroup[id] = true
+		}
+	}
+
 	// Get detected devices from device manager
 	detectedDevices := h.deviceManager.GetDetectedDeviceTypes()
 	
@@ -98,12 +124,44 @@ func (h *Handler) ListModels(w http.ResponseWriter, r *http.Request) {
 		availableModels = len(models)
 	}
 	
+	// Keep only models with every requested capability
+	models = h.modelRegistry.FilterByCapabilities(models, req.Capabilities)
+	
+	// Keep only members of the requested model group
+	if inGroup != nil {
+		filtered := models[:0]
+		for _, model := range models {
+			if inGroup[model.Name] {
+				filtered = append(filtered, model)
+			}
+		}
+		models = filtered
+	}
+	
+	// Name filter and paging, over a stable name order
+	if req.NamePrefix != "" {
+		prefix := strings.ToLower(req.NamePrefix)
+		filtered := models[:0]
+		for _, model := range models {
+			if strings.HasPrefix(strings.ToLower(model.Name), prefix) {
+				filtered = append(filtered, model)
+			}
+		}
+		models = filtered
+	}
+	sort.Slice(models, func(i, j int) bool {
+		return models[i].Name < models[j].Name
+	})
+	total := len(models)
+	models = paginateModels(models, req.Offset, req.Limit)
+	
 	// Check download status for each model
 	h.enrichModelsWithDownloadStatus(&models)
 
 	// Construct response with statistics
 	resp := api.ListModelsResponse{
 		Models:           models,
+		Total:            total,
 		TotalModels:      totalModels,
 		AvailableModels:  availableModels,
 		DetectedDevices:  detectedDevices,
@@ -113,6 +171,23 @@ func (h *Handler) ListModels(w http.ResponseWriter, r *http.Request) {
 	h.WriteJSON(w, resp, http.StatusOK)
 }
 
+// paginateModels returns the page of models starting at offset with at most
+// limit entries. A limit of zero or less means no limit; offsets past the
+// end yield an empty page.
+func paginateModels(models []api.Model, offset, limit int) []api.Model {
+	if offset < 0 {
+		offset = 0
+	}
+	if offset >= len(models) {
+		return []api.Model{}
+	}
+	models = models[offset:]
+	if limit > 0 && limit < len(models) {
+		models = models[:limit]
+	}
+	return models
+}
+
 // ShowModel handles requests to show detailed information about a specific model.
 //
 // This endpoint retrieves comprehensive information about a model including:
@@ -344,83 +419,16 @@ func (h *Handler) ShowModel(w http.ResponseWriter, r *http.Request) {
 	h.WriteJSON(w, response, http.StatusOK)
 }
 
-// extractDirectiveFromModelfile extracts a directive value from Modelfile
+// extractDirectiveFromModelfile extracts a directive value from Modelfile.
+// The last occurrence wins, as in Ollama.
 func (h *Handler) extractDirectiveFromModelfile(content, directive string) string {
-	lines := strings.Split(content, "\n")
-
-	for i, line := range lines {
-		trimmed := strings.TrimSpace(line)
-
-		if strings.HasPrefix(trimmed, directive+" ") {
-			rest := strings.TrimSpace(strings.TrimPrefix(trimmed, directive+" "))
-
-			// Handle triple quotes
-			if strings.HasPrefix(rest, "\"\"\"") {
-				rest = strings.TrimPrefix(rest, "\"\"\"")
-
-				// Single line
-				if strings.Contains(rest, "\"\"\"") {
-					return strings.Split(rest, "\"\"\"")[0]
-				}
-
-				// Multi-line
-				var parts []string
-				if rest != "" {
-					parts = append(parts, rest)
-				}
-
-				for j := i + 1; j < len(lines); j++ {
-					if strings.Contains(lines[j], "\"\"\"") {
-						ending := strings.Split(lines[j], "\"\"\"")[0]
-						if ending != "" {
-							parts = append(parts, ending)
-						}
-						break
-					}
-					parts = append(parts, lines[j])
-				}
-				return strings.Join(parts, "\n")
-			}
-
-			// Handle single quotes
-			if strings.HasPrefix(rest, "\"") && strings.HasSuffix(rest, "\"") {
-				return strings.Trim(rest, "\"")
-			}
-
-			return rest
-		}
-	}
-
-	return ""
+	return models.ModelfileDirectiveValue(content, directive)
 }
 
-// extractParametersFromModelfile extracts PARAMETER directives from Modelfile
+// extractParametersFromModelfile extracts typed PARAMETER directives from
+// Modelfile (numbers as numbers, repeated keys such as stop as lists)
 func (h *Handler) extractParametersFromModelfile(content string) map[string]interface{} {
-	params := make(map[string]interface{})
-	lines := strings.Split(content, "\n")
-
-	for _, line := range lines {
-		trimmed := strings.TrimSpace(line)
-
-		// Skip comments
-		if strings.HasPrefix(trimmed, "#") {
-			continue
-		}
-
-		if strings.HasPrefix(trimmed, "PARAMETER ") {
-			rest := strings.TrimSpace(strings.TrimPrefix(trimmed, "PARAMETER "))
-			parts := strings.Fields(rest)
-
-			if len(parts) >= 2 {
-				key := parts[0]
-				value := strings.Join(parts[1:], " ")
-				value = strings.Trim(value, "\"")
-				params[key] = value
-			}
-		}
-	}
-
-	return params
+	return models.ModelfileParameters(content)
 }
 
 // readModelConfig reads the config.json file from model directory
@@ -435,14 +443,14 @@ func (h *Handler) readModelConfig(modelPath string) map[string]interface{} {
 	// Read file
 	data, err := os.ReadFile(configPath)
 	if err != nil {
-		logger.Warn("Failed to read config.json: %v", err)
+		log.Warn("Failed to read config.json: %v", err)
 		return nil
 	}
 	
 	// Parse JSON
 	var config map[string]interface{}
 	if err := json.Unmarshal(data, &config); err != nil {
-		logger.Warn("Failed to parse config.json: %v", err)
+		log.Warn("Failed to parse config.json: %v", err)
 		return nil
 	}
 	
@@ -461,14 +469,14 @@ func (h *Handler) readGenerationConfig(modelPath string) map[string]interface{}
 	// Read file
 	data, err := os.ReadFile(configPath)
 	if err != nil {
-		logger.Warn("Failed to read generation_config.json: %v", err)
+		log.Warn("Failed to read generation_config.json: %v", err)
 		return nil
 	}
 	
 	// Parse JSON
 	var config map[string]interface{}
 	if err := json.Unmarshal(data, &config); err != nil {
-		logger.Warn("Failed to parse generation_config.json: %v", err)
+		log.Warn("Failed to parse generation_config.json: %v", err)
 		return nil
 	}
 	
@@ -525,7 +533,7 @@ func (h *Handler) readLicenseFile(modelPath string) string {
 // enrichModelsWithDownloadStatus checks the download status of models.
 //
 // This method updates the Status field of each model by checking:
-//   - If .download.lock exists: status = "downloading"
+//   - If a live .download.lock exists: status = "downloading"
 //   - If model directory exists with files: status = "downloaded"
 //   - Otherwise: status = "not_downloaded"
 //
@@ -538,10 +546,9 @@ func (h *Handler) enrichModelsWithDownloadStatus(models *[]api.Model) {
 		// Construct paths for model directory and lock file
 		// ModelScope downloads to: models_dir/Owner/Name structure
 		modelPath := h.getModelPath(modelsDir, (*models)[i].Name)
-		lockPath := filepath.Join(modelPath, ".download.lock")
 		
 		// Check if download is in progress
-		if _, err := os.Stat(lockPath); err == nil {
+		if isDownloadLocked(modelPath) {
 			(*models)[i].Status = "downloading"
 			continue
 		}
@@ -571,6 +578,9 @@ func (h *Handler) enrichModelsWithDownloadStatus(models *[]api.Model) {
 				}
 			}
 				(*models)[i].ModifiedAt = info.ModTime().Format(time.RFC3339)
+				if size, err := getDirSize(modelPath); err == nil {
+					(*models)[i].DiskSize = size
+				}
 			} else {
 				(*models)[i].Status = "not_downloaded"
 			}
@@ -664,7 +674,7 @@ func (h *Handler) readModelfile(modelPath string) (string, bool) {
 	// Read Modelfile content
 	content, err := os.ReadFile(modelfilePath)
 	if err != nil {
-		logger.Warn("Failed to read Modelfile at %s: %v", modelfilePath, err)
+		log.Warn("Failed to read Modelfile at %s: %v", modelfilePath, err)
 		return "", false
 	}
 	
@@ -717,7 +727,7 @@ func (h *Handler) ListDownloadedModels(w http.ResponseWriter, r *http.Request) {
 	// Read directory entries
 	entries, err := os.ReadDir(modelsDir)
 	if err != nil {
-		logger.Error("Failed to read models directory: %v", err)
+		log.Error("Failed to read models directory: %v", err)
 		h.WriteError(w, fmt.Sprintf("Failed to read models directory: %v", err), http.StatusInternalServerError)
 		return
 	}
@@ -740,7 +750,7 @@ func (h *Handler) ListDownloadedModels(w http.ResponseWriter, r *http.Request) {
 		// Look up model spec in registry by ID
 		spec := models.GetModelSpec(modelID)
 		if spec == nil {
-			logger.Warn("Model directory %s not found in registry, skipping", modelID)
+			log.Warn("Model directory %s not found in registry, skipping", modelID)
 			continue
 		}
 
@@ -748,7 +758,7 @@ func (h *Handler) ListDownloadedModels(w http.ResponseWriter, r *http.Request) {
 		modelIDPath := filepath.Join(modelsDir, modelID)
 		tagEntries, err := os.ReadDir(modelIDPath)
 		if err != nil {
-			logger.Warn("Failed to read model directory %s: %v", modelID, err)
+			log.Warn("Failed to read model directory %s: %v", modelID, err)
 			continue
 		}
 
@@ -774,14 +784,14 @@ func (h *Handler) ListDownloadedModels(w http.ResponseWriter, r *http.Request) {
 			// Get directory size
 			size, err := getDirSize(modelPath)
 			if err != nil {
-				logger.Warn("Failed to get size for %s/%s: %v", modelID, tag, err)
+				log.Warn("Failed to get size for %s/%s: %v", modelID, tag, err)
 				size = 0
 			}
 
 			// Get modification time
 			info, err := tagEntry.Info()
 			if err != nil {
-				logger.Warn("Failed to get info for %s/%s: %v", modelID, tag, err)
+				log.Warn("Failed to get info for %s/%s: %v", modelID, tag, err)
 				continue
 			}
 
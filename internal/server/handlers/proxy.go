@@ -4,6 +4,7 @@
 // Anthropic-compatible API handlers. It includes:
 //   - ProxyCore: instance lookup, concurrency management, and HTTP forwarding
 //   - concurrencyManager: semaphore-based per-instance request limiting
+//   - instanceCache: short-lived cache of the instance list used for routing
 //   - Header filtering utilities for hop-by-hop header removal
 //
 // API-format-specific handlers are in separate files:
@@ -14,16 +15,25 @@ package handlers
 import (
 	"bytes"
 	"context"
+	"errors"
 	"fmt"
+	"io"
 	"net/http"
+	"sort"
 	"strconv"
 	"strings"
 	"sync"
+	"syscall"
+	"time"
 
+	"github.com/tsingmaoai/xw-cli/internal/config"
 	"github.com/tsingmaoai/xw-cli/internal/logger"
 	"github.com/tsingmaoai/xw-cli/internal/runtime"
 )
 
+// proxyLog is the inference proxy component logger (XW_LOG=proxy=LEVEL).
+var proxyLog = logger.Named("proxy")
+
 // ---------------------------------------------------------------------------
 // Concurrency management
 // ---------------------------------------------------------------------------
@@ -32,53 +42,256 @@ import (
 //
 // Each instance has a semaphore based on its max_concurrent metadata value,
 // which determines how many concurrent requests it can handle efficiently.
+// Limits can be changed while requests are in flight (see resize).
 type concurrencyManager struct {
 	mu         sync.RWMutex
-	semaphores map[string]chan struct{} // instanceID → semaphore channel
+	semaphores map[string]*semaphore // instanceID → semaphore
+	unlimited  map[string]int        // instanceID → in-flight requests without a limit
+}
+
+// semaphore is a counting semaphore whose limit can be changed.
+//
+// A buffered channel cannot be resized, so the semaphore counts slots under
+// a mutex instead. Waiters block on the wake channel, which is closed and
+// replaced whenever a slot is released or the limit changes.
+type semaphore struct {
+	mu    sync.Mutex
+	limit int
+	inUse int
+	wake  chan struct{}
+}
+
+// newSemaphore creates a semaphore with the given limit.
+func newSemaphore(limit int) *semaphore {
+	return &semaphore{limit: limit, wake: make(chan struct{})}
+}
+
+// acquire takes a slot, blocking until one is free or ctx is done.
+func (s *semaphore) acquire(ctx context.Context) error {
+	for {
+		s.mu.Lock()
+		if s.inUse < s.limit {
+			s.inUse++
+			s.mu.Unlock()
+			return nil
+		}
+		wake := s.wake
+		s.mu.Unlock()
+
+		select {
+		case <-wake:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// release frees a slot and wakes waiters.
+func (s *semaphore) release() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.inUse--
+	s.broadcast()
+}
+
+// setLimit changes the limit and wakes waiters so they re-check it.
+func (s *semaphore) setLimit(limit int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.limit = limit
+	s.broadcast()
+}
+
+// broadcast wakes all waiters. The caller must hold s.mu.
+func (s *semaphore) broadcast() {
+	close(s.wake)
+	s.wake = make(chan struct{})
+}
+
+// usage returns the number of slots in use and the limit.
+func (s *semaphore) usage() (inUse, limit int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.inUse, s.limit
 }
 
 // newConcurrencyManager creates a concurrency manager with an empty semaphore map.
 func newConcurrencyManager() *concurrencyManager {
 	return &concurrencyManager{
-		semaphores: make(map[string]chan struct{}),
+		semaphores: make(map[string]*semaphore),
+		unlimited:  make(map[string]int),
+	}
+}
+
+// trackUnlimited counts an in-flight request for an instance without a
+// concurrency limit, so draining and load balancing still see it.
+// The returned function must be called when the request completes.
+func (cm *concurrencyManager) trackUnlimited(instanceID string) func() {
+	cm.mu.Lock()
+	cm.unlimited[instanceID]++
+	cm.mu.Unlock()
+
+	return func() {
+		cm.mu.Lock()
+		defer cm.mu.Unlock()
+		if cm.unlimited[instanceID]--; cm.unlimited[instanceID] <= 0 {
+			delete(cm.unlimited, instanceID)
+		}
 	}
 }
 
 // acquireSlot acquires a concurrency slot for the given instance.
 // It blocks until a slot is available or the context is cancelled.
+// If the semaphore exists with a different limit, it is resized first.
 // The returned function must be called to release the slot.
 func (cm *concurrencyManager) acquireSlot(ctx context.Context, instanceID string, maxConcurrency int) (func(), error) {
 	cm.mu.Lock()
 	sem, exists := cm.semaphores[instanceID]
 	if !exists {
-		sem = make(chan struct{}, maxConcurrency)
+		sem = newSemaphore(maxConcurrency)
 		cm.semaphores[instanceID] = sem
-		logger.Debug("Created concurrency semaphore for instance %s (max: %d)", instanceID, maxConcurrency)
+		proxyLog.Debug("Created concurrency semaphore for instance %s (max: %d)", instanceID, maxConcurrency)
 	}
 	cm.mu.Unlock()
 
-	select {
-	case sem <- struct{}{}:
-		logger.Debug("Acquired concurrency slot for instance %s", instanceID)
-		return func() {
-			<-sem
-			logger.Debug("Released concurrency slot for instance %s", instanceID)
-		}, nil
-	case <-ctx.Done():
-		return nil, fmt.Errorf("request cancelled while waiting for concurrency slot: %w", ctx.Err())
+	if _, limit := sem.usage(); limit != maxConcurrency {
+		sem.setLimit(maxConcurrency)
+	}
+
+	if err := sem.acquire(ctx); err != nil {
+		return nil, fmt.Errorf("request cancelled while waiting for concurrency slot: %w", err)
+	}
+	proxyLog.Debug("Acquired concurrency slot for instance %s", instanceID)
+	return func() {
+		sem.release()
+		proxyLog.Debug("Released concurrency slot for instance %s", instanceID)
+	}, nil
+}
+
+// resize changes the concurrency limit of an instance.
+//
+// Raising the limit admits waiting requests immediately. Lowering it below
+// the number of requests in flight does not interrupt them; new requests
+// wait until enough of them complete to fall below the new limit. A limit
+// of 0 removes the limit: later requests are only counted, while requests
+// already holding or waiting for a slot keep the old limit until done.
+func (cm *concurrencyManager) resize(instanceID string, maxConcurrency int) {
+	cm.mu.Lock()
+	sem, exists := cm.semaphores[instanceID]
+	if !exists && maxConcurrency > 0 {
+		sem = newSemaphore(maxConcurrency)
+		cm.semaphores[instanceID] = sem
+	}
+	cm.mu.Unlock()
+
+	if exists && maxConcurrency > 0 {
+		sem.setLimit(maxConcurrency)
+	}
+	proxyLog.Debug("Resized concurrency semaphore for instance %s (max: %d)", instanceID, maxConcurrency)
+}
+
+// inFlight returns the number of requests currently being served by the
+// given instance, whether or not it has a concurrency limit.
+func (cm *concurrencyManager) inFlight(instanceID string) int {
+	cm.mu.RLock()
+	defer cm.mu.RUnlock()
+
+	n := cm.unlimited[instanceID]
+	if sem, exists := cm.semaphores[instanceID]; exists {
+		inUse, _ := sem.usage()
+		n += inUse
 	}
+	return n
 }
 
-// cleanupInstance removes the semaphore for a stopped instance.
+// snapshot returns the current in-flight count and capacity of every
+// instance semaphore, keyed by instance ID.
+func (cm *concurrencyManager) snapshot() (inFlight, limits map[string]int) {
+	cm.mu.RLock()
+	defer cm.mu.RUnlock()
+
+	inFlight = make(map[string]int, len(cm.semaphores)+len(cm.unlimited))
+	limits = make(map[string]int, len(cm.semaphores))
+	for id, sem := range cm.semaphores {
+		inFlight[id], limits[id] = sem.usage()
+	}
+	for id, n := range cm.unlimited {
+		inFlight[id] += n
+	}
+	return inFlight, limits
+}
+
+// cleanupInstance removes the semaphore for a removed instance.
 func (cm *concurrencyManager) cleanupInstance(instanceID string) {
 	cm.mu.Lock()
 	defer cm.mu.Unlock()
 
-	if sem, exists := cm.semaphores[instanceID]; exists {
-		close(sem)
+	if _, exists := cm.semaphores[instanceID]; exists {
 		delete(cm.semaphores, instanceID)
-		logger.Debug("Cleaned up concurrency semaphore for instance %s", instanceID)
+		proxyLog.Debug("Cleaned up concurrency semaphore for instance %s", instanceID)
+	}
+}
+
+// ---------------------------------------------------------------------------
+// Instance cache
+// ---------------------------------------------------------------------------
+
+// instanceCacheTTL is how long a listing of instances is reused for routing.
+// Changes made through the runtime manager invalidate the cache at once;
+// the TTL bounds how long changes the manager does not see (a crashed
+// container, an instance becoming ready) take to be noticed.
+const instanceCacheTTL = 2 * time.Second
+
+// instanceCache caches the instance list used to route proxied requests.
+//
+// Listing instances queries every runtime (and Docker behind it), which is
+// too expensive to repeat for every inference request. Routing decisions
+// use the cached list until it expires or is invalidated; model names are
+// still matched against it on each request, so alias rules apply as usual.
+type instanceCache struct {
+	mu         sync.Mutex
+	instances  []*runtime.Instance
+	fetchedAt  time.Time
+	generation uint64 // Incremented by invalidate
+}
+
+// get returns the cached instance list, calling list to refresh it when it
+// has expired.
+//
+// The cached instances are shared between requests and must not be
+// modified. A listing that was in progress while the cache was invalidated
+// is returned to its caller but not cached, so an invalidation is never
+// overwritten by older data.
+func (c *instanceCache) get(ctx context.Context, list func(context.Context) ([]*runtime.Instance, error)) ([]*runtime.Instance, error) {
+	c.mu.Lock()
+	if c.instances != nil && time.Since(c.fetchedAt) < instanceCacheTTL {
+		instances := c.instances
+		c.mu.Unlock()
+		return instances, nil
+	}
+	generation := c.generation
+	c.mu.Unlock()
+
+	instances, err := list(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	if c.generation == generation {
+		c.instances = instances
+		c.fetchedAt = time.Now()
 	}
+	c.mu.Unlock()
+	return instances, nil
+}
+
+// invalidate discards the cached instance list.
+func (c *instanceCache) invalidate() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.instances = nil
+	c.generation++
 }
 
 // ---------------------------------------------------------------------------
@@ -92,66 +305,200 @@ func (cm *concurrencyManager) cleanupInstance(instanceID string) {
 type ProxyCore struct {
 	handler        *Handler
 	concurrencyMgr *concurrencyManager
+	metrics        *proxyMetrics
+	instances      *instanceCache
+
+	// rrCounters holds per-model round-robin positions for SelectInstance,
+	// keyed by the model ID of the selected instances rather than the name a
+	// client sent, so arbitrary request names cannot grow the map.
+	rrMu       sync.Mutex
+	rrCounters map[string]uint64
 }
 
 // newProxyCore creates a new ProxyCore instance.
+//
+// The core registers its in-flight counter with the runtime manager so
+// instances can be drained before they are stopped, and its concurrency
+// manager so live concurrency limit changes take effect immediately. Its
+// instance cache is invalidated whenever the manager changes an instance.
 func newProxyCore(h *Handler) *ProxyCore {
-	return &ProxyCore{
+	pc := &ProxyCore{
 		handler:        h,
 		concurrencyMgr: newConcurrencyManager(),
+		metrics:        newProxyMetrics(),
+		instances:      &instanceCache{},
+		rrCounters:     make(map[string]uint64),
 	}
+	h.runtimeManager.SetInFlightCounter(pc.concurrencyMgr.inFlight)
+	h.runtimeManager.SetConcurrencyListener(pc.concurrencyMgr.resize)
+	h.runtimeManager.SetInstanceListener(pc.instances.invalidate)
+	h.runtimeManager.SetInstanceRemovedListener(pc.forgetInstance)
+	return pc
+}
+
+// forgetInstance drops the metric series and concurrency semaphore of a
+// removed instance.
+func (pc *ProxyCore) forgetInstance(instanceID string) {
+	pc.metrics.removeInstance(instanceID)
+	pc.concurrencyMgr.cleanupInstance(instanceID)
 }
 
 // FindInstanceByModel finds a running instance that serves the specified model.
 //
-// The lookup performs two passes:
+// It returns the first instance reported by FindInstancesByModel and is kept
+// for callers that only need a single instance. Proxy handlers should use
+// SelectInstance so traffic is spread across replicas.
+func (pc *ProxyCore) FindInstanceByModel(ctx context.Context, modelName string) (*runtime.Instance, error) {
+	instances, err := pc.FindInstancesByModel(ctx, modelName)
+	if err != nil {
+		return nil, err
+	}
+	return instances[0], nil
+}
+
+// FindInstancesByModel finds all running instances that serve the specified model.
+//
+// The requested name is first rewritten through the client-facing alias map
+// (aliases.yaml), then matched against running instances in two passes:
 //  1. Exact match on alias (or ModelID as fallback), case-insensitive
 //  2. Prefix match for partial model names (e.g., "qwen2-7b" matches "qwen2-7b-instruct")
-func (pc *ProxyCore) FindInstanceByModel(ctx context.Context, modelName string) (*runtime.Instance, error) {
-	instances, err := pc.handler.runtimeManager.List(ctx)
+//
+// The second pass only runs when the first finds nothing. If neither pass
+// matches and the alias map defines a default, the default instances are
+// returned so unknown client model names still reach a live model.
+//
+// Instances are looked up in the instance cache rather than listed on every
+// request (see instanceCache).
+func (pc *ProxyCore) FindInstancesByModel(ctx context.Context, modelName string) ([]*runtime.Instance, error) {
+	instances, err := pc.instances.get(ctx, pc.handler.runtimeManager.List)
 	if err != nil {
 		return nil, fmt.Errorf("failed to list instances: %w", err)
 	}
 
+	aliases := pc.handler.config.GetModelAliases()
+
+	lookupName := modelName
+	if target, ok := aliases.Resolve(modelName); ok {
+		proxyLog.Debug("Model alias rule maps %s to %s", modelName, target)
+		lookupName = target
+	}
+
+	if matched := matchInstances(instances, lookupName); len(matched) > 0 {
+		return matched, nil
+	}
+
+	if aliases != nil && aliases.Default != "" {
+		if matched := matchInstances(instances, aliases.Default); len(matched) > 0 {
+			proxyLog.Debug("Routing model %s to default target %s", modelName, aliases.Default)
+			return matched, nil
+		}
+	}
+
+	return nil, fmt.Errorf("no running instance found for model: %s", modelName)
+}
+
+// SelectInstance picks one running instance for the specified model.
+//
+// When several instances serve the model (e.g., the same model started on
+// different device groups), the instance with the fewest in-flight requests
+// is chosen, as tracked by the concurrency semaphores. Ties are broken
+// round-robin so instances without concurrency limits still share load.
+func (pc *ProxyCore) SelectInstance(ctx context.Context, modelName string) (*runtime.Instance, error) {
+	candidates, err := pc.FindInstancesByModel(ctx, modelName)
+	if err != nil {
+		return nil, err
+	}
+	if len(candidates) == 1 {
+		return candidates[0], nil
+	}
+
+	// Keep candidate order stable so round-robin is meaningful across calls.
+	sort.Slice(candidates, func(i, j int) bool {
+		return candidates[i].ID < candidates[j].ID
+	})
+
+	least := -1
+	var leastLoaded []*runtime.Instance
+	for _, inst := range candidates {
+		inFlight := pc.concurrencyMgr.inFlight(inst.ID)
+		switch {
+		case least < 0 || inFlight < least:
+			least = inFlight
+			leastLoaded = []*runtime.Instance{inst}
+		case inFlight == least:
+			leastLoaded = append(leastLoaded, inst)
+		}
+	}
+
+	key := strings.ToLower(candidates[0].ModelID)
+	pc.rrMu.Lock()
+	next := pc.rrCounters[key]
+	pc.rrCounters[key] = next + 1
+	pc.rrMu.Unlock()
+
+	selected := leastLoaded[next%uint64(len(leastLoaded))]
+	proxyLog.Debug("Selected instance %s for model %s (%d candidates, %d in flight)",
+		selected.ID, modelName, len(candidates), least)
+	return selected, nil
+}
+
+// matchInstances returns all running instances matching modelName.
+// Exact alias matches take precedence over model ID matches, which take
+// precedence over prefix matches. Matching on the model ID lets requests for
+// a model reach instances started under a different --name. The special
+// name "*" matches every running instance.
+func matchInstances(instances []*runtime.Instance, modelName string) []*runtime.Instance {
 	modelNameLower := strings.ToLower(modelName)
 
-	// Pass 1: exact alias match.
+	// Pass 1: exact alias match (or wildcard).
+	var matched []*runtime.Instance
 	for _, inst := range instances {
 		if inst.State != "running" {
 			continue
 		}
-		alias := inst.Alias
-		if alias == "" {
-			alias = inst.ModelID
-		}
-		if strings.ToLower(alias) == modelNameLower {
-			logger.Debug("Found exact alias match: instance %s (alias: %s) for model %s", inst.ID, alias, modelName)
-			return inst, nil
+		alias := instanceModelName(inst)
+		if modelName == config.ModelAliasAnyInstance || strings.ToLower(alias) == modelNameLower {
+			proxyLog.Debug("Found exact alias match: instance %s (alias: %s) for model %s", inst.ID, alias, modelName)
+			matched = append(matched, inst)
 		}
 	}
+	if len(matched) > 0 {
+		return matched
+	}
 
-	// Pass 2: prefix match.
+	// Pass 2: exact model ID match across named instances.
 	for _, inst := range instances {
 		if inst.State != "running" {
 			continue
 		}
-		alias := inst.Alias
-		if alias == "" {
-			alias = inst.ModelID
+		if strings.ToLower(inst.ModelID) == modelNameLower {
+			proxyLog.Debug("Found model ID match: instance %s (alias: %s) for model %s", inst.ID, inst.Alias, modelName)
+			matched = append(matched, inst)
+		}
+	}
+	if len(matched) > 0 {
+		return matched
+	}
+
+	// Pass 3: prefix match.
+	for _, inst := range instances {
+		if inst.State != "running" {
+			continue
 		}
+		alias := instanceModelName(inst)
 		aliasLower := strings.ToLower(alias)
 		if strings.HasPrefix(aliasLower, modelNameLower) || strings.HasPrefix(modelNameLower, aliasLower) {
-			logger.Debug("Found prefix match: instance %s (alias: %s) for model %s", inst.ID, alias, modelName)
-			return inst, nil
+			proxyLog.Debug("Found prefix match: instance %s (alias: %s) for model %s", inst.ID, alias, modelName)
+			matched = append(matched, inst)
 		}
 	}
 
-	return nil, fmt.Errorf("no running instance found for model: %s", modelName)
+	return matched
 }
 
 // AcquireConcurrency acquires a concurrency slot for the instance if
-// max_concurrent is set in its metadata. Returns a release function (may be nil
-// if concurrency control is not enabled) and an error.
+// max_concurrent is set in its metadata. Requests to instances without a
+// limit are only counted. Returns a release function and an error.
 func (pc *ProxyCore) AcquireConcurrency(ctx context.Context, instance *runtime.Instance) (release func(), err error) {
 	maxConcurrency := 0
 	if v, ok := instance.Metadata["max_concurrent"]; ok && v != "" {
@@ -161,15 +508,15 @@ func (pc *ProxyCore) AcquireConcurrency(ctx context.Context, instance *runtime.I
 	}
 
 	if maxConcurrency <= 0 {
-		logger.Debug("Processing request for instance %s (unlimited concurrency)", instance.ID)
-		return nil, nil
+		proxyLog.Debug("Processing request for instance %s (unlimited concurrency)", instance.ID)
+		return pc.concurrencyMgr.trackUnlimited(instance.ID), nil
 	}
 
 	slot, err := pc.concurrencyMgr.acquireSlot(ctx, instance.ID, maxConcurrency)
 	if err != nil {
 		return nil, err
 	}
-	logger.Debug("Processing request for instance %s (max concurrent: %d)", instance.ID, maxConcurrency)
+	proxyLog.Debug("Processing request for instance %s (max concurrent: %d)", instance.ID, maxConcurrency)
 	return slot, nil
 }
 
@@ -190,7 +537,7 @@ func (pc *ProxyCore) ForwardRequest(ctx context.Context, method, path, query str
 		targetURL += "?" + query
 	}
 
-	logger.Debug("Forwarding to: %s", targetURL)
+	proxyLog.Debug("Forwarding to: %s", targetURL)
 
 	proxyReq, err := http.NewRequestWithContext(ctx, method, targetURL, bytes.NewReader(body))
 	if err != nil {
@@ -210,7 +557,160 @@ func (pc *ProxyCore) ForwardRequest(ctx context.Context, method, path, query str
 		},
 	}
 
-	return client.Do(proxyReq)
+	pc.metrics.recordRequest(instance)
+	resp, err := client.Do(proxyReq)
+	if err != nil {
+		pc.metrics.recordError(instance, "error")
+		// The instance may have exited since it was cached; list again
+		// before routing the next request
+		if errors.Is(err, syscall.ECONNREFUSED) {
+			pc.instances.invalidate()
+		}
+	} else if resp.StatusCode >= 400 {
+		pc.metrics.recordError(instance, strconv.Itoa(resp.StatusCode))
+	}
+	return resp, err
+}
+
+// ObserveDuration records the total duration of a proxied request, measured
+// from start until the response has been fully written to the client.
+func (pc *ProxyCore) ObserveDuration(instance *runtime.Instance, start time.Time) {
+	pc.metrics.observeDuration(instance, time.Since(start))
+}
+
+// Retry defaults for transient backend failures. The per-instance budget
+// can be overridden via the "max_retries" metadata key.
+const (
+	defaultMaxRetries = 2
+	retryBaseDelay    = 200 * time.Millisecond
+	retryMaxDelay     = 2 * time.Second
+)
+
+// ForwardRequestWithRetry forwards a request like ForwardRequest, retrying
+// with exponential backoff when the backend is briefly unavailable.
+//
+// A forward is retried when the connection is refused or the backend answers
+// 502 Bad Gateway or 503 Service Unavailable. Retries happen before anything
+// is written to the client, so they are safe for streaming requests too; the
+// request body is already buffered and is replayed on each attempt.
+//
+// The retry budget is read from instance.Metadata["max_retries"] and defaults
+// to defaultMaxRetries. A value of 0 disables retries.
+func (pc *ProxyCore) ForwardRequestWithRetry(ctx context.Context, method, path, query string, body []byte, srcHeaders http.Header, instance *runtime.Instance) (*http.Response, error) {
+	maxRetries := defaultMaxRetries
+	if v, ok := instance.Metadata["max_retries"]; ok && v != "" {
+		if mr, parseErr := strconv.Atoi(v); parseErr == nil && mr >= 0 {
+			maxRetries = mr
+		}
+	}
+
+	delay := retryBaseDelay
+	for attempt := 0; ; attempt++ {
+		resp, err := pc.ForwardRequest(ctx, method, path, query, body, srcHeaders, instance)
+		if attempt >= maxRetries || !isRetryableForward(resp, err) {
+			return resp, err
+		}
+
+		if err != nil {
+			proxyLog.Warn("Backend %s unreachable (attempt %d/%d): %v", instance.ID, attempt+1, maxRetries+1, err)
+		} else {
+			proxyLog.Warn("Backend %s returned HTTP %d (attempt %d/%d)", instance.ID, resp.StatusCode, attempt+1, maxRetries+1)
+			io.Copy(io.Discard, resp.Body)
+			resp.Body.Close()
+		}
+
+		select {
+		case <-time.After(delay):
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+
+		delay *= 2
+		if delay > retryMaxDelay {
+			delay = retryMaxDelay
+		}
+	}
+}
+
+// isRetryableForward reports whether a forward attempt failed transiently.
+func isRetryableForward(resp *http.Response, err error) bool {
+	if err != nil {
+		return errors.Is(err, syscall.ECONNREFUSED)
+	}
+	return resp.StatusCode == http.StatusBadGateway || resp.StatusCode == http.StatusServiceUnavailable
+}
+
+// ---------------------------------------------------------------------------
+// Request deadlines
+// ---------------------------------------------------------------------------
+
+// requestContext returns the context for forwarding a request to an
+// instance: it is cancelled when the client goes away (see
+// disconnectWriter) or when the proxy's request timeout expires.
+//
+// Streaming and non-streaming requests have separate limits
+// (config.ServerConfig.StreamTimeout and RequestTimeout), so a hung backend
+// does not hold a client connection open forever while long generations
+// can still stream. A zero limit disables the deadline.
+func (pc *ProxyCore) requestContext(r *http.Request, stream bool) (context.Context, context.CancelFunc) {
+	timeout := pc.handler.config.Server.RequestTimeout
+	if stream {
+		timeout = pc.handler.config.Server.StreamTimeout
+	}
+	if timeout <= 0 {
+		return context.WithCancel(r.Context())
+	}
+	return context.WithTimeout(r.Context(), timeout)
+}
+
+// isDeadlineExceeded reports whether a forward failed because the request
+// timeout expired.
+func isDeadlineExceeded(ctx context.Context, err error) bool {
+	return errors.Is(err, context.DeadlineExceeded) || errors.Is(ctx.Err(), context.DeadlineExceeded)
+}
+
+// ---------------------------------------------------------------------------
+// Client disconnect handling
+// ---------------------------------------------------------------------------
+
+// disconnectWriter wraps the client's ResponseWriter and cancels the backend
+// request as soon as a write to the client fails.
+//
+// The server cancels the request context when it notices a closed
+// connection, but a failed write is often the first sign of it, notably
+// while streaming. Cancelling then aborts the backend request at once, so
+// the engine stops generating and the concurrency slot is released instead
+// of being held until the backend finishes on its own.
+type disconnectWriter struct {
+	http.ResponseWriter
+	cancel     context.CancelFunc
+	instanceID string
+	once       sync.Once
+}
+
+// newDisconnectWriter returns w wrapped to call cancel on the first failed
+// write. The result implements http.Flusher if w does.
+func newDisconnectWriter(w http.ResponseWriter, cancel context.CancelFunc, instanceID string) http.ResponseWriter {
+	return &disconnectWriter{ResponseWriter: w, cancel: cancel, instanceID: instanceID}
+}
+
+// Write writes to the client and cancels the backend request on failure.
+func (d *disconnectWriter) Write(p []byte) (int, error) {
+	n, err := d.ResponseWriter.Write(p)
+	if err != nil {
+		d.once.Do(func() {
+			proxyLog.Debug("Client disconnected, cancelling backend request to instance %s", d.instanceID)
+			d.cancel()
+		})
+	}
+	return n, err
+}
+
+// Flush flushes buffered data to the client.
+func (d *disconnectWriter) Flush() {
+	if flusher, ok := d.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
 }
 
 // ---------------------------------------------------------------------------
@@ -2,12 +2,14 @@ package handlers
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
+	"time"
 
 	"github.com/tsingmaoai/xw-cli/internal/apiformat"
-	"github.com/tsingmaoai/xw-cli/internal/logger"
+	"github.com/tsingmaoai/xw-cli/internal/models"
 )
 
 // AnthropicHandler proxies Anthropic Messages API requests to OpenAI-compatible
@@ -53,7 +55,7 @@ func (ah *AnthropicHandler) HandleMessages(w http.ResponseWriter, r *http.Reques
 	// Read and parse the Anthropic request body.
 	bodyBytes, err := io.ReadAll(r.Body)
 	if err != nil {
-		logger.Error("Failed to read Anthropic request body: %v", err)
+		proxyLog.Error("Failed to read Anthropic request body: %v", err)
 		ah.writeAnthropicError(w, http.StatusBadRequest, "invalid_request_error", "Failed to read request body")
 		return
 	}
@@ -61,7 +63,7 @@ func (ah *AnthropicHandler) HandleMessages(w http.ResponseWriter, r *http.Reques
 
 	var req apiformat.MessagesRequest
 	if err := json.Unmarshal(bodyBytes, &req); err != nil {
-		logger.Error("Failed to parse Anthropic request: %v", err)
+		proxyLog.Error("Failed to parse Anthropic request: %v", err)
 		ah.writeAnthropicError(w, http.StatusBadRequest, "invalid_request_error", fmt.Sprintf("Invalid JSON: %v", err))
 		return
 	}
@@ -79,12 +81,12 @@ func (ah *AnthropicHandler) HandleMessages(w http.ResponseWriter, r *http.Reques
 		return
 	}
 
-	logger.Debug("Anthropic API request: model=%s, stream=%v, messages=%d", req.Model, req.Stream, len(req.Messages))
+	proxyLog.Debug("Anthropic API request: model=%s, stream=%v, messages=%d", req.Model, req.Stream, len(req.Messages))
 
 	// Find the backend instance matching the requested model.
-	instance, err := ah.FindInstanceByModel(r.Context(), req.Model)
+	instance, err := ah.SelectInstance(r.Context(), req.Model)
 	if err != nil {
-		logger.Error("No running instance found for model %s: %v", req.Model, err)
+		proxyLog.Error("No running instance found for model %s: %v", req.Model, err)
 		ah.writeAnthropicError(w, http.StatusNotFound, "not_found_error",
 			fmt.Sprintf("No running instance found for model: %s", req.Model))
 		return
@@ -99,7 +101,7 @@ func (ah *AnthropicHandler) HandleMessages(w http.ResponseWriter, r *http.Reques
 	// Acquire a concurrency slot if the instance has limits configured.
 	release, err := ah.AcquireConcurrency(r.Context(), instance)
 	if err != nil {
-		logger.Warn("Concurrency limit reached for instance %s: %v", instance.ID, err)
+		proxyLog.Warn("Concurrency limit reached for instance %s: %v", instance.ID, err)
 		ah.writeAnthropicError(w, http.StatusServiceUnavailable, "overloaded_error",
 			"Service temporarily unavailable (concurrency limit reached)")
 		return
@@ -116,20 +118,38 @@ func (ah *AnthropicHandler) HandleMessages(w http.ResponseWriter, r *http.Reques
 		backendModel = instance.ModelID
 	}
 
-	// Convert the Anthropic request to OpenAI format.
-	openaiBody, err := apiformat.ConvertRequest(&req, backendModel)
+	// Convert the Anthropic request to OpenAI format. Images returned by
+	// tools are only passed to models with the "vision" capability, and tool
+	// results are sent as tool messages only to models with "tool_messages",
+	// whose chat template is known to render them. "tool_use" alone only
+	// says the model calls tools.
+	var convertOpts apiformat.ConvertOptions
+	if spec := models.GetModelSpec(instance.ModelID); spec != nil {
+		convertOpts.ToolResultImages = spec.HasCapability("vision")
+		convertOpts.ToolMessages = spec.HasCapability("tool_messages")
+	}
+	openaiBody, err := apiformat.ConvertRequest(&req, backendModel, convertOpts)
 	if err != nil {
-		logger.Error("Failed to convert Anthropic request to OpenAI format: %v", err)
+		proxyLog.Error("Failed to convert Anthropic request to OpenAI format: %v", err)
 		ah.writeAnthropicError(w, http.StatusBadRequest, "invalid_request_error",
 			fmt.Sprintf("Failed to convert request: %v", err))
 		return
 	}
 
-	logger.Debug("Forwarding to instance %s (port %d) as OpenAI request", instance.ID, instance.Port)
+	proxyLog.Debug("Forwarding to instance %s (port %d) as OpenAI request", instance.ID, instance.Port)
+
+	start := time.Now()
+	defer ah.ObserveDuration(instance, start)
+
+	// Abort the backend request as soon as the client goes away or the
+	// request timeout expires.
+	ctx, cancel := ah.requestContext(r, req.Stream)
+	defer cancel()
+	w = newDisconnectWriter(w, cancel, instance.ID)
 
 	// Forward the converted request to the backend's chat completions endpoint.
-	resp, err := ah.ForwardRequest(
-		r.Context(),
+	resp, err := ah.ForwardRequestWithRetry(
+		ctx,
 		http.MethodPost,
 		"/v1/chat/completions",
 		"",
@@ -137,8 +157,14 @@ func (ah *AnthropicHandler) HandleMessages(w http.ResponseWriter, r *http.Reques
 		r.Header,
 		instance,
 	)
+	if err != nil && isDeadlineExceeded(ctx, err) {
+		proxyLog.Warn("Request to instance %s timed out: %v", instance.ID, err)
+		ah.writeAnthropicError(w, http.StatusGatewayTimeout, "timeout_error",
+			"The model did not respond before the request timeout")
+		return
+	}
 	if err != nil {
-		logger.Error("Backend request failed: %v", err)
+		proxyLog.Error("Backend request failed: %v", err)
 		ah.writeAnthropicError(w, http.StatusBadGateway, "api_error",
 			fmt.Sprintf("Failed to forward request to backend: %v", err))
 		return
@@ -152,9 +178,9 @@ func (ah *AnthropicHandler) HandleMessages(w http.ResponseWriter, r *http.Reques
 	}
 
 	if req.Stream {
-		ah.handleStreamingResponse(w, resp, req.Model)
+		ah.handleStreamingResponse(w, resp, req.Model, req.StopSequences)
 	} else {
-		ah.handleBufferedResponse(w, resp, req.Model)
+		ah.handleBufferedResponse(w, resp, req.Model, req.StopSequences)
 	}
 }
 
@@ -190,7 +216,7 @@ func (ah *AnthropicHandler) HandleCountTokens(w http.ResponseWriter, r *http.Req
 		estimatedTokens = 1
 	}
 
-	logger.Debug("Token count estimate for model %s: ~%d tokens (%d chars)", req.Model, estimatedTokens, charCount)
+	proxyLog.Debug("Token count estimate for model %s: ~%d tokens (%d chars)", req.Model, estimatedTokens, charCount)
 
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusOK)
@@ -200,10 +226,10 @@ func (ah *AnthropicHandler) HandleCountTokens(w http.ResponseWriter, r *http.Req
 }
 
 // handleStreamingResponse converts an OpenAI SSE stream to Anthropic SSE format.
-func (ah *AnthropicHandler) handleStreamingResponse(w http.ResponseWriter, resp *http.Response, requestModel string) {
+func (ah *AnthropicHandler) handleStreamingResponse(w http.ResponseWriter, resp *http.Response, requestModel string, stopSequences []string) {
 	flusher, ok := w.(http.Flusher)
 	if !ok {
-		logger.Error("Response writer does not support flushing for Anthropic streaming")
+		proxyLog.Error("Response writer does not support flushing for Anthropic streaming")
 		ah.writeAnthropicError(w, http.StatusInternalServerError, "api_error", "Streaming not supported")
 		return
 	}
@@ -213,26 +239,31 @@ func (ah *AnthropicHandler) handleStreamingResponse(w http.ResponseWriter, resp
 	w.Header().Set("Connection", "keep-alive")
 	w.WriteHeader(http.StatusOK)
 
-	adapter := apiformat.NewStreamAdapter(requestModel)
+	adapter := apiformat.NewStreamAdapter(requestModel, stopSequences)
 	if err := adapter.Transform(resp.Body, w, flusher); err != nil {
-		logger.Error("Stream transformation error: %v", err)
+		proxyLog.Error("Stream transformation error: %v", err)
 	}
 
-	logger.Debug("Anthropic streaming response completed for model: %s", requestModel)
+	proxyLog.Debug("Anthropic streaming response completed for model: %s", requestModel)
 }
 
 // handleBufferedResponse converts a non-streaming OpenAI response to Anthropic format.
-func (ah *AnthropicHandler) handleBufferedResponse(w http.ResponseWriter, resp *http.Response, requestModel string) {
+func (ah *AnthropicHandler) handleBufferedResponse(w http.ResponseWriter, resp *http.Response, requestModel string, stopSequences []string) {
 	respBody, err := io.ReadAll(resp.Body)
 	if err != nil {
-		logger.Error("Failed to read backend response: %v", err)
+		proxyLog.Error("Failed to read backend response: %v", err)
 		ah.writeAnthropicError(w, http.StatusBadGateway, "api_error", "Failed to read backend response")
 		return
 	}
 
-	anthropicResp, err := apiformat.ConvertResponse(respBody, requestModel)
+	anthropicResp, err := apiformat.ConvertResponse(respBody, requestModel, stopSequences)
+	if errors.Is(err, apiformat.ErrMultipleChoices) {
+		proxyLog.Error("Cannot convert backend response: %v", err)
+		ah.writeAnthropicError(w, http.StatusBadGateway, "api_error", err.Error())
+		return
+	}
 	if err != nil {
-		logger.Error("Failed to convert OpenAI response to Anthropic format: %v", err)
+		proxyLog.Error("Failed to convert OpenAI response to Anthropic format: %v", err)
 		ah.writeAnthropicError(w, http.StatusInternalServerError, "api_error",
 			fmt.Sprintf("Failed to convert response: %v", err))
 		return
@@ -242,7 +273,7 @@ func (ah *AnthropicHandler) handleBufferedResponse(w http.ResponseWriter, resp *
 	w.WriteHeader(http.StatusOK)
 	json.NewEncoder(w).Encode(anthropicResp)
 
-	logger.Debug("Anthropic buffered response completed for model: %s", requestModel)
+	proxyLog.Debug("Anthropic buffered response completed for model: %s", requestModel)
 }
 
 // forwardBackendError translates a backend HTTP error into an Anthropic-style
@@ -268,7 +299,7 @@ func (ah *AnthropicHandler) forwardBackendError(w http.ResponseWriter, resp *htt
 		}
 	}
 
-	logger.Error("Backend error (HTTP %d): %s", resp.StatusCode, errMsg)
+	proxyLog.Error("Backend error (HTTP %d): %s", resp.StatusCode, errMsg)
 	ah.writeAnthropicError(w, resp.StatusCode, "api_error", errMsg)
 }
 
@@ -0,0 +1,253 @@
+package handlers
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/tsingmaoai/xw-cli/internal/runtime"
+)
+
+// ---------------------------------------------------------------------------
+// Proxy metrics (Prometheus text exposition format)
+// ---------------------------------------------------------------------------
+
+// durationBuckets are the histogram upper bounds, in seconds, for proxied
+// request durations. Inference requests range from sub-second embeddings to
+// multi-minute generations, so the buckets are spread accordingly.
+var durationBuckets = []float64{0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30, 60, 120, 300}
+
+// instanceKey identifies a model instance in metric labels.
+type instanceKey struct {
+	model      string
+	instanceID string
+}
+
+// errorKey identifies a backend error series in metric labels.
+type errorKey struct {
+	instanceKey
+	status string
+}
+
+// histogram is a cumulative Prometheus-style histogram.
+type histogram struct {
+	counts []uint64 // per-bucket counts (non-cumulative), one per durationBuckets entry
+	count  uint64
+	sum    float64
+}
+
+// proxyMetrics collects proxy traffic metrics.
+//
+// The metrics are written in the Prometheus text exposition format by
+// writeTo, so no client library is required.
+type proxyMetrics struct {
+	mu        sync.Mutex
+	requests  map[instanceKey]uint64
+	errors    map[errorKey]uint64
+	durations map[instanceKey]*histogram
+	models    map[string]string // instanceID → model, for in-flight gauges
+}
+
+// newProxyMetrics creates an empty metrics collector.
+func newProxyMetrics() *proxyMetrics {
+	return &proxyMetrics{
+		requests:  make(map[instanceKey]uint64),
+		errors:    make(map[errorKey]uint64),
+		durations: make(map[instanceKey]*histogram),
+		models:    make(map[string]string),
+	}
+}
+
+// keyFor builds the metric label key for an instance.
+func keyFor(instance *runtime.Instance) instanceKey {
+	return instanceKey{model: instanceModelName(instance), instanceID: instance.ID}
+}
+
+// recordRequest counts one request forwarded to the instance.
+func (m *proxyMetrics) recordRequest(instance *runtime.Instance) {
+	key := keyFor(instance)
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.requests[key]++
+	m.models[key.instanceID] = key.model
+}
+
+// recordError counts one backend error. status is the HTTP status code,
+// or "error" when no response was received.
+func (m *proxyMetrics) recordError(instance *runtime.Instance, status string) {
+	key := errorKey{instanceKey: keyFor(instance), status: status}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.errors[key]++
+}
+
+// observeDuration records the total duration of a proxied request.
+func (m *proxyMetrics) observeDuration(instance *runtime.Instance, d time.Duration) {
+	key := keyFor(instance)
+	seconds := d.Seconds()
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	h, ok := m.durations[key]
+	if !ok {
+		h = &histogram{counts: make([]uint64, len(durationBuckets))}
+		m.durations[key] = h
+	}
+	for i, bound := range durationBuckets {
+		if seconds <= bound {
+			h.counts[i]++
+			break
+		}
+	}
+	h.count++
+	h.sum += seconds
+}
+
+// removeInstance deletes all series of an instance, so the metrics of
+// removed instances do not accumulate over the server's lifetime.
+func (m *proxyMetrics) removeInstance(instanceID string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for key := range m.requests {
+		if key.instanceID == instanceID {
+			delete(m.requests, key)
+		}
+	}
+	for key := range m.errors {
+		if key.instanceID == instanceID {
+			delete(m.errors, key)
+		}
+	}
+	for key := range m.durations {
+		if key.instanceID == instanceID {
+			delete(m.durations, key)
+		}
+	}
+	delete(m.models, instanceID)
+}
+
+// writeTo writes all metrics in Prometheus text format. In-flight and limit
+// gauges are derived from the concurrency semaphores at scrape time.
+func (m *proxyMetrics) writeTo(w io.Writer, cm *concurrencyManager) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	fmt.Fprintln(w, "# HELP xw_proxy_requests_total Total requests forwarded to inference instances.")
+	fmt.Fprintln(w, "# TYPE xw_proxy_requests_total counter")
+	for _, key := range sortedInstanceKeys(m.requests) {
+		fmt.Fprintf(w, "xw_proxy_requests_total{%s} %d\n", key.labels(), m.requests[key])
+	}
+
+	fmt.Fprintln(w, "# HELP xw_proxy_backend_errors_total Backend errors by HTTP status (\"error\" when no response).")
+	fmt.Fprintln(w, "# TYPE xw_proxy_backend_errors_total counter")
+	errorKeys := make([]errorKey, 0, len(m.errors))
+	for key := range m.errors {
+		errorKeys = append(errorKeys, key)
+	}
+	sort.Slice(errorKeys, func(i, j int) bool {
+		if errorKeys[i].instanceKey != errorKeys[j].instanceKey {
+			return errorKeys[i].instanceKey.less(errorKeys[j].instanceKey)
+		}
+		return errorKeys[i].status < errorKeys[j].status
+	})
+	for _, key := range errorKeys {
+		fmt.Fprintf(w, "xw_proxy_backend_errors_total{%s,status=%q} %d\n", key.labels(), key.status, m.errors[key])
+	}
+
+	fmt.Fprintln(w, "# HELP xw_proxy_request_duration_seconds Duration of proxied requests including response streaming.")
+	fmt.Fprintln(w, "# TYPE xw_proxy_request_duration_seconds histogram")
+	for _, key := range sortedInstanceKeys(m.durations) {
+		h := m.durations[key]
+		var cumulative uint64
+		for i, bound := range durationBuckets {
+			cumulative += h.counts[i]
+			fmt.Fprintf(w, "xw_proxy_request_duration_seconds_bucket{%s,le=%q} %d\n",
+				key.labels(), strconv.FormatFloat(bound, 'g', -1, 64), cumulative)
+		}
+		fmt.Fprintf(w, "xw_proxy_request_duration_seconds_bucket{%s,le=\"+Inf\"} %d\n", key.labels(), h.count)
+		fmt.Fprintf(w, "xw_proxy_request_duration_seconds_sum{%s} %g\n", key.labels(), h.sum)
+		fmt.Fprintf(w, "xw_proxy_request_duration_seconds_count{%s} %d\n", key.labels(), h.count)
+	}
+
+	inFlight, limits := cm.snapshot()
+	instanceIDs := make([]string, 0, len(inFlight))
+	for id := range inFlight {
+		instanceIDs = append(instanceIDs, id)
+	}
+	sort.Strings(instanceIDs)
+
+	fmt.Fprintln(w, "# HELP xw_proxy_inflight_requests Requests currently being served per instance.")
+	fmt.Fprintln(w, "# TYPE xw_proxy_inflight_requests gauge")
+	for _, id := range instanceIDs {
+		key := instanceKey{model: m.models[id], instanceID: id}
+		fmt.Fprintf(w, "xw_proxy_inflight_requests{%s} %d\n", key.labels(), inFlight[id])
+	}
+
+	fmt.Fprintln(w, "# HELP xw_proxy_concurrency_limit Maximum concurrent requests allowed per instance (0 for unlimited).")
+	fmt.Fprintln(w, "# TYPE xw_proxy_concurrency_limit gauge")
+	for _, id := range instanceIDs {
+		key := instanceKey{model: m.models[id], instanceID: id}
+		fmt.Fprintf(w, "xw_proxy_concurrency_limit{%s} %d\n", key.labels(), limits[id])
+	}
+}
+
+// labels formats the instance key as Prometheus labels.
+func (k instanceKey) labels() string {
+	return fmt.Sprintf("model=%q,instance_id=%q", escapeLabel(k.model), escapeLabel(k.instanceID))
+}
+
+// less orders instance keys by model, then instance ID.
+func (k instanceKey) less(other instanceKey) bool {
+	if k.model != other.model {
+		return k.model < other.model
+	}
+	return k.instanceID < other.instanceID
+}
+
+// sortedInstanceKeys returns the map keys in stable output order.
+func sortedInstanceKeys[V any](m map[instanceKey]V) []instanceKey {
+	keys := make([]instanceKey, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool { return keys[i].less(keys[j]) })
+	return keys
+}
+
+// escapeLabel removes the non-printable characters from a label value
+// before it is quoted with %q. Prometheus label values only support the
+// \\, \" and \n escapes, while %q writes other non-printable characters
+// as \t, \x or \u escapes.
+func escapeLabel(v string) string {
+	return strings.Map(func(r rune) rune {
+		if !strconv.IsPrint(r) {
+			return -1
+		}
+		return r
+	}, v)
+}
+
+// HandleMetrics handles GET /metrics requests.
+//
+// It exposes proxy traffic metrics in the Prometheus text exposition format:
+//   - xw_proxy_requests_total: requests forwarded per model and instance
+//   - xw_proxy_backend_errors_total: backend errors by status
+//   - xw_proxy_request_duration_seconds: request duration histogram
+//   - xw_proxy_inflight_requests / xw_proxy_concurrency_limit: semaphore usage
+func (pc *ProxyCore) HandleMetrics(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	w.WriteHeader(http.StatusOK)
+	pc.metrics.writeTo(w, pc.concurrencyMgr)
+}
@@ -0,0 +1,134 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/tsingmaoai/xw-cli/internal/models"
+	"github.com/tsingmaoai/xw-cli/internal/runtime"
+)
+
+// modelfileDefaultsHeader is the request header with which a client asks
+// the proxy to apply the model's Modelfile defaults ("true" or "1").
+const modelfileDefaultsHeader = "X-XW-Modelfile-Defaults"
+
+// modelfileRequestParams maps Modelfile PARAMETER names to OpenAI request
+// fields. Names follow Ollama; parameters without a per-request equivalent
+// (e.g., num_ctx) are not listed and never applied.
+var modelfileRequestParams = map[string]string{
+	"temperature":       "temperature",
+	"top_p":             "top_p",
+	"top_k":             "top_k",
+	"min_p":             "min_p",
+	"seed":              "seed",
+	"stop":              "stop",
+	"presence_penalty":  "presence_penalty",
+	"frequency_penalty": "frequency_penalty",
+	"repeat_penalty":    "repetition_penalty",
+	"num_predict":       "max_tokens",
+}
+
+// wantsModelfileDefaults reports whether a request opted in to Modelfile
+// defaults.
+//
+// Applying them is opt-in because a generated Modelfile carries a generic
+// SYSTEM prompt, which clients that manage their own conversations (agents,
+// benchmarks) must not receive unasked.
+func wantsModelfileDefaults(r *http.Request) bool {
+	switch strings.ToLower(strings.TrimSpace(r.Header.Get(modelfileDefaultsHeader))) {
+	case "true", "1", "yes":
+		return true
+	}
+	return false
+}
+
+// applyModelfileDefaults fills a chat or text completion request with the
+// defaults from the Modelfile of the instance's model, as edited with
+// 'xw edit'.
+//
+// The SYSTEM prompt is added as the first message of a chat request that
+// has no system message, and PARAMETER values are set for the request
+// fields the client left out. Anything the client set is kept. TEMPLATE is
+// not applied: engines format prompts with the model's own chat template.
+//
+// Parameters:
+//   - path: Request path (/v1/chat/completions or /v1/completions)
+//   - body: Request body
+//   - instance: Instance serving the request
+//
+// Returns:
+//   - Request body with the defaults applied, or body unchanged if the model
+//     has no Modelfile, nothing applies, or the body cannot be rewritten
+func (pc *ProxyCore) applyModelfileDefaults(path string, body []byte, instance *runtime.Instance) []byte {
+	chat := path == "/v1/chat/completions"
+	if !chat && path != "/v1/completions" {
+		return body
+	}
+
+	h := pc.handler
+	content, exists := h.readModelfile(h.getModelPath(h.config.Storage.GetModelsDir(), instance.ModelID))
+	if !exists {
+		return body
+	}
+
+	var req map[string]interface{}
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.UseNumber() // Keep the client's numbers as written
+	if err := decoder.Decode(&req); err != nil {
+		return body
+	}
+
+	changed := false
+	for param, value := range models.ModelfileParameters(content) {
+		field, ok := modelfileRequestParams[param]
+		if !ok {
+			continue
+		}
+		if _, set := req[field]; set {
+			continue
+		}
+		// num_predict -1 (unlimited) has no max_tokens equivalent
+		if n, isInt := value.(int); isInt && field == "max_tokens" && n <= 0 {
+			continue
+		}
+		req[field] = value
+		changed = true
+	}
+
+	if system := models.ModelfileDirectiveValue(content, "SYSTEM"); chat && system != "" {
+		if messages, ok := req["messages"].([]interface{}); ok && !hasSystemMessage(messages) {
+			req["messages"] = append([]interface{}{
+				map[string]interface{}{"role": "system", "content": system},
+			}, messages...)
+			changed = true
+		}
+	}
+
+	if !changed {
+		return body
+	}
+	rewritten, err := json.Marshal(req)
+	if err != nil {
+		proxyLog.Warn("Failed to apply Modelfile defaults for %s: %v", instance.ModelID, err)
+		return body
+	}
+	proxyLog.Debug("Applied Modelfile defaults of %s to request", instance.ModelID)
+	return rewritten
+}
+
+// hasSystemMessage reports whether chat messages include a system (or
+// developer) message.
+func hasSystemMessage(messages []interface{}) bool {
+	for _, m := range messages {
+		msg, ok := m.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		if role, _ := msg["role"].(string); role == "system" || role == "developer" {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,232 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/tsingmaoai/xw-cli/internal/apiformat"
+	"github.com/tsingmaoai/xw-cli/internal/models"
+	"github.com/tsingmaoai/xw-cli/internal/runtime"
+)
+
+// modelsOwner is reported as the owner of every model in /v1/models responses.
+const modelsOwner = "xw"
+
+// ListRunningInstances returns all running instances, sorted by their
+// client-facing model name for stable output.
+func (pc *ProxyCore) ListRunningInstances(ctx context.Context) ([]*runtime.Instance, error) {
+	instances, err := pc.handler.runtimeManager.List(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list instances: %w", err)
+	}
+
+	running := make([]*runtime.Instance, 0, len(instances))
+	for _, inst := range instances {
+		if inst.State == "running" {
+			running = append(running, inst)
+		}
+	}
+
+	sort.Slice(running, func(i, j int) bool {
+		return instanceModelName(running[i]) < instanceModelName(running[j])
+	})
+	return running, nil
+}
+
+// HandleListModels handles GET /v1/models requests.
+//
+// Both OpenAI SDKs and Anthropic clients probe this endpoint before sending
+// inference requests. Each running instance is reported as one model whose ID
+// is the instance alias (or ModelID when no alias is set). Requests carrying
+// an "anthropic-version" header receive the Anthropic list shape; all other
+// requests receive the OpenAI {"object":"list","data":[...]} shape.
+func (pc *ProxyCore) HandleListModels(w http.ResponseWriter, r *http.Request) {
+	anthropic := r.Header.Get("anthropic-version") != ""
+
+	if r.Method != http.MethodGet {
+		writeModelsError(w, anthropic, http.StatusMethodNotAllowed, "Only GET method is allowed")
+		return
+	}
+
+	instances, err := pc.ListRunningInstances(r.Context())
+	if err != nil {
+		proxyLog.Error("Failed to list running instances: %v", err)
+		writeModelsError(w, anthropic, http.StatusInternalServerError, "Failed to list models")
+		return
+	}
+
+	var response any
+	if anthropic {
+		response = buildAnthropicModelList(instances)
+	} else {
+		response = buildOpenAIModelList(instances)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(response)
+}
+
+// HandleGetModel handles GET /v1/models/{id} requests.
+//
+// Some SDKs retrieve a single model to check it exists and read its
+// capabilities. The model is looked up among running instances by its
+// client-facing name (see instanceModelName); the OpenAI object carries the
+// context length from the model spec and the server name as owner. Requests
+// carrying an "anthropic-version" header receive the Anthropic model shape.
+// If no running instance matches, 404 is returned in the client's error
+// format.
+func (pc *ProxyCore) HandleGetModel(w http.ResponseWriter, r *http.Request) {
+	anthropic := r.Header.Get("anthropic-version") != ""
+
+	if r.Method != http.MethodGet {
+		writeModelsError(w, anthropic, http.StatusMethodNotAllowed, "Only GET method is allowed")
+		return
+	}
+
+	modelName := strings.TrimPrefix(r.URL.Path, "/v1/models/")
+	if modelName == "" {
+		pc.HandleListModels(w, r)
+		return
+	}
+
+	instances, err := pc.ListRunningInstances(r.Context())
+	if err != nil {
+		proxyLog.Error("Failed to list running instances: %v", err)
+		writeModelsError(w, anthropic, http.StatusInternalServerError, "Failed to get model")
+		return
+	}
+
+	var instance *runtime.Instance
+	for _, inst := range instances {
+		if instanceModelName(inst) == modelName {
+			instance = inst
+			break
+		}
+	}
+	if instance == nil {
+		message := fmt.Sprintf("The model '%s' does not exist or is not running", modelName)
+		if anthropic {
+			writeAnthropicModelsError(w, http.StatusNotFound, "not_found_error", message)
+		} else {
+			writeOpenAIError(w, http.StatusNotFound, "invalid_request_error", "model_not_found", message)
+		}
+		return
+	}
+
+	var response any
+	if anthropic {
+		response = buildAnthropicModelInfo(instance)
+	} else {
+		model := buildOpenAIModel(instance)
+		model.OwnedBy = pc.handler.config.Server.Name
+		if model.OwnedBy == "" {
+			model.OwnedBy = modelsOwner
+		}
+		if spec := models.GetModelSpec(instance.ModelID); spec != nil {
+			model.ContextLength = spec.ContextLength
+		}
+		response = model
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(response)
+}
+
+// buildOpenAIModel converts a running instance to an OpenAI model object.
+func buildOpenAIModel(inst *runtime.Instance) apiformat.OpenAIModel {
+	return apiformat.OpenAIModel{
+		ID:      instanceModelName(inst),
+		Object:  "model",
+		Created: inst.CreatedAt.Unix(),
+		OwnedBy: modelsOwner,
+	}
+}
+
+// buildAnthropicModelInfo converts a running instance to an Anthropic model object.
+func buildAnthropicModelInfo(inst *runtime.Instance) apiformat.ModelInfo {
+	name := instanceModelName(inst)
+	return apiformat.ModelInfo{
+		Type:        "model",
+		ID:          name,
+		DisplayName: name,
+		CreatedAt:   inst.CreatedAt.UTC().Format(time.RFC3339),
+	}
+}
+
+// buildOpenAIModelList converts running instances to the OpenAI models list.
+func buildOpenAIModelList(instances []*runtime.Instance) apiformat.OpenAIModelList {
+	list := apiformat.OpenAIModelList{
+		Object: "list",
+		Data:   make([]apiformat.OpenAIModel, 0, len(instances)),
+	}
+	for _, inst := range instances {
+		list.Data = append(list.Data, buildOpenAIModel(inst))
+	}
+	return list
+}
+
+// buildAnthropicModelList converts running instances to the Anthropic models list.
+func buildAnthropicModelList(instances []*runtime.Instance) apiformat.ModelList {
+	list := apiformat.ModelList{
+		Data: make([]apiformat.ModelInfo, 0, len(instances)),
+	}
+	for _, inst := range instances {
+		list.Data = append(list.Data, buildAnthropicModelInfo(inst))
+	}
+	if len(list.Data) > 0 {
+		list.FirstID = list.Data[0].ID
+		list.LastID = list.Data[len(list.Data)-1].ID
+	}
+	return list
+}
+
+// writeModelsError writes an error in the API format the client expects.
+func writeModelsError(w http.ResponseWriter, anthropic bool, statusCode int, message string) {
+	if !anthropic {
+		http.Error(w, message, statusCode)
+		return
+	}
+	writeAnthropicModelsError(w, statusCode, "api_error", message)
+}
+
+// writeAnthropicModelsError writes an Anthropic error envelope.
+func writeAnthropicModelsError(w http.ResponseWriter, statusCode int, errorType, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+	json.NewEncoder(w).Encode(apiformat.AnthropicError{
+		Type: "error",
+		Error: apiformat.AnthropicErrorBody{
+			Type:    errorType,
+			Message: message,
+		},
+	})
+}
+
+// writeOpenAIError writes an OpenAI error envelope.
+func writeOpenAIError(w http.ResponseWriter, statusCode int, errorType, code, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+	json.NewEncoder(w).Encode(apiformat.OpenAIError{
+		Error: apiformat.OpenAIErrorBody{
+			Message: message,
+			Type:    errorType,
+			Code:    code,
+		},
+	})
+}
+
+// instanceModelName returns the client-facing model name of an instance:
+// its alias, or its ModelID when no alias is set.
+func instanceModelName(inst *runtime.Instance) string {
+	if inst.Alias != "" {
+		return inst.Alias
+	}
+	return inst.ModelID
+}
@@ -7,10 +7,12 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"strconv"
 	"strings"
+	"sync"
 	"time"
 
-	"github.com/tsingmaoai/xw-cli/internal/logger"
+	"github.com/tsingmaoai/xw-cli/internal/models"
 )
 
 // ---------------------------------------------------------------------------
@@ -49,18 +51,21 @@ type minimalRequest struct {
 //   - POST /v1/embeddings       — Embeddings (non-streaming only)
 //
 // The proxy preserves HTTP semantics including request/response headers,
-// status codes, and streaming vs buffered transfer modes.
+// status codes, and streaming vs buffered transfer modes. Requests sent
+// with the X-XW-Modelfile-Defaults header get the model's Modelfile system
+// prompt and parameters where they leave them out (see
+// applyModelfileDefaults).
 func (p *ProxyHandler) ProxyRequest(w http.ResponseWriter, r *http.Request) {
 	if !strings.HasPrefix(r.URL.Path, "/v1/") {
 		http.Error(w, "Invalid API path. Expected OpenAI-compatible format: /v1/{endpoint}", http.StatusBadRequest)
 		return
 	}
 
-	logger.Debug("Proxying OpenAI API request: %s %s", r.Method, r.URL.Path)
+	proxyLog.Debug("Proxying OpenAI API request: %s %s", r.Method, r.URL.Path)
 
 	bodyBytes, err := io.ReadAll(r.Body)
 	if err != nil {
-		logger.Error("Failed to read request body: %v", err)
+		proxyLog.Error("Failed to read request body: %v", err)
 		http.Error(w, "Failed to read request body", http.StatusInternalServerError)
 		return
 	}
@@ -69,7 +74,7 @@ func (p *ProxyHandler) ProxyRequest(w http.ResponseWriter, r *http.Request) {
 	var minReq minimalRequest
 	if len(bodyBytes) > 0 {
 		if err := json.NewDecoder(bytes.NewReader(bodyBytes)).Decode(&minReq); err != nil {
-			logger.Error("Failed to parse request body: %v", err)
+			proxyLog.Error("Failed to parse request body: %v", err)
 			http.Error(w, "Invalid request body: must be valid JSON", http.StatusBadRequest)
 			return
 		}
@@ -80,26 +85,30 @@ func (p *ProxyHandler) ProxyRequest(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	logger.Debug("Request model: %s, streaming: %v", minReq.Model, minReq.Stream)
+	proxyLog.Debug("Request model: %s, streaming: %v", minReq.Model, minReq.Stream)
 
-	instance, err := p.FindInstanceByModel(r.Context(), minReq.Model)
+	instance, err := p.SelectInstance(r.Context(), minReq.Model)
 	if err != nil {
-		logger.Error("No running instance found for model %s: %v", minReq.Model, err)
+		proxyLog.Error("No running instance found for model %s: %v", minReq.Model, err)
 		http.Error(w, fmt.Sprintf("No running instance found for model: %s", minReq.Model), http.StatusNotFound)
 		return
 	}
 
 	if instance.State != "running" {
-		logger.Warn("Instance %s is not running (state: %s)", instance.ID, instance.State)
+		proxyLog.Warn("Instance %s is not running (state: %s)", instance.ID, instance.State)
 		http.Error(w, fmt.Sprintf("Model instance is not running (state: %s)", instance.State), http.StatusServiceUnavailable)
 		return
 	}
 
-	logger.Debug("Routing to instance %s on port %d", instance.ID, instance.Port)
+	proxyLog.Debug("Routing to instance %s on port %d", instance.ID, instance.Port)
+
+	if wantsModelfileDefaults(r) {
+		bodyBytes = p.applyModelfileDefaults(r.URL.Path, bodyBytes, instance)
+	}
 
 	release, err := p.AcquireConcurrency(r.Context(), instance)
 	if err != nil {
-		logger.Warn("Failed to acquire concurrency slot for instance %s: %v", instance.ID, err)
+		proxyLog.Warn("Failed to acquire concurrency slot for instance %s: %v", instance.ID, err)
 		http.Error(w, "Service temporarily unavailable (concurrency limit reached)", http.StatusServiceUnavailable)
 		return
 	}
@@ -107,14 +116,43 @@ func (p *ProxyHandler) ProxyRequest(w http.ResponseWriter, r *http.Request) {
 		defer release()
 	}
 
-	resp, err := p.ForwardRequest(r.Context(), r.Method, r.URL.Path, r.URL.RawQuery, bodyBytes, r.Header, instance)
+	start := time.Now()
+	defer p.ObserveDuration(instance, start)
+
+	// Abort the backend request as soon as the client goes away or the
+	// request timeout expires
+	ctx, cancel := p.requestContext(r, minReq.Stream)
+	defer cancel()
+	w = newDisconnectWriter(w, cancel, instance.ID)
+
+	resp, err := p.ForwardRequestWithRetry(ctx, r.Method, r.URL.Path, r.URL.RawQuery, bodyBytes, r.Header, instance)
+	if err != nil && isDeadlineExceeded(ctx, err) {
+		proxyLog.Warn("Request to instance %s timed out: %v", instance.ID, err)
+		writeOpenAIError(w, http.StatusGatewayTimeout, "timeout", "timeout",
+			"The model did not respond before the request timeout")
+		return
+	}
 	if err != nil {
-		logger.Error("Proxy request failed: %v", err)
+		proxyLog.Error("Proxy request failed: %v", err)
 		http.Error(w, fmt.Sprintf("Failed to forward request: %v", err), http.StatusBadGateway)
 		return
 	}
 	defer resp.Body.Close()
 
+	// Errors are returned as a complete body whatever the stream flag, so
+	// clients see the backend's explanation rather than a broken SSE stream
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		if r.URL.Path == "/v1/embeddings" && resp.StatusCode < 500 && !supportsEmbeddings(instance.ModelID) {
+			proxyLog.Warn("Instance %s rejected an embeddings request with status %d; model %s lacks the embedding capability",
+				instance.ID, resp.StatusCode, minReq.Model)
+			writeOpenAIError(w, http.StatusBadRequest, "invalid_request_error", "model_not_supported",
+				fmt.Sprintf("Model %s does not support embeddings", minReq.Model))
+			return
+		}
+		handleOpenAIErrorResponse(w, resp, instance.ID)
+		return
+	}
+
 	copyResponseHeaders(resp.Header, w.Header())
 	w.WriteHeader(resp.StatusCode)
 
@@ -124,51 +162,148 @@ func (p *ProxyHandler) ProxyRequest(w http.ResponseWriter, r *http.Request) {
 		handleOpenAIBufferedResponse(w, resp.Body)
 	}
 
-	logger.Debug("Proxy request completed successfully for instance: %s", instance.ID)
+	proxyLog.Debug("Proxy request completed successfully for instance: %s", instance.ID)
+}
+
+// embeddingCapability is the models.yaml capability marking models that
+// serve /v1/embeddings.
+const embeddingCapability = "embedding"
+
+// supportsEmbeddings reports whether a model is declared to serve
+// embeddings requests.
+//
+// A chat-only backend answers /v1/embeddings with an error that does not
+// say why, so the proxy replaces a rejection from a model without the
+// capability with one that does. Requests are still forwarded: the
+// capability is not required, as models.yaml may not tag every model an
+// engine can serve embeddings for. Models without a registered spec (e.g.,
+// removed from models.yaml while running) keep the backend's error.
+func supportsEmbeddings(modelID string) bool {
+	spec := models.GetModelSpec(modelID)
+	return spec == nil || spec.HasCapability(embeddingCapability)
 }
 
+// streamKeepAliveInterval is how often an SSE comment is sent while a
+// streaming request waits for its first chunk.
+const streamKeepAliveInterval = 15 * time.Second
+
 // handleOpenAIStreamingResponse forwards an OpenAI SSE stream to the client
 // with immediate flushing after each chunk for low-latency delivery.
+//
+// Until the first chunk arrives, which can take long while the model warms
+// up or processes a large prompt, a ": ping" comment is sent every
+// streamKeepAliveInterval so reverse proxies do not close the idle
+// connection. SSE clients ignore comment lines.
 func handleOpenAIStreamingResponse(w http.ResponseWriter, body io.ReadCloser) {
 	flusher, ok := w.(http.Flusher)
 	if !ok {
-		logger.Error("Response writer does not support flushing")
+		proxyLog.Error("Response writer does not support flushing")
 		http.Error(w, "Streaming not supported", http.StatusInternalServerError)
 		return
 	}
 
+	stopKeepAlive := startStreamKeepAlive(w, flusher, streamKeepAliveInterval)
+	defer stopKeepAlive()
+
 	reader := bufio.NewReader(body)
 	buf := make([]byte, 4096)
 
 	for {
 		n, err := reader.Read(buf)
 		if n > 0 {
+			stopKeepAlive()
 			if _, writeErr := w.Write(buf[:n]); writeErr != nil {
-				logger.Debug("Client disconnected during streaming: %v", writeErr)
+				proxyLog.Debug("Client disconnected during streaming: %v", writeErr)
 				return
 			}
 			flusher.Flush()
 		}
 		if err != nil {
 			if err == io.EOF {
-				logger.Debug("Stream completed successfully")
+				proxyLog.Debug("Stream completed successfully")
 			} else {
-				logger.Debug("Stream interrupted: %v", err)
+				proxyLog.Debug("Stream interrupted: %v", err)
 			}
 			return
 		}
 	}
 }
 
+// startStreamKeepAlive writes an SSE ping comment every interval until the
+// returned function is called. The function waits for the pinging goroutine
+// to exit, so the caller can write to w afterwards; it may be called more
+// than once.
+func startStreamKeepAlive(w http.ResponseWriter, flusher http.Flusher, interval time.Duration) func() {
+	done := make(chan struct{})
+	exited := make(chan struct{})
+
+	go func() {
+		defer close(exited)
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				if _, err := io.WriteString(w, ": ping\n\n"); err != nil {
+					return
+				}
+				flusher.Flush()
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			close(done)
+			<-exited
+		})
+	}
+}
+
+// maxLoggedErrorBody is the number of bytes of a backend error body that
+// are written to the log.
+const maxLoggedErrorBody = 512
+
+// handleOpenAIErrorResponse forwards a non-2xx backend response verbatim.
+//
+// The body is read in full before anything is written, so the client
+// receives the backend's status, Content-Type and complete error body
+// (e.g., a JSON error explaining that max_tokens exceeds the context
+// length) even for a streaming request.
+func handleOpenAIErrorResponse(w http.ResponseWriter, resp *http.Response, instanceID string) {
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		proxyLog.Error("Failed to read error response from instance %s: %v", instanceID, err)
+		http.Error(w, fmt.Sprintf("Backend returned status %d", resp.StatusCode), http.StatusBadGateway)
+		return
+	}
+
+	logged := body
+	if len(logged) > maxLoggedErrorBody {
+		logged = logged[:maxLoggedErrorBody]
+	}
+	proxyLog.Warn("Instance %s returned status %d: %s", instanceID, resp.StatusCode, bytes.TrimSpace(logged))
+
+	copyResponseHeaders(resp.Header, w.Header())
+	w.Header().Set("Content-Length", strconv.Itoa(len(body)))
+	w.WriteHeader(resp.StatusCode)
+	if _, err := w.Write(body); err != nil {
+		proxyLog.Debug("Failed to write error response: %v", err)
+	}
+}
+
 // handleOpenAIBufferedResponse copies the entire response body to the client
 // in a single pass. Used for non-streaming endpoints such as embeddings.
 func handleOpenAIBufferedResponse(w http.ResponseWriter, body io.ReadCloser) {
 	written, err := io.Copy(w, body)
 	if err != nil {
-		logger.Error("Failed to write response body: %v", err)
+		proxyLog.Error("Failed to write response body: %v", err)
 		return
 	}
-	logger.Debug("Wrote %d bytes in buffered response", written)
+	proxyLog.Debug("Wrote %d bytes in buffered response", written)
 }
 
 // HealthCheck provides a health check endpoint for the proxy service.
@@ -0,0 +1,96 @@
+package handlers
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/tsingmaoai/xw-cli/internal/config"
+	"github.com/tsingmaoai/xw-cli/internal/runtime"
+)
+
+// newTestProxyHandler returns a proxy handler that routes requests for
+// "test-model" to the backend server, without a runtime manager.
+func newTestProxyHandler(t *testing.T, backend *httptest.Server) *ProxyHandler {
+	t.Helper()
+
+	backendURL, err := url.Parse(backend.URL)
+	if err != nil {
+		t.Fatalf("invalid backend URL: %v", err)
+	}
+	port, err := strconv.Atoi(backendURL.Port())
+	if err != nil {
+		t.Fatalf("invalid backend port: %v", err)
+	}
+
+	instance := &runtime.Instance{
+		ID:       "test-instance",
+		ModelID:  "test-model",
+		Alias:    "test-model",
+		State:    runtime.StateRunning,
+		Port:     port,
+		Metadata: map[string]string{"max_retries": "0"},
+	}
+
+	return &ProxyHandler{
+		ProxyCore: &ProxyCore{
+			handler:        &Handler{config: &config.Config{}},
+			concurrencyMgr: newConcurrencyManager(),
+			metrics:        newProxyMetrics(),
+			instances: &instanceCache{
+				instances: []*runtime.Instance{instance},
+				fetchedAt: time.Now(),
+			},
+			rrCounters: make(map[string]uint64),
+		},
+	}
+}
+
+func TestProxyRequestForwardsBackendErrors(t *testing.T) {
+	const errorBody = `{"error":{"message":"max_tokens exceeds the context length","type":"BadRequestError","code":400}}`
+
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		io.WriteString(w, errorBody)
+	}))
+	defer backend.Close()
+
+	p := newTestProxyHandler(t, backend)
+
+	tests := []struct {
+		name string
+		body string
+	}{
+		{"non-streaming", `{"model":"test-model","messages":[],"max_tokens":999999}`},
+		{"streaming", `{"model":"test-model","messages":[],"max_tokens":999999,"stream":true}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/v1/chat/completions", strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			rec := httptest.NewRecorder()
+
+			p.ProxyRequest(rec, req)
+
+			if rec.Code != http.StatusBadRequest {
+				t.Errorf("status = %d, want %d", rec.Code, http.StatusBadRequest)
+			}
+			if got := rec.Body.String(); got != errorBody {
+				t.Errorf("body = %q, want %q", got, errorBody)
+			}
+			if ct := rec.Header().Get("Content-Type"); ct != "application/json" {
+				t.Errorf("Content-Type = %q, want application/json", ct)
+			}
+			if cl := rec.Header().Get("Content-Length"); cl != strconv.Itoa(len(errorBody)) {
+				t.Errorf("Content-Length = %q, want %d", cl, len(errorBody))
+			}
+		})
+	}
+}
@@ -0,0 +1,156 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/tsingmaoai/xw-cli/internal/api"
+	"github.com/tsingmaoai/xw-cli/internal/config"
+	"github.com/tsingmaoai/xw-cli/internal/models"
+	"github.com/tsingmaoai/xw-cli/internal/runtime"
+)
+
+// newTestProxyCore returns a proxy core serving the given instances,
+// without a runtime manager.
+func newTestProxyCore(instances ...*runtime.Instance) *ProxyCore {
+	return &ProxyCore{
+		handler:        &Handler{config: &config.Config{}},
+		concurrencyMgr: newConcurrencyManager(),
+		metrics:        newProxyMetrics(),
+		instances: &instanceCache{
+			instances: instances,
+			fetchedAt: time.Now(),
+		},
+		rrCounters: make(map[string]uint64),
+	}
+}
+
+func TestSelectInstanceRoundRobin(t *testing.T) {
+	pc := newTestProxyCore(
+		&runtime.Instance{ID: "qwen3-8b-1", ModelID: "qwen3-8b", Alias: "qwen3-8b", State: runtime.StateRunning},
+		&runtime.Instance{ID: "qwen3-8b-2", ModelID: "qwen3-8b", Alias: "qwen3-8b", State: runtime.StateRunning},
+	)
+
+	// Names a client makes up resolve to the same model and share one position
+	var selected []string
+	for _, name := range []string{"qwen3-8b", "QWEN3-8B", "qwen3", "qwen3-8b-chat"} {
+		inst, err := pc.SelectInstance(context.Background(), name)
+		if err != nil {
+			t.Fatalf("SelectInstance(%q) failed: %v", name, err)
+		}
+		selected = append(selected, inst.ID)
+	}
+
+	want := []string{"qwen3-8b-1", "qwen3-8b-2", "qwen3-8b-1", "qwen3-8b-2"}
+	for i := range want {
+		if selected[i] != want[i] {
+			t.Fatalf("selected %v, want %v", selected, want)
+		}
+	}
+	if len(pc.rrCounters) != 1 {
+		t.Errorf("round-robin counters = %v, want one for the model", pc.rrCounters)
+	}
+}
+
+func TestProxyMetricsRemoveInstance(t *testing.T) {
+	m := newProxyMetrics()
+	kept := &runtime.Instance{ID: "qwen3-8b-1", ModelID: "qwen3-8b", Alias: "qwen3-8b"}
+	removed := &runtime.Instance{ID: "qwen3-8b-2", ModelID: "qwen3-8b", Alias: "qwen3-8b"}
+	for _, inst := range []*runtime.Instance{kept, removed} {
+		m.recordRequest(inst)
+		m.recordError(inst, "500")
+		m.observeDuration(inst, time.Second)
+	}
+
+	m.removeInstance(removed.ID)
+
+	var out strings.Builder
+	m.writeTo(&out, newConcurrencyManager())
+	if strings.Contains(out.String(), removed.ID) {
+		t.Errorf("metrics still contain removed instance %s:\n%s", removed.ID, out.String())
+	}
+	if !strings.Contains(out.String(), `xw_proxy_requests_total{model="qwen3-8b",instance_id="qwen3-8b-1"} 1`) {
+		t.Errorf("metrics lack the kept instance:\n%s", out.String())
+	}
+}
+
+func TestEscapeLabel(t *testing.T) {
+	tests := []struct {
+		value string
+		want  string
+	}{
+		{"qwen3-8b", `"qwen3-8b"`},
+		{`a"b\c`, `"a\"b\\c"`},
+		{"line\nbreak\ttab\x00", `"linebreaktab"`},
+		{"zero\u200bwidth", `"zerowidth"`},
+		{"通义千问", `"通义千问"`},
+	}
+	for _, tt := range tests {
+		if got := fmt.Sprintf("%q", escapeLabel(tt.value)); got != tt.want {
+			t.Errorf("escapeLabel(%q) quotes as %s, want %s", tt.value, got, tt.want)
+		}
+	}
+}
+
+func TestProxyRequestEmbeddings(t *testing.T) {
+	for _, id := range []string{"test-chat-8b", "test-embedding-8b"} {
+		capability := "completion"
+		if id == "test-embedding-8b" {
+			capability = embeddingCapability
+		}
+		models.RegisterModelSpec(&models.ModelSpec{
+			ID:           id,
+			Capabilities: []string{capability},
+			SupportedDevices: map[api.DeviceType][]models.BackendOption{
+				"test-npu": {{Type: api.BackendTypeVLLM, Mode: api.DeploymentModeDocker}},
+			},
+		})
+	}
+
+	tests := []struct {
+		name       string
+		modelID    string
+		status     int
+		wantStatus int
+		wantBody   string
+	}{
+		{"served by a model without the capability", "test-chat-8b", http.StatusOK, http.StatusOK, `"embedding"`},
+		{"rejected by a model without the capability", "test-chat-8b", http.StatusBadRequest, http.StatusBadRequest, "does not support embeddings"},
+		{"backend failure of a model without the capability", "test-chat-8b", http.StatusInternalServerError, http.StatusInternalServerError, "backend error"},
+		{"rejected by a model with the capability", "test-embedding-8b", http.StatusBadRequest, http.StatusBadRequest, "backend error"},
+		{"rejected by an unknown model", "test-unknown-8b", http.StatusBadRequest, http.StatusBadRequest, "backend error"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tt.status)
+				if tt.status == http.StatusOK {
+					fmt.Fprint(w, `{"data": [{"embedding": [0.1]}]}`)
+				} else {
+					fmt.Fprint(w, `{"error": {"message": "backend error"}}`)
+				}
+			}))
+			defer backend.Close()
+			u, _ := url.Parse(backend.URL)
+			port, _ := strconv.Atoi(u.Port())
+
+			p := &ProxyHandler{newTestProxyCore(&runtime.Instance{
+				ID: tt.modelID + "-1", ModelID: tt.modelID, Alias: tt.modelID, Port: port, State: runtime.StateRunning,
+			})}
+			rec := httptest.NewRecorder()
+			body := `{"model": "` + tt.modelID + `", "input": "hello"}`
+			p.ProxyRequest(rec, httptest.NewRequest(http.MethodPost, "/v1/embeddings", strings.NewReader(body)))
+
+			if rec.Code != tt.wantStatus || !strings.Contains(rec.Body.String(), tt.wantBody) {
+				t.Errorf("ProxyRequest() = %d %s, want %d with %q", rec.Code, rec.Body.String(), tt.wantStatus, tt.wantBody)
+			}
+		})
+	}
+}
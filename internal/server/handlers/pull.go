@@ -12,14 +12,15 @@ import (
 
 	"github.com/tsingmaoai/xw-cli/internal/api"
 	"github.com/tsingmaoai/xw-cli/internal/device"
-	"github.com/tsingmaoai/xw-cli/internal/logger"
 	"github.com/tsingmaoai/xw-cli/internal/models"
+	"github.com/tsingmaoai/xw-cli/internal/runtime"
 )
 
 // PullModel handles model download requests with real-time progress streaming.
 //
-// This endpoint downloads AI models from ModelScope and streams progress updates
-// to the client using Server-Sent Events (SSE). This provides a responsive user
+// This endpoint downloads AI models from ModelScope or HuggingFace (per the
+// model's source) and streams progress updates to the client using
+// Server-Sent Events (SSE). This provides a responsive user
 // experience with real-time feedback during long-running downloads.
 //
 // Workflow:
@@ -53,7 +54,8 @@ import (
 //
 //	{
 //	  "model": "qwen2-7b",      // Model ID from registry
-//	  "version": "main"         // Optional: ModelScope branch/tag (default: "main")
+//	  "version": "v1.0",        // Optional: source branch, tag, or commit (default: hub's default branch)
+//	  "jobs": 4                 // Optional: files downloaded concurrently (default: 4)
 //	}
 //
 // Response: SSE stream with Content-Type: text/event-stream
@@ -101,6 +103,11 @@ func (h *Handler) PullModel(w http.ResponseWriter, r *http.Request) {
 		h.WriteError(w, fmt.Sprintf("Model not found: %s", req.Model), http.StatusNotFound)
 		return
 	}
+	if modelSpec.DerivedFrom != "" {
+		h.WriteError(w, fmt.Sprintf("Model %s is a copy of %s and has no files of its own; pull %s instead",
+			req.Model, modelSpec.DerivedFrom, modelSpec.DerivedFrom), http.StatusBadRequest)
+		return
+	}
 
 	// Resolve the actual source ID for downloading
 	// If SourceID is set, use it; otherwise, fall back to the model ID
@@ -125,8 +132,23 @@ func (h *Handler) PullModel(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Reject a second concurrent pull of the same model; both would write
+	// to the same directory. The lock is held until the Modelfile and the
+	// download marker are written.
+	lock, err := acquireDownloadLock(h.getModelPath(h.config.Storage.GetModelsDir(), req.Model))
+	if err != nil {
+		errMsg, _ := json.Marshal(map[string]string{
+			"type":    "error",
+			"message": fmt.Sprintf("Cannot pull %s: %v", req.Model, err),
+		})
+		fmt.Fprintf(w, "data: %s\n\n", errMsg)
+		flusher.Flush()
+		return
+	}
+	defer lock.release()
+
 	// Log the pull operation for monitoring and debugging
-	logger.Info("Pulling model: %s (source: %s)", req.Model, sourceID)
+	log.Info("Pulling model: %s (source: %s)", req.Model, sourceID)
 
 	// Send initial status message to inform client download is starting
 	fmt.Fprintf(w, "data: {\"type\":\"status\",\"message\":\"Starting download of %s...\"}\n\n", modelSpec.ID)
@@ -138,12 +160,57 @@ func (h *Handler) PullModel(w http.ResponseWriter, r *http.Request) {
 	// - Direct HTTP downloads via Go ModelScope client
 	// - Progress tracking and SSE streaming
 	// - Automatic cancellation on client disconnect
-	// Use "latest" as default tag if version is not specified
-	tag := req.Version
-	if tag == "" {
-		tag = "latest"
+	// The requested version selects the source revision; the files are
+	// always stored under the "latest" tag, which is where run, show and
+	// edit look for them.
+	revision := req.Version
+	if revision == "" {
+		revision = models.DefaultRevision(modelSpec.Source)
+	}
+	commit, err := models.ResolveRevision(r.Context(), modelSpec.Source, sourceID, revision)
+	if err != nil {
+		log.Warn("Could not resolve revision %s of %s to a commit: %v", revision, sourceID, err)
+		commit = ""
+	}
+
+	// A different revision of a downloaded model is downloaded next to the
+	// current files and swapped in once complete, so the model never mixes
+	// files of both revisions. An interrupted download resumes on retry.
+	modelDir := h.getModelPath(h.config.Storage.GetModelsDir(), req.Model)
+	tag := "latest"
+	if isOtherRevision(modelDir, revision, commit) {
+		// Running engines read the files being replaced
+		if aliases := h.instancesUsingModelPath(modelDir); len(aliases) > 0 {
+			errMsg, _ := json.Marshal(map[string]string{
+				"type": "error",
+				"message": fmt.Sprintf("Cannot pull revision %s of %s while instances serve it (%s); stop them first",
+					revision, req.Model, strings.Join(aliases, ", ")),
+			})
+			fmt.Fprintf(w, "data: %s\n\n", errMsg)
+			flusher.Flush()
+			return
+		}
+		tag = revisionStagingTag(revision)
+		fmt.Fprintf(w, "data: {\"type\":\"status\",\"message\":\"Downloading revision %s; the current files are replaced when it completes\"}\n\n", revision)
+		flusher.Flush()
+	}
+
+	// Download the resolved commit, so that the files match the commit
+	// recorded in the Modelfile even if the branch moves meanwhile
+	downloadRevision := revision
+	if commit != "" {
+		downloadRevision = commit
+	}
+	modelPath, err := h.downloadModelStreaming(r.Context(), modelSpec.Source, sourceID, req.Model, tag, models.DownloadOptions{Revision: downloadRevision, Jobs: req.Jobs}, modelSpec.EstimatedSize(), w, flusher)
+	if err == nil && tag != "latest" {
+		if aliases := h.instancesUsingModelPath(modelDir); len(aliases) > 0 {
+			err = fmt.Errorf("instances started meanwhile serve %s (%s); stop them and pull again to switch to the downloaded revision",
+				req.Model, strings.Join(aliases, ", "))
+		} else {
+			err = replaceModelFiles(modelDir, modelPath)
+			modelPath = modelDir
+		}
 	}
-	modelPath, err := h.downloadModelStreaming(r.Context(), sourceID, req.Model, tag, w, flusher)
 	if err != nil {
 		// Send error message via SSE and terminate stream
 		fmt.Fprintf(w, "data: {\"type\":\"error\",\"message\":\"Failed to download: %s\"}\n\n", err.Error())
@@ -151,16 +218,17 @@ func (h *Handler) PullModel(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Generate Modelfile after successful download
-	if err := h.generateModelfile(modelPath, req.Model, modelSpec); err != nil {
-		logger.Warn("Failed to generate Modelfile for %s: %v", req.Model, err)
+	// Generate Modelfile after successful download, or record the new
+	// revision in the existing one
+	if err := h.generateModelfile(modelPath, req.Model, models.FormatRevision(revision, commit), modelSpec); err != nil {
+		log.Warn("Failed to generate Modelfile for %s: %v", req.Model, err)
 		// Don't fail the whole operation, just log the warning
 	}
 
 	// Apply chip-specific model configuration adjustments
 	// For Ascend 310P, ensure torch_dtype is set to float16 in config.json
 	if err := h.adjustModelConfigForChip(modelPath); err != nil {
-		logger.Warn("Failed to adjust model config for chip: %v", err)
+		log.Warn("Failed to adjust model config for chip: %v", err)
 		// Don't fail the whole operation, just log the warning
 	}
 
@@ -169,10 +237,10 @@ func (h *Handler) PullModel(w http.ResponseWriter, r *http.Request) {
 	markerPath := filepath.Join(modelPath, ".downloaded")
 	markerContent := fmt.Sprintf("Downloaded at: %s\n", time.Now().Format(time.RFC3339))
 	if err := os.WriteFile(markerPath, []byte(markerContent), 0644); err != nil {
-		logger.Warn("Failed to create .downloaded marker file: %v", err)
+		log.Warn("Failed to create .downloaded marker file: %v", err)
 		// Don't fail the whole operation, just log the warning
 	} else {
-		logger.Debug("Created download marker file: %s", markerPath)
+		log.Debug("Created download marker file: %s", markerPath)
 	}
 
 	// Send final success message with model path
@@ -198,21 +266,29 @@ func (h *Handler) PullModel(w http.ResponseWriter, r *http.Request) {
 //   - Configure prompt templates
 //   - Document model metadata
 //
-// This function generates an Ollama-compatible Modelfile format.
+// This function generates an Ollama-compatible Modelfile format, using the
+// same directive formatting that models.ParseModelfileDirectives reads back.
 //
 // Parameters:
 //   - modelPath: Path to the downloaded model directory
 //   - modelID: Model identifier (e.g., "qwen2-0.5b")
+//   - revision: Source revision the files were downloaded from (see
+//     models.FormatRevision), recorded as a comment
 //   - spec: Model specification containing metadata
 //
 // Returns:
 //   - Error if Modelfile creation fails
-func (h *Handler) generateModelfile(modelPath, modelID string, spec *models.ModelSpec) error {
+func (h *Handler) generateModelfile(modelPath, modelID, revision string, spec *models.ModelSpec) error {
 	modelfilePath := filepath.Join(modelPath, "Modelfile")
 	
-	// Check if Modelfile already exists (don't overwrite user customizations)
-	if _, err := os.Stat(modelfilePath); err == nil {
-		logger.Info("Modelfile already exists at %s, skipping generation", modelfilePath)
+	// An existing Modelfile keeps the user's customizations; only the
+	// revision it records is updated
+	if data, err := os.ReadFile(modelfilePath); err == nil {
+		content := models.SetModelfileRevision(string(data), revision)
+		if err := os.WriteFile(modelfilePath, []byte(content), 0644); err != nil {
+			return fmt.Errorf("failed to update Modelfile: %w", err)
+		}
+		log.Info("Modelfile already exists at %s, recorded revision %s", modelfilePath, revision)
 		return nil
 	}
 	
@@ -222,7 +298,8 @@ func (h *Handler) generateModelfile(modelPath, modelID string, spec *models.Mode
 	// Header comment
 	content.WriteString("# Modelfile generated by \"xw show\"\n")
 	content.WriteString("# To build a new Modelfile based on this, replace FROM with:\n")
-	content.WriteString(fmt.Sprintf("# FROM %s\n\n", modelID))
+	content.WriteString(fmt.Sprintf("# FROM %s\n", modelID))
+	content.WriteString(fmt.Sprintf("# Revision: %s\n\n", revision))
 	
 	// FROM directive - use model path
 	content.WriteString(fmt.Sprintf("FROM %s\n\n", modelPath))
@@ -230,12 +307,12 @@ func (h *Handler) generateModelfile(modelPath, modelID string, spec *models.Mode
 	// TEMPLATE directive - read from tokenizer_config.json
 	template := h.readChatTemplateFromTokenizer(modelPath)
 	if template != "" {
-		content.WriteString(fmt.Sprintf("TEMPLATE \"\"\"%s\"\"\"\n\n", template))
+		content.WriteString(models.FormatModelfileDirective("TEMPLATE", template) + "\n\n")
 	}
 	
 	// SYSTEM directive - default system prompt
 	systemPrompt := "You are Qwen, created by Alibaba Cloud. You are a helpful assistant."
-	content.WriteString(fmt.Sprintf("SYSTEM %s\n\n", systemPrompt))
+	content.WriteString(models.FormatModelfileDirective("SYSTEM", systemPrompt) + "\n\n")
 	
 	// PARAMETER directives - read from generation_config.json or use defaults
 	genConfig := h.readGenerationConfig(modelPath)
@@ -280,7 +357,118 @@ func (h *Handler) generateModelfile(modelPath, modelID string, spec *models.Mode
 		return fmt.Errorf("failed to write Modelfile: %w", err)
 	}
 	
-	logger.Info("Generated Modelfile at %s", modelfilePath)
+	log.Info("Generated Modelfile at %s", modelfilePath)
+	return nil
+}
+
+// isOtherRevision reports whether a pull of revision would replace a
+// completed download of another revision in modelPath.
+//
+// The revision recorded in the model's Modelfile is compared by commit if
+// both commits are known, and by branch or tag name otherwise. Models
+// downloaded before revisions were recorded are taken to be at the
+// default branch.
+func isOtherRevision(modelPath, revision, commit string) bool {
+	if _, err := os.Stat(filepath.Join(modelPath, ".downloaded")); err != nil {
+		return false
+	}
+
+	data, _ := os.ReadFile(filepath.Join(modelPath, "Modelfile"))
+	recordedRef, recordedCommit := models.ParseRevision(models.ModelfileRevision(string(data)))
+	switch {
+	case commit != "" && recordedCommit != "":
+		return commit != recordedCommit
+	case recordedRef != "":
+		return revision != recordedRef
+	default:
+		return revision != models.DefaultHuggingFaceRevision && revision != models.DefaultModelScopeRevision
+	}
+}
+
+// revisionStagingTag returns the tag (directory next to "latest") into
+// which a new revision of a downloaded model is downloaded.
+func revisionStagingTag(revision string) string {
+	return ".revision-" + strings.NewReplacer("/", "_", string(filepath.Separator), "_").Replace(revision)
+}
+
+// renameModelDir renames model directories; replaced in tests.
+var renameModelDir = os.Rename
+
+// instancesUsingModelPath returns the aliases of the active instances
+// that serve the model files in modelPath, directly or through the FROM of
+// their model's Modelfile (e.g., copies made with 'xw cp').
+func (h *Handler) instancesUsingModelPath(modelPath string) []string {
+	if h.runtimeManager == nil {
+		return nil
+	}
+
+	var aliases []string
+	for _, inst := range h.runtimeManager.ListCompat() {
+		switch inst.State {
+		case runtime.StateStopped, runtime.StateFailed, runtime.StateError:
+			continue
+		}
+		path, err := h.resolveModelPath(inst.ModelID)
+		if err == nil && path == modelPath {
+			aliases = append(aliases, inst.Alias)
+		}
+	}
+	return aliases
+}
+
+// replaceModelFiles swaps a completely downloaded revision into place.
+//
+// The user's files (the Modelfile and its backups) and the download lock
+// are linked into the staging directory first. The model directory is then
+// renamed aside and the staging directory renamed into its place, so the
+// model directory always holds one complete revision: if the second rename
+// fails, the previous directory is renamed back. The previous revision is
+// removed last.
+//
+// Parameters:
+//   - modelPath: Model directory (models/{id}/latest)
+//   - stagingPath: Directory holding the complete new revision, next to modelPath
+//
+// Returns:
+//   - Error if the new revision could not be put in place; the model
+//     directory is then unchanged
+func replaceModelFiles(modelPath, stagingPath string) error {
+	entries, err := os.ReadDir(modelPath)
+	if err != nil {
+		return fmt.Errorf("failed to read model directory: %w", err)
+	}
+	for _, entry := range entries {
+		name := entry.Name()
+		if name != "Modelfile" && name != downloadLockFile && !strings.HasPrefix(name, modelfileBackupPrefix) {
+			continue
+		}
+		staged := filepath.Join(stagingPath, name)
+		if err := os.RemoveAll(staged); err != nil {
+			return fmt.Errorf("failed to prepare downloaded revision: %w", err)
+		}
+		if err := os.Link(filepath.Join(modelPath, name), staged); err != nil {
+			return fmt.Errorf("failed to keep %s: %w", name, err)
+		}
+	}
+
+	previousPath := modelPath + ".previous"
+	if err := os.RemoveAll(previousPath); err != nil {
+		return fmt.Errorf("failed to remove leftover previous revision: %w", err)
+	}
+	if err := renameModelDir(modelPath, previousPath); err != nil {
+		return fmt.Errorf("failed to move previous revision aside: %w", err)
+	}
+	if err := renameModelDir(stagingPath, modelPath); err != nil {
+		if restoreErr := renameModelDir(previousPath, modelPath); restoreErr != nil {
+			return fmt.Errorf("failed to move downloaded revision into place: %w (previous revision left in %s: %v)",
+				err, previousPath, restoreErr)
+		}
+		return fmt.Errorf("failed to move downloaded revision into place: %w", err)
+	}
+
+	if err := os.RemoveAll(previousPath); err != nil {
+		log.Warn("Failed to remove previous revision %s: %v", previousPath, err)
+	}
 	return nil
 }
 
@@ -290,13 +478,13 @@ func (h *Handler) readChatTemplateFromTokenizer(modelPath string) string {
 	
 	data, err := os.ReadFile(tokenizerConfigPath)
 	if err != nil {
-		logger.Debug("Failed to read tokenizer_config.json: %v", err)
+		log.Debug("Failed to read tokenizer_config.json: %v", err)
 		return ""
 	}
 	
 	var config map[string]interface{}
 	if err := json.Unmarshal(data, &config); err != nil {
-		logger.Debug("Failed to parse tokenizer_config.json: %v", err)
+		log.Debug("Failed to parse tokenizer_config.json: %v", err)
 		return ""
 	}
 	
@@ -334,7 +522,7 @@ func (h *Handler) adjustModelConfigForChip(modelPath string) error {
 	chipsByType, err := device.FindAIChips()
 	if err != nil {
 		// If chip detection fails, skip adjustment
-		logger.Debug("Failed to detect chips for config adjustment: %v", err)
+		log.Debug("Failed to detect chips for config adjustment: %v", err)
 		return nil
 	}
 	
@@ -356,7 +544,7 @@ func (h *Handler) adjustModelConfigForChip(modelPath string) error {
 		return nil
 	}
 	
-	logger.Info("Detected Ascend 310P chip, adjusting model config for compatibility")
+	log.Info("Detected Ascend 310P chip, adjusting model config for compatibility")
 	
 	// Read config.json
 	configPath := filepath.Join(modelPath, "config.json")
@@ -364,7 +552,7 @@ func (h *Handler) adjustModelConfigForChip(modelPath string) error {
 	if err != nil {
 		// If config.json doesn't exist, it's not critical
 		if os.IsNotExist(err) {
-			logger.Debug("No config.json found at %s, skipping adjustment", configPath)
+			log.Debug("No config.json found at %s, skipping adjustment", configPath)
 			return nil
 		}
 		return fmt.Errorf("failed to read config.json: %w", err)
@@ -379,13 +567,13 @@ func (h *Handler) adjustModelConfigForChip(modelPath string) error {
 	// Check if torch_dtype needs adjustment
 	currentDtype, _ := config["torch_dtype"].(string)
 	if currentDtype == "float16" {
-		logger.Debug("torch_dtype is already float16, no adjustment needed")
+		log.Debug("torch_dtype is already float16, no adjustment needed")
 		return nil
 	}
 	
 	// Update torch_dtype to float16
 	config["torch_dtype"] = "float16"
-	logger.Info("Changed torch_dtype from '%s' to 'float16' for Ascend 310P compatibility", currentDtype)
+	log.Info("Changed torch_dtype from '%s' to 'float16' for Ascend 310P compatibility", currentDtype)
 	
 	// Write back to file with proper formatting
 	newData, err := json.MarshalIndent(config, "", "  ")
@@ -397,7 +585,7 @@ func (h *Handler) adjustModelConfigForChip(modelPath string) error {
 		return fmt.Errorf("failed to write config.json: %w", err)
 	}
 	
-	logger.Info("Successfully updated config.json for Ascend 310P")
+	log.Info("Successfully updated config.json for Ascend 310P")
 	return nil
 }
 
@@ -0,0 +1,137 @@
+package handlers
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+)
+
+// writeModelFiles creates a directory holding the given files.
+func writeModelFiles(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+// readModelFiles returns the files of a directory and their content.
+func readModelFiles(t *testing.T, dir string) map[string]string {
+	t.Helper()
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	files := make(map[string]string)
+	for _, entry := range entries {
+		data, err := os.ReadFile(filepath.Join(dir, entry.Name()))
+		if err != nil {
+			t.Fatal(err)
+		}
+		files[entry.Name()] = string(data)
+	}
+	return files
+}
+
+// assertModelFiles checks that a directory holds exactly the given files.
+func assertModelFiles(t *testing.T, dir string, want map[string]string) {
+	t.Helper()
+
+	got := readModelFiles(t, dir)
+	var names []string
+	for name := range got {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	if len(got) != len(want) {
+		t.Errorf("%s holds %v, want %d files", dir, names, len(want))
+	}
+	for name, content := range want {
+		if got[name] != content {
+			t.Errorf("%s/%s = %q, want %q", dir, name, got[name], content)
+		}
+	}
+}
+
+// revisionFixture creates a downloaded model at one revision and a staged
+// download of another, and returns their directories.
+func revisionFixture(t *testing.T) (string, string) {
+	modelDir := filepath.Join(t.TempDir(), "qwen3-8b")
+	modelPath := filepath.Join(modelDir, "latest")
+	stagingPath := filepath.Join(modelDir, revisionStagingTag("v2"))
+
+	writeModelFiles(t, modelPath, map[string]string{
+		"Modelfile":                        "FROM " + modelPath + "\nSYSTEM custom\n",
+		modelfileBackupPrefix + "20260101": "FROM " + modelPath + "\n",
+		downloadLockFile:                   "pid=1",
+		".downloaded":                      "Downloaded at: v1",
+		"config.json":                      "v1",
+		"model-00001.safetensors":          "v1",
+		"removed-in-v2.bin":                "v1",
+	})
+	writeModelFiles(t, stagingPath, map[string]string{
+		"Modelfile":               "FROM upstream\n",
+		"config.json":             "v2",
+		"model-00001.safetensors": "v2",
+	})
+	return modelPath, stagingPath
+}
+
+func TestReplaceModelFilesSwapsRevision(t *testing.T) {
+	modelPath, stagingPath := revisionFixture(t)
+
+	if err := replaceModelFiles(modelPath, stagingPath); err != nil {
+		t.Fatalf("replaceModelFiles() failed: %v", err)
+	}
+
+	// The new revision's files, with the user's Modelfile, its backups and
+	// the lock of the running pull kept
+	assertModelFiles(t, modelPath, map[string]string{
+		"Modelfile":                        "FROM " + modelPath + "\nSYSTEM custom\n",
+		modelfileBackupPrefix + "20260101": "FROM " + modelPath + "\n",
+		downloadLockFile:                   "pid=1",
+		"config.json":                      "v2",
+		"model-00001.safetensors":          "v2",
+	})
+	for _, path := range []string{stagingPath, modelPath + ".previous"} {
+		if _, err := os.Stat(path); !os.IsNotExist(err) {
+			t.Errorf("%s still exists after the swap", path)
+		}
+	}
+}
+
+func TestReplaceModelFilesKeepsPreviousRevisionOnFailure(t *testing.T) {
+	modelPath, stagingPath := revisionFixture(t)
+	before := readModelFiles(t, modelPath)
+
+	saved := renameModelDir
+	renameModelDir = func(from, to string) error {
+		if from == stagingPath {
+			return errors.New("disk error")
+		}
+		return os.Rename(from, to)
+	}
+	defer func() { renameModelDir = saved }()
+
+	if err := replaceModelFiles(modelPath, stagingPath); err == nil {
+		t.Fatal("replaceModelFiles() succeeded, want error")
+	}
+
+	// The previous revision is back in place, complete, and the staged
+	// download is kept for the next attempt
+	assertModelFiles(t, modelPath, before)
+	if _, err := os.Stat(filepath.Join(stagingPath, "model-00001.safetensors")); err != nil {
+		t.Errorf("staged download lost: %v", err)
+	}
+	if _, err := os.Stat(modelPath + ".previous"); !os.IsNotExist(err) {
+		t.Errorf("%s.previous still exists after the failed swap", modelPath)
+	}
+}
@@ -7,13 +7,14 @@ import (
 	"io"
 	"net/http"
 	"sort"
+	"strconv"
 	"strings"
 	"time"
 	
 	"github.com/docker/docker/pkg/stdcopy"
 	"github.com/tsingmaoai/xw-cli/internal/api"
+	"github.com/tsingmaoai/xw-cli/internal/config"
 	"github.com/tsingmaoai/xw-cli/internal/hooks"
-	"github.com/tsingmaoai/xw-cli/internal/logger"
 	"github.com/tsingmaoai/xw-cli/internal/models"
 	"github.com/tsingmaoai/xw-cli/internal/runtime"
 )
@@ -23,6 +24,15 @@ import (
 // This endpoint supports Server-Sent Events (SSE) for streaming progress updates
 // during model startup, Docker image pulling, etc.
 //
+// Progress is streamed as unnamed events with human-readable messages and
+// as "phase" events with a JSON api.StartupEvent, e.g.
+// {"phase":"pulling_image","percent":42}, for clients that render
+// progress by phase.
+//
+// If a dependency such as Docker is missing, clients that send the
+// api.ConfirmHeader header are asked with a "confirm" event whether it may
+// be installed (see ConfirmInstall); for other clients the start fails.
+//
 // HTTP Method: POST
 // Path: /api/runtime/start
 // Content-Type: application/json
@@ -35,6 +45,7 @@ func (h *Handler) StartModel(w http.ResponseWriter, r *http.Request) {
 		DeploymentMode api.DeploymentMode     `json:"deployment_mode"`
 		Interactive    bool                   `json:"interactive"`
 		Config         map[string]interface{} `json:"additional_config"`
+		Environment    map[string]string      `json:"environment"`
 	}
 	
 	if err := json.NewDecoder(r.Body).Decode(&reqBody); err != nil {
@@ -62,6 +73,7 @@ func (h *Handler) runModelWithSSE(w http.ResponseWriter, r *http.Request, reqBod
 	DeploymentMode api.DeploymentMode     `json:"deployment_mode"`
 	Interactive    bool                   `json:"interactive"`
 	Config         map[string]interface{} `json:"additional_config"`
+	Environment    map[string]string      `json:"environment"`
 }) {
 	// Set SSE headers
 	w.Header().Set("Content-Type", "text/event-stream")
@@ -84,8 +96,15 @@ func (h *Handler) runModelWithSSE(w http.ResponseWriter, r *http.Request, reqBod
 	ctx, cancel := context.WithCancel(r.Context())
 	defer cancel() // Ensure cleanup on exit
 	
+	// Clients that can answer install confirmations say so in a header;
+	// for others, installing a missing dependency is declined
+	var prompter hooks.Prompter = hooks.DeclinePrompter
+	if r.Header.Get(api.ConfirmHeader) == "true" {
+		prompter = h.streamPrompter(eventCh)
+	}
+	
 	// Start model in background with cancellable context
-	go h.runModelAsync(ctx, reqBody, eventCh, doneCh, errorCh)
+	go h.runModelAsync(ctx, reqBody, prompter, eventCh, doneCh, errorCh)
 	
 	// Stream events
 	ticker := time.NewTicker(30 * time.Second)
@@ -94,8 +113,14 @@ func (h *Handler) runModelWithSSE(w http.ResponseWriter, r *http.Request, reqBod
 	for {
 		select {
 		case event := <-eventCh:
-			// Send event
-			fmt.Fprintf(w, "data: %s\n\n", h.escapeSSE(event))
+			// Send event; startup phases are sent as named "phase" events
+			if phase, ok := api.ParseTaggedStartupEvent(event); ok {
+				fmt.Fprintf(w, "event: %s\ndata: %s\n\n", api.StartupEventSSEName, phase)
+			} else if confirmation, ok := api.ParseTaggedInstallConfirmation(event); ok {
+				fmt.Fprintf(w, "event: %s\ndata: %s\n\n", api.InstallConfirmationSSEName, confirmation)
+			} else {
+				fmt.Fprintf(w, "data: %s\n\n", h.escapeSSE(event))
+			}
 			flusher.Flush()
 			
 		case <-doneCh:
@@ -121,7 +146,7 @@ func (h *Handler) runModelWithSSE(w http.ResponseWriter, r *http.Request, reqBod
 			// Client disconnected (Ctrl+C or network issue)
 			// Cancel the context to stop all running hooks
 			cancel()
-			logger.Info("Client disconnected, cancelling running hooks")
+			log.Info("Client disconnected, cancelling running hooks")
 			return
 		}
 	}
@@ -135,7 +160,8 @@ func (h *Handler) runModelAsync(ctx context.Context, reqBody *struct {
 	DeploymentMode api.DeploymentMode  `json:"deployment_mode"`
 	Interactive    bool                   `json:"interactive"`
 	Config         map[string]interface{} `json:"additional_config"`
-}, eventCh chan<- string, doneCh chan<- struct{}, errorCh chan<- error) {
+	Environment    map[string]string      `json:"environment"`
+}, prompter hooks.Prompter, eventCh chan<- string, doneCh chan<- struct{}, errorCh chan<- error) {
 	
 	defer close(eventCh)
 	
@@ -152,76 +178,56 @@ func (h *Handler) runModelAsync(ctx context.Context, reqBody *struct {
 		return
 	}
 	
-	// Find the matching backend option from model spec
-	var selectedBackend *models.BackendOption
-	if reqBody.BackendType == "" || reqBody.DeploymentMode == "" {
-		// Use first available engine from first supported device as default
-		found := false
-		for _, engines := range modelSpec.SupportedDevices {
-			if len(engines) > 0 {
-				selectedBackend = &engines[0]
-				reqBody.BackendType = selectedBackend.Type
-				reqBody.DeploymentMode = selectedBackend.Mode
-				eventCh <- fmt.Sprintf("Using default backend: %s (%s mode)", reqBody.BackendType, reqBody.DeploymentMode)
-				found = true
-				break
-			}
-		}
-		if !found {
-			errorCh <- fmt.Errorf("no backends available for model %s", reqBody.ModelID)
-			return
-		}
-	} else {
-		// Find matching backend from user's choice across all devices
-		for _, engines := range modelSpec.SupportedDevices {
-			for i := range engines {
-				backend := &engines[i]
-				if backend.Type == reqBody.BackendType && backend.Mode == reqBody.DeploymentMode {
-					selectedBackend = backend
-					break
-				}
-			}
-			if selectedBackend != nil {
-				break
-			}
-		}
-		if selectedBackend == nil {
-			errorCh <- fmt.Errorf("backend %s (%s mode) not available for model %s", 
-				reqBody.BackendType, reqBody.DeploymentMode, reqBody.ModelID)
-			return
-		}
+	// Get model path (the Modelfile's FROM may point at a local checkpoint)
+	modelPath, err := h.resolveModelPath(reqBody.ModelID)
+	if err != nil {
+		errorCh <- err
+		return
 	}
 	
-	// Only support Docker mode for now
-	if reqBody.DeploymentMode != api.DeploymentModeDocker {
-		errorCh <- fmt.Errorf("only Docker mode is currently supported")
+	// Preflight: check that the model supports the chip it would run on and
+	// the requested engine before installing anything or pulling images.
+	// An omitted engine is chosen by the runtime manager.
+	deviceList, _ := reqBody.Config["device"].(string)
+	chipKey := h.runtimeManager.ChipConfigKey(h.config.Storage.ConfigDir, deviceList)
+	if _, err := runtime.CheckModelCompatibility(modelSpec, chipKey,
+		string(reqBody.BackendType), string(reqBody.DeploymentMode)); err != nil {
+		errorCh <- err
 		return
 	}
-	
-	// Use hook system to check and install Docker
-	hookRunner := hooks.NewRunner()
-	
-	dockerHook := hooks.NewDockerHook(eventCh)
-	hookRunner.Register(dockerHook)
-	
-	// Note: Image pulling is handled by the runtime itself
-	// Each runtime (vllm-docker, mindie-docker) knows its own default image
-	
-	// Run all hooks in auto mode (will install if missing)
-	// Use the cancellable context so hooks stop when client disconnects
-	if err := hookRunner.Run(ctx, hooks.ModeAuto); err != nil {
-		// Check if it's a cancellation
-		if ctx.Err() != nil {
-			errorCh <- fmt.Errorf("Operation cancelled by user")
+	arch, _ := reqBody.Config["arch"].(string)
+	if warning := runtimeImageWarning(chipKey, reqBody.BackendType, reqBody.DeploymentMode, arch); warning != "" {
+		log.Warn("%s", warning)
+		eventCh <- "Warning: " + warning
+	}
+	
+	// Native engines run on the host and do not need Docker
+	if reqBody.DeploymentMode != api.DeploymentModeNative {
+		// Use hook system to check and install Docker
+		hookRunner := hooks.NewRunner()
+		// The server has no terminal; the client confirms installations
+		hookRunner.SetPrompter(prompter)
+	
+		dockerHook := hooks.NewDockerHook(eventCh)
+		hookRunner.Register(dockerHook)
+	
+		// Note: Image pulling is handled by the runtime itself
+		// Each runtime (vllm-docker, mindie-docker) knows its own default image
+	
+		// Run all hooks in interactive mode (will install if missing and
+		// confirmed). Use the cancellable context so hooks stop when client
+		// disconnects
+		if err := hookRunner.Run(ctx, hooks.ModeInteractive); err != nil {
+			// Check if it's a cancellation
+			if ctx.Err() != nil {
+				errorCh <- fmt.Errorf("Operation cancelled by user")
+				return
+			}
+			errorCh <- fmt.Errorf("Setup failed: %w", err)
 			return
 		}
-		errorCh <- fmt.Errorf("Setup failed: %w", err)
-		return
 	}
 	
-	// Get model path
-	modelPath := h.getModelPath(h.config.Storage.GetModelsDir(), reqBody.ModelID)
-	
 	// Prepare additional config
 	additionalConfig := reqBody.Config
 	if additionalConfig == nil {
@@ -248,10 +254,11 @@ func (h *Handler) runModelAsync(ctx context.Context, reqBody *struct {
 		Port:             port,
 		Interactive:      reqBody.Interactive,
 		AdditionalConfig: additionalConfig,
+		Environment:      reqBody.Environment,
 		EventChannel:     eventCh, // Pass event channel for progress updates
 	}
 	
-	logger.Debug("RunOptions: BackendType=%s, DeploymentMode=%s", opts.BackendType, opts.DeploymentMode)
+	log.Debug("RunOptions: BackendType=%s, DeploymentMode=%s", opts.BackendType, opts.DeploymentMode)
 	
 	// Start the model
 	eventCh <- "Starting model instance..."
@@ -271,6 +278,9 @@ func (h *Handler) runModelAsync(ctx context.Context, reqBody *struct {
 		"port":            instance.Port,
 		"state":           instance.State,
 	}
+	if instance.DryRunCommand != "" {
+		successData["dry_run_command"] = instance.DryRunCommand
+	}
 	
 	dataJSON, _ := json.Marshal(successData)
 	eventCh <- string(dataJSON)
@@ -278,6 +288,41 @@ func (h *Handler) runModelAsync(ctx context.Context, reqBody *struct {
 	doneCh <- struct{}{}
 }
 
+// runtimeImageWarning warns before a start if the chip has no runtime
+// image for the architecture the instance would use, the host's unless
+// --arch was given. With an explicit Docker engine only that engine's image
+// is checked; otherwise the warning is given when no engine has one.
+//
+// Parameters:
+//   - chipKey: Chip config key (see runtime.Manager.ChipConfigKey), or empty
+//   - backendType: Requested backend, or empty
+//   - deploymentMode: Requested deployment mode, or empty
+//   - arch: Requested image architecture, or empty for the host's
+//
+// Returns:
+//   - Warning message, or empty string if an image is configured or the
+//     start does not use Docker
+func runtimeImageWarning(chipKey string, backendType api.BackendType, deploymentMode api.DeploymentMode, arch string) string {
+	if chipKey == "" || deploymentMode == api.DeploymentModeNative {
+		return ""
+	}
+
+	if arch == "" {
+		arch, _ = config.GetSystemArchitecture()
+	} else {
+		arch, _ = runtime.NormalizeArch(arch)
+	}
+	if arch == "" {
+		return ""
+	}
+
+	images, err := config.LoadRuntimeImagesConfig()
+	if err != nil {
+		return ""
+	}
+	return config.ArchImageWarning(images, chipKey, string(backendType), arch)
+}
+
 // runModelJSON handles model running with regular JSON response
 func (h *Handler) runModelJSON(w http.ResponseWriter, reqBody *struct {
 	ModelID        string                 `json:"model_id"`
@@ -286,10 +331,17 @@ func (h *Handler) runModelJSON(w http.ResponseWriter, reqBody *struct {
 	DeploymentMode api.DeploymentMode  `json:"deployment_mode"`
 	Interactive    bool                   `json:"interactive"`
 	Config         map[string]interface{} `json:"additional_config"`
+	Environment    map[string]string      `json:"environment"`
 }) {
 	// For JSON mode, we don't stream progress
 	// This is a simplified version
 	
+	modelPath, err := h.resolveModelPath(reqBody.ModelID)
+	if err != nil {
+		h.WriteError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	
 	// Always auto-allocate port
 	portAllocator := runtime.GetGlobalPortAllocator()
 	port, err := portAllocator.GetFreePort()
@@ -301,11 +353,13 @@ func (h *Handler) runModelJSON(w http.ResponseWriter, reqBody *struct {
 	opts := &runtime.RunOptions{
 		ModelID:          reqBody.ModelID,
 		Alias:            reqBody.Alias,
+		ModelPath:        modelPath,
 		BackendType:      string(reqBody.BackendType),
 		DeploymentMode:   string(reqBody.DeploymentMode),
 		Port:             port,
 		Interactive:      reqBody.Interactive,
 		AdditionalConfig: reqBody.Config,
+		Environment:      reqBody.Environment,
 	}
 	
 	// Pass config and data directories to runtime manager
@@ -323,6 +377,9 @@ func (h *Handler) runModelJSON(w http.ResponseWriter, reqBody *struct {
 		"port":            instance.Port,
 		"state":           instance.State,
 	}
+	if instance.DryRunCommand != "" {
+		response["dry_run_command"] = instance.DryRunCommand
+	}
 	
 	h.WriteJSON(w, response, http.StatusOK)
 }
@@ -431,11 +488,15 @@ func (h *Handler) CheckInstanceReady(w http.ResponseWriter, r *http.Request) {
 
 	// Check if instance state is running
 	if instance.State != runtime.StateRunning {
+		message := fmt.Sprintf("Instance is in %s state", instance.State)
+		if instance.HealthError != "" {
+			message = fmt.Sprintf("Instance is warming up (health check failing: %s)", instance.HealthError)
+		}
 		h.WriteJSON(w, map[string]interface{}{
 			"ready":    false,
 			"alias":    alias,
 			"state":    instance.State,
-			"message":  fmt.Sprintf("Instance is in %s state", instance.State),
+			"message":  message,
 		}, http.StatusOK)
 		return
 	}
@@ -484,7 +545,7 @@ func (h *Handler) checkEndpointAccessible(endpoint string) bool {
 	// 404 also counts as success (for engines without /health endpoint)
 	// but we'll log a warning
 	if resp.StatusCode == http.StatusNotFound {
-		logger.Warn("Endpoint %s returned 404 - engine may not implement /health", healthURL)
+		log.Warn("Endpoint %s returned 404 - engine may not implement /health", healthURL)
 		return true
 	}
 
@@ -537,6 +598,9 @@ func (h *Handler) RemoveInstance(w http.ResponseWriter, r *http.Request) {
 		InstanceID string `json:"instance_id"` // Deprecated: use alias instead
 		Alias      string `json:"alias"`
 		Force      bool   `json:"force"`
+		// DrainTimeout is the number of seconds to wait for in-flight
+		// requests before stopping; 0 stops immediately.
+		DrainTimeout float64 `json:"drain_timeout"`
 	}
 	
 	if err := json.NewDecoder(r.Body).Decode(&reqBody); err != nil {
@@ -555,7 +619,13 @@ func (h *Handler) RemoveInstance(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	
-	if err := h.runtimeManager.RemoveByAliasCompat(identifier, reqBody.Force); err != nil {
+	if reqBody.DrainTimeout < 0 {
+		h.WriteError(w, "drain_timeout must not be negative", http.StatusBadRequest)
+		return
+	}
+	drainTimeout := time.Duration(reqBody.DrainTimeout * float64(time.Second))
+	
+	if err := h.runtimeManager.RemoveByAliasCompat(identifier, reqBody.Force, drainTimeout); err != nil {
 		h.WriteError(w, fmt.Sprintf("Failed to remove instance: %v", err), http.StatusInternalServerError)
 		return
 	}
@@ -567,6 +637,208 @@ func (h *Handler) RemoveInstance(w http.ResponseWriter, r *http.Request) {
 	h.WriteJSON(w, response, http.StatusOK)
 }
 
+// RestartInstance handles HTTP requests to recreate an instance with the
+// options it was started with.
+//
+// The recorded start request includes the instance's environment variables,
+// which may hold secrets and are therefore never included in instance
+// listings; the instance is recreated from it here instead. Settings
+// changed on the live instance, its alias and concurrency limit, are kept.
+// Progress of the new start is streamed as Server-Sent Events, like a start
+// request.
+//
+// HTTP Method: POST
+// Path: /api/runtime/restart
+// Content-Type: application/json
+func (h *Handler) RestartInstance(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		h.WriteError(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	
+	var reqBody struct {
+		Alias string `json:"alias"`
+		// DrainTimeout is the number of seconds to wait for in-flight
+		// requests before stopping; 0 stops immediately.
+		DrainTimeout float64 `json:"drain_timeout"`
+	}
+	
+	if err := json.NewDecoder(r.Body).Decode(&reqBody); err != nil {
+		h.WriteError(w, fmt.Sprintf("Invalid request body: %v", err), http.StatusBadRequest)
+		return
+	}
+	
+	if reqBody.Alias == "" {
+		h.WriteError(w, "alias is required", http.StatusBadRequest)
+		return
+	}
+	
+	if reqBody.DrainTimeout < 0 {
+		h.WriteError(w, "drain_timeout must not be negative", http.StatusBadRequest)
+		return
+	}
+	drainTimeout := time.Duration(reqBody.DrainTimeout * float64(time.Second))
+	
+	var spec *runtime.RunSpec
+	found := false
+	for _, inst := range h.runtimeManager.ListCompat() {
+		if inst.Alias == reqBody.Alias {
+			spec = inst.RunSpec
+			found = true
+			break
+		}
+	}
+	if !found {
+		h.WriteError(w, fmt.Sprintf("instance not found: %s", reqBody.Alias), http.StatusNotFound)
+		return
+	}
+	if spec == nil {
+		h.WriteError(w, fmt.Sprintf("instance %s has no recorded start options (created by an older server); restart it with 'xw stop' and 'xw start'", reqBody.Alias), http.StatusBadRequest)
+		return
+	}
+	
+	if err := h.runtimeManager.RemoveByAliasCompat(reqBody.Alias, true, drainTimeout); err != nil {
+		h.WriteError(w, fmt.Sprintf("Failed to remove instance: %v", err), http.StatusInternalServerError)
+		return
+	}
+	
+	startReq := struct {
+		ModelID        string                 `json:"model_id"`
+		Alias          string                 `json:"alias"`
+		BackendType    api.BackendType        `json:"backend_type"`
+		DeploymentMode api.DeploymentMode     `json:"deployment_mode"`
+		Interactive    bool                   `json:"interactive"`
+		Config         map[string]interface{} `json:"additional_config"`
+		Environment    map[string]string      `json:"environment"`
+	}{
+		ModelID: spec.ModelID,
+		// Keep the current alias, which differs from the recorded one after a rename
+		Alias:          reqBody.Alias,
+		BackendType:    api.BackendType(spec.BackendType),
+		DeploymentMode: api.DeploymentMode(spec.DeploymentMode),
+		Config:         spec.AdditionalConfig,
+		Environment:    spec.Environment,
+	}
+	h.runModelWithSSE(w, r, &startReq)
+}
+
+// RenameInstance handles HTTP requests to change the alias of an instance.
+//
+// The instance keeps running; only the name used to route requests to it
+// changes. After renaming, the new alias is resolved the same way the proxy
+// resolves model names, to confirm requests will reach the instance.
+//
+// HTTP Method: POST
+// Path: /api/runtime/rename
+// Content-Type: application/json
+func (h *Handler) RenameInstance(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		h.WriteError(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	
+	var reqBody struct {
+		Alias    string `json:"alias"`
+		NewAlias string `json:"new_alias"`
+	}
+	
+	if err := json.NewDecoder(r.Body).Decode(&reqBody); err != nil {
+		h.WriteError(w, fmt.Sprintf("Invalid request body: %v", err), http.StatusBadRequest)
+		return
+	}
+	
+	if reqBody.Alias == "" || reqBody.NewAlias == "" {
+		h.WriteError(w, "alias and new_alias are required", http.StatusBadRequest)
+		return
+	}
+	
+	inst, err := h.runtimeManager.Rename(r.Context(), reqBody.Alias, reqBody.NewAlias)
+	if err != nil {
+		h.WriteError(w, fmt.Sprintf("Failed to rename instance: %v", err), http.StatusConflict)
+		return
+	}
+	
+	// Verify the proxy resolves the new alias to the renamed instance.
+	// Instances that are not running are not routable yet, so only
+	// running instances are checked.
+	if inst.State == runtime.StateRunning {
+		instances, err := h.runtimeManager.List(r.Context())
+		if err != nil {
+			h.WriteError(w, fmt.Sprintf("Instance renamed but could not be verified: %v", err), http.StatusInternalServerError)
+			return
+		}
+		resolved := false
+		for _, matched := range matchInstances(instances, reqBody.NewAlias) {
+			if matched.ID == inst.ID {
+				resolved = true
+				break
+			}
+		}
+		if !resolved {
+			h.WriteError(w, fmt.Sprintf("Instance renamed but '%s' does not resolve to it", reqBody.NewAlias), http.StatusInternalServerError)
+			return
+		}
+	}
+	
+	response := map[string]interface{}{
+		"message":     "Instance renamed successfully",
+		"instance_id": inst.ID,
+		"alias":       inst.Alias,
+	}
+	
+	h.WriteJSON(w, response, http.StatusOK)
+}
+
+// SetConcurrency handles HTTP requests to change the concurrency limit of
+// a running instance without restarting it.
+//
+// Lowering the limit below the number of requests in flight does not
+// interrupt them; new requests wait until the in-flight count falls below
+// the new limit. A limit of 0 removes the limit.
+//
+// HTTP Method: POST
+// Path: /api/runtime/concurrency
+// Content-Type: application/json
+func (h *Handler) SetConcurrency(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		h.WriteError(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	
+	var reqBody struct {
+		Alias         string `json:"alias"`
+		MaxConcurrent *int   `json:"max_concurrent"`
+	}
+	
+	if err := json.NewDecoder(r.Body).Decode(&reqBody); err != nil {
+		h.WriteError(w, fmt.Sprintf("Invalid request body: %v", err), http.StatusBadRequest)
+		return
+	}
+	
+	if reqBody.Alias == "" || reqBody.MaxConcurrent == nil {
+		h.WriteError(w, "alias and max_concurrent are required", http.StatusBadRequest)
+		return
+	}
+	if *reqBody.MaxConcurrent < 0 {
+		h.WriteError(w, "max_concurrent must not be negative", http.StatusBadRequest)
+		return
+	}
+	
+	inst, err := h.runtimeManager.SetMaxConcurrent(r.Context(), reqBody.Alias, *reqBody.MaxConcurrent)
+	if err != nil {
+		h.WriteError(w, fmt.Sprintf("Failed to set concurrency: %v", err), http.StatusInternalServerError)
+		return
+	}
+	
+	response := map[string]interface{}{
+		"message":        "Concurrency limit updated successfully",
+		"instance_id":    inst.ID,
+		"max_concurrent": *reqBody.MaxConcurrent,
+	}
+	
+	h.WriteJSON(w, response, http.StatusOK)
+}
+
 // escapeSSE escapes special characters for SSE
 func (h *Handler) escapeSSE(s string) string {
 	// Replace newlines with spaces for SSE
@@ -590,8 +862,22 @@ func (h *Handler) StreamLogs(w http.ResponseWriter, r *http.Request) {
 	// Get follow parameter (default: true for backward compatibility)
 	follow := r.URL.Query().Get("follow") != "false"
 	
+	// Get tail parameter (default: all lines)
+	tail := 0
+	if v := r.URL.Query().Get("tail"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 0 {
+			h.WriteError(w, fmt.Sprintf("invalid tail parameter: %s", v), http.StatusBadRequest)
+			return
+		}
+		tail = n
+	}
+	
 	// Get log stream from runtime manager
-	logStream, err := h.runtimeManager.GetLogsByAlias(r.Context(), alias, follow)
+	logStream, err := h.runtimeManager.GetLogsByAlias(r.Context(), alias, runtime.LogOptions{
+		Follow: follow,
+		Tail:   tail,
+	})
 	if err != nil {
 		h.WriteError(w, fmt.Sprintf("Failed to get logs: %v", err), http.StatusInternalServerError)
 		return
@@ -621,7 +907,7 @@ func (h *Handler) StreamLogs(w http.ResponseWriter, r *http.Request) {
 	// stdcopy.StdCopy properly separates and writes stdout and stderr to the response
 	_, err = stdcopy.StdCopy(flushWriter, flushWriter, logStream)
 	if err != nil && err != io.EOF {
-		logger.Error("Error streaming logs: %v", err)
+		log.Error("Error streaming logs: %v", err)
 	}
 }
 
@@ -7,7 +7,6 @@ import (
 	"strings"
 
 	"github.com/tsingmaoai/xw-cli/internal/config"
-	"github.com/tsingmaoai/xw-cli/internal/logger"
 )
 
 // ListVersionsResponse represents the response for listing available configuration versions.
@@ -129,15 +128,15 @@ func (h *Handler) ListVersions(w http.ResponseWriter, r *http.Request) {
 	// Get locally installed versions (always available)
 	installed, err := vm.ListInstalledVersions()
 	if err != nil {
-		logger.Warn("Failed to list installed versions: %v", err)
+		log.Warn("Failed to list installed versions: %v", err)
 		installed = []string{}
 	}
 
 	// Try to fetch registry (may fail if offline or registry unavailable)
-	logger.Debug("Fetching package registry...")
+	log.Debug("Fetching package registry...")
 	registry, err := vm.FetchRegistry()
 	if err != nil {
-		logger.Warn("Failed to fetch registry: %v", err)
+		log.Warn("Failed to fetch registry: %v", err)
 		
 		// Return local information only
 		response := ListVersionsResponse{
@@ -155,7 +154,7 @@ func (h *Handler) ListVersions(w http.ResponseWriter, r *http.Request) {
 	// Registry available, get compatible versions
 	compatible, err := vm.GetCompatibleVersions(binaryVersion)
 	if err != nil {
-		logger.Error("Failed to get compatible versions: %v", err)
+		log.Error("Failed to get compatible versions: %v", err)
 		h.WriteError(w, fmt.Sprintf("failed to get compatible versions: %v", err),
 			http.StatusInternalServerError)
 		return
@@ -292,7 +291,7 @@ func (h *Handler) Update(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Fetch registry
-	logger.Info("Fetching package registry...")
+	log.Info("Fetching package registry...")
 	_, err = vm.FetchRegistry()
 	if err != nil {
 		h.WriteError(w, fmt.Sprintf("failed to fetch registry: %v", err),
@@ -306,7 +305,7 @@ func (h *Handler) Update(w http.ResponseWriter, r *http.Request) {
 
 	if req.Version == "" {
 		// Update to latest compatible version
-		logger.Info("Finding latest compatible version...")
+		log.Info("Finding latest compatible version...")
 		latest, err := vm.GetLatestCompatibleVersion(h.config.BinaryVersion)
 		if err != nil {
 			h.WriteError(w, fmt.Sprintf("no compatible versions found: %v", err),
@@ -317,7 +316,7 @@ func (h *Handler) Update(w http.ResponseWriter, r *http.Request) {
 		pkg = latest
 	} else {
 		// Update to specific version
-		logger.Info("Finding version %s...", req.Version)
+		log.Info("Finding version %s...", req.Version)
 		foundPkg, err := vm.FindPackage(req.Version)
 		if err != nil {
 			h.WriteError(w, fmt.Sprintf("version not found: %v", err),
@@ -364,7 +363,7 @@ func (h *Handler) Update(w http.ResponseWriter, r *http.Request) {
 	// Download if not installed
 	downloaded := false
 	if !vm.IsVersionInstalled(targetVersion) {
-		logger.Info("Downloading configuration %s...", targetVersion)
+		log.Info("Downloading configuration %s...", targetVersion)
 		if err := vm.DownloadPackage(pkg); err != nil {
 			h.WriteError(w, fmt.Sprintf("failed to download package: %v", err),
 				http.StatusInternalServerError)
@@ -380,7 +379,7 @@ func (h *Handler) Update(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	logger.Info("Successfully updated from %s to %s", currentVersion, targetVersion)
+	log.Info("Successfully updated from %s to %s", currentVersion, targetVersion)
 
 	response := UpdateResponse{
 		Success:         true,
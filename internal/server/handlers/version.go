@@ -2,11 +2,18 @@
 package handlers
 
 import (
+	"context"
 	"net/http"
+	"strconv"
+	"time"
 
 	"github.com/tsingmaoai/xw-cli/internal/api"
 )
 
+// runtimeVersionTimeout bounds how long the runtimes may take to report
+// their versions for a verbose version request.
+const runtimeVersionTimeout = 5 * time.Second
+
 // Version handles requests for server version information.
 //
 // This endpoint returns detailed version metadata about the server build,
@@ -34,6 +41,9 @@ import (
 //	  "git_commit": "a1b2c3d4"
 //	}
 //
+// With ?verbose=true, the response also includes "runtimes", the versions
+// reported by the registered runtimes (e.g., the Docker daemon version).
+//
 // Example usage:
 //
 //	curl http://localhost:11581/api/version
@@ -50,6 +60,11 @@ func (h *Handler) Version(w http.ResponseWriter, r *http.Request) {
 		Version:   h.version,
 		BuildTime: h.buildTime,
 	}
+	if verbose, _ := strconv.ParseBool(r.URL.Query().Get("verbose")); verbose && h.runtimeManager != nil {
+		ctx, cancel := context.WithTimeout(r.Context(), runtimeVersionTimeout)
+		defer cancel()
+		resp.Runtimes = h.runtimeManager.RuntimeVersions(ctx)
+	}
 
 	// Return success response
 	h.WriteJSON(w, resp, http.StatusOK)
@@ -24,7 +24,9 @@ package server
 import (
 	"context"
 	"fmt"
+	"net"
 	"net/http"
+	"os"
 	"time"
 
 	"github.com/tsingmaoai/xw-cli/internal/config"
@@ -135,6 +137,9 @@ func NewServer(cfg *config.Config, runtimeMgr *runtime.Manager, version string)
 //	// Wait for signal to shutdown...
 //	srv.Stop(context.Background())
 func (s *Server) Start() error {
+	// Register the models created with 'xw cp' on top of models.yaml
+	models.LoadDerivedModels(s.config.Storage.GetModelsDir())
+	
 	// Create handler instance with all dependencies
 	h := handlers.NewHandler(
 		s.config,
@@ -157,14 +162,20 @@ func (s *Server) Start() error {
 	// Register routes with handlers from the handlers package
 	mux.HandleFunc("/api/health", h.Health)
 	mux.HandleFunc("/api/version", h.Version)
+	mux.HandleFunc("/api/doctor", h.Doctor)
 	mux.HandleFunc("/api/models/list", h.ListModels)
 	mux.HandleFunc("/api/models/downloaded", h.ListDownloadedModels)
 	mux.HandleFunc("/api/models/show", h.ShowModel)
 	mux.HandleFunc("/api/models/pull", h.PullModel)
+	mux.HandleFunc("/api/models/modelfile", h.UpdateModelfile)
+	mux.HandleFunc("/api/models/modelfile/revert", h.RevertModelfile)
+	mux.HandleFunc("/api/models/copy", h.CopyModel)
+	mux.HandleFunc("/api/models/remove", h.RemoveModel)
 
 	// Device management endpoints
 	mux.HandleFunc("/api/devices/list", h.ListDevices)
 	mux.HandleFunc("/api/devices/supported", h.GetSupportedDevices)
+	mux.HandleFunc("/api/devices/allocations", h.ListDeviceAllocations)
 
 	// Configuration management endpoints
 	mux.HandleFunc("/api/config/info", h.ConfigInfo)
@@ -183,6 +194,10 @@ func (s *Server) Start() error {
 	mux.HandleFunc("/api/runtime/check-ready", h.CheckInstanceReady)
 	mux.HandleFunc("/api/runtime/stop", h.StopInstance)
 	mux.HandleFunc("/api/runtime/remove", h.RemoveInstance)
+	mux.HandleFunc("/api/runtime/restart", h.RestartInstance)
+	mux.HandleFunc("/api/runtime/rename", h.RenameInstance)
+	mux.HandleFunc("/api/runtime/concurrency", h.SetConcurrency)
+	mux.HandleFunc("/api/runtime/confirm", h.ConfirmInstall)
 	mux.HandleFunc("/api/runtime/logs", h.StreamLogs)
 
 	// OpenAI-compatible API endpoints
@@ -191,6 +206,11 @@ func (s *Server) Start() error {
 	mux.HandleFunc("/v1/completions", proxyHandler.ProxyRequest)
 	mux.HandleFunc("/v1/embeddings", proxyHandler.ProxyRequest)
 
+	// Model discovery for OpenAI and Anthropic clients.
+	// Lists running instances; the response shape follows the client's API format.
+	mux.HandleFunc("/v1/models", proxyHandler.HandleListModels)
+	mux.HandleFunc("/v1/models/", proxyHandler.HandleGetModel)
+
 	// Anthropic Messages API endpoints
 	// Format-converting proxy: accepts Anthropic format, translates to OpenAI
 	// format for the backend, and translates responses back to Anthropic format.
@@ -201,20 +221,76 @@ func (s *Server) Start() error {
 	// Health check for proxy
 	mux.HandleFunc("/v1/health", proxyHandler.HealthCheck)
 
+	// Prometheus metrics for proxy traffic
+	mux.HandleFunc("/metrics", proxyHandler.HandleMetrics)
+
 	addr := fmt.Sprintf("%s:%d", s.config.Server.Host, s.config.Server.Port)
 	s.httpServer = &http.Server{
 		Addr:    addr,
-		Handler: s.loggingMiddleware(mux),
+		Handler: s.loggingMiddleware(s.authMiddleware(mux)),
 		// No timeouts for streaming operations (model downloads)
 		// ReadTimeout:  0,  // No read timeout
 		// WriteTimeout: 0,  // No write timeout for SSE streaming
 		IdleTimeout: 120 * time.Second,
 	}
 
+	if socket := s.config.Server.Socket; socket != "" {
+		ln, err := listenUnixSocket(socket)
+		if err != nil {
+			return err
+		}
+		logger.Info("Starting xw server on unix socket %s", socket)
+		return s.httpServer.Serve(ln)
+	}
+
+	if s.config.Server.TLSEnabled() {
+		logger.Info("Starting xw server on %s (TLS)", addr)
+		return s.httpServer.ListenAndServeTLS(s.config.Server.TLSCert, s.config.Server.TLSKey)
+	}
+
 	logger.Info("Starting xw server on %s", addr)
 	return s.httpServer.ListenAndServe()
 }
 
+// listenUnixSocket listens on a Unix domain socket, readable and writable
+// by the owner only.
+//
+// A socket file left by a server that did not shut down cleanly is removed
+// first; a socket that still accepts connections belongs to a running
+// server and is reported as in use. The socket file is removed when the
+// listener is closed.
+//
+// Parameters:
+//   - path: Socket file path
+//
+// Returns:
+//   - Listener on the socket
+//   - Error if the socket is in use or cannot be created
+func listenUnixSocket(path string) (net.Listener, error) {
+	if info, err := os.Stat(path); err == nil {
+		if info.Mode()&os.ModeSocket == 0 {
+			return nil, fmt.Errorf("%s exists and is not a socket", path)
+		}
+		if conn, err := net.Dial("unix", path); err == nil {
+			conn.Close()
+			return nil, fmt.Errorf("listen unix %s: bind: address already in use", path)
+		}
+		if err := os.Remove(path); err != nil {
+			return nil, fmt.Errorf("failed to remove stale socket %s: %w", path, err)
+		}
+	}
+
+	ln, err := net.Listen("unix", path)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.Chmod(path, 0600); err != nil {
+		ln.Close()
+		return nil, fmt.Errorf("failed to set socket permissions: %w", err)
+	}
+	return ln, nil
+}
+
 // Stop gracefully shuts down the server without interrupting active connections.
 //
 // This method initiates a graceful shutdown of the HTTP server. It: